@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListAddressesOptions selects the addresses listed. Limit is the page size, 0 taking the server's
+// default.
+type ListAddressesOptions struct {
+	Limit int
+}
+
+// ListAddresses returns a page of the user's watched addresses, oldest first, and its pagination
+func (c *Client) ListAddresses(ctx context.Context, opts ListAddressesOptions) ([]Address, *Pagination, error) {
+	addresses, meta, err := c.listAddresses(ctx, opts, "")
+	return addresses, meta.Pagination, err
+}
+
+// Addresses iterates over the user's watched addresses, oldest first, fetching the pages as they
+// are reached
+func (c *Client) Addresses(ctx context.Context, opts ListAddressesOptions) iter.Seq2[Address, error] {
+	return paginate(ctx, func(ctx context.Context, cursor string) ([]Address, Meta, error) {
+		return c.listAddresses(ctx, opts, cursor)
+	})
+}
+
+func (c *Client) listAddresses(ctx context.Context, opts ListAddressesOptions, cursor string) ([]Address, Meta, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var addresses []Address
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/addresses", query: withCursor(q, cursor), auth: userAuth}, &addresses)
+	return addresses, meta, err
+}
+
+// Address returns the user's watched address id
+func (c *Client) Address(ctx context.Context, id string) (*Address, error) {
+	var res Address
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/addresses/" + url.PathEscape(id), auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CreateAddress watches an address. Watching the same address on the same chain twice fails with
+// a conflict (see IsConflict).
+func (c *Client) CreateAddress(ctx context.Context, req CreateAddressRequest) (*Address, error) {
+	var res Address
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/addresses", body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateAddress replaces the label and notes of the user's address id, returning the updated
+// address. It fails with a conflict (see IsConflict) when the address was changed since
+// req.Version.
+func (c *Client) UpdateAddress(ctx context.Context, id string, req UpdateAddressRequest) (*Address, error) {
+	var res Address
+	path := "/api/v1/addresses/" + url.PathEscape(id)
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: path, body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteAddress stops watching the user's address id, returning it as it was
+func (c *Client) DeleteAddress(ctx context.Context, id string) (*Address, error) {
+	var res Address
+	path := "/api/v1/addresses/" + url.PathEscape(id)
+	if _, err := c.do(ctx, request{method: http.MethodDelete, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListActivityOptions selects the activity listed. Limit is the page size, 0 taking the server's
+// default.
+type ListActivityOptions struct {
+	Limit int
+}
+
+// ListAddressActivity returns a page of the activity of the user's address id, newest first, and
+// its pagination
+func (c *Client) ListAddressActivity(ctx context.Context, id string, opts ListActivityOptions) ([]ActivityItem, *Pagination, error) {
+	items, meta, err := c.listAddressActivity(ctx, id, opts, "")
+	return items, meta.Pagination, err
+}
+
+// AddressActivity iterates over the activity of the user's address id: its transactions, alerts
+// and the changes of the address and its rules, newest first, fetching the pages as they are
+// reached
+func (c *Client) AddressActivity(ctx context.Context, id string, opts ListActivityOptions) iter.Seq2[ActivityItem, error] {
+	return paginate(ctx, func(ctx context.Context, cursor string) ([]ActivityItem, Meta, error) {
+		return c.listAddressActivity(ctx, id, opts, cursor)
+	})
+}
+
+func (c *Client) listAddressActivity(ctx context.Context, id string, opts ListActivityOptions, cursor string) ([]ActivityItem, Meta, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var items []ActivityItem
+	path := "/api/v1/addresses/" + url.PathEscape(id) + "/activity"
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: path, query: withCursor(q, cursor), auth: userAuth}, &items)
+	return items, meta, err
+}
+
+// ListAddressTransactions returns a page of the transaction history of the user's address id,
+// newest first, and its pagination
+func (c *Client) ListAddressTransactions(ctx context.Context, id string, opts ListTransactionsOptions) ([]AddressTransaction, *Pagination, error) {
+	items, meta, err := c.listAddressTransactions(ctx, id, opts, "")
+	return items, meta.Pagination, err
+}
+
+// AddressTransactions iterates over the transaction history of the user's address id, newest
+// first, fetching the pages as they are reached
+func (c *Client) AddressTransactions(ctx context.Context, id string, opts ListTransactionsOptions) iter.Seq2[AddressTransaction, error] {
+	return paginate(ctx, func(ctx context.Context, cursor string) ([]AddressTransaction, Meta, error) {
+		return c.listAddressTransactions(ctx, id, opts, cursor)
+	})
+}
+
+func (c *Client) listAddressTransactions(ctx context.Context, id string, opts ListTransactionsOptions, cursor string) ([]AddressTransaction, Meta, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var items []AddressTransaction
+	path := "/api/v1/addresses/" + url.PathEscape(id) + "/transactions"
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: path, query: withCursor(q, cursor), auth: userAuth}, &items)
+	return items, meta, err
+}
+
+// AddressStatus returns the state of the engine's watch of the user's address id, to check it is
+// being monitored
+func (c *Client) AddressStatus(ctx context.Context, id string) (*AddressStatus, error) {
+	var res AddressStatus
+	path := "/api/v1/addresses/" + url.PathEscape(id) + "/status"
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
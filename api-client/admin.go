@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// AdminStats returns the operations dashboard snapshot, for a client created WithAdminToken
+func (c *Client) AdminStats(ctx context.Context) (*AdminStats, error) {
+	var res AdminStats
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/admin/stats", auth: adminAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Impersonate returns a token acting as the user id for the support operator to reproduce an
+// issue, for a client created WithAdminToken with the operator's own token from
+// ADMIN_OPERATOR_TOKENS, which names the operator. The grant and every request made with the token
+// are listed in the user's account activity, and the user is emailed.
+func (c *Client) Impersonate(ctx context.Context, id string, req ImpersonateRequest) (*Impersonation, error) {
+	var res Impersonation
+	path := "/api/v1/admin/users/" + url.PathEscape(id) + "/impersonate"
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: path, body: req, auth: adminAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RevokeUserSession signs out the session sessionID of the user id, e.g. the SessionID of an
+// Impersonation before it expires, for a client created WithAdminToken
+func (c *Client) RevokeUserSession(ctx context.Context, id, sessionID string) error {
+	path := "/api/v1/admin/users/" + url.PathEscape(id) + "/sessions/" + url.PathEscape(sessionID)
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: path, auth: adminAuth}, nil)
+	return err
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ArchivedAlerts reads the user's alerts created in [from, to) back from the archive, oldest
+// first. The period spans at most 31 days. The iteration stops after the first error.
+func (c *Client) ArchivedAlerts(ctx context.Context, from, to time.Time) iter.Seq2[ArchivedAlert, error] {
+	return func(yield func(ArchivedAlert, error) bool) {
+		q := url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}}
+		req := request{method: http.MethodGet, path: "/api/v1/alerts/archived", query: q, auth: userAuth}
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			yield(ArchivedAlert{}, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			var env envelope
+			json.NewDecoder(resp.Body).Decode(&env)
+			yield(ArchivedAlert{}, apiError(resp, env))
+			return
+		}
+
+		// One alert per line
+		lines := bufio.NewScanner(resp.Body)
+		lines.Buffer(nil, 1<<20)
+		for lines.Scan() {
+			var alert ArchivedAlert
+			if err := json.Unmarshal(lines.Bytes(), &alert); err != nil {
+				yield(ArchivedAlert{}, fmt.Errorf("failed to decode archived alert: %w", err))
+				return
+			}
+			if !yield(alert, nil) {
+				return
+			}
+		}
+		if err := lines.Err(); err != nil {
+			yield(ArchivedAlert{}, fmt.Errorf("failed to read archived alerts: %w", err))
+		}
+	}
+}
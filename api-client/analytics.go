@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Analytics returns the signed-in user's API usage of the last days UTC days, today included, 0
+// taking the server's default of 30. The latest calls show up within the server's
+// ANALYTICS_FLUSH_INTERVAL.
+func (c *Client) Analytics(ctx context.Context, days int) (*Analytics, error) {
+	q := url.Values{}
+	if days > 0 {
+		q.Set("days", strconv.Itoa(days))
+	}
+	var res Analytics
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me/analytics", query: q, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Dashboard returns the signed-in user's home screen summary: address and alert counts, the most
+// active addresses and, when the server's node can read them, their balances
+func (c *Client) Dashboard(ctx context.Context) (*Dashboard, error) {
+	var res Dashboard
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me/dashboard", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// APIKeys lists the signed-in user's API keys, revoked ones included
+func (c *Client) APIKeys(ctx context.Context) ([]APIKey, error) {
+	var res []APIKey
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me/api-keys", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateAPIKey creates an API key for the signed-in user. The key is only returned here, a client
+// created WithAPIKey presents it.
+func (c *Client) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	var res CreatedAPIKey
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/me/api-keys", body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RevokeAPIKey revokes the API key id, requests presenting it are rejected at once
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	path := "/api/v1/users/me/api-keys/" + url.PathEscape(id)
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: path, auth: userAuth}, nil)
+	return err
+}
+
+// SetAPIKeyAllowedCIDRs replaces the networks the API key id is accepted from, in CIDR notation or
+// as single addresses. Requests presenting it from elsewhere fail with 403; no networks accept it
+// from anywhere.
+func (c *Client) SetAPIKeyAllowedCIDRs(ctx context.Context, id string, cidrs []string) (*APIKey, error) {
+	if cidrs == nil {
+		cidrs = []string{}
+	}
+	var res APIKey
+	path := "/api/v1/users/me/api-keys/" + url.PathEscape(id) + "/allowed-cidrs"
+	body := map[string][]string{"allowed_cidrs": cidrs}
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: path, body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetAPIKeyScopes replaces the scopes of the API key id, at least one of the Scope constants.
+// Requests presenting it to endpoints of other scopes fail with 403.
+func (c *Client) SetAPIKeyScopes(ctx context.Context, id string, scopes []string) (*APIKey, error) {
+	var res APIKey
+	path := "/api/v1/users/me/api-keys/" + url.PathEscape(id) + "/scopes"
+	body := map[string][]string{"scopes": scopes}
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: path, body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
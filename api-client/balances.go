@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// BalanceWatches returns the balance watches of the user's address addressID
+func (c *Client) BalanceWatches(ctx context.Context, addressID string) ([]BalanceWatch, error) {
+	var res []BalanceWatch
+	path := "/api/v1/addresses/" + url.PathEscape(addressID) + "/balance-watches"
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WatchBalance polls the balance of an ERC-20 token held by the user's address addressID,
+// alerting when it moves by at least req.MinDelta. Watching a token twice fails with a conflict
+// (see IsConflict).
+func (c *Client) WatchBalance(ctx context.Context, addressID string, req CreateBalanceWatchRequest) (*BalanceWatch, error) {
+	var res BalanceWatch
+	path := "/api/v1/addresses/" + url.PathEscape(addressID) + "/balance-watches"
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: path, body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateBalanceWatch replaces the settings of the user's balance watch id, returning the updated
+// watch
+func (c *Client) UpdateBalanceWatch(ctx context.Context, id string, req UpdateBalanceWatchRequest) (*BalanceWatch, error) {
+	var res BalanceWatch
+	path := "/api/v1/balance-watches/" + url.PathEscape(id)
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: path, body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteBalanceWatch stops polling the user's balance watch id, returning it as it was
+func (c *Client) DeleteBalanceWatch(ctx context.Context, id string) (*BalanceWatch, error) {
+	var res BalanceWatch
+	path := "/api/v1/balance-watches/" + url.PathEscape(id)
+	if _, err := c.do(ctx, request{method: http.MethodDelete, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
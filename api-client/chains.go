@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Fees returns the current fee estimates of chain, e.g. ethereum or bitcoin, read from the
+// watcher's node. They are cached by the server for a few seconds.
+func (c *Client) Fees(ctx context.Context, chain string) (*Fees, error) {
+	var res Fees
+	path := "/api/v1/chains/" + url.PathEscape(chain) + "/fees"
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Transaction looks up the status, confirmations and transfers of any transaction of chain by its
+// hash, read from the watcher's node
+func (c *Client) Transaction(ctx context.Context, chain, hash string) (*TransactionStatus, error) {
+	var res TransactionStatus
+	path := "/api/v1/chains/" + url.PathEscape(chain) + "/tx/" + url.PathEscape(hash)
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,277 @@
+// Package client is the Go client of the Blockchain Address Watcher API. A Client signs in with
+// Login (or a token from WithToken), unwraps the response envelope into typed results, retries
+// requests failing with a temporary error and pages through lists with iterators.
+//
+//	c := client.New("https://watcher.example.com")
+//	if _, err := c.Login(ctx, email, password); err != nil {
+//		return err
+//	}
+//	for job, err := range c.Jobs(ctx, client.ListJobsOptions{}) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client calls the API at its base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	userAgent  string
+	adminToken string
+	apiKey     string
+	signingKey *signingKey
+	retry      RetryPolicy
+
+	mu    sync.RWMutex
+	token string
+}
+
+// RetryPolicy controls how requests failing with a temporary error are retried: network errors
+// and 429, 502, 503 and 504 responses. Only GET, PUT and DELETE requests are retried, after
+// Retry-After when the server sends one and with exponential backoff from BaseDelay to MaxDelay
+// otherwise.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy retries a request 3 times, after 200ms, 400ms and 800ms
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with httpClient instead of a client with a 30s timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithToken signs the requests in with a token returned by Login
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithAPIKey signs the requests in with an API key returned by CreateAPIKey instead of a token.
+// API keys cannot manage the user's credentials, such as their password or API keys.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithSigningKey signs the requests with a key created with CreateAPIKeyRequest.Signed, its ID
+// and the CreatedAPIKey.SigningSecret returned by CreateAPIKey, instead of sending a token or the
+// key. Every attempt of a request gets a new timestamp and nonce, so the server's clock must be
+// within its allowed skew.
+func WithSigningKey(id, secret string) Option {
+	return func(c *Client) { c.signingKey = &signingKey{id: id, secret: secret} }
+}
+
+// WithAdminToken sets the ADMIN_TOKEN presented to the admin endpoints, see AdminStats
+func WithAdminToken(token string) Option {
+	return func(c *Client) { c.adminToken = token }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy, RetryPolicy{} disables retries
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// WithUserAgent sets the User-Agent header of the requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New returns a client of the API at baseURL, e.g. http://localhost:7000. A baseURL that does not
+// parse fails every request.
+func New(baseURL string, opts ...Option) *Client {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		u = &url.URL{Opaque: baseURL}
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "baw-api-client",
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token returns the token the requests are signed in with, set by Login or WithToken
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken signs the following requests in with token, "" signs them out
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// auth selects the credentials of a request
+type auth int
+
+const (
+	noAuth auth = iota
+	userAuth
+	adminAuth
+)
+
+// request describes a call of the API. Body is sent as JSON.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	auth   auth
+}
+
+// envelope is the body of every JSON response
+type envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *APIError       `json:"error"`
+	Meta  Meta            `json:"meta"`
+}
+
+// do calls the API and decodes the data of the response into out, unless out is nil
+func (c *Client) do(ctx context.Context, req request, out any) (Meta, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return Meta{}, err
+	}
+	defer resp.Body.Close()
+
+	// 204 responses have no body to decode
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil && !errors.Is(err, io.EOF) && resp.StatusCode < 300 {
+		return Meta{}, fmt.Errorf("failed to decode response of %s %s: %w", req.method, req.path, err)
+	}
+	if resp.StatusCode >= 300 {
+		return env.Meta, apiError(resp, env)
+	}
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return env.Meta, fmt.Errorf("failed to decode response of %s %s: %w", req.method, req.path, err)
+		}
+	}
+	return env.Meta, nil
+}
+
+// send sends the request, retrying it as set by the retry policy. The caller closes the body of
+// the response.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	retryable := req.method == http.MethodGet || req.method == http.MethodPut || req.method == http.MethodDelete
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("User-Agent", c.userAgent)
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		switch req.auth {
+		case userAuth:
+			// The API takes the token itself, without a scheme
+			if token := c.Token(); token != "" {
+				httpReq.Header.Set("Authorization", token)
+			}
+			if c.apiKey != "" {
+				httpReq.Header.Set("X-API-Key", c.apiKey)
+			}
+			if c.signingKey != nil {
+				if err := c.signingKey.sign(httpReq, body); err != nil {
+					return nil, err
+				}
+			}
+		case adminAuth:
+			httpReq.Header.Set("Authorization", "Bearer "+c.adminToken)
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if !retryable || attempt >= c.retry.MaxRetries || !temporary(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// temporary reports whether a retry may succeed where resp or err failed
+func temporary(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff is the delay before retry attempt+1, doubling from BaseDelay up to MaxDelay with up to
+// 20% jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.retry.BaseDelay << attempt
+	if d <= 0 || (c.retry.MaxDelay > 0 && d > c.retry.MaxDelay) {
+		d = c.retry.MaxDelay
+	}
+	return d + time.Duration(rand.Int64N(int64(d)/5+1))
+}
+
+// retryAfter reads the Retry-After header given in seconds
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	s, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || s < 0 {
+		return 0, false
+	}
+	return time.Duration(s) * time.Second, true
+}
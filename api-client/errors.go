@@ -0,0 +1,66 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// APIError is an error response of the API. Code is derived from the HTTP status, e.g.
+// "not_found", and Fields maps invalid request fields to their validation errors.
+type APIError struct {
+	StatusCode int               `json:"-"`
+	Code       string            `json:"code"`
+	Message    string            `json:"message"`
+	Details    string            `json:"details,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	// RequestID is the trace ID of the request, to quote when reporting a problem
+	RequestID string `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "api: %d %s", e.StatusCode, e.Message)
+	if e.Details != "" {
+		b.WriteString(": " + e.Details)
+	}
+	for _, field := range slices.Sorted(maps.Keys(e.Fields)) {
+		fmt.Fprintf(&b, "; %s: %s", field, e.Fields[field])
+	}
+	return b.String()
+}
+
+// IsNotFound reports whether err is a 404 response of the API
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a 401 response of the API, e.g. for an expired token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsConflict reports whether err is a 409 response of the API, e.g. for an update based on a
+// stale version
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// apiError is the error of a response with status 300 or above, from its envelope when it has one
+func apiError(resp *http.Response, env envelope) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	if env.Error != nil {
+		apiErr = env.Error
+		apiErr.StatusCode = resp.StatusCode
+	}
+	apiErr.RequestID = env.Meta.RequestID
+	return apiErr
+}
@@ -0,0 +1,3 @@
+module github.com/ahsansaif47/blockchain-address-watcher/api-client
+
+go 1.25.5
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListJobsOptions selects the jobs listed. Limit is the page size, 0 taking the server's default.
+type ListJobsOptions struct {
+	Limit int
+}
+
+// ListJobs returns a page of the user's background jobs, newest first, and its pagination
+func (c *Client) ListJobs(ctx context.Context, opts ListJobsOptions) ([]Job, *Pagination, error) {
+	jobs, meta, err := c.listJobs(ctx, opts, "")
+	return jobs, meta.Pagination, err
+}
+
+// Jobs iterates over the user's background jobs, newest first, fetching the pages as they are
+// reached
+func (c *Client) Jobs(ctx context.Context, opts ListJobsOptions) iter.Seq2[Job, error] {
+	return paginate(ctx, func(ctx context.Context, cursor string) ([]Job, Meta, error) {
+		return c.listJobs(ctx, opts, cursor)
+	})
+}
+
+func (c *Client) listJobs(ctx context.Context, opts ListJobsOptions, cursor string) ([]Job, Meta, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var jobs []Job
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/jobs", query: withCursor(q, cursor), auth: userAuth}, &jobs)
+	return jobs, meta, err
+}
+
+// Job returns the user's background job id
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+	var res Job
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/jobs/" + url.PathEscape(id), auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WaitForJob polls the job id every interval until it is done, returning it as finished. A job
+// that failed is returned without an error, see Job.Status and Job.LastError.
+func (c *Client) WaitForJob(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil || job.Done() {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
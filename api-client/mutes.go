@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MuteAddress silences the notifications of the alerts of the user's address id until until, or
+// until it is unmuted when until is zero. Its alerts are still recorded.
+func (c *Client) MuteAddress(ctx context.Context, id string, until time.Time) (*Mute, error) {
+	return c.mute(ctx, http.MethodPost, "/api/v1/addresses/"+url.PathEscape(id)+"/mute", until)
+}
+
+// UnmuteAddress resumes the notifications of the alerts of the user's address id
+func (c *Client) UnmuteAddress(ctx context.Context, id string) (*Mute, error) {
+	return c.mute(ctx, http.MethodDelete, "/api/v1/addresses/"+url.PathEscape(id)+"/mute", time.Time{})
+}
+
+// MuteRule silences the notifications of the alerts of the user's rule id, like MuteAddress
+func (c *Client) MuteRule(ctx context.Context, id string, until time.Time) (*Mute, error) {
+	return c.mute(ctx, http.MethodPost, "/api/v1/rules/"+url.PathEscape(id)+"/mute", until)
+}
+
+// UnmuteRule resumes the notifications of the alerts of the user's rule id
+func (c *Client) UnmuteRule(ctx context.Context, id string) (*Mute, error) {
+	return c.mute(ctx, http.MethodDelete, "/api/v1/rules/"+url.PathEscape(id)+"/mute", time.Time{})
+}
+
+func (c *Client) mute(ctx context.Context, method, path string, until time.Time) (*Mute, error) {
+	q := url.Values{}
+	if !until.IsZero() {
+		q.Set("until", until.UTC().Format(time.RFC3339))
+	}
+	var res Mute
+	if _, err := c.do(ctx, request{method: method, path: path, query: q, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
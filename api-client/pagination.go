@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+)
+
+// paginate yields the items of a list page by page, following the next cursor of each page. fetch
+// returns the page after cursor, "" for the first. The iteration stops after the first error.
+func paginate[T any](ctx context.Context, fetch func(ctx context.Context, cursor string) ([]T, Meta, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		cursor := ""
+		for {
+			items, meta, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if meta.Pagination == nil || meta.Pagination.NextCursor == "" || meta.Pagination.NextCursor == cursor {
+				return
+			}
+			cursor = meta.Pagination.NextCursor
+		}
+	}
+}
+
+// withCursor returns q with the cursor of the page to fetch, unless it is the first
+func withCursor(q url.Values, cursor string) url.Values {
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	return q
+}
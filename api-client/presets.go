@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// TokenPresets returns the built-in lists of token contracts per chain a rule can filter its
+// transfers to, such as the major stablecoins
+func (c *Client) TokenPresets(ctx context.Context) ([]TokenPreset, error) {
+	var res []TokenPreset
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/token-presets", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SetRuleTokenPreset makes the user's rule id only alert on transfers of the contracts of the
+// preset named preset, e.g. "stablecoins", on its address's chain. Rules filtering on a single
+// token contract cannot take a preset.
+func (c *Client) SetRuleTokenPreset(ctx context.Context, id, preset string) (*RuleTokenPreset, error) {
+	return c.ruleTokenPreset(ctx, http.MethodPut, id, map[string]string{"preset": preset})
+}
+
+// ClearRuleTokenPreset makes the user's rule id alert on transfers of any token again
+func (c *Client) ClearRuleTokenPreset(ctx context.Context, id string) (*RuleTokenPreset, error) {
+	return c.ruleTokenPreset(ctx, http.MethodDelete, id, nil)
+}
+
+func (c *Client) ruleTokenPreset(ctx context.Context, method, id string, body any) (*RuleTokenPreset, error) {
+	var res RuleTokenPreset
+	path := "/api/v1/rules/" + url.PathEscape(id) + "/token-preset"
+	if _, err := c.do(ctx, request{method: method, path: path, body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Rules returns the user's alert rules, simple and advanced, oldest first
+func (c *Client) Rules(ctx context.Context) ([]Rule, error) {
+	var res []Rule
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/rules", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Rule returns the user's alert rule id
+func (c *Client) Rule(ctx context.Context, id string) (*Rule, error) {
+	return c.rule(ctx, http.MethodGet, id, nil)
+}
+
+// CreateRule creates a simple alert rule. Once the user has rules applying to an address, only
+// its transfers matching one of them are notified.
+func (c *Client) CreateRule(ctx context.Context, req CreateRuleRequest) (*Rule, error) {
+	var res Rule
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/rules", body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateRule replaces the conditions of the user's rule id. It fails with a conflict (see
+// IsConflict) when the rule changed since req.Version was read.
+func (c *Client) UpdateRule(ctx context.Context, id string, req UpdateRuleRequest) (*Rule, error) {
+	return c.rule(ctx, http.MethodPut, id, req)
+}
+
+// DeleteRule deletes the user's rule id, returning it as it was
+func (c *Client) DeleteRule(ctx context.Context, id string) (*Rule, error) {
+	return c.rule(ctx, http.MethodDelete, id, nil)
+}
+
+func (c *Client) rule(ctx context.Context, method, id string, body any) (*Rule, error) {
+	var res Rule
+	path := "/api/v1/rules/" + url.PathEscape(id)
+	if _, err := c.do(ctx, request{method: method, path: path, body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RuleTemplates returns the ready-made advanced rules, such as large outgoing transfers and
+// exchange deposits
+func (c *Client) RuleTemplates(ctx context.Context) ([]RuleTemplate, error) {
+	var res []RuleTemplate
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/rules/templates", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateRuleFromTemplate creates an enabled advanced rule for the user from the template named
+// name, e.g. "large-outgoing-transfer"
+func (c *Client) CreateRuleFromTemplate(ctx context.Context, name string, req CreateRuleFromTemplateRequest) (*TemplateRule, error) {
+	var res TemplateRule
+	path := "/api/v1/rules/templates/" + url.PathEscape(name)
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: path, body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetRuleExpression makes the user's rule id an advanced rule whose condition is expression, a CEL
+// expression over tx and known, e.g.
+// tx.value_usd > 10000 && tx.direction == "out" && !(tx.counterparty in known.exchanges).
+// Expressions that do not compile fail with a bad request naming the position of the mistake.
+func (c *Client) SetRuleExpression(ctx context.Context, id, expression string) (*RuleExpression, error) {
+	return c.ruleExpression(ctx, http.MethodPut, id, map[string]string{"expression": expression})
+}
+
+// ClearRuleExpression makes the user's rule id a simple rule again, evaluated by its direction,
+// min_value and token filters
+func (c *Client) ClearRuleExpression(ctx context.Context, id string) (*RuleExpression, error) {
+	return c.ruleExpression(ctx, http.MethodDelete, id, nil)
+}
+
+// SetRulePriority sets the order the user's rule id is evaluated in among the rules applying to a
+// transaction, from -1000 to 1000, higher first. When stopProcessing is set and the rule matches,
+// the rules after it are not evaluated.
+func (c *Client) SetRulePriority(ctx context.Context, id string, priority int32, stopProcessing bool) (*RulePriority, error) {
+	var res RulePriority
+	body := map[string]any{"priority": priority, "stop_processing": stopProcessing}
+	path := "/api/v1/rules/" + url.PathEscape(id) + "/priority"
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: path, body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) ruleExpression(ctx context.Context, method, id string, body any) (*RuleExpression, error) {
+	var res RuleExpression
+	path := "/api/v1/rules/" + url.PathEscape(id) + "/expression"
+	if _, err := c.do(ctx, request{method: method, path: path, body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Search matches query against the words of the user's addresses and the known entities, as word
+// prefixes. limit caps the results per kind, 0 takes the server's default.
+func (c *Client) Search(ctx context.Context, query string, limit int) (*SearchResults, error) {
+	q := url.Values{"q": {query}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var res SearchResults
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/search", query: q, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
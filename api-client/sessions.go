@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Sessions lists the signed-in user's active sessions, one per login
+func (c *Client) Sessions(ctx context.Context) ([]Session, error) {
+	var res []Session
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me/sessions", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RevokeSession signs out the session id, its token is rejected from its next request on
+func (c *Client) RevokeSession(ctx context.Context, id string) error {
+	path := "/api/v1/users/me/sessions/" + url.PathEscape(id)
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: path, auth: userAuth}, nil)
+	return err
+}
+
+// RevokeOtherSessions signs out every session of the user but the client's own
+func (c *Client) RevokeOtherSessions(ctx context.Context) (*RevokedSessions, error) {
+	var res RevokedSessions
+	if _, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/users/me/sessions", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
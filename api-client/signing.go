@@ -0,0 +1,40 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// signingKey is an API key signing the requests, see WithSigningKey
+type signingKey struct {
+	id     string
+	secret string
+}
+
+// sign sets the X-Key-ID, X-Timestamp, X-Nonce and X-Signature headers of req with body: "v1=" and
+// the hex HMAC-SHA256 of the method, path and query, timestamp, nonce and hex SHA-256 of the
+// body, joined by newlines
+func (k *signingKey) sign(req *http.Request, body []byte) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	digest := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(k.secret))
+	mac.Write([]byte(req.Method + "\n" + req.URL.RequestURI() + "\n" + timestamp + "\n" + hex.EncodeToString(nonce) + "\n"))
+	mac.Write([]byte(hex.EncodeToString(digest[:])))
+
+	req.Header.Set("X-Key-ID", k.id)
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Nonce", hex.EncodeToString(nonce))
+	req.Header.Set("X-Signature", "v1="+hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
@@ -0,0 +1,719 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Meta describes a response. RequestID is the trace ID of the request.
+type Meta struct {
+	RequestID  string      `json:"request_id"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the page of a list response. NextCursor is set when more items follow.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type RegisterRequest struct {
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	PhoneNo       string `json:"phone_no"`
+	WalletAddress string `json:"wallet_address,omitempty"`
+	Subscribed    bool   `json:"subscribed"`
+}
+
+// LoginResponse is the access token of a session, valid until ExpiresAt, and the refresh token to
+// exchange for the next one with Refresh, valid until RefreshExpiresAt
+type LoginResponse struct {
+	ID               string    `json:"id"`
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	SessionID        string    `json:"session_id"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type User struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	PhoneNo       string    `json:"phone_no"`
+	WalletAddress string    `json:"wallet_address"`
+	Subscribed    bool      `json:"subscribed"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int32     `json:"version"`
+}
+
+// UpdateProfileRequest replaces the user's profile. Version is the version of the profile the
+// change is based on, the update fails with a conflict (see IsConflict) when it has changed since.
+type UpdateProfileRequest struct {
+	PhoneNo       string `json:"phone_no"`
+	WalletAddress string `json:"wallet_address"`
+	Subscribed    bool   `json:"subscribed"`
+	Version       int32  `json:"version"`
+}
+
+// DeleteResult is the outcome of DeleteAccount. JobID is the job purging the user on a hard
+// delete, see Job.
+type DeleteResult struct {
+	Message string `json:"message"`
+	JobID   string `json:"job_id,omitempty"`
+}
+
+// Job is a background job of the user. Result is set once the job succeeded, LastError after a
+// failed attempt.
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Done reports whether the job finished, successfully or not
+func (j Job) Done() bool {
+	return j.Status == "succeeded" || j.Status == "failed"
+}
+
+// SearchResults holds the matches of a search, best first. Headlines are HTML-escaped text with
+// the matched words wrapped in <mark> tags.
+type SearchResults struct {
+	Addresses []AddressMatch `json:"addresses"`
+	Entities  []EntityMatch  `json:"entities"`
+}
+
+type AddressMatch struct {
+	ID         string  `json:"id"`
+	Chain      string  `json:"chain"`
+	Address    string  `json:"address"`
+	Label      string  `json:"label,omitempty"`
+	Notes      string  `json:"notes,omitempty"`
+	EntityName string  `json:"entity_name,omitempty"`
+	Rank       float32 `json:"rank"`
+	Headline   string  `json:"headline"`
+}
+
+type EntityMatch struct {
+	ID       string  `json:"id"`
+	Chain    string  `json:"chain"`
+	Address  string  `json:"address"`
+	Name     string  `json:"name"`
+	Category string  `json:"category,omitempty"`
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
+
+// Analytics is the user's API usage of the UTC days From to To (YYYY-MM-DD): per endpoint over
+// the whole period, and per day for the days with calls, oldest first
+type Analytics struct {
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Endpoints []EndpointUsage `json:"endpoints"`
+	Days      []DailyUsage    `json:"days"`
+}
+
+type DailyUsage struct {
+	Date         string          `json:"date"`
+	Calls        int64           `json:"calls"`
+	ClientErrors int64           `json:"client_errors"`
+	ServerErrors int64           `json:"server_errors"`
+	Endpoints    []EndpointUsage `json:"endpoints"`
+}
+
+// EndpointUsage counts the calls of an endpoint, Route being its pattern, e.g. /api/v1/jobs/:id
+type EndpointUsage struct {
+	Method       string  `json:"method"`
+	Route        string  `json:"route"`
+	Calls        int64   `json:"calls"`
+	ClientErrors int64   `json:"client_errors"`
+	ServerErrors int64   `json:"server_errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs float64 `json:"max_latency_ms"`
+}
+
+// ArchivedAlert is an alert read back from the archive, with the transfer that triggered it
+// while that was still stored
+type ArchivedAlert struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	AddressID      string     `json:"address_id"`
+	RuleID         *string    `json:"rule_id"`
+	TransactionID  *string    `json:"transaction_id"`
+	Message        string     `json:"message"`
+	Severity       string     `json:"severity"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Transfer       *Transfer  `json:"transfer,omitempty"`
+}
+
+// Transfer is a transfer of a watched address. Value is in the token's base unit, e.g. wei.
+type Transfer struct {
+	Chain        string      `json:"chain"`
+	Hash         string      `json:"hash"`
+	LogIndex     *int32      `json:"log_index"`
+	FromAddress  string      `json:"from_address"`
+	ToAddress    *string     `json:"to_address"`
+	Value        json.Number `json:"value"`
+	TokenAddress *string     `json:"token_address"`
+	Status       string      `json:"status"`
+	OccurredAt   *time.Time  `json:"occurred_at"`
+}
+
+// ImpersonateRequest asks for a token acting as a user, to look into Reason. The token is valid for
+// TTLMinutes, at most 60, or 15 when zero.
+type ImpersonateRequest struct {
+	Reason     string `json:"reason"`
+	TTLMinutes int    `json:"ttl_minutes,omitempty"`
+}
+
+// Impersonation is a token acting as a user, for a client created WithToken. It stops working
+// when its session SessionID is revoked, see RevokeUserSession.
+type Impersonation struct {
+	Token     string    `json:"token"`
+	TokenID   string    `json:"token_id"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	Operator  string    `json:"operator"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AdminStats is the operations dashboard snapshot. The alert and notification figures cover the
+// last 24 hours.
+type AdminStats struct {
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Users         int64             `json:"users"`
+	Addresses     AddressStats      `json:"addresses"`
+	Alerts24h     int64             `json:"alerts_24h"`
+	Notifications NotificationStats `json:"notifications"`
+	Chains        []ChainHeadStats  `json:"chains"`
+	Consumer      ConsumerStats     `json:"consumer"`
+}
+
+type AddressStats struct {
+	Total   int64               `json:"total"`
+	ByChain []ChainAddressStats `json:"by_chain"`
+}
+
+type ChainAddressStats struct {
+	Chain     string `json:"chain"`
+	Addresses int64  `json:"addresses"`
+	Users     int64  `json:"users"`
+}
+
+// NotificationStats counts notification deliveries by state. FailureRate is the share of finished
+// deliveries that failed. Skipped deliveries were not sent on purpose, e.g. because their address
+// was muted.
+type NotificationStats struct {
+	Sent        int64                  `json:"sent"`
+	Failed      int64                  `json:"failed"`
+	Pending     int64                  `json:"pending"`
+	Skipped     int64                  `json:"skipped"`
+	FailureRate float64                `json:"failure_rate"`
+	ByChannel   []ChannelDeliveryStats `json:"by_channel"`
+}
+
+type ChannelDeliveryStats struct {
+	Channel     string  `json:"channel"`
+	Sent        int64   `json:"sent"`
+	Failed      int64   `json:"failed"`
+	Pending     int64   `json:"pending"`
+	Skipped     int64   `json:"skipped"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+type ChainHeadStats struct {
+	Chain          string     `json:"chain"`
+	HeadBlock      *int64     `json:"head_block,omitempty"`
+	HeadTime       *time.Time `json:"head_time,omitempty"`
+	HeadLagSeconds *float64   `json:"head_lag_seconds,omitempty"`
+}
+
+type ConsumerStats struct {
+	Transport string     `json:"transport,omitempty"`
+	Lag       *int64     `json:"lag,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Mute is the mute state of an address or rule. MutedUntil is nil while it is muted until unmuted.
+type Mute struct {
+	ID         string     `json:"id"`
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+}
+
+// TokenPreset is a built-in list of token contracts, by the chain of the addresses it applies to
+type TokenPreset struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Chains      map[string][]Token `json:"chains"`
+}
+
+// Token is a token contract of a preset
+type Token struct {
+	Symbol  string `json:"symbol"`
+	Address string `json:"address"`
+}
+
+// Rule is an alert rule. Expression is set for advanced rules, whose condition it is; simple rules
+// match the transfers meeting every condition set.
+type Rule struct {
+	ID              string     `json:"id"`
+	AddressID       string     `json:"address_id,omitempty"`
+	Name            string     `json:"name"`
+	Direction       string     `json:"direction"`
+	MinValue        string     `json:"min_value"`
+	TokenAddress    string     `json:"token_address,omitempty"`
+	TokenPreset     string     `json:"token_preset,omitempty"`
+	Counterparties  []string   `json:"counterparties,omitempty"`
+	Expression      string     `json:"expression,omitempty"`
+	CooldownSeconds int32      `json:"cooldown_seconds"`
+	Severity        string     `json:"severity"`
+	Priority        int32      `json:"priority"`
+	StopProcessing  bool       `json:"stop_processing"`
+	Enabled         bool       `json:"enabled"`
+	MutedAt         *time.Time `json:"muted_at,omitempty"`
+	MutedUntil      *time.Time `json:"muted_until,omitempty"`
+	Version         int32      `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// CreateRuleRequest creates a simple rule for AddressID, or every address of the user when empty.
+// Direction is in, out or any (default); MinValue is a decimal integer in the token's smallest
+// unit; TokenAddress is empty for the chain's native currency; Counterparties are the addresses on
+// the other side of the transfers, any when empty. Enabled defaults to true.
+type CreateRuleRequest struct {
+	AddressID       string   `json:"address_id,omitempty"`
+	Name            string   `json:"name"`
+	Direction       string   `json:"direction,omitempty"`
+	MinValue        string   `json:"min_value,omitempty"`
+	TokenAddress    string   `json:"token_address,omitempty"`
+	Counterparties  []string `json:"counterparties,omitempty"`
+	CooldownSeconds int32    `json:"cooldown_seconds,omitempty"`
+	Severity        string   `json:"severity,omitempty"`
+	Enabled         *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateRuleRequest replaces the conditions of a rule. Version is the rule's version as last read.
+type UpdateRuleRequest struct {
+	Name            string   `json:"name"`
+	Direction       string   `json:"direction,omitempty"`
+	MinValue        string   `json:"min_value,omitempty"`
+	TokenAddress    string   `json:"token_address,omitempty"`
+	Counterparties  []string `json:"counterparties,omitempty"`
+	CooldownSeconds int32    `json:"cooldown_seconds,omitempty"`
+	Severity        string   `json:"severity,omitempty"`
+	Enabled         *bool    `json:"enabled,omitempty"`
+	Version         int32    `json:"version"`
+}
+
+// RuleTokenPreset is the token preset of a rule, empty when its transfers are not filtered by one
+type RuleTokenPreset struct {
+	ID          string `json:"id"`
+	TokenPreset string `json:"token_preset,omitempty"`
+}
+
+// RuleTemplate is a ready-made advanced rule. Its expression has a {name} placeholder for each of
+// its parameters.
+type RuleTemplate struct {
+	Name        string              `json:"name"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Severity    string              `json:"severity"`
+	Expression  string              `json:"expression"`
+	Params      []RuleTemplateParam `json:"params,omitempty"`
+}
+
+// RuleTemplateParam is a number filled into a template's expression
+type RuleTemplateParam struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Default     float64 `json:"default"`
+	Min         float64 `json:"min"`
+}
+
+// CreateRuleFromTemplateRequest creates a rule from a template for AddressID, or every address of
+// the user when empty. Name and Severity default to the template's, and Params to the defaults of
+// its parameters.
+type CreateRuleFromTemplateRequest struct {
+	AddressID string             `json:"address_id,omitempty"`
+	Name      string             `json:"name,omitempty"`
+	Severity  string             `json:"severity,omitempty"`
+	Params    map[string]float64 `json:"params,omitempty"`
+}
+
+// TemplateRule is a rule created from a template
+type TemplateRule struct {
+	ID         string `json:"id"`
+	Template   string `json:"template"`
+	AddressID  string `json:"address_id,omitempty"`
+	Name       string `json:"name"`
+	Severity   string `json:"severity"`
+	Expression string `json:"expression"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// RuleExpression is the CEL expression of an advanced rule, empty for simple rules
+type RuleExpression struct {
+	ID         string `json:"id"`
+	Expression string `json:"expression,omitempty"`
+}
+
+// RulePriority is the order a rule is evaluated in, and whether matching it suppresses the rules
+// after it
+type RulePriority struct {
+	ID             string `json:"id"`
+	Priority       int32  `json:"priority"`
+	StopProcessing bool   `json:"stop_processing"`
+}
+
+// Fees are the current fee estimates of a chain: EIP-1559 fees in wei on Ethereum-compatible
+// chains, GasPrice instead on chains without EIP-1559, and fee rates in sat/vB on Bitcoin.
+// BaseFee is the base fee of the next block.
+type Fees struct {
+	Chain     string    `json:"chain"`
+	BaseFee   string    `json:"base_fee,omitempty"`
+	Slow      FeeTier   `json:"slow"`
+	Standard  FeeTier   `json:"standard"`
+	Fast      FeeTier   `json:"fast"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// FeeTier is the fee to pay for a transaction to be included slowly, normally or fast.
+// TargetBlocks is the number of blocks a Bitcoin transaction is expected to confirm within.
+type FeeTier struct {
+	MaxPriorityFee string   `json:"max_priority_fee,omitempty"`
+	MaxFee         string   `json:"max_fee,omitempty"`
+	GasPrice       string   `json:"gas_price,omitempty"`
+	SatPerVByte    *float64 `json:"sat_per_vbyte,omitempty"`
+	TargetBlocks   int      `json:"target_blocks,omitempty"`
+}
+
+// TransactionStatus is the state of a transaction on chain. Status is pending until it is in a
+// block, then confirmed, or failed when it reverted. Final is set once the block has the
+// confirmations the watcher waits for. Values are in the token's base unit.
+type TransactionStatus struct {
+	Chain         string            `json:"chain"`
+	Hash          string            `json:"hash"`
+	Status        string            `json:"status"`
+	BlockNumber   *int64            `json:"block_number,omitempty"`
+	BlockHash     string            `json:"block_hash,omitempty"`
+	Confirmations int64             `json:"confirmations"`
+	Final         bool              `json:"final"`
+	From          string            `json:"from,omitempty"`
+	To            string            `json:"to,omitempty"`
+	Value         string            `json:"value"`
+	Fee           string            `json:"fee,omitempty"`
+	Transfers     []TransferSummary `json:"transfers"`
+}
+
+// TransferSummary is a movement of value in a transaction: its native value, an ERC-20 transfer or
+// a Bitcoin output. Token is empty for the native currency.
+type TransferSummary struct {
+	Token string `json:"token,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+}
+
+// Address is a watched address. Label is its nickname. MutedUntil is set when it is muted until a
+// time, MutedAt whenever it is muted.
+type Address struct {
+	ID         string     `json:"id"`
+	Chain      string     `json:"chain"`
+	Address    string     `json:"address"`
+	Label      string     `json:"label,omitempty"`
+	Notes      string     `json:"notes,omitempty"`
+	MutedAt    *time.Time `json:"muted_at,omitempty"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+	Version    int32      `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateAddressRequest watches Address on Chain, with an optional Label and Notes. Chain is one of
+// ethereum, polygon, arbitrum, optimism, base, bsc, avalanche, bitcoin and solana, and Address must
+// have its format.
+type CreateAddressRequest struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// UpdateAddressRequest replaces the label and notes of an address. Version is the version of the
+// address the change is based on, as last read.
+type UpdateAddressRequest struct {
+	Label   string `json:"label,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+	Version int32  `json:"version"`
+}
+
+// AddressStatus is the state of the engine's watch of an address. State is pending until the
+// engine picks the address up, watching once it has, and error while scans of its chain fail.
+// Mode is subscription or polling.
+type AddressStatus struct {
+	AddressID        string               `json:"address_id"`
+	Chain            string               `json:"chain"`
+	Address          string               `json:"address"`
+	State            string               `json:"state"`
+	Mode             string               `json:"mode,omitempty"`
+	WatchingSince    *time.Time           `json:"watching_since,omitempty"`
+	LastScannedBlock *int64               `json:"last_scanned_block,omitempty"`
+	LastScannedAt    *time.Time           `json:"last_scanned_at,omitempty"`
+	LastActivity     *AddressLastActivity `json:"last_activity,omitempty"`
+	LastError        *AddressWatcherError `json:"last_error,omitempty"`
+}
+
+// AddressLastActivity is the last transfer of the address the engine saw, At being its block time
+type AddressLastActivity struct {
+	BlockNumber int64     `json:"block_number"`
+	Hash        string    `json:"hash"`
+	At          time.Time `json:"at"`
+}
+
+// AddressWatcherError is the last failure watching the address, Count the failures since the
+// last successful scan
+type AddressWatcherError struct {
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+	Count   int32     `json:"count"`
+}
+
+// ActivityItem is an entry of an address's activity. Type is transaction, alert or change, and
+// tells which of Transaction, Alert and Change is set. At is when the entry was recorded.
+type ActivityItem struct {
+	Type        string               `json:"type"`
+	ID          string               `json:"id"`
+	At          time.Time            `json:"at"`
+	Transaction *ActivityTransaction `json:"transaction,omitempty"`
+	Alert       *ActivityAlert       `json:"alert,omitempty"`
+	Change      *ActivityChange      `json:"change,omitempty"`
+}
+
+// ActivityTransaction is a transfer to or from the address. Direction is in, out or self; Value is
+// in the token's smallest unit and Token empty for the native currency.
+type ActivityTransaction struct {
+	Chain       string    `json:"chain"`
+	Hash        string    `json:"hash"`
+	LogIndex    int32     `json:"log_index"`
+	BlockNumber int64     `json:"block_number"`
+	From        string    `json:"from"`
+	To          string    `json:"to,omitempty"`
+	Direction   string    `json:"direction"`
+	Value       string    `json:"value"`
+	Token       string    `json:"token,omitempty"`
+	Status      string    `json:"status"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ListTransactionsOptions selects the transactions listed. Limit is the page size, 0 taking the
+// server's default.
+type ListTransactionsOptions struct {
+	Limit int
+}
+
+// AddressTransaction is a transfer in an address's transaction history. A transaction moving
+// several tokens has one entry per transfer, told apart by LogIndex.
+type AddressTransaction struct {
+	ID string `json:"id"`
+	ActivityTransaction
+	// DetectedAt is when the transfer was first stored, which orders the history
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// ActivityAlert is an alert raised for the address. Alerts of balance changes have no
+// TransactionID.
+type ActivityAlert struct {
+	RuleID         string     `json:"rule_id,omitempty"`
+	TransactionID  string     `json:"transaction_id,omitempty"`
+	Message        string     `json:"message"`
+	Severity       string     `json:"severity"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// ActivityChange is a change of the address or of a rule applying to it, e.g. address.muted or
+// rule.paused, with what the kind records in Detail
+type ActivityChange struct {
+	Kind   string          `json:"kind"`
+	RuleID string          `json:"rule_id,omitempty"`
+	Detail json.RawMessage `json:"detail,omitempty"`
+}
+
+// AccountActivityItem is an entry of the account's activity, e.g. login, login.failed,
+// password.changed, webhook.created or address.deleted. IPAddress and UserAgent are the client's
+// where recorded, Detail holds what the kind records, such as a webhook's URL.
+type AccountActivityItem struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	At        time.Time       `json:"at"`
+	AddressID string          `json:"address_id,omitempty"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+}
+
+// CreateBalanceWatchRequest polls the balance of an ERC-20 token every IntervalSeconds, 300 when
+// zero, alerting when it moved by at least MinDelta, a base 10 amount in the token's smallest
+// unit. Severity defaults to warning, and Enabled to true.
+type CreateBalanceWatchRequest struct {
+	TokenAddress    string `json:"token_address"`
+	IntervalSeconds int32  `json:"interval_seconds,omitempty"`
+	MinDelta        string `json:"min_delta,omitempty"`
+	Severity        string `json:"severity,omitempty"`
+	Enabled         *bool  `json:"enabled,omitempty"`
+}
+
+// UpdateBalanceWatchRequest replaces the settings of a balance watch; all fields are required
+type UpdateBalanceWatchRequest struct {
+	IntervalSeconds int32  `json:"interval_seconds"`
+	MinDelta        string `json:"min_delta"`
+	Severity        string `json:"severity"`
+	Enabled         bool   `json:"enabled"`
+}
+
+// BalanceWatch is a token balance polled with balanceOf. Balance, read at BlockNumber, is the one
+// deltas are measured from: the first read, then the one of the last alert. LastError is why the
+// last check failed.
+type BalanceWatch struct {
+	ID              string     `json:"id"`
+	AddressID       string     `json:"address_id"`
+	TokenAddress    string     `json:"token_address"`
+	IntervalSeconds int32      `json:"interval_seconds"`
+	MinDelta        string     `json:"min_delta"`
+	Severity        string     `json:"severity"`
+	Enabled         bool       `json:"enabled"`
+	Balance         string     `json:"balance,omitempty"`
+	BlockNumber     *int64     `json:"block_number,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"`
+	NextCheckAt     time.Time  `json:"next_check_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Channel is a notification channel of the user, "email" or "webhook", and whether it is verified.
+// Alerts are only delivered over verified channels.
+type Channel struct {
+	Channel    string     `json:"channel"`
+	Target     string     `json:"target"`
+	WebhookID  string     `json:"webhook_id,omitempty"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// Dashboard summarizes the user's watch list. Portfolio is nil when the server's node cannot read
+// balances, e.g. a Bitcoin node.
+type Dashboard struct {
+	Addresses    DashboardAddresses `json:"addresses"`
+	Alerts       DashboardAlerts    `json:"alerts"`
+	TopAddresses []DashboardAddress `json:"top_addresses"`
+	Portfolio    *Portfolio         `json:"portfolio,omitempty"`
+	GeneratedAt  time.Time          `json:"generated_at"`
+}
+
+// DashboardAddresses counts the watched addresses; muted ones do not notify until their mute ends
+type DashboardAddresses struct {
+	Watched int64 `json:"watched"`
+	Active  int64 `json:"active"`
+	Muted   int64 `json:"muted"`
+}
+
+// DashboardAlerts counts the alerts raised in the last 24 hours and 7 days
+type DashboardAlerts struct {
+	LastDay  int64 `json:"last_day"`
+	LastWeek int64 `json:"last_week"`
+}
+
+// DashboardAddress is one of the addresses with the most transactions in the last 7 days
+type DashboardAddress struct {
+	ID                string    `json:"id"`
+	Chain             string    `json:"chain"`
+	Address           string    `json:"address"`
+	Label             string    `json:"label,omitempty"`
+	Transactions      int64     `json:"transactions"`
+	Alerts            int64     `json:"alerts"`
+	LastTransactionAt time.Time `json:"last_transaction_at"`
+}
+
+// Portfolio is the native currency held by the addresses watched on Chain, in its smallest unit
+type Portfolio struct {
+	Chain     string `json:"chain"`
+	Value     string `json:"value"`
+	Addresses int    `json:"addresses"`
+}
+
+// Scopes of API keys, each granting a key the endpoints of one kind of record
+const (
+	ScopeReadAccount    = "read:account"
+	ScopeWriteAccount   = "write:account"
+	ScopeReadAddresses  = "read:addresses"
+	ScopeWriteAddresses = "write:addresses"
+	ScopeReadRules      = "read:rules"
+	ScopeWriteRules     = "write:rules"
+	ScopeReadAlerts     = "read:alerts"
+)
+
+// CreateAPIKeyRequest creates an API key named Name with at least one of the Scope constants,
+// valid for ExpiresInDays or until revoked when zero. AllowedCIDRs are the networks the key is
+// accepted from, e.g. 203.0.113.0/24 or a single address; any when empty. A Signed key signs the
+// requests instead of being sent, see WithSigningKey.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	Signed        bool     `json:"signed,omitempty"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+	AllowedCIDRs  []string `json:"allowed_cidrs,omitempty"`
+}
+
+// APIKey is an API key of the user, identified by Prefix, the start of the key
+type APIKey struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	Scopes       []string   `json:"scopes"`
+	AllowedCIDRs []string   `json:"allowed_cidrs"`
+	Signed       bool       `json:"signed"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKey is a new API key with the key itself, which is not returned again. A signed key
+// comes with the SigningSecret its requests are signed with, not returned again either.
+type CreatedAPIKey struct {
+	APIKey
+	Key           string `json:"key"`
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// Session is a sign-in of the user, Current is set on the session of the client's token
+type Session struct {
+	ID         string    `json:"id"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RevokedSessions lists the sessions signed out by RevokeOtherSessions
+type RevokedSessions struct {
+	Revoked    int      `json:"revoked"`
+	SessionIDs []string `json:"session_ids"`
+}
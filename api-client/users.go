@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Register creates a user, returning its ID. Sign in with Login afterwards.
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (string, error) {
+	var res struct {
+		ID string `json:"id"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/register", body: req}, &res)
+	return res.ID, err
+}
+
+// Login signs in with email and password, and signs the client's following requests in with the
+// token returned
+func (c *Client) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	var res LoginResponse
+	body := map[string]string{"email": email, "password": password}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/login", body: body}, &res); err != nil {
+		return nil, err
+	}
+	c.SetToken(res.Token)
+	return &res, nil
+}
+
+// Refresh exchanges refreshToken, from Login or the previous Refresh, for a new access token and
+// refresh token, and signs the client's following requests in with the new access token. Each
+// refresh token works once: presenting one again signs its session out.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	var res LoginResponse
+	body := map[string]string{"refresh_token": refreshToken}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/refresh", body: body}, &res); err != nil {
+		return nil, err
+	}
+	c.SetToken(res.Token)
+	return &res, nil
+}
+
+// Logout signs out the session of refreshToken, whose access tokens stop working at once, and
+// clears the client's token
+func (c *Client) Logout(ctx context.Context, refreshToken string) error {
+	body := map[string]string{"refresh_token": refreshToken}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/logout", body: body}, nil); err != nil {
+		return err
+	}
+	c.SetToken("")
+	return nil
+}
+
+// Me returns the signed-in user's profile
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var res User
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateProfile replaces the signed-in user's profile, returning the updated one
+func (c *Client) UpdateProfile(ctx context.Context, req UpdateProfileRequest) (*User, error) {
+	var res User
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/api/v1/users/me", body: req, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ChangePassword replaces the signed-in user's password, returning their updated profile. Every
+// other session is signed out with its refresh tokens. A wrong currentPassword fails with 403.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) (*User, error) {
+	var res User
+	body := map[string]string{"current_password": currentPassword, "new_password": newPassword}
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/api/v1/users/me/password", body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListAccountActivity returns a page of the signed-in user's account activity, newest first, and
+// its pagination
+func (c *Client) ListAccountActivity(ctx context.Context, opts ListActivityOptions) ([]AccountActivityItem, *Pagination, error) {
+	items, meta, err := c.listAccountActivity(ctx, opts, "")
+	return items, meta.Pagination, err
+}
+
+// AccountActivity iterates over the signed-in user's sign-ins and changes of their profile,
+// password, webhooks, API keys and addresses, newest first, fetching the pages as they are reached
+func (c *Client) AccountActivity(ctx context.Context, opts ListActivityOptions) iter.Seq2[AccountActivityItem, error] {
+	return paginate(ctx, func(ctx context.Context, cursor string) ([]AccountActivityItem, Meta, error) {
+		return c.listAccountActivity(ctx, opts, cursor)
+	})
+}
+
+func (c *Client) listAccountActivity(ctx context.Context, opts ListActivityOptions, cursor string) ([]AccountActivityItem, Meta, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var items []AccountActivityItem
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me/activity", query: withCursor(q, cursor), auth: userAuth}, &items)
+	return items, meta, err
+}
+
+// Channels returns the signed-in user's notification channels and whether each is verified
+func (c *Client) Channels(ctx context.Context) ([]Channel, error) {
+	var res []Channel
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users/me/channels", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SendEmailVerification emails the signed-in user a link confirming their email, replacing any
+// earlier link
+func (c *Client) SendEmailVerification(ctx context.Context) (*Channel, error) {
+	var res Channel
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/me/email/verification", auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// VerifyEmail confirms the email the link holding token was sent to. It needs no sign-in.
+func (c *Client) VerifyEmail(ctx context.Context, token string) (*Channel, error) {
+	var res Channel
+	body := map[string]string{"token": token}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users/verify-email", body: body}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// VerifyWebhook posts a challenge to the webhook with ID id, verifying it once echoed back
+func (c *Client) VerifyWebhook(ctx context.Context, id string) (*Channel, error) {
+	var res Channel
+	path := "/api/v1/webhooks/" + url.PathEscape(id) + "/verify"
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: path, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteAccount deletes the signed-in user with ID userID. A soft delete keeps the records, a hard
+// one purges them in a background job.
+func (c *Client) DeleteAccount(ctx context.Context, userID string, hard bool) (*DeleteResult, error) {
+	body := map[string]string{"user_id": userID, "type": "soft"}
+	if hard {
+		body["type"] = "hard"
+	}
+	var res DeleteResult
+	if _, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/users/delete", body: body, auth: userAuth}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/archive"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/seed"
+)
+
+// commands are the administrative commands run by `api-server <command>` instead of the server.
+// They use the database of the server's configuration.
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"archive": archiveCommand,
+	"export":  exportCommand,
+	"import":  importCommand,
+	"seed":    seedCommand,
+}
+
+// runCommand runs the command name with args, returning the process exit code
+func runCommand(ctx context.Context, name string, args []string) int {
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected archive, export, import or seed\n", name)
+		return 2
+	}
+	if err := command(ctx, args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// exportCommand writes the users, addresses and alert rules to an archive, see package archive
+func exportCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "-", `archive file to write, "-" for stdout`)
+	format := flags.String("format", archive.NDJSON, "archive format: json or ndjson")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	repos, _, _, closeDB := openDatabase(config.GetConfig())
+	defer closeDB()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	stats, err := archive.Export(ctx, repos, w, *format)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d users, %d addresses and %d alert rules\n",
+		stats.Users, stats.Addresses, stats.AlertRules)
+	return nil
+}
+
+// importCommand creates the records of an archive, skipping ones that already exist
+func importCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := flags.String("in", "-", `archive file to read, "-" for stdin`)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	_, txManager, _, closeDB := openDatabase(config.GetConfig())
+	defer closeDB()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stats, err := archive.Import(ctx, txManager, r)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %d users, %d addresses and %d alert rules, skipped %d existing records\n",
+		stats.Users, stats.Addresses, stats.AlertRules, stats.Skipped)
+	return nil
+}
+
+// seedCommand fills a development database with demo data, see package seed
+func seedCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	_, txManager, _, closeDB := openDatabase(config.GetConfig())
+	defer closeDB()
+
+	stats, err := seed.Run(ctx, txManager)
+	if errors.Is(err, seed.ErrSeeded) {
+		fmt.Fprintln(os.Stderr, "database is already seeded, nothing to do")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "created %d users, %d addresses, %d alert rules, %d transactions and %d alerts\n",
+		stats.Users, stats.Addresses, stats.AlertRules, stats.Transactions, stats.Alerts)
+	fmt.Fprintf(os.Stderr, "sign in as alice@example.com or bob@example.com with password %s\n", seed.Password)
+	return nil
+}
+
+// archiveCommand archives aged alerts and detached transactions partitions once, see package
+// retention
+func archiveCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("archive", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.GetConfig()
+	if cfg.Archive.URL == "" {
+		return errors.New("no archive configured, set ARCHIVE_URL")
+	}
+	_, _, _, closeDB := openDatabase(cfg)
+	defer closeDB()
+
+	stats, err := openArchiver(ctx, cfg).RunOnce(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "archived %d alerts and %d transactions partitions in %d objects of %d bytes\n",
+		stats.Alerts, stats.Partitions, stats.Objects, stats.Bytes)
+	return nil
+}
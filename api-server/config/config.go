@@ -1,39 +1,333 @@
 package config
 
 import (
+	"context"
 	"log"
-	"os"
-	"path/filepath"
 	"sync"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/settings"
 )
 
 type Config struct {
-	DatabaseURL string
-	Port        string
-	JWTSecret   string
+	DatabaseDriver string
+	DatabaseURL    string
+	DatabasePool   DatabasePool
+	QueryTimeout   QueryTimeout
+	RequestTimeout RequestTimeout
+	Replicas       Replicas
+	Partitions     Partitions
+	Archive        Archive
+	Jobs           Jobs
+	Analytics      Analytics
+	Webhooks       Webhooks
+	Routing        Routing
+	Email          Email
+	SMTP           SMTP
+	Chain          Chain
+	Port           string
+	JWTSecret      string
+	JWTExpiry      time.Duration
+	// JWTRefreshExpiry is the lifetime of refresh tokens, see JWT_REFRESH_EXPIRY
+	JWTRefreshExpiry time.Duration
+	// SignatureMaxSkew bounds the clock skew of signed requests, see SERVER_SIGNATURE_MAX_SKEW
+	SignatureMaxSkew time.Duration
+	// APIKeyEncryptionKey encrypts the signing secrets of API keys, see SERVER_API_KEY_ENCRYPTION_KEY
+	APIKeyEncryptionKey string
+	// ProxyHeader carries the client's address in requests from TrustedProxies, see
+	// SERVER_PROXY_HEADER and SERVER_TRUSTED_PROXIES
+	ProxyHeader    string
+	TrustedProxies []string
+	// ShutdownTimeout bounds the requests in flight on shutdown, see SERVER_SHUTDOWN_TIMEOUT
+	ShutdownTimeout time.Duration
+	AdminToken      string
+	// AdminOperatorTokens are the support operators' own admin tokens, see ADMIN_OPERATOR_TOKENS
+	AdminOperatorTokens []string
+	EngineAdminURL      string
+	// LiveActivity enables /ws/activity, pushing the transfers the engine publishes
+	LiveActivity bool
+	// LiveActivityOrigins are the origins browsers may open /ws/activity from, see
+	// LIVE_ACTIVITY_ALLOWED_ORIGINS
+	LiveActivityOrigins []string
+}
+
+// DatabasePool holds the connection pool limits applied when the database pool is created
+type DatabasePool struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+}
+
+// Replicas holds the read replicas of the database and when they may serve reads
+type Replicas struct {
+	URLs          []string
+	MaxLag        time.Duration
+	CheckInterval time.Duration
+}
+
+// Partitions holds how the monthly partitions of the transactions table are maintained
+type Partitions struct {
+	Ahead           int
+	RetentionMonths int
+	CheckInterval   time.Duration
+}
+
+// Archive holds where aged alerts and detached transactions partitions are archived, and when
+type Archive struct {
+	URL            string
+	Endpoint       string
+	Region         string
+	AlertRetention time.Duration
+	Interval       time.Duration
+	BatchSize      int
+}
+
+// Jobs holds the background job workers' settings
+type Jobs struct {
+	Workers       int
+	PollInterval  time.Duration
+	Lease         time.Duration
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+// Analytics holds how often the API usage is written and how long it is kept
+type Analytics struct {
+	FlushInterval time.Duration
+	Retention     time.Duration
+}
+
+// Webhooks holds the webhook delivery settings
+type Webhooks struct {
+	PollInterval  time.Duration
+	Timeout       time.Duration
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	AggregateMin  int
+}
+
+// Chain holds the settings of the blockchain node the api-server reads from
+type Chain struct {
+	Network     string
+	RPCURL      string
+	RPCAPIKey   string
+	FeeCacheTTL time.Duration
+	// Confirmations is the number of blocks after which a transaction is final
+	Confirmations int
+	Cache         ChainCache
+}
+
+// ChainCache holds the settings of the cache of idempotent node calls
+type ChainCache struct {
+	MaxEntries  int
+	RedisURL    string
+	BlockTTL    time.Duration
+	MetadataTTL time.Duration
+	BalanceTTL  time.Duration
+}
+
+// Routing lists the channels alerts are delivered over by severity
+type Routing struct {
+	Info     []string
+	Warning  []string
+	Critical []string
+}
+
+// Email holds the branding of alert emails, see package email
+type Email struct {
+	BrandName       string
+	LogoURL         string
+	PrimaryColor    string
+	BackgroundColor string
+	TextColor       string
+	FooterText      string
+	SupportAddress  string
+	AppURL          string
+}
+
+// SMTP holds the server emails are sent through
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// QueryTimeout holds the deadlines of interactive (OLTP) and reporting queries
+type QueryTimeout struct {
+	OLTP      time.Duration
+	Reporting time.Duration
+}
+
+// RequestTimeout holds how long ordinary, authentication and reporting routes may take
+type RequestTimeout struct {
+	Default   time.Duration
+	Auth      time.Duration
+	Reporting time.Duration
 }
 
 var Cfg Config
 var cfgOnce sync.Once
+var cfgMu sync.RWMutex
+var reloader *settings.Reloader
 
 func GetConfig() Config {
 	cfgOnce.Do(func() {
-		instance, err := loadConfig()
+		opts := settings.Options{}
+		s, err := settings.Load(opts)
+		if err == nil {
+			err = s.Validate()
+		}
 		if err != nil {
-			log.Fatalf("Error loading .env file: %v", err)
+			log.Fatalf("Error loading configuration: %v", err)
 		}
-		Cfg = instance
+		Cfg = fromSettings(s)
+
+		reloader = settings.NewReloader(opts, s)
+		reloader.OnChange(func(s *settings.Settings) {
+			cfgMu.Lock()
+			defer cfgMu.Unlock()
+			Cfg = fromSettings(s)
+		})
 	})
+
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
 	return Cfg
 }
 
-func loadConfig() (Config, error) {
-	err := godotenv.Load(filepath.Join("..", ".env"))
+// Settings returns the current shared settings, including those the api-server config does not map
+func Settings() *settings.Settings {
+	GetConfig()
+	return reloader.Settings()
+}
+
+// Watch reloads the configuration on SIGHUP and every SECRETS_REFRESH_INTERVAL until ctx is done,
+// so changed settings and rotated secrets (JWT secret, database password) are picked up without a
+// restart. See settings.Reloader for which settings can change.
+func Watch(ctx context.Context) {
+	GetConfig()
+	go reloader.WatchSignals(ctx)
+	reloader.Refresh(ctx, reloader.Settings().Secrets.RefreshInterval)
+}
+
+// fromSettings maps the shared settings (CONFIG_FILE, environment and ../.env) to the api-server config
+func fromSettings(s *settings.Settings) Config {
 	return Config{
-		DatabaseURL: os.Getenv("DB_URL"),
-		Port:        os.Getenv("PORT"),
-		JWTSecret:   os.Getenv("JWT_SECRET"),
-	}, err
+		DatabaseDriver: s.Database.Driver,
+		DatabaseURL:    s.Database.URL,
+		DatabasePool: DatabasePool{
+			MaxConns:          s.Database.MaxConns,
+			MinConns:          s.Database.MinConns,
+			MaxConnLifetime:   s.Database.MaxConnLifetime,
+			MaxConnIdleTime:   s.Database.MaxConnIdleTime,
+			HealthCheckPeriod: s.Database.HealthCheckPeriod,
+		},
+		QueryTimeout: QueryTimeout{
+			OLTP:      s.Database.QueryTimeout,
+			Reporting: s.Database.ReportingQueryTimeout,
+		},
+		RequestTimeout: RequestTimeout{
+			Default:   s.Server.RequestTimeout,
+			Auth:      s.Server.AuthTimeout,
+			Reporting: s.Server.ReportingTimeout,
+		},
+		Replicas: Replicas{
+			URLs:          s.Database.ReplicaURLs,
+			MaxLag:        s.Database.MaxReplicaLag,
+			CheckInterval: s.Database.ReplicaCheckInterval,
+		},
+		Partitions: Partitions{
+			Ahead:           s.Database.PartitionsAhead,
+			RetentionMonths: s.Database.TransactionRetentionMonths,
+			CheckInterval:   s.Database.PartitionCheckInterval,
+		},
+		Archive: Archive{
+			URL:            s.Archive.URL,
+			Endpoint:       s.Archive.Endpoint,
+			Region:         s.Archive.Region,
+			AlertRetention: s.Archive.AlertRetention,
+			Interval:       s.Archive.Interval,
+			BatchSize:      s.Archive.BatchSize,
+		},
+		Jobs: Jobs{
+			Workers:       s.Jobs.Workers,
+			PollInterval:  s.Jobs.PollInterval,
+			Lease:         s.Jobs.Lease,
+			MaxAttempts:   s.Jobs.MaxAttempts,
+			RetryDelay:    s.Jobs.RetryDelay,
+			MaxRetryDelay: s.Jobs.MaxRetryDelay,
+		},
+		Analytics: Analytics{
+			FlushInterval: s.Analytics.FlushInterval,
+			Retention:     s.Analytics.Retention,
+		},
+		Webhooks: Webhooks{
+			PollInterval:  s.Notifications.Webhooks.PollInterval,
+			Timeout:       s.Notifications.Webhooks.Timeout,
+			MaxAttempts:   s.Notifications.Webhooks.MaxAttempts,
+			RetryDelay:    s.Notifications.Webhooks.RetryDelay,
+			MaxRetryDelay: s.Notifications.Webhooks.MaxRetryDelay,
+			AggregateMin:  s.Notifications.Webhooks.AggregateMin,
+		},
+		Routing: Routing{
+			Info:     s.Notifications.Routing.Info,
+			Warning:  s.Notifications.Routing.Warning,
+			Critical: s.Notifications.Routing.Critical,
+		},
+		Email: Email{
+			BrandName:       s.Notifications.Email.BrandName,
+			LogoURL:         s.Notifications.Email.LogoURL,
+			PrimaryColor:    s.Notifications.Email.PrimaryColor,
+			BackgroundColor: s.Notifications.Email.BackgroundColor,
+			TextColor:       s.Notifications.Email.TextColor,
+			FooterText:      s.Notifications.Email.FooterText,
+			SupportAddress:  s.Notifications.Email.SupportAddress,
+			AppURL:          s.Notifications.Email.AppURL,
+		},
+		SMTP: SMTP{
+			Host:     s.Notifications.SMTP.Host,
+			Port:     s.Notifications.SMTP.Port,
+			Username: s.Notifications.SMTP.Username,
+			Password: s.Notifications.SMTP.Password,
+			From:     s.Notifications.SMTP.From,
+		},
+		Chain: Chain{
+			Network:       s.Chain.Network,
+			RPCURL:        s.Chain.RPCURL,
+			RPCAPIKey:     s.Chain.RPCAPIKey,
+			FeeCacheTTL:   s.Chain.FeeCacheTTL,
+			Confirmations: s.Chain.Confirmations,
+			Cache: ChainCache{
+				MaxEntries:  s.Chain.Cache.MaxEntries,
+				RedisURL:    s.Chain.Cache.RedisURL,
+				BlockTTL:    s.Chain.Cache.BlockTTL,
+				MetadataTTL: s.Chain.Cache.MetadataTTL,
+				BalanceTTL:  s.Chain.Cache.BalanceTTL,
+			},
+		},
+		Port:      s.Server.Port,
+		JWTSecret: s.JWT.Secret,
+		JWTExpiry: s.JWT.Expiry,
+
+		JWTRefreshExpiry: s.JWT.RefreshExpiry,
+
+		SignatureMaxSkew:    s.Server.SignatureMaxSkew,
+		ShutdownTimeout:     s.Server.ShutdownTimeout,
+		APIKeyEncryptionKey: s.Server.APIKeyEncryptionKey,
+		ProxyHeader:         s.Server.ProxyHeader,
+		TrustedProxies:      s.Server.TrustedProxies,
+
+		AdminToken:          s.Admin.Token,
+		AdminOperatorTokens: s.Admin.OperatorTokens,
+		EngineAdminURL:      s.Admin.EngineURL,
+		LiveActivity:        s.LiveActivity.Enabled,
+
+		LiveActivityOrigins: s.LiveActivity.AllowedOrigins,
+	}
 }
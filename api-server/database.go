@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/objectstore"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/sqlite"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/retention"
+)
+
+// openDatabase connects to the backend selected by DB_DRIVER, returning its repositories, the
+// transaction manager running units of work on it, its health reporting and a function closing it
+func openDatabase(cfg config.Config) (postgres.Repositories, postgres.ITxManager, postgres.IHealthInterface, func()) {
+	if cfg.DatabaseDriver == "sqlite" {
+		db, err := sqlite.Open(cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Error opening SQLite database: %v", err)
+		}
+		log.Printf("Using SQLite database %s, for local development only", cfg.DatabaseURL)
+		return sqlite.NewRepositories(db.DB), sqlite.NewTxManager(db), db, db.Close
+	}
+
+	db := postgres.GetDatabaseInstance()
+	// Read-only queries may be served by a replica
+	return postgres.NewRepositories(db.Conn()), postgres.NewTxManager(db), db, db.Close
+}
+
+// openArchiver opens the object store of ARCHIVE_URL, returning the archiver of the Postgres
+// database to it
+func openArchiver(ctx context.Context, cfg config.Config) *retention.Archiver {
+	store, err := objectstore.Open(ctx, cfg.Archive.URL, cfg.Archive.Endpoint, cfg.Archive.Region)
+	if err != nil {
+		log.Fatalf("Error opening archive %s: %v", cfg.Archive.URL, err)
+	}
+	return retention.New(postgres.GetDatabaseInstance(), store, cfg.Archive)
+}
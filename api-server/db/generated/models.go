@@ -9,14 +9,317 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type User struct {
+type AccountEvent struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Kind      string
+	Detail    []byte
+	IpAddress pgtype.Text
+	UserAgent pgtype.Text
+	CreatedAt pgtype.Timestamptz
+}
+
+type Address struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Chain      string
+	Address    string
+	Label      pgtype.Text
+	Notes      pgtype.Text
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+	Version    int32
+	DeletedAt  pgtype.Timestamptz
+	MutedAt    pgtype.Timestamptz
+	MutedUntil pgtype.Timestamptz
+}
+
+type AddressEvent struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	AddressID pgtype.UUID
+	RuleID    pgtype.UUID
+	Kind      string
+	Detail    []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+type Alert struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	AddressID      uuid.UUID
+	RuleID         pgtype.UUID
+	TransactionID  pgtype.UUID
+	Message        string
+	AcknowledgedAt pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	Severity       string
+}
+
+type AlertRule struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	AddressID       pgtype.UUID
+	Name            string
+	Direction       string
+	MinValue        pgtype.Numeric
+	TokenAddress    pgtype.Text
+	CooldownSeconds int32
+	Enabled         bool
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	Version         int32
+	DeletedAt       pgtype.Timestamptz
+	MutedAt         pgtype.Timestamptz
+	MutedUntil      pgtype.Timestamptz
+	Severity        string
+	TokenPreset     pgtype.Text
+	Expression      pgtype.Text
+	Priority        int32
+	StopProcessing  bool
+}
+
+type AlertRuleCounterparty struct {
+	RuleID    uuid.UUID
+	UserID    uuid.UUID
+	Address   string
+	CreatedAt pgtype.Timestamptz
+}
+
+type ApiKey struct {
+	ID                     uuid.UUID
+	UserID                 uuid.UUID
+	Name                   string
+	Prefix                 string
+	KeyHash                string
+	LastUsedAt             pgtype.Timestamptz
+	ExpiresAt              pgtype.Timestamptz
+	RevokedAt              pgtype.Timestamptz
+	CreatedAt              pgtype.Timestamptz
+	AllowedCidrs           []string
+	Scopes                 []string
+	SigningSecretEncrypted []byte
+}
+
+type ApiUsage struct {
+	UserID         uuid.UUID
+	Day            pgtype.Date
+	Method         string
+	Route          string
+	Calls          int64
+	ClientErrors   int64
+	ServerErrors   int64
+	TotalLatencyUs int64
+	MaxLatencyUs   int64
+}
+
+type ArchivedRange struct {
+	ID         uuid.UUID
+	Kind       string
+	ObjectKey  string
+	RangeStart pgtype.Timestamptz
+	RangeEnd   pgtype.Timestamptz
+	Records    int32
+	Bytes      int64
+	Sha256     string
+	CreatedAt  pgtype.Timestamptz
+}
+
+type BalanceWatch struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	AddressID       uuid.UUID
+	TokenAddress    string
+	IntervalSeconds int32
+	MinDelta        pgtype.Numeric
+	Severity        string
+	Enabled         bool
+	Balance         pgtype.Numeric
+	BlockNumber     pgtype.Int8
+	LastError       pgtype.Text
+	CheckedAt       pgtype.Timestamptz
+	NextCheckAt     pgtype.Timestamptz
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type EmailVerification struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type EngineOutbox struct {
+	ID        int64
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+type EngineQuarantine struct {
+	ID        int64
+	Stage     string
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Error     string
+	CreatedAt pgtype.Timestamptz
+}
+
+type EngineProcessedEvent struct {
+	ConsumerGroup string
+	Topic         string
+	Partition     int32
+	Offset        int64
+	ProcessedAt   pgtype.Timestamptz
+}
+
+type Job struct {
+	ID          uuid.UUID
+	UserID      pgtype.UUID
+	Kind        string
+	Payload     []byte
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       pgtype.Timestamptz
+	LockedBy    pgtype.Text
+	LockedUntil pgtype.Timestamptz
+	LastError   pgtype.Text
+	Result      []byte
+	StartedAt   pgtype.Timestamptz
+	FinishedAt  pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type KnownEntity struct {
+	ID        uuid.UUID
+	Chain     string
+	Address   string
+	Name      string
+	Category  pgtype.Text
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type NotificationDelivery struct {
 	ID            uuid.UUID
-	Email         string
-	PasswordHash  string
-	PhoneNumber   pgtype.Text
-	WalletAddress pgtype.Text
-	Subscribed    bool
+	AlertID       uuid.UUID
+	WebhookID     pgtype.UUID
+	Channel       string
+	Destination   string
+	Status        string
+	Attempts      int32
+	LastError     pgtype.Text
+	NextAttemptAt pgtype.Timestamptz
+	DeliveredAt   pgtype.Timestamptz
 	CreatedAt     pgtype.Timestamptz
 	UpdatedAt     pgtype.Timestamptz
-	DeletedAt     pgtype.Timestamptz
+}
+
+type OutboxEvent struct {
+	ID            uuid.UUID
+	AggregateType string
+	AggregateID   string
+	Type          string
+	Payload       []byte
+	Traceparent   pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+}
+
+type RequestNonce struct {
+	KeyID     uuid.UUID
+	Nonce     string
+	ExpiresAt pgtype.Timestamptz
+}
+
+type Session struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	IpAddress  pgtype.Text
+	UserAgent  pgtype.Text
+	CreatedAt  pgtype.Timestamptz
+	LastSeenAt pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
+type Transaction struct {
+	ID           uuid.UUID
+	Chain        string
+	Hash         string
+	LogIndex     int32
+	BlockNumber  int64
+	BlockHash    string
+	FromAddress  string
+	ToAddress    pgtype.Text
+	Value        pgtype.Numeric
+	TokenAddress pgtype.Text
+	Status       string
+	OccurredAt   pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type User struct {
+	ID              uuid.UUID
+	Email           string
+	PasswordHash    string
+	PhoneNumber     pgtype.Text
+	WalletAddress   pgtype.Text
+	Subscribed      bool
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	DeletedAt       pgtype.Timestamptz
+	Version         int32
+	EmailVerifiedAt pgtype.Timestamptz
+}
+
+type WatcherStatus struct {
+	Chain             string
+	Address           string
+	Watching          bool
+	WatchingSince     pgtype.Timestamptz
+	Mode              pgtype.Text
+	LastScannedBlock  pgtype.Int8
+	LastScannedAt     pgtype.Timestamptz
+	LastActivityBlock pgtype.Int8
+	LastActivityHash  pgtype.Text
+	LastActivityAt    pgtype.Timestamptz
+	LastError         pgtype.Text
+	LastErrorAt       pgtype.Timestamptz
+	ErrorCount        int32
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type Webhook struct {
+	ID                   uuid.UUID
+	UserID               uuid.UUID
+	Url                  string
+	Secret               string
+	Enabled              bool
+	CreatedAt            pgtype.Timestamptz
+	UpdatedAt            pgtype.Timestamptz
+	DeletedAt            pgtype.Timestamptz
+	DeliveryMode         string
+	BatchMaxEvents       int32
+	BatchIntervalSeconds int32
+	VerifiedAt           pgtype.Timestamptz
 }
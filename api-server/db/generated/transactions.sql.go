@@ -12,95 +12,237 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (
+const getTransactionsByHash = `-- name: GetTransactionsByHash :many
+SELECT
     id,
-    email,
-    password_hash,
-    phone_number,
-    wallet_address,
-    subscribed,
+    chain,
+    hash,
+    log_index,
+    block_number,
+    block_hash,
+    from_address,
+    to_address,
+    value,
+    token_address,
+    status,
+    occurred_at,
     created_at,
     updated_at
-) VALUES (
-    $1, $2, $3, $4, $5, $6, NOW(), NOW()
-)
-RETURNING
-    id
+FROM transactions
+WHERE chain = $1 AND hash = $2
+ORDER BY log_index
 `
 
-type CreateUserParams struct {
-	ID            uuid.UUID
-	Email         string
-	PasswordHash  string
-	PhoneNumber   pgtype.Text
-	WalletAddress pgtype.Text
-	Subscribed    bool
+type GetTransactionsByHashParams struct {
+	Chain string
+	Hash  string
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, createUser,
-		arg.ID,
-		arg.Email,
-		arg.PasswordHash,
-		arg.PhoneNumber,
-		arg.WalletAddress,
-		arg.Subscribed,
+func (q *Queries) GetTransactionsByHash(ctx context.Context, arg GetTransactionsByHashParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByHash,
+		arg.Chain,
+		arg.Hash,
 	)
-	var id uuid.UUID
-	err := row.Scan(&id)
-	return id, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Chain,
+			&i.Hash,
+			&i.LogIndex,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.FromAddress,
+			&i.ToAddress,
+			&i.Value,
+			&i.TokenAddress,
+			&i.Status,
+			&i.OccurredAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByAddress = `-- name: ListTransactionsByAddress :many
+SELECT
+    id,
+    chain,
+    hash,
+    log_index,
+    block_number,
+    block_hash,
+    from_address,
+    to_address,
+    value,
+    token_address,
+    status,
+    occurred_at,
+    created_at,
+    updated_at
+FROM transactions
+WHERE chain = $1
+    AND (from_address = $2 OR to_address = $2)
+    AND ($3::timestamptz IS NULL
+        OR (created_at, id) < ($3, $4::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListTransactionsByAddressParams struct {
+	Chain          string
+	Address        string
+	AfterCreatedAt pgtype.Timestamptz
+	AfterID        pgtype.UUID
+	PageSize       int32
+}
+
+func (q *Queries) ListTransactionsByAddress(ctx context.Context, arg ListTransactionsByAddressParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByAddress,
+		arg.Chain,
+		arg.Address,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Chain,
+			&i.Hash,
+			&i.LogIndex,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.FromAddress,
+			&i.ToAddress,
+			&i.Value,
+			&i.TokenAddress,
+			&i.Status,
+			&i.OccurredAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const hardDeleteUser = `-- name: HardDeleteUser :exec
-DELETE FROM users
+const updateTransactionStatus = `-- name: UpdateTransactionStatus :exec
+UPDATE transactions
+SET status = $2, updated_at = NOW()
 WHERE id = $1
+    -- The partition key, so only the transfer's partition is scanned
+    AND created_at = (SELECT created_at FROM transaction_keys WHERE transaction_id = $1)
 `
 
-func (q *Queries) HardDeleteUser(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, hardDeleteUser, id)
+type UpdateTransactionStatusParams struct {
+	ID     uuid.UUID
+	Status string
+}
+
+func (q *Queries) UpdateTransactionStatus(ctx context.Context, arg UpdateTransactionStatusParams) error {
+	_, err := q.db.Exec(ctx, updateTransactionStatus,
+		arg.ID,
+		arg.Status,
+	)
 	return err
 }
 
-const signInUser = `-- name: SignInUser :one
-SELECT
+const upsertTransaction = `-- name: UpsertTransaction :one
+WITH transaction_key AS (
+    INSERT INTO transaction_keys (chain, hash, log_index, transaction_id, created_at)
+    VALUES ($2, $3, $4, $1, NOW())
+    -- A no-op update, so the key of a known transfer is returned
+    ON CONFLICT (chain, hash, log_index) DO UPDATE
+    SET chain = EXCLUDED.chain
+    RETURNING transaction_id, created_at
+)
+INSERT INTO transactions (
     id,
-    email,
-    password_hash,
-    phone_number,
-    wallet_address,
-    subscribed,
+    chain,
+    hash,
+    log_index,
+    block_number,
+    block_hash,
+    from_address,
+    to_address,
+    value,
+    token_address,
+    status,
+    occurred_at,
     created_at,
-    updated_at,
-    deleted_at
-FROM users
-WHERE email = $1 AND deleted_at IS NULL
+    updated_at
+) VALUES (
+    (SELECT transaction_id FROM transaction_key),
+    $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+    (SELECT created_at FROM transaction_key),
+    NOW()
+)
+ON CONFLICT (id, created_at) DO UPDATE
+SET
+    block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    status = EXCLUDED.status,
+    occurred_at = EXCLUDED.occurred_at,
+    updated_at = NOW()
+RETURNING
+    id
 `
 
-func (q *Queries) SignInUser(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, signInUser, email)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.PasswordHash,
-		&i.PhoneNumber,
-		&i.WalletAddress,
-		&i.Subscribed,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+type UpsertTransactionParams struct {
+	ID           uuid.UUID
+	Chain        string
+	Hash         string
+	LogIndex     int32
+	BlockNumber  int64
+	BlockHash    string
+	FromAddress  string
+	ToAddress    pgtype.Text
+	Value        pgtype.Numeric
+	TokenAddress pgtype.Text
+	Status       string
+	OccurredAt   pgtype.Timestamptz
 }
 
-const softDeleteUser = `-- name: SoftDeleteUser :exec
-UPDATE users
-SET deleted_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL
-`
-
-func (q *Queries) SoftDeleteUser(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, softDeleteUser, id)
-	return err
+func (q *Queries) UpsertTransaction(ctx context.Context, arg UpsertTransactionParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, upsertTransaction,
+		arg.ID,
+		arg.Chain,
+		arg.Hash,
+		arg.LogIndex,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.FromAddress,
+		arg.ToAddress,
+		arg.Value,
+		arg.TokenAddress,
+		arg.Status,
+		arg.OccurredAt,
+	)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
 }
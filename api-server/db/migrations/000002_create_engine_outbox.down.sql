@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS engine_outbox;
+
+DROP INDEX IF EXISTS idx_engine_processed_events_processed_at;
+DROP TABLE IF EXISTS engine_processed_events;
@@ -0,0 +1,26 @@
+-- Events the engine has committed, written in the same transaction as the messages they emitted
+CREATE TABLE engine_processed_events (
+    consumer_group TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    partition INTEGER NOT NULL,
+    "offset" BIGINT NOT NULL,
+
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (consumer_group, topic, partition, "offset")
+);
+
+-- Pruning of old entries
+CREATE INDEX idx_engine_processed_events_processed_at ON engine_processed_events (processed_at);
+
+-- Messages (e.g. alerts) waiting to be published by the engine's outbox relay
+CREATE TABLE engine_outbox (
+    id BIGSERIAL PRIMARY KEY,
+
+    topic TEXT NOT NULL,
+    key BYTEA,
+    value BYTEA NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
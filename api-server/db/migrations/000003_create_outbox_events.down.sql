@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_outbox_events_created_at;
+
+-- Drop table
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,18 @@
+-- Domain events written in the same transaction as the change they describe, captured by
+-- Debezium (e.g. with the outbox event router) so consumers see them only if the change committed
+CREATE TABLE outbox_events (
+    id UUID PRIMARY KEY, -- generated in Go
+
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    type VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL,
+
+    -- W3C traceparent of the request that wrote the event
+    traceparent VARCHAR(55),
+
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Cleanup of captured events
+CREATE INDEX idx_outbox_events_created_at ON outbox_events (created_at);
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_addresses_chain_address;
+DROP INDEX IF EXISTS idx_addresses_user_chain_address;
+
+-- Drop table
+DROP TABLE IF EXISTS addresses;
@@ -0,0 +1,18 @@
+-- Addresses users watch, one row per user, chain and address
+CREATE TABLE addresses (
+    id UUID PRIMARY KEY, -- generated in Go
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    chain VARCHAR(32) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    label VARCHAR(255),
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Uniqueness constraints
+CREATE UNIQUE INDEX idx_addresses_user_chain_address ON addresses (user_id, chain, address);
+
+-- Watchers of an address, looked up for every detected transaction
+CREATE INDEX idx_addresses_chain_address ON addresses (chain, address);
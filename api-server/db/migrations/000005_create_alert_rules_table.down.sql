@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_alert_rules_address_id;
+DROP INDEX IF EXISTS idx_alert_rules_user_id;
+
+-- Drop table
+DROP TABLE IF EXISTS alert_rules;
@@ -0,0 +1,22 @@
+-- Conditions under which a transaction raises an alert. A rule without an address applies to
+-- every address of its user.
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY, -- generated in Go
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id UUID REFERENCES addresses (id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    direction VARCHAR(8) NOT NULL DEFAULT 'any', -- in, out or any
+    min_value NUMERIC(78, 0) NOT NULL DEFAULT 0, -- in the token's smallest unit
+    token_address VARCHAR(255), -- NULL for the chain's native currency
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT chk_alert_rules_direction CHECK (direction IN ('in', 'out', 'any'))
+);
+
+CREATE INDEX idx_alert_rules_user_id ON alert_rules (user_id);
+CREATE INDEX idx_alert_rules_address_id ON alert_rules (address_id);
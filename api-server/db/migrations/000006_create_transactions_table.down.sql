@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_transactions_chain_to_address;
+DROP INDEX IF EXISTS idx_transactions_chain_from_address;
+DROP INDEX IF EXISTS idx_transactions_chain_hash_log_index;
+
+-- Drop table
+DROP TABLE IF EXISTS transactions;
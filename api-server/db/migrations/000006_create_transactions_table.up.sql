@@ -0,0 +1,29 @@
+-- Transfers detected on watched addresses. A transaction moving several tokens has one row per
+-- transfer, told apart by the index of its log (-1 for the native transfer).
+CREATE TABLE transactions (
+    id UUID PRIMARY KEY, -- generated in Go
+
+    chain VARCHAR(32) NOT NULL,
+    hash VARCHAR(128) NOT NULL,
+    log_index INTEGER NOT NULL DEFAULT -1,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(128) NOT NULL,
+
+    from_address VARCHAR(255) NOT NULL,
+    to_address VARCHAR(255),
+    value NUMERIC(78, 0) NOT NULL, -- in the token's smallest unit
+    token_address VARCHAR(255), -- NULL for the chain's native currency
+
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, confirmed, failed or dropped
+    occurred_at TIMESTAMPTZ NOT NULL, -- block time
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Uniqueness constraints
+CREATE UNIQUE INDEX idx_transactions_chain_hash_log_index ON transactions (chain, hash, log_index);
+
+-- Address history
+CREATE INDEX idx_transactions_chain_from_address ON transactions (chain, from_address, block_number DESC);
+CREATE INDEX idx_transactions_chain_to_address ON transactions (chain, to_address, block_number DESC);
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_alerts_user_created_at;
+DROP INDEX IF EXISTS idx_alerts_rule_transaction;
+
+-- Drop table
+DROP TABLE IF EXISTS alerts;
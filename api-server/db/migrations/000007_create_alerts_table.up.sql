@@ -0,0 +1,19 @@
+-- Alerts raised for a user when a transaction matched one of their rules
+CREATE TABLE alerts (
+    id UUID PRIMARY KEY, -- generated in Go
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id UUID NOT NULL REFERENCES addresses (id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES alert_rules (id) ON DELETE SET NULL,
+    transaction_id UUID NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+
+    message TEXT NOT NULL,
+    acknowledged_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- A rule alerts once per transaction
+CREATE UNIQUE INDEX idx_alerts_rule_transaction ON alerts (rule_id, transaction_id);
+
+-- Alert listings, newest first
+CREATE INDEX idx_alerts_user_created_at ON alerts (user_id, created_at DESC);
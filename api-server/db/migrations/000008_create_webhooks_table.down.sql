@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_webhooks_user_id;
+
+-- Drop table
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,14 @@
+-- Endpoints alerts are posted to, signed with the webhook's secret
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY, -- generated in Go
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks (user_id);
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_notification_deliveries_pending;
+DROP INDEX IF EXISTS idx_notification_deliveries_alert_id;
+
+-- Drop table
+DROP TABLE IF EXISTS notification_deliveries;
@@ -0,0 +1,24 @@
+-- Attempts to deliver an alert over one channel, retried until sent or given up
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY, -- generated in Go
+    alert_id UUID NOT NULL REFERENCES alerts (id) ON DELETE CASCADE,
+    webhook_id UUID REFERENCES webhooks (id) ON DELETE SET NULL,
+
+    channel VARCHAR(16) NOT NULL, -- email, sms or webhook
+    destination VARCHAR(2048) NOT NULL,
+
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, sent or failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_notification_deliveries_alert_id ON notification_deliveries (alert_id);
+
+-- Deliveries due for an attempt
+CREATE INDEX idx_notification_deliveries_pending ON notification_deliveries (next_attempt_at)
+    WHERE status = 'pending';
@@ -0,0 +1,21 @@
+-- API keys for programmatic access. Only a hash of the key is stored; the prefix identifies it to
+-- its owner.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY, -- generated in Go
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(255) NOT NULL,
+
+    last_used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Uniqueness constraints
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
@@ -0,0 +1,11 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_transactions_chain_to_address_created_at_id;
+DROP INDEX IF EXISTS idx_transactions_chain_from_address_created_at_id;
+DROP INDEX IF EXISTS idx_alerts_address_created_at_id;
+DROP INDEX IF EXISTS idx_alerts_user_created_at_id;
+DROP INDEX IF EXISTS idx_addresses_user_created_at_id;
+
+-- Restore the previous listing indexes
+CREATE INDEX idx_alerts_user_created_at ON alerts (user_id, created_at DESC);
+CREATE INDEX idx_transactions_chain_from_address ON transactions (chain, from_address, block_number DESC);
+CREATE INDEX idx_transactions_chain_to_address ON transactions (chain, to_address, block_number DESC);
@@ -0,0 +1,12 @@
+-- Listings page by (created_at, id), see the List* queries. Each page is an index range scan
+-- starting after the previous page's last row, however deep the page.
+CREATE INDEX idx_addresses_user_created_at_id ON addresses (user_id, created_at, id);
+
+DROP INDEX IF EXISTS idx_alerts_user_created_at;
+CREATE INDEX idx_alerts_user_created_at_id ON alerts (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_alerts_address_created_at_id ON alerts (address_id, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_transactions_chain_from_address;
+DROP INDEX IF EXISTS idx_transactions_chain_to_address;
+CREATE INDEX idx_transactions_chain_from_address_created_at_id ON transactions (chain, from_address, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_chain_to_address_created_at_id ON transactions (chain, to_address, created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_known_entities_search;
+DROP INDEX IF EXISTS idx_known_entities_chain_address;
+DROP INDEX IF EXISTS idx_addresses_search;
+
+-- Drop table
+DROP TABLE IF EXISTS known_entities;
+
+-- Drop columns
+ALTER TABLE addresses DROP COLUMN IF EXISTS notes;
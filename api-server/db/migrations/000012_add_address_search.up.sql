@@ -0,0 +1,27 @@
+-- Free-form notes next to an address's label
+ALTER TABLE addresses ADD COLUMN notes TEXT;
+
+-- Full-text search over labels (weight A) and notes (weight B). The 'simple' configuration does
+-- not stem, since labels are mostly names. Queries must use the same expression to use the index.
+CREATE INDEX idx_addresses_search ON addresses USING GIN ((
+    setweight(to_tsvector('simple', coalesce(label, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(notes, '')), 'B')
+));
+
+-- Publicly known owners of addresses, e.g. exchanges and bridges
+CREATE TABLE known_entities (
+    id UUID PRIMARY KEY, -- generated in Go
+
+    chain VARCHAR(32) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(64), -- e.g. exchange, bridge or mixer
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Uniqueness constraints
+CREATE UNIQUE INDEX idx_known_entities_chain_address ON known_entities (chain, address);
+
+CREATE INDEX idx_known_entities_search ON known_entities USING GIN (to_tsvector('simple', name));
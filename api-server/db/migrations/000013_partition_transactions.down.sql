@@ -0,0 +1,41 @@
+-- Move the rows of the attached partitions back to a plain table. Detached partitions are left
+-- as they are.
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_transaction_id_fkey;
+
+CREATE TABLE transactions_unpartitioned (
+    id UUID PRIMARY KEY, -- generated in Go
+
+    chain VARCHAR(32) NOT NULL,
+    hash VARCHAR(128) NOT NULL,
+    log_index INTEGER NOT NULL DEFAULT -1,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(128) NOT NULL,
+
+    from_address VARCHAR(255) NOT NULL,
+    to_address VARCHAR(255),
+    value NUMERIC(78, 0) NOT NULL, -- in the token's smallest unit
+    token_address VARCHAR(255), -- NULL for the chain's native currency
+
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, confirmed, failed or dropped
+    occurred_at TIMESTAMPTZ NOT NULL, -- block time
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO transactions_unpartitioned SELECT * FROM transactions;
+
+-- Drops the attached partitions
+DROP TABLE transactions;
+ALTER TABLE transactions_unpartitioned RENAME TO transactions;
+ALTER TABLE transactions RENAME CONSTRAINT transactions_unpartitioned_pkey TO transactions_pkey;
+
+CREATE UNIQUE INDEX idx_transactions_chain_hash_log_index ON transactions (chain, hash, log_index);
+CREATE INDEX idx_transactions_chain_from_address_created_at_id ON transactions (chain, from_address, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_chain_to_address_created_at_id ON transactions (chain, to_address, created_at DESC, id DESC);
+
+ALTER TABLE alerts ADD CONSTRAINT alerts_transaction_id_fkey
+    FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE;
+
+DROP FUNCTION IF EXISTS create_transactions_partition(DATE);
+DROP TABLE IF EXISTS transaction_keys;
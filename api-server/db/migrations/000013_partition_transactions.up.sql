@@ -0,0 +1,102 @@
+-- Partition transactions by month of created_at, which never changes, so listings newest first
+-- only scan the recent partitions and expired months can be detached instead of deleted. Postgres
+-- requires unique constraints of a partitioned table to include the partition key, so transfers
+-- are deduplicated by transaction_keys, which also fixes the partition of a transfer when it is
+-- first seen.
+CREATE TABLE transaction_keys (
+    chain VARCHAR(32) NOT NULL,
+    hash VARCHAR(128) NOT NULL,
+    log_index INTEGER NOT NULL,
+
+    transaction_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL, -- of the transaction, its partition key
+
+    PRIMARY KEY (chain, hash, log_index)
+);
+
+CREATE UNIQUE INDEX idx_transaction_keys_transaction_id ON transaction_keys (transaction_id);
+-- Expiry, see DB_TRANSACTION_RETENTION_MONTHS
+CREATE INDEX idx_transaction_keys_created_at ON transaction_keys (created_at);
+
+-- create_transactions_partition creates the partition of the UTC month containing day, named
+-- transactions_pYYYY_MM, unless it exists, and returns its name
+CREATE FUNCTION create_transactions_partition(day DATE) RETURNS TEXT AS $$
+DECLARE
+    first_day DATE := date_trunc('month', day)::date;
+    partition_name TEXT := 'transactions_p' || to_char(first_day, 'YYYY_MM');
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF transactions FOR VALUES FROM (%L) TO (%L)',
+        partition_name,
+        to_char(first_day, 'YYYY-MM-DD') || ' 00:00:00+00',
+        to_char(first_day + INTERVAL '1 month', 'YYYY-MM-DD') || ' 00:00:00+00'
+    );
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Replace the table, keeping its rows
+ALTER TABLE alerts DROP CONSTRAINT alerts_transaction_id_fkey;
+ALTER TABLE transactions RENAME TO transactions_unpartitioned;
+ALTER TABLE transactions_unpartitioned RENAME CONSTRAINT transactions_pkey TO transactions_unpartitioned_pkey;
+DROP INDEX IF EXISTS idx_transactions_chain_hash_log_index;
+DROP INDEX IF EXISTS idx_transactions_chain_from_address_created_at_id;
+DROP INDEX IF EXISTS idx_transactions_chain_to_address_created_at_id;
+
+CREATE TABLE transactions (
+    id UUID NOT NULL, -- generated in Go
+
+    chain VARCHAR(32) NOT NULL,
+    hash VARCHAR(128) NOT NULL,
+    log_index INTEGER NOT NULL DEFAULT -1,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(128) NOT NULL,
+
+    from_address VARCHAR(255) NOT NULL,
+    to_address VARCHAR(255),
+    value NUMERIC(78, 0) NOT NULL, -- in the token's smallest unit
+    token_address VARCHAR(255), -- NULL for the chain's native currency
+
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, confirmed, failed or dropped
+    occurred_at TIMESTAMPTZ NOT NULL, -- block time
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- Lookups by hash, unique through transaction_keys
+CREATE INDEX idx_transactions_chain_hash_log_index ON transactions (chain, hash, log_index);
+
+-- Address history
+CREATE INDEX idx_transactions_chain_from_address_created_at_id ON transactions (chain, from_address, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_chain_to_address_created_at_id ON transactions (chain, to_address, created_at DESC, id DESC);
+
+-- Partitions for the existing rows and the next three months. The api-server creates later ones,
+-- see DB_PARTITIONS_AHEAD.
+DO $$
+DECLARE
+    first_day DATE;
+BEGIN
+    FOR first_day IN
+        SELECT generate_series(
+            date_trunc('month', coalesce((SELECT min(created_at) FROM transactions_unpartitioned), NOW()) AT TIME ZONE 'UTC'),
+            date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months',
+            INTERVAL '1 month'
+        )::date
+    LOOP
+        PERFORM create_transactions_partition(first_day);
+    END LOOP;
+END;
+$$;
+
+INSERT INTO transactions SELECT * FROM transactions_unpartitioned;
+INSERT INTO transaction_keys (chain, hash, log_index, transaction_id, created_at)
+SELECT chain, hash, log_index, id, created_at FROM transactions_unpartitioned;
+
+DROP TABLE transactions_unpartitioned;
+
+-- Alerts are deleted with the key of their transaction when it expires
+ALTER TABLE alerts ADD CONSTRAINT alerts_transaction_id_fkey
+    FOREIGN KEY (transaction_id) REFERENCES transaction_keys (transaction_id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+-- Drop columns
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS version;
+ALTER TABLE addresses DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
@@ -0,0 +1,5 @@
+-- Optimistic concurrency: updates of these records name the version they were based on and bump
+-- it, so an update based on a stale read matches no row instead of overwriting a concurrent edit
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE addresses ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE alert_rules ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
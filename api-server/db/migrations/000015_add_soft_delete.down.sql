@@ -0,0 +1,15 @@
+-- Deleted rows are removed, as they would have been without soft deletes
+DELETE FROM webhooks WHERE deleted_at IS NOT NULL;
+DELETE FROM alert_rules WHERE deleted_at IS NOT NULL;
+DELETE FROM addresses WHERE deleted_at IS NOT NULL;
+
+-- Restore indexes
+DROP INDEX IF EXISTS idx_addresses_chain_address;
+CREATE INDEX idx_addresses_chain_address ON addresses (chain, address);
+DROP INDEX IF EXISTS idx_addresses_user_chain_address;
+CREATE UNIQUE INDEX idx_addresses_user_chain_address ON addresses (user_id, chain, address);
+
+-- Drop columns
+ALTER TABLE webhooks DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE addresses DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,14 @@
+-- Deleting an address, rule or webhook sets deleted_at instead of removing the row, so alerts and
+-- deliveries keep their history. Queries hide deleted rows unless asked to include them.
+ALTER TABLE addresses ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE alert_rules ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE webhooks ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- A deleted address no longer blocks watching the same address again
+DROP INDEX IF EXISTS idx_addresses_user_chain_address;
+CREATE UNIQUE INDEX idx_addresses_user_chain_address ON addresses (user_id, chain, address)
+    WHERE deleted_at IS NULL;
+
+-- Watchers of an address, looked up for every detected transaction, are never deleted ones
+DROP INDEX IF EXISTS idx_addresses_chain_address;
+CREATE INDEX idx_addresses_chain_address ON addresses (chain, address) WHERE deleted_at IS NULL;
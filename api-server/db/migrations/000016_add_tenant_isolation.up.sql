@@ -0,0 +1,43 @@
+-- Row-level security scoping every user-owned table to the user the connection acts for, set in
+-- app.user_id by the API server for each signed-in request. It backs up the repository checks:
+-- a query that forgets its user_id filter still cannot read or change another user's rows.
+-- Connections that set no user, such as the engine's and background jobs', see every row.
+CREATE FUNCTION app_tenant() RETURNS UUID
+    LANGUAGE SQL STABLE
+    AS $$ SELECT NULLIF(current_setting('app.user_id', true), '')::uuid $$;
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users
+    USING (app_tenant() IS NULL OR id = app_tenant());
+
+ALTER TABLE addresses ENABLE ROW LEVEL SECURITY;
+ALTER TABLE addresses FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON addresses
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
+
+ALTER TABLE alert_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE alert_rules FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON alert_rules
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
+
+ALTER TABLE alerts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE alerts FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON alerts
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
+
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhooks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhooks
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON api_keys
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
+
+-- Deliveries belong to the user of their alert, which the alerts policy already restricts
+ALTER TABLE notification_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notification_deliveries FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notification_deliveries
+    USING (app_tenant() IS NULL OR EXISTS (SELECT 1 FROM alerts a WHERE a.id = alert_id));
@@ -0,0 +1,25 @@
+-- Archive objects holding alerts and transactions moved out of the database to object storage,
+-- one row per object, so the objects covering a time range can be found for retrieval. Every
+-- object also has a manifest object next to it, see package retention.
+CREATE TABLE archived_ranges (
+    id UUID PRIMARY KEY, -- generated in Go
+    kind VARCHAR(16) NOT NULL, -- alerts or transactions
+    object_key TEXT NOT NULL,
+
+    -- Creation times of the first and last record in the object
+    range_start TIMESTAMPTZ NOT NULL,
+    range_end TIMESTAMPTZ NOT NULL,
+
+    records INTEGER NOT NULL,
+    bytes BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL, -- of the compressed object
+
+    created_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT chk_archived_ranges_kind CHECK (kind IN ('alerts', 'transactions'))
+);
+
+-- An object archived again after an interrupted run replaces its row
+CREATE UNIQUE INDEX idx_archived_ranges_object_key ON archived_ranges (object_key);
+
+CREATE INDEX idx_archived_ranges_kind_range ON archived_ranges (kind, range_start, range_end);
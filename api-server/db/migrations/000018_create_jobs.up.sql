@@ -0,0 +1,43 @@
+-- Background work enqueued by the API, such as exports, imports and account purges, run by the
+-- api-server's job workers, see package jobs. Workers claim due jobs with FOR UPDATE SKIP LOCKED
+-- and lease them until locked_until; a job whose worker stopped is claimed again once its lease
+-- expires. Failed attempts are retried with backoff until max_attempts.
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY, -- generated in Go
+    -- The user the job runs for, NULL for system jobs. Kept when the user is purged, so the
+    -- purge job itself survives it.
+    user_id UUID REFERENCES users (id) ON DELETE SET NULL,
+
+    kind VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+
+    status VARCHAR(16) NOT NULL DEFAULT 'queued', -- queued, running, succeeded or failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMPTZ NOT NULL, -- when the job is due, pushed back after a failed attempt
+
+    locked_by VARCHAR(128), -- the worker running the job
+    locked_until TIMESTAMPTZ,
+
+    last_error TEXT,
+    result JSONB,
+
+    started_at TIMESTAMPTZ, -- of the latest attempt
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT chk_jobs_status CHECK (status IN ('queued', 'running', 'succeeded', 'failed'))
+);
+
+-- Jobs due to be claimed, and running jobs whose lease may expire
+CREATE INDEX idx_jobs_queued ON jobs (run_at) WHERE status = 'queued';
+CREATE INDEX idx_jobs_running ON jobs (locked_until) WHERE status = 'running';
+
+CREATE INDEX idx_jobs_user_id_created_at ON jobs (user_id, created_at);
+
+-- A user sees their own jobs, see migration 000016
+ALTER TABLE jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE jobs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON jobs
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
@@ -0,0 +1,25 @@
+-- Daily API usage of each user per endpoint, for GET /api/v1/users/me/analytics. The api-server
+-- aggregates calls in memory and adds them to the day's row every ANALYTICS_FLUSH_INTERVAL; rows
+-- older than ANALYTICS_RETENTION are deleted.
+CREATE TABLE api_usage (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day DATE NOT NULL, -- UTC
+    method VARCHAR(8) NOT NULL,
+    route VARCHAR(255) NOT NULL, -- the route pattern, e.g. /api/v1/jobs/:id
+
+    calls BIGINT NOT NULL,
+    client_errors BIGINT NOT NULL, -- 4xx responses
+    server_errors BIGINT NOT NULL, -- 5xx responses
+    total_latency_us BIGINT NOT NULL,
+    max_latency_us BIGINT NOT NULL,
+
+    PRIMARY KEY (user_id, day, method, route)
+);
+
+CREATE INDEX idx_api_usage_day ON api_usage (day);
+
+-- A user sees their own usage, see migration 000016
+ALTER TABLE api_usage ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_usage FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON api_usage
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
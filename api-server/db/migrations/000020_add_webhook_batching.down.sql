@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notification_deliveries_webhook_pending;
+
+ALTER TABLE webhooks
+    DROP COLUMN IF EXISTS batch_interval_seconds,
+    DROP COLUMN IF EXISTS batch_max_events,
+    DROP COLUMN IF EXISTS delivery_mode;
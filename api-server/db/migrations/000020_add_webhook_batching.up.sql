@@ -0,0 +1,14 @@
+-- Webhooks opt into batched delivery: their alerts are posted together as an array once
+-- batch_max_events are pending or the oldest has waited batch_interval_seconds, with one signature
+-- for the batch. 'single' webhooks get one POST per alert.
+ALTER TABLE webhooks
+    ADD COLUMN delivery_mode VARCHAR(8) NOT NULL DEFAULT 'single',
+    ADD COLUMN batch_max_events INTEGER NOT NULL DEFAULT 100,
+    ADD COLUMN batch_interval_seconds INTEGER NOT NULL DEFAULT 30,
+    ADD CONSTRAINT chk_webhooks_delivery_mode CHECK (delivery_mode IN ('single', 'batch')),
+    ADD CONSTRAINT chk_webhooks_batch_max_events CHECK (batch_max_events BETWEEN 1 AND 1000),
+    ADD CONSTRAINT chk_webhooks_batch_interval CHECK (batch_interval_seconds BETWEEN 1 AND 3600);
+
+-- Pending deliveries of a webhook, grouped into batches
+CREATE INDEX idx_notification_deliveries_webhook_pending ON notification_deliveries (webhook_id, created_at)
+    WHERE status = 'pending';
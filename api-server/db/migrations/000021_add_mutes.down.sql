@@ -0,0 +1,7 @@
+ALTER TABLE alert_rules
+    DROP COLUMN IF EXISTS muted_until,
+    DROP COLUMN IF EXISTS muted_at;
+
+ALTER TABLE addresses
+    DROP COLUMN IF EXISTS muted_until,
+    DROP COLUMN IF EXISTS muted_at;
@@ -0,0 +1,9 @@
+-- Muting an address or rule silences the notifications of its alerts, until muted_until or, when
+-- that is NULL, until it is unmuted. Alerts are still recorded while muted.
+ALTER TABLE addresses
+    ADD COLUMN muted_at TIMESTAMPTZ,
+    ADD COLUMN muted_until TIMESTAMPTZ;
+
+ALTER TABLE alert_rules
+    ADD COLUMN muted_at TIMESTAMPTZ,
+    ADD COLUMN muted_until TIMESTAMPTZ;
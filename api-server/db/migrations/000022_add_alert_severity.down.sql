@@ -0,0 +1,3 @@
+ALTER TABLE alerts DROP COLUMN IF EXISTS severity;
+
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS severity;
@@ -0,0 +1,10 @@
+-- Severity of the alerts a rule raises, info, warning or critical, which selects the channels they
+-- are delivered over. An alert's severity is its rule's, raised by heuristics such as a sanctioned
+-- counterparty.
+ALTER TABLE alert_rules
+    ADD COLUMN severity VARCHAR(8) NOT NULL DEFAULT 'warning',
+    ADD CONSTRAINT chk_alert_rules_severity CHECK (severity IN ('info', 'warning', 'critical'));
+
+ALTER TABLE alerts
+    ADD COLUMN severity VARCHAR(8) NOT NULL DEFAULT 'warning',
+    ADD CONSTRAINT chk_alerts_severity CHECK (severity IN ('info', 'warning', 'critical'));
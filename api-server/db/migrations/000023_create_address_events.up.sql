@@ -0,0 +1,22 @@
+-- Changes of an address and of its rules, shown with its transactions and alerts in its activity
+-- feed. They are written by the statements making the change. Events of a rule without an address
+-- have no address_id and belong to the feed of every address of the user.
+CREATE TABLE address_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id UUID REFERENCES addresses (id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES alert_rules (id) ON DELETE CASCADE,
+
+    kind VARCHAR(32) NOT NULL, -- e.g. address.muted or rule.paused
+    detail JSONB NOT NULL DEFAULT '{}', -- e.g. the rule's name or the end of a mute
+
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_address_events_user_created_at_id ON address_events (user_id, created_at DESC, id DESC);
+
+-- A user sees the events of their own addresses and rules, see migration 000016
+ALTER TABLE address_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE address_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON address_events
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
@@ -0,0 +1,22 @@
+-- The audit log of a user's account: sign-ins and changes of their profile, webhooks and API keys,
+-- shown in their account activity feed. Changes of webhooks and API keys are written by the
+-- statements making them, sign-ins and profile changes by the user service.
+CREATE TABLE account_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    kind VARCHAR(32) NOT NULL, -- e.g. login or webhook.created
+    detail JSONB NOT NULL DEFAULT '{}', -- e.g. the webhook's URL or the changed profile fields
+    ip_address VARCHAR(45), -- the client of sign-ins
+    user_agent TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_account_events_user_created_at_id ON account_events (user_id, created_at DESC, id DESC);
+
+-- A user sees the events of their own account, see migration 000016
+ALTER TABLE account_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE account_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON account_events
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
@@ -0,0 +1,25 @@
+-- Notification channels are verified before alerts are delivered over them: a user's email by a
+-- confirmation link, a webhook by echoing a challenge posted to it. Webhooks created before
+-- verification was required are taken as verified, so their deliveries carry on.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+ALTER TABLE webhooks ADD COLUMN verified_at TIMESTAMPTZ;
+UPDATE webhooks SET verified_at = NOW() WHERE deleted_at IS NULL;
+
+-- Pending email confirmation links, by the SHA-256 of their token. A user has at most one; a new
+-- link replaces the previous.
+CREATE TABLE email_verifications (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL, -- the address the link verifies
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_email_verifications_user_id ON email_verifications (user_id);
+
+-- Links are followed signed out, so the lookup by token runs without a tenant, see migration 000016
+ALTER TABLE email_verifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE email_verifications FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON email_verifications
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
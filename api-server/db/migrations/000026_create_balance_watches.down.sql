@@ -0,0 +1,4 @@
+DELETE FROM alerts WHERE transaction_id IS NULL;
+ALTER TABLE alerts ALTER COLUMN transaction_id SET NOT NULL;
+
+DROP TABLE IF EXISTS balance_watches;
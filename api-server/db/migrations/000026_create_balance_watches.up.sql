@@ -0,0 +1,42 @@
+-- ERC-20 balances of watched addresses polled with balanceOf, alerting on their changes. They catch
+-- the balance changes of tokens that emit no Transfer events, such as rebasing tokens and some
+-- bridges. Deltas are measured from the balance first read, then from the one of the last alert,
+-- so slow drifts alert once they add up to min_delta.
+CREATE TABLE balance_watches (
+    id UUID PRIMARY KEY, -- generated in Go
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id UUID NOT NULL REFERENCES addresses (id) ON DELETE CASCADE,
+
+    token_address VARCHAR(255) NOT NULL,
+    interval_seconds INTEGER NOT NULL,
+    min_delta NUMERIC(78, 0) NOT NULL DEFAULT 0, -- in the token's smallest unit
+    severity VARCHAR(8) NOT NULL DEFAULT 'warning',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    balance NUMERIC(78, 0), -- NULL until first read
+    block_number BIGINT, -- the balance was read at
+    last_error TEXT,
+    checked_at TIMESTAMPTZ,
+    next_check_at TIMESTAMPTZ NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT chk_balance_watches_interval CHECK (interval_seconds >= 15),
+    CONSTRAINT chk_balance_watches_severity CHECK (severity IN ('info', 'warning', 'critical'))
+);
+
+-- A token is watched once per address
+CREATE UNIQUE INDEX idx_balance_watches_address_token ON balance_watches (address_id, LOWER(token_address));
+
+-- The poller's claim of due watches
+CREATE INDEX idx_balance_watches_next_check_at ON balance_watches (next_check_at) WHERE enabled;
+
+-- A user sees the watches of their own addresses, see migration 000016
+ALTER TABLE balance_watches ENABLE ROW LEVEL SECURITY;
+ALTER TABLE balance_watches FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON balance_watches
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
+
+-- Alerts of balance changes have no transaction
+ALTER TABLE alerts ALTER COLUMN transaction_id DROP NOT NULL;
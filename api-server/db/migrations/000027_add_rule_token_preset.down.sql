@@ -0,0 +1,2 @@
+ALTER TABLE alert_rules DROP CONSTRAINT IF EXISTS alert_rules_one_token_filter;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS token_preset;
@@ -0,0 +1,6 @@
+-- A rule's token preset filters its transfers to a built-in list of token contracts per chain,
+-- such as the major stablecoins, instead of the single contract of token_address
+ALTER TABLE alert_rules ADD COLUMN token_preset VARCHAR(32)
+    CHECK (token_preset IN ('stablecoins'));
+ALTER TABLE alert_rules ADD CONSTRAINT alert_rules_one_token_filter
+    CHECK (token_address IS NULL OR token_preset IS NULL);
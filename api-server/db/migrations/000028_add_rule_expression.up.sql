@@ -0,0 +1,4 @@
+-- Advanced rules have their condition written as a CEL expression over the normalized
+-- transaction, compiled and evaluated by the engine, see package ruleexpr
+ALTER TABLE alert_rules ADD COLUMN expression TEXT
+    CHECK (expression IS NULL OR LENGTH(expression) BETWEEN 1 AND 4096);
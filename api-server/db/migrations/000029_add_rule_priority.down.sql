@@ -0,0 +1 @@
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS stop_processing, DROP COLUMN IF EXISTS priority;
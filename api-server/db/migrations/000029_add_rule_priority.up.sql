@@ -0,0 +1,6 @@
+-- Rules of a transaction are evaluated by descending priority. A matching rule with
+-- stop_processing set suppresses the lower priority rules, e.g. a sanctions rule over the
+-- generic large transfer rules.
+ALTER TABLE alert_rules
+    ADD COLUMN priority INTEGER NOT NULL DEFAULT 0 CHECK (priority BETWEEN -1000 AND 1000),
+    ADD COLUMN stop_processing BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Networks an API key is accepted from, in CIDR notation; requests from other addresses are
+-- rejected. An empty list accepts the key from anywhere.
+ALTER TABLE api_keys ADD COLUMN allowed_cidrs TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (cardinality(allowed_cidrs) <= 32);
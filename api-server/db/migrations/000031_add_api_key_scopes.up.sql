@@ -0,0 +1,6 @@
+-- Permissions of an API key, e.g. read:addresses or write:rules, checked per route. Keys created
+-- before scopes existed keep full access.
+ALTER TABLE api_keys ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE api_keys
+SET scopes = ARRAY['read:account', 'write:account', 'read:addresses', 'write:addresses', 'read:rules', 'write:rules', 'read:alerts'];
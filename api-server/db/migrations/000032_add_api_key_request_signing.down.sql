@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_request_nonces_expires_at;
+
+-- Drop table
+DROP TABLE IF EXISTS request_nonces;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS signing_secret;
@@ -0,0 +1,15 @@
+-- Secret of API keys signing their requests with HMAC-SHA256 instead of presenting the key, which
+-- the server needs to verify the signatures. NULL for keys presented in the X-API-Key header.
+ALTER TABLE api_keys ADD COLUMN signing_secret VARCHAR(255);
+
+-- Nonces of signed requests, kept while their timestamp is within the allowed clock skew so a
+-- captured request cannot be replayed
+CREATE TABLE request_nonces (
+    key_id UUID NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (key_id, nonce)
+);
+
+CREATE INDEX idx_request_nonces_expires_at ON request_nonces (expires_at);
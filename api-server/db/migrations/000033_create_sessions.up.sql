@@ -0,0 +1,22 @@
+-- Sign-ins of a user, one per token handed out by login. A token of a revoked session is rejected
+-- at once, so a user can sign a lost device out.
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY, -- generated in Go, the session claim of the token
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL, -- when the token expires
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+-- A user sees their own sessions, see migration 000016
+ALTER TABLE sessions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sessions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON sessions
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_engine_quarantine_created_at;
+DROP INDEX IF EXISTS idx_engine_quarantine_topic_stage;
+DROP TABLE IF EXISTS engine_quarantine;
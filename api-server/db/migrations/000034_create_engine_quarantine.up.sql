@@ -0,0 +1,19 @@
+-- Samples of the change events the engine failed to decode or handle, kept for inspection
+CREATE TABLE engine_quarantine (
+    id BIGSERIAL PRIMARY KEY,
+
+    -- deserialize, parse, unknown_operation or handler
+    stage TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    partition INTEGER NOT NULL,
+    "offset" BIGINT NOT NULL,
+    key BYTEA,
+    value BYTEA,
+    error TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Listing by topic and stage, and pruning of old entries
+CREATE INDEX idx_engine_quarantine_topic_stage ON engine_quarantine (topic, stage, created_at);
+CREATE INDEX idx_engine_quarantine_created_at ON engine_quarantine (created_at);
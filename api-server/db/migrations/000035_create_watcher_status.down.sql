@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_watcher_status_chain;
+DROP TABLE IF EXISTS watcher_status;
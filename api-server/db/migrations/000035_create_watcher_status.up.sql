@@ -0,0 +1,30 @@
+-- State of the engine's watch of each address, written by the engine and read by the
+-- api-server's address status endpoint. One row per chain and address however many users watch
+-- it; watching is false once no address row watches it any longer.
+CREATE TABLE watcher_status (
+    chain VARCHAR(32) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+
+    watching BOOLEAN NOT NULL,
+    watching_since TIMESTAMPTZ NOT NULL,
+    mode VARCHAR(16), -- subscription or polling, NULL until the chain is first scanned
+
+    last_scanned_block BIGINT,
+    last_scanned_at TIMESTAMPTZ,
+
+    last_activity_block BIGINT,
+    last_activity_hash VARCHAR(255),
+    last_activity_at TIMESTAMPTZ, -- block time of the last transfer seen
+
+    last_error TEXT,
+    last_error_at TIMESTAMPTZ,
+    error_count INTEGER NOT NULL DEFAULT 0, -- since the last successful scan
+
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (chain, address),
+    CONSTRAINT chk_watcher_status_mode CHECK (mode IN ('subscription', 'polling'))
+);
+
+-- Scans update every watched address of their chain
+CREATE INDEX idx_watcher_status_chain ON watcher_status (chain) WHERE watching;
@@ -0,0 +1,17 @@
+-- Counterparties a rule's transfers are limited to: a rule with any only matches transfers from
+-- or to one of them, as seen from the watched address. Addresses are normalized for the rule's
+-- chain when it has an address, and stored as given otherwise.
+CREATE TABLE alert_rule_counterparties (
+    rule_id UUID NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (rule_id, address)
+);
+
+-- A user sees the counterparties of their own rules, see migration 000016
+ALTER TABLE alert_rule_counterparties ENABLE ROW LEVEL SECURITY;
+ALTER TABLE alert_rule_counterparties FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON alert_rule_counterparties
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_refresh_tokens_session_id;
+DROP INDEX IF EXISTS idx_refresh_tokens_token_hash;
+
+-- Drop table
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,22 @@
+-- Refresh tokens of the sessions, exchanged for a new access token and a new refresh token. Each
+-- is used once: presenting a used one again means it leaked, and its session is revoked. The
+-- session's expires_at is moved to the expiry of its latest refresh token on every exchange.
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash VARCHAR(255) NOT NULL, -- SHA-256 of the token, which is only shown once
+
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ -- when it was exchanged
+);
+
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+
+-- A user sees the refresh tokens of their own sessions, see migration 000016
+ALTER TABLE refresh_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON refresh_tokens
+    USING (app_tenant() IS NULL OR user_id = app_tenant());
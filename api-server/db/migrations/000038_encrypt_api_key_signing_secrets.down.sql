@@ -0,0 +1,7 @@
+ALTER TABLE api_keys ADD COLUMN signing_secret VARCHAR(255);
+
+-- Encrypted secrets cannot be restored in plaintext, the keys signing with them are revoked
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()), signing_secret = ''
+WHERE signing_secret_encrypted IS NOT NULL;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS signing_secret_encrypted;
@@ -0,0 +1,10 @@
+-- Signing secrets were the API keys themselves, stored in plaintext. They are now separate random
+-- secrets encrypted with SERVER_API_KEY_ENCRYPTION_KEY, which the stored ones cannot be converted
+-- to here, so the keys signing with them are revoked and have to be created again. They keep an
+-- empty secret to still be listed as signed.
+ALTER TABLE api_keys ADD COLUMN signing_secret_encrypted BYTEA;
+
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()), signing_secret_encrypted = ''::BYTEA
+WHERE signing_secret IS NOT NULL;
+
+ALTER TABLE api_keys DROP COLUMN signing_secret;
@@ -0,0 +1,27 @@
+-- name: CreateAccountEvent :exec
+INSERT INTO account_events (
+    user_id,
+    kind,
+    detail,
+    ip_address,
+    user_agent,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, NOW()
+);
+
+-- name: ListAccountEvents :many
+SELECT
+    id,
+    user_id,
+    kind,
+    detail,
+    ip_address,
+    user_agent,
+    created_at
+FROM account_events
+WHERE user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
\ No newline at end of file
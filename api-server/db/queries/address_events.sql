@@ -0,0 +1,34 @@
+-- name: ListAddressEvents :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    rule_id,
+    kind,
+    detail,
+    created_at
+FROM address_events
+WHERE user_id = sqlc.arg(user_id)
+    -- Events of the rules without an address, since the address was added
+    AND (address_id = sqlc.arg(address_id)
+        OR address_id IS NULL AND created_at >= (SELECT a.created_at FROM addresses a WHERE a.id = sqlc.arg(address_id)))
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUserAddressEvents :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    rule_id,
+    kind,
+    detail,
+    created_at
+FROM address_events
+WHERE user_id = sqlc.arg(user_id) AND kind = ANY(sqlc.arg(kinds)::text[])
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
\ No newline at end of file
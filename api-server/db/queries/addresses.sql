@@ -0,0 +1,195 @@
+-- name: CreateAddress :one
+WITH created AS (
+    INSERT INTO addresses (
+        id,
+        user_id,
+        chain,
+        address,
+        label,
+        notes,
+        created_at,
+        updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, NOW(), NOW()
+    )
+    RETURNING id, user_id
+), event AS (
+    INSERT INTO address_events (user_id, address_id, kind, created_at)
+    SELECT user_id, id, 'address.created', NOW()
+    FROM created
+)
+SELECT
+    id
+FROM created;
+
+-- name: GetAddress :one
+SELECT
+    id,
+    user_id,
+    chain,
+    address,
+    label,
+    notes,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until
+FROM addresses
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::bool);
+
+-- name: ListAddressesByUser :many
+SELECT
+    id,
+    user_id,
+    chain,
+    address,
+    label,
+    notes,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until
+FROM addresses
+WHERE user_id = sqlc.arg(user_id)
+    AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::bool)
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListAddressesByChainAddress :many
+SELECT
+    id,
+    user_id,
+    chain,
+    address,
+    label,
+    notes,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until
+FROM addresses
+WHERE chain = $1 AND address = $2 AND deleted_at IS NULL;
+
+-- name: UpdateAddressDetails :execrows
+WITH updated AS (
+    UPDATE addresses
+    SET label = $3, notes = $4, version = version + 1, updated_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND version = $5 AND deleted_at IS NULL
+    RETURNING id, user_id, label
+)
+INSERT INTO address_events (user_id, address_id, kind, detail, created_at)
+SELECT user_id, id, 'address.updated', jsonb_strip_nulls(jsonb_build_object('label', label)), NOW()
+FROM updated;
+
+-- name: MuteAddress :execrows
+WITH muted AS (
+    UPDATE addresses
+    SET muted_at = NOW(), muted_until = $3
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, muted_until
+)
+INSERT INTO address_events (user_id, address_id, kind, detail, created_at)
+SELECT user_id, id, 'address.muted', jsonb_strip_nulls(jsonb_build_object('muted_until', muted_until)), NOW()
+FROM muted;
+
+-- name: UnmuteAddress :execrows
+WITH unmuted AS (
+    UPDATE addresses
+    SET muted_at = NULL, muted_until = NULL
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id
+)
+INSERT INTO address_events (user_id, address_id, kind, created_at)
+SELECT user_id, id, 'address.unmuted', NOW()
+FROM unmuted;
+
+-- name: SoftDeleteAddress :execrows
+WITH address_rules AS (
+    UPDATE alert_rules
+    SET deleted_at = NOW()
+    WHERE address_id = $1 AND user_id = $2 AND deleted_at IS NULL
+), deleted AS (
+    UPDATE addresses
+    SET deleted_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id
+)
+INSERT INTO address_events (user_id, address_id, kind, created_at)
+SELECT user_id, id, 'address.deleted', NOW()
+FROM deleted;
+
+-- name: ExportAddresses :many
+SELECT
+    id,
+    user_id,
+    chain,
+    address,
+    label,
+    notes,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until
+FROM addresses
+WHERE deleted_at IS NULL
+    AND EXISTS (SELECT 1 FROM users u WHERE u.id = addresses.user_id AND u.deleted_at IS NULL)
+    AND (sqlc.narg(after_id)::uuid IS NULL OR id > sqlc.narg(after_id))
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: ImportAddress :execrows
+INSERT INTO addresses (
+    id,
+    user_id,
+    chain,
+    address,
+    label,
+    notes,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT DO NOTHING;
+
+-- name: SearchAddresses :many
+SELECT
+    a.id,
+    a.chain,
+    a.address,
+    a.label,
+    a.notes,
+    ke.name AS entity_name,
+    (ts_rank(
+        setweight(to_tsvector('simple', coalesce(a.label, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(a.notes, '')), 'B'),
+        to_tsquery('simple', sqlc.arg(query))
+    ) + ts_rank(to_tsvector('simple', coalesce(ke.name, '')), to_tsquery('simple', sqlc.arg(query))))::float4 AS rank,
+    ts_headline(
+        'simple',
+        concat_ws(' | ', a.label, a.notes, ke.name),
+        to_tsquery('simple', sqlc.arg(query)),
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2'
+    )::text AS headline
+FROM addresses a
+LEFT JOIN known_entities ke ON ke.chain = a.chain AND ke.address = a.address
+WHERE a.user_id = sqlc.arg(user_id)
+    AND a.deleted_at IS NULL
+    AND (
+        (setweight(to_tsvector('simple', coalesce(a.label, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(a.notes, '')), 'B')) @@ to_tsquery('simple', sqlc.arg(query))
+        OR to_tsvector('simple', coalesce(ke.name, '')) @@ to_tsquery('simple', sqlc.arg(query))
+    )
+ORDER BY rank DESC, a.created_at
+LIMIT sqlc.arg(max_results);
\ No newline at end of file
@@ -0,0 +1,314 @@
+-- name: CreateAlertRule :one
+WITH created AS (
+    INSERT INTO alert_rules (
+        id,
+        user_id,
+        address_id,
+        name,
+        direction,
+        min_value,
+        token_address,
+        cooldown_seconds,
+        enabled,
+        severity,
+        expression,
+        created_at,
+        updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW()
+    )
+    RETURNING id, user_id, address_id, name
+), event AS (
+    INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+    SELECT user_id, address_id, id, 'rule.created', jsonb_build_object('name', name), NOW()
+    FROM created
+)
+SELECT
+    id
+FROM created;
+
+-- name: GetAlertRule :one
+SELECT
+    id,
+    user_id,
+    address_id,
+    name,
+    direction,
+    min_value,
+    token_address,
+    cooldown_seconds,
+    enabled,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until,
+    severity,
+    token_preset,
+    expression,
+    priority,
+    stop_processing
+FROM alert_rules
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::bool);
+
+-- name: ListAlertRulesByUser :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    name,
+    direction,
+    min_value,
+    token_address,
+    cooldown_seconds,
+    enabled,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until,
+    severity,
+    token_preset,
+    expression,
+    priority,
+    stop_processing
+FROM alert_rules
+WHERE user_id = sqlc.arg(user_id)
+    AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::bool)
+ORDER BY created_at;
+
+-- name: ListEnabledAlertRulesForAddress :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    name,
+    direction,
+    min_value,
+    token_address,
+    cooldown_seconds,
+    enabled,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until,
+    severity,
+    token_preset,
+    expression,
+    priority,
+    stop_processing
+FROM alert_rules
+WHERE user_id = $1 AND enabled AND deleted_at IS NULL AND (address_id = $2 OR address_id IS NULL)
+ORDER BY priority DESC, created_at, id;
+
+-- name: UpdateAlertRule :execrows
+WITH updated AS (
+    UPDATE alert_rules r
+    SET
+        name = $3,
+        direction = $4,
+        min_value = $5,
+        token_address = $6,
+        cooldown_seconds = $7,
+        enabled = $8,
+        severity = $10,
+        version = r.version + 1,
+        updated_at = NOW()
+    -- The row as it was, to tell pausing and resuming the rule from other edits
+    FROM alert_rules previous
+    WHERE r.id = $1 AND r.user_id = $2 AND r.version = $9 AND r.deleted_at IS NULL
+        AND previous.id = r.id
+    RETURNING r.id, r.user_id, r.address_id, r.name, r.enabled, previous.enabled AS was_enabled
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT
+    user_id,
+    address_id,
+    id,
+    CASE
+        WHEN enabled = was_enabled THEN 'rule.updated'
+        WHEN enabled THEN 'rule.resumed'
+        ELSE 'rule.paused'
+    END,
+    jsonb_build_object('name', name),
+    NOW()
+FROM updated;
+
+-- name: MuteAlertRule :execrows
+WITH muted AS (
+    UPDATE alert_rules
+    SET muted_at = NOW(), muted_until = $3
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, address_id, name, muted_until
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT
+    user_id,
+    address_id,
+    id,
+    'rule.muted',
+    jsonb_strip_nulls(jsonb_build_object('name', name, 'muted_until', muted_until)),
+    NOW()
+FROM muted;
+
+-- name: UnmuteAlertRule :execrows
+WITH unmuted AS (
+    UPDATE alert_rules
+    SET muted_at = NULL, muted_until = NULL
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, address_id, name
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT user_id, address_id, id, 'rule.unmuted', jsonb_build_object('name', name), NOW()
+FROM unmuted;
+
+-- name: SetAlertRuleTokenPreset :execrows
+WITH updated AS (
+    UPDATE alert_rules
+    SET token_preset = $3, version = version + 1, updated_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND token_address IS NULL
+    RETURNING id, user_id, address_id, name, token_preset
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT
+    user_id,
+    address_id,
+    id,
+    'rule.updated',
+    jsonb_strip_nulls(jsonb_build_object('name', name, 'token_preset', token_preset)),
+    NOW()
+FROM updated;
+
+-- name: SetAlertRuleExpression :execrows
+WITH updated AS (
+    UPDATE alert_rules
+    SET expression = $3, version = version + 1, updated_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, address_id, name, expression
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT
+    user_id,
+    address_id,
+    id,
+    'rule.updated',
+    jsonb_strip_nulls(jsonb_build_object('name', name, 'expression', expression)),
+    NOW()
+FROM updated;
+
+-- name: SetAlertRulePriority :execrows
+WITH updated AS (
+    UPDATE alert_rules
+    SET priority = $3, stop_processing = $4, version = version + 1, updated_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, address_id, name, priority, stop_processing
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT
+    user_id,
+    address_id,
+    id,
+    'rule.updated',
+    jsonb_build_object('name', name, 'priority', priority, 'stop_processing', stop_processing),
+    NOW()
+FROM updated;
+
+-- name: SetAlertRuleCounterparties :execrows
+WITH updated AS (
+    UPDATE alert_rules
+    SET version = version + 1, updated_at = NOW()
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND deleted_at IS NULL
+    RETURNING id, user_id, address_id, name
+), removed AS (
+    DELETE FROM alert_rule_counterparties c
+    USING updated
+    WHERE c.rule_id = updated.id AND c.address <> ALL (sqlc.arg(addresses)::text[])
+), added AS (
+    INSERT INTO alert_rule_counterparties (rule_id, user_id, address, created_at)
+    SELECT updated.id, updated.user_id, a.address, NOW()
+    FROM updated, unnest(sqlc.arg(addresses)::text[]) AS a (address)
+    ON CONFLICT (rule_id, address) DO NOTHING
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT
+    user_id,
+    address_id,
+    id,
+    'rule.updated',
+    jsonb_build_object('name', name, 'counterparties', cardinality(sqlc.arg(addresses)::text[])),
+    NOW()
+FROM updated;
+
+-- name: ListAlertRuleCounterparties :many
+SELECT rule_id, address
+FROM alert_rule_counterparties
+WHERE user_id = sqlc.arg(user_id) AND rule_id = ANY (sqlc.arg(rule_ids)::uuid[])
+ORDER BY rule_id, address;
+
+-- name: SoftDeleteAlertRule :execrows
+WITH deleted AS (
+    UPDATE alert_rules
+    SET deleted_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, address_id, name
+)
+INSERT INTO address_events (user_id, address_id, rule_id, kind, detail, created_at)
+SELECT user_id, address_id, id, 'rule.deleted', jsonb_build_object('name', name), NOW()
+FROM deleted;
+
+-- name: ExportAlertRules :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    name,
+    direction,
+    min_value,
+    token_address,
+    cooldown_seconds,
+    enabled,
+    created_at,
+    updated_at,
+    version,
+    deleted_at,
+    muted_at,
+    muted_until,
+    severity,
+    token_preset,
+    expression,
+    priority,
+    stop_processing
+FROM alert_rules
+WHERE deleted_at IS NULL
+    AND EXISTS (SELECT 1 FROM users u WHERE u.id = alert_rules.user_id AND u.deleted_at IS NULL)
+    AND (sqlc.narg(after_id)::uuid IS NULL OR id > sqlc.narg(after_id))
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: ImportAlertRule :execrows
+INSERT INTO alert_rules (
+    id,
+    user_id,
+    address_id,
+    name,
+    direction,
+    min_value,
+    token_address,
+    cooldown_seconds,
+    enabled,
+    severity,
+    created_at,
+    updated_at,
+    token_preset,
+    expression,
+    priority,
+    stop_processing
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+)
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,102 @@
+-- name: CreateAlert :one
+INSERT INTO alerts (
+    id,
+    user_id,
+    address_id,
+    rule_id,
+    transaction_id,
+    message,
+    severity,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, NOW()
+)
+RETURNING
+    id;
+
+-- name: GetAlert :one
+SELECT
+    id,
+    user_id,
+    address_id,
+    rule_id,
+    transaction_id,
+    message,
+    acknowledged_at,
+    created_at,
+    severity
+FROM alerts
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListAlertsByUser :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    rule_id,
+    transaction_id,
+    message,
+    acknowledged_at,
+    created_at,
+    severity
+FROM alerts
+WHERE user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListAlertsByAddress :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    rule_id,
+    transaction_id,
+    message,
+    acknowledged_at,
+    created_at,
+    severity
+FROM alerts
+WHERE address_id = sqlc.arg(address_id) AND user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: AcknowledgeAlert :execrows
+UPDATE alerts
+SET acknowledged_at = NOW()
+WHERE id = $1 AND user_id = $2 AND acknowledged_at IS NULL;
+
+-- name: ListAgedAlerts :many
+SELECT
+    a.id,
+    a.user_id,
+    a.address_id,
+    a.rule_id,
+    a.transaction_id,
+    a.message,
+    a.severity,
+    a.acknowledged_at,
+    a.created_at,
+    t.chain,
+    t.hash,
+    t.log_index,
+    t.from_address,
+    t.to_address,
+    t.value,
+    t.token_address,
+    t.status,
+    t.occurred_at
+FROM alerts a
+LEFT JOIN transaction_keys k ON k.transaction_id = a.transaction_id
+-- The partition key, so only the transfer's partition is scanned
+LEFT JOIN transactions t ON t.id = k.transaction_id AND t.created_at = k.created_at
+WHERE a.created_at < sqlc.arg(before)
+ORDER BY a.created_at, a.id
+LIMIT sqlc.arg(page_size);
+
+-- name: DeleteAlerts :execrows
+DELETE FROM alerts
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
\ No newline at end of file
@@ -0,0 +1,136 @@
+-- name: CreateApiKey :one
+WITH created AS (
+    INSERT INTO api_keys (
+        id,
+        user_id,
+        name,
+        prefix,
+        key_hash,
+        expires_at,
+        allowed_cidrs,
+        scopes,
+        signing_secret_encrypted,
+        created_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()
+    )
+    RETURNING id, user_id, name, prefix, scopes
+), event AS (
+    INSERT INTO account_events (user_id, kind, detail, created_at)
+    SELECT user_id, 'api_key.created', jsonb_build_object('api_key_id', id, 'name', name, 'prefix', prefix, 'scopes', scopes), NOW()
+    FROM created
+)
+SELECT
+    id
+FROM created;
+
+-- name: GetActiveApiKeyByHash :one
+SELECT
+    id,
+    user_id,
+    name,
+    prefix,
+    key_hash,
+    last_used_at,
+    expires_at,
+    revoked_at,
+    created_at,
+    allowed_cidrs,
+    scopes,
+    signing_secret_encrypted
+FROM api_keys
+WHERE key_hash = $1
+    AND revoked_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: GetActiveApiKeyByID :one
+SELECT
+    id,
+    user_id,
+    name,
+    prefix,
+    key_hash,
+    last_used_at,
+    expires_at,
+    revoked_at,
+    created_at,
+    allowed_cidrs,
+    scopes,
+    signing_secret_encrypted
+FROM api_keys
+WHERE id = $1
+    AND revoked_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: ListApiKeysByUser :many
+SELECT
+    id,
+    user_id,
+    name,
+    prefix,
+    key_hash,
+    last_used_at,
+    expires_at,
+    revoked_at,
+    created_at,
+    allowed_cidrs,
+    scopes,
+    signing_secret_encrypted
+FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: SetApiKeyAllowedCidrs :execrows
+WITH updated AS (
+    UPDATE api_keys
+    SET allowed_cidrs = $3
+    WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+    RETURNING id, user_id, name, prefix, allowed_cidrs
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT
+    user_id,
+    'api_key.updated',
+    jsonb_build_object('api_key_id', id, 'name', name, 'prefix', prefix, 'allowed_cidrs', allowed_cidrs),
+    NOW()
+FROM updated;
+
+-- name: SetApiKeyScopes :execrows
+WITH updated AS (
+    UPDATE api_keys
+    SET scopes = $3
+    WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+    RETURNING id, user_id, name, prefix, scopes
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT
+    user_id,
+    'api_key.updated',
+    jsonb_build_object('api_key_id', id, 'name', name, 'prefix', prefix, 'scopes', scopes),
+    NOW()
+FROM updated;
+
+-- name: UseRequestNonce :execrows
+WITH expired AS (
+    DELETE FROM request_nonces
+    WHERE key_id = $1 AND expires_at < NOW()
+)
+INSERT INTO request_nonces (key_id, nonce, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key_id, nonce) DO NOTHING;
+
+-- name: RevokeApiKey :execrows
+WITH revoked AS (
+    UPDATE api_keys
+    SET revoked_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+    RETURNING id, user_id, name, prefix
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT user_id, 'api_key.revoked', jsonb_build_object('api_key_id', id, 'name', name, 'prefix', prefix), NOW()
+FROM revoked;
\ No newline at end of file
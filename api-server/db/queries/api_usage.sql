@@ -0,0 +1,40 @@
+-- name: RecordApiUsage :exec
+INSERT INTO api_usage (
+    user_id,
+    day,
+    method,
+    route,
+    calls,
+    client_errors,
+    server_errors,
+    total_latency_us,
+    max_latency_us
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (user_id, day, method, route) DO UPDATE
+SET
+    calls = api_usage.calls + EXCLUDED.calls,
+    client_errors = api_usage.client_errors + EXCLUDED.client_errors,
+    server_errors = api_usage.server_errors + EXCLUDED.server_errors,
+    total_latency_us = api_usage.total_latency_us + EXCLUDED.total_latency_us,
+    max_latency_us = GREATEST(api_usage.max_latency_us, EXCLUDED.max_latency_us);
+
+-- name: ListApiUsageByUser :many
+SELECT
+    user_id,
+    day,
+    method,
+    route,
+    calls,
+    client_errors,
+    server_errors,
+    total_latency_us,
+    max_latency_us
+FROM api_usage
+WHERE user_id = $1 AND day >= $2
+ORDER BY day, method, route;
+
+-- name: DeleteApiUsageBefore :execrows
+DELETE FROM api_usage
+WHERE day < $1;
\ No newline at end of file
@@ -0,0 +1,39 @@
+-- name: UpsertArchivedRange :exec
+INSERT INTO archived_ranges (
+    id,
+    kind,
+    object_key,
+    range_start,
+    range_end,
+    records,
+    bytes,
+    sha256,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, NOW()
+)
+ON CONFLICT (object_key) DO UPDATE
+SET
+    range_start = EXCLUDED.range_start,
+    range_end = EXCLUDED.range_end,
+    records = EXCLUDED.records,
+    bytes = EXCLUDED.bytes,
+    sha256 = EXCLUDED.sha256,
+    created_at = NOW();
+
+-- name: ListArchivedRanges :many
+SELECT
+    id,
+    kind,
+    object_key,
+    range_start,
+    range_end,
+    records,
+    bytes,
+    sha256,
+    created_at
+FROM archived_ranges
+WHERE kind = sqlc.arg(kind)
+    AND range_start < sqlc.arg(range_to)
+    AND range_end >= sqlc.arg(range_from)
+ORDER BY range_start;
\ No newline at end of file
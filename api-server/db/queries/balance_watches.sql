@@ -0,0 +1,131 @@
+-- name: CreateBalanceWatch :one
+WITH created AS (
+    INSERT INTO balance_watches (
+        id,
+        user_id,
+        address_id,
+        token_address,
+        interval_seconds,
+        min_delta,
+        severity,
+        enabled,
+        next_check_at,
+        created_at,
+        updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), NOW()
+    )
+    RETURNING id, user_id, address_id, token_address
+), event AS (
+    INSERT INTO address_events (user_id, address_id, kind, detail, created_at)
+    SELECT user_id, address_id, 'balance.watched', jsonb_build_object('watch_id', id, 'token_address', token_address), NOW()
+    FROM created
+)
+SELECT
+    id
+FROM created;
+
+-- name: GetBalanceWatch :one
+SELECT
+    id,
+    user_id,
+    address_id,
+    token_address,
+    interval_seconds,
+    min_delta,
+    severity,
+    enabled,
+    balance,
+    block_number,
+    last_error,
+    checked_at,
+    next_check_at,
+    created_at,
+    updated_at
+FROM balance_watches
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListBalanceWatchesByAddress :many
+SELECT
+    id,
+    user_id,
+    address_id,
+    token_address,
+    interval_seconds,
+    min_delta,
+    severity,
+    enabled,
+    balance,
+    block_number,
+    last_error,
+    checked_at,
+    next_check_at,
+    created_at,
+    updated_at
+FROM balance_watches
+WHERE address_id = $1 AND user_id = $2
+ORDER BY created_at, id;
+
+-- name: UpdateBalanceWatch :execrows
+UPDATE balance_watches
+SET
+    interval_seconds = $3,
+    min_delta = $4,
+    severity = $5,
+    enabled = $6,
+    -- A new interval applies from now rather than from the last check
+    next_check_at = CASE WHEN interval_seconds = $3 THEN next_check_at ELSE NOW() END,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteBalanceWatch :execrows
+WITH deleted AS (
+    DELETE FROM balance_watches
+    WHERE id = $1 AND user_id = $2
+    RETURNING id, user_id, address_id, token_address
+)
+INSERT INTO address_events (user_id, address_id, kind, detail, created_at)
+SELECT user_id, address_id, 'balance.unwatched', jsonb_build_object('watch_id', id, 'token_address', token_address), NOW()
+FROM deleted;
+
+-- name: ClaimDueBalanceWatches :many
+WITH due AS (
+    SELECT w.id
+    FROM balance_watches w
+    JOIN addresses a ON a.id = w.address_id
+    WHERE w.enabled AND w.next_check_at <= NOW() AND a.chain = sqlc.arg(chain) AND a.deleted_at IS NULL
+    ORDER BY w.next_check_at
+    LIMIT sqlc.arg(max_results)
+    FOR UPDATE OF w SKIP LOCKED
+)
+UPDATE balance_watches w
+SET next_check_at = NOW() + make_interval(secs => w.interval_seconds)
+FROM due, addresses a
+WHERE w.id = due.id AND a.id = w.address_id
+RETURNING
+    w.id,
+    w.user_id,
+    w.address_id,
+    a.address,
+    w.token_address,
+    w.min_delta,
+    w.severity,
+    w.balance,
+    w.block_number;
+
+-- name: RecordBalanceWatchBalance :execrows
+UPDATE balance_watches
+SET
+    balance = $2,
+    block_number = $3,
+    last_error = NULL,
+    checked_at = NOW()
+-- A read behind the recorded one, e.g. from a lagging node, is dropped
+WHERE id = $1 AND (block_number IS NULL OR block_number <= $3);
+
+-- name: RecordBalanceWatchCheck :exec
+UPDATE balance_watches
+SET
+    last_error = $2,
+    checked_at = NOW()
+WHERE id = $1;
\ No newline at end of file
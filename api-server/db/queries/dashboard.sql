@@ -0,0 +1,40 @@
+-- name: GetDashboardCounts :one
+SELECT
+    (SELECT COUNT(*) FROM addresses a
+        WHERE a.user_id = sqlc.arg(user_id) AND a.deleted_at IS NULL) AS addresses,
+    (SELECT COUNT(*) FROM addresses a
+        WHERE a.user_id = sqlc.arg(user_id) AND a.deleted_at IS NULL
+            AND a.muted_at IS NOT NULL AND (a.muted_until IS NULL OR a.muted_until > NOW())) AS muted_addresses,
+    (SELECT COUNT(*) FROM alerts al
+        WHERE al.user_id = sqlc.arg(user_id) AND al.created_at >= sqlc.arg(day_since)) AS alerts_last_day,
+    (SELECT COUNT(*) FROM alerts al
+        WHERE al.user_id = sqlc.arg(user_id) AND al.created_at >= sqlc.arg(week_since)) AS alerts_last_week;
+
+-- name: ListTopActiveAddresses :many
+SELECT
+    a.id,
+    a.chain,
+    a.address,
+    a.label,
+    t.transactions,
+    t.last_transaction_at,
+    (SELECT COUNT(*) FROM alerts al
+        WHERE al.address_id = a.id AND al.created_at >= sqlc.arg(since)) AS alerts
+FROM addresses a
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS transactions, MAX(tx.created_at)::timestamptz AS last_transaction_at
+    FROM transactions tx
+    WHERE tx.chain = a.chain AND (tx.from_address = a.address OR tx.to_address = a.address)
+        AND tx.created_at >= sqlc.arg(since)
+) t
+WHERE a.user_id = sqlc.arg(user_id) AND a.deleted_at IS NULL AND t.transactions > 0
+ORDER BY t.transactions DESC, a.created_at, a.id
+LIMIT sqlc.arg(max_results);
+
+-- name: ListChainAddresses :many
+SELECT
+    address
+FROM addresses
+WHERE user_id = sqlc.arg(user_id) AND chain = sqlc.arg(chain) AND deleted_at IS NULL
+ORDER BY created_at, id
+LIMIT sqlc.arg(max_results);
\ No newline at end of file
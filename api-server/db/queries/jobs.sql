@@ -0,0 +1,158 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (
+    id,
+    user_id,
+    kind,
+    payload,
+    max_attempts,
+    run_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, NOW(), NOW()
+)
+RETURNING
+    id,
+    user_id,
+    kind,
+    payload,
+    status,
+    attempts,
+    max_attempts,
+    run_at,
+    locked_by,
+    locked_until,
+    last_error,
+    result,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at;
+
+-- name: GetJob :one
+SELECT
+    id,
+    user_id,
+    kind,
+    payload,
+    status,
+    attempts,
+    max_attempts,
+    run_at,
+    locked_by,
+    locked_until,
+    last_error,
+    result,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+FROM jobs
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListJobsByUser :many
+SELECT
+    id,
+    user_id,
+    kind,
+    payload,
+    status,
+    attempts,
+    max_attempts,
+    run_at,
+    locked_by,
+    locked_until,
+    last_error,
+    result,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+FROM jobs
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_by = $1,
+    locked_until = $2,
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE (status = 'queued' AND run_at <= NOW())
+        OR (status = 'running' AND locked_until < NOW() AND attempts < max_attempts)
+    ORDER BY run_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING
+    id,
+    user_id,
+    kind,
+    payload,
+    status,
+    attempts,
+    max_attempts,
+    run_at,
+    locked_by,
+    locked_until,
+    last_error,
+    result,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at;
+
+-- name: ExtendJobLease :execrows
+UPDATE jobs
+SET locked_until = $3, updated_at = NOW()
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: CompleteJob :execrows
+UPDATE jobs
+SET
+    status = 'succeeded',
+    result = $3,
+    last_error = NULL,
+    locked_by = NULL,
+    locked_until = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: RecordJobFailure :execrows
+UPDATE jobs
+SET
+    status = $3,
+    last_error = $4,
+    run_at = $5,
+    locked_by = NULL,
+    locked_until = NULL,
+    finished_at = CASE WHEN $3 = 'failed' THEN NOW() END,
+    updated_at = NOW()
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
+
+-- name: FailAbandonedJobs :execrows
+UPDATE jobs
+SET
+    status = 'failed',
+    last_error = 'abandoned by its worker after the last attempt',
+    locked_by = NULL,
+    locked_until = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE status = 'running' AND locked_until < NOW() AND attempts >= max_attempts;
+
+-- name: CountJobs :many
+SELECT
+    kind,
+    status,
+    COUNT(*) AS count
+FROM jobs
+GROUP BY kind, status
+ORDER BY kind, status;
\ No newline at end of file
@@ -0,0 +1,31 @@
+-- name: SearchKnownEntities :many
+SELECT
+    id,
+    chain,
+    address,
+    name,
+    category,
+    ts_rank(to_tsvector('simple', name), to_tsquery('simple', sqlc.arg(query)))::float4 AS rank,
+    ts_headline('simple', name, to_tsquery('simple', sqlc.arg(query)), 'StartSel=<mark>, StopSel=</mark>')::text AS headline
+FROM known_entities
+WHERE to_tsvector('simple', name) @@ to_tsquery('simple', sqlc.arg(query))
+ORDER BY rank DESC, name
+LIMIT sqlc.arg(max_results);
+
+-- name: UpsertKnownEntity :exec
+INSERT INTO known_entities (
+    id,
+    chain,
+    address,
+    name,
+    category,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, NOW(), NOW()
+)
+ON CONFLICT (chain, address) DO UPDATE
+SET
+    name = EXCLUDED.name,
+    category = EXCLUDED.category,
+    updated_at = NOW();
\ No newline at end of file
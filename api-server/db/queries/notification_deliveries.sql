@@ -0,0 +1,230 @@
+-- name: CreateNotificationDelivery :one
+INSERT INTO notification_deliveries (
+    id,
+    alert_id,
+    webhook_id,
+    channel,
+    destination,
+    next_attempt_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, NOW(), NOW(), NOW()
+)
+RETURNING
+    id;
+
+-- name: ListNotificationDeliveriesByAlert :many
+SELECT
+    id,
+    alert_id,
+    webhook_id,
+    channel,
+    destination,
+    status,
+    attempts,
+    last_error,
+    next_attempt_at,
+    delivered_at,
+    created_at,
+    updated_at
+FROM notification_deliveries
+WHERE alert_id = $1
+ORDER BY created_at;
+
+-- name: ClaimDueNotificationDeliveries :many
+SELECT
+    id,
+    alert_id,
+    webhook_id,
+    channel,
+    destination,
+    status,
+    attempts,
+    last_error,
+    next_attempt_at,
+    delivered_at,
+    created_at,
+    updated_at
+FROM notification_deliveries
+WHERE status = 'pending' AND next_attempt_at <= NOW()
+ORDER BY next_attempt_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: ClaimDueWebhookDeliveries :many
+SELECT
+    d.id,
+    d.alert_id,
+    d.attempts,
+    w.id AS webhook_id,
+    w.url,
+    w.secret,
+    (w.enabled AND w.deleted_at IS NULL)::bool AS active,
+    (w.verified_at IS NOT NULL)::bool AS verified,
+    a.address_id,
+    ad.chain,
+    ad.address,
+    a.rule_id,
+    a.transaction_id,
+    a.message,
+    a.severity,
+    a.created_at AS alert_created_at,
+    t.block_number,
+    t.value,
+    t.token_address,
+    (
+        (ad.muted_at IS NOT NULL AND (ad.muted_until IS NULL OR ad.muted_until > NOW()))
+        OR (r.muted_at IS NOT NULL AND (r.muted_until IS NULL OR r.muted_until > NOW()))
+    )::bool AS muted
+FROM notification_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+JOIN alerts a ON a.id = d.alert_id
+JOIN addresses ad ON ad.id = a.address_id
+LEFT JOIN alert_rules r ON r.id = a.rule_id
+LEFT JOIN transaction_keys k ON k.transaction_id = a.transaction_id
+LEFT JOIN transactions t ON t.id = k.transaction_id AND t.created_at = k.created_at
+WHERE d.channel = 'webhook' AND d.status = 'pending' AND d.next_attempt_at <= NOW()
+    AND w.delivery_mode = 'single'
+ORDER BY d.next_attempt_at
+LIMIT sqlc.arg(max_results)
+FOR UPDATE OF d SKIP LOCKED;
+
+-- name: ListDueWebhookBatches :many
+SELECT
+    d.webhook_id::uuid AS id,
+    w.batch_max_events
+FROM notification_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.channel = 'webhook' AND d.status = 'pending' AND d.next_attempt_at <= NOW()
+    AND w.delivery_mode = 'batch'
+GROUP BY d.webhook_id, w.batch_max_events, w.batch_interval_seconds
+HAVING COUNT(*) >= w.batch_max_events
+    OR MIN(d.created_at) <= NOW() - make_interval(secs => w.batch_interval_seconds)
+ORDER BY MIN(d.created_at)
+LIMIT sqlc.arg(max_results);
+
+-- name: ClaimWebhookBatch :many
+SELECT
+    d.id,
+    d.alert_id,
+    d.attempts,
+    w.id AS webhook_id,
+    w.url,
+    w.secret,
+    (w.enabled AND w.deleted_at IS NULL)::bool AS active,
+    (w.verified_at IS NOT NULL)::bool AS verified,
+    a.address_id,
+    ad.chain,
+    ad.address,
+    a.rule_id,
+    a.transaction_id,
+    a.message,
+    a.severity,
+    a.created_at AS alert_created_at,
+    t.block_number,
+    t.value,
+    t.token_address,
+    (
+        (ad.muted_at IS NOT NULL AND (ad.muted_until IS NULL OR ad.muted_until > NOW()))
+        OR (r.muted_at IS NOT NULL AND (r.muted_until IS NULL OR r.muted_until > NOW()))
+    )::bool AS muted
+FROM notification_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+JOIN alerts a ON a.id = d.alert_id
+JOIN addresses ad ON ad.id = a.address_id
+LEFT JOIN alert_rules r ON r.id = a.rule_id
+LEFT JOIN transaction_keys k ON k.transaction_id = a.transaction_id
+LEFT JOIN transactions t ON t.id = k.transaction_id AND t.created_at = k.created_at
+WHERE d.channel = 'webhook' AND d.status = 'pending' AND d.next_attempt_at <= NOW()
+    AND d.webhook_id = sqlc.arg(webhook_id)
+ORDER BY d.created_at
+LIMIT sqlc.arg(max_results)
+FOR UPDATE OF d SKIP LOCKED;
+
+-- name: ClaimBlockWebhookDeliveries :many
+SELECT
+    d.id,
+    d.alert_id,
+    d.attempts,
+    w.id AS webhook_id,
+    w.url,
+    w.secret,
+    (w.enabled AND w.deleted_at IS NULL)::bool AS active,
+    (w.verified_at IS NOT NULL)::bool AS verified,
+    a.address_id,
+    ad.chain,
+    ad.address,
+    a.rule_id,
+    a.transaction_id,
+    a.message,
+    a.severity,
+    a.created_at AS alert_created_at,
+    t.block_number,
+    t.value,
+    t.token_address,
+    (
+        (ad.muted_at IS NOT NULL AND (ad.muted_until IS NULL OR ad.muted_until > NOW()))
+        OR (r.muted_at IS NOT NULL AND (r.muted_until IS NULL OR r.muted_until > NOW()))
+    )::bool AS muted
+FROM notification_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+JOIN alerts a ON a.id = d.alert_id
+JOIN addresses ad ON ad.id = a.address_id
+LEFT JOIN alert_rules r ON r.id = a.rule_id
+LEFT JOIN transaction_keys k ON k.transaction_id = a.transaction_id
+LEFT JOIN transactions t ON t.id = k.transaction_id AND t.created_at = k.created_at
+WHERE d.channel = 'webhook' AND d.status = 'pending' AND d.next_attempt_at <= NOW()
+    AND d.webhook_id = sqlc.arg(webhook_id) AND a.address_id = sqlc.arg(address_id)
+    AND t.block_number = sqlc.arg(block_number) AND NOT d.id = ANY(sqlc.arg(ids)::uuid[])
+ORDER BY d.created_at
+LIMIT sqlc.arg(max_results)
+FOR UPDATE OF d SKIP LOCKED;
+
+-- name: MarkNotificationDeliverySent :exec
+UPDATE notification_deliveries
+SET
+    status = 'sent',
+    attempts = attempts + 1,
+    last_error = NULL,
+    delivered_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkNotificationDeliveriesSent :exec
+UPDATE notification_deliveries
+SET
+    status = 'sent',
+    attempts = attempts + 1,
+    last_error = NULL,
+    delivered_at = NOW(),
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: RecordNotificationDeliveryFailure :exec
+UPDATE notification_deliveries
+SET
+    status = $2,
+    attempts = attempts + 1,
+    last_error = $3,
+    next_attempt_at = $4,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: SkipNotificationDeliveries :exec
+UPDATE notification_deliveries
+SET
+    status = 'skipped',
+    last_error = sqlc.arg(last_error),
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: RecordNotificationDeliveriesFailure :exec
+UPDATE notification_deliveries
+SET
+    status = sqlc.arg(status),
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
\ No newline at end of file
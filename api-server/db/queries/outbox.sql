@@ -0,0 +1,12 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events (
+    id,
+    aggregate_type,
+    aggregate_id,
+    type,
+    payload,
+    traceparent,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, NOW()
+);
@@ -0,0 +1,43 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (
+    id,
+    session_id,
+    user_id,
+    token_hash,
+    created_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, NOW(), $5
+);
+
+-- name: GetRefreshToken :one
+SELECT
+    rt.id,
+    rt.session_id,
+    rt.user_id,
+    u.email,
+    rt.used_at
+FROM refresh_tokens rt
+JOIN sessions s ON s.id = rt.session_id
+JOIN users u ON u.id = rt.user_id
+WHERE rt.token_hash = $1
+    AND rt.expires_at > NOW()
+    AND s.revoked_at IS NULL
+    AND s.expires_at > NOW()
+    AND u.deleted_at IS NULL;
+
+-- name: RotateRefreshToken :execrows
+WITH used AS (
+    UPDATE refresh_tokens
+    SET used_at = NOW()
+    WHERE refresh_tokens.id = sqlc.arg(id) AND used_at IS NULL
+    RETURNING session_id, user_id
+), extended AS (
+    UPDATE sessions
+    SET expires_at = sqlc.arg(expires_at), last_seen_at = NOW()
+    FROM used
+    WHERE sessions.id = used.session_id
+)
+INSERT INTO refresh_tokens (id, session_id, user_id, token_hash, created_at, expires_at)
+SELECT sqlc.arg(new_id), session_id, user_id, sqlc.arg(token_hash), NOW(), sqlc.arg(expires_at)
+FROM used;
@@ -0,0 +1,91 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (
+    id,
+    user_id,
+    ip_address,
+    user_agent,
+    created_at,
+    last_seen_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, NOW(), NOW(), $5
+);
+
+-- name: GetActiveSession :one
+SELECT
+    id,
+    user_id,
+    ip_address,
+    user_agent,
+    created_at,
+    last_seen_at,
+    expires_at,
+    revoked_at
+FROM sessions
+WHERE id = $1
+    AND user_id = $2
+    AND revoked_at IS NULL
+    AND expires_at > NOW();
+
+-- name: ListActiveSessionsByUser :many
+SELECT
+    id,
+    user_id,
+    ip_address,
+    user_agent,
+    created_at,
+    last_seen_at,
+    expires_at,
+    revoked_at
+FROM sessions
+WHERE user_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+ORDER BY last_seen_at DESC, id;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeSession :execrows
+WITH revoked AS (
+    UPDATE sessions
+    SET revoked_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+    RETURNING id, user_id, ip_address, user_agent
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT
+    user_id,
+    'session.revoked',
+    jsonb_build_object('session_id', id, 'ip_address', ip_address, 'user_agent', user_agent),
+    NOW()
+FROM revoked;
+
+-- name: RevokeOtherSessions :many
+WITH revoked AS (
+    UPDATE sessions
+    SET revoked_at = NOW()
+    WHERE user_id = sqlc.arg(user_id)
+        AND id <> sqlc.arg(id)
+        AND revoked_at IS NULL
+        AND expires_at > NOW()
+    RETURNING id, user_id, ip_address, user_agent
+), expired AS (
+    -- The refresh tokens of the sessions stop working with them
+    UPDATE refresh_tokens
+    SET expires_at = NOW()
+    WHERE session_id IN (SELECT id FROM revoked) AND expires_at > NOW()
+), event AS (
+    INSERT INTO account_events (user_id, kind, detail, created_at)
+    SELECT
+        user_id,
+        'session.revoked',
+        jsonb_build_object('session_id', id, 'ip_address', ip_address, 'user_agent', user_agent),
+        NOW()
+    FROM revoked
+)
+SELECT
+    id
+FROM revoked;
\ No newline at end of file
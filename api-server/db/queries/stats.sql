@@ -0,0 +1,39 @@
+-- name: CountUsers :one
+SELECT COUNT(*) AS count
+FROM users
+WHERE deleted_at IS NULL;
+
+-- name: CountAddressesByChain :many
+SELECT
+    chain,
+    COUNT(*) AS addresses,
+    COUNT(DISTINCT user_id) AS users
+FROM addresses
+WHERE deleted_at IS NULL
+GROUP BY chain
+ORDER BY chain;
+
+-- name: CountAlertsSince :one
+SELECT COUNT(*) AS count
+FROM alerts
+WHERE created_at >= $1;
+
+-- name: CountDeliveriesSince :many
+SELECT
+    channel,
+    status,
+    COUNT(*) AS count
+FROM notification_deliveries
+WHERE created_at >= $1
+GROUP BY channel, status
+ORDER BY channel, status;
+
+-- name: ListChainHeads :many
+SELECT
+    chain,
+    MAX(block_number)::BIGINT AS block_number,
+    MAX(occurred_at)::TIMESTAMPTZ AS occurred_at
+FROM transactions
+WHERE created_at >= $1
+GROUP BY chain
+ORDER BY chain;
\ No newline at end of file
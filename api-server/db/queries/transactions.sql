@@ -1,38 +1,90 @@
--- name: CreateUser :one
-INSERT INTO users (
+-- name: UpsertTransaction :one
+WITH transaction_key AS (
+    INSERT INTO transaction_keys (chain, hash, log_index, transaction_id, created_at)
+    VALUES ($2, $3, $4, $1, NOW())
+    -- A no-op update, so the key of a known transfer is returned
+    ON CONFLICT (chain, hash, log_index) DO UPDATE
+    SET chain = EXCLUDED.chain
+    RETURNING transaction_id, created_at
+)
+INSERT INTO transactions (
     id,
-    email,
-    password_hash,
-    phone_number,
-    wallet_address,
-    subscribed,
+    chain,
+    hash,
+    log_index,
+    block_number,
+    block_hash,
+    from_address,
+    to_address,
+    value,
+    token_address,
+    status,
+    occurred_at,
     created_at,
     updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, NOW(), NOW()
+    (SELECT transaction_id FROM transaction_key),
+    $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+    (SELECT created_at FROM transaction_key),
+    NOW()
 )
+ON CONFLICT (id, created_at) DO UPDATE
+SET
+    block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    status = EXCLUDED.status,
+    occurred_at = EXCLUDED.occurred_at,
+    updated_at = NOW()
 RETURNING
     id;
 
--- name: SignInUser :one
+-- name: GetTransactionsByHash :many
 SELECT
     id,
-    email,
-    password_hash,
-    phone_number,
-    wallet_address,
-    subscribed,
+    chain,
+    hash,
+    log_index,
+    block_number,
+    block_hash,
+    from_address,
+    to_address,
+    value,
+    token_address,
+    status,
+    occurred_at,
     created_at,
-    updated_at,
-    deleted_at
-FROM users
-WHERE email = $1 AND deleted_at IS NULL;
+    updated_at
+FROM transactions
+WHERE chain = $1 AND hash = $2
+ORDER BY log_index;
 
--- name: SoftDeleteUser :exec
-UPDATE users
-SET deleted_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL;
+-- name: ListTransactionsByAddress :many
+SELECT
+    id,
+    chain,
+    hash,
+    log_index,
+    block_number,
+    block_hash,
+    from_address,
+    to_address,
+    value,
+    token_address,
+    status,
+    occurred_at,
+    created_at,
+    updated_at
+FROM transactions
+WHERE chain = sqlc.arg(chain)
+    AND (from_address = sqlc.arg(address) OR to_address = sqlc.arg(address))
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+        OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
 
--- name: HardDeleteUser :exec
-DELETE FROM users
-WHERE id = $1;
\ No newline at end of file
+-- name: UpdateTransactionStatus :exec
+UPDATE transactions
+SET status = $2, updated_at = NOW()
+WHERE id = $1
+    -- The partition key, so only the transfer's partition is scanned
+    AND created_at = (SELECT created_at FROM transaction_keys WHERE transaction_id = $1);
\ No newline at end of file
@@ -0,0 +1,127 @@
+-- name: CreateUser :one
+INSERT INTO users (
+    id,
+    email,
+    password_hash,
+    phone_number,
+    wallet_address,
+    subscribed,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, NOW(), NOW()
+)
+RETURNING
+    id;
+
+-- name: SignInUser :one
+SELECT
+    id,
+    email,
+    password_hash,
+    phone_number,
+    wallet_address,
+    subscribed,
+    created_at,
+    updated_at,
+    deleted_at,
+    version,
+    email_verified_at
+FROM users
+WHERE email = $1 AND deleted_at IS NULL;
+
+-- name: GetUserByID :one
+SELECT
+    id,
+    email,
+    password_hash,
+    phone_number,
+    wallet_address,
+    subscribed,
+    created_at,
+    updated_at,
+    deleted_at,
+    version,
+    email_verified_at
+FROM users
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: UpdateUserProfile :execrows
+UPDATE users
+SET phone_number = $2, wallet_address = $3, subscribed = $4, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND version = $5 AND deleted_at IS NULL;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users
+SET password_hash = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CreateEmailVerification :exec
+WITH superseded AS (
+    DELETE FROM email_verifications
+    WHERE user_id = $2
+)
+INSERT INTO email_verifications (token_hash, user_id, email, expires_at, created_at)
+VALUES ($1, $2, $3, $4, NOW());
+
+-- name: VerifyEmail :one
+WITH consumed AS (
+    DELETE FROM email_verifications
+    WHERE token_hash = $1
+    RETURNING user_id, email, expires_at
+), verified AS (
+    UPDATE users u
+    SET email_verified_at = NOW(), updated_at = NOW()
+    FROM consumed c
+    -- A link sent before the email changed no longer verifies it
+    WHERE u.id = c.user_id AND u.email = c.email AND c.expires_at > NOW() AND u.deleted_at IS NULL
+    RETURNING u.id, u.email
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT id, 'email.verified', jsonb_build_object('email', email), NOW()
+FROM verified
+RETURNING
+    user_id;
+
+-- name: SoftDeleteUser :exec
+UPDATE users
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: HardDeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: ExportUsers :many
+SELECT
+    id,
+    email,
+    password_hash,
+    phone_number,
+    wallet_address,
+    subscribed,
+    created_at,
+    updated_at,
+    deleted_at,
+    version,
+    email_verified_at
+FROM users
+WHERE deleted_at IS NULL
+    AND (sqlc.narg(after_id)::uuid IS NULL OR id > sqlc.narg(after_id))
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: ImportUser :execrows
+INSERT INTO users (
+    id,
+    email,
+    password_hash,
+    phone_number,
+    wallet_address,
+    subscribed,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT DO NOTHING;
\ No newline at end of file
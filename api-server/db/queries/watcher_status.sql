@@ -0,0 +1,3 @@
+-- name: GetWatcherStatus :one
+SELECT * FROM watcher_status
+WHERE chain = $1 AND address = $2;
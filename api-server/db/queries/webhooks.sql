@@ -0,0 +1,103 @@
+-- name: CreateWebhook :one
+WITH created AS (
+    INSERT INTO webhooks (
+        id,
+        user_id,
+        url,
+        secret,
+        enabled,
+        delivery_mode,
+        batch_max_events,
+        batch_interval_seconds,
+        created_at,
+        updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()
+    )
+    RETURNING id, user_id, url
+), event AS (
+    INSERT INTO account_events (user_id, kind, detail, created_at)
+    SELECT user_id, 'webhook.created', jsonb_build_object('webhook_id', id, 'url', url), NOW()
+    FROM created
+)
+SELECT
+    id
+FROM created;
+
+-- name: GetWebhook :one
+SELECT
+    id,
+    user_id,
+    url,
+    secret,
+    enabled,
+    created_at,
+    updated_at,
+    deleted_at,
+    delivery_mode,
+    batch_max_events,
+    batch_interval_seconds,
+    verified_at
+FROM webhooks
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::bool);
+
+-- name: ListWebhooksByUser :many
+SELECT
+    id,
+    user_id,
+    url,
+    secret,
+    enabled,
+    created_at,
+    updated_at,
+    deleted_at,
+    delivery_mode,
+    batch_max_events,
+    batch_interval_seconds,
+    verified_at
+FROM webhooks
+WHERE user_id = sqlc.arg(user_id)
+    AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::bool)
+ORDER BY created_at;
+
+-- name: UpdateWebhook :execrows
+WITH updated AS (
+    UPDATE webhooks
+    SET
+        url = $3,
+        enabled = $4,
+        delivery_mode = $5,
+        batch_max_events = $6,
+        batch_interval_seconds = $7,
+        -- A new URL is verified again
+        verified_at = CASE WHEN url = $3 THEN verified_at END,
+        updated_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, url
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT user_id, 'webhook.updated', jsonb_build_object('webhook_id', id, 'url', url), NOW()
+FROM updated;
+
+-- name: SoftDeleteWebhook :execrows
+WITH deleted AS (
+    UPDATE webhooks
+    SET deleted_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id, user_id, url
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT user_id, 'webhook.deleted', jsonb_build_object('webhook_id', id, 'url', url), NOW()
+FROM deleted;
+
+-- name: MarkWebhookVerified :execrows
+WITH verified AS (
+    UPDATE webhooks
+    SET verified_at = NOW(), updated_at = NOW()
+    WHERE id = $1 AND user_id = $2 AND url = $3 AND deleted_at IS NULL
+    RETURNING id, user_id, url
+)
+INSERT INTO account_events (user_id, kind, detail, created_at)
+SELECT user_id, 'webhook.verified', jsonb_build_object('webhook_id', id, 'url', url), NOW()
+FROM verified;
\ No newline at end of file
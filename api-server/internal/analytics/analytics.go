@@ -0,0 +1,148 @@
+// Package analytics records each user's API usage: the calls, errors and latencies of every
+// endpoint per UTC day, served back by GET /api/v1/users/me/analytics. Calls are counted in memory
+// and added to the api_usage table every ANALYTICS_FLUSH_INTERVAL, so the usage of the latest
+// interval is not visible yet and is lost when the process is killed.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+)
+
+// pruneInterval is how often the usage older than ANALYTICS_RETENTION is deleted
+const pruneInterval = 24 * time.Hour
+
+// endpoint identifies the counters of one user's calls of an endpoint on a UTC day
+type endpoint struct {
+	userID uuid.UUID
+	day    time.Time
+	method string
+	route  string
+}
+
+// usage counts the calls of an endpoint
+type usage struct {
+	calls        int64
+	clientErrors int64
+	serverErrors int64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+}
+
+func (u *usage) add(o usage) {
+	u.calls += o.calls
+	u.clientErrors += o.clientErrors
+	u.serverErrors += o.serverErrors
+	u.totalLatency += o.totalLatency
+	u.maxLatency = max(u.maxLatency, o.maxLatency)
+}
+
+// Recorder counts API calls and writes them to the api_usage table
+type Recorder struct {
+	repo postgres.IAPIUsageInterface
+	cfg  config.Analytics
+
+	mu      sync.Mutex
+	pending map[endpoint]*usage
+}
+
+func New(repo postgres.IAPIUsageInterface, cfg config.Analytics) *Recorder {
+	return &Recorder{
+		repo:    repo,
+		cfg:     cfg,
+		pending: map[endpoint]*usage{},
+	}
+}
+
+// Record counts a call of userID to the endpoint method route, e.g. GET /api/v1/jobs/:id,
+// answered with status after latency
+func (r *Recorder) Record(userID uuid.UUID, method, route string, status int, latency time.Duration) {
+	call := usage{calls: 1, totalLatency: latency, maxLatency: latency}
+	switch {
+	case status >= 500:
+		call.serverErrors = 1
+	case status >= 400:
+		call.clientErrors = 1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.merge(endpoint{userID: userID, day: utils.ToPgDate(time.Now()).Time, method: method, route: route}, call)
+}
+
+// merge adds u to the pending counters of e, r.mu held
+func (r *Recorder) merge(e endpoint, u usage) {
+	if p, ok := r.pending[e]; ok {
+		p.add(u)
+		return
+	}
+	r.pending[e] = &u
+}
+
+// Run writes the counted calls every ANALYTICS_FLUSH_INTERVAL and deletes the usage older than
+// ANALYTICS_RETENTION once a day, until ctx is done. The calls counted afterwards, e.g. while the
+// server shuts down, are written by a last Flush.
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+	var pruned time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.Flush(ctx)
+		if time.Since(pruned) >= pruneInterval {
+			pruned = time.Now()
+			if _, err := r.repo.DeleteUsageBefore(ctx, pruned.Add(-r.cfg.Retention)); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to delete aged API usage: %v", err)
+			}
+		}
+	}
+}
+
+// Flush writes the counted calls. Counters that failed to be written are kept for the next flush,
+// except those of users deleted meanwhile.
+func (r *Recorder) Flush(ctx context.Context) {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = map[endpoint]*usage{}
+	r.mu.Unlock()
+
+	var failed int
+	var lastErr error
+	for e, u := range pending {
+		err := r.repo.RecordUsage(ctx, sqlc.RecordApiUsageParams{
+			UserID:         e.userID,
+			Day:            utils.ToPgDate(e.day),
+			Method:         e.method,
+			Route:          e.route,
+			Calls:          u.calls,
+			ClientErrors:   u.clientErrors,
+			ServerErrors:   u.serverErrors,
+			TotalLatencyUs: u.totalLatency.Microseconds(),
+			MaxLatencyUs:   u.maxLatency.Microseconds(),
+		})
+		if err == nil || errors.Is(err, postgres.ErrMissingReference) {
+			continue
+		}
+		failed, lastErr = failed+1, err
+		r.mu.Lock()
+		r.merge(e, *u)
+		r.mu.Unlock()
+	}
+	if failed > 0 {
+		log.Printf("Failed to record the API usage of %d endpoints, retrying: %v", failed, lastErr)
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ActivityHandler struct {
+	service   service.IActivityService
+	validator *validator.Validate
+}
+
+func NewActivityHandler(activityService service.IActivityService, validator *validator.Validate) *ActivityHandler {
+	return &ActivityHandler{
+		service:   activityService,
+		validator: validator,
+	}
+}
+
+// AddressActivity handles listing an address's activity
+// @Summary List an address's activity
+// @Description List the address's transactions, alerts, and changes of the address and its rules such as edits, pauses and mutes, newest first
+// @Tags addresses
+// @Produce json
+// @Param id path string true "Address ID"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Maximum entries, 1 to 200 (default 50)"
+// @Param If-None-Match header string false "ETag of the page held, answered with 304 while unchanged"
+// @Success 200 {object} dto.Envelope{data=[]dto.ActivityItem}
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/activity [get]
+func (h *ActivityHandler) AddressActivity(c *fiber.Ctx) error {
+	var req dto.ListActivityRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.AddressActivity(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list address activity",
+			Details: err.Error(),
+		})
+	}
+
+	return respondPage(c, status, res.Items, res.Pagination)
+}
+
+// AddressTransactions handles listing an address's transaction history
+// @Summary List an address's transactions
+// @Description List the transfers from or to the address stored by the engine, newest first. A transaction moving several tokens has one entry per transfer; direction is in, out or self.
+// @Tags addresses
+// @Produce json
+// @Param id path string true "Address ID"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Maximum entries, 1 to 200 (default 50)"
+// @Param If-None-Match header string false "ETag of the page held, answered with 304 while unchanged"
+// @Success 200 {object} dto.Envelope{data=[]dto.AddressTransaction}
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/transactions [get]
+func (h *ActivityHandler) AddressTransactions(c *fiber.Ctx) error {
+	var req dto.ListTransactionsRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.AddressTransactions(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list address transactions",
+			Details: err.Error(),
+		})
+	}
+
+	return respondPage(c, status, res.Items, res.Pagination)
+}
+
+// AccountActivity handles listing the signed-in user's account activity
+// @Summary List the account's activity
+// @Description List the user's sign-ins, failed sign-ins and changes of their profile, password, webhooks, API keys and watched addresses, newest first
+// @Tags users
+// @Produce json
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Maximum entries, 1 to 200 (default 50)"
+// @Param If-None-Match header string false "ETag of the page held, answered with 304 while unchanged"
+// @Success 200 {object} dto.Envelope{data=[]dto.AccountActivityItem}
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/activity [get]
+func (h *ActivityHandler) AccountActivity(c *fiber.Ctx) error {
+	var req dto.ListActivityRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.AccountActivity(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list account activity",
+			Details: err.Error(),
+		})
+	}
+
+	return respondPage(c, status, res.Items, res.Pagination)
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AddressHandler struct {
+	service   service.IAddressService
+	validator *validator.Validate
+}
+
+func NewAddressHandler(addressService service.IAddressService, validator *validator.Validate) *AddressHandler {
+	return &AddressHandler{
+		service:   addressService,
+		validator: validator,
+	}
+}
+
+// ListAddresses handles listing the user's watched addresses
+// @Summary List watched addresses
+// @Description List the user's watched addresses, oldest first
+// @Tags addresses
+// @Produce json
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Maximum addresses, 1 to 200 (default 50)"
+// @Param If-None-Match header string false "ETag of the page held, answered with 304 while unchanged"
+// @Success 200 {object} dto.Envelope{data=[]dto.AddressResponse}
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses [get]
+func (h *AddressHandler) ListAddresses(c *fiber.Ctx) error {
+	var req dto.ListAddressesRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ListAddresses(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list addresses",
+			Details: err.Error(),
+		})
+	}
+
+	return respondPage(c, status, res.Items, res.Pagination)
+}
+
+// GetAddress handles reading a watched address
+// @Summary Get a watched address
+// @Description Get one of the user's watched addresses with its label, notes and version
+// @Tags addresses
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} dto.Envelope{data=dto.AddressResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id} [get]
+func (h *AddressHandler) GetAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.GetAddress(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to get address",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// CreateAddress handles watching a new address
+// @Summary Watch an address
+// @Description Watch an address on a chain, with an optional label (its nickname) and notes. The chain is one of the supported chains (ethereum, polygon, arbitrum, optimism, base, bsc, avalanche, bitcoin, solana) and the address must have its format; EVM and bech32 addresses are stored in lowercase. Watching the same address on the same chain twice fails with 409.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAddressRequest true "Chain, address, label and notes"
+// @Success 201 {object} dto.Envelope{data=dto.AddressResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses [post]
+func (h *AddressHandler) CreateAddress(c *fiber.Ctx) error {
+	var req dto.CreateAddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.CreateAddress(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to create address",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// UpdateAddress handles relabeling a watched address
+// @Summary Update a watched address
+// @Description Replace the label and notes of an address, based on the version last read. The update fails with 409 when the address was changed since; read it again and retry.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Address ID"
+// @Param request body dto.UpdateAddressRequest true "Label, notes and version"
+// @Success 200 {object} dto.Envelope{data=dto.AddressResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id} [put]
+func (h *AddressHandler) UpdateAddress(c *fiber.Ctx) error {
+	var req dto.UpdateAddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.UpdateAddress(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to update address",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// DeleteAddress handles no longer watching an address
+// @Summary Delete a watched address
+// @Description Stop watching the address, returning it as it was. The rules that apply only to it are deleted with it; its past alerts are kept.
+// @Tags addresses
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} dto.Envelope{data=dto.AddressResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id} [delete]
+func (h *AddressHandler) DeleteAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.DeleteAddress(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to delete address",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
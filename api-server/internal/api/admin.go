@@ -0,0 +1,155 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/email"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/severity"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler struct {
+	service service.IAdminService
+}
+
+func NewAdminHandler(adminService service.IAdminService) *AdminHandler {
+	return &AdminHandler{service: adminService}
+}
+
+// SetupAdminRoutes configures the operations and support endpoints, for requests presenting one of
+// the bearer tokens returned by credentials. engineURL is the engine admin server the consumer lag
+// is read from.
+func SetupAdminRoutes(app *fiber.App, repos postgres.Repositories, engineURL string, credentials func() AdminCredentials) {
+	adminHandler := NewAdminHandler(service.NewAdminService(repos.Stats, engineURL))
+	impersonationHandler := NewImpersonationHandler(
+		service.NewImpersonationService(repos.Users, repos.Sessions, repos.AccountEvents, email.NewSMTPSender()),
+		service.NewSessionService(repos.Sessions),
+		validators.NewValidator())
+
+	admin := app.Group("/api/v1/admin", AdminMiddleware(credentials))
+	{
+		admin.Get("/stats", Timeout(reportingBudget), adminHandler.Stats)
+		// A sample alert email in the configured EMAIL_* theme
+		admin.Get("/email/preview", adminHandler.EmailPreview)
+		// Support staff act as a user to reproduce their issue, audited in the user's activity
+		admin.Post("/users/:id/impersonate", Timeout(requestBudget), impersonationHandler.Impersonate)
+		// Ends an impersonation, or any other session of the user, before it expires
+		admin.Delete("/users/:id/sessions/:session_id", Timeout(requestBudget), impersonationHandler.RevokeSession)
+	}
+}
+
+// AdminOperatorLocal holds the support operator whose own token an admin request presented, unset
+// for the shared ADMIN_TOKEN
+const AdminOperatorLocal = "admin_operator"
+
+// AdminCredentials are the bearer tokens admin requests may present: Token, the shared
+// ADMIN_TOKEN, and Operators, the ADMIN_OPERATOR_TOKENS entries "name=sha256" of the support
+// operators' own tokens
+type AdminCredentials struct {
+	Token     string
+	Operators []string
+}
+
+// AdminMiddleware rejects requests without one of the bearer tokens returned by credentials, and
+// sets AdminOperatorLocal for an operator's own token. credentials is called on every request so
+// rotated tokens take effect at once; while it returns none every request is rejected.
+func AdminMiddleware(credentials func() AdminCredentials) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		creds := credentials()
+		got, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if ok && got != "" {
+			if creds.Token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(creds.Token)) == 1 {
+				return c.Next()
+			}
+			if operator := tokenOperator(creds.Operators, got); operator != "" {
+				c.Locals(AdminOperatorLocal, operator)
+				return c.Next()
+			}
+		}
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+		return fiber.ErrUnauthorized
+	}
+}
+
+// tokenOperator returns the operator of operators whose token is token, "" for none. Only the
+// hashes of the tokens are configured, and each is compared in constant time.
+func tokenOperator(operators []string, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	for _, entry := range operators {
+		name, hexSum, _ := strings.Cut(entry, "=")
+		want, err := hex.DecodeString(hexSum)
+		if err == nil && name != "" && subtle.ConstantTimeCompare(sum[:], want) == 1 {
+			return name
+		}
+	}
+	return ""
+}
+
+// Stats handles the operations dashboard snapshot
+// @Summary Operations statistics
+// @Description Users, watched addresses per chain, alerts and notification failure rates of the last 24 hours, per-chain head lag and the engine's consumer lag. Requires the ADMIN_TOKEN bearer token.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=dto.AdminStatsResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/admin/stats [get]
+func (h *AdminHandler) Stats(c *fiber.Ctx) error {
+	status, res, err := h.service.Stats(c.UserContext())
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to collect statistics",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// EmailPreview handles rendering a sample alert email
+// @Summary Preview alert emails
+// @Description Render a sample alert email in the theme configured by the EMAIL_* settings, so operators can check their branding. The subject is returned in the X-Email-Subject header. Requires the ADMIN_TOKEN bearer token.
+// @Tags admin
+// @Produce html
+// @Param severity query string false "Severity of the sample alert: info, warning or critical" default(warning)
+// @Param format query string false "html or text" default(html)
+// @Success 200 {string} string "The email body"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/admin/email/preview [get]
+func (h *AdminHandler) EmailPreview(c *fiber.Ctx) error {
+	s := c.Query("severity", severity.Default)
+	format := c.Query("format", "html")
+	if !severity.Valid(s) || (format != "html" && format != "text") {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: "severity must be info, warning or critical and format html or text",
+		})
+	}
+
+	msg, err := email.Render(config.GetConfig().Email, email.Sample(s))
+	if err != nil {
+		return respondError(c, fiber.StatusInternalServerError, dto.ErrorResponse{
+			Message: "Failed to render the email",
+			Details: err.Error(),
+		})
+	}
+
+	c.Set("X-Email-Subject", msg.Subject)
+	if format == "text" {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(msg.Text)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(msg.HTML)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/analytics"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RecordUsage counts the calls of signed-in users with recorder, by the route they matched.
+// Requests without a user, e.g. rejected by the JWT middleware, are not counted.
+func RecordUsage(recorder *analytics.Recorder) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		id, _ := c.Locals("user_id").(string)
+		userID, parseErr := uuid.Parse(id)
+		if parseErr != nil {
+			return err
+		}
+		recorder.Record(userID, c.Method(), c.Route().Path, responseStatus(c, err), time.Since(start))
+		return err
+	}
+}
+
+// responseStatus returns the status of the response to c, whose handlers returned err
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	// Written by the error handler once the middleware returned
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+type AnalyticsHandler struct {
+	service   service.IAnalyticsService
+	validator *validator.Validate
+}
+
+func NewAnalyticsHandler(analyticsService service.IAnalyticsService, validator *validator.Validate) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service:   analyticsService,
+		validator: validator,
+	}
+}
+
+// Usage handles the signed-in user's API usage
+// @Summary API usage analytics
+// @Description Calls, 4xx and 5xx responses and latencies of the user's requests per endpoint, over the period and per UTC day. Calls show up within ANALYTICS_FLUSH_INTERVAL (30 seconds by default).
+// @Tags users
+// @Produce json
+// @Param days query int false "Days reported, today included, 1 to 90 (default 30)"
+// @Success 200 {object} dto.Envelope{data=dto.APIAnalyticsResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/analytics [get]
+func (h *AnalyticsHandler) Usage(c *fiber.Ctx) error {
+	var req dto.APIAnalyticsRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.Usage(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to report API usage",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/signing"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/tenant"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader carries the API key of requests authenticated with one instead of a token
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth authenticates requests presenting an API key in the X-API-Key header, or signed with
+// one as described in package signing, as the key's user, for the routes behind jwt.JWTMiddleware
+// and those of the key's scopes only, see jwt.RequireScope. Unknown, revoked and expired keys,
+// invalid, stale and replayed signatures are rejected with 401, and keys used from outside their
+// allowed networks with 403. Requests with neither are left to jwt.JWTMiddleware.
+func APIKeyAuth(keys service.IAPIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var (
+			status int
+			key    *sqlc.ApiKey
+			err    error
+		)
+		switch {
+		case c.Get(signing.SignatureHeader) != "":
+			status, key, err = keys.AuthenticateSigned(c.UserContext(), service.SignedRequest{
+				KeyID:     c.Get(signing.KeyIDHeader),
+				Timestamp: c.Get(signing.TimestampHeader),
+				Nonce:     c.Get(signing.NonceHeader),
+				Signature: c.Get(signing.SignatureHeader),
+				Method:    c.Method(),
+				URI:       c.OriginalURL(),
+				Body:      c.Body(),
+				IP:        c.IP(),
+			})
+		case c.Get(APIKeyHeader) != "":
+			status, key, err = keys.Authenticate(c.UserContext(), c.Get(APIKeyHeader), c.IP())
+		default:
+			return c.Next()
+		}
+		if err != nil {
+			return fiber.NewError(status, err.Error())
+		}
+
+		c.SetUserContext(tenant.WithUser(c.UserContext(), key.UserID))
+		c.Locals("user_id", key.UserID.String())
+		c.Locals(jwt.APIKeyIDLocal, key.ID.String())
+		c.Locals(jwt.APIKeyScopesLocal, key.Scopes)
+
+		return c.Next()
+	}
+}
+
+type APIKeyHandler struct {
+	service   service.IAPIKeyService
+	validator *validator.Validate
+}
+
+func NewAPIKeyHandler(apiKeyService service.IAPIKeyService, validator *validator.Validate) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:   apiKeyService,
+		validator: validator,
+	}
+}
+
+// CreateKey handles creating an API key
+// @Summary Create an API key
+// @Description Create an API key for programmatic access, presented in the X-API-Key header. The key is only returned here. A signed key is never sent: it comes with a signing_secret, also only returned here, and requests carry its ID in X-Key-ID, the Unix time in X-Timestamp, a random X-Nonce of 16 to 64 characters and in X-Signature "v1=" and the hex HMAC-SHA256, keyed with the signing secret, of the method, path and query, timestamp, nonce and hex SHA-256 of the body, joined by newlines. Timestamps off by more than SERVER_SIGNATURE_MAX_SKEW and reused nonces are rejected. It reaches the routes of its scopes only: read:account, write:account, read:addresses, write:addresses, read:rules, write:rules and read:alerts; others are answered with 403. allowed_cidrs restricts the networks it is accepted from, e.g. the addresses of the integrating server; requests from elsewhere are answered with 403.
+// @Tags api keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "API key"
+// @Success 201 {object} dto.Envelope{data=dto.CreatedAPIKeyResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 503 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/api-keys [post]
+func (h *APIKeyHandler) CreateKey(c *fiber.Ctx) error {
+	var req dto.CreateAPIKeyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.CreateKey(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to create API key",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// ListKeys handles listing the user's API keys
+// @Summary List API keys
+// @Description List the user's API keys, revoked ones included, identified by their prefix
+// @Tags api keys
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=[]dto.APIKeyResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/api-keys [get]
+func (h *APIKeyHandler) ListKeys(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ListKeys(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list API keys",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// RevokeKey handles revoking an API key
+// @Summary Revoke an API key
+// @Description Revoke the API key; requests presenting it are rejected at once
+// @Tags api keys
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, err := h.service.RevokeKey(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to revoke API key",
+			Details: err.Error(),
+		})
+	}
+
+	return c.SendStatus(status)
+}
+
+// SetAllowedCIDRs handles restricting the networks an API key is accepted from
+// @Summary Set an API key's allowed networks
+// @Description Replace the networks the API key is accepted from, in CIDR notation or as single addresses, at most 32. Requests presenting the key from other addresses are answered with 403. An empty list accepts the key from anywhere. Behind a reverse proxy the client's address is read from SERVER_PROXY_HEADER for requests from SERVER_TRUSTED_PROXIES only.
+// @Tags api keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body dto.SetAPIKeyAllowedCIDRsRequest true "Allowed networks"
+// @Success 200 {object} dto.Envelope{data=dto.APIKeyResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/api-keys/{id}/allowed-cidrs [put]
+func (h *APIKeyHandler) SetAllowedCIDRs(c *fiber.Ctx) error {
+	var req dto.SetAPIKeyAllowedCIDRsRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.SetAllowedCIDRs(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to update API key",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// SetScopes handles replacing the scopes of an API key
+// @Summary Set an API key's scopes
+// @Description Replace the scopes of the API key, at least one of read:account, write:account, read:addresses, write:addresses, read:rules, write:rules and read:alerts. Requests presenting the key to routes of other scopes are answered with 403.
+// @Tags api keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body dto.SetAPIKeyScopesRequest true "Scopes"
+// @Success 200 {object} dto.Envelope{data=dto.APIKeyResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/api-keys/{id}/scopes [put]
+func (h *APIKeyHandler) SetScopes(c *fiber.Ctx) error {
+	var req dto.SetAPIKeyScopesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.SetScopes(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to update API key",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
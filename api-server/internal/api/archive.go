@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/retention"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxArchivedRange bounds the period of one retrieval of archived alerts, every object holding
+// alerts of the period is read
+const maxArchivedRange = 31 * 24 * time.Hour
+
+type ArchiveHandler struct {
+	archiver *retention.Archiver
+}
+
+func NewArchiveHandler(archiver *retention.Archiver) *ArchiveHandler {
+	return &ArchiveHandler{archiver: archiver}
+}
+
+// SetupArchiveRoutes configures the routes reading archived records, registered when an archive
+// is configured
+func SetupArchiveRoutes(app *fiber.App, archiver *retention.Archiver) {
+	archiveHandler := NewArchiveHandler(archiver)
+
+	// Alerts moved to the archive once past ARCHIVE_ALERT_RETENTION
+	app.Get("/api/v1/alerts/archived", Timeout(reportingBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAlerts),
+		archiveHandler.ArchivedAlerts)
+}
+
+// ArchivedAlerts handles retrieving archived alerts
+// @Summary Retrieve archived alerts
+// @Description Read the user's alerts created in [from, to) back from the archive, oldest first, one JSON object per line. The period spans at most 31 days.
+// @Tags alerts
+// @Produce application/x-ndjson
+// @Param from query string true "Start of the period, RFC 3339"
+// @Param to query string true "End of the period, RFC 3339"
+// @Param If-None-Match header string false "ETag of the alerts held, answered with 304 while unchanged"
+// @Success 200 {string} string "Archived alerts, one per line"
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/alerts/archived [get]
+func (h *ArchiveHandler) ArchivedAlerts(c *fiber.Ctx) error {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: "from must be an RFC 3339 time",
+		})
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: "to must be an RFC 3339 time",
+		})
+	}
+	if !to.After(from) || to.Sub(from) > maxArchivedRange {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: "to must be after from, by at most 31 days",
+		})
+	}
+
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	// Buffered, so a failure midway is answered with an error rather than a truncated body
+	var body bytes.Buffer
+	if _, err := h.archiver.Retrieve(c.UserContext(), userID, from, to, &body); err != nil {
+		return respondError(c, fiber.StatusInternalServerError, dto.ErrorResponse{
+			Message: "Failed to retrieve archived alerts",
+			Details: err.Error(),
+		})
+	}
+	if notModified(c, body.Bytes()) {
+		return nil
+	}
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	return c.Send(body.Bytes())
+}
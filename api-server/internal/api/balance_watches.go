@@ -0,0 +1,163 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BalanceWatchHandler struct {
+	service   service.IBalanceWatchService
+	validator *validator.Validate
+}
+
+func NewBalanceWatchHandler(balanceWatchService service.IBalanceWatchService, validator *validator.Validate) *BalanceWatchHandler {
+	return &BalanceWatchHandler{
+		service:   balanceWatchService,
+		validator: validator,
+	}
+}
+
+// ListWatches handles listing the balance watches of an address
+// @Summary List the balance watches of an address
+// @Description The ERC-20 tokens whose balance held by the address is polled, with the balance deltas are measured from
+// @Tags balance-watches
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} dto.Envelope{data=[]dto.BalanceWatchResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/balance-watches [get]
+func (h *BalanceWatchHandler) ListWatches(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ListWatches(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list balance watches",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// CreateWatch handles watching the balance of a token held by an address
+// @Summary Watch a token balance
+// @Description Poll balanceOf(address) on an ERC-20 token at an interval, alerting when the balance moved by at least min_delta. Catches changes without Transfer events, e.g. of rebasing tokens. The first read sets the balance deltas are measured from; each alert sets it anew. Only addresses on the CHAIN_NETWORK node's chain can be watched.
+// @Tags balance-watches
+// @Accept json
+// @Produce json
+// @Param id path string true "Address ID"
+// @Param request body dto.CreateBalanceWatchRequest true "Token and polling settings"
+// @Success 201 {object} dto.Envelope{data=dto.BalanceWatchResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 422 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/balance-watches [post]
+func (h *BalanceWatchHandler) CreateWatch(c *fiber.Ctx) error {
+	var req dto.CreateBalanceWatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.CreateWatch(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to create balance watch",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// UpdateWatch handles changing the settings of a balance watch
+// @Summary Update a balance watch
+// @Description Replace the interval, min_delta, severity and enabled state of a balance watch. A new interval is counted from now.
+// @Tags balance-watches
+// @Accept json
+// @Produce json
+// @Param id path string true "Balance watch ID"
+// @Param request body dto.UpdateBalanceWatchRequest true "Polling settings"
+// @Success 200 {object} dto.Envelope{data=dto.BalanceWatchResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/balance-watches/{id} [put]
+func (h *BalanceWatchHandler) UpdateWatch(c *fiber.Ctx) error {
+	var req dto.UpdateBalanceWatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.UpdateWatch(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to update balance watch",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// DeleteWatch handles deleting a balance watch
+// @Summary Delete a balance watch
+// @Description Stop polling the token balance, returning the watch as it was. Its past alerts are kept.
+// @Tags balance-watches
+// @Produce json
+// @Param id path string true "Balance watch ID"
+// @Success 200 {object} dto.Envelope{data=dto.BalanceWatchResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/balance-watches/{id} [delete]
+func (h *BalanceWatchHandler) DeleteWatch(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.DeleteWatch(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to delete balance watch",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChannelHandler struct {
+	service   service.IChannelService
+	validator *validator.Validate
+}
+
+func NewChannelHandler(channelService service.IChannelService, validator *validator.Validate) *ChannelHandler {
+	return &ChannelHandler{
+		service:   channelService,
+		validator: validator,
+	}
+}
+
+// ListChannels handles listing the verification state of the user's notification channels
+// @Summary List notification channels
+// @Description The signed-in user's email and webhooks, and whether each is verified. Alerts are only delivered over verified channels.
+// @Tags channels
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=[]dto.ChannelResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/channels [get]
+func (h *ChannelHandler) ListChannels(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ListChannels(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list channels",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// SendEmailVerification handles sending the link confirming the user's email
+// @Summary Send an email confirmation link
+// @Description Email the signed-in user a link to EMAIL_APP_URL/verify-email?token=..., valid for 24 hours, whose token confirms their email with POST /api/v1/users/verify-email. A new link replaces the previous.
+// @Tags channels
+// @Produce json
+// @Success 202 {object} dto.Envelope{data=dto.ChannelResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 502 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 503 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/email/verification [post]
+func (h *ChannelHandler) SendEmailVerification(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.SendEmailVerification(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to send the confirmation link",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// VerifyEmail handles confirming an email with the token of its link
+// @Summary Confirm an email
+// @Description Verify the email the token's link was sent to. Tokens are used once, and fail once expired or when the email changed since.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyEmailRequest true "Token of the confirmation link"
+// @Success 200 {object} dto.Envelope{data=dto.ChannelResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/verify-email [post]
+func (h *ChannelHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	status, res, err := h.service.VerifyEmail(c.UserContext(), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to verify email",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// VerifyWebhook handles verifying a webhook
+// @Summary Verify a webhook
+// @Description Post a signed webhook.verification event with a random challenge to the webhook, which must answer 2xx with the challenge as its body or as the challenge field of a JSON object. Alerts are only posted to verified webhooks; changing the URL requires verifying it again.
+// @Tags channels
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.Envelope{data=dto.ChannelResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 422 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/webhooks/{id}/verify [post]
+func (h *ChannelHandler) VerifyWebhook(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.VerifyWebhook(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to verify webhook",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DashboardHandler struct {
+	service service.IDashboardService
+}
+
+func NewDashboardHandler(dashboardService service.IDashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		service: dashboardService,
+	}
+}
+
+// Dashboard handles summarizing the signed-in user's watch list
+// @Summary Get the dashboard
+// @Description Summary for home screens: the watched, active and muted addresses, the alerts of the last 24 hours and 7 days, the addresses with the most transactions in the last 7 days, and the native balance of the addresses on the node's network in its smallest unit
+// @Tags users
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=dto.DashboardResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/dashboard [get]
+func (h *DashboardHandler) Dashboard(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.Dashboard(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to get dashboard",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
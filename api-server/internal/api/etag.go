@@ -0,0 +1,41 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// notModified sets the ETag of a response with payload and reports whether the copy the client
+// names in If-None-Match is still current, in which case the response is 304 without a body and
+// the caller writes nothing more. The ETag is weak since the same payload is served under
+// different content encodings. Responses are private to the user and revalidated on every use.
+func notModified(c *fiber.Ctx, payload []byte) bool {
+	sum := sha256.Sum256(payload)
+	etag := `W/"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+	if !etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return false
+	}
+	c.Status(fiber.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, comparing weakly
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FeeHandler struct {
+	service service.IFeeService
+}
+
+func NewFeeHandler(feeService service.IFeeService) *FeeHandler {
+	return &FeeHandler{
+		service: feeService,
+	}
+}
+
+// Fees handles fee estimation
+// @Summary Estimate transaction fees
+// @Description Current fee estimates of the chain from the watcher's node, cached for CHAIN_FEE_CACHE_TTL: slow, standard and fast EIP-1559 fees in wei, or fee rates in sat/vB on Bitcoin
+// @Tags chains
+// @Produce json
+// @Param chain path string true "Chain, e.g. ethereum or bitcoin"
+// @Success 200 {object} dto.Envelope{data=dto.FeesResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 502 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/chains/{chain}/fees [get]
+func (h *FeeHandler) Fees(c *fiber.Ctx) error {
+	status, res, err := h.service.Fees(c.UserContext(), c.Params("chain"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to estimate fees",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -27,97 +28,298 @@ func NewUserHandler(userService service.IUserService, validator *validator.Valid
 // @Accept json
 // @Produce json
 // @Param request body dto.RegisterRequest true "User registration details"
-// @Success 201 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 201 {object} dto.Envelope{data=dto.RegisterUserResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
 // @Router /api/users/register [post]
 func (h *UserHandler) Register(c *fiber.Ctx) error {
 	var req dto.RegisterUserRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error:   "Invalid request body",
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
 			Details: err.Error(),
 		})
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error:   "Validation failed",
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
 			Details: "Please check the fields and try again",
-			Fields:  validators.GetValidationErrors(err),
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
 		})
 	}
 
-	status, userID, err := h.service.RegisterUser(req)
+	status, userID, err := h.service.RegisterUser(c.UserContext(), req)
 	if err != nil {
-		c.Status(status).JSON(dto.ErrorResponse{
-			Error:   "Failed to register",
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to register",
 			Details: err.Error(),
 		})
 	}
 
-	return c.Status(status).JSON(fiber.Map{"id": userID})
+	return respond(c, status, dto.RegisterUserResponse{ID: userID})
 }
 
 // Login handles user login
 // @Summary Login user
-// @Description Authenticate user with email and password
+// @Description Authenticate user with email and password, starting a session. The access token expires after JWT_EXPIRY; exchange the refresh token for the next one at /api/v1/users/refresh.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "Login credentials"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} dto.Envelope{data=dto.LoginResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
 // @Router /api/users/login [post]
 func (h *UserHandler) Login(c *fiber.Ctx) error {
 	var req dto.LoginRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error:   "Invalid request body",
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
 			Details: err.Error(),
 		})
 	}
 
-	// Service layer handles authentication logic
-	// TODO: Implement password verification and JWT token generation in service layer
-	status, res, err := h.service.Login(req)
+	// Sign-ins are recorded with the client they came from
+	req.IPAddress, req.UserAgent = c.IP(), c.Get(fiber.HeaderUserAgent)
+	status, res, err := h.service.Login(c.UserContext(), req)
 	if err != nil {
-		return c.Status(status).JSON(dto.ErrorResponse{
-			Error:   "Failed to authenticate",
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to authenticate",
 			Details: err.Error(),
 		})
 	}
 
 	if res == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
-			Error: "Invalid credentials",
+		return respondError(c, fiber.StatusUnauthorized, dto.ErrorResponse{
+			Message: "Invalid credentials",
 		})
 	}
 
-	return c.Status(status).JSON(res)
+	return respond(c, status, res)
+}
+
+// Refresh handles exchanging a refresh token
+// @Summary Refresh the access token
+// @Description Exchange a refresh token, from login or the previous refresh, for a new access token and refresh token of the same session, whose expiry moves to the new refresh token's. Each refresh token works once: presenting one again signs its session out, since it must have been copied.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.Envelope{data=dto.LoginResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/refresh [post]
+func (h *UserHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	status, res, err := h.service.Refresh(c.UserContext(), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to refresh token",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// Logout handles signing out the session of a refresh token
+// @Summary Logout
+// @Description Sign out the session of the refresh token: the refresh token is revoked and the session's access tokens are rejected from their next request on. Refresh tokens that no longer work are answered with 204 as well.
+// @Tags users
+// @Accept json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 204
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/logout [post]
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	status, err := h.service.Logout(c.UserContext(), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to log out",
+			Details: err.Error(),
+		})
+	}
+
+	return c.SendStatus(status)
+}
+
+// GetProfile handles reading the signed-in user's profile
+// @Summary Get profile
+// @Description Get the signed-in user's profile, including the version to send with updates
+// @Tags users
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=dto.UserResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me [get]
+func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.GetProfile(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to get profile",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// UpdateProfile handles updating the signed-in user's profile
+// @Summary Update profile
+// @Description Replace the signed-in user's profile. The update fails with 409 when the profile changed since the version sent, re-read it and apply the change again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateProfileRequest true "Profile and the version it is based on"
+// @Success 200 {object} dto.Envelope{data=dto.UserResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me [put]
+func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
+	var req dto.UpdateProfileRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.UpdateProfile(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to update profile",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// ChangePassword handles changing the signed-in user's password
+// @Summary Change password
+// @Description Replace the signed-in user's password. The current password must be sent, and the change shows in the account's activity. Every other session is signed out with its refresh tokens; the current one stays signed in.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} dto.Envelope{data=dto.UserResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 403 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/password [put]
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
+	var req dto.ChangePasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	req.IPAddress, req.UserAgent = c.IP(), c.Get(fiber.HeaderUserAgent)
+	req.SessionID, _ = c.Locals(jwt.SessionIDLocal).(string)
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ChangePassword(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to change password",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
 }
 
 // DeleteUser handles user deletion (soft or hard)
 // @Summary Delete user
-// @Description Delete a user account (soft or hard delete)
+// @Description Delete a user account. A soft delete hides it at once, a hard delete is run by a background job whose ID is returned.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body dto.DeleteUserRequest true "Deletion details"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} dto.Envelope{data=dto.DeleteUserResponse}
+// @Success 202 {object} dto.Envelope{data=dto.DeleteUserResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
 // @Router /api/users/delete [delete]
 func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	var req dto.DeleteUserRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error:   "Invalid request body",
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
 			Details: err.Error(),
 		})
 	}
@@ -126,22 +328,29 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	// Service should handle validation of user ID and delete type
 
 	var status int
+	var jobID string
 	var err error
 
 	if req.Type == "soft" {
-		status, err = h.service.SoftDeleteUser(req.UserID)
+		status, err = h.service.SoftDeleteUser(c.UserContext(), req.UserID)
 	} else {
-		status, err = h.service.HardDeleteUser(req.UserID)
+		status, jobID, err = h.service.HardDeleteUser(c.UserContext(), req.UserID)
 	}
 
 	if err != nil {
-		return c.Status(status).JSON(dto.ErrorResponse{
-			Error:   "Failed to delete user",
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to delete user",
 			Details: err.Error(),
 		})
 	}
 
-	return c.Status(status).JSON(dto.DeleteUserResponse{
+	if jobID != "" {
+		return respond(c, status, dto.DeleteUserResponse{
+			Message: "User deletion scheduled",
+			JobID:   jobID,
+		})
+	}
+	return respond(c, status, dto.DeleteUserResponse{
 		Message: "User deleted successfully",
 	})
 }
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/jobs"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/gofiber/fiber/v2"
+)
+
+// readinessTimeout bounds the database ping of the readiness check. A pool with no free
+// connection fails the check once acquiring one takes longer.
+const readinessTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db    postgres.IHealthInterface
+	queue *jobs.Queue
+}
+
+func NewHealthHandler(db postgres.IHealthInterface, queue *jobs.Queue) *HealthHandler {
+	return &HealthHandler{db: db, queue: queue}
+}
+
+// Ready handles the readiness check
+// @Summary Readiness check
+// @Description Ping the database, reporting the round-trip latency including the wait for a pooled connection
+// @Tags health
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=map[string]interface{}}
+// @Failure 503 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /ready [get]
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	latency, err := h.db.Ping(ctx)
+	if err != nil {
+		return respondError(c, fiber.StatusServiceUnavailable, dto.ErrorResponse{
+			Message: "Database unavailable",
+			Details: err.Error(),
+		})
+	}
+	return respond(c, fiber.StatusOK, fiber.Map{
+		"status":   "ok",
+		"database": fiber.Map{"latency_ms": float64(latency.Microseconds()) / 1000},
+	})
+}
+
+// Metrics handles the metrics endpoint
+// @Summary Service metrics
+// @Description Connection pool counters of the primary database and its replicas, and background job counters
+// @Tags health
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=map[string]interface{}}
+// @Router /metrics [get]
+func (h *HealthHandler) Metrics(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	metrics := fiber.Map{
+		"database": h.db.Stats(),
+	}
+	jobMetrics, err := h.queue.Metrics(ctx)
+	if err != nil {
+		metrics["jobs_error"] = err.Error()
+	}
+	metrics["jobs"] = jobMetrics
+	return respond(c, fiber.StatusOK, metrics)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AuditImpersonation records every request made with an impersonation token in the account
+// activity of the impersonated user, with the operator, the route and the response status
+func AuditImpersonation(events postgres.IAccountEventInterface) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		operator, _ := c.Locals(jwt.ImpersonatorLocal).(string)
+		if operator == "" {
+			return err
+		}
+		id, _ := c.Locals("user_id").(string)
+		userID, parseErr := uuid.Parse(id)
+		if parseErr != nil {
+			return err
+		}
+		tokenID, _ := c.Locals(jwt.TokenIDLocal).(string)
+
+		// The request's own deadline may have passed, the record is written regardless. Strings
+		// of the request are copied, fiber reuses their buffers.
+		event := postgres.AccountEvent{
+			UserID: userID,
+			Kind:   postgres.EventImpersonatedRequest,
+			Detail: map[string]any{
+				"operator": operator,
+				"token_id": tokenID,
+				"method":   strings.Clone(c.Method()),
+				"route":    c.Route().Path,
+				"path":     strings.Clone(c.Path()),
+				"status":   responseStatus(c, err),
+			},
+			IPAddress: strings.Clone(c.IP()),
+			UserAgent: strings.Clone(c.Get(fiber.HeaderUserAgent)),
+		}
+		if recordErr := events.RecordEvent(context.WithoutCancel(c.UserContext()), event); recordErr != nil {
+			log.Printf("Failed to record a request of %s impersonating user %s: %v", operator, userID, recordErr)
+		}
+		return err
+	}
+}
+
+type ImpersonationHandler struct {
+	service   service.IImpersonationService
+	sessions  service.ISessionService
+	validator *validator.Validate
+}
+
+func NewImpersonationHandler(impersonationService service.IImpersonationService, sessionService service.ISessionService, validator *validator.Validate) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		service:   impersonationService,
+		sessions:  sessionService,
+		validator: validator,
+	}
+}
+
+// Impersonate handles handing support staff a token acting as a user
+// @Summary Impersonate a user
+// @Description Issue a token acting as the user, for the support operator to reproduce an issue, valid for ttl_minutes (15 by default, at most 60) unless its session is revoked first. The grant, with the operator and reason, and every request made with the token are recorded in the user's account activity, and the user is emailed. Responses to requests made with the token carry the X-Impersonated-By header. Requires the operator's own bearer token from ADMIN_OPERATOR_TOKENS, which names the operator; the shared ADMIN_TOKEN is refused.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ImpersonateRequest true "Reason"
+// @Success 201 {object} dto.Envelope{data=dto.ImpersonationResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 403 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (h *ImpersonationHandler) Impersonate(c *fiber.Ctx) error {
+	// The operator is named by their own token, not by the request
+	operator, _ := c.Locals(AdminOperatorLocal).(string)
+	if operator == "" {
+		return respondError(c, fiber.StatusForbidden, dto.ErrorResponse{
+			Message: "Failed to impersonate user",
+			Details: "impersonating a user requires an operator token of your own, see ADMIN_OPERATOR_TOKENS",
+		})
+	}
+
+	var req dto.ImpersonateRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	// The grant is recorded with the operator and their client
+	req.Operator, req.IPAddress, req.UserAgent = operator, c.IP(), c.Get(fiber.HeaderUserAgent)
+	status, res, err := h.service.Impersonate(c.UserContext(), c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to impersonate user",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// RevokeSession handles ending a session of a user, e.g. an impersonation before it expires
+// @Summary Revoke a user's session
+// @Description Sign out a session of the user, such as the session_id of an impersonation; its token is rejected from its next request on. Requires an admin bearer token.
+// @Tags admin
+// @Param id path string true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 204
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/admin/users/{id}/sessions/{session_id} [delete]
+func (h *ImpersonationHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Failed to revoke session",
+			Details: "invalid user ID",
+		})
+	}
+
+	status, err := h.sessions.RevokeSession(c.UserContext(), userID.String(), c.Params("session_id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to revoke session",
+			Details: err.Error(),
+		})
+	}
+
+	return c.SendStatus(status)
+}
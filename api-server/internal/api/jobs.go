@@ -0,0 +1,91 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobHandler struct {
+	service   service.IJobService
+	validator *validator.Validate
+}
+
+func NewJobHandler(jobService service.IJobService, validator *validator.Validate) *JobHandler {
+	return &JobHandler{
+		service:   jobService,
+		validator: validator,
+	}
+}
+
+// ListJobs handles listing the user's background jobs
+// @Summary List background jobs
+// @Description List the user's background jobs, such as account purges, newest first
+// @Tags jobs
+// @Produce json
+// @Param limit query int false "Maximum jobs, 1 to 100 (default 20)"
+// @Param If-None-Match header string false "ETag of the page held, answered with 304 while unchanged"
+// @Success 200 {object} dto.Envelope{data=[]dto.JobResponse}
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) ListJobs(c *fiber.Ctx) error {
+	var req dto.ListJobsRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ListJobs(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list jobs",
+			Details: err.Error(),
+		})
+	}
+
+	return respondPage(c, status, res.Items, res.Pagination)
+}
+
+// GetJob handles reading the state of a background job
+// @Summary Get a background job
+// @Description Get the state of one of the user's background jobs, with its result once it succeeded
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} dto.Envelope{data=dto.JobResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.GetJob(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to get job",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
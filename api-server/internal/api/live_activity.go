@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/live"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LiveActivityHandler struct {
+	hub *live.Hub
+}
+
+func NewLiveActivityHandler(hub *live.Hub) *LiveActivityHandler {
+	return &LiveActivityHandler{hub: hub}
+}
+
+// SetupLiveRoutes registers the WebSocket endpoint pushing the live activity of hub. It is outside
+// /api/v1 and its request timeouts, since connections stay open.
+func SetupLiveRoutes(app *fiber.App, repos postgres.Repositories, hub *live.Hub) {
+	liveActivityHandler := NewLiveActivityHandler(hub)
+
+	// Browsers cannot set headers on WebSocket handshakes, so they pass the token in ?access_token=
+	app.Get("/ws/activity", APIKeyAuth(service.NewAPIKeyService(repos.APIKeys)), tokenFromQuery,
+		jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAddresses), liveActivityHandler.Activity,
+		websocket.New(liveActivityHandler.Serve))
+}
+
+// tokenFromQuery moves the access_token query parameter into the Authorization header of requests
+// without one, for jwt.JWTMiddleware
+func tokenFromQuery(c *fiber.Ctx) error {
+	if token := c.Query("access_token"); token != "" && c.Get(fiber.HeaderAuthorization) == "" {
+		c.Request().Header.Set(fiber.HeaderAuthorization, token)
+	}
+	return c.Next()
+}
+
+// Activity handles the live activity connections
+// @Summary Stream the live activity of the user's addresses
+// @Description Upgrade to a WebSocket receiving a JSON event, of type transfer, for each transfer of the user's watched addresses as the engine matches it: pending, then confirmed, failed or dropped. Browsers pass the token in access_token and connect from the origins in LIVE_ACTIVITY_ALLOWED_ORIGINS only. The server pings every 30s; slow connections are closed with 1013. When the token's session is signed out the connection receives a session.revoked event with its session_id and is closed with 1008. A user has at most 5 connections.
+// @Tags addresses
+// @Param access_token query string false "JWT, for clients that cannot set the Authorization header"
+// @Success 101 {string} string "Switching Protocols, then one JSON event per message"
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 403 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 426 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 429 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /ws/activity [get]
+func (h *LiveActivityHandler) Activity(c *fiber.Ctx) error {
+	if !live.IsWebSocket(c) {
+		c.Set(fiber.HeaderUpgrade, "websocket")
+		return respondError(c, fiber.StatusUpgradeRequired, dto.ErrorResponse{
+			Message: "WebSocket handshake required",
+			Details: "Connect with a WebSocket client",
+		})
+	}
+
+	if !live.OriginAllowed(c, config.GetConfig().LiveActivityOrigins) {
+		return respondError(c, fiber.StatusForbidden, dto.ErrorResponse{
+			Message: "Origin not allowed",
+			Details: "Connect from an origin listed in LIVE_ACTIVITY_ALLOWED_ORIGINS",
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	if h.hub.Connections(userID) >= live.MaxConnections {
+		return respondError(c, fiber.StatusTooManyRequests, dto.ErrorResponse{
+			Message: "Too many live activity connections",
+			Details: fmt.Sprintf("Close one of your %d open connections first", live.MaxConnections),
+		})
+	}
+	return c.Next()
+}
+
+// Serve pushes the live activity to an upgraded connection of the user Activity authenticated,
+// until the session of its token is revoked
+func (h *LiveActivityHandler) Serve(conn *websocket.Conn) {
+	userID, _ := conn.Locals("user_id").(string)
+	sessionID, _ := conn.Locals(jwt.SessionIDLocal).(string)
+	h.hub.Serve(conn, userID, sessionID)
+}
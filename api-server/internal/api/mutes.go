@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MuteHandler struct {
+	service   service.IMuteService
+	validator *validator.Validate
+}
+
+func NewMuteHandler(muteService service.IMuteService, validator *validator.Validate) *MuteHandler {
+	return &MuteHandler{
+		service:   muteService,
+		validator: validator,
+	}
+}
+
+// MuteAddress handles muting an address
+// @Summary Mute an address
+// @Description Silence the notifications of the address's alerts until the given time, or until it is unmuted. Alerts are still recorded.
+// @Tags mutes
+// @Produce json
+// @Param id path string true "Address ID"
+// @Param until query string false "RFC 3339 time the mute ends, muted until unmuted when left out"
+// @Success 200 {object} dto.Envelope{data=dto.MuteResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/mute [post]
+func (h *MuteHandler) MuteAddress(c *fiber.Ctx) error {
+	return h.mute(c, "Failed to mute address", h.service.MuteAddress)
+}
+
+// UnmuteAddress handles unmuting an address
+// @Summary Unmute an address
+// @Description Resume the notifications of the address's alerts
+// @Tags mutes
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} dto.Envelope{data=dto.MuteResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/mute [delete]
+func (h *MuteHandler) UnmuteAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.UnmuteAddress(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to unmute address",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// MuteRule handles muting an alert rule
+// @Summary Mute an alert rule
+// @Description Silence the notifications of the rule's alerts until the given time, or until it is unmuted. Alerts are still recorded.
+// @Tags mutes
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param until query string false "RFC 3339 time the mute ends, muted until unmuted when left out"
+// @Success 200 {object} dto.Envelope{data=dto.MuteResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/mute [post]
+func (h *MuteHandler) MuteRule(c *fiber.Ctx) error {
+	return h.mute(c, "Failed to mute rule", h.service.MuteRule)
+}
+
+// UnmuteRule handles unmuting an alert rule
+// @Summary Unmute an alert rule
+// @Description Resume the notifications of the rule's alerts
+// @Tags mutes
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.Envelope{data=dto.MuteResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/mute [delete]
+func (h *MuteHandler) UnmuteRule(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.UnmuteRule(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to unmute rule",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// mute parses and validates the until query parameter and mutes the record of the id path
+// parameter with fn
+func (h *MuteHandler) mute(c *fiber.Ctx, failure string,
+	fn func(ctx context.Context, userID, id string, req dto.MuteRequest) (int, *dto.MuteResponse, error)) error {
+	var req dto.MuteRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := fn(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: failure,
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
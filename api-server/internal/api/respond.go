@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// respond writes data with status in the response envelope
+func respond(c *fiber.Ctx, status int, data any) error {
+	return c.Status(status).JSON(dto.Envelope{Data: data, Meta: meta(c)})
+}
+
+// respondPage writes a page of a list with status in the response envelope. Polling clients
+// sending the ETag of the page they hold in If-None-Match get 304 while the page is unchanged.
+func respondPage(c *fiber.Ctx, status int, data any, page dto.Pagination) error {
+	// The ETag covers the page, not the request ID in meta
+	payload, err := json.Marshal(struct {
+		Data       any            `json:"data"`
+		Pagination dto.Pagination `json:"pagination"`
+	}{data, page})
+	if err != nil {
+		return err
+	}
+	if notModified(c, payload) {
+		return nil
+	}
+
+	m := meta(c)
+	m.Pagination = &page
+	return c.Status(status).JSON(dto.Envelope{Data: data, Meta: m})
+}
+
+// respondError writes the error with status in the response envelope, setting its code from status
+func respondError(c *fiber.Ctx, status int, res dto.ErrorResponse) error {
+	res.Code = errorCode(status)
+	return c.Status(status).JSON(dto.Envelope{Error: &res, Meta: meta(c)})
+}
+
+func meta(c *fiber.Ctx) dto.Meta {
+	requestID, _ := c.Locals(TraceIDLocal).(string)
+	return dto.Meta{RequestID: requestID}
+}
+
+// errorCode turns the reason phrase of status into a code, e.g. 404 into "not_found"
+func errorCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
+
+// ErrorHandler writes the errors returned by handlers and middleware, e.g. fiber.ErrUnauthorized
+// or the 404 of an unknown route, in the response envelope. Errors other than *fiber.Error are
+// logged and answered with 500 without their message.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return respondError(c, fiberErr.Code, dto.ErrorResponse{Message: fiberErr.Message})
+	}
+
+	log.Printf("Unhandled error on %s %s: %v", c.Method(), c.Path(), err)
+	return respondError(c, fiber.StatusInternalServerError, dto.ErrorResponse{
+		Message: utils.StatusMessage(fiber.StatusInternalServerError),
+	})
+}
@@ -1,36 +1,230 @@
 package api
 
 import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/email"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/jobs"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/rpc"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/webhooks"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
 	"github.com/gofiber/fiber/v2"
 )
 
-// SetupRoutes configures all API routes
-func SetupRoutes(app *fiber.App, db *postgres.Database) {
-	// Initialize repository
-	userRepo := postgres.NewUserRepository(db.Pool)
+// SetupRoutes configures all API routes, and registers the handlers of the jobs the services
+// enqueue on queue. Chain data is read from node, a node of CHAIN_NETWORK.
+func SetupRoutes(app *fiber.App, repos postgres.Repositories, tx postgres.ITxManager, db postgres.IHealthInterface, queue *jobs.Queue,
+	node *rpc.Client) {
+	// Initialize service, multi-step operations run in transactions on the same database
+	userService := service.NewService(repos.Users, repos.AccountEvents, repos.Sessions, tx, queue)
+	addressService := service.NewAddressService(repos.Addresses)
+	searchService := service.NewSearchService(repos.Addresses, repos.KnownEntities)
+	jobService := service.NewJobService(repos.Jobs)
+	apiKeyService := service.NewAPIKeyService(repos.APIKeys)
+	sessionService := service.NewSessionService(repos.Sessions)
+	analyticsService := service.NewAnalyticsService(repos.APIUsage)
+	muteService := service.NewMuteService(repos.Addresses, repos.AlertRules)
+	tokenPresetService := service.NewTokenPresetService(repos.AlertRules)
+	ruleExpressionService := service.NewRuleExpressionService(repos.AlertRules)
+	rulePriorityService := service.NewRulePriorityService(repos.AlertRules)
+	ruleTemplateService := service.NewRuleTemplateService(repos.Addresses, repos.AlertRules)
+	ruleService := service.NewRuleService(repos.Addresses, repos.AlertRules, tx)
+	activityService := service.NewActivityService(repos.Addresses, repos.Transactions, repos.Alerts, repos.AddressEvents,
+		repos.AccountEvents)
+	channelService := service.NewChannelService(repos.Users, repos.Webhooks, email.NewSMTPSender(),
+		webhooks.New(repos.NotificationDeliveries, tx))
 
-	// Initialize service
-	userService := service.NewService(userRepo)
+	chain := config.GetConfig().Chain
+	feeService := service.NewFeeService(chain.Network, node, chain.FeeCacheTTL)
+	transactionService := service.NewTransactionService(chain.Network, node, chain.Confirmations)
+	dashboardService := service.NewDashboardService(repos.Dashboard, chain.Network, node)
+	balanceWatchService := service.NewBalanceWatchService(repos.Addresses, repos.BalanceWatches, chain.Network)
+	watcherStatusService := service.NewWatcherStatusService(repos.Addresses, repos.WatcherStatus)
+
+	// Background jobs
+	queue.Register(service.JobPurgeUser, userService.PurgeUser)
+
+	// Tokens of revoked sessions are rejected from their next request on
+	jwt.CheckSessions(sessionService.Check)
 
 	// Initialize validator with custom validators
 	validator := validators.NewValidator()
 
 	// Initialize handler
 	userHandler := NewUserHandler(userService, validator)
+	addressHandler := NewAddressHandler(addressService, validator)
+	searchHandler := NewSearchHandler(searchService, validator)
+	jobHandler := NewJobHandler(jobService, validator)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, validator)
+	sessionHandler := NewSessionHandler(sessionService)
+	analyticsHandler := NewAnalyticsHandler(analyticsService, validator)
+	muteHandler := NewMuteHandler(muteService, validator)
+	tokenPresetHandler := NewTokenPresetHandler(tokenPresetService, validator)
+	ruleExpressionHandler := NewRuleExpressionHandler(ruleExpressionService, validator)
+	rulePriorityHandler := NewRulePriorityHandler(rulePriorityService, validator)
+	ruleTemplateHandler := NewRuleTemplateHandler(ruleTemplateService, validator)
+	ruleHandler := NewRuleHandler(ruleService, validator)
+	activityHandler := NewActivityHandler(activityService, validator)
+	channelHandler := NewChannelHandler(channelService, validator)
+	feeHandler := NewFeeHandler(feeService)
+	transactionHandler := NewTransactionHandler(transactionService)
+	dashboardHandler := NewDashboardHandler(dashboardService)
+	balanceWatchHandler := NewBalanceWatchHandler(balanceWatchService, validator)
+	watcherStatusHandler := NewWatcherStatusHandler(watcherStatusService)
+	healthHandler := NewHealthHandler(db, queue)
 
-	// API v1 routes
-	api := app.Group("/api/v1")
+	// API v1 routes, requests presenting an API key are authenticated as its user for the
+	// routes behind jwt.JWTMiddleware, those of the key's scopes only. Chain data is public and
+	// reached with any key.
+	api := app.Group("/api/v1", APIKeyAuth(apiKeyService))
 
-	// User routes
+	// User routes, answered with 504 past SERVER_AUTH_TIMEOUT or SERVER_REQUEST_TIMEOUT
 	users := api.Group("/users")
 	{
 		// Public routes
-		users.Post("/register", userHandler.Register)
-		users.Post("/login", userHandler.Login)
-		users.Delete("/delete", userHandler.DeleteUser)
+		users.Post("/register", Timeout(authBudget), userHandler.Register)
+		users.Post("/login", Timeout(authBudget), userHandler.Login)
+		// Refresh tokens stand in for the access token, which may have expired
+		users.Post("/refresh", Timeout(authBudget), userHandler.Refresh)
+		users.Post("/logout", Timeout(requestBudget), userHandler.Logout)
+		// Opened from the link of the confirmation email
+		users.Post("/verify-email", Timeout(requestBudget), channelHandler.VerifyEmail)
+
+		// Deletes the signed-in user, other users' IDs are answered with 404. Support staff
+		// impersonating the user can neither delete them nor change their password, nor can
+		// API keys.
+		users.Delete("/delete", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.DenyImpersonation(), jwt.DenyAPIKey(),
+			userHandler.DeleteUser)
+
+		// Profile of the signed-in user, updates name the version they are based on
+		users.Get("/me", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAccount),
+			userHandler.GetProfile)
+		users.Put("/me", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeWriteAccount),
+			userHandler.UpdateProfile)
+		users.Put("/me/password", Timeout(authBudget), jwt.JWTMiddleware(), jwt.DenyImpersonation(), jwt.DenyAPIKey(),
+			userHandler.ChangePassword)
+
+		// The signed-in user's API keys, presented in the X-API-Key header and restricted to
+		// their scopes and allowed networks. They are managed after logging in only. Support
+		// staff impersonating the user see them but cannot change them.
+		apiKeys := users.Group("/me/api-keys", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.DenyAPIKey())
+		{
+			apiKeys.Get("/", apiKeyHandler.ListKeys)
+			apiKeys.Post("/", jwt.DenyImpersonation(), apiKeyHandler.CreateKey)
+			apiKeys.Delete("/:id", jwt.DenyImpersonation(), apiKeyHandler.RevokeKey)
+			apiKeys.Put("/:id/allowed-cidrs", jwt.DenyImpersonation(), apiKeyHandler.SetAllowedCIDRs)
+			apiKeys.Put("/:id/scopes", jwt.DenyImpersonation(), apiKeyHandler.SetScopes)
+		}
+
+		// The signed-in user's sessions, one per login, which can be signed out from another
+		// device. Support staff impersonating the user see them but cannot sign them out.
+		sessions := users.Group("/me/sessions", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.DenyAPIKey())
+		{
+			sessions.Get("/", sessionHandler.ListSessions)
+			sessions.Delete("/", jwt.DenyImpersonation(), sessionHandler.RevokeOtherSessions)
+			sessions.Delete("/:id", jwt.DenyImpersonation(), sessionHandler.RevokeSession)
+		}
+
+		// Summary of the signed-in user's addresses, alerts and balances for home screens
+		users.Get("/me/dashboard", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAddresses),
+			dashboardHandler.Dashboard)
+
+		// Verification of the email and webhooks alerts are delivered to
+		users.Get("/me/channels", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAccount),
+			channelHandler.ListChannels)
+		users.Post("/me/email/verification", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeWriteAccount),
+			channelHandler.SendEmailVerification)
+
+		// The signed-in user's sign-ins and account changes
+		users.Get("/me/activity", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAccount),
+			activityHandler.AccountActivity)
+
+		// The signed-in user's API usage per endpoint and day
+		users.Get("/me/analytics", Timeout(reportingBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAccount),
+			analyticsHandler.Usage)
+	}
+
+	// Full-text search over the user's addresses and known entities
+	api.Get("/search", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAddresses),
+		searchHandler.Search)
+
+	// State of the user's background jobs, e.g. the purge started by a hard delete
+	jobRoutes := api.Group("/jobs", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadAccount))
+	{
+		jobRoutes.Get("/", jobHandler.ListJobs)
+		jobRoutes.Get("/:id", jobHandler.GetJob)
+	}
+
+	// The user's watched addresses, each on a chain with an optional label and notes. Muting
+	// silences the notifications of an address's or rule's alerts, until ?until= or until
+	// unmuted. The activity feed merges an address's transactions, alerts and changes, while the
+	// transactions list the transfers alone. Balance watches poll the address's ERC-20 balances
+	// for changes without Transfer events. The status tells whether the engine is watching the
+	// address yet and how far it has scanned.
+	addresses := api.Group("/addresses", Timeout(requestBudget), jwt.JWTMiddleware(),
+		jwt.ReadWriteScope(jwt.ScopeReadAddresses, jwt.ScopeWriteAddresses))
+	{
+		addresses.Get("/", addressHandler.ListAddresses)
+		addresses.Post("/", addressHandler.CreateAddress)
+		addresses.Get("/:id", addressHandler.GetAddress)
+		addresses.Put("/:id", addressHandler.UpdateAddress)
+		addresses.Delete("/:id", addressHandler.DeleteAddress)
+		addresses.Get("/:id/activity", activityHandler.AddressActivity)
+		addresses.Get("/:id/transactions", activityHandler.AddressTransactions)
+		addresses.Get("/:id/status", watcherStatusHandler.AddressStatus)
+		addresses.Post("/:id/mute", muteHandler.MuteAddress)
+		addresses.Delete("/:id/mute", muteHandler.UnmuteAddress)
+		addresses.Get("/:id/balance-watches", balanceWatchHandler.ListWatches)
+		addresses.Post("/:id/balance-watches", balanceWatchHandler.CreateWatch)
+	}
+	// A simple rule's conditions are its direction, minimum value, token and counterparties; the
+	// engine notifies the transfers matching them. A token preset filters a rule's transfers to
+	// built-in token contracts, e.g. the stablecoins. An expression makes the rule an advanced
+	// rule, its condition written in CEL. A matching rule with stop_processing suppresses the lower
+	// priority rules of the transaction. Templates are ready-made advanced rules, created with one
+	// call.
+	rules := api.Group("/rules", Timeout(requestBudget), jwt.JWTMiddleware(),
+		jwt.ReadWriteScope(jwt.ScopeReadRules, jwt.ScopeWriteRules))
+	{
+		rules.Get("/templates", ruleTemplateHandler.ListTemplates)
+		rules.Post("/templates/:name", ruleTemplateHandler.CreateRule)
+		rules.Get("/", ruleHandler.ListRules)
+		rules.Post("/", ruleHandler.CreateRule)
+		rules.Get("/:id", ruleHandler.GetRule)
+		rules.Put("/:id", ruleHandler.UpdateRule)
+		rules.Delete("/:id", ruleHandler.DeleteRule)
+		rules.Post("/:id/mute", muteHandler.MuteRule)
+		rules.Delete("/:id/mute", muteHandler.UnmuteRule)
+		rules.Put("/:id/token-preset", tokenPresetHandler.SetRulePreset)
+		rules.Delete("/:id/token-preset", tokenPresetHandler.ClearRulePreset)
+		rules.Put("/:id/expression", ruleExpressionHandler.SetExpression)
+		rules.Delete("/:id/expression", ruleExpressionHandler.ClearExpression)
+		rules.Put("/:id/priority", rulePriorityHandler.SetPriority)
+	}
+	api.Get("/token-presets", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeReadRules),
+		tokenPresetHandler.ListPresets)
+
+	// Webhooks answer a challenge before alerts are posted to them
+	webhookRoutes := api.Group("/webhooks", Timeout(requestBudget), jwt.JWTMiddleware(), jwt.RequireScope(jwt.ScopeWriteAccount))
+	{
+		webhookRoutes.Post("/:id/verify", channelHandler.VerifyWebhook)
+	}
+
+	// ERC-20 balances polled with balanceOf, see package balances
+	balanceWatches := api.Group("/balance-watches", Timeout(requestBudget), jwt.JWTMiddleware(),
+		jwt.ReadWriteScope(jwt.ScopeReadAddresses, jwt.ScopeWriteAddresses))
+	{
+		balanceWatches.Put("/:id", balanceWatchHandler.UpdateWatch)
+		balanceWatches.Delete("/:id", balanceWatchHandler.DeleteWatch)
+	}
+
+	// Chain data read from the node, so clients need no node of their own
+	chains := api.Group("/chains", Timeout(requestBudget), jwt.JWTMiddleware())
+	{
+		chains.Get("/:chain/fees", feeHandler.Fees)
+		chains.Get("/:chain/tx/:hash", transactionHandler.Status)
 	}
 
 	// subscription := api.Group("/subscriptions", jwt.JWTMiddleware())
@@ -41,15 +235,21 @@ func SetupRoutes(app *fiber.App, db *postgres.Database) {
 
 	// Health check endpoint
 	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
+		return respond(c, fiber.StatusOK, fiber.Map{
 			"status":  "ok",
 			"service": "blockchain-address-watcher-api",
 		})
 	})
 
+	// Readiness check, failing while the database is unreachable or the pool is exhausted
+	app.Get("/ready", healthHandler.Ready)
+
+	// Connection pool metrics
+	app.Get("/metrics", healthHandler.Metrics)
+
 	// Root endpoint
 	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
+		return respond(c, fiber.StatusOK, fiber.Map{
 			"message": "Blockchain Address Watcher API",
 			"version": "1.0.0",
 		})
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RuleExpressionHandler struct {
+	service   service.IRuleExpressionService
+	validator *validator.Validate
+}
+
+func NewRuleExpressionHandler(ruleExpressionService service.IRuleExpressionService, validator *validator.Validate) *RuleExpressionHandler {
+	return &RuleExpressionHandler{
+		service:   ruleExpressionService,
+		validator: validator,
+	}
+}
+
+// SetExpression handles making a rule an advanced rule
+// @Summary Set a rule's CEL expression
+// @Description Make the rule's condition a CEL expression over tx, the normalized transaction (chain, hash, block_number, log_index, from, to, value, value_usd, token, kind, direction, counterparty, new_counterparty, status, counterparty_risk, counterparty_risk_level), and known, the addresses of known entities (exchanges, mixers, sanctioned, bridges). The expression must evaluate to a bool and replaces the rule's direction, min_value and token filters. Mistakes are answered with 400 and their position.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body dto.SetRuleExpressionRequest true "CEL expression"
+// @Success 200 {object} dto.Envelope{data=dto.RuleExpressionResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/expression [put]
+func (h *RuleExpressionHandler) SetExpression(c *fiber.Ctx) error {
+	var req dto.SetRuleExpressionRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.SetExpression(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to set rule expression",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// ClearExpression handles making a rule a simple rule again
+// @Summary Remove a rule's CEL expression
+// @Description Evaluate the rule by its direction, min_value and token filters again
+// @Tags rules
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.Envelope{data=dto.RuleExpressionResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/expression [delete]
+func (h *RuleExpressionHandler) ClearExpression(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ClearExpression(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to remove rule expression",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RulePriorityHandler struct {
+	service   service.IRulePriorityService
+	validator *validator.Validate
+}
+
+func NewRulePriorityHandler(rulePriorityService service.IRulePriorityService, validator *validator.Validate) *RulePriorityHandler {
+	return &RulePriorityHandler{
+		service:   rulePriorityService,
+		validator: validator,
+	}
+}
+
+// SetPriority handles ordering a rule among the rules of a transaction
+// @Summary Set a rule's priority
+// @Description Set the order the rule is evaluated in among the rules applying to a transaction, from -1000 to 1000, higher first, rules of equal priority by creation. When stop_processing is set and the rule matches, the rules after it are not evaluated, e.g. a sanctions rule suppressing the generic large transfer rules.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body dto.SetRulePriorityRequest true "Priority"
+// @Success 200 {object} dto.Envelope{data=dto.RulePriorityResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/priority [put]
+func (h *RulePriorityHandler) SetPriority(c *fiber.Ctx) error {
+	var req dto.SetRulePriorityRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.SetPriority(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to set rule priority",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
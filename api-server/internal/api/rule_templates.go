@@ -0,0 +1,90 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RuleTemplateHandler struct {
+	service   service.IRuleTemplateService
+	validator *validator.Validate
+}
+
+func NewRuleTemplateHandler(ruleTemplateService service.IRuleTemplateService, validator *validator.Validate) *RuleTemplateHandler {
+	return &RuleTemplateHandler{
+		service:   ruleTemplateService,
+		validator: validator,
+	}
+}
+
+// ListTemplates handles listing the rule templates
+// @Summary List rule templates
+// @Description Ready-made advanced rules, such as large outgoing transfers, new counterparties, approvals granted and exchange deposits, each created with one call
+// @Tags rules
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=[]dto.RuleTemplateResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/templates [get]
+func (h *RuleTemplateHandler) ListTemplates(c *fiber.Ctx) error {
+	status, res, err := h.service.ListTemplates(c.UserContext())
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list rule templates",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// CreateRule handles creating a rule from a template
+// @Summary Create a rule from a template
+// @Description Create an enabled advanced rule with the template's expression, its parameters filled in from params or their defaults, for address_id or every address of the user
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param request body dto.CreateRuleFromTemplateRequest false "Rule settings"
+// @Success 201 {object} dto.Envelope{data=dto.TemplateRuleResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/templates/{name} [post]
+func (h *RuleTemplateHandler) CreateRule(c *fiber.Ctx) error {
+	var req dto.CreateRuleFromTemplateRequest
+
+	// The body is optional, every setting has a default
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+				Message: "Invalid request body",
+				Details: err.Error(),
+			})
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.CreateRule(c.UserContext(), userID, c.Params("name"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to create rule from template",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RuleHandler struct {
+	service   service.IRuleService
+	validator *validator.Validate
+}
+
+func NewRuleHandler(ruleService service.IRuleService, validator *validator.Validate) *RuleHandler {
+	return &RuleHandler{
+		service:   ruleService,
+		validator: validator,
+	}
+}
+
+// ListRules handles listing the user's alert rules
+// @Summary List alert rules
+// @Description List the user's alert rules, simple and advanced, oldest first
+// @Tags rules
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=[]dto.RuleResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules [get]
+func (h *RuleHandler) ListRules(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ListRules(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list rules",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// GetRule handles reading an alert rule
+// @Summary Get an alert rule
+// @Description Get one of the user's alert rules with its conditions and version
+// @Tags rules
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.Envelope{data=dto.RuleResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id} [get]
+func (h *RuleHandler) GetRule(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.GetRule(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to get rule",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// CreateRule handles creating a simple alert rule
+// @Summary Create an alert rule
+// @Description Create a simple rule for one of the user's addresses, or all of them when address_id is left out. A transfer matches when every condition set holds: direction (in, out or any), min_value in the token's smallest unit, token_address (left out for the chain's native currency) and counterparties, the addresses on the other side. Once a user has rules applying to an address, only the transfers matching one of them are notified, with the rule's severity. Addresses are checked against the chain of the rule's address.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRuleRequest true "Address, name and conditions"
+// @Success 201 {object} dto.Envelope{data=dto.RuleResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules [post]
+func (h *RuleHandler) CreateRule(c *fiber.Ctx) error {
+	var req dto.CreateRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.CreateRule(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to create rule",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// UpdateRule handles changing the conditions of an alert rule
+// @Summary Update an alert rule
+// @Description Replace the name, conditions, counterparties, cooldown, severity and state of a rule, based on the version last read. The update fails with 409 when the rule was changed since; read it again and retry.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body dto.UpdateRuleRequest true "Name, conditions and version"
+// @Success 200 {object} dto.Envelope{data=dto.RuleResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 409 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id} [put]
+func (h *RuleHandler) UpdateRule(c *fiber.Ctx) error {
+	var req dto.UpdateRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.UpdateRule(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to update rule",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// DeleteRule handles deleting an alert rule
+// @Summary Delete an alert rule
+// @Description Delete one of the user's rules, returning it as it was. Its past alerts are kept.
+// @Tags rules
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.Envelope{data=dto.RuleResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id} [delete]
+func (h *RuleHandler) DeleteRule(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.DeleteRule(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to delete rule",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
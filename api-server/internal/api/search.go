@@ -0,0 +1,64 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchHandler struct {
+	service   service.ISearchService
+	validator *validator.Validate
+}
+
+func NewSearchHandler(searchService service.ISearchService, validator *validator.Validate) *SearchHandler {
+	return &SearchHandler{
+		service:   searchService,
+		validator: validator,
+	}
+}
+
+// Search handles full-text search
+// @Summary Search addresses and known entities
+// @Description Search the labels and notes of the user's addresses and the names of known entities, best match first
+// @Tags search
+// @Produce json
+// @Param q query string true "Search words, matched as word prefixes"
+// @Param limit query int false "Maximum results per kind, 1 to 100 (default 20)"
+// @Success 200 {object} dto.Envelope{data=dto.SearchResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/search [get]
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	var req dto.SearchRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.Search(c.UserContext(), userID, req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to search",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
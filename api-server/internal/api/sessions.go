@@ -0,0 +1,91 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	service service.ISessionService
+}
+
+func NewSessionHandler(sessionService service.ISessionService) *SessionHandler {
+	return &SessionHandler{
+		service: sessionService,
+	}
+}
+
+// ListSessions handles listing the user's sessions
+// @Summary List sessions
+// @Description List the user's active sessions, one per sign-in, with the address and user agent of the client and when it was last seen. The session of the request's token is marked current.
+// @Tags users
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=[]dto.SessionResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/sessions [get]
+func (h *SessionHandler) ListSessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals(jwt.SessionIDLocal).(string)
+	status, res, err := h.service.ListSessions(c.UserContext(), userID, sessionID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list sessions",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// RevokeSession handles signing out a session
+// @Summary Revoke a session
+// @Description Sign out the session, e.g. of a lost device; its token is rejected from its next request on. The revocation shows in the account activity.
+// @Tags users
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, err := h.service.RevokeSession(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to revoke session",
+			Details: err.Error(),
+		})
+	}
+
+	return c.SendStatus(status)
+}
+
+// RevokeOtherSessions handles signing out every other session
+// @Summary Revoke all other sessions
+// @Description Sign out every session of the user but the current one; their tokens are rejected from their next request on. The revocations show in the account activity.
+// @Tags users
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=dto.RevokedSessionsResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/users/me/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals(jwt.SessionIDLocal).(string)
+	status, res, err := h.service.RevokeOtherSessions(c.UserContext(), userID, sessionID)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to revoke sessions",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Budgets of the routes, read on every request so a reloaded SERVER_*_TIMEOUT takes effect at once
+var (
+	requestBudget   = func() time.Duration { return config.GetConfig().RequestTimeout.Default }
+	authBudget      = func() time.Duration { return config.GetConfig().RequestTimeout.Auth }
+	reportingBudget = func() time.Duration { return config.GetConfig().RequestTimeout.Reporting }
+)
+
+// Timeout binds a deadline of budget() to the request's user context, and answers requests whose
+// deadline passed while they were handled with 504 in place of the handler's response. Handlers
+// are not interrupted: the deadline ends the queries and calls they make with the user context.
+func Timeout(budget func() time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parent := c.UserContext()
+		ctx, cancel := context.WithTimeout(parent, budget())
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		err := c.Next()
+		c.SetUserContext(parent)
+
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return err
+		}
+		c.Response().ResetBody()
+		c.Response().Header.Del(fiber.HeaderETag)
+		c.Response().Header.Del(fiber.HeaderCacheControl)
+		return respondError(c, fiber.StatusGatewayTimeout, dto.ErrorResponse{
+			Message: "Request timed out",
+			Details: "the request took longer than " + budget().String(),
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TokenPresetHandler struct {
+	service   service.ITokenPresetService
+	validator *validator.Validate
+}
+
+func NewTokenPresetHandler(tokenPresetService service.ITokenPresetService, validator *validator.Validate) *TokenPresetHandler {
+	return &TokenPresetHandler{
+		service:   tokenPresetService,
+		validator: validator,
+	}
+}
+
+// ListPresets handles listing the token presets
+// @Summary List token presets
+// @Description Built-in lists of token contracts per chain, such as the major stablecoins, that a rule can filter its transfers to
+// @Tags token presets
+// @Produce json
+// @Success 200 {object} dto.Envelope{data=[]dto.TokenPresetResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/token-presets [get]
+func (h *TokenPresetHandler) ListPresets(c *fiber.Ctx) error {
+	status, res, err := h.service.ListPresets(c.UserContext())
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to list token presets",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// SetRulePreset handles filtering a rule's transfers to a token preset
+// @Summary Filter a rule to a token preset
+// @Description Only alert on transfers of the preset's token contracts on the chain of the rule's address, e.g. stablecoin movements only. Rules filtering on a token_address cannot take a preset.
+// @Tags token presets
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body dto.SetTokenPresetRequest true "Token preset"
+// @Success 200 {object} dto.Envelope{data=dto.RuleTokenPresetResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 422 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/token-preset [put]
+func (h *TokenPresetHandler) SetRulePreset(c *fiber.Ctx) error {
+	var req dto.SetTokenPresetRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, dto.ErrorResponse{
+			Message: "Validation failed",
+			Details: "Please check the fields and try again",
+			Fields:  validators.GetValidationErrors(err, c.Get(fiber.HeaderAcceptLanguage)),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.SetRulePreset(c.UserContext(), userID, c.Params("id"), req)
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to set token preset",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
+
+// ClearRulePreset handles removing the token preset of a rule
+// @Summary Remove a rule's token preset
+// @Description Alert on transfers of any token again
+// @Tags token presets
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} dto.Envelope{data=dto.RuleTokenPresetResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/rules/{id}/token-preset [delete]
+func (h *TokenPresetHandler) ClearRulePreset(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.ClearRulePreset(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to remove token preset",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tracecontext"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TraceIDLocal is the fiber local holding the request's trace ID, e.g. for ${locals:trace_id} in
+// the logger format
+const TraceIDLocal = "trace_id"
+
+// Tracing continues the W3C trace of the incoming traceparent header, or starts one, with a span
+// for the request. The span context is stored in the request's user context for code that writes
+// on its behalf and returned in the traceparent response header.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parent, _ := tracecontext.Parse(c.Get(tracecontext.TraceparentHeader), c.Get(tracecontext.TracestateHeader))
+		sc := parent.Child()
+
+		c.SetUserContext(tracecontext.NewContext(c.UserContext(), sc))
+		c.Locals(TraceIDLocal, sc.TraceIDString())
+		c.Set(tracecontext.TraceparentHeader, sc.String())
+
+		return c.Next()
+	}
+}
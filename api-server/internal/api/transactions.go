@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransactionHandler struct {
+	service service.ITransactionService
+}
+
+func NewTransactionHandler(transactionService service.ITransactionService) *TransactionHandler {
+	return &TransactionHandler{
+		service: transactionService,
+	}
+}
+
+// Status handles transaction lookups
+// @Summary Look a transaction up
+// @Description Status, confirmations and transfers of any transaction of the chain, read from the watcher's node
+// @Tags chains
+// @Produce json
+// @Param chain path string true "Chain, e.g. ethereum or bitcoin"
+// @Param hash path string true "Transaction hash"
+// @Success 200 {object} dto.Envelope{data=dto.TransactionStatusResponse}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 502 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/chains/{chain}/tx/{hash} [get]
+func (h *TransactionHandler) Status(c *fiber.Ctx) error {
+	status, res, err := h.service.Status(c.UserContext(), c.Params("chain"), c.Params("hash"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to look the transaction up",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
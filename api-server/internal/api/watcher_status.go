@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WatcherStatusHandler struct {
+	service service.IWatcherStatusService
+}
+
+func NewWatcherStatusHandler(watcherStatusService service.IWatcherStatusService) *WatcherStatusHandler {
+	return &WatcherStatusHandler{
+		service: watcherStatusService,
+	}
+}
+
+// AddressStatus handles reading whether an address is being watched
+// @Summary Get an address's watcher status
+// @Description Get the state of the engine's watch of the address: pending until the engine picks it up, the chain's scan mode, the last block scanned and transfer seen, and the last error while scans fail
+// @Tags addresses
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} dto.Envelope{data=dto.AddressStatus}
+// @Failure 400 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 401 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 404 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 500 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Failure 504 {object} dto.Envelope{error=dto.ErrorResponse}
+// @Router /api/v1/addresses/{id}/status [get]
+func (h *WatcherStatusHandler) AddressStatus(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	status, res, err := h.service.AddressStatus(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return respondError(c, status, dto.ErrorResponse{
+			Message: "Failed to get address status",
+			Details: err.Error(),
+		})
+	}
+
+	return respond(c, status, res)
+}
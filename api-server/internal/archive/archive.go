@@ -0,0 +1,398 @@
+// Package archive exports the users, addresses and alert rules of a database to a versioned
+// archive and imports them again, to restore a backup or to clone an environment.
+//
+// An archive is either one JSON document holding every record, or NDJSON: a header line followed
+// by one line per record, which is written and read without holding the records in memory.
+// Records keep their IDs and timestamps. Importing skips records that conflict with existing
+// ones, so an archive can be imported again, and runs in one transaction.
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/severity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Version is the archive format version written by Export. Import reads archives up to it.
+const Version = 1
+
+// Formats of an archive
+const (
+	JSON   = "json"
+	NDJSON = "ndjson"
+)
+
+// batchSize is the number of records exported per query
+const batchSize = 500
+
+// Record kinds of NDJSON lines
+const (
+	kindHeader    = "header"
+	kindUser      = "user"
+	kindAddress   = "address"
+	kindAlertRule = "alert_rule"
+)
+
+type User struct {
+	ID            uuid.UUID          `json:"id"`
+	Email         string             `json:"email"`
+	PasswordHash  string             `json:"password_hash"`
+	PhoneNumber   pgtype.Text        `json:"phone_number"`
+	WalletAddress pgtype.Text        `json:"wallet_address"`
+	Subscribed    bool               `json:"subscribed"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Address struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Chain     string             `json:"chain"`
+	Address   string             `json:"address"`
+	Label     pgtype.Text        `json:"label"`
+	Notes     pgtype.Text        `json:"notes"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AlertRule struct {
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	AddressID       pgtype.UUID        `json:"address_id"`
+	Name            string             `json:"name"`
+	Direction       string             `json:"direction"`
+	MinValue        pgtype.Numeric     `json:"min_value"`
+	TokenAddress    pgtype.Text        `json:"token_address"`
+	CooldownSeconds int32              `json:"cooldown_seconds"`
+	Enabled         bool               `json:"enabled"`
+	Severity        string             `json:"severity,omitempty"`
+	TokenPreset     pgtype.Text        `json:"token_preset"`
+	Expression      pgtype.Text        `json:"expression"`
+	Priority        int32              `json:"priority,omitempty"`
+	StopProcessing  bool               `json:"stop_processing,omitempty"`
+	Counterparties  []string           `json:"counterparties,omitempty"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+// header is the start of an archive: the NDJSON header line, or the JSON document with its
+// records
+type header struct {
+	Kind       string      `json:"kind,omitempty"`
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Users      []User      `json:"users,omitempty"`
+	Addresses  []Address   `json:"addresses,omitempty"`
+	AlertRules []AlertRule `json:"alert_rules,omitempty"`
+}
+
+// line is an NDJSON record line
+type line struct {
+	Kind   string          `json:"kind"`
+	Record json.RawMessage `json:"record"`
+}
+
+// Stats counts the records of an export or import. Skipped records conflicted with existing
+// ones on import.
+type Stats struct {
+	Users      int `json:"users"`
+	Addresses  int `json:"addresses"`
+	AlertRules int `json:"alert_rules"`
+	Skipped    int `json:"skipped"`
+}
+
+// Export writes the live records of repos to w in format. Records are read in batches, each a
+// separate query, so records changed during the export may be missing from the archive.
+func Export(ctx context.Context, repos postgres.Repositories, w io.Writer, format string) (Stats, error) {
+	var stats Stats
+	var out recordWriter
+	switch format {
+	case NDJSON:
+		out = newLineWriter(w)
+	case JSON, "":
+		out = &documentWriter{w: w}
+	default:
+		return stats, fmt.Errorf("unknown archive format %q", format)
+	}
+
+	if err := out.begin(header{Version: Version, ExportedAt: time.Now().UTC()}); err != nil {
+		return stats, err
+	}
+	err := exportAll(ctx, repos.Archive.ExportUsers, func(u sqlc.User) (uuid.UUID, error) {
+		stats.Users++
+		return u.ID, out.write(kindUser, User{
+			ID:            u.ID,
+			Email:         u.Email,
+			PasswordHash:  u.PasswordHash,
+			PhoneNumber:   u.PhoneNumber,
+			WalletAddress: u.WalletAddress,
+			Subscribed:    u.Subscribed,
+			CreatedAt:     u.CreatedAt,
+			UpdatedAt:     u.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to export users: %w", err)
+	}
+	err = exportAll(ctx, repos.Archive.ExportAddresses, func(a sqlc.Address) (uuid.UUID, error) {
+		stats.Addresses++
+		return a.ID, out.write(kindAddress, Address{
+			ID:        a.ID,
+			UserID:    a.UserID,
+			Chain:     a.Chain,
+			Address:   a.Address,
+			Label:     a.Label,
+			Notes:     a.Notes,
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to export addresses: %w", err)
+	}
+	err = exportAll(ctx, repos.Archive.ExportAlertRules, func(r sqlc.AlertRule) (uuid.UUID, error) {
+		stats.AlertRules++
+		counterparties, err := repos.AlertRules.ListCounterparties(ctx, r.UserID, []uuid.UUID{r.ID})
+		if err != nil {
+			return r.ID, err
+		}
+		return r.ID, out.write(kindAlertRule, AlertRule{
+			ID:              r.ID,
+			UserID:          r.UserID,
+			AddressID:       r.AddressID,
+			Name:            r.Name,
+			Direction:       r.Direction,
+			MinValue:        r.MinValue,
+			TokenAddress:    r.TokenAddress,
+			CooldownSeconds: r.CooldownSeconds,
+			Enabled:         r.Enabled,
+			Severity:        r.Severity,
+			TokenPreset:     r.TokenPreset,
+			Expression:      r.Expression,
+			Priority:        r.Priority,
+			StopProcessing:  r.StopProcessing,
+			Counterparties:  counterparties[r.ID],
+			CreatedAt:       r.CreatedAt,
+			UpdatedAt:       r.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to export alert rules: %w", err)
+	}
+
+	return stats, out.end()
+}
+
+// exportAll pages through the records returned by list, writing each with write, which returns
+// the record's ID
+func exportAll[T any](ctx context.Context, list func(context.Context, pgtype.UUID, int32) ([]T, error), write func(T) (uuid.UUID, error)) error {
+	var after pgtype.UUID
+	for {
+		records, err := list(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			id, err := write(record)
+			if err != nil {
+				return err
+			}
+			after = pgtype.UUID{Bytes: id, Valid: true}
+		}
+		if len(records) < batchSize {
+			return nil
+		}
+	}
+}
+
+// Import reads an archive of either format from r and creates its records in one transaction of
+// tx, users before their addresses and addresses before their rules. A record referencing a
+// user or address that is neither in the database nor earlier in the archive fails the import.
+func Import(ctx context.Context, tx postgres.ITxManager, r io.Reader) (Stats, error) {
+	var stats Stats
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		return stats, fmt.Errorf("failed to read archive header: %w", err)
+	}
+	if h.Version < 1 || h.Version > Version {
+		return stats, fmt.Errorf("unsupported archive version %d, expected at most %d", h.Version, Version)
+	}
+
+	err := tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		stats = Stats{}
+		in := importer{repos: repos.Archive, rules: repos.AlertRules, stats: &stats}
+		if h.Kind != kindHeader {
+			return in.document(ctx, h)
+		}
+		for n := 2; ; n++ {
+			var l line
+			if err := dec.Decode(&l); errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+			if err := in.line(ctx, l); err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+		}
+	})
+	return stats, err
+}
+
+// importer creates the records of an archive, counting them in stats
+type importer struct {
+	repos postgres.IArchiveInterface
+	rules postgres.IAlertRuleInterface
+	stats *Stats
+}
+
+// document imports the records of a JSON archive
+func (in importer) document(ctx context.Context, h header) error {
+	for _, u := range h.Users {
+		if err := in.user(ctx, u); err != nil {
+			return err
+		}
+	}
+	for _, a := range h.Addresses {
+		if err := in.address(ctx, a); err != nil {
+			return err
+		}
+	}
+	for _, r := range h.AlertRules {
+		if err := in.alertRule(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// line imports the record of an NDJSON line
+func (in importer) line(ctx context.Context, l line) error {
+	switch l.Kind {
+	case kindUser:
+		var u User
+		if err := json.Unmarshal(l.Record, &u); err != nil {
+			return err
+		}
+		return in.user(ctx, u)
+	case kindAddress:
+		var a Address
+		if err := json.Unmarshal(l.Record, &a); err != nil {
+			return err
+		}
+		return in.address(ctx, a)
+	case kindAlertRule:
+		var r AlertRule
+		if err := json.Unmarshal(l.Record, &r); err != nil {
+			return err
+		}
+		return in.alertRule(ctx, r)
+	}
+	return fmt.Errorf("unknown record kind %q", l.Kind)
+}
+
+func (in importer) user(ctx context.Context, u User) error {
+	created, err := in.repos.ImportUser(ctx, sqlc.ImportUserParams{
+		ID:            u.ID,
+		Email:         u.Email,
+		PasswordHash:  u.PasswordHash,
+		PhoneNumber:   u.PhoneNumber,
+		WalletAddress: u.WalletAddress,
+		Subscribed:    u.Subscribed,
+		CreatedAt:     timestamp(u.CreatedAt),
+		UpdatedAt:     timestamp(u.UpdatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import user %s: %w", u.ID, err)
+	}
+	in.count(created, &in.stats.Users)
+	return nil
+}
+
+func (in importer) address(ctx context.Context, a Address) error {
+	created, err := in.repos.ImportAddress(ctx, sqlc.ImportAddressParams{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		Chain:     a.Chain,
+		Address:   a.Address,
+		Label:     a.Label,
+		Notes:     a.Notes,
+		CreatedAt: timestamp(a.CreatedAt),
+		UpdatedAt: timestamp(a.UpdatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import address %s: %w", a.ID, err)
+	}
+	in.count(created, &in.stats.Addresses)
+	return nil
+}
+
+func (in importer) alertRule(ctx context.Context, r AlertRule) error {
+	minValue := r.MinValue
+	if !minValue.Valid {
+		minValue = pgtype.Numeric{Int: new(big.Int), Valid: true}
+	}
+	// Archives exported before severities have none
+	ruleSeverity := r.Severity
+	if ruleSeverity == "" {
+		ruleSeverity = severity.Default
+	}
+	created, err := in.repos.ImportAlertRule(ctx, sqlc.ImportAlertRuleParams{
+		ID:              r.ID,
+		UserID:          r.UserID,
+		AddressID:       r.AddressID,
+		Name:            r.Name,
+		Direction:       r.Direction,
+		MinValue:        minValue,
+		TokenAddress:    r.TokenAddress,
+		CooldownSeconds: r.CooldownSeconds,
+		Enabled:         r.Enabled,
+		Severity:        ruleSeverity,
+		TokenPreset:     r.TokenPreset,
+		Expression:      r.Expression,
+		Priority:        r.Priority,
+		StopProcessing:  r.StopProcessing,
+		CreatedAt:       timestamp(r.CreatedAt),
+		UpdatedAt:       timestamp(r.UpdatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import alert rule %s: %w", r.ID, err)
+	}
+	if created && len(r.Counterparties) > 0 {
+		if err := in.rules.SetCounterparties(ctx, r.ID, r.UserID, r.Counterparties); err != nil {
+			return fmt.Errorf("failed to import the counterparties of alert rule %s: %w", r.ID, err)
+		}
+	}
+	in.count(created, &in.stats.AlertRules)
+	return nil
+}
+
+// count adds a created record to n, or a skipped one to the skipped records
+func (in importer) count(created bool, n *int) {
+	if created {
+		*n++
+	} else {
+		in.stats.Skipped++
+	}
+}
+
+// timestamp defaults a missing timestamp of an archived record to now
+func timestamp(t pgtype.Timestamptz) pgtype.Timestamptz {
+	if !t.Valid {
+		return pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+	}
+	return t
+}
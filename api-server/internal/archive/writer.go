@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// recordWriter writes an archive: its header, then its records grouped by kind
+type recordWriter interface {
+	begin(h header) error
+	write(kind string, record any) error
+	end() error
+}
+
+// lineWriter writes NDJSON archives
+type lineWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newLineWriter(w io.Writer) *lineWriter {
+	buf := bufio.NewWriter(w)
+	return &lineWriter{w: buf, enc: json.NewEncoder(buf)}
+}
+
+func (l *lineWriter) begin(h header) error {
+	h.Kind = kindHeader
+	return l.enc.Encode(h)
+}
+
+func (l *lineWriter) write(kind string, record any) error {
+	return l.enc.Encode(struct {
+		Kind   string `json:"kind"`
+		Record any    `json:"record"`
+	}{kind, record})
+}
+
+func (l *lineWriter) end() error {
+	return l.w.Flush()
+}
+
+// documentKeys are the keys of the record arrays of JSON archives
+var documentKeys = map[string]string{
+	kindUser:      "users",
+	kindAddress:   "addresses",
+	kindAlertRule: "alert_rules",
+}
+
+// documentWriter writes JSON archives, streaming the records into the array of their kind
+type documentWriter struct {
+	w     io.Writer
+	buf   *bufio.Writer
+	kind  string
+	count int
+}
+
+func (d *documentWriter) begin(h header) error {
+	d.buf = bufio.NewWriter(d.w)
+	b, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	// The header without records, left open for their arrays
+	_, err = d.buf.Write(b[:len(b)-1])
+	return err
+}
+
+func (d *documentWriter) write(kind string, record any) error {
+	if kind != d.kind {
+		if d.kind != "" {
+			d.buf.WriteString("]")
+		}
+		d.buf.WriteString(`,"` + documentKeys[kind] + `":[`)
+		d.kind, d.count = kind, 0
+	}
+	if d.count > 0 {
+		d.buf.WriteString(",")
+	}
+	d.count++
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = d.buf.Write(b)
+	return err
+}
+
+func (d *documentWriter) end() error {
+	if d.kind != "" {
+		d.buf.WriteString("]")
+	}
+	d.buf.WriteString("}\n")
+	return d.buf.Flush()
+}
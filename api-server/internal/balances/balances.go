@@ -0,0 +1,223 @@
+// Package balances polls the ERC-20 balances of the users' balance watches with balanceOf and
+// raises an alert when one moved by at least the watch's min_delta. It catches the balance changes
+// of tokens that emit no Transfer events, such as rebasing tokens and some bridges. Deltas are
+// measured from the balance first read, then from the one of the last alert, so slow drifts alert
+// once they add up.
+package balances
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/rpc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// pollInterval is how often due watches are claimed. A watch's own interval is at least 15s.
+	pollInterval = 5 * time.Second
+	// claimLimit bounds the watches claimed at once
+	claimLimit = 50
+	// concurrency is the number of balances read from the node at once
+	concurrency = 8
+	// balanceOfSelector is the selector of the ERC-20 call balanceOf(address)
+	balanceOfSelector = "0x70a08231"
+)
+
+// Poller checks the due balance watches of addresses on the node's network
+type Poller struct {
+	watches   postgres.IBalanceWatchInterface
+	txManager postgres.ITxManager
+	network   string
+	node      *rpc.Client
+}
+
+// New creates a poller of the watches of addresses on network, read from node, a node of it
+func New(watches postgres.IBalanceWatchInterface, txManager postgres.ITxManager, network string,
+	node *rpc.Client) *Poller {
+	return &Poller{
+		watches:   watches,
+		txManager: txManager,
+		network:   strings.ToLower(network),
+		node:      node,
+	}
+}
+
+// Run polls the due watches until ctx is done. Bitcoin has no ERC-20 tokens, so nothing is
+// polled on it.
+func (p *Poller) Run(ctx context.Context) {
+	if p.network == "bitcoin" {
+		return
+	}
+	for ctx.Err() == nil {
+		if err := p.Poll(context.WithoutCancel(ctx)); err != nil && !errors.Is(err, rpc.ErrNotConfigured) {
+			log.Printf("Failed to poll balances: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Poll checks the due watches, reading their balances at the node's latest block. A watch that
+// cannot be read records the error and is tried again an interval later.
+func (p *Poller) Poll(ctx context.Context) error {
+	for {
+		watches, err := p.watches.ClaimDueWatches(ctx, p.network, claimLimit)
+		if err != nil {
+			return fmt.Errorf("failed to claim balance watches: %w", err)
+		}
+		if len(watches) == 0 {
+			return nil
+		}
+
+		var block string
+		if err := p.node.Call(ctx, &block, "eth_blockNumber"); err != nil {
+			for _, w := range watches {
+				p.recordCheck(ctx, w.ID, err)
+			}
+			return err
+		}
+		blockNumber, err := parseHex(block)
+		if err != nil {
+			return fmt.Errorf("invalid block number: %w", err)
+		}
+
+		slots := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for _, w := range watches {
+			wg.Go(func() {
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				if err := p.check(ctx, w, blockNumber.Int64()); err != nil {
+					log.Printf("Failed to check balance watch %s: %v", w.ID, err)
+				}
+			})
+		}
+		wg.Wait()
+
+		if len(watches) < claimLimit {
+			return nil
+		}
+	}
+}
+
+// check reads the balance of w at block, and records it with an alert when it moved by at least
+// w's min_delta. The first balance read is recorded without an alert.
+func (p *Poller) check(ctx context.Context, w sqlc.ClaimDueBalanceWatchesRow, block int64) error {
+	balance, err := p.balanceOf(ctx, w.TokenAddress, w.Address, block)
+	if err != nil {
+		p.recordCheck(ctx, w.ID, err)
+		return nil
+	}
+	recorded := pgtype.Numeric{Int: balance, Valid: true}
+	if !w.Balance.Valid {
+		return ignoreStale(p.watches.RecordBalance(ctx, w.ID, recorded, block))
+	}
+
+	previous := integer(w.Balance)
+	delta := new(big.Int).Sub(balance, previous)
+	if delta.Sign() == 0 || delta.CmpAbs(integer(w.MinDelta)) < 0 {
+		p.recordCheck(ctx, w.ID, nil)
+		return nil
+	}
+
+	change := "rose"
+	if delta.Sign() < 0 {
+		change = "fell"
+	}
+	message := fmt.Sprintf("Balance of token %s %s by %s to %s at block %d", w.TokenAddress, change,
+		new(big.Int).Abs(delta), balance, block)
+	err = p.txManager.WithinTx(ctx, func(repos postgres.Repositories) error {
+		// A balance read behind the recorded one raises no alert
+		if err := repos.BalanceWatches.RecordBalance(ctx, w.ID, recorded, block); err != nil {
+			return err
+		}
+		_, err := repos.Alerts.CreateAlert(ctx, sqlc.CreateAlertParams{
+			ID:        uuid.New(),
+			UserID:    w.UserID,
+			AddressID: w.AddressID,
+			Message:   message,
+			Severity:  w.Severity,
+		})
+		return err
+	})
+	return ignoreStale(err)
+}
+
+// balanceOf reads the balance of holder in token at block
+func (p *Poller) balanceOf(ctx context.Context, token, holder string, block int64) (*big.Int, error) {
+	address := strings.TrimPrefix(strings.ToLower(holder), "0x")
+	if len(address) != 40 {
+		return nil, fmt.Errorf("%q is not an EVM address", holder)
+	}
+	call := map[string]string{
+		"to":   token,
+		"data": balanceOfSelector + strings.Repeat("0", 24) + address,
+	}
+
+	var result string
+	if err := p.node.Call(ctx, &result, "eth_call", call, fmt.Sprintf("0x%x", block)); err != nil {
+		return nil, err
+	}
+	// A contract without balanceOf, or an address that is no contract, answers empty data
+	if result == "0x" || result == "" {
+		return nil, fmt.Errorf("%s answered no balance, is it an ERC-20 token?", token)
+	}
+	return parseHex(result)
+}
+
+// recordCheck records a check of watch id that left its balance as recorded, with the error it
+// failed with, if any
+func (p *Poller) recordCheck(ctx context.Context, id uuid.UUID, cause error) {
+	var lastError pgtype.Text
+	if cause != nil {
+		lastError = pgtype.Text{String: cause.Error(), Valid: true}
+	}
+	if err := p.watches.RecordCheck(ctx, id, lastError); err != nil {
+		log.Printf("Failed to record the check of balance watch %s: %v", id, err)
+	}
+}
+
+// ignoreStale drops the pgx.ErrNoRows of recording a balance behind the recorded one, e.g. read
+// from a lagging node
+func ignoreStale(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// parseHex parses a hex quantity or 32-byte word
+func parseHex(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	return n, nil
+}
+
+// integer returns the integer value of n, zero when NULL
+func integer(n pgtype.Numeric) *big.Int {
+	if !n.Valid || n.Int == nil {
+		return new(big.Int)
+	}
+	v := new(big.Int).Set(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(n.Exp, -n.Exp))), nil)
+	if n.Exp >= 0 {
+		return v.Mul(v, exp)
+	}
+	return v.Quo(v, exp)
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ListActivityRequest asks for a page of an address's or the account's activity, following Cursor,
+// the next_cursor of the previous page
+type ListActivityRequest struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// ActivityItem is an entry of an address's activity feed, newest first. Type is transaction, alert
+// or change, and tells which of Transaction, Alert and Change is set. At is when the entry was
+// recorded; a transaction's block time is its OccurredAt.
+type ActivityItem struct {
+	Type        string               `json:"type"`
+	ID          string               `json:"id"`
+	At          time.Time            `json:"at"`
+	Transaction *ActivityTransaction `json:"transaction,omitempty"`
+	Alert       *ActivityAlert       `json:"alert,omitempty"`
+	Change      *ActivityChange      `json:"change,omitempty"`
+}
+
+// ActivityTransaction is a transfer to or from the address. Direction is in, out, or self for a
+// transfer to itself; Value is in the token's smallest unit and Token empty for the native
+// currency.
+type ActivityTransaction struct {
+	Chain       string    `json:"chain"`
+	Hash        string    `json:"hash"`
+	LogIndex    int32     `json:"log_index"`
+	BlockNumber int64     `json:"block_number"`
+	From        string    `json:"from"`
+	To          string    `json:"to,omitempty"`
+	Direction   string    `json:"direction"`
+	Value       string    `json:"value"`
+	Token       string    `json:"token,omitempty"`
+	Status      string    `json:"status"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ActivityAlert is an alert raised for the address. Alerts of balance changes have no
+// TransactionID.
+type ActivityAlert struct {
+	RuleID         string     `json:"rule_id,omitempty"`
+	TransactionID  string     `json:"transaction_id,omitempty"`
+	Message        string     `json:"message"`
+	Severity       string     `json:"severity"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// ActivityChange is a change of the address or of a rule applying to it, e.g. address.muted or
+// rule.paused. Detail holds what the kind records, such as the rule's name or the end of a mute.
+type ActivityChange struct {
+	Kind   string          `json:"kind"`
+	RuleID string          `json:"rule_id,omitempty"`
+	Detail json.RawMessage `json:"detail,omitempty"`
+}
+
+// AccountActivityItem is an entry of the account's activity feed, newest first: a sign-in, e.g.
+// login or login.failed, or a change of the profile, password, webhooks, API keys or the watched
+// addresses, e.g. webhook.created or address.deleted. IPAddress and UserAgent are the client's
+// where recorded. Detail holds what the kind records, such as a webhook's URL or the changed
+// profile fields.
+type AccountActivityItem struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	At        time.Time       `json:"at"`
+	AddressID string          `json:"address_id,omitempty"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// CreateAddressRequest watches Address on Chain, one of package chains, e.g. ethereum or bitcoin.
+// Address must have the chain's address format. Label is the address's nickname, shown instead of
+// the address, and Notes free-form text; both are searchable.
+type CreateAddressRequest struct {
+	Chain   string `json:"chain" validate:"required,chain"`
+	Address string `json:"address" validate:"required,max=255"`
+	Label   string `json:"label" validate:"omitempty,max=255"`
+	Notes   string `json:"notes" validate:"omitempty,max=2000"`
+}
+
+// UpdateAddressRequest replaces the label and notes of an address; its chain and address are kept.
+// Version is the version of the address the change is based on, as last read; the update fails with
+// 409 when it has changed since.
+type UpdateAddressRequest struct {
+	Label   string `json:"label" validate:"omitempty,max=255"`
+	Notes   string `json:"notes" validate:"omitempty,max=2000"`
+	Version int32  `json:"version" validate:"required,min=1"`
+}
+
+// ListAddressesRequest asks for a page of the user's addresses, following Cursor, the next_cursor
+// of the previous page
+type ListAddressesRequest struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// AddressResponse is a watched address. MutedUntil is set when it is muted until a time, MutedAt
+// whenever it is muted.
+type AddressResponse struct {
+	ID         string     `json:"id"`
+	Chain      string     `json:"chain"`
+	Address    string     `json:"address"`
+	Label      string     `json:"label,omitempty"`
+	Notes      string     `json:"notes,omitempty"`
+	MutedAt    *time.Time `json:"muted_at,omitempty"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+	Version    int32      `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
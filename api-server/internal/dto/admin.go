@@ -0,0 +1,91 @@
+package dto
+
+import "time"
+
+// AdminStatsResponse is the operations dashboard snapshot. The alert and notification figures cover
+// the last 24 hours.
+type AdminStatsResponse struct {
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Users         int64             `json:"users"`
+	Addresses     AddressStats      `json:"addresses"`
+	Alerts24h     int64             `json:"alerts_24h"`
+	Notifications NotificationStats `json:"notifications"`
+	Chains        []ChainHeadStats  `json:"chains"`
+	Consumer      ConsumerStats     `json:"consumer"`
+}
+
+type AddressStats struct {
+	Total   int64               `json:"total"`
+	ByChain []ChainAddressStats `json:"by_chain"`
+}
+
+// ChainAddressStats counts the addresses watched on a chain and the users watching them
+type ChainAddressStats struct {
+	Chain     string `json:"chain"`
+	Addresses int64  `json:"addresses"`
+	Users     int64  `json:"users"`
+}
+
+// NotificationStats counts notification deliveries by state. FailureRate is the share of finished
+// deliveries that failed, pending ones are left out. Skipped deliveries were not sent on purpose,
+// e.g. because their address was muted.
+type NotificationStats struct {
+	Sent        int64                  `json:"sent"`
+	Failed      int64                  `json:"failed"`
+	Pending     int64                  `json:"pending"`
+	Skipped     int64                  `json:"skipped"`
+	FailureRate float64                `json:"failure_rate"`
+	ByChannel   []ChannelDeliveryStats `json:"by_channel"`
+}
+
+type ChannelDeliveryStats struct {
+	Channel     string  `json:"channel"`
+	Sent        int64   `json:"sent"`
+	Failed      int64   `json:"failed"`
+	Pending     int64   `json:"pending"`
+	Skipped     int64   `json:"skipped"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// ChainHeadStats is how far the watcher of a chain is behind: the newest block it stored a
+// transaction of, and how long ago that block was produced. The head is omitted when no
+// transaction of the chain was stored in the last 7 days.
+type ChainHeadStats struct {
+	Chain          string     `json:"chain"`
+	HeadBlock      *int64     `json:"head_block,omitempty"`
+	HeadTime       *time.Time `json:"head_time,omitempty"`
+	HeadLagSeconds *float64   `json:"head_lag_seconds,omitempty"`
+}
+
+// ConsumerStats is the engine's consumer group lag, in messages, read from its admin server. Lag
+// is omitted when no engine is configured, its transport has no lag or it was not measured yet.
+type ConsumerStats struct {
+	Transport string     `json:"transport,omitempty"`
+	Lag       *int64     `json:"lag,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// ImpersonateRequest asks for a token acting as a user, to reproduce the issue described by
+// Reason. The token is valid for TTLMinutes, 15 by default. Operator, IPAddress and UserAgent are
+// set by the handler for the audit log: the support operator whose own admin token the request
+// presented, and their client.
+type ImpersonateRequest struct {
+	Reason     string `json:"reason" validate:"required,max=500"`
+	TTLMinutes int    `json:"ttl_minutes" validate:"omitempty,min=1,max=60"`
+	Operator   string `json:"-"`
+	IPAddress  string `json:"-"`
+	UserAgent  string `json:"-"`
+}
+
+// ImpersonationResponse is a token acting as the user. Responses to requests made with it carry
+// the X-Impersonated-By header, and the requests are listed in the user's account activity. The
+// token stops working when its session SessionID is revoked, by the user or an operator.
+type ImpersonationResponse struct {
+	Token     string    `json:"token"`
+	TokenID   string    `json:"token_id"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	Operator  string    `json:"operator"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
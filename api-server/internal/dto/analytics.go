@@ -0,0 +1,34 @@
+package dto
+
+type APIAnalyticsRequest struct {
+	Days int `query:"days" validate:"omitempty,min=1,max=90"`
+}
+
+// APIAnalyticsResponse is the user's API usage of the UTC days From to To: per endpoint over the
+// whole period, and per day for the days with calls, oldest first
+type APIAnalyticsResponse struct {
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Endpoints []EndpointUsage `json:"endpoints"`
+	Days      []DailyUsage    `json:"days"`
+}
+
+type DailyUsage struct {
+	Date         string          `json:"date"`
+	Calls        int64           `json:"calls"`
+	ClientErrors int64           `json:"client_errors"`
+	ServerErrors int64           `json:"server_errors"`
+	Endpoints    []EndpointUsage `json:"endpoints"`
+}
+
+// EndpointUsage counts the calls of an endpoint, Route being its pattern, e.g. /api/v1/jobs/:id.
+// ClientErrors are the 4xx responses, ServerErrors the 5xx ones.
+type EndpointUsage struct {
+	Method       string  `json:"method"`
+	Route        string  `json:"route"`
+	Calls        int64   `json:"calls"`
+	ClientErrors int64   `json:"client_errors"`
+	ServerErrors int64   `json:"server_errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs float64 `json:"max_latency_ms"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest creates an API key named Name, valid for ExpiresInDays or until revoked when
+// zero. Scopes are the routes the key reaches, e.g. read:addresses for a dashboard. AllowedCIDRs
+// are the networks the key is accepted from, e.g. 203.0.113.0/24 or a single address; any when
+// empty. A Signed key signs its requests with a signing secret instead of presenting the key.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=255"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	Signed        bool     `json:"signed"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=3650"`
+	AllowedCIDRs  []string `json:"allowed_cidrs" validate:"max=32"`
+}
+
+// SetAPIKeyAllowedCIDRsRequest replaces the networks an API key is accepted from; an empty list
+// accepts it from anywhere
+type SetAPIKeyAllowedCIDRsRequest struct {
+	AllowedCIDRs []string `json:"allowed_cidrs" validate:"required,max=32"`
+}
+
+// SetAPIKeyScopesRequest replaces the scopes of an API key
+type SetAPIKeyScopesRequest struct {
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+}
+
+// APIKeyResponse is an API key, identified to its owner by Prefix, the start of the key
+type APIKeyResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	Scopes       []string   `json:"scopes"`
+	AllowedCIDRs []string   `json:"allowed_cidrs"`
+	Signed       bool       `json:"signed"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse is a new API key with the key itself, which is only shown once. Requests
+// present it in the X-API-Key header, or when the key is signed sign with SigningSecret, which is
+// only shown once too.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key           string `json:"key"`
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// CreateBalanceWatchRequest polls the balance of an ERC-20 token held by an address every
+// IntervalSeconds, 300 by default, alerting when it moved by at least MinDelta, in the token's
+// smallest unit. The alerts have Severity, warning by default.
+type CreateBalanceWatchRequest struct {
+	TokenAddress    string `json:"token_address" validate:"required,eth_addr"`
+	IntervalSeconds int32  `json:"interval_seconds" validate:"omitempty,min=15,max=86400"`
+	MinDelta        string `json:"min_delta" validate:"omitempty,number"`
+	Severity        string `json:"severity" validate:"omitempty,oneof=info warning critical"`
+	Enabled         *bool  `json:"enabled"`
+}
+
+// UpdateBalanceWatchRequest replaces the settings of a balance watch; its token is kept
+type UpdateBalanceWatchRequest struct {
+	IntervalSeconds int32  `json:"interval_seconds" validate:"required,min=15,max=86400"`
+	MinDelta        string `json:"min_delta" validate:"required,number"`
+	Severity        string `json:"severity" validate:"required,oneof=info warning critical"`
+	Enabled         *bool  `json:"enabled" validate:"required"`
+}
+
+// BalanceWatchResponse is a balance watch. Balance is the one deltas are measured from, read at
+// BlockNumber: the first read, then the one of the last alert. LastError is why the last check
+// failed.
+type BalanceWatchResponse struct {
+	ID              string     `json:"id"`
+	AddressID       string     `json:"address_id"`
+	TokenAddress    string     `json:"token_address"`
+	IntervalSeconds int32      `json:"interval_seconds"`
+	MinDelta        string     `json:"min_delta"`
+	Severity        string     `json:"severity"`
+	Enabled         bool       `json:"enabled"`
+	Balance         string     `json:"balance,omitempty"`
+	BlockNumber     *int64     `json:"block_number,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"`
+	NextCheckAt     time.Time  `json:"next_check_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
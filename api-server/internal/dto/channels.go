@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// ChannelResponse is the verification state of one of the user's notification channels: their
+// email, or a webhook, whose ID is WebhookID. Alerts are only delivered over verified channels.
+type ChannelResponse struct {
+	Channel    string     `json:"channel"`
+	Target     string     `json:"target"`
+	WebhookID  string     `json:"webhook_id,omitempty"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// VerifyEmailRequest confirms the user's email with the token of the link sent to it
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// DashboardResponse summarizes the user's watch list for their home screen
+type DashboardResponse struct {
+	Addresses    DashboardAddresses `json:"addresses"`
+	Alerts       DashboardAlerts    `json:"alerts"`
+	TopAddresses []DashboardAddress `json:"top_addresses"`
+	// Portfolio is left out when the configured node cannot read balances, e.g. a Bitcoin node
+	Portfolio   *DashboardPortfolio `json:"portfolio,omitempty"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
+
+// DashboardAddresses counts the watched addresses. Active ones notify of their alerts, muted ones
+// do not until their mute ends.
+type DashboardAddresses struct {
+	Watched int64 `json:"watched"`
+	Active  int64 `json:"active"`
+	Muted   int64 `json:"muted"`
+}
+
+// DashboardAlerts counts the alerts raised in the last 24 hours and 7 days
+type DashboardAlerts struct {
+	LastDay  int64 `json:"last_day"`
+	LastWeek int64 `json:"last_week"`
+}
+
+// DashboardAddress is one of the addresses with the most transactions in the last 7 days
+type DashboardAddress struct {
+	ID                string    `json:"id"`
+	Chain             string    `json:"chain"`
+	Address           string    `json:"address"`
+	Label             string    `json:"label,omitempty"`
+	Transactions      int64     `json:"transactions"`
+	Alerts            int64     `json:"alerts"`
+	LastTransactionAt time.Time `json:"last_transaction_at"`
+}
+
+// DashboardPortfolio is the native currency held by the addresses watched on Chain, the network
+// of the configured node, in its smallest unit, e.g. wei. Addresses is the number of balances
+// summed, at most the first 100 addresses.
+type DashboardPortfolio struct {
+	Chain     string `json:"chain"`
+	Value     string `json:"value"`
+	Addresses int    `json:"addresses"`
+}
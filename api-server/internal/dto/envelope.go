@@ -0,0 +1,40 @@
+package dto
+
+// Envelope is the body of every JSON response: Data on success, Error on failure, and Meta on
+// both. Handlers write it through the helpers of package api rather than building it.
+type Envelope struct {
+	Data  any            `json:"data,omitempty"`
+	Error *ErrorResponse `json:"error,omitempty"`
+	Meta  Meta           `json:"meta"`
+}
+
+// Meta describes the response. RequestID is the trace ID of the request, also returned in the
+// traceparent header and logged, to quote when reporting a problem.
+type Meta struct {
+	RequestID  string      `json:"request_id"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the page of a list response. NextCursor is set when more items follow,
+// for lists paged by cursor.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Page is a page of a list as returned by the services, written as Items in data and Pagination in
+// meta
+type Page[T any] struct {
+	Items      []T
+	Pagination Pagination
+}
+
+// ErrorResponse is the error of a failed request. Code is derived from the HTTP status, e.g.
+// "not_found", Fields maps invalid fields to their validation errors.
+type ErrorResponse struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details string            `json:"details,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
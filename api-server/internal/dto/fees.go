@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// FeesResponse holds the current fee estimates of a chain: EIP-1559 fees in wei on
+// Ethereum-compatible chains, with GasPrice instead on chains without EIP-1559, and fee rates in
+// sat/vB on Bitcoin
+type FeesResponse struct {
+	Chain string `json:"chain"`
+	// BaseFee is the base fee of the next block
+	BaseFee   string    `json:"base_fee,omitempty"`
+	Slow      FeeTier   `json:"slow"`
+	Standard  FeeTier   `json:"standard"`
+	Fast      FeeTier   `json:"fast"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// FeeTier is the fee to pay for a transaction to be included slowly, normally or fast
+type FeeTier struct {
+	MaxPriorityFee string   `json:"max_priority_fee,omitempty"`
+	MaxFee         string   `json:"max_fee,omitempty"`
+	GasPrice       string   `json:"gas_price,omitempty"`
+	SatPerVByte    *float64 `json:"sat_per_vbyte,omitempty"`
+	// TargetBlocks is the number of blocks a Bitcoin transaction paying SatPerVByte is expected to
+	// confirm within
+	TargetBlocks int `json:"target_blocks,omitempty"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ListJobsRequest struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// JobResponse is a background job of the user. Result is set once the job succeeded, LastError
+// after a failed attempt.
+type JobResponse struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
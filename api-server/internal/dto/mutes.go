@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// MuteRequest mutes an address or rule until Until, an RFC 3339 time, or until it is unmuted when
+// Until is empty
+type MuteRequest struct {
+	Until string `query:"until" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// MuteResponse is the mute state of an address or rule. MutedUntil is left out while it is muted
+// until unmuted.
+type MuteResponse struct {
+	ID         string     `json:"id"`
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+// SetRuleExpressionRequest makes a rule an advanced rule whose condition is Expression, a CEL
+// expression over the normalized transaction tx and the known entities, e.g.
+// tx.value_usd > 10000 && tx.direction == "out" && !(tx.counterparty in known.exchanges)
+type SetRuleExpressionRequest struct {
+	Expression string `json:"expression" validate:"required,max=4096"`
+}
+
+// RuleExpressionResponse is the expression of a rule, left out for simple rules
+type RuleExpressionResponse struct {
+	ID         string `json:"id"`
+	Expression string `json:"expression,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+// SetRulePriorityRequest sets the order a rule is evaluated in among the rules of a transaction,
+// higher priority first, and whether matching it suppresses the rules after it, e.g. a sanctions
+// rule over the generic large transfer rules
+type SetRulePriorityRequest struct {
+	Priority       *int32 `json:"priority" validate:"required,min=-1000,max=1000"`
+	StopProcessing bool   `json:"stop_processing"`
+}
+
+// RulePriorityResponse is the priority of a rule
+type RulePriorityResponse struct {
+	ID             string `json:"id"`
+	Priority       int32  `json:"priority"`
+	StopProcessing bool   `json:"stop_processing"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+// RuleTemplateResponse is a ready-made advanced rule. Its expression has a {name} placeholder for
+// each of its parameters.
+type RuleTemplateResponse struct {
+	Name        string                      `json:"name"`
+	Title       string                      `json:"title"`
+	Description string                      `json:"description"`
+	Severity    string                      `json:"severity"`
+	Expression  string                      `json:"expression"`
+	Params      []RuleTemplateParamResponse `json:"params,omitempty"`
+}
+
+// RuleTemplateParamResponse is a number filled into a template's expression
+type RuleTemplateParamResponse struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Default     float64 `json:"default"`
+	Min         float64 `json:"min"`
+}
+
+// CreateRuleFromTemplateRequest creates a rule from a template, for the address AddressID or, when
+// left out, every address of the user. Name and Severity default to the template's title and
+// severity, and Params to the defaults of its parameters.
+type CreateRuleFromTemplateRequest struct {
+	AddressID string             `json:"address_id" validate:"omitempty,uuid"`
+	Name      string             `json:"name" validate:"omitempty,max=255"`
+	Severity  string             `json:"severity" validate:"omitempty,oneof=info warning critical"`
+	Params    map[string]float64 `json:"params"`
+}
+
+// TemplateRuleResponse is a rule created from a template
+type TemplateRuleResponse struct {
+	ID         string `json:"id"`
+	Template   string `json:"template"`
+	AddressID  string `json:"address_id,omitempty"`
+	Name       string `json:"name"`
+	Severity   string `json:"severity"`
+	Expression string `json:"expression"`
+	Enabled    bool   `json:"enabled"`
+}
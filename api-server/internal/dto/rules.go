@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// CreateRuleRequest creates a simple rule for the address AddressID or, when left out, every
+// address of the user. A transfer matches when every condition set holds: Direction, in, out or
+// any (default); MinValue, the smallest value in the token's smallest unit, e.g. wei, as a
+// decimal integer; TokenAddress, the token contract, left out for the chain's native currency;
+// and Counterparties, the addresses on the other side of the transfer. CooldownSeconds is the
+// least time between two notifications of the rule. Severity defaults to warning.
+type CreateRuleRequest struct {
+	AddressID       string   `json:"address_id" validate:"omitempty,uuid"`
+	Name            string   `json:"name" validate:"required,max=255"`
+	Direction       string   `json:"direction" validate:"omitempty,oneof=in out any"`
+	MinValue        string   `json:"min_value" validate:"omitempty,max=78"`
+	TokenAddress    string   `json:"token_address" validate:"omitempty,max=255"`
+	Counterparties  []string `json:"counterparties" validate:"omitempty,max=100,dive,required,max=255"`
+	CooldownSeconds int32    `json:"cooldown_seconds" validate:"omitempty,min=0,max=604800"`
+	Severity        string   `json:"severity" validate:"omitempty,oneof=info warning critical"`
+	Enabled         *bool    `json:"enabled"`
+}
+
+// UpdateRuleRequest replaces the conditions, name, cooldown, severity and state of a rule; its
+// address is kept. Counterparties replaces the rule's counterparties, an empty list lifting the
+// limit. Version is the version of the rule the change is based on, as last read; the update
+// fails with 409 when it has changed since.
+type UpdateRuleRequest struct {
+	Name            string   `json:"name" validate:"required,max=255"`
+	Direction       string   `json:"direction" validate:"omitempty,oneof=in out any"`
+	MinValue        string   `json:"min_value" validate:"omitempty,max=78"`
+	TokenAddress    string   `json:"token_address" validate:"omitempty,max=255"`
+	Counterparties  []string `json:"counterparties" validate:"omitempty,max=100,dive,required,max=255"`
+	CooldownSeconds int32    `json:"cooldown_seconds" validate:"omitempty,min=0,max=604800"`
+	Severity        string   `json:"severity" validate:"omitempty,oneof=info warning critical"`
+	Enabled         *bool    `json:"enabled"`
+	Version         int32    `json:"version" validate:"required,min=1"`
+}
+
+// RuleResponse is an alert rule. Expression is set for advanced rules, whose condition it is, and
+// TokenPreset when the rule's transfers are filtered to a preset's contracts. MutedUntil is set
+// when the rule is muted until a time, MutedAt whenever it is muted.
+type RuleResponse struct {
+	ID              string     `json:"id"`
+	AddressID       string     `json:"address_id,omitempty"`
+	Name            string     `json:"name"`
+	Direction       string     `json:"direction"`
+	MinValue        string     `json:"min_value"`
+	TokenAddress    string     `json:"token_address,omitempty"`
+	TokenPreset     string     `json:"token_preset,omitempty"`
+	Counterparties  []string   `json:"counterparties,omitempty"`
+	Expression      string     `json:"expression,omitempty"`
+	CooldownSeconds int32      `json:"cooldown_seconds"`
+	Severity        string     `json:"severity"`
+	Priority        int32      `json:"priority"`
+	StopProcessing  bool       `json:"stop_processing"`
+	Enabled         bool       `json:"enabled"`
+	MutedAt         *time.Time `json:"muted_at,omitempty"`
+	MutedUntil      *time.Time `json:"muted_until,omitempty"`
+	Version         int32      `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+type SearchRequest struct {
+	Query string `query:"q" validate:"required,max=200"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// SearchResponse holds the matches of a search, best first. Headlines are HTML-escaped text with
+// the matched words wrapped in <mark> tags.
+type SearchResponse struct {
+	Addresses []AddressSearchResult `json:"addresses"`
+	Entities  []EntitySearchResult  `json:"entities"`
+}
+
+type AddressSearchResult struct {
+	ID         string  `json:"id"`
+	Chain      string  `json:"chain"`
+	Address    string  `json:"address"`
+	Label      string  `json:"label,omitempty"`
+	Notes      string  `json:"notes,omitempty"`
+	EntityName string  `json:"entity_name,omitempty"`
+	Rank       float32 `json:"rank"`
+	Headline   string  `json:"headline"`
+}
+
+type EntitySearchResult struct {
+	ID       string  `json:"id"`
+	Chain    string  `json:"chain"`
+	Address  string  `json:"address"`
+	Name     string  `json:"name"`
+	Category string  `json:"category,omitempty"`
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
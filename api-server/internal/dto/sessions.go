@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// SessionResponse is a sign-in of the user, with the client it was made from. Current is set on
+// the session of the token making the request.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RevokedSessionsResponse lists the sessions signed out by revoking all other sessions
+type RevokedSessionsResponse struct {
+	Revoked    int      `json:"revoked"`
+	SessionIDs []string `json:"session_ids"`
+}
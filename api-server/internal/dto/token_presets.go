@@ -0,0 +1,28 @@
+package dto
+
+// SetTokenPresetRequest filters a rule's transfers to the token contracts of Preset, the name of
+// one of the presets of GET /api/v1/token-presets
+type SetTokenPresetRequest struct {
+	Preset string `json:"preset" validate:"required"`
+}
+
+// TokenPresetResponse is a built-in list of token contracts, by the chain of the addresses they
+// apply to
+type TokenPresetResponse struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Chains      map[string][]TokenResponse `json:"chains"`
+}
+
+// TokenResponse is a token contract of a preset
+type TokenResponse struct {
+	Symbol  string `json:"symbol"`
+	Address string `json:"address"`
+}
+
+// RuleTokenPresetResponse is the token preset of a rule, left out when its transfers are not
+// filtered by one
+type RuleTokenPresetResponse struct {
+	ID          string `json:"id"`
+	TokenPreset string `json:"token_preset,omitempty"`
+}
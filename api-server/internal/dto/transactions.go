@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// TransactionStatusResponse is the state of a transaction on chain. Values are in the token's base
+// unit: wei, the token's smallest unit, or satoshi.
+type TransactionStatusResponse struct {
+	Chain string `json:"chain"`
+	Hash  string `json:"hash"`
+	// Status is pending until the transaction is in a block, then confirmed, or failed when it
+	// reverted
+	Status        string `json:"status"`
+	BlockNumber   *int64 `json:"block_number,omitempty"`
+	BlockHash     string `json:"block_hash,omitempty"`
+	Confirmations int64  `json:"confirmations"`
+	// Final is set once the block has CHAIN_CONFIRMATIONS confirmations
+	Final     bool              `json:"final"`
+	From      string            `json:"from,omitempty"`
+	To        string            `json:"to,omitempty"`
+	Value     string            `json:"value"`
+	Fee       string            `json:"fee,omitempty"`
+	Transfers []TransferSummary `json:"transfers"`
+}
+
+// TransferSummary is a movement of value decoded from a transaction: its native value, an ERC-20
+// Transfer event, or a Bitcoin output. Token is the token's contract, empty for the native
+// currency; From is empty for Bitcoin outputs.
+type TransferSummary struct {
+	Token string `json:"token,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+}
+
+// ListTransactionsRequest asks for a page of an address's transaction history, following Cursor,
+// the next_cursor of the previous page
+type ListTransactionsRequest struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// AddressTransaction is a transfer in an address's transaction history, as stored by the engine.
+// A transaction moving several tokens has one entry per transfer, told apart by LogIndex.
+type AddressTransaction struct {
+	ID string `json:"id"`
+	ActivityTransaction
+	// DetectedAt is when the transfer was first stored, which orders the history
+	DetectedAt time.Time `json:"detected_at"`
+}
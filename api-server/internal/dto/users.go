@@ -17,24 +17,63 @@ type RegisterUserResponse struct {
 	ID string `json:"id"`
 }
 
+// LoginRequest signs in with email and password. IPAddress and UserAgent are the client's, set by
+// the handler for the account's audit log.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
+// LoginResponse is the access token of a session, valid until ExpiresAt, and the refresh token
+// exchanged for the next one at POST /users/refresh, valid until RefreshExpiresAt. Each refresh
+// token is used once.
 type LoginResponse struct {
-	ID    string `json:"id"`
-	Token string `json:"token"`
+	ID        string    `json:"id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// SessionID is the session of the token, see GET /users/me/sessions
+	SessionID        string    `json:"session_id"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshTokenRequest presents a refresh token, to exchange it or to log its session out
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=255"`
 }
 
 type UserResponse struct {
 	ID            string    `json:"id"`
 	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
 	PhoneNo       string    `json:"phone_no"`
 	WalletAddress string    `json:"wallet_address"`
 	Subscribed    bool      `json:"subscribed"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int32     `json:"version"`
+}
+
+// UpdateProfileRequest replaces the user's profile. Version is the version of the profile the
+// change is based on, as last read; the update fails with 409 when it has changed since.
+type UpdateProfileRequest struct {
+	PhoneNo       string `json:"phone_no" validate:"required,phone,min=10,max=20"`
+	WalletAddress string `json:"wallet_address"`
+	Subscribed    bool   `json:"subscribed"`
+	Version       int32  `json:"version" validate:"required,min=1"`
+}
+
+// ChangePasswordRequest replaces the user's password, proving they know CurrentPassword.
+// IPAddress and UserAgent are set like LoginRequest's, and SessionID by the handler to the session
+// the change is made from, the only one that stays signed in.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,strong_password,min=8,max=128"`
+	IPAddress       string `json:"-"`
+	UserAgent       string `json:"-"`
+	SessionID       string `json:"-"`
 }
 
 type DeleteUserRequest struct {
@@ -44,10 +83,6 @@ type DeleteUserRequest struct {
 
 type DeleteUserResponse struct {
 	Message string `json:"message"`
-}
-
-type ErrorResponse struct {
-	Error   string            `json:"error"`
-	Details string            `json:"details,omitempty"`
-	Fields  map[string]string `json:"fields,omitempty"`
+	// JobID is the job deleting the user on a hard delete, see GET /api/v1/jobs/{id}
+	JobID string `json:"job_id,omitempty"`
 }
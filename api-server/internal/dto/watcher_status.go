@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// AddressStatus is the state of the engine's watch of an address. State is pending until the
+// engine picks the address up, watching once it has, and error while the latest scan of the
+// address's chain failed. Mode is how the chain is scanned, subscription or polling, and
+// LastScannedBlock the last block checked for the address's transfers.
+type AddressStatus struct {
+	AddressID        string               `json:"address_id"`
+	Chain            string               `json:"chain"`
+	Address          string               `json:"address"`
+	State            string               `json:"state"`
+	Mode             string               `json:"mode,omitempty"`
+	WatchingSince    *time.Time           `json:"watching_since,omitempty"`
+	LastScannedBlock *int64               `json:"last_scanned_block,omitempty"`
+	LastScannedAt    *time.Time           `json:"last_scanned_at,omitempty"`
+	LastActivity     *AddressLastActivity `json:"last_activity,omitempty"`
+	LastError        *AddressWatcherError `json:"last_error,omitempty"`
+}
+
+// AddressLastActivity is the last transfer to or from the address the engine saw, At being its
+// block time
+type AddressLastActivity struct {
+	BlockNumber int64     `json:"block_number"`
+	Hash        string    `json:"hash"`
+	At          time.Time `json:"at"`
+}
+
+// AddressWatcherError is the last failure watching the address. Count is the number of failures
+// since the last successful scan, 0 once scans succeed again.
+type AddressWatcherError struct {
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+	Count   int32     `json:"count"`
+}
@@ -0,0 +1,161 @@
+// Package email renders alert notifications and account emails as branded emails and sends them
+// through the SMTP_* server. The theme, the brand name or logo, colors, footer text and support
+// address, comes from the EMAIL_* settings so white-label deployments can send notifications under
+// their own brand.
+//
+// The HTML follows the layout MJML compiles to: nested tables at most 600px wide with inline
+// styles, which email clients without CSS support still lay out, and a media query collapsing the
+// padding on narrow screens. Every email also has a plain-text part.
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/severity"
+	"github.com/google/uuid"
+)
+
+//go:embed templates/*
+var templateFiles embed.FS
+
+// layout is the frame of every HTML email, filled with the content template of each
+var layout = htmltemplate.Must(htmltemplate.New("").Funcs(htmltemplate.FuncMap{
+	"upper": strings.ToUpper,
+}).ParseFS(templateFiles, "templates/layout.html"))
+
+var (
+	alertHTML         = withLayout("alert.html")
+	verificationHTML  = withLayout("verify_email.html")
+	impersonationHTML = withLayout("impersonation.html")
+	textTemplates     = texttemplate.Must(texttemplate.New("").Funcs(texttemplate.FuncMap{
+		"upper": strings.ToUpper,
+	}).ParseFS(templateFiles, "templates/*.txt"))
+)
+
+// withLayout parses the HTML email of file, which defines its content, into a copy of layout
+func withLayout(file string) *htmltemplate.Template {
+	return htmltemplate.Must(htmltemplate.Must(layout.Clone()).ParseFS(templateFiles, "templates/"+file))
+}
+
+// severityColors are the colors of the severity badge, which stay fixed whatever the theme so
+// critical alerts look critical in every deployment
+var severityColors = map[string]string{
+	severity.Info:     "#0e7490",
+	severity.Warning:  "#b45309",
+	severity.Critical: "#b91c1c",
+}
+
+// Alert is what an alert email shows
+type Alert struct {
+	ID          uuid.UUID
+	Chain       string
+	Address     string
+	Label       string
+	Message     string
+	Severity    string
+	Value       string
+	BlockNumber *int64
+	CreatedAt   time.Time
+}
+
+// Message is a rendered email
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// alertView is the data of the alert templates
+type alertView struct {
+	Theme         config.Email
+	Alert         Alert
+	SeverityColor string
+	// Link is the alert in the app, empty without EMAIL_APP_URL
+	Link string
+}
+
+// verificationView is the data of the email confirmation templates
+type verificationView struct {
+	Theme    config.Email
+	Address  string
+	Link     string
+	ValidFor string
+}
+
+// impersonationView is the data of the impersonation notice templates
+type impersonationView struct {
+	Theme     config.Email
+	Operator  string
+	Reason    string
+	ExpiresAt string
+}
+
+// Render renders the email of an alert with theme
+func Render(theme config.Email, alert Alert) (Message, error) {
+	if !severity.Valid(alert.Severity) {
+		alert.Severity = severity.Default
+	}
+	v := alertView{Theme: theme, Alert: alert, SeverityColor: severityColors[alert.Severity]}
+	if theme.AppURL != "" {
+		v.Link = strings.TrimSuffix(theme.AppURL, "/") + "/alerts/" + alert.ID.String()
+	}
+
+	return render(severity.Subject(alert.Severity, alert.Message), alertHTML, "alert.html", "alert.txt", v)
+}
+
+// RenderVerification renders the email confirming address, whose link stays valid for validFor
+func RenderVerification(theme config.Email, address, link string, validFor time.Duration) (Message, error) {
+	v := verificationView{Theme: theme, Address: address, Link: link, ValidFor: validFor.String()}
+	if validFor%time.Hour == 0 {
+		v.ValidFor = fmt.Sprintf("%d hours", int(validFor.Hours()))
+	}
+	return render("Confirm your email address for "+theme.BrandName, verificationHTML, "verify_email.html", "verify_email.txt", v)
+}
+
+// RenderImpersonation renders the notice that the support operator was given access to the user's
+// account until expiresAt, for reason
+func RenderImpersonation(theme config.Email, operator, reason string, expiresAt time.Time) (Message, error) {
+	v := impersonationView{
+		Theme:     theme,
+		Operator:  operator,
+		Reason:    reason,
+		ExpiresAt: expiresAt.UTC().Format("2006-01-02 15:04 UTC"),
+	}
+	return render("Support accessed your "+theme.BrandName+" account", impersonationHTML, "impersonation.html", "impersonation.txt", v)
+}
+
+// render executes the HTML template name of html and the text template text with v
+func render(subject string, html *htmltemplate.Template, name, text string, v any) (Message, error) {
+	var htmlBody, textBody bytes.Buffer
+	if err := html.ExecuteTemplate(&htmlBody, name, v); err != nil {
+		return Message{}, fmt.Errorf("failed to render the HTML email: %w", err)
+	}
+	if err := textTemplates.ExecuteTemplate(&textBody, text, v); err != nil {
+		return Message{}, fmt.Errorf("failed to render the text email: %w", err)
+	}
+
+	return Message{Subject: subject, HTML: htmlBody.String(), Text: textBody.String()}, nil
+}
+
+// Sample is an alert of severity s to preview the theme with
+func Sample(s string) Alert {
+	block := int64(19_000_000)
+	return Alert{
+		ID:          uuid.MustParse("00000000-0000-4000-8000-000000000000"),
+		Chain:       "ethereum",
+		Address:     "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045",
+		Label:       "Treasury",
+		Message:     "Outgoing transfer of 12.5 ETH",
+		Severity:    s,
+		Value:       "12.5",
+		BlockNumber: &block,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+}
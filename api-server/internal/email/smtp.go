@@ -0,0 +1,101 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+)
+
+// ErrNotConfigured is returned by SMTPSender while SMTP_HOST is not set
+var ErrNotConfigured = errors.New("SMTP_HOST is not set")
+
+// Sender sends rendered emails
+type Sender interface {
+	Send(ctx context.Context, to string, msg Message) error
+}
+
+// SMTPSender sends emails through the SMTP_* server, read on every send so reloaded credentials
+// take effect at once
+type SMTPSender struct{}
+
+func NewSMTPSender() *SMTPSender {
+	return &SMTPSender{}
+}
+
+// Send sends msg to the address to as a multipart/alternative email of its text and HTML. net/smtp
+// takes no context, so ctx only stops a send that has not started.
+func (s *SMTPSender) Send(ctx context.Context, to string, msg Message) error {
+	cfg := config.GetConfig().SMTP
+	if cfg.Host == "" {
+		return ErrNotConfigured
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM %q: %w", cfg.From, err)
+	}
+	body, err := compose(from, to, msg)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	if err := smtp.SendMail(addr, auth, from.Address, []string{to}, body); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	return nil
+}
+
+// compose encodes msg as a MIME message. Clients show the last part they can, so the text comes
+// first.
+func compose(from *mail.Address, to string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n"+
+		"Content-Type: multipart/alternative; boundary=%s\r\n\r\n",
+		from.String(), to, mime.QEncoding.Encode("utf-8", msg.Subject), time.Now().Format(time.RFC1123Z), parts.Boundary())
+	buf.WriteString(header)
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
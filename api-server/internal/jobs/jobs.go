@@ -0,0 +1,252 @@
+// Package jobs runs background work enqueued by the API, such as exports, imports and account
+// purges, on a durable queue in the jobs table. Every api-server instance runs JOBS_WORKERS
+// workers, which claim due jobs with FOR UPDATE SKIP LOCKED so no job runs twice at once.
+//
+// A claimed job is leased to its worker for JOBS_LEASE and the lease is renewed while the job
+// runs; when a worker stops, another one takes the job over once the lease expires. A failed
+// attempt is retried with exponential backoff until JOBS_MAX_ATTEMPTS, unless the handler marks
+// the error Permanent. Handlers must therefore be idempotent.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/tenant"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUnknownKind is returned by Enqueue for a kind no handler was registered for
+var ErrUnknownKind = errors.New("unknown job kind")
+
+// Handler runs a job with its JSON payload, returning a result stored as JSON with the job. The
+// job of a user runs under a context acting for that user, see package tenant.
+type Handler func(ctx context.Context, payload json.RawMessage) (any, error)
+
+// permanentError is an error retrying cannot fix
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// Permanent marks err as one retrying cannot fix, so the job fails without further attempts
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// Queue enqueues jobs and runs them with its registered handlers
+type Queue struct {
+	repo     postgres.IJobInterface
+	cfg      config.Jobs
+	worker   string
+	handlers map[string]Handler
+	metrics  counters
+}
+
+func New(repo postgres.IJobInterface, cfg config.Jobs) *Queue {
+	host, _ := os.Hostname()
+	return &Queue{
+		repo:     repo,
+		cfg:      cfg,
+		worker:   fmt.Sprintf("%s:%d", host, os.Getpid()),
+		handlers: map[string]Handler{},
+		metrics:  counters{kinds: map[string]*kindCounters{}},
+	}
+}
+
+// Register sets the handler of jobs of kind. Handlers are registered before Run.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// Enqueue stores a job of kind with payload as JSON, due immediately. userID is the user the job
+// runs for, uuid.Nil for a system job.
+func (q *Queue) Enqueue(ctx context.Context, userID uuid.UUID, kind string, payload any) (sqlc.Job, error) {
+	if _, ok := q.handlers[kind]; !ok {
+		return sqlc.Job{}, fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return sqlc.Job{}, fmt.Errorf("failed to encode %s job: %w", kind, err)
+	}
+
+	job, err := q.repo.EnqueueJob(ctx, sqlc.EnqueueJobParams{
+		ID:          uuid.New(),
+		UserID:      utils.ToPgUUID(userID),
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: int32(q.cfg.MaxAttempts),
+		RunAt:       utils.ToPgTime(time.Now()),
+	})
+	if err != nil {
+		return sqlc.Job{}, err
+	}
+	q.metrics.kind(kind).enqueued.Add(1)
+	return job, nil
+}
+
+// Run runs JOBS_WORKERS workers until ctx is done, returning once their jobs were stopped
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range q.cfg.Workers {
+		wg.Go(func() { q.work(ctx) })
+	}
+	wg.Wait()
+}
+
+// work runs due jobs one after the other, polling every JOBS_POLL_INTERVAL while none is due
+func (q *Queue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := q.RunNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to run job: %v", err)
+		}
+		if ran {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+// RunNext claims the next due job and runs it, reporting false when no job was due. Jobs whose
+// lease expired on their last attempt are failed first.
+func (q *Queue) RunNext(ctx context.Context) (bool, error) {
+	if n, err := q.repo.FailAbandonedJobs(ctx); err != nil {
+		return false, fmt.Errorf("failed to fail abandoned jobs: %w", err)
+	} else if n > 0 {
+		log.Printf("Failed %d jobs abandoned by their workers", n)
+		q.metrics.abandoned.Add(n)
+	}
+
+	job, err := q.repo.ClaimJob(ctx, q.worker, time.Now().Add(q.cfg.Lease))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return true, q.run(ctx, job)
+}
+
+// run runs the claimed job, renewing its lease meanwhile, and records the outcome
+func (q *Queue) run(ctx context.Context, job sqlc.Job) error {
+	counters := q.metrics.kind(job.Kind)
+	counters.running.Add(1)
+	defer counters.running.Add(-1)
+
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return q.fail(ctx, job, Permanent(fmt.Errorf("%w %q", ErrUnknownKind, job.Kind)))
+	}
+
+	// The lease is renewed for no user: purging the job's user clears its user_id
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go q.renewLease(jobCtx, cancel, job.ID)
+	if job.UserID.Valid {
+		jobCtx = tenant.WithUser(jobCtx, job.UserID.Bytes)
+	}
+
+	start := time.Now()
+	result, err := handler(jobCtx, job.Payload)
+	counters.observe(time.Since(start))
+	if err == nil {
+		var data []byte
+		if data, err = json.Marshal(result); err == nil {
+			// Recorded even when ctx is done, the job is finished
+			err = q.repo.CompleteJob(context.WithoutCancel(ctx), job.ID, q.worker, data)
+			if err == nil {
+				counters.succeeded.Add(1)
+				return nil
+			}
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%s job %s was taken over by another worker", job.Kind, job.ID)
+			}
+			return fmt.Errorf("failed to complete %s job %s: %w", job.Kind, job.ID, err)
+		}
+		err = Permanent(fmt.Errorf("failed to encode result: %w", err))
+	}
+	return q.fail(ctx, job, err)
+}
+
+// fail records the failed attempt of job, retrying it unless cause is permanent or the attempts
+// are exhausted. An attempt interrupted by shutdown is retried right away by the next worker.
+func (q *Queue) fail(ctx context.Context, job sqlc.Job, cause error) error {
+	counters := q.metrics.kind(job.Kind)
+	var retryAt time.Time
+	var permanent permanentError
+	switch {
+	case ctx.Err() != nil:
+		retryAt = time.Now()
+	case errors.As(cause, &permanent) || job.Attempts >= job.MaxAttempts:
+	default:
+		retryAt = time.Now().Add(backoff(q.cfg.RetryDelay, q.cfg.MaxRetryDelay, int(job.Attempts)-1))
+	}
+
+	err := q.repo.FailJob(context.WithoutCancel(ctx), job.ID, q.worker, cause, retryAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s job %s was taken over by another worker", job.Kind, job.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record failure of %s job %s: %w", job.Kind, job.ID, err)
+	}
+	if retryAt.IsZero() {
+		counters.failed.Add(1)
+		return fmt.Errorf("%s job %s failed after %d attempts: %w", job.Kind, job.ID, job.Attempts, cause)
+	}
+	counters.retried.Add(1)
+	if ctx.Err() == nil {
+		log.Printf("Attempt %d of %s job %s failed, retrying at %s: %v",
+			job.Attempts, job.Kind, job.ID, retryAt.Format(time.RFC3339), cause)
+	}
+	return nil
+}
+
+// renewLease extends the lease of job id every third of JOBS_LEASE until ctx is done, and cancels
+// the job when it was taken over by another worker
+func (q *Queue) renewLease(ctx context.Context, cancel context.CancelFunc, id uuid.UUID) {
+	ticker := time.NewTicker(q.cfg.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := q.repo.ExtendLease(ctx, id, q.worker, time.Now().Add(q.cfg.Lease))
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Job %s was taken over by another worker, stopping it", id)
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to renew the lease of job %s: %v", id, err)
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt (0 based): base * 2^attempt capped at max,
+// jittered over [d/2, d] so jobs failing together are not retried in lockstep
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	d := max
+	if attempt < 32 && base<<attempt > 0 && base<<attempt < max {
+		d = base << attempt
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
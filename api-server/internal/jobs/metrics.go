@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// counters are the in-process job counters of a queue
+type counters struct {
+	mu        sync.Mutex
+	kinds     map[string]*kindCounters
+	abandoned atomic.Int64
+}
+
+// kindCounters count the jobs of one kind enqueued and run by this instance
+type kindCounters struct {
+	enqueued  atomic.Int64
+	running   atomic.Int64
+	succeeded atomic.Int64
+	retried   atomic.Int64
+	failed    atomic.Int64
+	attempts  atomic.Int64
+	runTime   atomic.Int64 // nanoseconds
+}
+
+func (c *counters) kind(kind string) *kindCounters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k, ok := c.kinds[kind]
+	if !ok {
+		k = &kindCounters{}
+		c.kinds[kind] = k
+	}
+	return k
+}
+
+// observe counts an attempt that ran for d
+func (k *kindCounters) observe(d time.Duration) {
+	k.attempts.Add(1)
+	k.runTime.Add(int64(d))
+}
+
+// Metrics are the job counters of the queue, shared by all instances, and of this instance's
+// workers
+type Metrics struct {
+	// Jobs counts the jobs of every kind by state, across all instances
+	Jobs map[string]map[string]int64 `json:"jobs"`
+	// Workers are the counters of this instance since it started, by kind
+	Workers   map[string]KindMetrics `json:"workers"`
+	Abandoned int64                  `json:"abandoned"`
+}
+
+type KindMetrics struct {
+	Enqueued     int64   `json:"enqueued"`
+	Running      int64   `json:"running"`
+	Succeeded    int64   `json:"succeeded"`
+	Retried      int64   `json:"retried"`
+	Failed       int64   `json:"failed"`
+	AvgRunTimeMs float64 `json:"avg_run_time_ms"`
+}
+
+// Metrics returns the job counts of the queue and the counters of this instance's workers
+func (q *Queue) Metrics(ctx context.Context) (Metrics, error) {
+	m := Metrics{
+		Jobs:      map[string]map[string]int64{},
+		Workers:   map[string]KindMetrics{},
+		Abandoned: q.metrics.abandoned.Load(),
+	}
+
+	q.metrics.mu.Lock()
+	for kind, k := range q.metrics.kinds {
+		km := KindMetrics{
+			Enqueued:  k.enqueued.Load(),
+			Running:   k.running.Load(),
+			Succeeded: k.succeeded.Load(),
+			Retried:   k.retried.Load(),
+			Failed:    k.failed.Load(),
+		}
+		if attempts := k.attempts.Load(); attempts > 0 {
+			km.AvgRunTimeMs = float64(k.runTime.Load()) / float64(attempts) / float64(time.Millisecond)
+		}
+		m.Workers[kind] = km
+	}
+	q.metrics.mu.Unlock()
+
+	rows, err := q.repo.CountJobs(ctx)
+	if err != nil {
+		return m, err
+	}
+	for _, row := range rows {
+		if m.Jobs[row.Kind] == nil {
+			m.Jobs[row.Kind] = map[string]int64{}
+		}
+		m.Jobs[row.Kind][row.Status] = row.Count
+	}
+	return m, nil
+}
@@ -0,0 +1,304 @@
+// Package live pushes the live activity of the users' watched addresses to their WebSocket
+// connections at /ws/activity. The engine publishes each matched transfer with pg_notify, see
+// package liveactivity of the shared module, and the Hub LISTENs on a connection of its own and
+// forwards every event to the connections of its user.
+//
+// When a session is signed out the hub sends a session.revoked event to its connections and closes
+// them with 1008 (policy violation). The revocation goes through the same channel, so it reaches
+// the connections on every api-server replica.
+//
+// Delivery is best effort. Events published while the hub is reconnecting to the database are
+// lost, and a connection that cannot keep up is closed with 1013 (try again later). Clients
+// catch up on anything missed from the address's activity feed.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/liveactivity"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// MaxConnections bounds the connections of a user, e.g. a few browser tabs and a bot
+	MaxConnections = 5
+	// sendBuffer bounds the events waiting to be written to a connection
+	sendBuffer = 64
+	// reconnectDelay is how long the hub waits before listening again after losing its connection
+	reconnectDelay = 5 * time.Second
+)
+
+// ErrTooManyConnections ends the connections of a user with MaxConnections open already
+var ErrTooManyConnections = errors.New("too many live activity connections")
+
+// Hub forwards the live activity to the connections of its users
+//
+// Example usage:
+//
+//	hub := live.NewHub(pool)
+//	go hub.Run(ctx)
+//	...
+//	app.Get("/ws/activity", websocket.New(func(conn *websocket.Conn) { hub.Serve(conn, userID, sessionID) }))
+type Hub struct {
+	pool *pgxpool.Pool
+
+	mu          sync.Mutex
+	subscribers map[string]map[*subscription]struct{} // user ID -> subscriptions
+	closed      bool
+}
+
+// subscription is a connection's subscription to the events of its user
+type subscription struct {
+	userID string
+	// sessionID is the session the connection was authenticated with, empty for API keys
+	sessionID string
+	// events are the encoded events, as written to the connection
+	events chan []byte
+	// done is closed when the subscription is dropped, with reason the close code to end the
+	// connection with, after writing farewell if set
+	done     chan struct{}
+	reason   int
+	farewell []byte
+}
+
+// NewHub creates a Hub listening on a connection of pool
+func NewHub(pool *pgxpool.Pool) *Hub {
+	return &Hub{
+		pool:        pool,
+		subscribers: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// Connections returns the number of open connections of userID
+func (h *Hub) Connections(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[userID])
+}
+
+// Serve pushes the events of userID to conn, pinging it meanwhile, until either side closes it or
+// the session sessionID is revoked
+func (h *Hub) Serve(conn *websocket.Conn, userID, sessionID string) {
+	sub, err := h.subscribe(userID, sessionID)
+	if err != nil {
+		closeConn(conn, websocket.CloseTryAgainLater, err.Error())
+		return
+	}
+	defer h.unsubscribe(sub)
+
+	// Clients send nothing but pongs and close frames, which ReadMessage handles. Every frame
+	// read, the pongs included, shows the client is still there.
+	conn.SetReadLimit(maxClientMessage)
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			conn.SetReadDeadline(time.Now().Add(readTimeout))
+		}
+	}()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case event := <-sub.events:
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, event); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case <-sub.done:
+			if sub.farewell != nil {
+				conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+				conn.WriteMessage(websocket.TextMessage, sub.farewell)
+			}
+			closeConn(conn, sub.reason, "")
+			return
+		case <-gone:
+			return
+		}
+	}
+}
+
+// subscribe subscribes a connection of userID in sessionID to their events
+func (h *Hub) subscribe(userID, sessionID string) (*subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, errors.New("live activity is shutting down")
+	}
+	if len(h.subscribers[userID]) >= MaxConnections {
+		return nil, ErrTooManyConnections
+	}
+
+	sub := &subscription{
+		userID:    userID,
+		sessionID: sessionID,
+		events:    make(chan []byte, sendBuffer),
+		done:      make(chan struct{}),
+	}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	return sub, nil
+}
+
+// unsubscribe removes sub once its connection is gone
+func (h *Hub) unsubscribe(sub *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub, websocket.CloseNormalClosure)
+}
+
+// drop removes sub and closes its done channel, under the lock
+func (h *Hub) drop(sub *subscription, reason int) {
+	subs, ok := h.subscribers[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.userID)
+	}
+	sub.reason = reason
+	close(sub.done)
+}
+
+// Run listens for the live activity until ctx is done, listening again after a delay when the
+// connection is lost. The subscriptions are dropped with 1001 (going away) when it returns.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.shutdown()
+	for ctx.Err() == nil {
+		if err := h.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Lost the live activity connection, listening again in %s: %v", reconnectDelay, err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// listen holds a connection LISTENing on the live activity channel and forwards its notifications
+func (h *Hub) listen(ctx context.Context) error {
+	conn, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection stays in LISTEN mode, so it is taken out of the pool and closed after
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.WithoutCancel(ctx))
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+liveactivity.Channel); err != nil {
+		return err
+	}
+	for {
+		n, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var msg liveactivity.Message
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+			log.Printf("Skipping malformed live activity: %v", err)
+			continue
+		}
+		if msg.Revoked != nil {
+			event, err := json.Marshal(msg.Revoked)
+			if err != nil {
+				continue
+			}
+			h.revoke(msg.UserID, msg.Revoked.SessionID, event)
+			continue
+		}
+		event, err := json.Marshal(msg.Event)
+		if err != nil {
+			continue
+		}
+		h.publish(msg.UserID, event)
+	}
+}
+
+// publish queues event on every connection of userID, dropping those whose queue is full
+func (h *Hub) publish(userID string, event []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("Closing a live activity connection of user %s, too slow to keep up", userID)
+			h.drop(sub, websocket.CloseTryAgainLater)
+		}
+	}
+}
+
+// RevokeSessions publishes a session.revoked event for each of sessionIDs of userID, whose
+// connections on every api-server replica receive it and are closed. Failures are logged, the
+// connections then stay open until the user's token expires or is next checked.
+func (h *Hub) RevokeSessions(ctx context.Context, userID uuid.UUID, sessionIDs []uuid.UUID) {
+	now := time.Now().UTC()
+	for _, id := range sessionIDs {
+		payload, err := json.Marshal(liveactivity.Message{
+			UserID: userID.String(),
+			Revoked: &liveactivity.SessionRevoked{
+				Type:      liveactivity.EventSessionRevoked,
+				SessionID: id.String(),
+				RevokedAt: now,
+			},
+		})
+		if err == nil {
+			_, err = h.pool.Exec(ctx, "SELECT pg_notify($1, $2)", liveactivity.Channel, string(payload))
+		}
+		if err != nil {
+			log.Printf("Failed to publish the revocation of session %s: %v", id, err)
+		}
+	}
+}
+
+// revoke sends event to the connections of the session sessionID of userID and drops them
+func (h *Hub) revoke(userID, sessionID string, event []byte) {
+	if sessionID == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers[userID] {
+		if sub.sessionID == sessionID {
+			sub.farewell = event
+			h.drop(sub, websocket.ClosePolicyViolation)
+		}
+	}
+}
+
+// shutdown drops every subscription, rejecting new ones
+func (h *Hub) shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.drop(sub, websocket.CloseGoingAway)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package live
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// maxControlLength bounds the payload of ping, pong and close frames
+	maxControlLength = 125
+	// maxClientMessage bounds the messages read from clients, who have nothing to send but pings
+	maxClientMessage = 4 << 10
+	// writeTimeout bounds each frame written to a client
+	writeTimeout = 10 * time.Second
+	// pingInterval is how often clients are pinged. A client that sent nothing, not even the pong,
+	// for readTimeout is gone.
+	pingInterval = 30 * time.Second
+	readTimeout  = 2*pingInterval + writeTimeout
+)
+
+// IsWebSocket reports whether c is a WebSocket handshake
+func IsWebSocket(c *fiber.Ctx) bool {
+	return websocket.IsWebSocketUpgrade(c)
+}
+
+// OriginAllowed reports whether the WebSocket handshake c may connect from its Origin. Browsers
+// send the page's origin with every handshake, which must be in allowed, or allowed holds "*", so
+// another site cannot open connections with the credentials of its visitors. Handshakes without
+// an Origin do not come from a browser and are allowed.
+func OriginAllowed(c *fiber.Ctx, allowed []string) bool {
+	origin := c.Get(fiber.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	if slices.Contains(allowed, "*") {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	return slices.ContainsFunc(allowed, func(a string) bool {
+		return strings.EqualFold(strings.TrimSuffix(a, "/"), u.Scheme+"://"+u.Host)
+	})
+}
+
+// closeConn sends a close frame with code and reason, after which nothing else is written. The
+// connection itself is closed when the handler given to websocket.New returns.
+func closeConn(conn *websocket.Conn, code int, reason string) error {
+	if len(reason) > maxControlLength-2 {
+		reason = reason[:maxControlLength-2]
+	}
+	return conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeTimeout))
+}
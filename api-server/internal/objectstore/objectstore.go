@@ -0,0 +1,151 @@
+// Package objectstore stores archive objects in S3, in Google Cloud Storage through its
+// S3-compatible API, or in a local directory for development.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrNotFound is returned by Get for a missing object
+var ErrNotFound = errors.New("object not found")
+
+// gcsEndpoint is the S3-compatible endpoint of Google Cloud Storage
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// Store reads and writes objects by key, relative to the store's prefix
+type Store interface {
+	// Put writes the object key, replacing an existing one
+	Put(ctx context.Context, key string, body io.ReadSeeker) error
+	// Get opens the object key, failing with ErrNotFound when it does not exist
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Open returns the store of rawURL: s3://bucket/prefix, gs://bucket/prefix or file:///dir.
+// endpoint overrides the S3 endpoint, e.g. for MinIO, and region the AWS region. S3 and GCS
+// credentials come from the usual AWS sources (environment, shared files, instance role).
+func Open(ctx context.Context, rawURL, endpoint, region string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object store URL: %w", err)
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "file":
+		if err := os.MkdirAll(u.Path, 0o755); err != nil {
+			return nil, err
+		}
+		return &dirStore{dir: u.Path}, nil
+	case "s3", "gs":
+		if u.Scheme == "gs" {
+			if endpoint == "" {
+				endpoint = gcsEndpoint
+			}
+			if region == "" {
+				region = "auto"
+			}
+		}
+		var opts []func(*awsconfig.LoadOptions) error
+		if region != "" {
+			opts = append(opts, awsconfig.WithRegion(region))
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+			if endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+				o.UsePathStyle = true
+			}
+		})
+		return &s3Store{client: client, bucket: u.Host, prefix: prefix}, nil
+	}
+	return nil, fmt.Errorf("unsupported object store URL scheme %q", u.Scheme)
+}
+
+type s3Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func (s *s3Store) key(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.ReadSeeker) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+		Body:   body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	var missing *types.NoSuchKey
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return out.Body, nil
+}
+
+// dirStore keeps objects as files under dir, for development
+type dirStore struct {
+	dir string
+}
+
+func (s *dirStore) Put(ctx context.Context, key string, body io.ReadSeeker) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Written aside and renamed, so a reader never sees a partial object
+	f, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s *dirStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return f, err
+}
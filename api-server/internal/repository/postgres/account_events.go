@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of account events. Changes of webhooks and API keys, revoked sessions and email
+// verifications are recorded by their repositories with the change, impersonations by the impersonation service and the
+// audit middleware, the others by the user service.
+const (
+	EventAccountCreated  = "account.created"
+	EventLogin           = "login"
+	EventLoginFailed     = "login.failed"
+	EventProfileUpdated  = "profile.updated"
+	EventPasswordChanged = "password.changed"
+	EventEmailVerified   = "email.verified"
+	EventWebhookCreated  = "webhook.created"
+	EventWebhookUpdated  = "webhook.updated"
+	EventWebhookDeleted  = "webhook.deleted"
+	EventWebhookVerified = "webhook.verified"
+	EventAPIKeyCreated   = "api_key.created"
+	EventAPIKeyRevoked   = "api_key.revoked"
+	EventAPIKeyUpdated   = "api_key.updated"
+	EventSessionRevoked  = "session.revoked"
+	// Support staff were given a token acting as the user, and the requests made with it
+	EventImpersonationStarted = "impersonation.started"
+	EventImpersonatedRequest  = "impersonation.request"
+)
+
+// AccountEvent is an entry of a user's audit log, e.g. a sign-in with the client it came from
+type AccountEvent struct {
+	UserID    uuid.UUID
+	Kind      string
+	Detail    map[string]any
+	IPAddress string
+	UserAgent string
+}
+
+// IAccountEventInterface is the audit log of the users' accounts
+type IAccountEventInterface interface {
+	RecordEvent(ctx context.Context, event AccountEvent) error
+	// ListAccountEvents returns a page of the user's account events, newest first
+	ListAccountEvents(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.AccountEvent], error)
+}
+
+type AccountEventRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAccountEventRepository(db sqlc.DBTX) IAccountEventInterface {
+	return &AccountEventRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *AccountEventRepo) RecordEvent(ctx context.Context, event AccountEvent) error {
+	detail := []byte("{}")
+	if len(event.Detail) > 0 {
+		var err error
+		if detail, err = json.Marshal(event.Detail); err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", event.Kind, err)
+		}
+	}
+
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateAccountEvent(ctx, sqlc.CreateAccountEventParams{
+		UserID:    event.UserID,
+		Kind:      event.Kind,
+		Detail:    detail,
+		IpAddress: optionalText(event.IPAddress),
+		UserAgent: optionalText(event.UserAgent),
+	})
+}
+
+func (r *AccountEventRepo) ListAccountEvents(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.AccountEvent], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.AccountEvent]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListAccountEvents(ctx, sqlc.ListAccountEventsParams{
+		UserID:         userID,
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.AccountEvent]{}, err
+	}
+
+	return NewPage(q, rows, AccountEventKey), nil
+}
+
+// optionalText is s, NULL when empty
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
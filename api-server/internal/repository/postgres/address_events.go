@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+)
+
+// Kinds of address events. They are recorded by the address and rule repositories with the change
+// they describe, the events of a rule without an address apply to every address of its user.
+const (
+	EventAddressCreated   = "address.created"
+	EventAddressUpdated   = "address.updated"
+	EventAddressDeleted   = "address.deleted"
+	EventAddressMuted     = "address.muted"
+	EventAddressUnmuted   = "address.unmuted"
+	EventRuleCreated      = "rule.created"
+	EventRuleUpdated      = "rule.updated"
+	EventRulePaused       = "rule.paused"
+	EventRuleResumed      = "rule.resumed"
+	EventRuleDeleted      = "rule.deleted"
+	EventRuleMuted        = "rule.muted"
+	EventRuleUnmuted      = "rule.unmuted"
+	EventBalanceWatched   = "balance.watched"
+	EventBalanceUnwatched = "balance.unwatched"
+)
+
+// IAddressEventInterface is the repository of the changes of addresses and their rules
+type IAddressEventInterface interface {
+	// ListAddressEvents returns a page of the events of the address and, since it was added, of
+	// the rules without an address, newest first
+	ListAddressEvents(ctx context.Context, addressID, userID uuid.UUID, page PageRequest) (Page[sqlc.AddressEvent], error)
+	// ListUserAddressEvents returns a page of the events of kinds of all the user's addresses and
+	// rules, newest first
+	ListUserAddressEvents(ctx context.Context, userID uuid.UUID, kinds []string, page PageRequest) (Page[sqlc.AddressEvent], error)
+}
+
+type AddressEventRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAddressEventRepository(db sqlc.DBTX) IAddressEventInterface {
+	return &AddressEventRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *AddressEventRepo) ListAddressEvents(ctx context.Context, addressID, userID uuid.UUID, page PageRequest) (Page[sqlc.AddressEvent], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.AddressEvent]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListAddressEvents(ctx, sqlc.ListAddressEventsParams{
+		UserID:         userID,
+		AddressID:      addressID,
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.AddressEvent]{}, err
+	}
+
+	return NewPage(q, rows, AddressEventKey), nil
+}
+
+func (r *AddressEventRepo) ListUserAddressEvents(ctx context.Context, userID uuid.UUID, kinds []string, page PageRequest) (Page[sqlc.AddressEvent], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.AddressEvent]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListUserAddressEvents(ctx, sqlc.ListUserAddressEventsParams{
+		UserID:         userID,
+		Kinds:          kinds,
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.AddressEvent]{}, err
+	}
+
+	return NewPage(q, rows, AddressEventKey), nil
+}
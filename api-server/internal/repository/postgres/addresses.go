@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IAddressInterface is the watched addresses repository. Methods taking a user ID only see that
+// user's addresses; updating or deleting another user's address fails with pgx.ErrNoRows. Deleted
+// addresses are kept with deleted_at set and hidden, see IncludeDeleted.
+type IAddressInterface interface {
+	CreateAddress(ctx context.Context, address sqlc.CreateAddressParams) (uuid.UUID, error)
+	GetAddress(ctx context.Context, id, userID uuid.UUID) (*sqlc.Address, error)
+	// ListAddresses returns a page of the user's addresses, oldest first
+	ListAddresses(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.Address], error)
+	ListWatchers(ctx context.Context, chain, address string) ([]sqlc.Address, error)
+	// SearchAddresses returns up to limit of the user's addresses whose label, notes or known entity
+	// name match every term as a prefix, best match first
+	SearchAddresses(ctx context.Context, userID uuid.UUID, terms []string, limit int32) ([]sqlc.SearchAddressesRow, error)
+	// UpdateDetails fails with ErrStaleVersion when the address was changed since version
+	UpdateDetails(ctx context.Context, id, userID uuid.UUID, label, notes pgtype.Text, version int32) error
+	// DeleteAddress soft-deletes the address and the rules that apply only to it
+	DeleteAddress(ctx context.Context, id, userID uuid.UUID) error
+	// MuteAddress silences the notifications of the address's alerts until until, or until it is
+	// unmuted when until is not valid
+	MuteAddress(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error
+	UnmuteAddress(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type AddressRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAddressRepository(db sqlc.DBTX) IAddressInterface {
+	return &AddressRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *AddressRepo) CreateAddress(ctx context.Context, address sqlc.CreateAddressParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateAddress(ctx, address)
+}
+
+func (r *AddressRepo) GetAddress(ctx context.Context, id, userID uuid.UUID) (*sqlc.Address, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	address, err := r.db.GetAddress(ctx, sqlc.GetAddressParams{
+		ID:             id,
+		UserID:         userID,
+		IncludeDeleted: IsIncludingDeleted(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (r *AddressRepo) ListAddresses(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.Address], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.Address]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListAddressesByUser(ctx, sqlc.ListAddressesByUserParams{
+		UserID:         userID,
+		IncludeDeleted: IsIncludingDeleted(ctx),
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.Address]{}, err
+	}
+
+	return NewPage(q, rows, AddressKey), nil
+}
+
+// ListWatchers returns every user's entry for address on chain, reading from the primary so an
+// address is matched as soon as it is added
+func (r *AddressRepo) ListWatchers(ctx context.Context, chain, address string) ([]sqlc.Address, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ListAddressesByChainAddress(ctx, sqlc.ListAddressesByChainAddressParams{
+		Chain:   chain,
+		Address: address,
+	})
+}
+
+func (r *AddressRepo) SearchAddresses(ctx context.Context, userID uuid.UUID, terms []string, limit int32) ([]sqlc.SearchAddressesRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.SearchAddresses(ctx, sqlc.SearchAddressesParams{
+		Query:      prefixQuery(terms),
+		UserID:     userID,
+		MaxResults: limit,
+	})
+}
+
+func (r *AddressRepo) UpdateDetails(ctx context.Context, id, userID uuid.UUID, label, notes pgtype.Text, version int32) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	err := expectRow(r.db.UpdateAddressDetails(ctx, sqlc.UpdateAddressDetailsParams{
+		ID:      id,
+		UserID:  userID,
+		Label:   label,
+		Notes:   notes,
+		Version: version,
+	}))
+	return CheckVersion(err, func() error {
+		_, err := r.db.GetAddress(ctx, sqlc.GetAddressParams{ID: id, UserID: userID})
+		return err
+	})
+}
+
+func (r *AddressRepo) DeleteAddress(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SoftDeleteAddress(ctx, sqlc.SoftDeleteAddressParams{ID: id, UserID: userID}))
+}
+
+func (r *AddressRepo) MuteAddress(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.MuteAddress(ctx, sqlc.MuteAddressParams{ID: id, UserID: userID, MutedUntil: until}))
+}
+
+func (r *AddressRepo) UnmuteAddress(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.UnmuteAddress(ctx, sqlc.UnmuteAddressParams{ID: id, UserID: userID}))
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IAlertRuleInterface is the alert rules repository. Methods taking a user ID only see that user's
+// rules; updating or deleting another user's rule fails with pgx.ErrNoRows. Deleted rules are kept
+// with deleted_at set and hidden, see IncludeDeleted; they never raise alerts.
+type IAlertRuleInterface interface {
+	CreateRule(ctx context.Context, rule sqlc.CreateAlertRuleParams) (uuid.UUID, error)
+	GetRule(ctx context.Context, id, userID uuid.UUID) (*sqlc.AlertRule, error)
+	ListRules(ctx context.Context, userID uuid.UUID) ([]sqlc.AlertRule, error)
+	// ListEnabledRules returns the enabled rules of the user that apply to the address: its own
+	// and those without an address
+	ListEnabledRules(ctx context.Context, userID, addressID uuid.UUID) ([]sqlc.AlertRule, error)
+	// UpdateRule fails with ErrStaleVersion when the rule was changed since rule.Version
+	UpdateRule(ctx context.Context, rule sqlc.UpdateAlertRuleParams) error
+	DeleteRule(ctx context.Context, id, userID uuid.UUID) error
+	// MuteRule silences the notifications of the rule's alerts, like IAddressInterface.MuteAddress
+	MuteRule(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error
+	UnmuteRule(ctx context.Context, id, userID uuid.UUID) error
+	// SetTokenPreset filters the rule's transfers to the contracts of a token preset, see package
+	// tokens, or stops filtering them when preset is NULL. Rules filtering on a token_address are
+	// left as they are, failing with pgx.ErrNoRows.
+	SetTokenPreset(ctx context.Context, id, userID uuid.UUID, preset pgtype.Text) error
+	// SetExpression makes the rule an advanced rule whose condition is the CEL expression, see
+	// package ruleexpr, or a simple rule again when expression is NULL. The expression is stored
+	// as given, callers compile it first.
+	SetExpression(ctx context.Context, id, userID uuid.UUID, expression pgtype.Text) error
+	// SetPriority sets the order the rule is evaluated in among the rules of a transaction, higher
+	// first, and whether matching it suppresses the rules after it
+	SetPriority(ctx context.Context, id, userID uuid.UUID, priority int32, stopProcessing bool) error
+	// SetCounterparties limits the transfers of a simple rule to those from or to one of
+	// addresses, as seen from the watched address, or lifts the limit when addresses is empty
+	SetCounterparties(ctx context.Context, id, userID uuid.UUID, addresses []string) error
+	// ListCounterparties returns the counterparties of the user's rules ruleIDs by rule ID. Rules
+	// without any are left out.
+	ListCounterparties(ctx context.Context, userID uuid.UUID, ruleIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
+
+type AlertRuleRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAlertRuleRepository(db sqlc.DBTX) IAlertRuleInterface {
+	return &AlertRuleRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *AlertRuleRepo) CreateRule(ctx context.Context, rule sqlc.CreateAlertRuleParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateAlertRule(ctx, rule)
+}
+
+func (r *AlertRuleRepo) GetRule(ctx context.Context, id, userID uuid.UUID) (*sqlc.AlertRule, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	rule, err := r.db.GetAlertRule(ctx, sqlc.GetAlertRuleParams{
+		ID:             id,
+		UserID:         userID,
+		IncludeDeleted: IsIncludingDeleted(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *AlertRuleRepo) ListRules(ctx context.Context, userID uuid.UUID) ([]sqlc.AlertRule, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListAlertRulesByUser(ctx, sqlc.ListAlertRulesByUserParams{
+		UserID:         userID,
+		IncludeDeleted: IsIncludingDeleted(ctx),
+	})
+}
+
+func (r *AlertRuleRepo) ListEnabledRules(ctx context.Context, userID, addressID uuid.UUID) ([]sqlc.AlertRule, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ListEnabledAlertRulesForAddress(ctx, sqlc.ListEnabledAlertRulesForAddressParams{
+		UserID:    userID,
+		AddressID: utils.ToPgUUID(addressID),
+	})
+}
+
+func (r *AlertRuleRepo) UpdateRule(ctx context.Context, rule sqlc.UpdateAlertRuleParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return CheckVersion(expectRow(r.db.UpdateAlertRule(ctx, rule)), func() error {
+		_, err := r.db.GetAlertRule(ctx, sqlc.GetAlertRuleParams{ID: rule.ID, UserID: rule.UserID})
+		return err
+	})
+}
+
+func (r *AlertRuleRepo) DeleteRule(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SoftDeleteAlertRule(ctx, sqlc.SoftDeleteAlertRuleParams{ID: id, UserID: userID}))
+}
+
+func (r *AlertRuleRepo) MuteRule(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.MuteAlertRule(ctx, sqlc.MuteAlertRuleParams{ID: id, UserID: userID, MutedUntil: until}))
+}
+
+func (r *AlertRuleRepo) UnmuteRule(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.UnmuteAlertRule(ctx, sqlc.UnmuteAlertRuleParams{ID: id, UserID: userID}))
+}
+
+func (r *AlertRuleRepo) SetTokenPreset(ctx context.Context, id, userID uuid.UUID, preset pgtype.Text) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SetAlertRuleTokenPreset(ctx, sqlc.SetAlertRuleTokenPresetParams{
+		ID:          id,
+		UserID:      userID,
+		TokenPreset: preset,
+	}))
+}
+
+func (r *AlertRuleRepo) SetExpression(ctx context.Context, id, userID uuid.UUID, expression pgtype.Text) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SetAlertRuleExpression(ctx, sqlc.SetAlertRuleExpressionParams{
+		ID:         id,
+		UserID:     userID,
+		Expression: expression,
+	}))
+}
+
+func (r *AlertRuleRepo) SetPriority(ctx context.Context, id, userID uuid.UUID, priority int32, stopProcessing bool) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SetAlertRulePriority(ctx, sqlc.SetAlertRulePriorityParams{
+		ID:             id,
+		UserID:         userID,
+		Priority:       priority,
+		StopProcessing: stopProcessing,
+	}))
+}
+
+func (r *AlertRuleRepo) SetCounterparties(ctx context.Context, id, userID uuid.UUID, addresses []string) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	if addresses == nil {
+		// A NULL array would keep the counterparties stored
+		addresses = []string{}
+	}
+	return expectRow(r.db.SetAlertRuleCounterparties(ctx, sqlc.SetAlertRuleCounterpartiesParams{
+		ID:        id,
+		UserID:    userID,
+		Addresses: addresses,
+	}))
+}
+
+func (r *AlertRuleRepo) ListCounterparties(ctx context.Context, userID uuid.UUID, ruleIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListAlertRuleCounterparties(ctx, sqlc.ListAlertRuleCounterpartiesParams{
+		UserID:  userID,
+		RuleIds: ruleIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	counterparties := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		counterparties[row.RuleID] = append(counterparties[row.RuleID], row.Address)
+	}
+	return counterparties, nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+)
+
+// IAlertInterface is the raised alerts repository. Methods taking a user ID only see that user's
+// alerts.
+type IAlertInterface interface {
+	CreateAlert(ctx context.Context, alert sqlc.CreateAlertParams) (uuid.UUID, error)
+	GetAlert(ctx context.Context, id, userID uuid.UUID) (*sqlc.Alert, error)
+	// ListAlerts returns a page of the user's alerts, newest first
+	ListAlerts(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.Alert], error)
+	// ListAddressAlerts returns a page of the alerts raised for one of the user's addresses,
+	// newest first
+	ListAddressAlerts(ctx context.Context, addressID, userID uuid.UUID, page PageRequest) (Page[sqlc.Alert], error)
+	// AcknowledgeAlert fails with pgx.ErrNoRows when the alert does not exist or was acknowledged
+	// already
+	AcknowledgeAlert(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type AlertRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAlertRepository(db sqlc.DBTX) IAlertInterface {
+	return &AlertRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *AlertRepo) CreateAlert(ctx context.Context, alert sqlc.CreateAlertParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateAlert(ctx, alert)
+}
+
+func (r *AlertRepo) GetAlert(ctx context.Context, id, userID uuid.UUID) (*sqlc.Alert, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	alert, err := r.db.GetAlert(ctx, sqlc.GetAlertParams{ID: id, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+func (r *AlertRepo) ListAlerts(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.Alert], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.Alert]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListAlertsByUser(ctx, sqlc.ListAlertsByUserParams{
+		UserID:         userID,
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.Alert]{}, err
+	}
+
+	return NewPage(q, rows, AlertKey), nil
+}
+
+func (r *AlertRepo) ListAddressAlerts(ctx context.Context, addressID, userID uuid.UUID, page PageRequest) (Page[sqlc.Alert], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.Alert]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListAlertsByAddress(ctx, sqlc.ListAlertsByAddressParams{
+		AddressID:      addressID,
+		UserID:         userID,
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.Alert]{}, err
+	}
+
+	return NewPage(q, rows, AlertKey), nil
+}
+
+func (r *AlertRepo) AcknowledgeAlert(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.AcknowledgeAlert(ctx, sqlc.AcknowledgeAlertParams{ID: id, UserID: userID}))
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IAPIKeyInterface is the API keys repository. Keys are looked up by the hash of the presented
+// key; the key itself is never stored. Keys signing their requests are looked up by ID, their
+// secret is stored to verify the signatures.
+type IAPIKeyInterface interface {
+	CreateKey(ctx context.Context, key sqlc.CreateApiKeyParams) (uuid.UUID, error)
+	// GetActiveKey returns the key with keyHash unless it was revoked or has expired
+	GetActiveKey(ctx context.Context, keyHash string) (*sqlc.ApiKey, error)
+	// GetActiveKeyByID returns the key id unless it was revoked or has expired
+	GetActiveKeyByID(ctx context.Context, id uuid.UUID) (*sqlc.ApiKey, error)
+	// UseNonce records the nonce of a signed request of the key until expiresAt, failing with
+	// pgx.ErrNoRows when it was already used
+	UseNonce(ctx context.Context, keyID uuid.UUID, nonce string, expiresAt time.Time) error
+	ListKeys(ctx context.Context, userID uuid.UUID) ([]sqlc.ApiKey, error)
+	TouchKey(ctx context.Context, id uuid.UUID) error
+	// RevokeKey fails with pgx.ErrNoRows when the user has no such active key
+	RevokeKey(ctx context.Context, id, userID uuid.UUID) error
+	// SetAllowedCIDRs replaces the networks the key is accepted from, any when cidrs is empty. It
+	// fails with pgx.ErrNoRows when the user has no such active key.
+	SetAllowedCIDRs(ctx context.Context, id, userID uuid.UUID, cidrs []string) error
+	// SetScopes replaces the key's scopes. It fails with pgx.ErrNoRows when the user has no such
+	// active key.
+	SetScopes(ctx context.Context, id, userID uuid.UUID, scopes []string) error
+}
+
+type APIKeyRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAPIKeyRepository(db sqlc.DBTX) IAPIKeyInterface {
+	return &APIKeyRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *APIKeyRepo) CreateKey(ctx context.Context, key sqlc.CreateApiKeyParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateApiKey(ctx, key)
+}
+
+// GetActiveKey reads from the primary, so a revoked key stops working immediately
+func (r *APIKeyRepo) GetActiveKey(ctx context.Context, keyHash string) (*sqlc.ApiKey, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	key, err := r.db.GetActiveApiKeyByHash(ctx, keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) GetActiveKeyByID(ctx context.Context, id uuid.UUID) (*sqlc.ApiKey, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	key, err := r.db.GetActiveApiKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// UseNonce also deletes the key's expired nonces
+func (r *APIKeyRepo) UseNonce(ctx context.Context, keyID uuid.UUID, nonce string, expiresAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.UseRequestNonce(ctx, sqlc.UseRequestNonceParams{
+		KeyID:     keyID,
+		Nonce:     nonce,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}))
+}
+
+func (r *APIKeyRepo) ListKeys(ctx context.Context, userID uuid.UUID) ([]sqlc.ApiKey, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListApiKeysByUser(ctx, userID)
+}
+
+func (r *APIKeyRepo) TouchKey(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.TouchApiKey(ctx, id)
+}
+
+func (r *APIKeyRepo) RevokeKey(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.RevokeApiKey(ctx, sqlc.RevokeApiKeyParams{ID: id, UserID: userID}))
+}
+
+func (r *APIKeyRepo) SetAllowedCIDRs(ctx context.Context, id, userID uuid.UUID, cidrs []string) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SetApiKeyAllowedCidrs(ctx, sqlc.SetApiKeyAllowedCidrsParams{
+		ID:           id,
+		UserID:       userID,
+		AllowedCidrs: cidrs,
+	}))
+}
+
+func (r *APIKeyRepo) SetScopes(ctx context.Context, id, userID uuid.UUID, scopes []string) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SetApiKeyScopes(ctx, sqlc.SetApiKeyScopesParams{
+		ID:     id,
+		UserID: userID,
+		Scopes: scopes,
+	}))
+}
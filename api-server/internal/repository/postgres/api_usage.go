@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+)
+
+// IAPIUsageInterface is the daily API usage repository, counting each user's calls per endpoint
+type IAPIUsageInterface interface {
+	// RecordUsage adds usage to the counters of its user, day and endpoint
+	RecordUsage(ctx context.Context, usage sqlc.RecordApiUsageParams) error
+	// ListUsage returns the usage of userID from the UTC day of since on, oldest first
+	ListUsage(ctx context.Context, userID uuid.UUID, since time.Time) ([]sqlc.ApiUsage, error)
+	// DeleteUsageBefore deletes the usage of the UTC days before the day of before
+	DeleteUsageBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type APIUsageRepo struct {
+	db *sqlc.Queries
+}
+
+func NewAPIUsageRepository(db sqlc.DBTX) IAPIUsageInterface {
+	return &APIUsageRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *APIUsageRepo) RecordUsage(ctx context.Context, usage sqlc.RecordApiUsageParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.RecordApiUsage(ctx, usage)
+}
+
+func (r *APIUsageRepo) ListUsage(ctx context.Context, userID uuid.UUID, since time.Time) ([]sqlc.ApiUsage, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.ListApiUsageByUser(ctx, sqlc.ListApiUsageByUserParams{UserID: userID, Since: utils.ToPgDate(since)})
+}
+
+func (r *APIUsageRepo) DeleteUsageBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx, Reporting)
+	defer cancel()
+
+	return r.db.DeleteApiUsageBefore(ctx, utils.ToPgDate(before))
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IArchiveInterface reads and writes the records of all users for exports and restores. It is
+// not scoped to a user, so it must only be reached by administrative tools.
+type IArchiveInterface interface {
+	// ExportUsers, ExportAddresses and ExportAlertRules return up to limit live records ordered
+	// by ID, starting after the ID after, or at the first one when after is not valid. Records of
+	// deleted users are left out.
+	ExportUsers(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.User, error)
+	ExportAddresses(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.Address, error)
+	ExportAlertRules(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.AlertRule, error)
+	// ImportUser, ImportAddress and ImportAlertRule create a record with the given ID and
+	// timestamps, reporting false when it was skipped because it conflicts with an existing one
+	ImportUser(ctx context.Context, user sqlc.ImportUserParams) (bool, error)
+	ImportAddress(ctx context.Context, address sqlc.ImportAddressParams) (bool, error)
+	ImportAlertRule(ctx context.Context, rule sqlc.ImportAlertRuleParams) (bool, error)
+}
+
+type ArchiveRepo struct {
+	db *sqlc.Queries
+}
+
+func NewArchiveRepository(db sqlc.DBTX) IArchiveInterface {
+	return &ArchiveRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *ArchiveRepo) ExportUsers(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.ExportUsers(ctx, sqlc.ExportUsersParams{AfterID: after, PageSize: limit})
+}
+
+func (r *ArchiveRepo) ExportAddresses(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.Address, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.ExportAddresses(ctx, sqlc.ExportAddressesParams{AfterID: after, PageSize: limit})
+}
+
+func (r *ArchiveRepo) ExportAlertRules(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.AlertRule, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.ExportAlertRules(ctx, sqlc.ExportAlertRulesParams{AfterID: after, PageSize: limit})
+}
+
+func (r *ArchiveRepo) ImportUser(ctx context.Context, user sqlc.ImportUserParams) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	n, err := r.db.ImportUser(ctx, user)
+	return n > 0, err
+}
+
+func (r *ArchiveRepo) ImportAddress(ctx context.Context, address sqlc.ImportAddressParams) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	n, err := r.db.ImportAddress(ctx, address)
+	return n > 0, err
+}
+
+func (r *ArchiveRepo) ImportAlertRule(ctx context.Context, rule sqlc.ImportAlertRuleParams) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	n, err := r.db.ImportAlertRule(ctx, rule)
+	return n > 0, err
+}
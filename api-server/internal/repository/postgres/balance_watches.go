@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IBalanceWatchInterface is the repository of the ERC-20 balances polled with balanceOf. Methods
+// taking a user ID only see that user's watches; updating or deleting another user's watch fails
+// with pgx.ErrNoRows.
+type IBalanceWatchInterface interface {
+	CreateWatch(ctx context.Context, watch sqlc.CreateBalanceWatchParams) (uuid.UUID, error)
+	GetWatch(ctx context.Context, id, userID uuid.UUID) (*sqlc.BalanceWatch, error)
+	ListWatches(ctx context.Context, addressID, userID uuid.UUID) ([]sqlc.BalanceWatch, error)
+	UpdateWatch(ctx context.Context, watch sqlc.UpdateBalanceWatchParams) error
+	DeleteWatch(ctx context.Context, id, userID uuid.UUID) error
+	// ClaimDueWatches returns up to limit enabled watches of addresses on chain whose check is
+	// due, and schedules their next check an interval away
+	ClaimDueWatches(ctx context.Context, chain string, limit int32) ([]sqlc.ClaimDueBalanceWatchesRow, error)
+	// RecordBalance stores the balance deltas are measured from, read at blockNumber, failing
+	// with pgx.ErrNoRows when a later block's balance is stored already
+	RecordBalance(ctx context.Context, id uuid.UUID, balance pgtype.Numeric, blockNumber int64) error
+	// RecordCheck records a check that did not change the stored balance, with the error it
+	// failed with, if any
+	RecordCheck(ctx context.Context, id uuid.UUID, lastError pgtype.Text) error
+}
+
+type BalanceWatchRepo struct {
+	db *sqlc.Queries
+}
+
+func NewBalanceWatchRepository(db sqlc.DBTX) IBalanceWatchInterface {
+	return &BalanceWatchRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *BalanceWatchRepo) CreateWatch(ctx context.Context, watch sqlc.CreateBalanceWatchParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateBalanceWatch(ctx, watch)
+}
+
+func (r *BalanceWatchRepo) GetWatch(ctx context.Context, id, userID uuid.UUID) (*sqlc.BalanceWatch, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	watch, err := r.db.GetBalanceWatch(ctx, sqlc.GetBalanceWatchParams{ID: id, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &watch, nil
+}
+
+func (r *BalanceWatchRepo) ListWatches(ctx context.Context, addressID, userID uuid.UUID) ([]sqlc.BalanceWatch, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListBalanceWatchesByAddress(ctx, sqlc.ListBalanceWatchesByAddressParams{
+		AddressID: addressID,
+		UserID:    userID,
+	})
+}
+
+func (r *BalanceWatchRepo) UpdateWatch(ctx context.Context, watch sqlc.UpdateBalanceWatchParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.UpdateBalanceWatch(ctx, watch))
+}
+
+func (r *BalanceWatchRepo) DeleteWatch(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.DeleteBalanceWatch(ctx, sqlc.DeleteBalanceWatchParams{ID: id, UserID: userID}))
+}
+
+func (r *BalanceWatchRepo) ClaimDueWatches(ctx context.Context, chain string, limit int32) ([]sqlc.ClaimDueBalanceWatchesRow, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ClaimDueBalanceWatches(ctx, sqlc.ClaimDueBalanceWatchesParams{Chain: chain, MaxResults: limit})
+}
+
+func (r *BalanceWatchRepo) RecordBalance(ctx context.Context, id uuid.UUID, balance pgtype.Numeric, blockNumber int64) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.RecordBalanceWatchBalance(ctx, sqlc.RecordBalanceWatchBalanceParams{
+		ID:          id,
+		Balance:     balance,
+		BlockNumber: blockNumber,
+	}))
+}
+
+func (r *BalanceWatchRepo) RecordCheck(ctx context.Context, id uuid.UUID, lastError pgtype.Text) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.RecordBalanceWatchCheck(ctx, sqlc.RecordBalanceWatchCheckParams{ID: id, LastError: lastError})
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+)
+
+// IDashboardInterface aggregates over a user's addresses and alerts for their home screen
+type IDashboardInterface interface {
+	// CountActivity counts the user's addresses, those muted now, and their alerts raised since
+	// daySince and weekSince
+	CountActivity(ctx context.Context, userID uuid.UUID, daySince, weekSince time.Time) (sqlc.GetDashboardCountsRow, error)
+	// ListTopActiveAddresses returns the user's limit addresses with the most transactions stored
+	// since since, with their alerts since then
+	ListTopActiveAddresses(ctx context.Context, userID uuid.UUID, since time.Time, limit int32) ([]sqlc.ListTopActiveAddressesRow, error)
+	// ListChainAddresses returns the first limit addresses the user watches on chain, oldest first
+	ListChainAddresses(ctx context.Context, userID uuid.UUID, chain string, limit int32) ([]string, error)
+}
+
+type DashboardRepo struct {
+	db *sqlc.Queries
+}
+
+func NewDashboardRepository(db sqlc.DBTX) IDashboardInterface {
+	return &DashboardRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *DashboardRepo) CountActivity(ctx context.Context, userID uuid.UUID, daySince, weekSince time.Time) (sqlc.GetDashboardCountsRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.GetDashboardCounts(ctx, sqlc.GetDashboardCountsParams{
+		UserID:    userID,
+		DaySince:  utils.ToPgTime(daySince),
+		WeekSince: utils.ToPgTime(weekSince),
+	})
+}
+
+func (r *DashboardRepo) ListTopActiveAddresses(ctx context.Context, userID uuid.UUID, since time.Time, limit int32) ([]sqlc.ListTopActiveAddressesRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListTopActiveAddresses(ctx, sqlc.ListTopActiveAddressesParams{
+		Since:      utils.ToPgTime(since),
+		UserID:     userID,
+		MaxResults: limit,
+	})
+}
+
+func (r *DashboardRepo) ListChainAddresses(ctx context.Context, userID uuid.UUID, chain string, limit int32) ([]string, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListChainAddresses(ctx, sqlc.ListChainAddressesParams{
+		UserID:     userID,
+		Chain:      chain,
+		MaxResults: limit,
+	})
+}
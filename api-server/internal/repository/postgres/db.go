@@ -2,17 +2,29 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/dbstats"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Database is the api-server's PostgreSQL connection pool. Repositories take the pool, or a
+// transaction started with WithTx, as their sqlc DBTX. Repositories created on Conn may also have
+// their read-only queries served by a replica.
 type Database struct {
-	Connection *pgx.Conn
-	Pool       *pgxpool.Pool
+	Pool *pgxpool.Pool
+
+	replicas []*replica
+	next     atomic.Uint64
+
+	// stopBackground stops the replica checks and partition maintenance, tracked by background
+	stopBackground context.CancelFunc
+	background     sync.WaitGroup
 }
 
 var dbInstance *Database
@@ -29,18 +41,122 @@ func connect() *Database {
 	c := config.GetConfig()
 	ctx := context.Background()
 
-	conn, err := pgx.Connect(ctx, c.DatabaseURL)
+	dbPool, err := newPool(c.DatabaseURL, c.DatabasePool)
 	if err != nil {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
+	if err := dbPool.Ping(ctx); err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+
+	db := &Database{
+		Pool: dbPool,
+	}
+	backgroundCtx, stop := context.WithCancel(ctx)
+	db.stopBackground = stop
+
+	// An unreachable replica is not fatal, reads fall back to the primary until it catches up
+	for _, url := range c.Replicas.URLs {
+		pool, err := newPool(url, c.DatabasePool)
+		if err != nil {
+			log.Fatalf("Error parsing database replica URL: %v", err)
+		}
+		db.replicas = append(db.replicas, &replica{name: pool.Config().ConnConfig.Host, pool: pool})
+	}
+	if len(db.replicas) > 0 {
+		db.checkReplicas(backgroundCtx, c.Replicas.MaxLag)
+		db.background.Go(func() {
+			db.monitorReplicas(backgroundCtx, c.Replicas.CheckInterval, c.Replicas.MaxLag)
+		})
+	}
+	db.background.Go(func() {
+		db.maintainPartitions(backgroundCtx, c.Partitions.CheckInterval)
+	})
+
+	return db
+}
 
-	dbPool, err := pgxpool.New(ctx, c.DatabaseURL)
+// newPool creates a connection pool for url with the configured pool limits. Connections are
+// opened lazily, so the database need not be reachable yet.
+func newPool(url string, limits config.DatabasePool) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(url)
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		return nil, err
+	}
+	poolConfig.MaxConns = limits.MaxConns
+	poolConfig.MinConns = limits.MinConns
+	poolConfig.MaxConnLifetime = limits.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = limits.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = limits.HealthCheckPeriod
+	poolConfig.BeforeConnect = useCurrentCredentials
+	poolConfig.PrepareConn = setTenant
+	// pgxpool does not count failed connection attempts, see Stats
+	dbstats.Trace(poolConfig)
+
+	return pgxpool.NewWithConfig(context.Background(), poolConfig)
+}
+
+// WithTx runs fn in a transaction on the pool, committing when fn returns nil and rolling back
+// when it returns an error or panics
+//
+// Example usage:
+//
+//	err := db.WithTx(ctx, func(tx pgx.Tx) error {
+//	    users := postgres.NewUserRepository(tx)
+//	    ...
+//	})
+func (d *Database) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rolling back a committed transaction is a no-op
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		// Serializable transactions may fail at commit
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+	return nil
+}
+
+// Close stops the replica checks and partition maintenance, waits for acquired connections to be
+// released and closes the pools
+func (d *Database) Close() {
+	d.stopBackground()
+	d.background.Wait()
+	for _, r := range d.replicas {
+		r.pool.Close()
 	}
+	d.Pool.Close()
+}
+
+// useCurrentCredentials applies the latest database credentials and OLTP statement timeout to new
+// pool connections, so a rotated password is used once the config has been refreshed. Replicas
+// use them too: physical standbys share the primary's roles.
+func useCurrentCredentials(ctx context.Context, connConfig *pgx.ConnConfig) error {
+	c := config.GetConfig()
+	current, err := pgx.ParseConfig(c.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	connConfig.User = current.User
+	connConfig.Password = current.Password
+	connConfig.RuntimeParams["statement_timeout"] = statementTimeout(c.QueryTimeout.OLTP)
+	return nil
+}
 
-	return &Database{
-		Connection: conn,
-		Pool:       dbPool,
+// expectRow converts the result of an :execrows query that matched no row into pgx.ErrNoRows, the
+// error :one queries return, so callers check for missing records the same way
+func expectRow(rows int64, err error) error {
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
 	}
+	return nil
 }
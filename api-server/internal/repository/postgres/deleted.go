@@ -0,0 +1,18 @@
+package postgres
+
+import "context"
+
+type includeDeletedKey struct{}
+
+// IncludeDeleted makes the Get and List methods of the addresses, alert rules and webhooks
+// repositories return soft-deleted records too when run under ctx, for admin tools and audits. By
+// default deleted records are hidden as if they were gone; they can never be updated.
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IsIncludingDeleted reports whether ctx was marked with IncludeDeleted
+func IsIncludingDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Domain errors returned by the repositories in place of driver errors, check them with errors.Is.
+// Missing records are reported as pgx.ErrNoRows.
+var (
+	// ErrDuplicate means a unique constraint rejected the write, e.g. an email that is taken
+	ErrDuplicate = errors.New("record already exists")
+	// ErrMissingReference means a referenced record, e.g. the user of an address, does not exist
+	ErrMissingReference = errors.New("referenced record does not exist")
+	// ErrConflict means a concurrent transaction conflicted with this one, retrying may succeed
+	ErrConflict = errors.New("conflicting concurrent update, please retry")
+	// ErrStaleVersion means the record was changed since the version an update was based on was
+	// read. Retrying the same update fails again, the caller must read the record again.
+	ErrStaleVersion = errors.New("record was changed by another update, reload it and try again")
+)
+
+// PostgreSQL error codes mapped to domain errors, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// Error is a database error mapped to a domain error. Its message is the domain error's, so driver
+// details do not reach API responses, while errors.Is and errors.As match both the domain error
+// and the driver error.
+type Error struct {
+	// Kind is ErrDuplicate, ErrMissingReference or ErrConflict
+	Kind error
+	// Constraint is the violated constraint, or the table and columns it covers when the backend
+	// does not name it, e.g. "users.email" on SQLite. It is empty for conflicts.
+	Constraint string
+	// Err is the driver error
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// CheckVersion converts the pgx.ErrNoRows of an update guarded by a record version into
+// ErrStaleVersion when exists finds the record, i.e. it was changed since that version was read.
+// When the record is gone exists fails with pgx.ErrNoRows, which is returned.
+func CheckVersion(err error, exists func() error) error {
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err := exists(); err != nil {
+		return err
+	}
+	return ErrStaleVersion
+}
+
+// mapError converts the PostgreSQL errors of constraint violations and transaction conflicts into
+// an *Error, returning any other error unchanged
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolation:
+		return &Error{Kind: ErrDuplicate, Constraint: pgErr.ConstraintName, Err: err}
+	case foreignKeyViolation:
+		return &Error{Kind: ErrMissingReference, Constraint: pgErr.ConstraintName, Err: err}
+	case serializationFailure, deadlockDetected:
+		return &Error{Kind: ErrConflict, Err: err}
+	}
+	return err
+}
+
+// mappedConn maps the errors of the queries run on db, see mapError
+type mappedConn struct {
+	db sqlc.DBTX
+}
+
+func (c mappedConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tag, err := c.db.Exec(ctx, sql, args...)
+	return tag, mapError(err)
+}
+
+func (c mappedConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return mappedRows{rows}, nil
+}
+
+func (c mappedConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return mappedRow{c.db.QueryRow(ctx, sql, args...)}
+}
+
+// mappedRow maps the error of a QueryRow, which pgx defers to Scan
+type mappedRow struct {
+	pgx.Row
+}
+
+func (r mappedRow) Scan(dest ...any) error {
+	return mapError(r.Row.Scan(dest...))
+}
+
+// mappedRows maps the error of a Query, which pgx may defer to Err
+type mappedRows struct {
+	pgx.Rows
+}
+
+func (r mappedRows) Err() error {
+	return mapError(r.Rows.Err())
+}
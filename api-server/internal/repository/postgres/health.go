@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/dbstats"
+)
+
+// IHealthInterface reports the state of the database for the readiness and metrics endpoints
+type IHealthInterface interface {
+	// Ping round-trips to the primary, returning the latency including the wait for a connection
+	Ping(ctx context.Context) (time.Duration, error)
+	Stats() DatabaseStats
+}
+
+// DatabaseStats are the connection pool counters of the primary and of each replica
+type DatabaseStats struct {
+	Driver   string         `json:"driver"`
+	Primary  dbstats.Pool   `json:"primary"`
+	Replicas []ReplicaStats `json:"replicas,omitempty"`
+}
+
+// ReplicaStats are the pool counters of a replica, identified by its position in DB_REPLICA_URLS
+// rather than its host name
+type ReplicaStats struct {
+	Index   int  `json:"index"`
+	Healthy bool `json:"healthy"`
+	dbstats.Pool
+}
+
+func (d *Database) Ping(ctx context.Context) (time.Duration, error) {
+	return dbstats.Ping(ctx, d.Pool)
+}
+
+func (d *Database) Stats() DatabaseStats {
+	stats := DatabaseStats{Driver: "postgres", Primary: dbstats.Snapshot(d.Pool)}
+	for i, r := range d.replicas {
+		stats.Replicas = append(stats.Replicas, ReplicaStats{
+			Index:   i,
+			Healthy: r.healthy.Load(),
+			Pool:    dbstats.Snapshot(r.pool),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+)
+
+// Job states
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// IJobInterface is the background jobs repository, the queue of package jobs
+type IJobInterface interface {
+	// EnqueueJob stores a queued job, due at job.RunAt
+	EnqueueJob(ctx context.Context, job sqlc.EnqueueJobParams) (sqlc.Job, error)
+	GetJob(ctx context.Context, id, userID uuid.UUID) (sqlc.Job, error)
+	// ListJobs returns the latest limit jobs of userID, newest first
+	ListJobs(ctx context.Context, userID uuid.UUID, limit int32) ([]sqlc.Job, error)
+	// ClaimJob marks the next due job running for worker until until, skipping jobs claimed by
+	// other workers and taking over jobs whose lease expired. It fails with pgx.ErrNoRows when no
+	// job is due.
+	ClaimJob(ctx context.Context, worker string, until time.Time) (sqlc.Job, error)
+	// ExtendLease keeps the running job id claimed by worker until until, failing with
+	// pgx.ErrNoRows once the job was taken over
+	ExtendLease(ctx context.Context, id uuid.UUID, worker string, until time.Time) error
+	// CompleteJob records the success of worker's job id with its JSON result
+	CompleteJob(ctx context.Context, id uuid.UUID, worker string, result []byte) error
+	// FailJob records a failed attempt of worker's job id, retrying at retryAt or, when retryAt
+	// is zero, giving up
+	FailJob(ctx context.Context, id uuid.UUID, worker string, cause error, retryAt time.Time) error
+	// FailAbandonedJobs gives up the jobs whose lease expired during their last attempt
+	FailAbandonedJobs(ctx context.Context) (int64, error)
+	// CountJobs counts the jobs by kind and state
+	CountJobs(ctx context.Context) ([]sqlc.CountJobsRow, error)
+}
+
+type JobRepo struct {
+	db *sqlc.Queries
+}
+
+func NewJobRepository(db sqlc.DBTX) IJobInterface {
+	return &JobRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *JobRepo) EnqueueJob(ctx context.Context, job sqlc.EnqueueJobParams) (sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.EnqueueJob(ctx, job)
+}
+
+func (r *JobRepo) GetJob(ctx context.Context, id, userID uuid.UUID) (sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.GetJob(ctx, sqlc.GetJobParams{ID: id, UserID: userID})
+}
+
+func (r *JobRepo) ListJobs(ctx context.Context, userID uuid.UUID, limit int32) ([]sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ListJobsByUser(ctx, sqlc.ListJobsByUserParams{UserID: userID, Limit: limit})
+}
+
+func (r *JobRepo) ClaimJob(ctx context.Context, worker string, until time.Time) (sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ClaimJob(ctx, sqlc.ClaimJobParams{
+		LockedBy:    utils.ToPgText(&worker),
+		LockedUntil: utils.ToPgTime(until),
+	})
+}
+
+func (r *JobRepo) ExtendLease(ctx context.Context, id uuid.UUID, worker string, until time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.ExtendJobLease(ctx, sqlc.ExtendJobLeaseParams{
+		ID:          id,
+		LockedBy:    utils.ToPgText(&worker),
+		LockedUntil: utils.ToPgTime(until),
+	}))
+}
+
+func (r *JobRepo) CompleteJob(ctx context.Context, id uuid.UUID, worker string, result []byte) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.CompleteJob(ctx, sqlc.CompleteJobParams{
+		ID:       id,
+		LockedBy: utils.ToPgText(&worker),
+		Result:   result,
+	}))
+}
+
+func (r *JobRepo) FailJob(ctx context.Context, id uuid.UUID, worker string, cause error, retryAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	status := JobQueued
+	if retryAt.IsZero() {
+		status, retryAt = JobFailed, time.Now()
+	}
+	message := cause.Error()
+
+	return expectRow(r.db.RecordJobFailure(ctx, sqlc.RecordJobFailureParams{
+		ID:        id,
+		LockedBy:  utils.ToPgText(&worker),
+		Status:    status,
+		LastError: utils.ToPgText(&message),
+		RunAt:     utils.ToPgTime(retryAt),
+	}))
+}
+
+func (r *JobRepo) FailAbandonedJobs(ctx context.Context) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.FailAbandonedJobs(ctx)
+}
+
+func (r *JobRepo) CountJobs(ctx context.Context) ([]sqlc.CountJobsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CountJobs(ctx)
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+)
+
+// IKnownEntityInterface is the repository of publicly known address owners, such as exchanges
+// and bridges, shared by all users
+type IKnownEntityInterface interface {
+	// UpsertEntity creates the entity of (chain, address) or renames it
+	UpsertEntity(ctx context.Context, entity sqlc.UpsertKnownEntityParams) error
+	// SearchEntities returns up to limit entities whose name matches every term as a prefix, best
+	// match first
+	SearchEntities(ctx context.Context, terms []string, limit int32) ([]sqlc.SearchKnownEntitiesRow, error)
+}
+
+type KnownEntityRepo struct {
+	db *sqlc.Queries
+}
+
+func NewKnownEntityRepository(db sqlc.DBTX) IKnownEntityInterface {
+	return &KnownEntityRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *KnownEntityRepo) UpsertEntity(ctx context.Context, entity sqlc.UpsertKnownEntityParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.UpsertKnownEntity(ctx, entity)
+}
+
+func (r *KnownEntityRepo) SearchEntities(ctx context.Context, terms []string, limit int32) ([]sqlc.SearchKnownEntitiesRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.SearchKnownEntities(ctx, sqlc.SearchKnownEntitiesParams{
+		Query:      prefixQuery(terms),
+		MaxResults: limit,
+	})
+}
+
+// prefixQuery builds a tsquery matching documents that contain every term as a word prefix. Terms
+// are quoted, so tsquery operators in them are matched literally.
+func prefixQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = "'" + strings.ReplaceAll(term, "'", "''") + "':*"
+	}
+	return strings.Join(parts, " & ")
+}
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+)
+
+// Notification delivery states
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	// DeliverySkipped deliveries were not sent on purpose, e.g. because their address was muted
+	DeliverySkipped = "skipped"
+)
+
+// WebhookDelivery is a claimed webhook delivery with the webhook it is posted to and its alert
+type WebhookDelivery sqlc.ClaimDueWebhookDeliveriesRow
+
+// INotificationDeliveryInterface is the notification deliveries repository
+type INotificationDeliveryInterface interface {
+	// CreateDelivery stores a pending delivery, due immediately
+	CreateDelivery(ctx context.Context, delivery sqlc.CreateNotificationDeliveryParams) (uuid.UUID, error)
+	ListDeliveries(ctx context.Context, alertID uuid.UUID) ([]sqlc.NotificationDelivery, error)
+	// ClaimDueDeliveries locks up to limit pending deliveries that are due, skipping those claimed
+	// by other workers. It must run in a transaction (see ITxManager), which holds the locks until
+	// the deliveries are marked sent or failed.
+	ClaimDueDeliveries(ctx context.Context, limit int32) ([]sqlc.NotificationDelivery, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	// MarkFailed records a failed attempt, retrying at retryAt or, when retryAt is zero, giving up
+	MarkFailed(ctx context.Context, id uuid.UUID, cause error, retryAt time.Time) error
+
+	// ClaimWebhookDeliveries locks up to limit due deliveries of webhooks in the single delivery
+	// mode, like ClaimDueDeliveries
+	ClaimWebhookDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error)
+	// ListDueBatches returns up to limit webhooks in the batch delivery mode whose batch is due:
+	// batch_max_events deliveries are due or the oldest has waited batch_interval_seconds
+	ListDueBatches(ctx context.Context, limit int32) ([]sqlc.ListDueWebhookBatchesRow, error)
+	// ClaimBatch locks up to limit due deliveries of a batch webhook, oldest first, like
+	// ClaimDueDeliveries
+	ClaimBatch(ctx context.Context, webhookID uuid.UUID, limit int32) ([]WebhookDelivery, error)
+	// ClaimBlock locks up to limit more due deliveries to a webhook of an address's alerts for
+	// transfers in block, but those in claimed, like ClaimDueDeliveries
+	ClaimBlock(ctx context.Context, webhookID, addressID uuid.UUID, block int64, claimed []uuid.UUID, limit int32) ([]WebhookDelivery, error)
+	// MarkBatchSent and MarkBatchFailed are MarkSent and MarkFailed for the deliveries of a batch
+	MarkBatchSent(ctx context.Context, ids []uuid.UUID) error
+	MarkBatchFailed(ctx context.Context, ids []uuid.UUID, cause error, retryAt time.Time) error
+	// SkipDeliveries gives up the deliveries without sending them, recording why
+	SkipDeliveries(ctx context.Context, ids []uuid.UUID, reason string) error
+}
+
+type NotificationDeliveryRepo struct {
+	db *sqlc.Queries
+}
+
+func NewNotificationDeliveryRepository(db sqlc.DBTX) INotificationDeliveryInterface {
+	return &NotificationDeliveryRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *NotificationDeliveryRepo) CreateDelivery(ctx context.Context, delivery sqlc.CreateNotificationDeliveryParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateNotificationDelivery(ctx, delivery)
+}
+
+func (r *NotificationDeliveryRepo) ListDeliveries(ctx context.Context, alertID uuid.UUID) ([]sqlc.NotificationDelivery, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListNotificationDeliveriesByAlert(ctx, alertID)
+}
+
+func (r *NotificationDeliveryRepo) ClaimDueDeliveries(ctx context.Context, limit int32) ([]sqlc.NotificationDelivery, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ClaimDueNotificationDeliveries(ctx, limit)
+}
+
+func (r *NotificationDeliveryRepo) MarkSent(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.MarkNotificationDeliverySent(ctx, id)
+}
+
+func (r *NotificationDeliveryRepo) MarkFailed(ctx context.Context, id uuid.UUID, cause error, retryAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	status := DeliveryPending
+	if retryAt.IsZero() {
+		status, retryAt = DeliveryFailed, time.Now()
+	}
+	message := cause.Error()
+
+	return r.db.RecordNotificationDeliveryFailure(ctx, sqlc.RecordNotificationDeliveryFailureParams{
+		ID:            id,
+		Status:        status,
+		LastError:     utils.ToPgText(&message),
+		NextAttemptAt: utils.ToPgTime(retryAt),
+	})
+}
+
+func (r *NotificationDeliveryRepo) ClaimWebhookDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	rows, err := r.db.ClaimDueWebhookDeliveries(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = WebhookDelivery(row)
+	}
+	return deliveries, nil
+}
+
+func (r *NotificationDeliveryRepo) ListDueBatches(ctx context.Context, limit int32) ([]sqlc.ListDueWebhookBatchesRow, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.ListDueWebhookBatches(ctx, limit)
+}
+
+func (r *NotificationDeliveryRepo) ClaimBatch(ctx context.Context, webhookID uuid.UUID, limit int32) ([]WebhookDelivery, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	rows, err := r.db.ClaimWebhookBatch(ctx, sqlc.ClaimWebhookBatchParams{WebhookID: webhookID, MaxResults: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = WebhookDelivery(row)
+	}
+	return deliveries, nil
+}
+
+func (r *NotificationDeliveryRepo) ClaimBlock(ctx context.Context, webhookID, addressID uuid.UUID, block int64, claimed []uuid.UUID, limit int32) ([]WebhookDelivery, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	rows, err := r.db.ClaimBlockWebhookDeliveries(ctx, sqlc.ClaimBlockWebhookDeliveriesParams{
+		WebhookID:   webhookID,
+		AddressID:   addressID,
+		BlockNumber: block,
+		Ids:         claimed,
+		MaxResults:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = WebhookDelivery(row)
+	}
+	return deliveries, nil
+}
+
+func (r *NotificationDeliveryRepo) MarkBatchSent(ctx context.Context, ids []uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.MarkNotificationDeliveriesSent(ctx, ids)
+}
+
+func (r *NotificationDeliveryRepo) MarkBatchFailed(ctx context.Context, ids []uuid.UUID, cause error, retryAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	status := DeliveryPending
+	if retryAt.IsZero() {
+		status, retryAt = DeliveryFailed, time.Now()
+	}
+	message := cause.Error()
+
+	return r.db.RecordNotificationDeliveriesFailure(ctx, sqlc.RecordNotificationDeliveriesFailureParams{
+		Status:        status,
+		LastError:     utils.ToPgText(&message),
+		NextAttemptAt: utils.ToPgTime(retryAt),
+		Ids:           ids,
+	})
+}
+
+func (r *NotificationDeliveryRepo) SkipDeliveries(ctx context.Context, ids []uuid.UUID, reason string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.SkipNotificationDeliveries(ctx, sqlc.SkipNotificationDeliveriesParams{
+		LastError: utils.ToPgText(&reason),
+		Ids:       ids,
+	})
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tracecontext"
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event, e.g. "user.registered" for aggregate "user"
+type OutboxEvent struct {
+	AggregateType string
+	AggregateID   string
+	Type          string
+	Payload       any
+}
+
+// IOutboxInterface writes domain events to the outbox_events table. Events must be written in the
+// transaction of the change they describe (see ITxManager).
+type IOutboxInterface interface {
+	CreateEvent(ctx context.Context, event OutboxEvent) error
+}
+
+type OutboxRepo struct {
+	db *sqlc.Queries
+}
+
+func NewOutboxRepository(db sqlc.DBTX) IOutboxInterface {
+	return &OutboxRepo{
+		db: sqlc.New(db),
+	}
+}
+
+// CreateEvent stores event with its payload as JSON and the trace context of ctx, if any
+func (r *OutboxRepo) CreateEvent(ctx context.Context, event OutboxEvent) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+
+	var traceparent *string
+	if sc, ok := tracecontext.FromContext(ctx); ok {
+		s := sc.String()
+		traceparent = &s
+	}
+
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateOutboxEvent(ctx, sqlc.CreateOutboxEventParams{
+		ID:            uuid.New(),
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Type:          event.Type,
+		Payload:       payload,
+		Traceparent:   utils.ToPgText(traceparent),
+	})
+}
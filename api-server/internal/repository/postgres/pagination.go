@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Page sizes used when a request asks for none or too many items
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ErrInvalidCursor is returned for a cursor that was not returned by a previous page
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// PageRequest asks for up to Limit items following Cursor, the NextCursor of the previous page.
+// The zero value asks for the first page of DefaultPageSize items.
+type PageRequest struct {
+	Cursor string
+	Limit  int32
+}
+
+// Page is one page of a listing. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// keyset is the position of the last item of a page: listings are ordered by creation time and
+// then ID, so a page continues after it however many rows precede it. Cursors are opaque to
+// clients and only decoded here.
+type keyset struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (k keyset) encode() string {
+	b, _ := json.Marshal(k)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// PageQuery holds the query parameters of a page request: the keyset to continue after, NULL for
+// the first page, and the number of rows to fetch, one more than the limit to find out whether
+// another page follows
+type PageQuery struct {
+	AfterCreatedAt pgtype.Timestamptz
+	AfterID        pgtype.UUID
+	Size           int32
+	limit          int32
+}
+
+// Query decodes the page request
+func (p PageRequest) Query() (PageQuery, error) {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	q := PageQuery{Size: limit + 1, limit: limit}
+	if p.Cursor == "" {
+		return q, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return q, ErrInvalidCursor
+	}
+	var k keyset
+	if err := json.Unmarshal(b, &k); err != nil || k.CreatedAt.IsZero() {
+		return q, ErrInvalidCursor
+	}
+	q.AfterCreatedAt = pgtype.Timestamptz{Time: k.CreatedAt, Valid: true}
+	q.AfterID = pgtype.UUID{Bytes: k.ID, Valid: true}
+	return q, nil
+}
+
+// NewPage builds the page from the rows fetched for q, with key returning an item's creation time
+// and ID
+func NewPage[T any](q PageQuery, rows []T, key func(T) (pgtype.Timestamptz, uuid.UUID)) Page[T] {
+	if int32(len(rows)) <= q.limit {
+		return Page[T]{Items: rows}
+	}
+
+	items := rows[:q.limit]
+	createdAt, id := key(items[len(items)-1])
+	return Page[T]{
+		Items:      items,
+		NextCursor: keyset{CreatedAt: createdAt.Time, ID: id}.encode(),
+	}
+}
+
+// MergePages merges pages of listings ordered newest first, each read with page, into one page of
+// the size page asks for. The listings share its cursor: every one continues after the last item
+// merged, as their keysets compare alike.
+func MergePages[T any](page PageRequest, key func(T) (pgtype.Timestamptz, uuid.UUID), pages ...Page[T]) Page[T] {
+	q, _ := page.Query()
+
+	var items []T
+	more := false
+	for _, p := range pages {
+		items = append(items, p.Items...)
+		more = more || p.NextCursor != ""
+	}
+	slices.SortFunc(items, func(a, b T) int {
+		at, aid := key(a)
+		bt, bid := key(b)
+		if c := bt.Time.Compare(at.Time); c != 0 {
+			return c
+		}
+		return bytes.Compare(bid[:], aid[:])
+	})
+	if int32(len(items)) > q.limit {
+		items, more = items[:q.limit], true
+	}
+	if !more || len(items) == 0 {
+		return Page[T]{Items: items}
+	}
+
+	createdAt, id := key(items[len(items)-1])
+	return Page[T]{
+		Items:      items,
+		NextCursor: keyset{CreatedAt: createdAt.Time, ID: id}.encode(),
+	}
+}
+
+// AddressKey, AlertKey, TransactionKey, AddressEventKey and AccountEventKey return the keyset of a
+// listed row for NewPage
+func AddressKey(a sqlc.Address) (pgtype.Timestamptz, uuid.UUID)           { return a.CreatedAt, a.ID }
+func AlertKey(a sqlc.Alert) (pgtype.Timestamptz, uuid.UUID)               { return a.CreatedAt, a.ID }
+func TransactionKey(t sqlc.Transaction) (pgtype.Timestamptz, uuid.UUID)   { return t.CreatedAt, t.ID }
+func AddressEventKey(e sqlc.AddressEvent) (pgtype.Timestamptz, uuid.UUID) { return e.CreatedAt, e.ID }
+func AccountEventKey(e sqlc.AccountEvent) (pgtype.Timestamptz, uuid.UUID) { return e.CreatedAt, e.ID }
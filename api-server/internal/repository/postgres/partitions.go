@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The transactions table is partitioned by month of created_at, see migration 000013. Partitions
+// are named transactions_pYYYY_MM after their UTC month.
+const (
+	partitionNameLayout = "transactions_p2006_01"
+
+	// partitionLockKey is the advisory lock held while partitions are maintained, so api-server
+	// instances take turns
+	partitionLockKey = 0x7472616e73 // "trans"
+
+	// expiredKeysBatch bounds the keys, and the alerts cascading from them, deleted per statement
+	expiredKeysBatch = 1000
+)
+
+const listPartitionsQuery = `
+SELECT c.relname::text, i.inhdetachpending
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'transactions'::regclass`
+
+const deleteExpiredKeysQuery = `
+DELETE FROM transaction_keys
+WHERE (chain, hash, log_index) IN (
+    SELECT chain, hash, log_index FROM transaction_keys WHERE created_at < $1 LIMIT $2
+)`
+
+// maintainPartitions maintains the transactions partitions now and every interval until ctx is done
+func (d *Database) maintainPartitions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := d.MaintainPartitions(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to maintain transactions partitions: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// MaintainPartitions creates the partitions of the DB_PARTITIONS_AHEAD months starting with the
+// month of now, and detaches those ending more than DB_TRANSACTION_RETENTION_MONTHS months before
+// it. Detached partitions are left in place, for the archival job to archive and drop. It does
+// nothing while another api-server instance is maintaining the partitions.
+func (d *Database) MaintainPartitions(ctx context.Context, now time.Time) error {
+	conn, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, partitionLockKey).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	// Detaching waits for the queries using the partition, allow it the reporting timeout
+	if _, err := conn.Exec(ctx, `SELECT set_config('statement_timeout', $1, false)`, statementTimeout(Reporting.Timeout())); err != nil {
+		return err
+	}
+	defer func() {
+		ctx := context.WithoutCancel(ctx)
+		if _, err := conn.Exec(ctx, `RESET statement_timeout`); err != nil {
+			// Do not return the connection to the pool with the longer timeout
+			conn.Conn().Close(ctx)
+		}
+		conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, partitionLockKey)
+	}()
+
+	partitions, err := listPartitions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	c := config.GetConfig().Partitions
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := range c.Ahead {
+		start := month.AddDate(0, i, 0)
+		if _, ok := partitions[start.Format(partitionNameLayout)]; ok {
+			continue
+		}
+		var name string
+		if err := conn.QueryRow(ctx, `SELECT create_transactions_partition($1)`, start).Scan(&name); err != nil {
+			return fmt.Errorf("failed to create partition for %s: %w", start.Format("2006-01"), err)
+		}
+		log.Printf("Created transactions partition %s", name)
+	}
+
+	if c.RetentionMonths == 0 {
+		return nil
+	}
+	cutoff := month.AddDate(0, -c.RetentionMonths, 0)
+	for name, pending := range partitions {
+		start, err := time.Parse(partitionNameLayout, name)
+		if err != nil {
+			// Not created by create_transactions_partition
+			continue
+		}
+		end := start.AddDate(0, 1, 0)
+		if end.After(cutoff) {
+			continue
+		}
+		if err := detachPartition(ctx, conn, name, end, pending); err != nil {
+			return err
+		}
+		log.Printf("Detached transactions partition %s, to be archived or dropped", name)
+	}
+	return nil
+}
+
+// listPartitions returns the names of the partitions of transactions and whether a concurrent
+// detach of them was interrupted
+func listPartitions(ctx context.Context, conn *pgxpool.Conn) (map[string]bool, error) {
+	rows, err := conn.Query(ctx, listPartitionsQuery)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make(map[string]bool)
+	var name string
+	var pending bool
+	_, err = pgx.ForEachRow(rows, []any{&name, &pending}, func() error {
+		partitions[name] = pending
+		return nil
+	})
+	return partitions, err
+}
+
+// detachPartition deletes the keys of the transactions created before end, with their alerts, and
+// detaches the partition name. Keys go first, so a transfer seen again is stored in a current
+// partition rather than one being detached.
+func detachPartition(ctx context.Context, conn *pgxpool.Conn, name string, end time.Time, pending bool) error {
+	for {
+		tag, err := conn.Exec(ctx, deleteExpiredKeysQuery, end, expiredKeysBatch)
+		if err != nil {
+			return fmt.Errorf("failed to delete transaction keys of %s: %w", name, err)
+		}
+		if tag.RowsAffected() < expiredKeysBatch {
+			break
+		}
+	}
+
+	// A concurrent detach cannot run in a transaction and must be finalized when interrupted
+	mode := "CONCURRENTLY"
+	if pending {
+		mode = "FINALIZE"
+	}
+	sql := fmt.Sprintf("ALTER TABLE transactions DETACH PARTITION %s %s", pgx.Identifier{name}.Sanitize(), mode)
+	if _, err := conn.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("failed to detach %s: %w", name, err)
+	}
+	return nil
+}
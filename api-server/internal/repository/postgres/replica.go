@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicationLagQuery measures how far a standby is behind the primary. A standby that has replayed
+// everything it received is current even when the last replayed transaction is old, which happens
+// whenever the primary is idle. On a primary both LSN functions return NULL and the lag is 0.
+const replicationLagQuery = `
+SELECT COALESCE(
+    CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END,
+    0)::float8`
+
+// replica is a read-only standby and whether its last check found it reachable and current
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// monitorReplicas checks the replicas every interval until ctx is done
+func (d *Database) monitorReplicas(ctx context.Context, interval, maxLag time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.checkReplicas(ctx, maxLag)
+		}
+	}
+}
+
+// checkReplicas marks each replica healthy when it answers within the OLTP query timeout and lags
+// at most maxLag behind the primary, logging every change
+func (d *Database) checkReplicas(ctx context.Context, maxLag time.Duration) {
+	for _, r := range d.replicas {
+		lag, err := r.lag(ctx)
+		healthy := err == nil && lag <= maxLag
+		if healthy == r.healthy.Swap(healthy) {
+			continue
+		}
+		switch {
+		case healthy:
+			log.Printf("Database replica %s is serving reads (lag %s)", r.name, lag)
+		case err != nil:
+			log.Printf("Database replica %s is unavailable, reading from the primary: %v", r.name, err)
+		default:
+			log.Printf("Database replica %s lags %s behind, reading from the primary", r.name, lag)
+		}
+	}
+}
+
+func (r *replica) lag(ctx context.Context) (time.Duration, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	var seconds float64
+	if err := r.pool.QueryRow(ctx, replicationLagQuery).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// reader returns the pool read-only queries run on: the next healthy replica in turn, or the
+// primary when there is none
+func (d *Database) reader() *pgxpool.Pool {
+	n := uint64(len(d.replicas))
+	if n == 0 {
+		return d.Pool
+	}
+	start := d.next.Add(1)
+	for i := range n {
+		if r := d.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return d.Pool
+}
+
+// Conn returns a DBTX for repositories whose read-only methods may be served by a replica: queries
+// run under a context marked with readOnly go to a healthy replica, everything else to the primary.
+// Transactions always use the primary.
+func (d *Database) Conn() sqlc.DBTX {
+	return routedConn{db: d}
+}
+
+type routedConn struct {
+	db *Database
+}
+
+func (c routedConn) pool(ctx context.Context) *pgxpool.Pool {
+	if isReadOnly(ctx) {
+		return c.db.reader()
+	}
+	return c.db.Pool
+}
+
+func (c routedConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return c.db.Pool.Exec(ctx, sql, args...)
+}
+
+func (c routedConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.pool(ctx).Query(ctx, sql, args...)
+}
+
+func (c routedConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.pool(ctx).QueryRow(ctx, sql, args...)
+}
+
+type readOnlyKey struct{}
+
+// readOnly marks the queries run under ctx as read-only, so repositories created on Database.Conn
+// may serve them from a replica. Use it only for reads that tolerate the configured replica lag,
+// e.g. history and alert listings, not for reads that must see the caller's own writes.
+func readOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func isReadOnly(ctx context.Context) bool {
+	ro, _ := ctx.Value(readOnlyKey{}).(bool)
+	return ro
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// archiveLockKey is the advisory lock held while aged records are archived, so api-server
+// instances take turns
+const archiveLockKey = 0x61726368 // "arch"
+
+// detachedPartitionsQuery lists the transactions partitions detached by MaintainPartitions that
+// have not been dropped yet
+const detachedPartitionsQuery = `
+SELECT c.relname::text
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = current_schema()
+    AND c.relkind = 'r'
+    AND NOT c.relispartition
+    AND c.relname ~ '^transactions_p[0-9]{4}_[0-9]{2}$'
+ORDER BY c.relname`
+
+// partitionName matches the names of transactions partitions, which are interpolated into SQL
+var partitionName = regexp.MustCompile(`^transactions_p[0-9]{4}_[0-9]{2}$`)
+
+// IRetentionInterface is the repository of the archival job, which moves alerts past the
+// retention window to object storage, and of the objects it archived. It is not scoped to a user.
+type IRetentionInterface interface {
+	// ListAgedAlerts returns up to limit alerts created before before, oldest first, with their
+	// transfers
+	ListAgedAlerts(ctx context.Context, before time.Time, limit int32) ([]sqlc.ListAgedAlertsRow, error)
+	// DeleteAlerts deletes the alerts ids, with their notification deliveries
+	DeleteAlerts(ctx context.Context, ids []uuid.UUID) (int64, error)
+	// SaveArchivedRange records an archive object, replacing the record of an object with the
+	// same key
+	SaveArchivedRange(ctx context.Context, r sqlc.UpsertArchivedRangeParams) error
+	// ListArchivedRanges returns the archive objects of kind holding records created in
+	// [from, to), oldest first
+	ListArchivedRanges(ctx context.Context, kind string, from, to time.Time) ([]sqlc.ArchivedRange, error)
+}
+
+type RetentionRepo struct {
+	db *sqlc.Queries
+}
+
+func NewRetentionRepository(db sqlc.DBTX) IRetentionInterface {
+	return &RetentionRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *RetentionRepo) ListAgedAlerts(ctx context.Context, before time.Time, limit int32) ([]sqlc.ListAgedAlertsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx, Reporting)
+	defer cancel()
+
+	return r.db.ListAgedAlerts(ctx, sqlc.ListAgedAlertsParams{
+		Before:   pgtype.Timestamptz{Time: before, Valid: true},
+		PageSize: limit,
+	})
+}
+
+func (r *RetentionRepo) DeleteAlerts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx, Reporting)
+	defer cancel()
+
+	return r.db.DeleteAlerts(ctx, ids)
+}
+
+func (r *RetentionRepo) SaveArchivedRange(ctx context.Context, archived sqlc.UpsertArchivedRangeParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.UpsertArchivedRange(ctx, archived)
+}
+
+func (r *RetentionRepo) ListArchivedRanges(ctx context.Context, kind string, from, to time.Time) ([]sqlc.ArchivedRange, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListArchivedRanges(ctx, sqlc.ListArchivedRangesParams{
+		Kind:      kind,
+		RangeFrom: pgtype.Timestamptz{Time: from, Valid: true},
+		RangeTo:   pgtype.Timestamptz{Time: to, Valid: true},
+	})
+}
+
+// WithArchiveLock runs fn while holding the archival advisory lock, reporting false without
+// running it when another api-server instance holds the lock
+func (d *Database) WithArchiveLock(ctx context.Context, fn func() error) (bool, error) {
+	conn, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, archiveLockKey).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, archiveLockKey)
+
+	return true, fn()
+}
+
+// DetachedPartitions returns the names of the transactions partitions detached by
+// MaintainPartitions and not dropped yet, oldest first
+func (d *Database) DetachedPartitions(ctx context.Context) ([]string, error) {
+	rows, err := d.Pool.Query(ctx, detachedPartitionsQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ReadPartition calls fn with every row of the detached partition name as a JSON object, in
+// order of creation, under the reporting deadline
+func (d *Database) ReadPartition(ctx context.Context, name string, fn func(row json.RawMessage, createdAt time.Time) error) error {
+	if !partitionName.MatchString(name) {
+		return fmt.Errorf("not a transactions partition: %q", name)
+	}
+	return d.WithReportingTx(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, fmt.Sprintf(
+			`SELECT row_to_json(t)::text, t.created_at FROM %s t ORDER BY t.created_at, t.id`,
+			pgx.Identifier{name}.Sanitize()))
+		if err != nil {
+			return err
+		}
+		var row string
+		var createdAt time.Time
+		_, err = pgx.ForEachRow(rows, []any{&row, &createdAt}, func() error {
+			return fn(json.RawMessage(row), createdAt)
+		})
+		return err
+	})
+}
+
+// DropPartition drops the detached partition name once it was archived
+func (d *Database) DropPartition(ctx context.Context, name string) error {
+	if !partitionName.MatchString(name) {
+		return fmt.Errorf("not a transactions partition: %q", name)
+	}
+	_, err := d.Pool.Exec(ctx, "DROP TABLE "+pgx.Identifier{name}.Sanitize())
+	return err
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+)
+
+// ISessionInterface is the sessions repository, a session per sign-in, with the refresh tokens
+// renewing its access tokens. Revocations are recorded in the account activity by the statements
+// making them.
+type ISessionInterface interface {
+	CreateSession(ctx context.Context, session sqlc.CreateSessionParams) error
+	// GetActiveSession returns the user's session id unless it was revoked or its token expired
+	GetActiveSession(ctx context.Context, id, userID uuid.UUID) (*sqlc.Session, error)
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]sqlc.Session, error)
+	TouchSession(ctx context.Context, id uuid.UUID) error
+	// RevokeSession fails with pgx.ErrNoRows when the user has no such active session
+	RevokeSession(ctx context.Context, id, userID uuid.UUID) error
+	// RevokeOtherSessions revokes the user's active sessions but keep, returning their IDs
+	RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) ([]uuid.UUID, error)
+	CreateRefreshToken(ctx context.Context, token sqlc.CreateRefreshTokenParams) error
+	// GetRefreshToken returns the unexpired refresh token hashed tokenHash of an active session,
+	// used or not, failing with pgx.ErrNoRows when there is none
+	GetRefreshToken(ctx context.Context, tokenHash string) (*sqlc.GetRefreshTokenRow, error)
+	// RotateRefreshToken marks the refresh token used and creates its successor, moving the
+	// expiry of their session to the successor's. It fails with pgx.ErrNoRows when the token was
+	// used already.
+	RotateRefreshToken(ctx context.Context, rotation sqlc.RotateRefreshTokenParams) error
+}
+
+type SessionRepo struct {
+	db *sqlc.Queries
+}
+
+func NewSessionRepository(db sqlc.DBTX) ISessionInterface {
+	return &SessionRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *SessionRepo) CreateSession(ctx context.Context, session sqlc.CreateSessionParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateSession(ctx, session)
+}
+
+// GetActiveSession reads from the primary, so a revoked session ends immediately
+func (r *SessionRepo) GetActiveSession(ctx context.Context, id, userID uuid.UUID) (*sqlc.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	session, err := r.db.GetActiveSession(ctx, sqlc.GetActiveSessionParams{ID: id, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepo) ListSessions(ctx context.Context, userID uuid.UUID) ([]sqlc.Session, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListActiveSessionsByUser(ctx, userID)
+}
+
+func (r *SessionRepo) TouchSession(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.TouchSession(ctx, id)
+}
+
+func (r *SessionRepo) RevokeSession(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.RevokeSession(ctx, sqlc.RevokeSessionParams{ID: id, UserID: userID}))
+}
+
+func (r *SessionRepo) RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) ([]uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.RevokeOtherSessions(ctx, sqlc.RevokeOtherSessionsParams{UserID: userID, ID: keep})
+}
+
+func (r *SessionRepo) CreateRefreshToken(ctx context.Context, token sqlc.CreateRefreshTokenParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateRefreshToken(ctx, token)
+}
+
+// GetRefreshToken reads from the primary, so a token rotated or revoked a moment ago is seen
+func (r *SessionRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*sqlc.GetRefreshTokenRow, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	token, err := r.db.GetRefreshToken(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *SessionRepo) RotateRefreshToken(ctx context.Context, rotation sqlc.RotateRefreshTokenParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.RotateRefreshToken(ctx, rotation))
+}
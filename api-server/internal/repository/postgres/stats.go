@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+)
+
+// IStatsInterface aggregates over every user for the operations dashboard. It is not scoped to a
+// user, so it must only be reached by administrative endpoints.
+type IStatsInterface interface {
+	// CountUsers counts the users that are not deleted
+	CountUsers(ctx context.Context) (int64, error)
+	// CountAddressesByChain counts the watched addresses, and the users watching them, per chain
+	CountAddressesByChain(ctx context.Context) ([]sqlc.CountAddressesByChainRow, error)
+	CountAlertsSince(ctx context.Context, since time.Time) (int64, error)
+	// CountDeliveriesSince counts the notification deliveries created since since by channel and
+	// state
+	CountDeliveriesSince(ctx context.Context, since time.Time) ([]sqlc.CountDeliveriesSinceRow, error)
+	// ListChainHeads returns the highest block, and its time, of the transactions stored since
+	// since on each chain
+	ListChainHeads(ctx context.Context, since time.Time) ([]sqlc.ListChainHeadsRow, error)
+}
+
+type StatsRepo struct {
+	db *sqlc.Queries
+}
+
+func NewStatsRepository(db sqlc.DBTX) IStatsInterface {
+	return &StatsRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *StatsRepo) CountUsers(ctx context.Context) (int64, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.CountUsers(ctx)
+}
+
+func (r *StatsRepo) CountAddressesByChain(ctx context.Context) ([]sqlc.CountAddressesByChainRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.CountAddressesByChain(ctx)
+}
+
+func (r *StatsRepo) CountAlertsSince(ctx context.Context, since time.Time) (int64, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.CountAlertsSince(ctx, utils.ToPgTime(since))
+}
+
+func (r *StatsRepo) CountDeliveriesSince(ctx context.Context, since time.Time) ([]sqlc.CountDeliveriesSinceRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.CountDeliveriesSince(ctx, utils.ToPgTime(since))
+}
+
+func (r *StatsRepo) ListChainHeads(ctx context.Context, since time.Time) ([]sqlc.ListChainHeadsRow, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), Reporting)
+	defer cancel()
+
+	return r.db.ListChainHeads(ctx, utils.ToPgTime(since))
+}
@@ -0,0 +1,553 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CheckTenant fails with pgx.ErrNoRows when ctx acts for a user other than userID, so a request
+// naming another user's record is answered as if the record did not exist
+func CheckTenant(ctx context.Context, userID uuid.UUID) error {
+	if current, ok := tenant.User(ctx); ok && current != userID {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// tenantSetting is the session setting the row-level security policies compare user_id against,
+// see migration 000016
+const tenantSetting = "app.user_id"
+
+// setTenant sets tenantSetting of conn to the user ctx acts for, or clears it for unscoped
+// contexts, before conn is handed out by the pool. The setting is only sent when it changes.
+// A connection whose setting cannot be sent is destroyed so it cannot serve the wrong user.
+func setTenant(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	var userID string
+	if id, ok := tenant.User(ctx); ok {
+		userID = id.String()
+	}
+	data := conn.PgConn().CustomData()
+	if current, _ := data[tenantSetting].(string); current == userID {
+		return true, nil
+	}
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", tenantSetting, userID); err != nil {
+		return false, fmt.Errorf("failed to set tenant: %w", err)
+	}
+	data[tenantSetting] = userID
+	return true, nil
+}
+
+// Scoped wraps the user-scoped methods of repos with CheckTenant, so a request can only reach the
+// records of the user its context acts for. Both backends' NewRepositories return scoped
+// repositories; on Postgres the row-level security policies enforce the same rule once more.
+// Methods added to the repository interfaces must be wrapped here when they take a user ID.
+func Scoped(repos Repositories) Repositories {
+	repos.Users = scopedUsers{repos.Users}
+	repos.Addresses = scopedAddresses{repos.Addresses}
+	repos.AlertRules = scopedAlertRules{repos.AlertRules}
+	repos.Alerts = scopedAlerts{repos.Alerts}
+	repos.Webhooks = scopedWebhooks{repos.Webhooks}
+	repos.APIKeys = scopedAPIKeys{repos.APIKeys}
+	repos.Jobs = scopedJobs{repos.Jobs}
+	repos.APIUsage = scopedAPIUsage{repos.APIUsage}
+	repos.AddressEvents = scopedAddressEvents{repos.AddressEvents}
+	repos.AccountEvents = scopedAccountEvents{repos.AccountEvents}
+	repos.Dashboard = scopedDashboard{repos.Dashboard}
+	repos.BalanceWatches = scopedBalanceWatches{repos.BalanceWatches}
+	repos.Sessions = scopedSessions{repos.Sessions}
+	return repos
+}
+
+type scopedUsers struct{ IUserInterface }
+
+func (r scopedUsers) GetUserByID(ctx context.Context, id uuid.UUID) (*sqlc.User, error) {
+	if err := CheckTenant(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.IUserInterface.GetUserByID(ctx, id)
+}
+
+func (r scopedUsers) UpdateProfile(ctx context.Context, user sqlc.UpdateUserProfileParams) error {
+	if err := CheckTenant(ctx, user.ID); err != nil {
+		return err
+	}
+	return r.IUserInterface.UpdateProfile(ctx, user)
+}
+
+func (r scopedUsers) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	if err := CheckTenant(ctx, id); err != nil {
+		return err
+	}
+	return r.IUserInterface.UpdatePassword(ctx, id, passwordHash)
+}
+
+func (r scopedUsers) CreateEmailVerification(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error {
+	if err := CheckTenant(ctx, id); err != nil {
+		return err
+	}
+	return r.IUserInterface.CreateEmailVerification(ctx, id, email, tokenHash, expiresAt)
+}
+
+func (r scopedUsers) SoftDeleteUser(ctx context.Context, id uuid.UUID) error {
+	if err := CheckTenant(ctx, id); err != nil {
+		return err
+	}
+	return r.IUserInterface.SoftDeleteUser(ctx, id)
+}
+
+func (r scopedUsers) HardDeleteUser(ctx context.Context, id uuid.UUID) error {
+	if err := CheckTenant(ctx, id); err != nil {
+		return err
+	}
+	return r.IUserInterface.HardDeleteUser(ctx, id)
+}
+
+type scopedAddresses struct{ IAddressInterface }
+
+func (r scopedAddresses) CreateAddress(ctx context.Context, address sqlc.CreateAddressParams) (uuid.UUID, error) {
+	if err := CheckTenant(ctx, address.UserID); err != nil {
+		return uuid.UUID{}, err
+	}
+	return r.IAddressInterface.CreateAddress(ctx, address)
+}
+
+func (r scopedAddresses) GetAddress(ctx context.Context, id, userID uuid.UUID) (*sqlc.Address, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAddressInterface.GetAddress(ctx, id, userID)
+}
+
+func (r scopedAddresses) ListAddresses(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.Address], error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return Page[sqlc.Address]{}, err
+	}
+	return r.IAddressInterface.ListAddresses(ctx, userID, page)
+}
+
+func (r scopedAddresses) SearchAddresses(ctx context.Context, userID uuid.UUID, terms []string, limit int32) ([]sqlc.SearchAddressesRow, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAddressInterface.SearchAddresses(ctx, userID, terms, limit)
+}
+
+func (r scopedAddresses) UpdateDetails(ctx context.Context, id, userID uuid.UUID, label, notes pgtype.Text, version int32) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAddressInterface.UpdateDetails(ctx, id, userID, label, notes, version)
+}
+
+func (r scopedAddresses) DeleteAddress(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAddressInterface.DeleteAddress(ctx, id, userID)
+}
+
+func (r scopedAddresses) MuteAddress(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAddressInterface.MuteAddress(ctx, id, userID, until)
+}
+
+func (r scopedAddresses) UnmuteAddress(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAddressInterface.UnmuteAddress(ctx, id, userID)
+}
+
+type scopedAlertRules struct{ IAlertRuleInterface }
+
+func (r scopedAlertRules) CreateRule(ctx context.Context, rule sqlc.CreateAlertRuleParams) (uuid.UUID, error) {
+	if err := CheckTenant(ctx, rule.UserID); err != nil {
+		return uuid.UUID{}, err
+	}
+	return r.IAlertRuleInterface.CreateRule(ctx, rule)
+}
+
+func (r scopedAlertRules) GetRule(ctx context.Context, id, userID uuid.UUID) (*sqlc.AlertRule, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAlertRuleInterface.GetRule(ctx, id, userID)
+}
+
+func (r scopedAlertRules) ListRules(ctx context.Context, userID uuid.UUID) ([]sqlc.AlertRule, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAlertRuleInterface.ListRules(ctx, userID)
+}
+
+func (r scopedAlertRules) ListEnabledRules(ctx context.Context, userID, addressID uuid.UUID) ([]sqlc.AlertRule, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAlertRuleInterface.ListEnabledRules(ctx, userID, addressID)
+}
+
+func (r scopedAlertRules) UpdateRule(ctx context.Context, rule sqlc.UpdateAlertRuleParams) error {
+	if err := CheckTenant(ctx, rule.UserID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.UpdateRule(ctx, rule)
+}
+
+func (r scopedAlertRules) DeleteRule(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.DeleteRule(ctx, id, userID)
+}
+
+func (r scopedAlertRules) MuteRule(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.MuteRule(ctx, id, userID, until)
+}
+
+func (r scopedAlertRules) UnmuteRule(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.UnmuteRule(ctx, id, userID)
+}
+
+func (r scopedAlertRules) SetTokenPreset(ctx context.Context, id, userID uuid.UUID, preset pgtype.Text) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.SetTokenPreset(ctx, id, userID, preset)
+}
+
+func (r scopedAlertRules) SetExpression(ctx context.Context, id, userID uuid.UUID, expression pgtype.Text) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.SetExpression(ctx, id, userID, expression)
+}
+
+func (r scopedAlertRules) SetPriority(ctx context.Context, id, userID uuid.UUID, priority int32, stopProcessing bool) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.SetPriority(ctx, id, userID, priority, stopProcessing)
+}
+
+func (r scopedAlertRules) SetCounterparties(ctx context.Context, id, userID uuid.UUID, addresses []string) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertRuleInterface.SetCounterparties(ctx, id, userID, addresses)
+}
+
+func (r scopedAlertRules) ListCounterparties(ctx context.Context, userID uuid.UUID, ruleIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAlertRuleInterface.ListCounterparties(ctx, userID, ruleIDs)
+}
+
+type scopedAlerts struct{ IAlertInterface }
+
+func (r scopedAlerts) CreateAlert(ctx context.Context, alert sqlc.CreateAlertParams) (uuid.UUID, error) {
+	if err := CheckTenant(ctx, alert.UserID); err != nil {
+		return uuid.UUID{}, err
+	}
+	return r.IAlertInterface.CreateAlert(ctx, alert)
+}
+
+func (r scopedAlerts) GetAlert(ctx context.Context, id, userID uuid.UUID) (*sqlc.Alert, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAlertInterface.GetAlert(ctx, id, userID)
+}
+
+func (r scopedAlerts) ListAlerts(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.Alert], error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return Page[sqlc.Alert]{}, err
+	}
+	return r.IAlertInterface.ListAlerts(ctx, userID, page)
+}
+
+func (r scopedAlerts) ListAddressAlerts(ctx context.Context, addressID, userID uuid.UUID, page PageRequest) (Page[sqlc.Alert], error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return Page[sqlc.Alert]{}, err
+	}
+	return r.IAlertInterface.ListAddressAlerts(ctx, addressID, userID, page)
+}
+
+func (r scopedAlerts) AcknowledgeAlert(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAlertInterface.AcknowledgeAlert(ctx, id, userID)
+}
+
+type scopedWebhooks struct{ IWebhookInterface }
+
+func (r scopedWebhooks) CreateWebhook(ctx context.Context, webhook sqlc.CreateWebhookParams) (uuid.UUID, error) {
+	if err := CheckTenant(ctx, webhook.UserID); err != nil {
+		return uuid.UUID{}, err
+	}
+	return r.IWebhookInterface.CreateWebhook(ctx, webhook)
+}
+
+func (r scopedWebhooks) GetWebhook(ctx context.Context, id, userID uuid.UUID) (*sqlc.Webhook, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IWebhookInterface.GetWebhook(ctx, id, userID)
+}
+
+func (r scopedWebhooks) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]sqlc.Webhook, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IWebhookInterface.ListWebhooks(ctx, userID)
+}
+
+func (r scopedWebhooks) UpdateWebhook(ctx context.Context, webhook sqlc.UpdateWebhookParams) error {
+	if err := CheckTenant(ctx, webhook.UserID); err != nil {
+		return err
+	}
+	return r.IWebhookInterface.UpdateWebhook(ctx, webhook)
+}
+
+func (r scopedWebhooks) DeleteWebhook(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IWebhookInterface.DeleteWebhook(ctx, id, userID)
+}
+
+func (r scopedWebhooks) MarkVerified(ctx context.Context, id, userID uuid.UUID, url string) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IWebhookInterface.MarkVerified(ctx, id, userID, url)
+}
+
+type scopedAPIKeys struct{ IAPIKeyInterface }
+
+func (r scopedAPIKeys) CreateKey(ctx context.Context, key sqlc.CreateApiKeyParams) (uuid.UUID, error) {
+	if err := CheckTenant(ctx, key.UserID); err != nil {
+		return uuid.UUID{}, err
+	}
+	return r.IAPIKeyInterface.CreateKey(ctx, key)
+}
+
+func (r scopedAPIKeys) ListKeys(ctx context.Context, userID uuid.UUID) ([]sqlc.ApiKey, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAPIKeyInterface.ListKeys(ctx, userID)
+}
+
+func (r scopedAPIKeys) RevokeKey(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAPIKeyInterface.RevokeKey(ctx, id, userID)
+}
+
+func (r scopedAPIKeys) SetAllowedCIDRs(ctx context.Context, id, userID uuid.UUID, cidrs []string) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAPIKeyInterface.SetAllowedCIDRs(ctx, id, userID, cidrs)
+}
+
+func (r scopedAPIKeys) SetScopes(ctx context.Context, id, userID uuid.UUID, scopes []string) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IAPIKeyInterface.SetScopes(ctx, id, userID, scopes)
+}
+
+type scopedJobs struct{ IJobInterface }
+
+// EnqueueJob checks jobs enqueued for a user. System jobs have none, on Postgres the row-level
+// security policy only admits them from contexts acting for no user.
+func (r scopedJobs) EnqueueJob(ctx context.Context, job sqlc.EnqueueJobParams) (sqlc.Job, error) {
+	if job.UserID.Valid {
+		if err := CheckTenant(ctx, job.UserID.Bytes); err != nil {
+			return sqlc.Job{}, err
+		}
+	}
+	return r.IJobInterface.EnqueueJob(ctx, job)
+}
+
+func (r scopedJobs) GetJob(ctx context.Context, id, userID uuid.UUID) (sqlc.Job, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return sqlc.Job{}, err
+	}
+	return r.IJobInterface.GetJob(ctx, id, userID)
+}
+
+func (r scopedJobs) ListJobs(ctx context.Context, userID uuid.UUID, limit int32) ([]sqlc.Job, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IJobInterface.ListJobs(ctx, userID, limit)
+}
+
+// scopedAPIUsage leaves RecordUsage unchecked, usage is recorded for many users at once by
+// package analytics from a context acting for none
+type scopedAPIUsage struct{ IAPIUsageInterface }
+
+func (r scopedAPIUsage) ListUsage(ctx context.Context, userID uuid.UUID, since time.Time) ([]sqlc.ApiUsage, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IAPIUsageInterface.ListUsage(ctx, userID, since)
+}
+
+type scopedAddressEvents struct{ IAddressEventInterface }
+
+func (r scopedAddressEvents) ListAddressEvents(ctx context.Context, addressID, userID uuid.UUID, page PageRequest) (Page[sqlc.AddressEvent], error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return Page[sqlc.AddressEvent]{}, err
+	}
+	return r.IAddressEventInterface.ListAddressEvents(ctx, addressID, userID, page)
+}
+
+func (r scopedAddressEvents) ListUserAddressEvents(ctx context.Context, userID uuid.UUID, kinds []string, page PageRequest) (Page[sqlc.AddressEvent], error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return Page[sqlc.AddressEvent]{}, err
+	}
+	return r.IAddressEventInterface.ListUserAddressEvents(ctx, userID, kinds, page)
+}
+
+type scopedAccountEvents struct{ IAccountEventInterface }
+
+func (r scopedAccountEvents) RecordEvent(ctx context.Context, event AccountEvent) error {
+	if err := CheckTenant(ctx, event.UserID); err != nil {
+		return err
+	}
+	return r.IAccountEventInterface.RecordEvent(ctx, event)
+}
+
+func (r scopedAccountEvents) ListAccountEvents(ctx context.Context, userID uuid.UUID, page PageRequest) (Page[sqlc.AccountEvent], error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return Page[sqlc.AccountEvent]{}, err
+	}
+	return r.IAccountEventInterface.ListAccountEvents(ctx, userID, page)
+}
+
+type scopedDashboard struct{ IDashboardInterface }
+
+func (r scopedDashboard) CountActivity(ctx context.Context, userID uuid.UUID, daySince, weekSince time.Time) (sqlc.GetDashboardCountsRow, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return sqlc.GetDashboardCountsRow{}, err
+	}
+	return r.IDashboardInterface.CountActivity(ctx, userID, daySince, weekSince)
+}
+
+func (r scopedDashboard) ListTopActiveAddresses(ctx context.Context, userID uuid.UUID, since time.Time, limit int32) ([]sqlc.ListTopActiveAddressesRow, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IDashboardInterface.ListTopActiveAddresses(ctx, userID, since, limit)
+}
+
+func (r scopedDashboard) ListChainAddresses(ctx context.Context, userID uuid.UUID, chain string, limit int32) ([]string, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IDashboardInterface.ListChainAddresses(ctx, userID, chain, limit)
+}
+
+type scopedBalanceWatches struct{ IBalanceWatchInterface }
+
+func (r scopedBalanceWatches) CreateWatch(ctx context.Context, watch sqlc.CreateBalanceWatchParams) (uuid.UUID, error) {
+	if err := CheckTenant(ctx, watch.UserID); err != nil {
+		return uuid.UUID{}, err
+	}
+	return r.IBalanceWatchInterface.CreateWatch(ctx, watch)
+}
+
+func (r scopedBalanceWatches) GetWatch(ctx context.Context, id, userID uuid.UUID) (*sqlc.BalanceWatch, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IBalanceWatchInterface.GetWatch(ctx, id, userID)
+}
+
+func (r scopedBalanceWatches) ListWatches(ctx context.Context, addressID, userID uuid.UUID) ([]sqlc.BalanceWatch, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.IBalanceWatchInterface.ListWatches(ctx, addressID, userID)
+}
+
+func (r scopedBalanceWatches) UpdateWatch(ctx context.Context, watch sqlc.UpdateBalanceWatchParams) error {
+	if err := CheckTenant(ctx, watch.UserID); err != nil {
+		return err
+	}
+	return r.IBalanceWatchInterface.UpdateWatch(ctx, watch)
+}
+
+func (r scopedBalanceWatches) DeleteWatch(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.IBalanceWatchInterface.DeleteWatch(ctx, id, userID)
+}
+
+type scopedSessions struct{ ISessionInterface }
+
+func (r scopedSessions) CreateSession(ctx context.Context, session sqlc.CreateSessionParams) error {
+	if err := CheckTenant(ctx, session.UserID); err != nil {
+		return err
+	}
+	return r.ISessionInterface.CreateSession(ctx, session)
+}
+
+func (r scopedSessions) CreateRefreshToken(ctx context.Context, token sqlc.CreateRefreshTokenParams) error {
+	if err := CheckTenant(ctx, token.UserID); err != nil {
+		return err
+	}
+	return r.ISessionInterface.CreateRefreshToken(ctx, token)
+}
+
+func (r scopedSessions) GetActiveSession(ctx context.Context, id, userID uuid.UUID) (*sqlc.Session, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.ISessionInterface.GetActiveSession(ctx, id, userID)
+}
+
+func (r scopedSessions) ListSessions(ctx context.Context, userID uuid.UUID) ([]sqlc.Session, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.ISessionInterface.ListSessions(ctx, userID)
+}
+
+func (r scopedSessions) RevokeSession(ctx context.Context, id, userID uuid.UUID) error {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return err
+	}
+	return r.ISessionInterface.RevokeSession(ctx, id, userID)
+}
+
+func (r scopedSessions) RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) ([]uuid.UUID, error) {
+	if err := CheckTenant(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.ISessionInterface.RevokeOtherSessions(ctx, userID, keep)
+}
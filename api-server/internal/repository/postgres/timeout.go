@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryClass groups queries that share a deadline
+type QueryClass int
+
+const (
+	// OLTP queries serve a request: single-row reads and writes that should finish quickly
+	OLTP QueryClass = iota
+	// Reporting queries scan and aggregate many rows, e.g. for dashboards and exports
+	Reporting
+)
+
+// Timeout returns the configured deadline of the query class
+func (q QueryClass) Timeout() time.Duration {
+	t := config.GetConfig().QueryTimeout
+	if q == Reporting {
+		return t.Reporting
+	}
+	return t.OLTP
+}
+
+// withQueryTimeout bounds ctx by the deadline of class, keeping an earlier deadline ctx already
+// has. Cancelling the context cancels the running query on the server.
+func withQueryTimeout(ctx context.Context, class QueryClass) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, class.Timeout())
+}
+
+// WithReportingTx runs fn in a read-only transaction under the reporting deadline, on a replica
+// when one is healthy. Connections otherwise use the OLTP statement_timeout, which the transaction
+// raises for its own statements.
+func (d *Database) WithReportingTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	ctx, cancel := withQueryTimeout(ctx, Reporting)
+	defer cancel()
+
+	tx, err := d.reader().BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	// set_config with is_local = true is SET LOCAL, which cannot take a bind parameter
+	if _, err := tx.Exec(ctx, "SELECT set_config('statement_timeout', $1, true)", statementTimeout(Reporting.Timeout())); err != nil {
+		return fmt.Errorf("failed to set statement timeout: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// statementTimeout formats d as a statement_timeout value in milliseconds
+func statementTimeout(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+)
+
+// ITransactionInterface is the detected transactions repository
+type ITransactionInterface interface {
+	// SaveTransaction stores a transfer, or updates its block and status when it was seen before
+	// (e.g. once confirmed or after a reorg), returning its ID
+	SaveTransaction(ctx context.Context, tx sqlc.UpsertTransactionParams) (uuid.UUID, error)
+	GetTransactions(ctx context.Context, chain, hash string) ([]sqlc.Transaction, error)
+	// ListTransactions returns a page of the transfers from or to address, newest first
+	ListTransactions(ctx context.Context, chain, address string, page PageRequest) (Page[sqlc.Transaction], error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+}
+
+type TransactionRepo struct {
+	db *sqlc.Queries
+}
+
+func NewTransactionRepository(db sqlc.DBTX) ITransactionInterface {
+	return &TransactionRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *TransactionRepo) SaveTransaction(ctx context.Context, tx sqlc.UpsertTransactionParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.UpsertTransaction(ctx, tx)
+}
+
+func (r *TransactionRepo) GetTransactions(ctx context.Context, chain, hash string) ([]sqlc.Transaction, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.GetTransactionsByHash(ctx, sqlc.GetTransactionsByHashParams{Chain: chain, Hash: hash})
+}
+
+func (r *TransactionRepo) ListTransactions(ctx context.Context, chain, address string, page PageRequest) (Page[sqlc.Transaction], error) {
+	q, err := page.Query()
+	if err != nil {
+		return Page[sqlc.Transaction]{}, err
+	}
+
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	rows, err := r.db.ListTransactionsByAddress(ctx, sqlc.ListTransactionsByAddressParams{
+		Chain:          chain,
+		Address:        address,
+		AfterCreatedAt: q.AfterCreatedAt,
+		AfterID:        q.AfterID,
+		PageSize:       q.Size,
+	})
+	if err != nil {
+		return Page[sqlc.Transaction]{}, err
+	}
+
+	return NewPage(q, rows, TransactionKey), nil
+}
+
+func (r *TransactionRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.UpdateTransactionStatus(ctx, sqlc.UpdateTransactionStatusParams{ID: id, Status: status})
+}
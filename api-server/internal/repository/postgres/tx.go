@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repositories are the repositories of one unit of work, all running on the same pool or
+// transaction
+type Repositories struct {
+	Users                  IUserInterface
+	Addresses              IAddressInterface
+	AlertRules             IAlertRuleInterface
+	Alerts                 IAlertInterface
+	Transactions           ITransactionInterface
+	NotificationDeliveries INotificationDeliveryInterface
+	Webhooks               IWebhookInterface
+	KnownEntities          IKnownEntityInterface
+	APIKeys                IAPIKeyInterface
+	Outbox                 IOutboxInterface
+	Archive                IArchiveInterface
+	Jobs                   IJobInterface
+	Stats                  IStatsInterface
+	APIUsage               IAPIUsageInterface
+	AddressEvents          IAddressEventInterface
+	AccountEvents          IAccountEventInterface
+	Dashboard              IDashboardInterface
+	BalanceWatches         IBalanceWatchInterface
+	Sessions               ISessionInterface
+	WatcherStatus          IWatcherStatusInterface
+}
+
+// NewRepositories creates the repositories on db, the pool or a transaction. Their errors are
+// mapped to domain errors such as ErrDuplicate, and they are scoped to the user of the request
+// context, see Scoped.
+func NewRepositories(db sqlc.DBTX) Repositories {
+	db = mappedConn{db: db}
+	return Scoped(Repositories{
+		Users:                  NewUserRepository(db),
+		Addresses:              NewAddressRepository(db),
+		AlertRules:             NewAlertRuleRepository(db),
+		Alerts:                 NewAlertRepository(db),
+		Transactions:           NewTransactionRepository(db),
+		NotificationDeliveries: NewNotificationDeliveryRepository(db),
+		Webhooks:               NewWebhookRepository(db),
+		KnownEntities:          NewKnownEntityRepository(db),
+		APIKeys:                NewAPIKeyRepository(db),
+		Outbox:                 NewOutboxRepository(db),
+		Archive:                NewArchiveRepository(db),
+		Jobs:                   NewJobRepository(db),
+		Stats:                  NewStatsRepository(db),
+		APIUsage:               NewAPIUsageRepository(db),
+		AddressEvents:          NewAddressEventRepository(db),
+		AccountEvents:          NewAccountEventRepository(db),
+		Dashboard:              NewDashboardRepository(db),
+		BalanceWatches:         NewBalanceWatchRepository(db),
+		Sessions:               NewSessionRepository(db),
+		WatcherStatus:          NewWatcherStatusRepository(db),
+	})
+}
+
+// ITxManager runs multi-step operations as one unit of work
+type ITxManager interface {
+	// WithinTx runs fn with repositories bound to a single transaction, committing when fn returns
+	// nil and rolling back every step when it returns an error
+	WithinTx(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+type TxManager struct {
+	db *Database
+}
+
+func NewTxManager(db *Database) ITxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(repos Repositories) error) error {
+	return m.db.WithTx(ctx, func(tx pgx.Tx) error {
+		return fn(NewRepositories(tx))
+	})
+}
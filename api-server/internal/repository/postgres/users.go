@@ -2,32 +2,49 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// IUserInterface is the users repository. Every method runs its queries under ctx, so they are
+// cancelled with the request and carry its deadline and trace, bounded by the OLTP query timeout.
 type IUserInterface interface {
-	CreateNewUser(user sqlc.CreateUserParams) (uuid.UUID, error)
-	GetUser(email string) (*sqlc.User, error)
-	SoftDeleteUser(id uuid.UUID) error
-	HardDeleteUser(id uuid.UUID) error
+	CreateNewUser(ctx context.Context, user sqlc.CreateUserParams) (uuid.UUID, error)
+	GetUser(ctx context.Context, email string) (*sqlc.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*sqlc.User, error)
+	// UpdateProfile fails with ErrStaleVersion when the user was changed since user.Version
+	UpdateProfile(ctx context.Context, user sqlc.UpdateUserProfileParams) error
+	// UpdatePassword replaces the user's password hash, failing with pgx.ErrNoRows when the user
+	// does not exist
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	// CreateEmailVerification stores the confirmation link of the user's email, by the SHA-256 of
+	// its token, replacing any earlier link
+	CreateEmailVerification(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error
+	// VerifyEmail consumes the link of tokenHash and marks its email verified, returning the user.
+	// It fails with pgx.ErrNoRows when there is no such link, it expired or the email changed since.
+	VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	SoftDeleteUser(ctx context.Context, id uuid.UUID) error
+	HardDeleteUser(ctx context.Context, id uuid.UUID) error
 }
 
 type UserRepo struct {
-	ctx context.Context
-	db  *sqlc.Queries
+	db *sqlc.Queries
 }
 
 func NewUserRepository(db sqlc.DBTX) IUserInterface {
 	return &UserRepo{
-		db:  sqlc.New(db),
-		ctx: context.Background(),
+		db: sqlc.New(db),
 	}
 }
 
-func (r *UserRepo) CreateNewUser(user sqlc.CreateUserParams) (uuid.UUID, error) {
-	id, err := r.db.CreateUser(r.ctx, user)
+func (r *UserRepo) CreateNewUser(ctx context.Context, user sqlc.CreateUserParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	id, err := r.db.CreateUser(ctx, user)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
@@ -35,8 +52,24 @@ func (r *UserRepo) CreateNewUser(user sqlc.CreateUserParams) (uuid.UUID, error)
 	return id, err
 }
 
-func (r *UserRepo) GetUser(email string) (*sqlc.User, error) {
-	user, err := r.db.SignInUser(r.ctx, email)
+// GetUser reads from the primary: signing in right after registering must find the new user
+func (r *UserRepo) GetUser(ctx context.Context, email string) (*sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	user, err := r.db.SignInUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	user, err := r.db.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -44,10 +77,52 @@ func (r *UserRepo) GetUser(email string) (*sqlc.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepo) SoftDeleteUser(id uuid.UUID) error {
-	return r.db.SoftDeleteUser(r.ctx, id)
+func (r *UserRepo) UpdateProfile(ctx context.Context, user sqlc.UpdateUserProfileParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return CheckVersion(expectRow(r.db.UpdateUserProfile(ctx, user)), func() error {
+		_, err := r.db.GetUserByID(ctx, user.ID)
+		return err
+	})
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{ID: id, PasswordHash: passwordHash}))
+}
+
+func (r *UserRepo) CreateEmailVerification(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateEmailVerification(ctx, sqlc.CreateEmailVerificationParams{
+		TokenHash: tokenHash,
+		UserID:    id,
+		Email:     email,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+}
+
+func (r *UserRepo) VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.VerifyEmail(ctx, tokenHash)
+}
+
+func (r *UserRepo) SoftDeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.SoftDeleteUser(ctx, id)
 }
 
-func (r *UserRepo) HardDeleteUser(id uuid.UUID) error {
-	return r.db.HardDeleteUser(r.ctx, id)
+func (r *UserRepo) HardDeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.HardDeleteUser(ctx, id)
 }
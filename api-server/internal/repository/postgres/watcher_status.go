@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+)
+
+// IWatcherStatusInterface reads the state of the engine's watch of an address, which the engine
+// writes. It is shared by every user watching the address, so callers check the address is theirs.
+type IWatcherStatusInterface interface {
+	// GetWatcherStatus fails with pgx.ErrNoRows until the engine starts watching the address
+	GetWatcherStatus(ctx context.Context, chain, address string) (*sqlc.WatcherStatus, error)
+}
+
+type WatcherStatusRepo struct {
+	db *sqlc.Queries
+}
+
+func NewWatcherStatusRepository(db sqlc.DBTX) IWatcherStatusInterface {
+	return &WatcherStatusRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *WatcherStatusRepo) GetWatcherStatus(ctx context.Context, chain, address string) (*sqlc.WatcherStatus, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	status, err := r.db.GetWatcherStatus(ctx, sqlc.GetWatcherStatusParams{Chain: chain, Address: address})
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/google/uuid"
+)
+
+// Webhook delivery modes: one POST per alert, or the alerts of batch_interval_seconds, at most
+// batch_max_events, posted together
+const (
+	WebhookSingle = "single"
+	WebhookBatch  = "batch"
+)
+
+// IWebhookInterface is the webhooks repository. Methods taking a user ID only see that user's
+// webhooks; updating or deleting another user's webhook fails with pgx.ErrNoRows. Deleted webhooks
+// are kept with deleted_at set, so past deliveries still name them, and hidden, see IncludeDeleted.
+type IWebhookInterface interface {
+	CreateWebhook(ctx context.Context, webhook sqlc.CreateWebhookParams) (uuid.UUID, error)
+	GetWebhook(ctx context.Context, id, userID uuid.UUID) (*sqlc.Webhook, error)
+	ListWebhooks(ctx context.Context, userID uuid.UUID) ([]sqlc.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook sqlc.UpdateWebhookParams) error
+	DeleteWebhook(ctx context.Context, id, userID uuid.UUID) error
+	// MarkVerified records that the webhook answered the verification challenge posted to url,
+	// failing with pgx.ErrNoRows when its URL changed meanwhile
+	MarkVerified(ctx context.Context, id, userID uuid.UUID, url string) error
+}
+
+type WebhookRepo struct {
+	db *sqlc.Queries
+}
+
+func NewWebhookRepository(db sqlc.DBTX) IWebhookInterface {
+	return &WebhookRepo{
+		db: sqlc.New(db),
+	}
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook sqlc.CreateWebhookParams) (uuid.UUID, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return r.db.CreateWebhook(ctx, webhook)
+}
+
+func (r *WebhookRepo) GetWebhook(ctx context.Context, id, userID uuid.UUID) (*sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	webhook, err := r.db.GetWebhook(ctx, sqlc.GetWebhookParams{
+		ID:             id,
+		UserID:         userID,
+		IncludeDeleted: IsIncludingDeleted(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepo) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(readOnly(ctx), OLTP)
+	defer cancel()
+
+	return r.db.ListWebhooksByUser(ctx, sqlc.ListWebhooksByUserParams{
+		UserID:         userID,
+		IncludeDeleted: IsIncludingDeleted(ctx),
+	})
+}
+
+func (r *WebhookRepo) UpdateWebhook(ctx context.Context, webhook sqlc.UpdateWebhookParams) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.UpdateWebhook(ctx, webhook))
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, id, userID uuid.UUID) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.SoftDeleteWebhook(ctx, sqlc.SoftDeleteWebhookParams{ID: id, UserID: userID}))
+}
+
+func (r *WebhookRepo) MarkVerified(ctx context.Context, id, userID uuid.UUID, url string) error {
+	ctx, cancel := withQueryTimeout(ctx, OLTP)
+	defer cancel()
+
+	return expectRow(r.db.MarkWebhookVerified(ctx, sqlc.MarkWebhookVerifiedParams{ID: id, UserID: userID, Url: url}))
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const accountEventColumns = `id, user_id, kind, detail, ip_address, user_agent, created_at`
+
+func scanAccountEvent(row scanner) (sqlc.AccountEvent, error) {
+	var e sqlc.AccountEvent
+	err := row.Scan(&e.ID, &e.UserID, &e.Kind, &e.Detail, &e.IpAddress, &e.UserAgent, &e.CreatedAt)
+	return e, err
+}
+
+// recordWebhookEvent records a change of the webhook with its URL. Like recordAddressEvent, it
+// follows the statement making the change.
+func recordWebhookEvent(ctx context.Context, db dbtx, webhookID uuid.UUID, kind string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO account_events (id, user_id, kind, detail, created_at)
+		SELECT ?, user_id, ?, json_object('webhook_id', id, 'url', url), ? FROM webhooks WHERE id = ?`,
+		uuid.New(), kind, now(), webhookID)
+	return err
+}
+
+// recordAPIKeyEvent records a change of the API key with its name and prefix, like
+// recordWebhookEvent
+func recordAPIKeyEvent(ctx context.Context, db dbtx, keyID uuid.UUID, kind string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO account_events (id, user_id, kind, detail, created_at)
+		SELECT ?, user_id, ?, json_object('api_key_id', id, 'name', name, 'prefix', prefix), ? FROM api_keys WHERE id = ?`,
+		uuid.New(), kind, now(), keyID)
+	return err
+}
+
+type AccountEventRepo struct {
+	db dbtx
+}
+
+func NewAccountEventRepository(db dbtx) postgres.IAccountEventInterface {
+	return &AccountEventRepo{
+		db: db,
+	}
+}
+
+func (r *AccountEventRepo) RecordEvent(ctx context.Context, event postgres.AccountEvent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO account_events (id, user_id, kind, detail, ip_address, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New(), event.UserID, event.Kind, eventDetail(event.Detail), optionalText(event.IPAddress),
+		optionalText(event.UserAgent), now())
+	return err
+}
+
+func (r *AccountEventRepo) ListAccountEvents(ctx context.Context, userID uuid.UUID, page postgres.PageRequest) (postgres.Page[sqlc.AccountEvent], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.AccountEvent]{}, err
+	}
+
+	rows, err := list(ctx, r.db, scanAccountEvent, `
+		SELECT `+accountEventColumns+` FROM account_events
+		WHERE user_id = ? AND (? IS NULL OR (created_at, id) < (?, ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, append([]any{userID}, after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.AccountEvent]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.AccountEventKey), nil
+}
+
+// optionalText is s, NULL when empty
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addressEventColumns = `id, user_id, address_id, rule_id, kind, detail, created_at`
+
+func scanAddressEvent(row scanner) (sqlc.AddressEvent, error) {
+	var e sqlc.AddressEvent
+	err := row.Scan(&e.ID, &e.UserID, &e.AddressID, &e.RuleID, &e.Kind, &e.Detail, &e.CreatedAt)
+	return e, err
+}
+
+// recordAddressEvent records a change of the address. The Postgres queries record it in the
+// statement making the change, here it follows that statement.
+func recordAddressEvent(ctx context.Context, db dbtx, addressID uuid.UUID, kind string, detail map[string]any) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO address_events (id, user_id, address_id, kind, detail, created_at)
+		SELECT ?, user_id, id, ?, ?, ? FROM addresses WHERE id = ?`,
+		uuid.New(), kind, eventDetail(detail), now(), addressID)
+	return err
+}
+
+// recordRuleEvent records a change of the rule, like recordAddressEvent, with the rule's name
+// added to detail
+func recordRuleEvent(ctx context.Context, db dbtx, ruleID uuid.UUID, kind string, detail map[string]any) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO address_events (id, user_id, address_id, rule_id, kind, detail, created_at)
+		SELECT ?, user_id, address_id, id, ?, json_patch(json_object('name', name), ?), ? FROM alert_rules WHERE id = ?`,
+		uuid.New(), kind, eventDetail(detail), now(), ruleID)
+	return err
+}
+
+// mutedUntil is the detail of a mute event, the end of the mute unless it lasts until unmuted
+func mutedUntil(until pgtype.Timestamptz) map[string]any {
+	if !until.Valid {
+		return nil
+	}
+	return map[string]any{"muted_until": until.Time.UTC()}
+}
+
+// eventDetail encodes the detail of an event, an empty object when it has none
+func eventDetail(detail map[string]any) string {
+	if len(detail) == 0 {
+		return "{}"
+	}
+	b, _ := json.Marshal(detail)
+	return string(b)
+}
+
+type AddressEventRepo struct {
+	db dbtx
+}
+
+func NewAddressEventRepository(db dbtx) postgres.IAddressEventInterface {
+	return &AddressEventRepo{
+		db: db,
+	}
+}
+
+func (r *AddressEventRepo) ListAddressEvents(ctx context.Context, addressID, userID uuid.UUID, page postgres.PageRequest) (postgres.Page[sqlc.AddressEvent], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.AddressEvent]{}, err
+	}
+
+	rows, err := list(ctx, r.db, scanAddressEvent, `
+		SELECT `+addressEventColumns+` FROM address_events
+		WHERE user_id = ?
+			AND (address_id = ?
+				OR address_id IS NULL AND created_at >= (SELECT a.created_at FROM addresses a WHERE a.id = ?))
+			AND (? IS NULL OR (created_at, id) < (?, ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, append([]any{userID, addressID, addressID}, after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.AddressEvent]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.AddressEventKey), nil
+}
+
+func (r *AddressEventRepo) ListUserAddressEvents(ctx context.Context, userID uuid.UUID, kinds []string, page postgres.PageRequest) (postgres.Page[sqlc.AddressEvent], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.AddressEvent]{}, err
+	}
+
+	in, args := inList(kinds)
+	rows, err := list(ctx, r.db, scanAddressEvent, `
+		SELECT `+addressEventColumns+` FROM address_events
+		WHERE user_id = ? AND kind IN `+in+`
+			AND (? IS NULL OR (created_at, id) < (?, ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, append(append([]any{userID}, args...), after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.AddressEvent]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.AddressEventKey), nil
+}
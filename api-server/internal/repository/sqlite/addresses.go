@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addressColumns = `id, user_id, chain, address, label, notes, created_at, updated_at, version, deleted_at, muted_at, muted_until`
+
+func scanAddress(row scanner) (sqlc.Address, error) {
+	var a sqlc.Address
+	err := row.Scan(&a.ID, &a.UserID, &a.Chain, &a.Address, &a.Label, &a.Notes, &a.CreatedAt, &a.UpdatedAt, &a.Version, &a.DeletedAt,
+		&a.MutedAt, &a.MutedUntil)
+	return a, err
+}
+
+type AddressRepo struct {
+	db dbtx
+}
+
+func NewAddressRepository(db dbtx) postgres.IAddressInterface {
+	return &AddressRepo{
+		db: db,
+	}
+}
+
+func (r *AddressRepo) CreateAddress(ctx context.Context, address sqlc.CreateAddressParams) (uuid.UUID, error) {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO addresses (id, user_id, chain, address, label, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		address.ID, address.UserID, address.Chain, address.Address, address.Label, address.Notes, t, t)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err := recordAddressEvent(ctx, r.db, address.ID, postgres.EventAddressCreated, nil); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return address.ID, nil
+}
+
+func (r *AddressRepo) GetAddress(ctx context.Context, id, userID uuid.UUID) (*sqlc.Address, error) {
+	address, err := get(ctx, r.db, scanAddress,
+		`SELECT `+addressColumns+` FROM addresses WHERE id = ? AND user_id = ? AND (deleted_at IS NULL OR ?)`,
+		id, userID, postgres.IsIncludingDeleted(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (r *AddressRepo) ListAddresses(ctx context.Context, userID uuid.UUID, page postgres.PageRequest) (postgres.Page[sqlc.Address], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.Address]{}, err
+	}
+
+	rows, err := list(ctx, r.db, scanAddress, `
+		SELECT `+addressColumns+` FROM addresses
+		WHERE user_id = ? AND (deleted_at IS NULL OR ?) AND (? IS NULL OR (created_at, id) > (?, ?))
+		ORDER BY created_at, id
+		LIMIT ?`, append([]any{userID, postgres.IsIncludingDeleted(ctx)}, after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.Address]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.AddressKey), nil
+}
+
+func (r *AddressRepo) ListWatchers(ctx context.Context, chain, address string) ([]sqlc.Address, error) {
+	return list(ctx, r.db, scanAddress,
+		`SELECT `+addressColumns+` FROM addresses WHERE chain = ? AND address = ? AND deleted_at IS NULL`, chain, address)
+}
+
+// SearchAddresses scans all of the user's addresses, see search
+func (r *AddressRepo) SearchAddresses(ctx context.Context, userID uuid.UUID, terms []string, limit int32) ([]sqlc.SearchAddressesRow, error) {
+	rows, err := list(ctx, r.db, func(row scanner) (sqlc.SearchAddressesRow, error) {
+		var a sqlc.SearchAddressesRow
+		err := row.Scan(&a.ID, &a.Chain, &a.Address, &a.Label, &a.Notes, &a.EntityName)
+		return a, err
+	}, `
+		SELECT a.id, a.chain, a.address, a.label, a.notes, ke.name
+		FROM addresses a
+		LEFT JOIN known_entities ke ON ke.chain = a.chain AND ke.address = a.address
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
+		ORDER BY a.created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []sqlc.SearchAddressesRow
+	for _, a := range rows {
+		fields := []searchField{{a.Label.String, 1}, {a.Notes.String, 0.4}}
+		// Like the tsvector query, every term must match the label and notes, or the entity name
+		rank, ok := rankFields(terms, fields)
+		if entityRank, entityOK := rankFields(terms, []searchField{{a.EntityName.String, 1}}); entityOK {
+			rank, ok = rank+entityRank, true
+		}
+		if !ok {
+			continue
+		}
+		a.Rank = rank
+		a.Headline = highlight(joinText(a.Label, a.Notes, a.EntityName), terms)
+		matches = append(matches, a)
+	}
+
+	return topRanked(matches, func(a sqlc.SearchAddressesRow) float32 { return a.Rank }, limit), nil
+}
+
+func (r *AddressRepo) UpdateDetails(ctx context.Context, id, userID uuid.UUID, label, notes pgtype.Text, version int32) error {
+	err := exec(ctx, r.db, `
+		UPDATE addresses SET label = ?, notes = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND version = ? AND deleted_at IS NULL`,
+		label, notes, now(), id, userID, version)
+	err = postgres.CheckVersion(err, func() error {
+		_, err := get(ctx, r.db, scanAddress,
+			`SELECT `+addressColumns+` FROM addresses WHERE id = ? AND user_id = ? AND deleted_at IS NULL`, id, userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	var detail map[string]any
+	if label.Valid {
+		detail = map[string]any{"label": label.String}
+	}
+	return recordAddressEvent(ctx, r.db, id, postgres.EventAddressUpdated, detail)
+}
+
+func (r *AddressRepo) DeleteAddress(ctx context.Context, id, userID uuid.UUID) error {
+	t := now()
+	err := exec(ctx, r.db, `UPDATE addresses SET deleted_at = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		t, id, userID)
+	if err != nil {
+		return err
+	}
+	// Like the Postgres query, the rules of the address are deleted with it
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE alert_rules SET deleted_at = ? WHERE address_id = ? AND user_id = ? AND deleted_at IS NULL`,
+		t, id, userID)
+	if err != nil {
+		return err
+	}
+	return recordAddressEvent(ctx, r.db, id, postgres.EventAddressDeleted, nil)
+}
+
+func (r *AddressRepo) MuteAddress(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error {
+	err := exec(ctx, r.db, `UPDATE addresses SET muted_at = ?, muted_until = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		now(), timestamp(until), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordAddressEvent(ctx, r.db, id, postgres.EventAddressMuted, mutedUntil(until))
+}
+
+func (r *AddressRepo) UnmuteAddress(ctx context.Context, id, userID uuid.UUID) error {
+	err := exec(ctx, r.db, `UPDATE addresses SET muted_at = NULL, muted_until = NULL WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+	return recordAddressEvent(ctx, r.db, id, postgres.EventAddressUnmuted, nil)
+}
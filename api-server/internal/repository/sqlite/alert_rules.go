@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const alertRuleColumns = `id, user_id, address_id, name, direction, min_value, token_address, cooldown_seconds, enabled, created_at, updated_at, version, deleted_at, muted_at, muted_until, severity, token_preset, expression, priority, stop_processing`
+
+func scanAlertRule(row scanner) (sqlc.AlertRule, error) {
+	var r sqlc.AlertRule
+	err := row.Scan(&r.ID, &r.UserID, &r.AddressID, &r.Name, &r.Direction, &r.MinValue, &r.TokenAddress,
+		&r.CooldownSeconds, &r.Enabled, &r.CreatedAt, &r.UpdatedAt, &r.Version, &r.DeletedAt,
+		&r.MutedAt, &r.MutedUntil, &r.Severity, &r.TokenPreset, &r.Expression, &r.Priority, &r.StopProcessing)
+	return r, err
+}
+
+type AlertRuleRepo struct {
+	db dbtx
+}
+
+func NewAlertRuleRepository(db dbtx) postgres.IAlertRuleInterface {
+	return &AlertRuleRepo{
+		db: db,
+	}
+}
+
+func (r *AlertRuleRepo) CreateRule(ctx context.Context, rule sqlc.CreateAlertRuleParams) (uuid.UUID, error) {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_rules (id, user_id, address_id, name, direction, min_value, token_address, cooldown_seconds, enabled, severity, expression, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.UserID, rule.AddressID, rule.Name, rule.Direction, rule.MinValue, rule.TokenAddress,
+		rule.CooldownSeconds, rule.Enabled, rule.Severity, rule.Expression, t, t)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err := recordRuleEvent(ctx, r.db, rule.ID, postgres.EventRuleCreated, nil); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return rule.ID, nil
+}
+
+func (r *AlertRuleRepo) GetRule(ctx context.Context, id, userID uuid.UUID) (*sqlc.AlertRule, error) {
+	rule, err := get(ctx, r.db, scanAlertRule,
+		`SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = ? AND user_id = ? AND (deleted_at IS NULL OR ?)`,
+		id, userID, postgres.IsIncludingDeleted(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *AlertRuleRepo) ListRules(ctx context.Context, userID uuid.UUID) ([]sqlc.AlertRule, error) {
+	return list(ctx, r.db, scanAlertRule,
+		`SELECT `+alertRuleColumns+` FROM alert_rules WHERE user_id = ? AND (deleted_at IS NULL OR ?) ORDER BY created_at`,
+		userID, postgres.IsIncludingDeleted(ctx))
+}
+
+func (r *AlertRuleRepo) ListEnabledRules(ctx context.Context, userID, addressID uuid.UUID) ([]sqlc.AlertRule, error) {
+	return list(ctx, r.db, scanAlertRule, `
+		SELECT `+alertRuleColumns+` FROM alert_rules
+		WHERE user_id = ? AND enabled AND deleted_at IS NULL AND (address_id = ? OR address_id IS NULL)
+		ORDER BY priority DESC, created_at, id`, userID, addressID)
+}
+
+func (r *AlertRuleRepo) UpdateRule(ctx context.Context, rule sqlc.UpdateAlertRuleParams) error {
+	// Whether the rule was enabled, to tell pausing and resuming it from other edits
+	wasEnabled, err := get(ctx, r.db, func(row scanner) (bool, error) {
+		var enabled bool
+		err := row.Scan(&enabled)
+		return enabled, err
+	}, `SELECT enabled FROM alert_rules WHERE id = ? AND user_id = ? AND deleted_at IS NULL`, rule.ID, rule.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = exec(ctx, r.db, `
+		UPDATE alert_rules
+		SET name = ?, direction = ?, min_value = ?, token_address = ?, cooldown_seconds = ?, enabled = ?,
+			severity = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND version = ? AND deleted_at IS NULL`,
+		rule.Name, rule.Direction, rule.MinValue, rule.TokenAddress, rule.CooldownSeconds, rule.Enabled, rule.Severity, now(),
+		rule.ID, rule.UserID, rule.Version)
+	err = postgres.CheckVersion(err, func() error {
+		_, err := get(ctx, r.db, scanAlertRule,
+			`SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+			rule.ID, rule.UserID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	kind := postgres.EventRuleUpdated
+	switch {
+	case rule.Enabled && !wasEnabled:
+		kind = postgres.EventRuleResumed
+	case !rule.Enabled && wasEnabled:
+		kind = postgres.EventRulePaused
+	}
+	return recordRuleEvent(ctx, r.db, rule.ID, kind, nil)
+}
+
+func (r *AlertRuleRepo) DeleteRule(ctx context.Context, id, userID uuid.UUID) error {
+	err := exec(ctx, r.db, `UPDATE alert_rules SET deleted_at = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		now(), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleDeleted, nil)
+}
+
+func (r *AlertRuleRepo) MuteRule(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error {
+	err := exec(ctx, r.db, `UPDATE alert_rules SET muted_at = ?, muted_until = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		now(), timestamp(until), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleMuted, mutedUntil(until))
+}
+
+func (r *AlertRuleRepo) UnmuteRule(ctx context.Context, id, userID uuid.UUID) error {
+	err := exec(ctx, r.db, `UPDATE alert_rules SET muted_at = NULL, muted_until = NULL WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleUnmuted, nil)
+}
+
+func (r *AlertRuleRepo) SetTokenPreset(ctx context.Context, id, userID uuid.UUID, preset pgtype.Text) error {
+	err := exec(ctx, r.db, `
+		UPDATE alert_rules SET token_preset = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL AND token_address IS NULL`,
+		preset, now(), id, userID)
+	if err != nil {
+		return err
+	}
+	var detail map[string]any
+	if preset.Valid {
+		detail = map[string]any{"token_preset": preset.String}
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleUpdated, detail)
+}
+
+func (r *AlertRuleRepo) SetExpression(ctx context.Context, id, userID uuid.UUID, expression pgtype.Text) error {
+	err := exec(ctx, r.db, `
+		UPDATE alert_rules SET expression = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		expression, now(), id, userID)
+	if err != nil {
+		return err
+	}
+	var detail map[string]any
+	if expression.Valid {
+		detail = map[string]any{"expression": expression.String}
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleUpdated, detail)
+}
+
+func (r *AlertRuleRepo) SetPriority(ctx context.Context, id, userID uuid.UUID, priority int32, stopProcessing bool) error {
+	err := exec(ctx, r.db, `
+		UPDATE alert_rules SET priority = ?, stop_processing = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		priority, stopProcessing, now(), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleUpdated, map[string]any{
+		"priority":        priority,
+		"stop_processing": stopProcessing,
+	})
+}
+
+func (r *AlertRuleRepo) SetCounterparties(ctx context.Context, id, userID uuid.UUID, addresses []string) error {
+	err := exec(ctx, r.db, `
+		UPDATE alert_rules SET version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		now(), id, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM alert_rule_counterparties WHERE rule_id = ?`, id); err != nil {
+		return err
+	}
+	t := now()
+	for _, address := range addresses {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO alert_rule_counterparties (rule_id, user_id, address, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT DO NOTHING`,
+			id, userID, address, t)
+		if err != nil {
+			return err
+		}
+	}
+	return recordRuleEvent(ctx, r.db, id, postgres.EventRuleUpdated, map[string]any{"counterparties": len(addresses)})
+}
+
+func (r *AlertRuleRepo) ListCounterparties(ctx context.Context, userID uuid.UUID, ruleIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	counterparties := make(map[uuid.UUID][]string)
+	if len(ruleIDs) == 0 {
+		return counterparties, nil
+	}
+	in, args := inList(ruleIDs)
+	rows, err := list(ctx, r.db, func(row scanner) (sqlc.ListAlertRuleCounterpartiesRow, error) {
+		var c sqlc.ListAlertRuleCounterpartiesRow
+		err := row.Scan(&c.RuleID, &c.Address)
+		return c, err
+	}, `SELECT rule_id, address FROM alert_rule_counterparties WHERE user_id = ? AND rule_id IN `+in+` ORDER BY rule_id, address`,
+		append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counterparties[row.RuleID] = append(counterparties[row.RuleID], row.Address)
+	}
+	return counterparties, nil
+}
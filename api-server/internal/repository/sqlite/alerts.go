@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const alertColumns = `id, user_id, address_id, rule_id, transaction_id, message, acknowledged_at, created_at, severity`
+
+func scanAlert(row scanner) (sqlc.Alert, error) {
+	var a sqlc.Alert
+	err := row.Scan(&a.ID, &a.UserID, &a.AddressID, &a.RuleID, &a.TransactionID, &a.Message, &a.AcknowledgedAt,
+		&a.CreatedAt, &a.Severity)
+	return a, err
+}
+
+type AlertRepo struct {
+	db dbtx
+}
+
+func NewAlertRepository(db dbtx) postgres.IAlertInterface {
+	return &AlertRepo{
+		db: db,
+	}
+}
+
+func (r *AlertRepo) CreateAlert(ctx context.Context, alert sqlc.CreateAlertParams) (uuid.UUID, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alerts (id, user_id, address_id, rule_id, transaction_id, message, severity, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		alert.ID, alert.UserID, alert.AddressID, alert.RuleID, alert.TransactionID, alert.Message, alert.Severity, now())
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return alert.ID, nil
+}
+
+func (r *AlertRepo) GetAlert(ctx context.Context, id, userID uuid.UUID) (*sqlc.Alert, error) {
+	alert, err := get(ctx, r.db, scanAlert,
+		`SELECT `+alertColumns+` FROM alerts WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+func (r *AlertRepo) ListAlerts(ctx context.Context, userID uuid.UUID, page postgres.PageRequest) (postgres.Page[sqlc.Alert], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.Alert]{}, err
+	}
+
+	rows, err := list(ctx, r.db, scanAlert, `
+		SELECT `+alertColumns+` FROM alerts
+		WHERE user_id = ? AND (? IS NULL OR (created_at, id) < (?, ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, append([]any{userID}, after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.Alert]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.AlertKey), nil
+}
+
+func (r *AlertRepo) ListAddressAlerts(ctx context.Context, addressID, userID uuid.UUID, page postgres.PageRequest) (postgres.Page[sqlc.Alert], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.Alert]{}, err
+	}
+
+	rows, err := list(ctx, r.db, scanAlert, `
+		SELECT `+alertColumns+` FROM alerts
+		WHERE address_id = ? AND user_id = ? AND (? IS NULL OR (created_at, id) < (?, ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, append([]any{addressID, userID}, after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.Alert]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.AlertKey), nil
+}
+
+func (r *AlertRepo) AcknowledgeAlert(ctx context.Context, id, userID uuid.UUID) error {
+	return exec(ctx, r.db, `
+		UPDATE alerts SET acknowledged_at = ?
+		WHERE id = ? AND user_id = ? AND acknowledged_at IS NULL`, now(), id, userID)
+}
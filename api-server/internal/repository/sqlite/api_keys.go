@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, last_used_at, expires_at, revoked_at, created_at, allowed_cidrs, scopes, signing_secret_encrypted`
+
+func scanAPIKey(row scanner) (sqlc.ApiKey, error) {
+	var k sqlc.ApiKey
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.LastUsedAt, &k.ExpiresAt, &k.RevokedAt,
+		&k.CreatedAt, (*stringList)(&k.AllowedCidrs), (*stringList)(&k.Scopes), &k.SigningSecretEncrypted)
+	return k, err
+}
+
+type APIKeyRepo struct {
+	db dbtx
+}
+
+func NewAPIKeyRepository(db dbtx) postgres.IAPIKeyInterface {
+	return &APIKeyRepo{
+		db: db,
+	}
+}
+
+func (r *APIKeyRepo) CreateKey(ctx context.Context, key sqlc.CreateApiKeyParams) (uuid.UUID, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, expires_at, allowed_cidrs, scopes, signing_secret_encrypted, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, timestamp(key.ExpiresAt), stringList(key.AllowedCidrs),
+		stringList(key.Scopes), key.SigningSecretEncrypted, now())
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err := recordAPIKeyEvent(ctx, r.db, key.ID, postgres.EventAPIKeyCreated); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return key.ID, nil
+}
+
+func (r *APIKeyRepo) GetActiveKey(ctx context.Context, keyHash string) (*sqlc.ApiKey, error) {
+	key, err := get(ctx, r.db, scanAPIKey, `
+		SELECT `+apiKeyColumns+` FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)`, keyHash, now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) GetActiveKeyByID(ctx context.Context, id uuid.UUID) (*sqlc.ApiKey, error) {
+	key, err := get(ctx, r.db, scanAPIKey, `
+		SELECT `+apiKeyColumns+` FROM api_keys
+		WHERE id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)`, id, now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) UseNonce(ctx context.Context, keyID uuid.UUID, nonce string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM request_nonces WHERE key_id = ? AND expires_at < ?`, keyID, now())
+	if err != nil {
+		return err
+	}
+	return exec(ctx, r.db, `INSERT OR IGNORE INTO request_nonces (key_id, nonce, expires_at) VALUES (?, ?, ?)`,
+		keyID, nonce, expiresAt.UTC())
+}
+
+func (r *APIKeyRepo) ListKeys(ctx context.Context, userID uuid.UUID) ([]sqlc.ApiKey, error) {
+	return list(ctx, r.db, scanAPIKey,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY created_at`, userID)
+}
+
+func (r *APIKeyRepo) TouchKey(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, now(), id)
+	return err
+}
+
+func (r *APIKeyRepo) RevokeKey(ctx context.Context, id, userID uuid.UUID) error {
+	err := exec(ctx, r.db, `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		now(), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordAPIKeyEvent(ctx, r.db, id, postgres.EventAPIKeyRevoked)
+}
+
+func (r *APIKeyRepo) SetAllowedCIDRs(ctx context.Context, id, userID uuid.UUID, cidrs []string) error {
+	err := exec(ctx, r.db, `UPDATE api_keys SET allowed_cidrs = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		stringList(cidrs), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordAPIKeyEvent(ctx, r.db, id, postgres.EventAPIKeyUpdated)
+}
+
+func (r *APIKeyRepo) SetScopes(ctx context.Context, id, userID uuid.UUID, scopes []string) error {
+	err := exec(ctx, r.db, `UPDATE api_keys SET scopes = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		stringList(scopes), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordAPIKeyEvent(ctx, r.db, id, postgres.EventAPIKeyUpdated)
+}
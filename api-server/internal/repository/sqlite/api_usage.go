@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const apiUsageColumns = `user_id, day, method, route, calls, client_errors, server_errors, total_latency_us, max_latency_us`
+
+func scanAPIUsage(row scanner) (sqlc.ApiUsage, error) {
+	var u sqlc.ApiUsage
+	err := row.Scan(&u.UserID, &u.Day, &u.Method, &u.Route, &u.Calls, &u.ClientErrors, &u.ServerErrors,
+		&u.TotalLatencyUs, &u.MaxLatencyUs)
+	return u, err
+}
+
+// day is the UTC date of t as stored, YYYY-MM-DD text that compares in date order
+func day(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+type APIUsageRepo struct {
+	db dbtx
+}
+
+func NewAPIUsageRepository(db dbtx) postgres.IAPIUsageInterface {
+	return &APIUsageRepo{
+		db: db,
+	}
+}
+
+func (r *APIUsageRepo) RecordUsage(ctx context.Context, usage sqlc.RecordApiUsageParams) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_usage (`+apiUsageColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, day, method, route) DO UPDATE
+		SET calls = calls + excluded.calls,
+			client_errors = client_errors + excluded.client_errors,
+			server_errors = server_errors + excluded.server_errors,
+			total_latency_us = total_latency_us + excluded.total_latency_us,
+			max_latency_us = MAX(max_latency_us, excluded.max_latency_us)`,
+		usage.UserID, date(usage.Day), usage.Method, usage.Route, usage.Calls, usage.ClientErrors,
+		usage.ServerErrors, usage.TotalLatencyUs, usage.MaxLatencyUs)
+	return err
+}
+
+func (r *APIUsageRepo) ListUsage(ctx context.Context, userID uuid.UUID, since time.Time) ([]sqlc.ApiUsage, error) {
+	return list(ctx, r.db, scanAPIUsage, `
+		SELECT `+apiUsageColumns+` FROM api_usage
+		WHERE user_id = ? AND day >= ?
+		ORDER BY day, method, route`, userID, day(since))
+}
+
+func (r *APIUsageRepo) DeleteUsageBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_usage WHERE day < ?`, day(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// date converts a date parameter to its stored text, see day
+func date(d pgtype.Date) any {
+	if !d.Valid {
+		return nil
+	}
+	return day(d.Time)
+}
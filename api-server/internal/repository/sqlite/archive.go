@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ArchiveRepo struct {
+	db dbtx
+}
+
+func NewArchiveRepository(db dbtx) postgres.IArchiveInterface {
+	return &ArchiveRepo{
+		db: db,
+	}
+}
+
+func (r *ArchiveRepo) ExportUsers(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.User, error) {
+	return list(ctx, r.db, scanUser, `
+		SELECT `+userColumns+` FROM users
+		WHERE deleted_at IS NULL AND (? IS NULL OR id > ?)
+		ORDER BY id
+		LIMIT ?`, after, after, limit)
+}
+
+func (r *ArchiveRepo) ExportAddresses(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.Address, error) {
+	return list(ctx, r.db, scanAddress, `
+		SELECT `+addressColumns+` FROM addresses
+		WHERE deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM users u WHERE u.id = addresses.user_id AND u.deleted_at IS NULL)
+			AND (? IS NULL OR id > ?)
+		ORDER BY id
+		LIMIT ?`, after, after, limit)
+}
+
+func (r *ArchiveRepo) ExportAlertRules(ctx context.Context, after pgtype.UUID, limit int32) ([]sqlc.AlertRule, error) {
+	return list(ctx, r.db, scanAlertRule, `
+		SELECT `+alertRuleColumns+` FROM alert_rules
+		WHERE deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM users u WHERE u.id = alert_rules.user_id AND u.deleted_at IS NULL)
+			AND (? IS NULL OR id > ?)
+		ORDER BY id
+		LIMIT ?`, after, after, limit)
+}
+
+func (r *ArchiveRepo) ImportUser(ctx context.Context, user sqlc.ImportUserParams) (bool, error) {
+	return imported(r.db.ExecContext(ctx, `
+		INSERT INTO users (id, email, password_hash, phone_number, wallet_address, subscribed, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		user.ID, user.Email, user.PasswordHash, user.PhoneNumber, user.WalletAddress, user.Subscribed,
+		timestamp(user.CreatedAt), timestamp(user.UpdatedAt)))
+}
+
+func (r *ArchiveRepo) ImportAddress(ctx context.Context, address sqlc.ImportAddressParams) (bool, error) {
+	return imported(r.db.ExecContext(ctx, `
+		INSERT INTO addresses (id, user_id, chain, address, label, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		address.ID, address.UserID, address.Chain, address.Address, address.Label, address.Notes,
+		timestamp(address.CreatedAt), timestamp(address.UpdatedAt)))
+}
+
+func (r *ArchiveRepo) ImportAlertRule(ctx context.Context, rule sqlc.ImportAlertRuleParams) (bool, error) {
+	return imported(r.db.ExecContext(ctx, `
+		INSERT INTO alert_rules (id, user_id, address_id, name, direction, min_value, token_address, cooldown_seconds, enabled, severity, created_at, updated_at, token_preset, expression, priority, stop_processing)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		rule.ID, rule.UserID, rule.AddressID, rule.Name, rule.Direction, rule.MinValue, rule.TokenAddress,
+		rule.CooldownSeconds, rule.Enabled, rule.Severity, timestamp(rule.CreatedAt), timestamp(rule.UpdatedAt), rule.TokenPreset,
+		rule.Expression, rule.Priority, rule.StopProcessing))
+}
+
+// imported reports whether an insert that ignores conflicts created its row
+func imported(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
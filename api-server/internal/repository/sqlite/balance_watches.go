@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const balanceWatchColumns = `id, user_id, address_id, token_address, interval_seconds, min_delta, severity, enabled, balance, block_number, last_error, checked_at, next_check_at, created_at, updated_at`
+
+func scanBalanceWatch(row scanner) (sqlc.BalanceWatch, error) {
+	var w sqlc.BalanceWatch
+	err := row.Scan(&w.ID, &w.UserID, &w.AddressID, &w.TokenAddress, &w.IntervalSeconds, &w.MinDelta, &w.Severity,
+		&w.Enabled, &w.Balance, &w.BlockNumber, &w.LastError, &w.CheckedAt, &w.NextCheckAt, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+// dueBalanceWatch is a claimed watch with its interval, which schedules its next check
+type dueBalanceWatch struct {
+	sqlc.ClaimDueBalanceWatchesRow
+	interval int32
+}
+
+type BalanceWatchRepo struct {
+	db dbtx
+}
+
+func NewBalanceWatchRepository(db dbtx) postgres.IBalanceWatchInterface {
+	return &BalanceWatchRepo{
+		db: db,
+	}
+}
+
+func (r *BalanceWatchRepo) CreateWatch(ctx context.Context, watch sqlc.CreateBalanceWatchParams) (uuid.UUID, error) {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO balance_watches (id, user_id, address_id, token_address, interval_seconds, min_delta, severity, enabled,
+			next_check_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		watch.ID, watch.UserID, watch.AddressID, watch.TokenAddress, watch.IntervalSeconds, watch.MinDelta, watch.Severity,
+		watch.Enabled, t, t, t)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	err = recordAddressEvent(ctx, r.db, watch.AddressID, postgres.EventBalanceWatched,
+		map[string]any{"watch_id": watch.ID, "token_address": watch.TokenAddress})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return watch.ID, nil
+}
+
+func (r *BalanceWatchRepo) GetWatch(ctx context.Context, id, userID uuid.UUID) (*sqlc.BalanceWatch, error) {
+	watch, err := get(ctx, r.db, scanBalanceWatch,
+		`SELECT `+balanceWatchColumns+` FROM balance_watches WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &watch, nil
+}
+
+func (r *BalanceWatchRepo) ListWatches(ctx context.Context, addressID, userID uuid.UUID) ([]sqlc.BalanceWatch, error) {
+	return list(ctx, r.db, scanBalanceWatch,
+		`SELECT `+balanceWatchColumns+` FROM balance_watches WHERE address_id = ? AND user_id = ? ORDER BY created_at, id`,
+		addressID, userID)
+}
+
+func (r *BalanceWatchRepo) UpdateWatch(ctx context.Context, watch sqlc.UpdateBalanceWatchParams) error {
+	t := now()
+	return exec(ctx, r.db, `
+		UPDATE balance_watches
+		SET interval_seconds = ?, min_delta = ?, severity = ?, enabled = ?,
+			next_check_at = CASE WHEN interval_seconds = ? THEN next_check_at ELSE ? END, updated_at = ?
+		WHERE id = ? AND user_id = ?`,
+		watch.IntervalSeconds, watch.MinDelta, watch.Severity, watch.Enabled, watch.IntervalSeconds, t, t,
+		watch.ID, watch.UserID)
+}
+
+func (r *BalanceWatchRepo) DeleteWatch(ctx context.Context, id, userID uuid.UUID) error {
+	watch, err := r.GetWatch(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := exec(ctx, r.db, `DELETE FROM balance_watches WHERE id = ? AND user_id = ?`, id, userID); err != nil {
+		return err
+	}
+	return recordAddressEvent(ctx, r.db, watch.AddressID, postgres.EventBalanceUnwatched,
+		map[string]any{"watch_id": watch.ID, "token_address": watch.TokenAddress})
+}
+
+// ClaimDueWatches reads the due watches, then schedules them. SQLite allows one writer at a time,
+// so no other poller claims them in between.
+func (r *BalanceWatchRepo) ClaimDueWatches(ctx context.Context, chain string, limit int32) ([]sqlc.ClaimDueBalanceWatchesRow, error) {
+	t := now()
+	due, err := list(ctx, r.db, func(row scanner) (dueBalanceWatch, error) {
+		var w dueBalanceWatch
+		err := row.Scan(&w.ID, &w.UserID, &w.AddressID, &w.Address, &w.TokenAddress, &w.MinDelta, &w.Severity,
+			&w.Balance, &w.BlockNumber, &w.interval)
+		return w, err
+	}, `
+		SELECT w.id, w.user_id, w.address_id, a.address, w.token_address, w.min_delta, w.severity, w.balance,
+			w.block_number, w.interval_seconds
+		FROM balance_watches w
+		JOIN addresses a ON a.id = w.address_id
+		WHERE w.enabled AND w.next_check_at <= ? AND a.chain = ? AND a.deleted_at IS NULL
+		ORDER BY w.next_check_at
+		LIMIT ?`, t, chain, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	watches := make([]sqlc.ClaimDueBalanceWatchesRow, len(due))
+	for i, w := range due {
+		next := t.Add(time.Duration(w.interval) * time.Second)
+		if err := exec(ctx, r.db, `UPDATE balance_watches SET next_check_at = ? WHERE id = ?`, next, w.ID); err != nil {
+			return nil, err
+		}
+		watches[i] = w.ClaimDueBalanceWatchesRow
+	}
+	return watches, nil
+}
+
+func (r *BalanceWatchRepo) RecordBalance(ctx context.Context, id uuid.UUID, balance pgtype.Numeric, blockNumber int64) error {
+	return exec(ctx, r.db, `
+		UPDATE balance_watches
+		SET balance = ?, block_number = ?, last_error = NULL, checked_at = ?
+		WHERE id = ? AND (block_number IS NULL OR block_number <= ?)`,
+		balance, blockNumber, now(), id, blockNumber)
+}
+
+func (r *BalanceWatchRepo) RecordCheck(ctx context.Context, id uuid.UUID, lastError pgtype.Text) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE balance_watches SET last_error = ?, checked_at = ? WHERE id = ?`,
+		lastError, now(), id)
+	return err
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+type DashboardRepo struct {
+	db dbtx
+}
+
+func NewDashboardRepository(db dbtx) postgres.IDashboardInterface {
+	return &DashboardRepo{
+		db: db,
+	}
+}
+
+func (r *DashboardRepo) CountActivity(ctx context.Context, userID uuid.UUID, daySince, weekSince time.Time) (sqlc.GetDashboardCountsRow, error) {
+	return get(ctx, r.db, func(row scanner) (sqlc.GetDashboardCountsRow, error) {
+		var c sqlc.GetDashboardCountsRow
+		err := row.Scan(&c.Addresses, &c.MutedAddresses, &c.AlertsLastDay, &c.AlertsLastWeek)
+		return c, err
+	}, `
+		SELECT
+			(SELECT COUNT(*) FROM addresses WHERE user_id = ? AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM addresses WHERE user_id = ? AND deleted_at IS NULL
+				AND muted_at IS NOT NULL AND (muted_until IS NULL OR muted_until > ?)),
+			(SELECT COUNT(*) FROM alerts WHERE user_id = ? AND created_at >= ?),
+			(SELECT COUNT(*) FROM alerts WHERE user_id = ? AND created_at >= ?)`,
+		userID, userID, now(), userID, daySince.UTC(), userID, weekSince.UTC())
+}
+
+// ListTopActiveAddresses takes the last transaction's time as a bare column, like
+// StatsRepo.ListChainHeads, so it is returned as a timestamp
+func (r *DashboardRepo) ListTopActiveAddresses(ctx context.Context, userID uuid.UUID, since time.Time, limit int32) ([]sqlc.ListTopActiveAddressesRow, error) {
+	t := since.UTC()
+	return list(ctx, r.db, func(row scanner) (sqlc.ListTopActiveAddressesRow, error) {
+		var a sqlc.ListTopActiveAddressesRow
+		err := row.Scan(&a.ID, &a.Chain, &a.Address, &a.Label, &a.Transactions, &a.LastTransactionAt, &a.Alerts)
+		return a, err
+	}, `
+		SELECT a.id, a.chain, a.address, a.label, t.transactions, t.last_transaction_at,
+			(SELECT COUNT(*) FROM alerts al WHERE al.address_id = a.id AND al.created_at >= ?)
+		FROM addresses a
+		JOIN (
+			SELECT a.id AS address_id, COUNT(*) AS transactions, MAX(tx.created_at), tx.created_at AS last_transaction_at
+			FROM addresses a
+			JOIN transactions tx ON tx.chain = a.chain AND (tx.from_address = a.address OR tx.to_address = a.address)
+			WHERE a.user_id = ? AND a.deleted_at IS NULL AND tx.created_at >= ?
+			GROUP BY a.id
+		) t ON t.address_id = a.id
+		ORDER BY t.transactions DESC, a.created_at, a.id
+		LIMIT ?`, t, userID, t, limit)
+}
+
+func (r *DashboardRepo) ListChainAddresses(ctx context.Context, userID uuid.UUID, chain string, limit int32) ([]string, error) {
+	return list(ctx, r.db, func(row scanner) (string, error) {
+		var address string
+		err := row.Scan(&address)
+		return address, err
+	}, `SELECT address FROM addresses WHERE user_id = ? AND chain = ? AND deleted_at IS NULL ORDER BY created_at, id LIMIT ?`,
+		userID, chain, limit)
+}
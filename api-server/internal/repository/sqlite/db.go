@@ -0,0 +1,178 @@
+// Package sqlite implements the repository interfaces of package postgres on SQLite, so the
+// api-server can run locally without provisioning Postgres (DB_DRIVER=sqlite). Replica routing,
+// query timeouts and row locking are Postgres features and have no effect here.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+// Database is a SQLite database with the api-server's schema
+type Database struct {
+	DB *sql.DB
+}
+
+// dbtx is implemented by *sql.DB and *sql.Tx, so repositories run on either
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Open opens the SQLite database at url, a file name or ":memory:", and creates the tables that do
+// not exist yet
+func Open(url string) (*Database, error) {
+	if url == "" {
+		return nil, errors.New("database URL is empty")
+	}
+	// Foreign keys are off by default in SQLite
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", url+sep+"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time, and an in-memory database exists per connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return &Database{DB: db}, nil
+}
+
+// WithTx runs fn in a transaction, committing when fn returns nil and rolling back when it returns
+// an error or panics
+func (d *Database) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rolling back a committed transaction is a no-op
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+	return nil
+}
+
+// Close closes the database
+func (d *Database) Close() {
+	d.DB.Close()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// get runs a query returning one row, converting sql.ErrNoRows into the pgx.ErrNoRows the
+// repository interfaces return for missing records, and mapping other errors with mapError
+func get[T any](ctx context.Context, db dbtx, scan func(scanner) (T, error), query string, args ...any) (T, error) {
+	v, err := scan(db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pgx.ErrNoRows
+	}
+	return v, mapError(err)
+}
+
+// list runs a query returning any number of rows
+func list[T any](ctx context.Context, db dbtx, scan func(scanner) (T, error), query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, mapError(rows.Err())
+}
+
+// exec runs a statement that must change a row, failing with pgx.ErrNoRows when it matched none
+func exec(ctx context.Context, db dbtx, query string, args ...any) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// stringList is a list of strings stored as a JSON array, for the TEXT[] columns of Postgres
+type stringList []string
+
+func (l stringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = stringList{}
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+func (l *stringList) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	}
+	return fmt.Errorf("cannot scan %T into a string list", src)
+}
+
+// now is the current time as stored: timestamps are kept in UTC so they compare as text
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// timestamp converts a nullable timestamp parameter to UTC, see now
+func timestamp(t pgtype.Timestamptz) any {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.UTC()
+}
+
+// after returns the arguments of a keyset condition `(? IS NULL OR (created_at, id) > (?, ?))`
+// followed by the page size
+func after(q postgres.PageQuery) []any {
+	createdAt := timestamp(q.AfterCreatedAt)
+	return []any{createdAt, createdAt, q.AfterID, q.Size}
+}
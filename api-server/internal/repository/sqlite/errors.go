@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// mapError converts SQLite constraint violations and lock timeouts into the *postgres.Error the
+// repository interfaces return, returning any other error unchanged
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return &postgres.Error{Kind: postgres.ErrDuplicate, Constraint: constraintColumns(err), Err: err}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return &postgres.Error{Kind: postgres.ErrMissingReference, Err: err}
+	case sqlite3.SQLITE_BUSY:
+		return &postgres.Error{Kind: postgres.ErrConflict, Err: err}
+	}
+	return err
+}
+
+// constraintColumns returns the columns of a unique constraint error, e.g. "users.email" for
+// "UNIQUE constraint failed: users.email (2067)". SQLite does not report the index name.
+func constraintColumns(err error) string {
+	msg := err.Error()
+	_, columns, ok := strings.Cut(msg, "constraint failed: ")
+	if !ok {
+		return ""
+	}
+	columns, _, _ = strings.Cut(columns, " (")
+	return columns
+}
+
+// mappedDB maps the errors of the statements run on db. Errors of QueryRowContext surface in
+// Scan and are mapped by get.
+type mappedDB struct {
+	dbtx
+}
+
+func (db mappedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	result, err := db.dbtx.ExecContext(ctx, query, args...)
+	return result, mapError(err)
+}
+
+func (db mappedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := db.dbtx.QueryContext(ctx, query, args...)
+	return rows, mapError(err)
+}
@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/dbstats"
+)
+
+var _ postgres.IHealthInterface = (*Database)(nil)
+
+func (d *Database) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	err := d.DB.PingContext(ctx)
+	return time.Since(start), err
+}
+
+func (d *Database) Stats() postgres.DatabaseStats {
+	return postgres.DatabaseStats{Driver: "sqlite", Primary: dbstats.FromDB(d.DB.Stats())}
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const jobColumns = `id, user_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, last_error, result, started_at, finished_at, created_at, updated_at`
+
+func scanJob(row scanner) (sqlc.Job, error) {
+	var j sqlc.Job
+	err := row.Scan(&j.ID, &j.UserID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LockedBy, &j.LockedUntil, &j.LastError, &j.Result, &j.StartedAt, &j.FinishedAt, &j.CreatedAt, &j.UpdatedAt)
+	return j, err
+}
+
+type JobRepo struct {
+	db dbtx
+}
+
+func NewJobRepository(db dbtx) postgres.IJobInterface {
+	return &JobRepo{
+		db: db,
+	}
+}
+
+func (r *JobRepo) EnqueueJob(ctx context.Context, job sqlc.EnqueueJobParams) (sqlc.Job, error) {
+	t := now()
+	return get(ctx, r.db, scanJob, `
+		INSERT INTO jobs (id, user_id, kind, payload, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+jobColumns,
+		job.ID, job.UserID, job.Kind, string(job.Payload), job.MaxAttempts, timestamp(job.RunAt), t, t)
+}
+
+func (r *JobRepo) GetJob(ctx context.Context, id, userID uuid.UUID) (sqlc.Job, error) {
+	return get(ctx, r.db, scanJob, `SELECT `+jobColumns+` FROM jobs WHERE id = ? AND user_id = ?`, id, userID)
+}
+
+func (r *JobRepo) ListJobs(ctx context.Context, userID uuid.UUID, limit int32) ([]sqlc.Job, error) {
+	return list(ctx, r.db, scanJob,
+		`SELECT `+jobColumns+` FROM jobs WHERE user_id = ? ORDER BY created_at DESC LIMIT ?`, userID, limit)
+}
+
+// ClaimJob claims the next due job without row locks: SQLite runs one writer at a time, so two
+// workers never claim the same job
+func (r *JobRepo) ClaimJob(ctx context.Context, worker string, until time.Time) (sqlc.Job, error) {
+	t := now()
+	return get(ctx, r.db, scanJob, `
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, started_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = ? AND run_at <= ?)
+				OR (status = ? AND locked_until < ? AND attempts < max_attempts)
+			ORDER BY run_at
+			LIMIT 1
+		)
+		RETURNING `+jobColumns,
+		postgres.JobRunning, worker, until.UTC(), t, t, postgres.JobQueued, t, postgres.JobRunning, t)
+}
+
+func (r *JobRepo) ExtendLease(ctx context.Context, id uuid.UUID, worker string, until time.Time) error {
+	return exec(ctx, r.db, `
+		UPDATE jobs SET locked_until = ?, updated_at = ?
+		WHERE id = ? AND locked_by = ? AND status = ?`, until.UTC(), now(), id, worker, postgres.JobRunning)
+}
+
+func (r *JobRepo) CompleteJob(ctx context.Context, id uuid.UUID, worker string, result []byte) error {
+	t := now()
+	return exec(ctx, r.db, `
+		UPDATE jobs
+		SET status = ?, result = ?, last_error = NULL, locked_by = NULL, locked_until = NULL, finished_at = ?, updated_at = ?
+		WHERE id = ? AND locked_by = ? AND status = ?`,
+		postgres.JobSucceeded, string(result), t, t, id, worker, postgres.JobRunning)
+}
+
+func (r *JobRepo) FailJob(ctx context.Context, id uuid.UUID, worker string, cause error, retryAt time.Time) error {
+	t := now()
+	status := postgres.JobQueued
+	var finishedAt any
+	if retryAt.IsZero() {
+		status, retryAt, finishedAt = postgres.JobFailed, t, t
+	}
+
+	return exec(ctx, r.db, `
+		UPDATE jobs
+		SET status = ?, last_error = ?, run_at = ?, locked_by = NULL, locked_until = NULL, finished_at = ?, updated_at = ?
+		WHERE id = ? AND locked_by = ? AND status = ?`,
+		status, cause.Error(), retryAt.UTC(), finishedAt, t, id, worker, postgres.JobRunning)
+}
+
+func (r *JobRepo) FailAbandonedJobs(ctx context.Context) (int64, error) {
+	t := now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = ?, last_error = 'abandoned by its worker after the last attempt', locked_by = NULL,
+			locked_until = NULL, finished_at = ?, updated_at = ?
+		WHERE status = ? AND locked_until < ? AND attempts >= max_attempts`,
+		postgres.JobFailed, t, t, postgres.JobRunning, t)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *JobRepo) CountJobs(ctx context.Context) ([]sqlc.CountJobsRow, error) {
+	return list(ctx, r.db, func(row scanner) (sqlc.CountJobsRow, error) {
+		var c sqlc.CountJobsRow
+		err := row.Scan(&c.Kind, &c.Status, &c.Count)
+		return c, err
+	}, `SELECT kind, status, COUNT(*) FROM jobs GROUP BY kind, status ORDER BY kind, status`)
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+)
+
+type KnownEntityRepo struct {
+	db dbtx
+}
+
+func NewKnownEntityRepository(db dbtx) postgres.IKnownEntityInterface {
+	return &KnownEntityRepo{
+		db: db,
+	}
+}
+
+func (r *KnownEntityRepo) UpsertEntity(ctx context.Context, entity sqlc.UpsertKnownEntityParams) error {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO known_entities (id, chain, address, name, category, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (chain, address) DO UPDATE
+		SET name = excluded.name, category = excluded.category, updated_at = excluded.updated_at`,
+		entity.ID, entity.Chain, entity.Address, entity.Name, entity.Category, t, t)
+	return err
+}
+
+// SearchEntities scans every entity, see search
+func (r *KnownEntityRepo) SearchEntities(ctx context.Context, terms []string, limit int32) ([]sqlc.SearchKnownEntitiesRow, error) {
+	rows, err := list(ctx, r.db, func(row scanner) (sqlc.SearchKnownEntitiesRow, error) {
+		var e sqlc.SearchKnownEntitiesRow
+		err := row.Scan(&e.ID, &e.Chain, &e.Address, &e.Name, &e.Category)
+		return e, err
+	}, `SELECT id, chain, address, name, category FROM known_entities ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []sqlc.SearchKnownEntitiesRow
+	for _, e := range rows {
+		rank, ok := rankFields(terms, []searchField{{e.Name, 1}})
+		if !ok {
+			continue
+		}
+		e.Rank = rank
+		e.Headline = highlight(e.Name, terms)
+		matches = append(matches, e)
+	}
+
+	return topRanked(matches, func(e sqlc.SearchKnownEntitiesRow) float32 { return e.Rank }, limit), nil
+}
@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const notificationDeliveryColumns = `id, alert_id, webhook_id, channel, destination, status, attempts, last_error, next_attempt_at, delivered_at, created_at, updated_at`
+
+func scanNotificationDelivery(row scanner) (sqlc.NotificationDelivery, error) {
+	var d sqlc.NotificationDelivery
+	err := row.Scan(&d.ID, &d.AlertID, &d.WebhookID, &d.Channel, &d.Destination, &d.Status, &d.Attempts,
+		&d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// webhookDeliveryQuery selects the due deliveries of webhooks with their alerts, followed by a
+// condition on the webhook. Its parameters are webhookDeliveryArgs.
+const webhookDeliveryQuery = `
+	SELECT d.id, d.alert_id, d.attempts, w.id, w.url, w.secret, w.enabled AND w.deleted_at IS NULL, w.verified_at IS NOT NULL,
+		a.address_id, ad.chain, ad.address, a.rule_id, a.transaction_id, a.message, a.severity, a.created_at,
+		t.block_number, t.value, t.token_address,
+		(ad.muted_at IS NOT NULL AND (ad.muted_until IS NULL OR ad.muted_until > ?))
+			OR (r.muted_at IS NOT NULL AND (r.muted_until IS NULL OR r.muted_until > ?))
+	FROM notification_deliveries d
+	JOIN webhooks w ON w.id = d.webhook_id
+	JOIN alerts a ON a.id = d.alert_id
+	JOIN addresses ad ON ad.id = a.address_id
+	LEFT JOIN alert_rules r ON r.id = a.rule_id
+	LEFT JOIN transactions t ON t.id = a.transaction_id
+	WHERE d.channel = 'webhook' AND d.status = ? AND d.next_attempt_at <= ?
+		AND `
+
+// webhookDeliveryArgs are the parameters of webhookDeliveryQuery at t, followed by those of the
+// condition
+func webhookDeliveryArgs(t time.Time, args ...any) []any {
+	return append([]any{t, t, postgres.DeliveryPending, t}, args...)
+}
+
+func scanWebhookDelivery(row scanner) (postgres.WebhookDelivery, error) {
+	var d postgres.WebhookDelivery
+	err := row.Scan(&d.ID, &d.AlertID, &d.Attempts, &d.WebhookID, &d.Url, &d.Secret, &d.Active, &d.Verified,
+		&d.AddressID, &d.Chain, &d.Address, &d.RuleID, &d.TransactionID, &d.Message, &d.Severity, &d.AlertCreatedAt,
+		&d.BlockNumber, &d.Value, &d.TokenAddress, &d.Muted)
+	return d, err
+}
+
+// storedTime parses the text SQLite returns for an aggregate of timestamps, which loses the column
+// type: the time formatted by the driver, see now
+func storedTime(s string) (time.Time, error) {
+	return time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", s)
+}
+
+// inList is the IN list of values and its parameters
+func inList[T any](values []T) (string, []any) {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", args
+}
+
+type NotificationDeliveryRepo struct {
+	db dbtx
+}
+
+func NewNotificationDeliveryRepository(db dbtx) postgres.INotificationDeliveryInterface {
+	return &NotificationDeliveryRepo{
+		db: db,
+	}
+}
+
+func (r *NotificationDeliveryRepo) CreateDelivery(ctx context.Context, delivery sqlc.CreateNotificationDeliveryParams) (uuid.UUID, error) {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_deliveries (id, alert_id, webhook_id, channel, destination, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.ID, delivery.AlertID, delivery.WebhookID, delivery.Channel, delivery.Destination, t, t, t)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return delivery.ID, nil
+}
+
+func (r *NotificationDeliveryRepo) ListDeliveries(ctx context.Context, alertID uuid.UUID) ([]sqlc.NotificationDelivery, error) {
+	return list(ctx, r.db, scanNotificationDelivery,
+		`SELECT `+notificationDeliveryColumns+` FROM notification_deliveries WHERE alert_id = ? ORDER BY created_at`, alertID)
+}
+
+// ClaimDueDeliveries returns the due deliveries without locking them: SQLite runs one writer at a
+// time, so there are no concurrent workers to skip
+func (r *NotificationDeliveryRepo) ClaimDueDeliveries(ctx context.Context, limit int32) ([]sqlc.NotificationDelivery, error) {
+	return list(ctx, r.db, scanNotificationDelivery, `
+		SELECT `+notificationDeliveryColumns+` FROM notification_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at
+		LIMIT ?`, postgres.DeliveryPending, now(), limit)
+}
+
+func (r *NotificationDeliveryRepo) MarkSent(ctx context.Context, id uuid.UUID) error {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_deliveries
+		SET status = ?, attempts = attempts + 1, last_error = NULL, delivered_at = ?, updated_at = ?
+		WHERE id = ?`, postgres.DeliverySent, t, t, id)
+	return err
+}
+
+func (r *NotificationDeliveryRepo) MarkFailed(ctx context.Context, id uuid.UUID, cause error, retryAt time.Time) error {
+	status := postgres.DeliveryPending
+	if retryAt.IsZero() {
+		status, retryAt = postgres.DeliveryFailed, now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_deliveries
+		SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?`, status, cause.Error(), retryAt.UTC(), now(), id)
+	return err
+}
+
+func (r *NotificationDeliveryRepo) ClaimWebhookDeliveries(ctx context.Context, limit int32) ([]postgres.WebhookDelivery, error) {
+	return list(ctx, r.db, scanWebhookDelivery, webhookDeliveryQuery+`w.delivery_mode = ?
+		ORDER BY d.next_attempt_at
+		LIMIT ?`, webhookDeliveryArgs(now(), postgres.WebhookSingle, limit)...)
+}
+
+// ListDueBatches compares the age of each webhook's oldest delivery here rather than in SQL, where
+// the stored timestamps are not dates
+func (r *NotificationDeliveryRepo) ListDueBatches(ctx context.Context, limit int32) ([]sqlc.ListDueWebhookBatchesRow, error) {
+	type batch struct {
+		id          uuid.UUID
+		maxEvents   int32
+		interval    int32
+		pending     int64
+		oldestSince string
+	}
+	t := now()
+	batches, err := list(ctx, r.db, func(row scanner) (batch, error) {
+		var b batch
+		err := row.Scan(&b.id, &b.maxEvents, &b.interval, &b.pending, &b.oldestSince)
+		return b, err
+	}, `
+		SELECT w.id, w.batch_max_events, w.batch_interval_seconds, COUNT(*), MIN(d.created_at)
+		FROM notification_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.channel = 'webhook' AND d.status = ? AND d.next_attempt_at <= ? AND w.delivery_mode = ?
+		GROUP BY w.id, w.batch_max_events, w.batch_interval_seconds
+		ORDER BY MIN(d.created_at)`, postgres.DeliveryPending, t, postgres.WebhookBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []sqlc.ListDueWebhookBatchesRow
+	for _, b := range batches {
+		oldest, err := storedTime(b.oldestSince)
+		if err != nil {
+			return nil, fmt.Errorf("parse the oldest delivery of webhook %s: %w", b.id, err)
+		}
+		if b.pending < int64(b.maxEvents) && t.Sub(oldest) < time.Duration(b.interval)*time.Second {
+			continue
+		}
+		due = append(due, sqlc.ListDueWebhookBatchesRow{ID: b.id, BatchMaxEvents: b.maxEvents})
+		if len(due) == int(limit) {
+			break
+		}
+	}
+	return due, nil
+}
+
+func (r *NotificationDeliveryRepo) ClaimBatch(ctx context.Context, webhookID uuid.UUID, limit int32) ([]postgres.WebhookDelivery, error) {
+	return list(ctx, r.db, scanWebhookDelivery, webhookDeliveryQuery+`d.webhook_id = ?
+		ORDER BY d.created_at
+		LIMIT ?`, webhookDeliveryArgs(now(), webhookID, limit)...)
+}
+
+func (r *NotificationDeliveryRepo) ClaimBlock(ctx context.Context, webhookID, addressID uuid.UUID, block int64, claimed []uuid.UUID, limit int32) ([]postgres.WebhookDelivery, error) {
+	in, args := inList(claimed)
+	return list(ctx, r.db, scanWebhookDelivery, webhookDeliveryQuery+`d.webhook_id = ? AND a.address_id = ? AND t.block_number = ?
+			AND d.id NOT IN `+in+`
+		ORDER BY d.created_at
+		LIMIT ?`, webhookDeliveryArgs(now(), append(append([]any{webhookID, addressID, block}, args...), limit)...)...)
+}
+
+func (r *NotificationDeliveryRepo) MarkBatchSent(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	in, args := inList(ids)
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_deliveries
+		SET status = ?, attempts = attempts + 1, last_error = NULL, delivered_at = ?, updated_at = ?
+		WHERE id IN `+in, append([]any{postgres.DeliverySent, t, t}, args...)...)
+	return err
+}
+
+func (r *NotificationDeliveryRepo) MarkBatchFailed(ctx context.Context, ids []uuid.UUID, cause error, retryAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	status := postgres.DeliveryPending
+	if retryAt.IsZero() {
+		status, retryAt = postgres.DeliveryFailed, now()
+	}
+
+	in, args := inList(ids)
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_deliveries
+		SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id IN `+in, append([]any{status, cause.Error(), retryAt.UTC(), now()}, args...)...)
+	return err
+}
+
+func (r *NotificationDeliveryRepo) SkipDeliveries(ctx context.Context, ids []uuid.UUID, reason string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	in, args := inList(ids)
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_deliveries
+		SET status = ?, last_error = ?, updated_at = ?
+		WHERE id IN `+in, append([]any{postgres.DeliverySkipped, reason, now()}, args...)...)
+	return err
+}
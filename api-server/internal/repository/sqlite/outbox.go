@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tracecontext"
+	"github.com/google/uuid"
+)
+
+// OutboxRepo stores events like postgres.OutboxRepo. Nothing captures them from SQLite; they are
+// kept so local runs exercise the same unit of work.
+type OutboxRepo struct {
+	db dbtx
+}
+
+func NewOutboxRepository(db dbtx) postgres.IOutboxInterface {
+	return &OutboxRepo{
+		db: db,
+	}
+}
+
+func (r *OutboxRepo) CreateEvent(ctx context.Context, event postgres.OutboxEvent) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+
+	var traceparent *string
+	if sc, ok := tracecontext.FromContext(ctx); ok {
+		s := sc.String()
+		traceparent = &s
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO outbox_events (id, aggregate_type, aggregate_id, type, payload, traceparent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New(), event.AggregateType, event.AggregateID, event.Type, string(payload), traceparent, now())
+	return err
+}
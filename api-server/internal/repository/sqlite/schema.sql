@@ -0,0 +1,418 @@
+-- SQLite version of db/migrations, applied when the database is opened. Keep it in step with the
+-- migrations: UUIDs are stored as text, timestamps as DATETIME and NUMERIC values as text, so
+-- 78-digit token amounts keep their precision.
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+
+    email TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+
+    phone_number TEXT,
+    wallet_address TEXT,
+
+    subscribed BOOLEAN NOT NULL DEFAULT false,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME,
+
+    version INTEGER NOT NULL DEFAULT 1,
+
+    email_verified_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_wallet_address ON users (wallet_address);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    token_hash TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_user_id ON email_verifications (user_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id TEXT PRIMARY KEY,
+
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+
+    traceparent TEXT,
+
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    chain TEXT NOT NULL,
+    address TEXT NOT NULL,
+    label TEXT,
+    notes TEXT,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at DATETIME,
+
+    muted_at DATETIME,
+    muted_until DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_user_chain_address ON addresses (user_id, chain, address)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_addresses_chain_address ON addresses (chain, address) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_addresses_user_created_at_id ON addresses (user_id, created_at, id);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id TEXT REFERENCES addresses (id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    direction TEXT NOT NULL DEFAULT 'any' CHECK (direction IN ('in', 'out', 'any')),
+    min_value TEXT NOT NULL DEFAULT '0',
+    token_address TEXT,
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    severity TEXT NOT NULL DEFAULT 'warning' CHECK (severity IN ('info', 'warning', 'critical')),
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at DATETIME,
+
+    muted_at DATETIME,
+    muted_until DATETIME,
+
+    -- Built-in list of token contracts transfers are filtered to, instead of token_address
+    token_preset TEXT CHECK (token_preset IN ('stablecoins')),
+
+    -- CEL condition of advanced rules, see package ruleexpr
+    expression TEXT CHECK (expression IS NULL OR LENGTH(expression) BETWEEN 1 AND 4096),
+    priority INTEGER NOT NULL DEFAULT 0 CHECK (priority BETWEEN -1000 AND 1000),
+    stop_processing INTEGER NOT NULL DEFAULT 0,
+
+    -- SQLite takes table constraints after the columns only
+    CHECK (token_address IS NULL OR token_preset IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_user_id ON alert_rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_address_id ON alert_rules (address_id);
+
+-- Counterparties a rule's transfers are limited to
+CREATE TABLE IF NOT EXISTS alert_rule_counterparties (
+    rule_id TEXT NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+
+    PRIMARY KEY (rule_id, address)
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id TEXT PRIMARY KEY,
+
+    chain TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    log_index INTEGER NOT NULL DEFAULT -1,
+    block_number INTEGER NOT NULL,
+    block_hash TEXT NOT NULL,
+
+    from_address TEXT NOT NULL,
+    to_address TEXT,
+    value TEXT NOT NULL,
+    token_address TEXT,
+
+    status TEXT NOT NULL DEFAULT 'pending',
+    occurred_at DATETIME NOT NULL,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_chain_hash_log_index ON transactions (chain, hash, log_index);
+CREATE INDEX IF NOT EXISTS idx_transactions_chain_from_address_created_at_id ON transactions (chain, from_address, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_chain_to_address_created_at_id ON transactions (chain, to_address, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id TEXT NOT NULL REFERENCES addresses (id) ON DELETE CASCADE,
+    rule_id TEXT REFERENCES alert_rules (id) ON DELETE SET NULL,
+    -- NULL for alerts of balance changes, see migration 000026
+    transaction_id TEXT REFERENCES transactions (id) ON DELETE CASCADE,
+
+    message TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'warning' CHECK (severity IN ('info', 'warning', 'critical')),
+    acknowledged_at DATETIME,
+
+    created_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_rule_transaction ON alerts (rule_id, transaction_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_user_created_at_id ON alerts (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_alerts_address_created_at_id ON alerts (address_id, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME,
+
+    delivery_mode TEXT NOT NULL DEFAULT 'single' CHECK (delivery_mode IN ('single', 'batch')),
+    batch_max_events INTEGER NOT NULL DEFAULT 100 CHECK (batch_max_events BETWEEN 1 AND 1000),
+    batch_interval_seconds INTEGER NOT NULL DEFAULT 30 CHECK (batch_interval_seconds BETWEEN 1 AND 3600),
+
+    verified_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id TEXT PRIMARY KEY,
+    alert_id TEXT NOT NULL REFERENCES alerts (id) ON DELETE CASCADE,
+    webhook_id TEXT REFERENCES webhooks (id) ON DELETE SET NULL,
+
+    channel TEXT NOT NULL,
+    destination TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL,
+    delivered_at DATETIME,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_alert_id ON notification_deliveries (alert_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_pending ON notification_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_webhook_pending ON notification_deliveries (webhook_id, created_at)
+    WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL,
+
+    last_used_at DATETIME,
+    expires_at DATETIME,
+    revoked_at DATETIME,
+
+    created_at DATETIME NOT NULL,
+
+    -- JSON array of the networks the key is accepted from, any when empty
+    allowed_cidrs TEXT NOT NULL DEFAULT '[]',
+    -- JSON array of the key's scopes, e.g. read:addresses
+    scopes TEXT NOT NULL DEFAULT '[]',
+    -- AES-256-GCM nonce and ciphertext of the secret of keys signing their requests
+    signing_secret_encrypted BLOB
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS request_nonces (
+    key_id TEXT NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    nonce TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+
+    PRIMARY KEY (key_id, nonce)
+);
+
+-- Searched in Go, SQLite has no equivalent of the tsvector indexes
+CREATE TABLE IF NOT EXISTS known_entities (
+    id TEXT PRIMARY KEY,
+
+    chain TEXT NOT NULL,
+    address TEXT NOT NULL,
+    name TEXT NOT NULL,
+    category TEXT,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_known_entities_chain_address ON known_entities (chain, address);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    user_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at DATETIME NOT NULL,
+
+    locked_by TEXT,
+    locked_until DATETIME,
+
+    last_error TEXT,
+    result TEXT,
+
+    started_at DATETIME,
+    finished_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queued ON jobs (run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs (locked_until) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_user_id_created_at ON jobs (user_id, created_at);
+
+-- Days are stored as YYYY-MM-DD text
+CREATE TABLE IF NOT EXISTS api_usage (
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day TEXT NOT NULL,
+    method TEXT NOT NULL,
+    route TEXT NOT NULL,
+
+    calls INTEGER NOT NULL,
+    client_errors INTEGER NOT NULL,
+    server_errors INTEGER NOT NULL,
+    total_latency_us INTEGER NOT NULL,
+    max_latency_us INTEGER NOT NULL,
+
+    PRIMARY KEY (user_id, day, method, route)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_day ON api_usage (day);
+
+-- Events of a rule without an address have no address_id, see migration 000023
+CREATE TABLE IF NOT EXISTS address_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id TEXT REFERENCES addresses (id) ON DELETE CASCADE,
+    rule_id TEXT REFERENCES alert_rules (id) ON DELETE CASCADE,
+
+    kind TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '{}',
+
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_address_events_user_created_at_id ON address_events (user_id, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS account_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    kind TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '{}',
+    ip_address TEXT,
+    user_agent TEXT,
+
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_events_user_created_at_id ON account_events (user_id, created_at DESC, id DESC);
+
+-- Deltas are measured from the balance first read, then from the one of the last alert, see
+-- migration 000026
+CREATE TABLE IF NOT EXISTS balance_watches (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id TEXT NOT NULL REFERENCES addresses (id) ON DELETE CASCADE,
+
+    token_address TEXT NOT NULL,
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds >= 15),
+    min_delta TEXT NOT NULL DEFAULT '0',
+    severity TEXT NOT NULL DEFAULT 'warning' CHECK (severity IN ('info', 'warning', 'critical')),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    balance TEXT,
+    block_number INTEGER,
+    last_error TEXT,
+    checked_at DATETIME,
+    next_check_at DATETIME NOT NULL,
+
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_watches_address_token ON balance_watches (address_id, LOWER(token_address));
+CREATE INDEX IF NOT EXISTS idx_balance_watches_next_check_at ON balance_watches (next_check_at) WHERE enabled;
+
+-- Sign-ins of a user, one per token handed out by login, see migration 000033
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    ip_address TEXT,
+    user_agent TEXT,
+
+    created_at DATETIME NOT NULL,
+    last_seen_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+-- Refresh tokens of the sessions, see migration 000037
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+
+-- State of the engine's watch of each address, see migration 000035. The engine only runs on
+-- Postgres, so addresses stay pending here.
+CREATE TABLE IF NOT EXISTS watcher_status (
+    chain TEXT NOT NULL,
+    address TEXT NOT NULL,
+
+    watching BOOLEAN NOT NULL,
+    watching_since DATETIME NOT NULL,
+    mode TEXT CHECK (mode IN ('subscription', 'polling')),
+
+    last_scanned_block INTEGER,
+    last_scanned_at DATETIME,
+
+    last_activity_block INTEGER,
+    last_activity_hash TEXT,
+    last_activity_at DATETIME,
+
+    last_error TEXT,
+    last_error_at DATETIME,
+    error_count INTEGER NOT NULL DEFAULT 0,
+
+    updated_at DATETIME NOT NULL,
+
+    PRIMARY KEY (chain, address)
+);
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Search approximates the Postgres full-text queries in Go: a term matches a word it prefixes,
+// ignoring case, and a row matches when all terms do. Ranks order the results of one search but
+// are not comparable with the ts_rank values Postgres returns.
+
+// searchField is searched text and the weight of matches in it
+type searchField struct {
+	text   string
+	weight float32
+}
+
+// words splits text into lower case words of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// rankFields reports whether every term prefixes a word of the fields, ranking the match by the
+// weight of the best field each term matched in
+func rankFields(terms []string, fields []searchField) (float32, bool) {
+	var rank float32
+	for _, term := range terms {
+		var best float32
+		for _, f := range fields {
+			if f.weight > best && hasPrefixWord(f.text, term) {
+				best = f.weight
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		rank += best
+	}
+	return rank / float32(len(terms)), true
+}
+
+func hasPrefixWord(text, term string) bool {
+	for _, w := range words(text) {
+		if strings.HasPrefix(w, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight wraps the words of text prefixed by a term in <mark> tags, like ts_headline
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if matchesAny(strings.ToLower(word), terms) {
+			b.WriteString("<mark>" + word + "</mark>")
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func matchesAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinText joins the non-null values with " | ", like concat_ws
+func joinText(values ...pgtype.Text) string {
+	var parts []string
+	for _, v := range values {
+		if v.Valid {
+			parts = append(parts, v.String)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// topRanked sorts matches by descending rank, keeping the query order among equal ranks, and
+// returns at most limit of them
+func topRanked[T any](matches []T, rank func(T) float32, limit int32) []T {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return rank(matches[i]) > rank(matches[j])
+	})
+	if len(matches) > int(limit) {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const sessionColumns = `id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row scanner) (sqlc.Session, error) {
+	var s sqlc.Session
+	err := row.Scan(&s.ID, &s.UserID, &s.IpAddress, &s.UserAgent, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt)
+	return s, err
+}
+
+// recordSessionEvent records the revocation of the session with its client, like
+// recordAPIKeyEvent
+func recordSessionEvent(ctx context.Context, db dbtx, sessionID uuid.UUID) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO account_events (id, user_id, kind, detail, created_at)
+		SELECT ?, user_id, ?, json_object('session_id', id, 'ip_address', ip_address, 'user_agent', user_agent), ?
+		FROM sessions WHERE id = ?`,
+		uuid.New(), postgres.EventSessionRevoked, now(), sessionID)
+	return err
+}
+
+type SessionRepo struct {
+	db dbtx
+}
+
+func NewSessionRepository(db dbtx) postgres.ISessionInterface {
+	return &SessionRepo{
+		db: db,
+	}
+}
+
+func (r *SessionRepo) CreateSession(ctx context.Context, session sqlc.CreateSessionParams) error {
+	created := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		session.ID, session.UserID, session.IpAddress, session.UserAgent, created, created, timestamp(session.ExpiresAt))
+	return err
+}
+
+func (r *SessionRepo) GetActiveSession(ctx context.Context, id, userID uuid.UUID) (*sqlc.Session, error) {
+	session, err := get(ctx, r.db, scanSession, `
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?`, id, userID, now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepo) ListSessions(ctx context.Context, userID uuid.UUID) ([]sqlc.Session, error) {
+	return list(ctx, r.db, scanSession, `
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC, id`, userID, now())
+}
+
+func (r *SessionRepo) TouchSession(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE sessions SET last_seen_at = ? WHERE id = ?`, now(), id)
+	return err
+}
+
+func (r *SessionRepo) RevokeSession(ctx context.Context, id, userID uuid.UUID) error {
+	err := exec(ctx, r.db, `
+		UPDATE sessions SET revoked_at = ?
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?`, now(), id, userID, now())
+	if err != nil {
+		return err
+	}
+	return recordSessionEvent(ctx, r.db, id)
+}
+
+func (r *SessionRepo) RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) ([]uuid.UUID, error) {
+	scanID := func(row scanner) (uuid.UUID, error) {
+		var id uuid.UUID
+		return id, row.Scan(&id)
+	}
+	ids, err := list(ctx, r.db, scanID, `
+		UPDATE sessions SET revoked_at = ?
+		WHERE user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?
+		RETURNING id`, now(), userID, keep, now())
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		// The refresh tokens of the sessions stop working with them
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE refresh_tokens SET expires_at = ? WHERE session_id = ? AND expires_at > ?`,
+			now(), id, now())
+		if err != nil {
+			return nil, err
+		}
+		if err := recordSessionEvent(ctx, r.db, id); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func (r *SessionRepo) CreateRefreshToken(ctx context.Context, token sqlc.CreateRefreshTokenParams) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (id, session_id, user_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		token.ID, token.SessionID, token.UserID, token.TokenHash, now(), timestamp(token.ExpiresAt))
+	return err
+}
+
+func (r *SessionRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*sqlc.GetRefreshTokenRow, error) {
+	scan := func(row scanner) (sqlc.GetRefreshTokenRow, error) {
+		var t sqlc.GetRefreshTokenRow
+		err := row.Scan(&t.ID, &t.SessionID, &t.UserID, &t.Email, &t.UsedAt)
+		return t, err
+	}
+	token, err := get(ctx, r.db, scan, `
+		SELECT rt.id, rt.session_id, rt.user_id, u.email, rt.used_at
+		FROM refresh_tokens rt
+		JOIN sessions s ON s.id = rt.session_id
+		JOIN users u ON u.id = rt.user_id
+		WHERE rt.token_hash = ? AND rt.expires_at > ?
+			AND s.revoked_at IS NULL AND s.expires_at > ? AND u.deleted_at IS NULL`,
+		tokenHash, now(), now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *SessionRepo) RotateRefreshToken(ctx context.Context, rotation sqlc.RotateRefreshTokenParams) error {
+	err := exec(ctx, r.db, `UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, now(), rotation.ID)
+	if err != nil {
+		return err
+	}
+	expiresAt := timestamp(rotation.ExpiresAt)
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE sessions SET expires_at = ?, last_seen_at = ?
+		WHERE id = (SELECT session_id FROM refresh_tokens WHERE id = ?)`,
+		expiresAt, now(), rotation.ID)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (id, session_id, user_id, token_hash, created_at, expires_at)
+		SELECT ?, session_id, user_id, ?, ?, ? FROM refresh_tokens WHERE id = ?`,
+		rotation.NewID, rotation.TokenHash, now(), expiresAt, rotation.ID)
+	return err
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+)
+
+func scanCount(row scanner) (int64, error) {
+	var n int64
+	err := row.Scan(&n)
+	return n, err
+}
+
+type StatsRepo struct {
+	db dbtx
+}
+
+func NewStatsRepository(db dbtx) postgres.IStatsInterface {
+	return &StatsRepo{
+		db: db,
+	}
+}
+
+func (r *StatsRepo) CountUsers(ctx context.Context) (int64, error) {
+	return get(ctx, r.db, scanCount, `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`)
+}
+
+func (r *StatsRepo) CountAddressesByChain(ctx context.Context) ([]sqlc.CountAddressesByChainRow, error) {
+	return list(ctx, r.db, func(row scanner) (sqlc.CountAddressesByChainRow, error) {
+		var c sqlc.CountAddressesByChainRow
+		err := row.Scan(&c.Chain, &c.Addresses, &c.Users)
+		return c, err
+	}, `
+		SELECT chain, COUNT(*), COUNT(DISTINCT user_id) FROM addresses
+		WHERE deleted_at IS NULL
+		GROUP BY chain
+		ORDER BY chain`)
+}
+
+func (r *StatsRepo) CountAlertsSince(ctx context.Context, since time.Time) (int64, error) {
+	return get(ctx, r.db, scanCount, `SELECT COUNT(*) FROM alerts WHERE created_at >= ?`, since.UTC())
+}
+
+func (r *StatsRepo) CountDeliveriesSince(ctx context.Context, since time.Time) ([]sqlc.CountDeliveriesSinceRow, error) {
+	return list(ctx, r.db, func(row scanner) (sqlc.CountDeliveriesSinceRow, error) {
+		var c sqlc.CountDeliveriesSinceRow
+		err := row.Scan(&c.Channel, &c.Status, &c.Count)
+		return c, err
+	}, `
+		SELECT channel, status, COUNT(*) FROM notification_deliveries
+		WHERE created_at >= ?
+		GROUP BY channel, status
+		ORDER BY channel, status`, since.UTC())
+}
+
+// ListChainHeads takes occurred_at as a bare column, which SQLite reads from the row holding the
+// maximum block number and, unlike MAX(occurred_at), returns as a timestamp
+func (r *StatsRepo) ListChainHeads(ctx context.Context, since time.Time) ([]sqlc.ListChainHeadsRow, error) {
+	return list(ctx, r.db, func(row scanner) (sqlc.ListChainHeadsRow, error) {
+		var h sqlc.ListChainHeadsRow
+		err := row.Scan(&h.Chain, &h.BlockNumber, &h.OccurredAt)
+		return h, err
+	}, `
+		SELECT chain, MAX(block_number), occurred_at FROM transactions
+		WHERE created_at >= ?
+		GROUP BY chain
+		ORDER BY chain`, since.UTC())
+}
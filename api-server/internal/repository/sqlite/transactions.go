@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const transactionColumns = `id, chain, hash, log_index, block_number, block_hash, from_address, to_address, value, token_address, status, occurred_at, created_at, updated_at`
+
+func scanTransaction(row scanner) (sqlc.Transaction, error) {
+	var t sqlc.Transaction
+	err := row.Scan(&t.ID, &t.Chain, &t.Hash, &t.LogIndex, &t.BlockNumber, &t.BlockHash, &t.FromAddress,
+		&t.ToAddress, &t.Value, &t.TokenAddress, &t.Status, &t.OccurredAt, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+type TransactionRepo struct {
+	db dbtx
+}
+
+func NewTransactionRepository(db dbtx) postgres.ITransactionInterface {
+	return &TransactionRepo{
+		db: db,
+	}
+}
+
+func (r *TransactionRepo) SaveTransaction(ctx context.Context, tx sqlc.UpsertTransactionParams) (uuid.UUID, error) {
+	t := now()
+	var id uuid.UUID
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO transactions (id, chain, hash, log_index, block_number, block_hash, from_address, to_address, value, token_address, status, occurred_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (chain, hash, log_index) DO UPDATE
+		SET block_number = excluded.block_number,
+			block_hash = excluded.block_hash,
+			status = excluded.status,
+			occurred_at = excluded.occurred_at,
+			updated_at = excluded.updated_at
+		RETURNING id`,
+		tx.ID, tx.Chain, tx.Hash, tx.LogIndex, tx.BlockNumber, tx.BlockHash, tx.FromAddress, tx.ToAddress,
+		tx.Value, tx.TokenAddress, tx.Status, timestamp(tx.OccurredAt), t, t).Scan(&id)
+	return id, err
+}
+
+func (r *TransactionRepo) GetTransactions(ctx context.Context, chain, hash string) ([]sqlc.Transaction, error) {
+	return list(ctx, r.db, scanTransaction,
+		`SELECT `+transactionColumns+` FROM transactions WHERE chain = ? AND hash = ? ORDER BY log_index`, chain, hash)
+}
+
+func (r *TransactionRepo) ListTransactions(ctx context.Context, chain, address string, page postgres.PageRequest) (postgres.Page[sqlc.Transaction], error) {
+	q, err := page.Query()
+	if err != nil {
+		return postgres.Page[sqlc.Transaction]{}, err
+	}
+
+	rows, err := list(ctx, r.db, scanTransaction, `
+		SELECT `+transactionColumns+` FROM transactions
+		WHERE chain = ? AND (from_address = ? OR to_address = ?) AND (? IS NULL OR (created_at, id) < (?, ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, append([]any{chain, address, address}, after(q)...)...)
+	if err != nil {
+		return postgres.Page[sqlc.Transaction]{}, err
+	}
+
+	return postgres.NewPage(q, rows, postgres.TransactionKey), nil
+}
+
+func (r *TransactionRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE transactions SET status = ?, updated_at = ? WHERE id = ?`, status, now(), id)
+	return err
+}
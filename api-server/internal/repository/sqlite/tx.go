@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+)
+
+// NewRepositories creates the repositories on db, the database or a transaction. Their errors
+// are mapped to the domain errors of package postgres.
+func NewRepositories(db dbtx) postgres.Repositories {
+	db = mappedDB{db}
+	return postgres.Scoped(postgres.Repositories{
+		Users:                  NewUserRepository(db),
+		Addresses:              NewAddressRepository(db),
+		AlertRules:             NewAlertRuleRepository(db),
+		Alerts:                 NewAlertRepository(db),
+		Transactions:           NewTransactionRepository(db),
+		NotificationDeliveries: NewNotificationDeliveryRepository(db),
+		Webhooks:               NewWebhookRepository(db),
+		KnownEntities:          NewKnownEntityRepository(db),
+		APIKeys:                NewAPIKeyRepository(db),
+		Outbox:                 NewOutboxRepository(db),
+		Archive:                NewArchiveRepository(db),
+		Jobs:                   NewJobRepository(db),
+		Stats:                  NewStatsRepository(db),
+		APIUsage:               NewAPIUsageRepository(db),
+		AddressEvents:          NewAddressEventRepository(db),
+		AccountEvents:          NewAccountEventRepository(db),
+		Dashboard:              NewDashboardRepository(db),
+		BalanceWatches:         NewBalanceWatchRepository(db),
+		Sessions:               NewSessionRepository(db),
+		WatcherStatus:          NewWatcherStatusRepository(db),
+	})
+}
+
+type TxManager struct {
+	db *Database
+}
+
+func NewTxManager(db *Database) postgres.ITxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(repos postgres.Repositories) error) error {
+	return m.db.WithTx(ctx, func(tx *sql.Tx) error {
+		return fn(NewRepositories(tx))
+	})
+}
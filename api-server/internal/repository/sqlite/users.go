@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const userColumns = `id, email, password_hash, phone_number, wallet_address, subscribed, created_at, updated_at, deleted_at, version, email_verified_at`
+
+func scanUser(row scanner) (sqlc.User, error) {
+	var u sqlc.User
+	err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.PhoneNumber, &u.WalletAddress, &u.Subscribed,
+		&u.CreatedAt, &u.UpdatedAt, &u.DeletedAt, &u.Version, &u.EmailVerifiedAt)
+	return u, err
+}
+
+type UserRepo struct {
+	db dbtx
+}
+
+func NewUserRepository(db dbtx) postgres.IUserInterface {
+	return &UserRepo{
+		db: db,
+	}
+}
+
+func (r *UserRepo) CreateNewUser(ctx context.Context, user sqlc.CreateUserParams) (uuid.UUID, error) {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (id, email, password_hash, phone_number, wallet_address, subscribed, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		user.ID, user.Email, user.PasswordHash, user.PhoneNumber, user.WalletAddress, user.Subscribed, t, t)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return user.ID, nil
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, email string) (*sqlc.User, error) {
+	user, err := get(ctx, r.db, scanUser,
+		`SELECT `+userColumns+` FROM users WHERE email = ? AND deleted_at IS NULL`, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*sqlc.User, error) {
+	user, err := get(ctx, r.db, scanUser,
+		`SELECT `+userColumns+` FROM users WHERE id = ? AND deleted_at IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepo) UpdateProfile(ctx context.Context, user sqlc.UpdateUserProfileParams) error {
+	err := exec(ctx, r.db, `
+		UPDATE users
+		SET phone_number = ?, wallet_address = ?, subscribed = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ? AND deleted_at IS NULL`,
+		user.PhoneNumber, user.WalletAddress, user.Subscribed, now(), user.ID, user.Version)
+	return postgres.CheckVersion(err, func() error {
+		_, err := r.GetUserByID(ctx, user.ID)
+		return err
+	})
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return exec(ctx, r.db, `UPDATE users SET password_hash = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL`,
+		passwordHash, now(), id)
+}
+
+func (r *UserRepo) CreateEmailVerification(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM email_verifications WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO email_verifications (token_hash, user_id, email, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)`, tokenHash, id, email, expiresAt.UTC(), now())
+	return err
+}
+
+func (r *UserRepo) VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	type link struct {
+		userID    uuid.UUID
+		email     string
+		expiresAt time.Time
+	}
+	l, err := get(ctx, r.db, func(row scanner) (link, error) {
+		var l link
+		err := row.Scan(&l.userID, &l.email, &l.expiresAt)
+		return l, err
+	}, `SELECT user_id, email, expires_at FROM email_verifications WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	// Like the Postgres query, the link is consumed even when it no longer verifies the email
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM email_verifications WHERE token_hash = ?`, tokenHash); err != nil {
+		return uuid.UUID{}, err
+	}
+	t := now()
+	if !l.expiresAt.After(t) {
+		return uuid.UUID{}, pgx.ErrNoRows
+	}
+	err = exec(ctx, r.db, `UPDATE users SET email_verified_at = ?, updated_at = ? WHERE id = ? AND email = ? AND deleted_at IS NULL`,
+		t, t, l.userID, l.email)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	err = (&AccountEventRepo{db: r.db}).RecordEvent(ctx, postgres.AccountEvent{
+		UserID: l.userID,
+		Kind:   postgres.EventEmailVerified,
+		Detail: map[string]any{"email": l.email},
+	})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return l.userID, nil
+}
+
+func (r *UserRepo) SoftDeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, now(), id)
+	return err
+}
+
+func (r *UserRepo) HardDeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+)
+
+type WatcherStatusRepo struct {
+	db dbtx
+}
+
+func NewWatcherStatusRepository(db dbtx) postgres.IWatcherStatusInterface {
+	return &WatcherStatusRepo{
+		db: db,
+	}
+}
+
+func (r *WatcherStatusRepo) GetWatcherStatus(ctx context.Context, chain, address string) (*sqlc.WatcherStatus, error) {
+	status, err := get(ctx, r.db, func(row scanner) (sqlc.WatcherStatus, error) {
+		var s sqlc.WatcherStatus
+		err := row.Scan(&s.Chain, &s.Address, &s.Watching, &s.WatchingSince, &s.Mode, &s.LastScannedBlock, &s.LastScannedAt,
+			&s.LastActivityBlock, &s.LastActivityHash, &s.LastActivityAt, &s.LastError, &s.LastErrorAt, &s.ErrorCount, &s.UpdatedAt)
+		return s, err
+	}, `
+		SELECT chain, address, watching, watching_since, mode, last_scanned_block, last_scanned_at, last_activity_block,
+			last_activity_hash, last_activity_at, last_error, last_error_at, error_count, updated_at
+		FROM watcher_status WHERE chain = ? AND address = ?`, chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
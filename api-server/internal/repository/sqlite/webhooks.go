@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+const webhookColumns = `id, user_id, url, secret, enabled, created_at, updated_at, deleted_at, delivery_mode, batch_max_events, batch_interval_seconds, verified_at`
+
+func scanWebhook(row scanner) (sqlc.Webhook, error) {
+	var w sqlc.Webhook
+	err := row.Scan(&w.ID, &w.UserID, &w.Url, &w.Secret, &w.Enabled, &w.CreatedAt, &w.UpdatedAt, &w.DeletedAt,
+		&w.DeliveryMode, &w.BatchMaxEvents, &w.BatchIntervalSeconds, &w.VerifiedAt)
+	return w, err
+}
+
+type WebhookRepo struct {
+	db dbtx
+}
+
+func NewWebhookRepository(db dbtx) postgres.IWebhookInterface {
+	return &WebhookRepo{
+		db: db,
+	}
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook sqlc.CreateWebhookParams) (uuid.UUID, error) {
+	t := now()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhooks (id, user_id, url, secret, enabled, delivery_mode, batch_max_events, batch_interval_seconds,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		webhook.ID, webhook.UserID, webhook.Url, webhook.Secret, webhook.Enabled, webhook.DeliveryMode,
+		webhook.BatchMaxEvents, webhook.BatchIntervalSeconds, t, t)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err := recordWebhookEvent(ctx, r.db, webhook.ID, postgres.EventWebhookCreated); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return webhook.ID, nil
+}
+
+func (r *WebhookRepo) GetWebhook(ctx context.Context, id, userID uuid.UUID) (*sqlc.Webhook, error) {
+	webhook, err := get(ctx, r.db, scanWebhook,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = ? AND user_id = ? AND (deleted_at IS NULL OR ?)`,
+		id, userID, postgres.IsIncludingDeleted(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepo) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]sqlc.Webhook, error) {
+	return list(ctx, r.db, scanWebhook,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE user_id = ? AND (deleted_at IS NULL OR ?) ORDER BY created_at`,
+		userID, postgres.IsIncludingDeleted(ctx))
+}
+
+func (r *WebhookRepo) UpdateWebhook(ctx context.Context, webhook sqlc.UpdateWebhookParams) error {
+	err := exec(ctx, r.db, `
+		UPDATE webhooks
+		SET url = ?, enabled = ?, delivery_mode = ?, batch_max_events = ?, batch_interval_seconds = ?,
+			verified_at = CASE WHEN url = ? THEN verified_at END, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		webhook.Url, webhook.Enabled, webhook.DeliveryMode, webhook.BatchMaxEvents, webhook.BatchIntervalSeconds,
+		webhook.Url, now(), webhook.ID, webhook.UserID)
+	if err != nil {
+		return err
+	}
+	return recordWebhookEvent(ctx, r.db, webhook.ID, postgres.EventWebhookUpdated)
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, id, userID uuid.UUID) error {
+	err := exec(ctx, r.db, `UPDATE webhooks SET deleted_at = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		now(), id, userID)
+	if err != nil {
+		return err
+	}
+	return recordWebhookEvent(ctx, r.db, id, postgres.EventWebhookDeleted)
+}
+
+func (r *WebhookRepo) MarkVerified(ctx context.Context, id, userID uuid.UUID, url string) error {
+	t := now()
+	err := exec(ctx, r.db, `UPDATE webhooks SET verified_at = ?, updated_at = ? WHERE id = ? AND user_id = ? AND url = ? AND deleted_at IS NULL`,
+		t, t, id, userID, url)
+	if err != nil {
+		return err
+	}
+	return recordWebhookEvent(ctx, r.db, id, postgres.EventWebhookVerified)
+}
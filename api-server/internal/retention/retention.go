@@ -0,0 +1,426 @@
+// Package retention archives records past their retention window to object storage and reads
+// them back. Alerts older than ARCHIVE_ALERT_RETENTION are written in batches, and transactions
+// partitions detached by MaintainPartitions as a whole, each as a gzip-compressed NDJSON object
+// with a JSON manifest beside it. Every object is recorded in archived_ranges before the records
+// are deleted, so a run interrupted midway is repeated by the next one, rewriting the same keys.
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/objectstore"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of archived records
+const (
+	KindAlerts       = "alerts"
+	KindTransactions = "transactions"
+)
+
+// Format is the format of archive objects, written in their manifests
+const Format = "ndjson+gzip"
+
+// ManifestVersion is the version of the manifests written
+const ManifestVersion = 1
+
+// partitionNameLayout is the layout of transactions partition names, see MaintainPartitions
+const partitionNameLayout = "transactions_p2006_01"
+
+// Alert is an archived alert, with the transfer that triggered it while it was still stored
+type Alert struct {
+	ID             uuid.UUID          `json:"id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	AddressID      uuid.UUID          `json:"address_id"`
+	RuleID         pgtype.UUID        `json:"rule_id"`
+	TransactionID  pgtype.UUID        `json:"transaction_id"`
+	Message        string             `json:"message"`
+	Severity       string             `json:"severity"`
+	AcknowledgedAt pgtype.Timestamptz `json:"acknowledged_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	Transfer       *Transfer          `json:"transfer,omitempty"`
+}
+
+type Transfer struct {
+	Chain        string             `json:"chain"`
+	Hash         string             `json:"hash"`
+	LogIndex     pgtype.Int4        `json:"log_index"`
+	FromAddress  string             `json:"from_address"`
+	ToAddress    pgtype.Text        `json:"to_address"`
+	Value        pgtype.Numeric     `json:"value"`
+	TokenAddress pgtype.Text        `json:"token_address"`
+	Status       string             `json:"status"`
+	OccurredAt   pgtype.Timestamptz `json:"occurred_at"`
+}
+
+// Manifest describes an archive object, stored beside it under the object's key with a
+// .manifest.json suffix
+type Manifest struct {
+	Version    int       `json:"version"`
+	Kind       string    `json:"kind"`
+	Object     string    `json:"object"`
+	Format     string    `json:"format"`
+	RangeStart time.Time `json:"range_start"`
+	RangeEnd   time.Time `json:"range_end"`
+	Records    int       `json:"records"`
+	Bytes      int64     `json:"bytes"`
+	SHA256     string    `json:"sha256"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Stats counts the records and objects of an archival run
+type Stats struct {
+	Alerts     int   `json:"alerts"`
+	Partitions int   `json:"partitions"`
+	Objects    int   `json:"objects"`
+	Bytes      int64 `json:"bytes"`
+}
+
+// Archiver moves aged records of db to store
+type Archiver struct {
+	db    *postgres.Database
+	repo  postgres.IRetentionInterface
+	store objectstore.Store
+	cfg   config.Archive
+}
+
+func New(db *postgres.Database, store objectstore.Store, cfg config.Archive) *Archiver {
+	return &Archiver{
+		db:    db,
+		repo:  postgres.NewRetentionRepository(db.Conn()),
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Run archives aged records now and every ARCHIVE_INTERVAL until ctx is done
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		stats, err := a.RunOnce(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to archive aged records: %v", err)
+		}
+		if stats.Objects > 0 {
+			log.Printf("Archived %d alerts and %d transactions partitions in %d objects",
+				stats.Alerts, stats.Partitions, stats.Objects)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives the alerts created more than ARCHIVE_ALERT_RETENTION before now and the
+// detached transactions partitions. It does nothing while another api-server instance is
+// archiving.
+func (a *Archiver) RunOnce(ctx context.Context, now time.Time) (Stats, error) {
+	var stats Stats
+	_, err := a.db.WithArchiveLock(ctx, func() error {
+		if err := a.archiveAlerts(ctx, now.Add(-a.cfg.AlertRetention), &stats); err != nil {
+			return fmt.Errorf("failed to archive alerts: %w", err)
+		}
+		if err := a.archivePartitions(ctx, &stats); err != nil {
+			return fmt.Errorf("failed to archive transactions partitions: %w", err)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// archiveAlerts archives the alerts created before before, ARCHIVE_BATCH_SIZE per object, and
+// deletes them
+func (a *Archiver) archiveAlerts(ctx context.Context, before time.Time, stats *Stats) error {
+	for {
+		rows, err := a.repo.ListAgedAlerts(ctx, before, int32(a.cfg.BatchSize))
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		out := newObjectWriter(&buf)
+		ids := make([]uuid.UUID, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+			if err := out.write(archivedAlert(row)); err != nil {
+				return err
+			}
+		}
+		if err := out.close(); err != nil {
+			return err
+		}
+
+		first, last := rows[0].CreatedAt.Time.UTC(), rows[len(rows)-1].CreatedAt.Time.UTC()
+		key := fmt.Sprintf("%s/%s/%s.ndjson.gz", KindAlerts, first.Format("2006/01/02"), rows[0].ID)
+		archived, err := a.put(ctx, KindAlerts, key, bytes.NewReader(buf.Bytes()), out, first, last)
+		if err != nil {
+			return err
+		}
+
+		// The object is recorded in the transaction deleting its alerts, so alerts are only
+		// gone once they can be found again
+		err = a.db.WithTx(ctx, func(tx pgx.Tx) error {
+			repo := postgres.NewRetentionRepository(tx)
+			if err := repo.SaveArchivedRange(ctx, archived); err != nil {
+				return err
+			}
+			_, err := repo.DeleteAlerts(ctx, ids)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete alerts archived in %s: %w", key, err)
+		}
+		stats.Alerts += len(rows)
+		stats.Objects++
+		stats.Bytes += out.bytes
+
+		if len(rows) < a.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// archivePartitions archives every detached transactions partition in one object and drops it
+func (a *Archiver) archivePartitions(ctx context.Context, stats *Stats) error {
+	names, err := a.db.DetachedPartitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		start, err := time.Parse(partitionNameLayout, name)
+		if err != nil {
+			continue
+		}
+		size, err := a.archivePartition(ctx, name, start)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := a.db.DropPartition(ctx, name); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", name, err)
+		}
+		log.Printf("Archived and dropped transactions partition %s", name)
+		stats.Partitions++
+		stats.Objects++
+		stats.Bytes += size
+	}
+	return nil
+}
+
+// archivePartition writes the rows of the partition name, holding the transactions of the month
+// starting at start, to a temporary file and stores it, returning its size
+func (a *Archiver) archivePartition(ctx context.Context, name string, start time.Time) (int64, error) {
+	// Partitions may be larger than memory, the object is staged on disk
+	f, err := os.CreateTemp("", name+"-*.ndjson.gz")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	out := newObjectWriter(f)
+	err = a.db.ReadPartition(ctx, name, func(row json.RawMessage, _ time.Time) error {
+		return out.write(row)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := out.close(); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	key := fmt.Sprintf("%s/%s.ndjson.gz", KindTransactions, name)
+	archived, err := a.put(ctx, KindTransactions, key, f, out, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return 0, err
+	}
+	if err := a.repo.SaveArchivedRange(ctx, archived); err != nil {
+		return 0, err
+	}
+	return out.bytes, nil
+}
+
+// put stores the object key written by out with its manifest, returning its archived range
+func (a *Archiver) put(ctx context.Context, kind, key string, body io.ReadSeeker, out *objectWriter, start, end time.Time) (sqlc.UpsertArchivedRangeParams, error) {
+	sum := hex.EncodeToString(out.hash.Sum(nil))
+	manifest, err := json.MarshalIndent(Manifest{
+		Version:    ManifestVersion,
+		Kind:       kind,
+		Object:     key,
+		Format:     Format,
+		RangeStart: start,
+		RangeEnd:   end,
+		Records:    out.records,
+		Bytes:      out.bytes,
+		SHA256:     sum,
+		CreatedAt:  time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return sqlc.UpsertArchivedRangeParams{}, err
+	}
+
+	// The manifest goes last, so one without its object is never seen
+	if err := a.store.Put(ctx, key, body); err != nil {
+		return sqlc.UpsertArchivedRangeParams{}, err
+	}
+	if err := a.store.Put(ctx, key+".manifest.json", bytes.NewReader(manifest)); err != nil {
+		return sqlc.UpsertArchivedRangeParams{}, err
+	}
+
+	return sqlc.UpsertArchivedRangeParams{
+		ID:         uuid.New(),
+		Kind:       kind,
+		ObjectKey:  key,
+		RangeStart: pgtype.Timestamptz{Time: start, Valid: true},
+		RangeEnd:   pgtype.Timestamptz{Time: end, Valid: true},
+		Records:    int32(out.records),
+		Bytes:      out.bytes,
+		Sha256:     sum,
+	}, nil
+}
+
+// Retrieve writes the archived alerts of userID created in [from, to) to w as NDJSON, oldest
+// object first, returning their number
+func (a *Archiver) Retrieve(ctx context.Context, userID uuid.UUID, from, to time.Time, w io.Writer) (int, error) {
+	ranges, err := a.repo.ListArchivedRanges(ctx, KindAlerts, from, to)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	n := 0
+	for _, r := range ranges {
+		err := a.read(ctx, r.ObjectKey, func(alert Alert) error {
+			if alert.UserID != userID || alert.CreatedAt.Before(from) || !alert.CreatedAt.Before(to) {
+				return nil
+			}
+			n++
+			return enc.Encode(alert)
+		})
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// read calls fn with every alert of the archive object key
+func (a *Archiver) read(ctx context.Context, key string, fn func(Alert) error) error {
+	body, err := a.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	dec := json.NewDecoder(gz)
+	for dec.More() {
+		var alert Alert
+		if err := dec.Decode(&alert); err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if err := fn(alert); err != nil {
+			return err
+		}
+	}
+	return gz.Close()
+}
+
+func archivedAlert(row sqlc.ListAgedAlertsRow) Alert {
+	alert := Alert{
+		ID:             row.ID,
+		UserID:         row.UserID,
+		AddressID:      row.AddressID,
+		RuleID:         row.RuleID,
+		TransactionID:  row.TransactionID,
+		Message:        row.Message,
+		Severity:       row.Severity,
+		AcknowledgedAt: row.AcknowledgedAt,
+		CreatedAt:      row.CreatedAt.Time.UTC(),
+	}
+	// The transfer is missing once its partition was detached
+	if row.Chain.Valid {
+		alert.Transfer = &Transfer{
+			Chain:        row.Chain.String,
+			Hash:         row.Hash.String,
+			LogIndex:     row.LogIndex,
+			FromAddress:  row.FromAddress.String,
+			ToAddress:    row.ToAddress,
+			Value:        row.Value,
+			TokenAddress: row.TokenAddress,
+			Status:       row.Status.String,
+			OccurredAt:   row.OccurredAt,
+		}
+	}
+	return alert
+}
+
+// objectWriter compresses NDJSON records into an archive object, counting them and hashing and
+// counting the compressed bytes
+type objectWriter struct {
+	gz      *gzip.Writer
+	hash    hash.Hash
+	records int
+	bytes   int64
+}
+
+func newObjectWriter(w io.Writer) *objectWriter {
+	out := &objectWriter{hash: sha256.New()}
+	out.gz = gzip.NewWriter(io.MultiWriter(w, out.hash, (*counter)(&out.bytes)))
+	return out
+}
+
+// write appends record as a line, as is when it is already JSON
+func (o *objectWriter) write(record any) error {
+	line, ok := record.(json.RawMessage)
+	if !ok {
+		var err error
+		if line, err = json.Marshal(record); err != nil {
+			return err
+		}
+	}
+	if _, err := o.gz.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	o.records++
+	return nil
+}
+
+// close flushes the object, after which its hash and size are final
+func (o *objectWriter) close() error {
+	return o.gz.Close()
+}
+
+// counter counts the bytes written to it
+type counter int64
+
+func (c *counter) Write(p []byte) (int, error) {
+	*c += counter(len(p))
+	return len(p), nil
+}
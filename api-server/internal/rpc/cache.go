@@ -0,0 +1,246 @@
+package rpc
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Selectors of the ERC-20 calls whose results are cached
+const (
+	selectorName      = "0x06fdde03"
+	selectorSymbol    = "0x95d89b41"
+	selectorDecimals  = "0x313ce567"
+	selectorBalanceOf = "0x70a08231"
+)
+
+// Cache holds the results of idempotent node calls: blocks, transactions and receipts by number
+// or hash, token metadata and balances. Results are kept in memory, bounded by
+// CHAIN_CACHE_MAX_ENTRIES, and, when CHAIN_CACHE_REDIS_URL is set, in Redis, shared by the
+// replicas. Blocks by number are cached for CHAIN_CACHE_BLOCK_TTL, which should stay short of the
+// time a reorg could replace them.
+type Cache struct {
+	prefix string
+	ttls   config.ChainCache
+	redis  *redis.Client
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// entry is a result held in memory
+type entry struct {
+	key     string
+	value   json.RawMessage
+	expires time.Time
+}
+
+// NewCache creates the cache of the calls to the node of network, nil when both tiers are
+// disabled
+func NewCache(network string, cfg config.ChainCache) (*Cache, error) {
+	if cfg.MaxEntries == 0 && cfg.RedisURL == "" {
+		return nil, nil
+	}
+	c := &Cache{
+		prefix:  "rpc:" + strings.ToLower(network) + ":",
+		ttls:    cfg,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+	if cfg.RedisURL != "" {
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHAIN_CACHE_REDIS_URL: %w", err)
+		}
+		c.redis = redis.NewClient(opts)
+	}
+	return c, nil
+}
+
+// get returns the cached result of method with params
+func (c *Cache) get(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, bool) {
+	if c == nil || c.ttl(method, params) == 0 {
+		return nil, false
+	}
+	key := c.key(method, params)
+	if value, ok := c.load(key); ok {
+		return value, true
+	}
+	if c.redis == nil {
+		return nil, false
+	}
+
+	value, err := c.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Failed to read %s from the RPC cache: %v", method, err)
+		}
+		return nil, false
+	}
+	// Redis hits are kept in memory for what is left of their TTL
+	if ttl, err := c.redis.PTTL(ctx, key).Result(); err == nil && ttl > 0 {
+		c.store(key, value, ttl)
+	}
+	return value, true
+}
+
+// set caches result, the result of method with params, unless it may still change
+func (c *Cache) set(ctx context.Context, method string, params, result json.RawMessage) {
+	if c == nil || !final(method, result) {
+		return
+	}
+	ttl := c.ttl(method, params)
+	if ttl == 0 {
+		return
+	}
+	key := c.key(method, params)
+	c.store(key, result, ttl)
+	if c.redis != nil {
+		if err := c.redis.Set(ctx, key, []byte(result), ttl).Err(); err != nil {
+			log.Printf("Failed to write %s to the RPC cache: %v", method, err)
+		}
+	}
+}
+
+// key names the result of method with params
+func (c *Cache) key(method string, params json.RawMessage) string {
+	sum := sha256.Sum256(params)
+	return c.prefix + method + ":" + hex.EncodeToString(sum[:])
+}
+
+// load returns the result held in memory under key
+func (c *Cache) load(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if time.Now().After(e.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+// store holds value in memory under key for ttl, evicting the least recently used results past
+// the bound
+func (c *Cache) store(key string, value json.RawMessage, ttl time.Duration) {
+	if c.ttls.MaxEntries == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.ttls.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// ttl returns how long the result of method with params is cached, 0 when it is not
+func (c *Cache) ttl(method string, params json.RawMessage) time.Duration {
+	var args []json.RawMessage
+	_ = json.Unmarshal(params, &args)
+	arg := func(i int) string {
+		var s string
+		if i < len(args) {
+			_ = json.Unmarshal(args[i], &s)
+		}
+		return s
+	}
+
+	switch method {
+	case "eth_chainId", "net_version":
+		return c.ttls.MetadataTTL
+	case "eth_getBlockByHash", "eth_getTransactionByHash", "eth_getTransactionReceipt",
+		"getblockhash", "getblock", "getblockheader", "getrawtransaction":
+		return c.ttls.BlockTTL
+	case "eth_getBlockByNumber":
+		if isBlockNumber(arg(0)) {
+			return c.ttls.BlockTTL
+		}
+	case "eth_getBalance":
+		return c.atBlock(arg(1))
+	case "eth_call":
+		var call struct {
+			Data  string `json:"data"`
+			Input string `json:"input"`
+		}
+		if len(args) > 0 {
+			_ = json.Unmarshal(args[0], &call)
+		}
+		data := call.Input
+		if data == "" {
+			data = call.Data
+		}
+		switch strings.ToLower(data[:min(len(data), 10)]) {
+		case selectorName, selectorSymbol, selectorDecimals:
+			return c.ttls.MetadataTTL
+		case selectorBalanceOf:
+			return c.atBlock(arg(1))
+		}
+	}
+	return 0
+}
+
+// atBlock returns how long a balance read at block is cached: the block TTL at a block number,
+// the balance TTL at the latest block and not at all at other tags, e.g. pending
+func (c *Cache) atBlock(block string) time.Duration {
+	switch {
+	case isBlockNumber(block):
+		return c.ttls.BlockTTL
+	case block == "" || block == "latest":
+		return c.ttls.BalanceTTL
+	}
+	return 0
+}
+
+// isBlockNumber tells whether block is a hex block number rather than a tag
+func isBlockNumber(block string) bool {
+	return strings.HasPrefix(block, "0x")
+}
+
+// final tells whether result, a result of method, no longer changes. Unknown and pending
+// transactions are looked up again, as they may yet be mined.
+func final(method string, result json.RawMessage) bool {
+	if len(result) == 0 || string(result) == "null" {
+		return false
+	}
+	switch method {
+	case "eth_getTransactionByHash":
+		var tx struct {
+			BlockNumber *string `json:"blockNumber"`
+		}
+		return json.Unmarshal(result, &tx) == nil && tx.BlockNumber != nil
+	case "getrawtransaction":
+		var tx struct {
+			Confirmations int64 `json:"confirmations"`
+		}
+		return json.Unmarshal(result, &tx) == nil && tx.Confirmations > 0
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+// Package rpc calls the JSON-RPC API of the blockchain node configured by CHAIN_RPC_URL: an
+// Ethereum-compatible node, or Bitcoin Core when CHAIN_NETWORK is bitcoin. CHAIN_RPC_API_KEY, when
+// set, is sent as a bearer token; Bitcoin Core's credentials go in the URL's user info. Both are
+// read on every call, so reloads take effect. Idempotent calls are served from a Cache.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// timeout bounds each call
+const timeout = 10 * time.Second
+
+// ErrNotConfigured fails the calls made while no node URL is set
+var ErrNotConfigured = errors.New("no node is configured")
+
+// Error is an error answered by the node
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("node error %d: %s", e.Code, e.Message)
+}
+
+// Client calls a node
+type Client struct {
+	endpoint func() (url, apiKey string)
+	cache    *Cache
+	client   *http.Client
+	nextID   atomic.Int64
+}
+
+// New creates a client of the node endpoint returns the URL and API key of. Results are cached in
+// cache, unless it is nil.
+func New(endpoint func() (url, apiKey string), cache *Cache) *Client {
+	return &Client{
+		endpoint: endpoint,
+		cache:    cache,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Call calls method with params and decodes its result into result
+func (c *Client) Call(ctx context.Context, result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s call: %w", method, err)
+	}
+
+	raw, cached := c.cache.get(ctx, method, encoded)
+	if !cached {
+		if raw, err = c.call(ctx, method, encoded); err != nil {
+			return err
+		}
+		c.cache.set(ctx, method, encoded, raw)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// call sends one request to the node and returns its raw result
+func (c *Client) call(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, error) {
+	url, apiKey := c.endpoint()
+	if url == "" {
+		return nil, ErrNotConfigured
+	}
+	body, err := json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      int64           `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}{"2.0", c.nextID.Add(1), method, params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s call: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	// Bitcoin Core answers errors with 4xx and 5xx statuses, but still with a JSON-RPC body
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 8<<20)).Decode(&reply); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s answered %s", method, resp.Status)
+		}
+		return nil, fmt.Errorf("failed to decode %s reply: %w", method, err)
+	}
+	if reply.Error != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, reply.Error)
+	}
+	if len(reply.Result) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return reply.Result, nil
+}
@@ -0,0 +1,253 @@
+// Package seed fills a development database with demo users, their watched addresses on several
+// chains, alert rules and a history of transfers and alerts, so a fresh environment can be used
+// right away. The data is fixed: every run creates the same IDs, emails and hashes.
+package seed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Password is the password of every demo user
+const Password = "Demo-pass1"
+
+// ErrSeeded is returned when the database already holds the demo data
+var ErrSeeded = errors.New("database is already seeded")
+
+// namespace derives the IDs of the demo records from their names
+var namespace = uuid.MustParse("5b0e7f5e-2f4e-4c43-9b0f-7c1f0f6a8d21")
+
+type user struct {
+	email, phone, wallet string
+	subscribed           bool
+}
+
+type address struct {
+	user                         int
+	chain, address, label, notes string
+}
+
+type rule struct {
+	address         int
+	name, direction string
+	minValue        string
+	cooldown        int32
+	enabled         bool
+	severity        string
+}
+
+var users = []user{
+	{"alice@example.com", "+14155550101", "0x71c7656ec7ab88b098defb751b7401b5f6d8976f", true},
+	{"bob@example.com", "+14155550102", "0x2546bcd3c84621e976d8185a91a922ae77ecec30", false},
+}
+
+var addresses = []address{
+	{0, "ethereum", "0xd8da6bf26964af9d7eed9e03e53415d37aa96045", "Cold wallet", "Hardware wallet, long-term holdings"},
+	{0, "polygon", "0x9fe46736679d2d9a65f0992f2272de9f3c7fa6e0", "Polygon hot wallet", "Used for daily payments"},
+	{0, "bitcoin", "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh", "Savings", ""},
+	{1, "ethereum", "0xbe0eb53f46cd790cd13851d5eff43d12404d33e8", "Exchange deposit", "Watch for large outflows"},
+	{1, "arbitrum", "0xf977814e90da44bfa03b6295a0616a897441acec", "Bridge funds", ""},
+}
+
+var rules = []rule{
+	{0, "Any incoming transfer", "in", "0", 0, true, "info"},
+	{0, "Large outgoing transfer", "out", "1000000000000000000", 3600, true, "warning"},
+	{1, "Any movement", "any", "0", 300, true, "info"},
+	{2, "Incoming over 0.1 BTC", "in", "10000000", 0, true, "warning"},
+	{3, "Outflows over 100 ETH", "out", "100000000000000000000", 0, true, "critical"},
+	{4, "Any incoming transfer", "in", "0", 0, false, "info"},
+}
+
+// transfersPerAddress is the number of transfers seeded for every address, one a day going back
+const transfersPerAddress = 6
+
+// Stats counts the seeded records
+type Stats struct {
+	Users        int `json:"users"`
+	Addresses    int `json:"addresses"`
+	AlertRules   int `json:"alert_rules"`
+	Transactions int `json:"transactions"`
+	Alerts       int `json:"alerts"`
+}
+
+// Run creates the demo data in one transaction of tx, failing with ErrSeeded when the demo users
+// exist
+func Run(ctx context.Context, tx postgres.ITxManager) (Stats, error) {
+	var stats Stats
+	err := tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		stats = Stats{}
+		_, err := repos.Users.GetUser(ctx, users[0].email)
+		if err == nil {
+			return ErrSeeded
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return seed(ctx, repos, &stats)
+	})
+	return stats, err
+}
+
+func seed(ctx context.Context, repos postgres.Repositories, stats *Stats) error {
+	hash, err := utils.HashPassword(Password)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make([]uuid.UUID, len(users))
+	for i, u := range users {
+		userIDs[i] = id("user", u.email)
+		_, err := repos.Users.CreateNewUser(ctx, sqlc.CreateUserParams{
+			ID:            userIDs[i],
+			Email:         u.email,
+			PasswordHash:  hash,
+			PhoneNumber:   pgtype.Text{String: u.phone, Valid: true},
+			WalletAddress: pgtype.Text{String: u.wallet, Valid: true},
+			Subscribed:    u.subscribed,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create user %s: %w", u.email, err)
+		}
+		stats.Users++
+	}
+
+	addressIDs := make([]uuid.UUID, len(addresses))
+	for i, a := range addresses {
+		addressIDs[i] = id("address", a.chain, a.address)
+		_, err := repos.Addresses.CreateAddress(ctx, sqlc.CreateAddressParams{
+			ID:      addressIDs[i],
+			UserID:  userIDs[a.user],
+			Chain:   a.chain,
+			Address: a.address,
+			Label:   text(a.label),
+			Notes:   text(a.notes),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create address %s: %w", a.address, err)
+		}
+		stats.Addresses++
+	}
+
+	ruleIDs := make([]uuid.UUID, len(rules))
+	for i, r := range rules {
+		a := addresses[r.address]
+		ruleIDs[i] = id("rule", a.chain, a.address, r.name)
+		minValue, _ := new(big.Int).SetString(r.minValue, 10)
+		_, err := repos.AlertRules.CreateRule(ctx, sqlc.CreateAlertRuleParams{
+			ID:              ruleIDs[i],
+			UserID:          userIDs[a.user],
+			AddressID:       pgtype.UUID{Bytes: addressIDs[r.address], Valid: true},
+			Name:            r.name,
+			Direction:       r.direction,
+			MinValue:        pgtype.Numeric{Int: minValue, Valid: true},
+			CooldownSeconds: r.cooldown,
+			Enabled:         r.enabled,
+			Severity:        r.severity,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create rule %q: %w", r.name, err)
+		}
+		stats.AlertRules++
+	}
+
+	// One transfer a day per address, alternating in and out, with alerts from the address's
+	// enabled rules of that direction. Alerts older than two days are acknowledged.
+	now := time.Now().UTC().Truncate(time.Hour)
+	for i, a := range addresses {
+		for n := range transfersPerAddress {
+			incoming := n%2 == 0
+			counterparty := "0x" + digest("counterparty", a.chain, a.address)[:40]
+			from, to := a.address, counterparty
+			if incoming {
+				from, to = counterparty, a.address
+			}
+			value := new(big.Int).Mul(big.NewInt(int64(n+1)*25), new(big.Int).Exp(big.NewInt(10), big.NewInt(17), nil))
+			txHash := digest("transaction", a.chain, a.address, fmt.Sprint(n))
+			if a.chain != "bitcoin" {
+				txHash = "0x" + txHash
+			}
+			txID, err := repos.Transactions.SaveTransaction(ctx, sqlc.UpsertTransactionParams{
+				ID:          id("transaction", txHash),
+				Chain:       a.chain,
+				Hash:        txHash,
+				BlockNumber: int64(19_000_000 - n*7_200),
+				BlockHash:   "0x" + digest("block", a.chain, fmt.Sprint(n)),
+				FromAddress: from,
+				ToAddress:   pgtype.Text{String: to, Valid: true},
+				Value:       pgtype.Numeric{Int: value, Valid: true},
+				Status:      "confirmed",
+				OccurredAt:  pgtype.Timestamptz{Time: now.Add(-time.Duration(n) * 24 * time.Hour), Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create transaction %s: %w", txHash, err)
+			}
+			stats.Transactions++
+
+			for j, r := range rules {
+				if r.address != i || !r.enabled || !matches(r, incoming, value) {
+					continue
+				}
+				alertID := id("alert", txHash, r.name)
+				direction := "outgoing"
+				if incoming {
+					direction = "incoming"
+				}
+				_, err := repos.Alerts.CreateAlert(ctx, sqlc.CreateAlertParams{
+					ID:            alertID,
+					UserID:        userIDs[a.user],
+					AddressID:     addressIDs[i],
+					RuleID:        pgtype.UUID{Bytes: ruleIDs[j], Valid: true},
+					TransactionID: pgtype.UUID{Bytes: txID, Valid: true},
+					Message:       fmt.Sprintf("%s: %s transfer of %s on %s", r.name, direction, value, a.chain),
+					Severity:      r.severity,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to create alert for %s: %w", txHash, err)
+				}
+				stats.Alerts++
+				if n > 2 {
+					if err := repos.Alerts.AcknowledgeAlert(ctx, alertID, userIDs[a.user]); err != nil {
+						return fmt.Errorf("failed to acknowledge alert for %s: %w", txHash, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether a transfer of value in the direction given by incoming triggers r
+func matches(r rule, incoming bool, value *big.Int) bool {
+	if r.direction == "in" && !incoming || r.direction == "out" && incoming {
+		return false
+	}
+	minValue, _ := new(big.Int).SetString(r.minValue, 10)
+	return value.Cmp(minValue) >= 0
+}
+
+// id derives the ID of a demo record from its names
+func id(names ...string) uuid.UUID {
+	return uuid.NewSHA1(namespace, []byte(fmt.Sprint(names)))
+}
+
+// digest returns a hex hash of names, used for fake transaction and block hashes
+func digest(names ...string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprint(names)))
+	return hex.EncodeToString(sum[:])
+}
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
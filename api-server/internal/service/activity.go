@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IActivityService lists the activity of the user's addresses and account
+type IActivityService interface {
+	// AddressActivity returns a page of the address's transactions, alerts and changes of the
+	// address and its rules, newest first
+	AddressActivity(ctx context.Context, userID, id string, req dto.ListActivityRequest) (int, *dto.Page[dto.ActivityItem], error)
+	// AccountActivity returns a page of the user's sign-ins and account changes, newest first
+	AccountActivity(ctx context.Context, userID string, req dto.ListActivityRequest) (int, *dto.Page[dto.AccountActivityItem], error)
+	// AddressTransactions returns a page of the address's transaction history, newest first
+	AddressTransactions(ctx context.Context, userID, id string, req dto.ListTransactionsRequest) (int, *dto.Page[dto.AddressTransaction], error)
+}
+
+// accountAddressEvents are the kinds of address events shown in the account's activity as well
+var accountAddressEvents = []string{postgres.EventAddressCreated, postgres.EventAddressDeleted}
+
+type ActivityService struct {
+	addresses     postgres.IAddressInterface
+	transactions  postgres.ITransactionInterface
+	alerts        postgres.IAlertInterface
+	events        postgres.IAddressEventInterface
+	accountEvents postgres.IAccountEventInterface
+}
+
+func NewActivityService(addresses postgres.IAddressInterface, transactions postgres.ITransactionInterface,
+	alerts postgres.IAlertInterface, events postgres.IAddressEventInterface, accountEvents postgres.IAccountEventInterface) IActivityService {
+	return &ActivityService{
+		addresses:     addresses,
+		transactions:  transactions,
+		alerts:        alerts,
+		events:        events,
+		accountEvents: accountEvents,
+	}
+}
+
+// feedEntry is an entry of a feed with the keyset it is ordered by
+type feedEntry[T any] struct {
+	createdAt pgtype.Timestamptz
+	id        uuid.UUID
+	item      T
+}
+
+func feedKey[T any](e feedEntry[T]) (pgtype.Timestamptz, uuid.UUID) { return e.createdAt, e.id }
+
+// activity and accountActivity are the entries of the address and account feeds
+type (
+	activity        = feedEntry[dto.ActivityItem]
+	accountActivity = feedEntry[dto.AccountActivityItem]
+)
+
+var (
+	activityKey        = feedKey[dto.ActivityItem]
+	accountActivityKey = feedKey[dto.AccountActivityItem]
+)
+
+// AddressActivity reads a page of each listing with the same cursor and merges them, so the next
+// page of every listing continues after the last entry returned
+func (s *ActivityService) AddressActivity(ctx context.Context, userID, id string, req dto.ListActivityRequest) (int, *dto.Page[dto.ActivityItem], error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	addressID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	page := postgres.PageRequest{Cursor: req.Cursor, Limit: int32(req.Limit)}
+	if _, err := page.Query(); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	address, err := s.addresses.GetAddress(ctx, *addressID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+
+	transactions, err := s.transactions.ListTransactions(ctx, address.Chain, address.Address, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	alerts, err := s.alerts.ListAddressAlerts(ctx, address.ID, *uid, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	events, err := s.events.ListAddressEvents(ctx, address.ID, *uid, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	merged := postgres.MergePages(page, activityKey,
+		mapPage(transactions, func(t sqlc.Transaction) activity { return transactionActivity(address, t) }),
+		mapPage(alerts, alertActivity),
+		mapPage(events, eventActivity))
+
+	return fiber.StatusOK, feedPage(req, merged), nil
+}
+
+// AccountActivity merges the account's audit log with the additions and removals of its
+// addresses, recorded with the other address events, like AddressActivity
+func (s *ActivityService) AccountActivity(ctx context.Context, userID string, req dto.ListActivityRequest) (int, *dto.Page[dto.AccountActivityItem], error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	page := postgres.PageRequest{Cursor: req.Cursor, Limit: int32(req.Limit)}
+	if _, err := page.Query(); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	events, err := s.accountEvents.ListAccountEvents(ctx, *uid, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	addressEvents, err := s.events.ListUserAddressEvents(ctx, *uid, accountAddressEvents, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	merged := postgres.MergePages(page, accountActivityKey,
+		mapPage(events, accountEventActivity),
+		mapPage(addressEvents, addressAccountActivity))
+
+	return fiber.StatusOK, feedPage(req, merged), nil
+}
+
+// AddressTransactions lists the transfers stored by the engine from or to the address. Transfers
+// detected before the address was added are listed too, since they are stored once per chain.
+func (s *ActivityService) AddressTransactions(ctx context.Context, userID, id string, req dto.ListTransactionsRequest) (int, *dto.Page[dto.AddressTransaction], error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	addressID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	page := postgres.PageRequest{Cursor: req.Cursor, Limit: int32(req.Limit)}
+	if _, err := page.Query(); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	address, err := s.addresses.GetAddress(ctx, *addressID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+
+	transactions, err := s.transactions.ListTransactions(ctx, address.Chain, address.Address, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	limit := postgres.DefaultPageSize
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+	res := &dto.Page[dto.AddressTransaction]{
+		Items: make([]dto.AddressTransaction, len(transactions.Items)),
+		Pagination: dto.Pagination{
+			Limit:      limit,
+			Count:      len(transactions.Items),
+			NextCursor: transactions.NextCursor,
+		},
+	}
+	for i, t := range transactions.Items {
+		res.Items[i] = dto.AddressTransaction{
+			ID:                  t.ID.String(),
+			ActivityTransaction: *activityTransaction(address, t),
+			DetectedAt:          t.CreatedAt.Time,
+		}
+	}
+	return fiber.StatusOK, res, nil
+}
+
+// feedPage is the response of merged, a page of a feed read with req
+func feedPage[T any](req dto.ListActivityRequest, merged postgres.Page[feedEntry[T]]) *dto.Page[T] {
+	limit := postgres.DefaultPageSize
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+	res := &dto.Page[T]{
+		Items: make([]T, len(merged.Items)),
+		Pagination: dto.Pagination{
+			Limit:      limit,
+			Count:      len(merged.Items),
+			NextCursor: merged.NextCursor,
+		},
+	}
+	for i, e := range merged.Items {
+		res.Items[i] = e.item
+	}
+	return res
+}
+
+// mapPage converts the items of a page with fn
+func mapPage[T, U any](page postgres.Page[T], fn func(T) U) postgres.Page[U] {
+	items := make([]U, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = fn(item)
+	}
+	return postgres.Page[U]{Items: items, NextCursor: page.NextCursor}
+}
+
+func transactionActivity(address *sqlc.Address, t sqlc.Transaction) activity {
+	return activity{t.CreatedAt, t.ID, dto.ActivityItem{
+		Type:        "transaction",
+		ID:          t.ID.String(),
+		At:          t.CreatedAt.Time,
+		Transaction: activityTransaction(address, t),
+	}}
+}
+
+// activityTransaction returns t as a transfer in, out of or to address itself
+func activityTransaction(address *sqlc.Address, t sqlc.Transaction) *dto.ActivityTransaction {
+	to := utils.PgTextToString(t.ToAddress)
+	direction := "in"
+	switch {
+	case strings.EqualFold(t.FromAddress, address.Address) && strings.EqualFold(to, address.Address):
+		direction = "self"
+	case strings.EqualFold(t.FromAddress, address.Address):
+		direction = "out"
+	}
+	return &dto.ActivityTransaction{
+		Chain:       t.Chain,
+		Hash:        t.Hash,
+		LogIndex:    t.LogIndex,
+		BlockNumber: t.BlockNumber,
+		From:        t.FromAddress,
+		To:          to,
+		Direction:   direction,
+		Value:       utils.PgNumericToString(t.Value),
+		Token:       utils.PgTextToString(t.TokenAddress),
+		Status:      t.Status,
+		OccurredAt:  t.OccurredAt.Time,
+	}
+}
+
+func alertActivity(a sqlc.Alert) activity {
+	alert := &dto.ActivityAlert{
+		TransactionID: a.TransactionID.String(),
+		Message:       a.Message,
+		Severity:      a.Severity,
+	}
+	if a.RuleID.Valid {
+		alert.RuleID = uuid.UUID(a.RuleID.Bytes).String()
+	}
+	if a.AcknowledgedAt.Valid {
+		alert.AcknowledgedAt = &a.AcknowledgedAt.Time
+	}
+	return activity{a.CreatedAt, a.ID, dto.ActivityItem{
+		Type:  "alert",
+		ID:    a.ID.String(),
+		At:    a.CreatedAt.Time,
+		Alert: alert,
+	}}
+}
+
+func eventActivity(e sqlc.AddressEvent) activity {
+	change := &dto.ActivityChange{Kind: e.Kind}
+	if e.RuleID.Valid {
+		change.RuleID = uuid.UUID(e.RuleID.Bytes).String()
+	}
+	if len(e.Detail) > 0 && string(e.Detail) != "{}" {
+		change.Detail = e.Detail
+	}
+	return activity{e.CreatedAt, e.ID, dto.ActivityItem{
+		Type:   "change",
+		ID:     e.ID.String(),
+		At:     e.CreatedAt.Time,
+		Change: change,
+	}}
+}
+
+func accountEventActivity(e sqlc.AccountEvent) accountActivity {
+	item := dto.AccountActivityItem{
+		ID:        e.ID.String(),
+		Kind:      e.Kind,
+		At:        e.CreatedAt.Time,
+		IPAddress: utils.PgTextToString(e.IpAddress),
+		UserAgent: utils.PgTextToString(e.UserAgent),
+	}
+	if len(e.Detail) > 0 && string(e.Detail) != "{}" {
+		item.Detail = e.Detail
+	}
+	return accountActivity{e.CreatedAt, e.ID, item}
+}
+
+func addressAccountActivity(e sqlc.AddressEvent) accountActivity {
+	item := dto.AccountActivityItem{
+		ID:   e.ID.String(),
+		Kind: e.Kind,
+		At:   e.CreatedAt.Time,
+	}
+	if e.AddressID.Valid {
+		item.AddressID = uuid.UUID(e.AddressID.Bytes).String()
+	}
+	if len(e.Detail) > 0 && string(e.Detail) != "{}" {
+		item.Detail = e.Detail
+	}
+	return accountActivity{e.CreatedAt, e.ID, item}
+}
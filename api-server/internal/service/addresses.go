@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IAddressService manages the user's watched addresses. The engine picks up the changes from the
+// addresses table, see GET /addresses/{id}/status.
+type IAddressService interface {
+	ListAddresses(ctx context.Context, userID string, req dto.ListAddressesRequest) (int, *dto.Page[dto.AddressResponse], error)
+	GetAddress(ctx context.Context, userID, id string) (int, *dto.AddressResponse, error)
+	CreateAddress(ctx context.Context, userID string, req dto.CreateAddressRequest) (int, *dto.AddressResponse, error)
+	// UpdateAddress replaces the label and notes of the address unless it was changed since
+	// req.Version, in which case it fails with 409
+	UpdateAddress(ctx context.Context, userID, id string, req dto.UpdateAddressRequest) (int, *dto.AddressResponse, error)
+	// DeleteAddress stops watching the address and deletes the rules that apply only to it,
+	// returning it as it was
+	DeleteAddress(ctx context.Context, userID, id string) (int, *dto.AddressResponse, error)
+}
+
+type AddressService struct {
+	repo postgres.IAddressInterface
+}
+
+func NewAddressService(repo postgres.IAddressInterface) IAddressService {
+	return &AddressService{
+		repo: repo,
+	}
+}
+
+func (s *AddressService) ListAddresses(ctx context.Context, userID string, req dto.ListAddressesRequest) (int, *dto.Page[dto.AddressResponse], error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	page := postgres.PageRequest{Cursor: req.Cursor, Limit: int32(req.Limit)}
+	if _, err := page.Query(); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	addresses, err := s.repo.ListAddresses(ctx, *uid, page)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+
+	limit := postgres.DefaultPageSize
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+	res := &dto.Page[dto.AddressResponse]{
+		Items: make([]dto.AddressResponse, len(addresses.Items)),
+		Pagination: dto.Pagination{
+			Limit:      limit,
+			Count:      len(addresses.Items),
+			NextCursor: addresses.NextCursor,
+		},
+	}
+	for i := range addresses.Items {
+		res.Items[i] = addressResponse(&addresses.Items[i])
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *AddressService) GetAddress(ctx context.Context, userID, id string) (int, *dto.AddressResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	addressID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	address, err := s.repo.GetAddress(ctx, *addressID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	res := addressResponse(address)
+	return fiber.StatusOK, &res, nil
+}
+
+func (s *AddressService) CreateAddress(ctx context.Context, userID string, req dto.CreateAddressRequest) (int, *dto.AddressResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	chain, ok := chains.Get(req.Chain)
+	if !ok {
+		return fiber.StatusBadRequest, nil, fmt.Errorf("unsupported chain %q", req.Chain)
+	}
+	if !chains.ValidAddress(chain.Name, req.Address) {
+		return fiber.StatusBadRequest, nil, fmt.Errorf("%q is not a %s address", req.Address, chain.Name)
+	}
+
+	id, err := s.repo.CreateAddress(ctx, sqlc.CreateAddressParams{
+		ID:      uuid.New(),
+		UserID:  *uid,
+		Chain:   chain.Name,
+		Address: chains.NormalizeAddress(chain.Name, req.Address),
+		Label:   optionalText(req.Label),
+		Notes:   optionalText(req.Notes),
+	})
+	if errors.Is(err, postgres.ErrDuplicate) {
+		return fiber.StatusConflict, nil, fmt.Errorf("the address is already watched on %s", chain.Name)
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to create address: %w", err)
+	}
+
+	address, err := s.repo.GetAddress(ctx, id, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	res := addressResponse(address)
+	return fiber.StatusCreated, &res, nil
+}
+
+func (s *AddressService) UpdateAddress(ctx context.Context, userID, id string, req dto.UpdateAddressRequest) (int, *dto.AddressResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	addressID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	err = s.repo.UpdateDetails(ctx, *addressID, *uid, optionalText(req.Label), optionalText(req.Notes), req.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to update address: %w", err)
+	}
+
+	address, err := s.repo.GetAddress(ctx, *addressID, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	res := addressResponse(address)
+	return fiber.StatusOK, &res, nil
+}
+
+func (s *AddressService) DeleteAddress(ctx context.Context, userID, id string) (int, *dto.AddressResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	addressID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	address, err := s.repo.GetAddress(ctx, *addressID, *uid)
+	if err == nil {
+		err = s.repo.DeleteAddress(ctx, *addressID, *uid)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to delete address: %w", err)
+	}
+	res := addressResponse(address)
+	return fiber.StatusOK, &res, nil
+}
+
+// optionalText is s, NULL when empty
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func addressResponse(a *sqlc.Address) dto.AddressResponse {
+	return dto.AddressResponse{
+		ID:         a.ID.String(),
+		Chain:      a.Chain,
+		Address:    a.Address,
+		Label:      utils.PgTextToString(a.Label),
+		Notes:      utils.PgTextToString(a.Notes),
+		MutedAt:    optionalTime(a.MutedAt),
+		MutedUntil: optionalTime(a.MutedUntil),
+		Version:    a.Version,
+		CreatedAt:  a.CreatedAt.Time,
+		UpdatedAt:  a.UpdatedAt.Time,
+	}
+}
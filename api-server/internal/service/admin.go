@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// statsWindow is the period the alert and notification figures cover
+	statsWindow = 24 * time.Hour
+	// headWindow bounds the transactions searched for chain heads, so only the latest partitions
+	// are scanned
+	headWindow = 7 * 24 * time.Hour
+	// engineStatsTimeout bounds the request to the engine admin server
+	engineStatsTimeout = 2 * time.Second
+)
+
+// IAdminService aggregates the state of the whole deployment for the operations dashboard
+type IAdminService interface {
+	Stats(ctx context.Context) (int, *dto.AdminStatsResponse, error)
+}
+
+type AdminService struct {
+	repo      postgres.IStatsInterface
+	engineURL string
+	client    *http.Client
+}
+
+// NewAdminService creates the admin service. engineURL is the base URL of the engine admin server
+// the consumer lag is read from, empty leaves it out.
+func NewAdminService(repo postgres.IStatsInterface, engineURL string) IAdminService {
+	return &AdminService{
+		repo:      repo,
+		engineURL: strings.TrimSuffix(engineURL, "/"),
+		client:    &http.Client{Timeout: engineStatsTimeout},
+	}
+}
+
+func (s *AdminService) Stats(ctx context.Context) (int, *dto.AdminStatsResponse, error) {
+	now := time.Now()
+	res := &dto.AdminStatsResponse{GeneratedAt: now.UTC()}
+
+	users, err := s.repo.CountUsers(ctx)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	res.Users = users
+
+	addresses, err := s.repo.CountAddressesByChain(ctx)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to count addresses: %w", err)
+	}
+	res.Addresses.ByChain = make([]dto.ChainAddressStats, len(addresses))
+	for i, a := range addresses {
+		res.Addresses.Total += a.Addresses
+		res.Addresses.ByChain[i] = dto.ChainAddressStats{Chain: a.Chain, Addresses: a.Addresses, Users: a.Users}
+	}
+
+	if res.Alerts24h, err = s.repo.CountAlertsSince(ctx, now.Add(-statsWindow)); err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to count alerts: %w", err)
+	}
+
+	deliveries, err := s.repo.CountDeliveriesSince(ctx, now.Add(-statsWindow))
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to count notification deliveries: %w", err)
+	}
+	res.Notifications.ByChannel = []dto.ChannelDeliveryStats{}
+	for _, d := range deliveries {
+		n := len(res.Notifications.ByChannel)
+		if n == 0 || res.Notifications.ByChannel[n-1].Channel != d.Channel {
+			res.Notifications.ByChannel = append(res.Notifications.ByChannel, dto.ChannelDeliveryStats{Channel: d.Channel})
+			n++
+		}
+		channel := &res.Notifications.ByChannel[n-1]
+		switch d.Status {
+		case "sent":
+			channel.Sent += d.Count
+			res.Notifications.Sent += d.Count
+		case "failed":
+			channel.Failed += d.Count
+			res.Notifications.Failed += d.Count
+		case "skipped":
+			channel.Skipped += d.Count
+			res.Notifications.Skipped += d.Count
+		default:
+			channel.Pending += d.Count
+			res.Notifications.Pending += d.Count
+		}
+	}
+	for i := range res.Notifications.ByChannel {
+		channel := &res.Notifications.ByChannel[i]
+		channel.FailureRate = failureRate(channel.Sent, channel.Failed)
+	}
+	res.Notifications.FailureRate = failureRate(res.Notifications.Sent, res.Notifications.Failed)
+
+	heads, err := s.repo.ListChainHeads(ctx, now.Add(-headWindow))
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to list chain heads: %w", err)
+	}
+	// Every chain with watched addresses is listed, with or without recent transactions
+	res.Chains = []dto.ChainHeadStats{}
+	chains := map[string]int{}
+	for _, a := range addresses {
+		chains[a.Chain] = len(res.Chains)
+		res.Chains = append(res.Chains, dto.ChainHeadStats{Chain: a.Chain})
+	}
+	for _, h := range heads {
+		i, ok := chains[h.Chain]
+		if !ok {
+			i = len(res.Chains)
+			res.Chains = append(res.Chains, dto.ChainHeadStats{Chain: h.Chain})
+		}
+		block, headTime := h.BlockNumber, h.OccurredAt.Time
+		lag := max(now.Sub(headTime), 0).Seconds()
+		res.Chains[i].HeadBlock, res.Chains[i].HeadTime, res.Chains[i].HeadLagSeconds = &block, &headTime, &lag
+	}
+
+	if s.engineURL != "" {
+		res.Consumer = s.consumerStats(ctx)
+	}
+	return fiber.StatusOK, res, nil
+}
+
+// consumerStats reads the consumer lag from the /stats of the engine admin server, which reports it
+// under the name of its transport. A failure is reported in the result rather than failing the
+// whole snapshot.
+func (s *AdminService) consumerStats(ctx context.Context) dto.ConsumerStats {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.engineURL+"/stats", nil)
+	if err != nil {
+		return dto.ConsumerStats{Error: err.Error()}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return dto.ConsumerStats{Error: fmt.Sprintf("failed to reach the engine: %v", err)}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return dto.ConsumerStats{Error: fmt.Sprintf("engine stats returned %s", resp.Status)}
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&sections); err != nil {
+		return dto.ConsumerStats{Error: fmt.Sprintf("failed to decode engine stats: %v", err)}
+	}
+	for name, section := range sections {
+		var stats struct {
+			ConsumerLag  *int64     `json:"consumer_lag"`
+			LagCheckedAt *time.Time `json:"lag_checked_at"`
+		}
+		if json.Unmarshal(section, &stats) == nil && stats.ConsumerLag != nil {
+			return dto.ConsumerStats{Transport: name, Lag: stats.ConsumerLag, CheckedAt: stats.LagCheckedAt}
+		}
+	}
+	return dto.ConsumerStats{}
+}
+
+// failureRate is the share of failed among the finished deliveries, 0 when none finished
+func failureRate(sent, failed int64) float64 {
+	if sent+failed == 0 {
+		return 0
+	}
+	return float64(failed) / float64(sent+failed)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultAnalyticsDays is the number of days reported when none is given
+const defaultAnalyticsDays = 30
+
+// IAnalyticsService reports the user's API usage
+type IAnalyticsService interface {
+	Usage(ctx context.Context, userID string, req dto.APIAnalyticsRequest) (int, *dto.APIAnalyticsResponse, error)
+}
+
+type AnalyticsService struct {
+	repo postgres.IAPIUsageInterface
+}
+
+func NewAnalyticsService(repo postgres.IAPIUsageInterface) IAnalyticsService {
+	return &AnalyticsService{
+		repo: repo,
+	}
+}
+
+// Usage reports the usage of the last req.Days UTC days, today included
+func (s *AnalyticsService) Usage(ctx context.Context, userID string, req dto.APIAnalyticsRequest) (int, *dto.APIAnalyticsResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	days := defaultAnalyticsDays
+	if req.Days > 0 {
+		days = req.Days
+	}
+	to := utils.ToPgDate(time.Now()).Time
+	from := to.AddDate(0, 0, 1-days)
+
+	rows, err := s.repo.ListUsage(ctx, *uid, from)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	res := &dto.APIAnalyticsResponse{
+		From:      from.Format(time.DateOnly),
+		To:        to.Format(time.DateOnly),
+		Endpoints: []dto.EndpointUsage{},
+		Days:      []dto.DailyUsage{},
+	}
+	// Rows come by day, then endpoint
+	totals := map[[2]string]*sqlc.ApiUsage{}
+	var order [][2]string
+	for i := range rows {
+		row := &rows[i]
+		date := row.Day.Time.Format(time.DateOnly)
+		if n := len(res.Days); n == 0 || res.Days[n-1].Date != date {
+			res.Days = append(res.Days, dto.DailyUsage{Date: date})
+		}
+		day := &res.Days[len(res.Days)-1]
+		day.Calls += row.Calls
+		day.ClientErrors += row.ClientErrors
+		day.ServerErrors += row.ServerErrors
+		day.Endpoints = append(day.Endpoints, endpointUsage(row))
+
+		key := [2]string{row.Method, row.Route}
+		total, ok := totals[key]
+		if !ok {
+			total = &sqlc.ApiUsage{Method: row.Method, Route: row.Route}
+			totals[key] = total
+			order = append(order, key)
+		}
+		total.Calls += row.Calls
+		total.ClientErrors += row.ClientErrors
+		total.ServerErrors += row.ServerErrors
+		total.TotalLatencyUs += row.TotalLatencyUs
+		total.MaxLatencyUs = max(total.MaxLatencyUs, row.MaxLatencyUs)
+	}
+	// Endpoints first seen on different days, ordered like those of a day
+	slices.SortFunc(order, func(a, b [2]string) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+	for _, key := range order {
+		res.Endpoints = append(res.Endpoints, endpointUsage(totals[key]))
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func endpointUsage(u *sqlc.ApiUsage) dto.EndpointUsage {
+	res := dto.EndpointUsage{
+		Method:       u.Method,
+		Route:        u.Route,
+		Calls:        u.Calls,
+		ClientErrors: u.ClientErrors,
+		ServerErrors: u.ServerErrors,
+		MaxLatencyMs: float64(u.MaxLatencyUs) / 1000,
+	}
+	if u.Calls > 0 {
+		res.AvgLatencyMs = float64(u.TotalLatencyUs) / float64(u.Calls) / 1000
+	}
+	return res
+}
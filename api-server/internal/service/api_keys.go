@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/signing"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// apiKeyPrefix starts every API key, so leaked keys are easy to recognize
+	apiKeyPrefix = "baw_"
+	// apiKeyPrefixLength is the length of the start of the key kept to identify it to its owner
+	apiKeyPrefixLength = 12
+	// apiKeyTouchInterval bounds how often last_used_at is written for a busy key
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	errInvalidAPIKey    = errors.New("invalid or expired API key")
+	errAPIKeyNotAllowed = errors.New("the API key is not allowed from this address")
+	errAPIKeySigns      = errors.New("the API key signs its requests, send a signature instead of the key")
+	errInvalidSignature = errors.New("invalid request signature")
+	errStaleSignature   = errors.New("the request timestamp is too far from the server's clock")
+	errReplayedRequest  = errors.New("the request nonce was already used")
+)
+
+// SignedRequest is a request signed with the secret of an API key, see package signing
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	// URI is the path and query of the request as sent
+	URI  string
+	Body []byte
+	IP   string
+}
+
+// IAPIKeyService manages the user's API keys and authenticates requests presenting one. A key
+// reaches the routes of its scopes only, and can be restricted to a list of networks, so a leaked
+// key of a server-to-server integration is useless from anywhere else.
+type IAPIKeyService interface {
+	CreateKey(ctx context.Context, userID string, req dto.CreateAPIKeyRequest) (int, *dto.CreatedAPIKeyResponse, error)
+	ListKeys(ctx context.Context, userID string) (int, []dto.APIKeyResponse, error)
+	RevokeKey(ctx context.Context, userID, id string) (int, error)
+	SetAllowedCIDRs(ctx context.Context, userID, id string, req dto.SetAPIKeyAllowedCIDRsRequest) (int, *dto.APIKeyResponse, error)
+	SetScopes(ctx context.Context, userID, id string, req dto.SetAPIKeyScopesRequest) (int, *dto.APIKeyResponse, error)
+	// Authenticate returns the active key presented by a request from the address ip, failing
+	// with 401 for unknown, revoked and expired keys and 403 outside the key's networks
+	Authenticate(ctx context.Context, key, ip string) (int, *sqlc.ApiKey, error)
+	// AuthenticateSigned returns the active key a request was signed with, failing with 401 for
+	// unknown, revoked and expired keys, invalid signatures, timestamps past the allowed clock
+	// skew and replayed nonces, and with 403 outside the key's networks
+	AuthenticateSigned(ctx context.Context, req SignedRequest) (int, *sqlc.ApiKey, error)
+}
+
+type APIKeyService struct {
+	keys postgres.IAPIKeyInterface
+}
+
+func NewAPIKeyService(keys postgres.IAPIKeyInterface) IAPIKeyService {
+	return &APIKeyService{
+		keys: keys,
+	}
+}
+
+func (s *APIKeyService) CreateKey(ctx context.Context, userID string, req dto.CreateAPIKeyRequest) (int, *dto.CreatedAPIKeyResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	cidrs, err := normalizeCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to generate the key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	params := sqlc.CreateApiKeyParams{
+		ID:           uuid.New(),
+		UserID:       *uid,
+		Name:         req.Name,
+		Prefix:       key[:apiKeyPrefixLength],
+		KeyHash:      tokenHash(key),
+		AllowedCidrs: cidrs,
+		Scopes:       scopes,
+	}
+	// Signed keys get a secret of their own, stored encrypted and only returned here
+	var signingSecret string
+	if req.Signed {
+		encryptionKey, err := signing.ParseEncryptionKey(config.GetConfig().APIKeyEncryptionKey)
+		if err != nil {
+			return fiber.StatusServiceUnavailable, nil, err
+		}
+		if signingSecret, err = signing.NewSecret(); err != nil {
+			return fiber.StatusInternalServerError, nil, err
+		}
+		if params.SigningSecretEncrypted, err = signing.Encrypt(encryptionKey, params.ID.String(), signingSecret); err != nil {
+			return fiber.StatusInternalServerError, nil, err
+		}
+	}
+	if req.ExpiresInDays > 0 {
+		params.ExpiresAt = pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, req.ExpiresInDays), Valid: true}
+	}
+	if _, err := s.keys.CreateKey(ctx, params); err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return fiber.StatusCreated, &dto.CreatedAPIKeyResponse{
+		APIKeyResponse: dto.APIKeyResponse{
+			ID:           params.ID.String(),
+			Name:         params.Name,
+			Prefix:       params.Prefix,
+			Scopes:       scopes,
+			AllowedCIDRs: cidrs,
+			Signed:       req.Signed,
+			ExpiresAt:    optionalTime(params.ExpiresAt),
+			CreatedAt:    time.Now().UTC(),
+		},
+		Key:           key,
+		SigningSecret: signingSecret,
+	}, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, userID string) (int, []dto.APIKeyResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	keys, err := s.keys.ListKeys(ctx, *uid)
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	res := make([]dto.APIKeyResponse, len(keys))
+	for i, k := range keys {
+		res[i] = apiKeyResponse(k)
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID, id string) (int, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, errors.New("token has no user ID, log in again")
+	}
+	keyID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, err
+	}
+
+	err = s.keys.RevokeKey(ctx, *keyID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, errors.New("API key not found")
+	}
+	if err != nil {
+		return errorStatus(err), fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return fiber.StatusNoContent, nil
+}
+
+func (s *APIKeyService) SetAllowedCIDRs(ctx context.Context, userID, id string, req dto.SetAPIKeyAllowedCIDRsRequest) (int, *dto.APIKeyResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	keyID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	cidrs, err := normalizeCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	err = s.keys.SetAllowedCIDRs(ctx, *keyID, *uid, cidrs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("API key not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to update API key: %w", err)
+	}
+	return s.getKey(ctx, *uid, *keyID)
+}
+
+func (s *APIKeyService) SetScopes(ctx context.Context, userID, id string, req dto.SetAPIKeyScopesRequest) (int, *dto.APIKeyResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	keyID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	err = s.keys.SetScopes(ctx, *keyID, *uid, scopes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("API key not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to update API key: %w", err)
+	}
+	return s.getKey(ctx, *uid, *keyID)
+}
+
+// getKey returns the user's key keyID, which the repository only lists
+func (s *APIKeyService) getKey(ctx context.Context, userID, keyID uuid.UUID) (int, *dto.APIKeyResponse, error) {
+	keys, err := s.keys.ListKeys(ctx, userID)
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	i := slices.IndexFunc(keys, func(k sqlc.ApiKey) bool { return k.ID == keyID })
+	if i < 0 {
+		return fiber.StatusNotFound, nil, errors.New("API key not found")
+	}
+	res := apiKeyResponse(keys[i])
+	return fiber.StatusOK, &res, nil
+}
+
+func (s *APIKeyService) Authenticate(ctx context.Context, key, ip string) (int, *sqlc.ApiKey, error) {
+	k, err := s.keys.GetActiveKey(ctx, tokenHash(key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusUnauthorized, nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	// A signed key is not to be sent
+	if k.SigningSecretEncrypted != nil {
+		return fiber.StatusUnauthorized, nil, errAPIKeySigns
+	}
+	if !cidrsContain(k.AllowedCidrs, ip) {
+		return fiber.StatusForbidden, nil, errAPIKeyNotAllowed
+	}
+
+	if err := s.touchKey(ctx, k); err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, k, nil
+}
+
+func (s *APIKeyService) AuthenticateSigned(ctx context.Context, req SignedRequest) (int, *sqlc.ApiKey, error) {
+	keyID, err := uuid.Parse(req.KeyID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errInvalidAPIKey
+	}
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errInvalidSignature
+	}
+	signedAt := time.Unix(unix, 0)
+	skew := config.GetConfig().SignatureMaxSkew
+	if d := time.Since(signedAt); d > skew || d < -skew {
+		return fiber.StatusUnauthorized, nil, errStaleSignature
+	}
+	if len(req.Nonce) < signing.MinNonceLength || len(req.Nonce) > signing.MaxNonceLength {
+		return fiber.StatusUnauthorized, nil, errInvalidSignature
+	}
+
+	k, err := s.keys.GetActiveKeyByID(ctx, keyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusUnauthorized, nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if k.SigningSecretEncrypted == nil {
+		return fiber.StatusUnauthorized, nil, errInvalidAPIKey
+	}
+	encryptionKey, err := signing.ParseEncryptionKey(config.GetConfig().APIKeyEncryptionKey)
+	if err != nil {
+		return fiber.StatusServiceUnavailable, nil, err
+	}
+	// Fails for keys revoked by the migration to encrypted secrets and after the encryption key
+	// changed, which invalidates every signed key
+	secret, err := signing.Decrypt(encryptionKey, k.ID.String(), k.SigningSecretEncrypted)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errInvalidAPIKey
+	}
+	if !signing.Verify(secret, req.Method, req.URI, req.Timestamp, req.Nonce, req.Body, req.Signature) {
+		return fiber.StatusUnauthorized, nil, errInvalidSignature
+	}
+	if !cidrsContain(k.AllowedCidrs, req.IP) {
+		return fiber.StatusForbidden, nil, errAPIKeyNotAllowed
+	}
+
+	// The nonce is remembered for as long as its timestamp is accepted, only then can a replay
+	// pass the skew check
+	err = s.keys.UseNonce(ctx, k.ID, req.Nonce, signedAt.Add(skew))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusUnauthorized, nil, errReplayedRequest
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to record the request nonce: %w", err)
+	}
+
+	if err := s.touchKey(ctx, k); err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, k, nil
+}
+
+// touchKey records the use of the key, at most once per apiKeyTouchInterval
+func (s *APIKeyService) touchKey(ctx context.Context, k *sqlc.ApiKey) error {
+	if k.LastUsedAt.Valid && time.Since(k.LastUsedAt.Time) <= apiKeyTouchInterval {
+		return nil
+	}
+	if err := s.keys.TouchKey(ctx, k.ID); err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
+
+// normalizeScopes checks scopes are known, returning them without duplicates in the order of
+// jwt.Scopes
+func normalizeScopes(list []string) ([]string, error) {
+	if len(list) == 0 {
+		return nil, errors.New("an API key needs at least one scope")
+	}
+	for _, s := range list {
+		if !slices.Contains(jwt.Scopes, s) {
+			return nil, fmt.Errorf("unknown scope %q, expected one of %s", s, strings.Join(jwt.Scopes, ", "))
+		}
+	}
+	scopes := []string{}
+	for _, s := range jwt.Scopes {
+		if slices.Contains(list, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
+
+// normalizeCIDRs parses networks in CIDR notation or single addresses, returning them masked in
+// canonical form without duplicates
+func normalizeCIDRs(list []string) ([]string, error) {
+	cidrs := []string{}
+	for _, s := range list {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%q is not a network in CIDR notation or an IP address", s)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		c := prefix.Masked().String()
+		if !slices.Contains(cidrs, c) {
+			cidrs = append(cidrs, c)
+		}
+	}
+	return cidrs, nil
+}
+
+// cidrsContain reports whether ip is in one of cidrs, or cidrs is empty
+func cidrsContain(cidrs []string, ip string) bool {
+	if len(cidrs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, c := range cidrs {
+		if prefix, err := netip.ParsePrefix(c); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func apiKeyResponse(k sqlc.ApiKey) dto.APIKeyResponse {
+	scopes, cidrs := k.Scopes, k.AllowedCidrs
+	if scopes == nil {
+		scopes = []string{}
+	}
+	if cidrs == nil {
+		cidrs = []string{}
+	}
+	return dto.APIKeyResponse{
+		ID:           k.ID.String(),
+		Name:         k.Name,
+		Prefix:       k.Prefix,
+		Scopes:       scopes,
+		AllowedCIDRs: cidrs,
+		Signed:       k.SigningSecretEncrypted != nil,
+		LastUsedAt:   optionalTime(k.LastUsedAt),
+		ExpiresAt:    optionalTime(k.ExpiresAt),
+		RevokedAt:    optionalTime(k.RevokedAt),
+		CreatedAt:    k.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/severity"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultBalanceWatchInterval is how often a watch created without an interval is polled, in
+// seconds
+const defaultBalanceWatchInterval = 300
+
+// IBalanceWatchService manages the ERC-20 balances of the user's addresses that are polled with
+// balanceOf, see package balances
+type IBalanceWatchService interface {
+	ListWatches(ctx context.Context, userID, addressID string) (int, []dto.BalanceWatchResponse, error)
+	CreateWatch(ctx context.Context, userID, addressID string, req dto.CreateBalanceWatchRequest) (int, *dto.BalanceWatchResponse, error)
+	UpdateWatch(ctx context.Context, userID, id string, req dto.UpdateBalanceWatchRequest) (int, *dto.BalanceWatchResponse, error)
+	// DeleteWatch deletes the watch, returning it as it was
+	DeleteWatch(ctx context.Context, userID, id string) (int, *dto.BalanceWatchResponse, error)
+}
+
+type BalanceWatchService struct {
+	addresses postgres.IAddressInterface
+	watches   postgres.IBalanceWatchInterface
+	network   string
+}
+
+// NewBalanceWatchService creates the balance watch service. Balances are read from a node of
+// network, so only its addresses can be watched.
+func NewBalanceWatchService(addresses postgres.IAddressInterface, watches postgres.IBalanceWatchInterface,
+	network string) IBalanceWatchService {
+	return &BalanceWatchService{
+		addresses: addresses,
+		watches:   watches,
+		network:   strings.ToLower(network),
+	}
+}
+
+func (s *BalanceWatchService) ListWatches(ctx context.Context, userID, addressID string) (int, []dto.BalanceWatchResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	aid, err := utils.StringToUUID(addressID)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	if _, err := s.addresses.GetAddress(ctx, *aid, *uid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fiber.StatusNotFound, nil, errors.New("address not found")
+		}
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	watches, err := s.watches.ListWatches(ctx, *aid, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to list balance watches: %w", err)
+	}
+
+	res := make([]dto.BalanceWatchResponse, len(watches))
+	for i := range watches {
+		res[i] = balanceWatchResponse(&watches[i])
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *BalanceWatchService) CreateWatch(ctx context.Context, userID, addressID string, req dto.CreateBalanceWatchRequest) (int, *dto.BalanceWatchResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	aid, err := utils.StringToUUID(addressID)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	address, err := s.addresses.GetAddress(ctx, *aid, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	if s.network == "bitcoin" || strings.ToLower(address.Chain) != s.network {
+		return fiber.StatusUnprocessableEntity, nil,
+			fmt.Errorf("token balances are read from the %s node, the address is on %s", s.network, address.Chain)
+	}
+
+	params := sqlc.CreateBalanceWatchParams{
+		ID:              uuid.New(),
+		UserID:          *uid,
+		AddressID:       *aid,
+		TokenAddress:    strings.ToLower(req.TokenAddress),
+		IntervalSeconds: req.IntervalSeconds,
+		Severity:        req.Severity,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+	if params.IntervalSeconds == 0 {
+		params.IntervalSeconds = defaultBalanceWatchInterval
+	}
+	if params.Severity == "" {
+		params.Severity = severity.Default
+	}
+	if params.MinDelta, err = tokenAmount(req.MinDelta); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	if _, err := s.watches.CreateWatch(ctx, params); err != nil {
+		if errors.Is(err, postgres.ErrDuplicate) {
+			return fiber.StatusConflict, nil, errors.New("the token's balance is already watched for this address")
+		}
+		return errorStatus(err), nil, fmt.Errorf("failed to create balance watch: %w", err)
+	}
+	watch, err := s.watches.GetWatch(ctx, params.ID, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get balance watch: %w", err)
+	}
+	res := balanceWatchResponse(watch)
+	return fiber.StatusCreated, &res, nil
+}
+
+func (s *BalanceWatchService) UpdateWatch(ctx context.Context, userID, id string, req dto.UpdateBalanceWatchRequest) (int, *dto.BalanceWatchResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	watchID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	params := sqlc.UpdateBalanceWatchParams{
+		ID:              *watchID,
+		UserID:          *uid,
+		IntervalSeconds: req.IntervalSeconds,
+		Severity:        req.Severity,
+		Enabled:         *req.Enabled,
+	}
+	if params.MinDelta, err = tokenAmount(req.MinDelta); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	err = s.watches.UpdateWatch(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("balance watch not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to update balance watch: %w", err)
+	}
+
+	watch, err := s.watches.GetWatch(ctx, *watchID, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get balance watch: %w", err)
+	}
+	res := balanceWatchResponse(watch)
+	return fiber.StatusOK, &res, nil
+}
+
+func (s *BalanceWatchService) DeleteWatch(ctx context.Context, userID, id string) (int, *dto.BalanceWatchResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	watchID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	watch, err := s.watches.GetWatch(ctx, *watchID, *uid)
+	if err == nil {
+		err = s.watches.DeleteWatch(ctx, *watchID, *uid)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("balance watch not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to delete balance watch: %w", err)
+	}
+	res := balanceWatchResponse(watch)
+	return fiber.StatusOK, &res, nil
+}
+
+// tokenAmount parses a base 10 amount in a token's smallest unit, zero when empty
+func tokenAmount(s string) (pgtype.Numeric, error) {
+	if s == "" {
+		return pgtype.Numeric{Int: new(big.Int), Valid: true}, nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 {
+		return pgtype.Numeric{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return pgtype.Numeric{Int: n, Valid: true}, nil
+}
+
+func balanceWatchResponse(w *sqlc.BalanceWatch) dto.BalanceWatchResponse {
+	res := dto.BalanceWatchResponse{
+		ID:              w.ID.String(),
+		AddressID:       w.AddressID.String(),
+		TokenAddress:    w.TokenAddress,
+		IntervalSeconds: w.IntervalSeconds,
+		MinDelta:        utils.PgNumericToString(w.MinDelta),
+		Severity:        w.Severity,
+		Enabled:         w.Enabled,
+		LastError:       utils.PgTextToString(w.LastError),
+		CheckedAt:       optionalTime(w.CheckedAt),
+		NextCheckAt:     w.NextCheckAt.Time,
+		CreatedAt:       w.CreatedAt.Time,
+		UpdatedAt:       w.UpdatedAt.Time,
+	}
+	if w.Balance.Valid {
+		res.Balance = utils.PgNumericToString(w.Balance)
+	}
+	if w.BlockNumber.Valid {
+		res.BlockNumber = &w.BlockNumber.Int64
+	}
+	return res
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/email"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// emailVerificationTTL is how long the link confirming an email stays valid
+const emailVerificationTTL = 24 * time.Hour
+
+// IChannelService verifies the user's notification channels, which alerts are only delivered over
+// once verified: their email by a confirmation link sent to it, a webhook by echoing a challenge
+// posted to it
+type IChannelService interface {
+	ListChannels(ctx context.Context, userID string) (int, []dto.ChannelResponse, error)
+	// SendEmailVerification sends the link confirming the user's email, replacing any earlier link
+	SendEmailVerification(ctx context.Context, userID string) (int, *dto.ChannelResponse, error)
+	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) (int, *dto.ChannelResponse, error)
+	VerifyWebhook(ctx context.Context, userID, id string) (int, *dto.ChannelResponse, error)
+}
+
+// challenger posts the verification challenge to a webhook, see webhooks.Dispatcher.Challenge
+type challenger interface {
+	Challenge(ctx context.Context, url, secret string) error
+}
+
+type ChannelService struct {
+	users      postgres.IUserInterface
+	webhooks   postgres.IWebhookInterface
+	sender     email.Sender
+	challenger challenger
+}
+
+func NewChannelService(users postgres.IUserInterface, webhooks postgres.IWebhookInterface, sender email.Sender,
+	challenger challenger) IChannelService {
+	return &ChannelService{
+		users:      users,
+		webhooks:   webhooks,
+		sender:     sender,
+		challenger: challenger,
+	}
+}
+
+func (s *ChannelService) ListChannels(ctx context.Context, userID string) (int, []dto.ChannelResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	user, err := s.users.GetUserByID(ctx, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	webhooks, err := s.webhooks.ListWebhooks(ctx, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	channels := []dto.ChannelResponse{emailChannel(user)}
+	for _, w := range webhooks {
+		channels = append(channels, webhookChannel(&w))
+	}
+	return fiber.StatusOK, channels, nil
+}
+
+func (s *ChannelService) SendEmailVerification(ctx context.Context, userID string) (int, *dto.ChannelResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	theme := config.GetConfig().Email
+	if theme.AppURL == "" {
+		return fiber.StatusServiceUnavailable, nil, errors.New("email verification is not configured: EMAIL_APP_URL is not set")
+	}
+
+	user, err := s.users.GetUserByID(ctx, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt.Valid {
+		return fiber.StatusConflict, nil, errors.New("email is already verified")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to generate the token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	link := strings.TrimSuffix(theme.AppURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+	msg, err := email.RenderVerification(theme, user.Email, link, emailVerificationTTL)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	err = s.users.CreateEmailVerification(ctx, *uid, user.Email, tokenHash(token), time.Now().Add(emailVerificationTTL))
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to store the verification: %w", err)
+	}
+	if err := s.sender.Send(ctx, user.Email, msg); err != nil {
+		if errors.Is(err, email.ErrNotConfigured) {
+			return fiber.StatusServiceUnavailable, nil, errors.New("email verification is not configured: SMTP_HOST is not set")
+		}
+		return fiber.StatusBadGateway, nil, err
+	}
+	res := emailChannel(user)
+	return fiber.StatusAccepted, &res, nil
+}
+
+func (s *ChannelService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) (int, *dto.ChannelResponse, error) {
+	userID, err := s.users.VerifyEmail(ctx, tokenHash(req.Token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusBadRequest, nil, errors.New("the link is invalid or expired, ask for a new one")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	res := emailChannel(user)
+	return fiber.StatusOK, &res, nil
+}
+
+func (s *ChannelService) VerifyWebhook(ctx context.Context, userID, id string) (int, *dto.ChannelResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	webhookID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	webhook, err := s.webhooks.GetWebhook(ctx, *webhookID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("webhook not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if err := s.challenger.Challenge(ctx, webhook.Url, webhook.Secret); err != nil {
+		return fiber.StatusUnprocessableEntity, nil, fmt.Errorf("webhook verification failed: %w", err)
+	}
+	err = s.webhooks.MarkVerified(ctx, webhook.ID, webhook.UserID, webhook.Url)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusConflict, nil, errors.New("the webhook changed during verification, verify it again")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to record the verification: %w", err)
+	}
+
+	webhook, err = s.webhooks.GetWebhook(ctx, *webhookID, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	res := webhookChannel(webhook)
+	return fiber.StatusOK, &res, nil
+}
+
+// emailChannel is the verification state of the user's email
+func emailChannel(user *sqlc.User) dto.ChannelResponse {
+	return dto.ChannelResponse{
+		Channel:    "email",
+		Target:     user.Email,
+		Verified:   user.EmailVerifiedAt.Valid,
+		VerifiedAt: optionalTime(user.EmailVerifiedAt),
+	}
+}
+
+// webhookChannel is the verification state of webhook
+func webhookChannel(webhook *sqlc.Webhook) dto.ChannelResponse {
+	return dto.ChannelResponse{
+		Channel:    "webhook",
+		Target:     webhook.Url,
+		WebhookID:  webhook.ID.String(),
+		Verified:   webhook.VerifiedAt.Valid,
+		VerifiedAt: optionalTime(webhook.VerifiedAt),
+	}
+}
+
+// tokenHash is the hex SHA-256 an email verification token is stored by
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
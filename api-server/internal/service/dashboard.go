@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/rpc"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// dashboardTopAddresses is the number of most active addresses listed
+	dashboardTopAddresses = 5
+	// portfolioMaxAddresses bounds the balances read from the node for one dashboard
+	portfolioMaxAddresses = 100
+	// portfolioConcurrency is the number of balances read from the node at once
+	portfolioConcurrency = 8
+)
+
+// IDashboardService summarizes the user's watch list
+type IDashboardService interface {
+	Dashboard(ctx context.Context, userID string) (int, *dto.DashboardResponse, error)
+}
+
+type DashboardService struct {
+	repo    postgres.IDashboardInterface
+	network string
+	node    *rpc.Client
+}
+
+// NewDashboardService creates the dashboard service, reading balances of the network node is a
+// node of
+func NewDashboardService(repo postgres.IDashboardInterface, network string, node *rpc.Client) IDashboardService {
+	return &DashboardService{
+		repo:    repo,
+		network: strings.ToLower(network),
+		node:    node,
+	}
+}
+
+// Dashboard counts the user's addresses and recent alerts in one query and lists their most
+// active addresses in another. The portfolio is read from the node, whose balances are cached,
+// and left out rather than failing the dashboard when the node cannot be read.
+func (s *DashboardService) Dashboard(ctx context.Context, userID string) (int, *dto.DashboardResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	now := time.Now()
+	weekSince := now.AddDate(0, 0, -7)
+	counts, err := s.repo.CountActivity(ctx, *uid, now.Add(-24*time.Hour), weekSince)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	top, err := s.repo.ListTopActiveAddresses(ctx, *uid, weekSince, dashboardTopAddresses)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	res := &dto.DashboardResponse{
+		Addresses: dto.DashboardAddresses{
+			Watched: counts.Addresses,
+			Active:  counts.Addresses - counts.MutedAddresses,
+			Muted:   counts.MutedAddresses,
+		},
+		Alerts: dto.DashboardAlerts{
+			LastDay:  counts.AlertsLastDay,
+			LastWeek: counts.AlertsLastWeek,
+		},
+		TopAddresses: make([]dto.DashboardAddress, len(top)),
+		GeneratedAt:  now.UTC(),
+	}
+	for i, a := range top {
+		res.TopAddresses[i] = dto.DashboardAddress{
+			ID:                a.ID.String(),
+			Chain:             a.Chain,
+			Address:           a.Address,
+			Label:             utils.PgTextToString(a.Label),
+			Transactions:      a.Transactions,
+			Alerts:            a.Alerts,
+			LastTransactionAt: a.LastTransactionAt.Time,
+		}
+	}
+
+	if res.Portfolio, err = s.portfolio(ctx, *uid); err != nil {
+		if !errors.Is(err, rpc.ErrNotConfigured) {
+			log.Printf("Failed to read the portfolio of user %s: %v", *uid, err)
+		}
+	}
+	return fiber.StatusOK, res, nil
+}
+
+// portfolio sums the latest native balances of the user's addresses on the node's network. It
+// is nil on Bitcoin, whose nodes do not index balances by address.
+func (s *DashboardService) portfolio(ctx context.Context, userID uuid.UUID) (*dto.DashboardPortfolio, error) {
+	if s.network == "bitcoin" {
+		return nil, nil
+	}
+	addresses, err := s.repo.ListChainAddresses(ctx, userID, s.network, portfolioMaxAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]*big.Int, len(addresses))
+	errs := make([]error, len(addresses))
+	slots := make(chan struct{}, portfolioConcurrency)
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			var balance string
+			if errs[i] = s.node.Call(ctx, &balance, "eth_getBalance", address, "latest"); errs[i] == nil {
+				balances[i], errs[i] = parseHex(balance)
+			}
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	for _, balance := range balances {
+		total.Add(total, balance)
+	}
+	return &dto.DashboardPortfolio{
+		Chain:     s.network,
+		Value:     total.String(),
+		Addresses: len(addresses),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorStatus returns the HTTP status of a repository error: 409 when the request conflicts with
+// existing data or a concurrent update, or is based on a stale version of the record, 422 when it
+// references a missing record and 500 otherwise
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, postgres.ErrDuplicate), errors.Is(err, postgres.ErrConflict),
+		errors.Is(err, postgres.ErrStaleVersion):
+		return fiber.StatusConflict
+	case errors.Is(err, postgres.ErrMissingReference):
+		return fiber.StatusUnprocessableEntity
+	}
+	return fiber.StatusInternalServerError
+}
+
+// registrationError replaces the error of registering a user whose email or wallet address is
+// taken with a message naming the field
+func registrationError(err error) error {
+	var dbErr *postgres.Error
+	if !errors.As(err, &dbErr) || dbErr.Kind != postgres.ErrDuplicate {
+		return err
+	}
+	if strings.Contains(dbErr.Constraint, "wallet_address") {
+		return errors.New("an account with this wallet address already exists")
+	}
+	return errors.New("an account with this email already exists")
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/rpc"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// feeHistoryBlocks is the number of recent blocks the EIP-1559 priority fees are taken from
+	feeHistoryBlocks = 20
+	// baseFeeHeadroom multiplies the next base fee in the max fee, so a transaction stays valid
+	// through several full blocks
+	baseFeeHeadroom = 2
+)
+
+// feePercentiles are the priority fee percentiles of recent blocks paid by the slow, standard and
+// fast tiers
+var feePercentiles = []float64{10, 50, 90}
+
+// bitcoinTargets are the confirmation targets, in blocks, of the slow, standard and fast tiers
+var bitcoinTargets = []int{144, 6, 2}
+
+// IFeeService estimates transaction fees from the configured node
+type IFeeService interface {
+	Fees(ctx context.Context, chain string) (int, *dto.FeesResponse, error)
+}
+
+type FeeService struct {
+	network string
+	node    *rpc.Client
+	ttl     time.Duration
+
+	mu     sync.Mutex
+	cached *dto.FeesResponse
+}
+
+// NewFeeService creates the fee service of the network node is a node of. Estimates are served
+// from memory for ttl.
+func NewFeeService(network string, node *rpc.Client, ttl time.Duration) IFeeService {
+	return &FeeService{
+		network: strings.ToLower(network),
+		node:    node,
+		ttl:     ttl,
+	}
+}
+
+// Fees returns the estimates of chain. Concurrent requests past the TTL wait for one read of the
+// node rather than each reading it.
+func (s *FeeService) Fees(ctx context.Context, chain string) (int, *dto.FeesResponse, error) {
+	if strings.ToLower(chain) != s.network {
+		return fiber.StatusNotFound, nil, fmt.Errorf("no node is configured for chain %q", chain)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && time.Since(s.cached.FetchedAt) < s.ttl {
+		return fiber.StatusOK, s.cached, nil
+	}
+
+	var res *dto.FeesResponse
+	var err error
+	if s.network == "bitcoin" {
+		res, err = s.bitcoinFees(ctx)
+	} else {
+		res, err = s.evmFees(ctx)
+	}
+	if errors.Is(err, rpc.ErrNotConfigured) {
+		return fiber.StatusNotFound, nil, fmt.Errorf("no node is configured for chain %q", chain)
+	}
+	if err != nil {
+		return fiber.StatusBadGateway, nil, fmt.Errorf("failed to read fees from the node: %w", err)
+	}
+	res.Chain = s.network
+	res.FetchedAt = time.Now().UTC()
+	s.cached = res
+	return fiber.StatusOK, res, nil
+}
+
+// evmFees derives the EIP-1559 tiers from the fee history of the latest blocks: the priority fee
+// is the median of the blocks' percentile of the tier and the max fee adds twice the next base
+// fee. Nodes of chains without EIP-1559 answer eth_feeHistory with an error; their tiers all pay
+// the gas price.
+func (s *FeeService) evmFees(ctx context.Context) (*dto.FeesResponse, error) {
+	var history struct {
+		BaseFeePerGas []string   `json:"baseFeePerGas"`
+		Reward        [][]string `json:"reward"`
+	}
+	err := s.node.Call(ctx, &history, "eth_feeHistory", hexUint(feeHistoryBlocks), "latest", feePercentiles)
+	var nodeErr *rpc.Error
+	if errors.As(err, &nodeErr) || err == nil && len(history.BaseFeePerGas) == 0 {
+		return s.legacyFees(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	baseFee, err := parseHex(history.BaseFeePerGas[len(history.BaseFeePerGas)-1])
+	if err != nil {
+		return nil, err
+	}
+	tiers := make([]dto.FeeTier, len(feePercentiles))
+	for i := range feePercentiles {
+		var rewards []*big.Int
+		for _, block := range history.Reward {
+			if i >= len(block) {
+				continue
+			}
+			reward, err := parseHex(block[i])
+			if err != nil {
+				return nil, err
+			}
+			rewards = append(rewards, reward)
+		}
+		priorityFee := median(rewards)
+		maxFee := new(big.Int).Mul(baseFee, big.NewInt(baseFeeHeadroom))
+		tiers[i] = dto.FeeTier{
+			MaxPriorityFee: priorityFee.String(),
+			MaxFee:         maxFee.Add(maxFee, priorityFee).String(),
+		}
+	}
+	return &dto.FeesResponse{BaseFee: baseFee.String(), Slow: tiers[0], Standard: tiers[1], Fast: tiers[2]}, nil
+}
+
+// legacyFees returns the gas price in every tier
+func (s *FeeService) legacyFees(ctx context.Context) (*dto.FeesResponse, error) {
+	var price string
+	if err := s.node.Call(ctx, &price, "eth_gasPrice"); err != nil {
+		return nil, err
+	}
+	gasPrice, err := parseHex(price)
+	if err != nil {
+		return nil, err
+	}
+	tier := dto.FeeTier{GasPrice: gasPrice.String()}
+	return &dto.FeesResponse{Slow: tier, Standard: tier, Fast: tier}, nil
+}
+
+// bitcoinFees reads the fee rate of each tier's confirmation target with estimatesmartfee, which
+// answers in BTC/kvB
+func (s *FeeService) bitcoinFees(ctx context.Context) (*dto.FeesResponse, error) {
+	tiers := make([]dto.FeeTier, len(bitcoinTargets))
+	for i, target := range bitcoinTargets {
+		var estimate struct {
+			FeeRate *float64 `json:"feerate"`
+			Errors  []string `json:"errors"`
+			Blocks  int      `json:"blocks"`
+		}
+		if err := s.node.Call(ctx, &estimate, "estimatesmartfee", target); err != nil {
+			return nil, err
+		}
+		if estimate.FeeRate == nil {
+			return nil, fmt.Errorf("no fee rate for %d blocks: %s", target, strings.Join(estimate.Errors, "; "))
+		}
+		satPerVByte := math.Round(*estimate.FeeRate*1e5*1000) / 1000
+		tiers[i] = dto.FeeTier{SatPerVByte: &satPerVByte, TargetBlocks: estimate.Blocks}
+	}
+	return &dto.FeesResponse{Slow: tiers[0], Standard: tiers[1], Fast: tiers[2]}, nil
+}
+
+// hexUint is n as a JSON-RPC quantity
+func hexUint(n uint64) string {
+	return fmt.Sprintf("0x%x", n)
+}
+
+// parseHex parses a JSON-RPC quantity, e.g. 0x1bf08eb000
+func parseHex(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	return n, nil
+}
+
+// median returns the median of values, zero when there are none
+func median(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	slices.SortFunc(values, (*big.Int).Cmp)
+	return values[len(values)/2]
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/email"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultImpersonationTTL is how long impersonation tokens are valid unless asked otherwise
+const defaultImpersonationTTL = 15 * time.Minute
+
+// IImpersonationService hands support staff tokens acting as a user, to reproduce their issues.
+// Every token is recorded in the user's account activity with the operator and the reason, and
+// the user is emailed about it. Each token has a session of its own, listed with the user's
+// sessions, which ends the token when revoked.
+type IImpersonationService interface {
+	Impersonate(ctx context.Context, userID string, req dto.ImpersonateRequest) (int, *dto.ImpersonationResponse, error)
+}
+
+type ImpersonationService struct {
+	users    postgres.IUserInterface
+	sessions postgres.ISessionInterface
+	events   postgres.IAccountEventInterface
+	sender   email.Sender
+}
+
+func NewImpersonationService(users postgres.IUserInterface, sessions postgres.ISessionInterface, events postgres.IAccountEventInterface, sender email.Sender) IImpersonationService {
+	return &ImpersonationService{
+		users:    users,
+		sessions: sessions,
+		events:   events,
+		sender:   sender,
+	}
+}
+
+func (s *ImpersonationService) Impersonate(ctx context.Context, userID string, req dto.ImpersonateRequest) (int, *dto.ImpersonationResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	user, err := s.users.GetUserByID(ctx, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	ttl := defaultImpersonationTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	sessionID := uuid.New()
+	token, tokenID, expiresAt, err := jwt.GenerateImpersonationJWT(user.ID.String(), user.Email, req.Operator, sessionID.String(), ttl)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to generate the token: %w", err)
+	}
+
+	// The token works while its session is active, so the user or an operator can end it early
+	err = s.sessions.CreateSession(ctx, sqlc.CreateSessionParams{
+		ID:        sessionID,
+		UserID:    user.ID,
+		IpAddress: pgtype.Text{String: req.IPAddress, Valid: req.IPAddress != ""},
+		UserAgent: pgtype.Text{String: req.UserAgent, Valid: req.UserAgent != ""},
+		ExpiresAt: utils.ToPgTime(expiresAt),
+	})
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to create the session: %w", err)
+	}
+
+	// No token is handed out without its audit record
+	err = s.events.RecordEvent(ctx, postgres.AccountEvent{
+		UserID: user.ID,
+		Kind:   postgres.EventImpersonationStarted,
+		Detail: map[string]any{
+			"operator":   req.Operator,
+			"reason":     req.Reason,
+			"token_id":   tokenID,
+			"session_id": sessionID,
+			"expires_at": expiresAt.UTC(),
+		},
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	})
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to record the impersonation: %w", err)
+	}
+
+	// The account activity shows the access whether or not the email goes out
+	theme := config.GetConfig().Email
+	msg, err := email.RenderImpersonation(theme, req.Operator, req.Reason, expiresAt)
+	if err == nil {
+		err = s.sender.Send(ctx, user.Email, msg)
+	}
+	if err != nil {
+		log.Printf("Failed to notify user %s of their impersonation by %s: %v", user.ID, req.Operator, err)
+	}
+
+	return fiber.StatusCreated, &dto.ImpersonationResponse{
+		Token:     token,
+		TokenID:   tokenID,
+		SessionID: sessionID.String(),
+		UserID:    user.ID.String(),
+		Operator:  req.Operator,
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
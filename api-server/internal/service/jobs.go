@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultJobsLimit is the number of jobs listed when no limit is given
+const defaultJobsLimit = 20
+
+// IJobService reports the state of the user's background jobs
+type IJobService interface {
+	ListJobs(ctx context.Context, userID string, req dto.ListJobsRequest) (int, *dto.Page[dto.JobResponse], error)
+	GetJob(ctx context.Context, userID, id string) (int, *dto.JobResponse, error)
+}
+
+type JobService struct {
+	repo postgres.IJobInterface
+}
+
+func NewJobService(repo postgres.IJobInterface) IJobService {
+	return &JobService{
+		repo: repo,
+	}
+}
+
+func (s *JobService) ListJobs(ctx context.Context, userID string, req dto.ListJobsRequest) (int, *dto.Page[dto.JobResponse], error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	limit := int32(defaultJobsLimit)
+	if req.Limit > 0 {
+		limit = int32(req.Limit)
+	}
+
+	jobs, err := s.repo.ListJobs(ctx, *uid, limit)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	res := &dto.Page[dto.JobResponse]{
+		Items:      make([]dto.JobResponse, len(jobs)),
+		Pagination: dto.Pagination{Limit: int(limit), Count: len(jobs)},
+	}
+	for i := range jobs {
+		res.Items[i] = jobResponse(&jobs[i])
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *JobService) GetJob(ctx context.Context, userID, id string) (int, *dto.JobResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	jobID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	job, err := s.repo.GetJob(ctx, *jobID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("job not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	res := jobResponse(&job)
+	return fiber.StatusOK, &res, nil
+}
+
+func jobResponse(job *sqlc.Job) dto.JobResponse {
+	return dto.JobResponse{
+		ID:          job.ID.String(),
+		Kind:        job.Kind,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   utils.PgTextToString(job.LastError),
+		Result:      job.Result,
+		RunAt:       job.RunAt.Time,
+		StartedAt:   optionalTime(job.StartedAt),
+		FinishedAt:  optionalTime(job.FinishedAt),
+		CreatedAt:   job.CreatedAt.Time,
+		UpdatedAt:   job.UpdatedAt.Time,
+	}
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
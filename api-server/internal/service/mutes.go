@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IMuteService mutes and unmutes the user's addresses and rules. The alerts of a muted address or
+// rule are still recorded, but their notifications are skipped.
+type IMuteService interface {
+	MuteAddress(ctx context.Context, userID, id string, req dto.MuteRequest) (int, *dto.MuteResponse, error)
+	UnmuteAddress(ctx context.Context, userID, id string) (int, *dto.MuteResponse, error)
+	MuteRule(ctx context.Context, userID, id string, req dto.MuteRequest) (int, *dto.MuteResponse, error)
+	UnmuteRule(ctx context.Context, userID, id string) (int, *dto.MuteResponse, error)
+}
+
+type MuteService struct {
+	addresses postgres.IAddressInterface
+	rules     postgres.IAlertRuleInterface
+}
+
+func NewMuteService(addresses postgres.IAddressInterface, rules postgres.IAlertRuleInterface) IMuteService {
+	return &MuteService{
+		addresses: addresses,
+		rules:     rules,
+	}
+}
+
+func (s *MuteService) MuteAddress(ctx context.Context, userID, id string, req dto.MuteRequest) (int, *dto.MuteResponse, error) {
+	return mute(ctx, "address", userID, id, req, s.addresses.MuteAddress)
+}
+
+func (s *MuteService) UnmuteAddress(ctx context.Context, userID, id string) (int, *dto.MuteResponse, error) {
+	return unmute(ctx, "address", userID, id, s.addresses.UnmuteAddress)
+}
+
+func (s *MuteService) MuteRule(ctx context.Context, userID, id string, req dto.MuteRequest) (int, *dto.MuteResponse, error) {
+	return mute(ctx, "rule", userID, id, req, s.rules.MuteRule)
+}
+
+func (s *MuteService) UnmuteRule(ctx context.Context, userID, id string) (int, *dto.MuteResponse, error) {
+	return unmute(ctx, "rule", userID, id, s.rules.UnmuteRule)
+}
+
+// mute mutes the record of kind ("address" or "rule") with fn
+func mute(ctx context.Context, kind, userID, id string, req dto.MuteRequest,
+	fn func(ctx context.Context, id, userID uuid.UUID, until pgtype.Timestamptz) error) (int, *dto.MuteResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	recordID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	res := &dto.MuteResponse{ID: recordID.String(), Muted: true}
+	var until pgtype.Timestamptz
+	if req.Until != "" {
+		t, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			return fiber.StatusBadRequest, nil, fmt.Errorf("until is not an RFC 3339 time: %w", err)
+		}
+		if !t.After(time.Now()) {
+			return fiber.StatusBadRequest, nil, errors.New("until must be in the future")
+		}
+		until, res.MutedUntil = utils.ToPgTime(t), &t
+	}
+
+	err = fn(ctx, *recordID, *uid, until)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, fmt.Errorf("%s not found", kind)
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, res, nil
+}
+
+// unmute unmutes the record of kind ("address" or "rule") with fn
+func unmute(ctx context.Context, kind, userID, id string,
+	fn func(ctx context.Context, id, userID uuid.UUID) error) (int, *dto.MuteResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	recordID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	err = fn(ctx, *recordID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, fmt.Errorf("%s not found", kind)
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, &dto.MuteResponse{ID: recordID.String()}, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/ruleexpr"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IRuleExpressionService turns the user's rules into advanced rules and back. The condition of an
+// advanced rule is its CEL expression alone: its direction, min_value and token filters are
+// ignored while it has one.
+type IRuleExpressionService interface {
+	SetExpression(ctx context.Context, userID, id string, req dto.SetRuleExpressionRequest) (int, *dto.RuleExpressionResponse, error)
+	ClearExpression(ctx context.Context, userID, id string) (int, *dto.RuleExpressionResponse, error)
+}
+
+type RuleExpressionService struct {
+	rules postgres.IAlertRuleInterface
+}
+
+func NewRuleExpressionService(rules postgres.IAlertRuleInterface) IRuleExpressionService {
+	return &RuleExpressionService{
+		rules: rules,
+	}
+}
+
+func (s *RuleExpressionService) SetExpression(ctx context.Context, userID, id string, req dto.SetRuleExpressionRequest) (int, *dto.RuleExpressionResponse, error) {
+	// Expressions the engine cannot compile are rejected here, with where the mistake is
+	if _, err := ruleexpr.Compile(req.Expression); err != nil {
+		return fiber.StatusBadRequest, nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	return s.setExpression(ctx, userID, id, pgtype.Text{String: req.Expression, Valid: true})
+}
+
+func (s *RuleExpressionService) ClearExpression(ctx context.Context, userID, id string) (int, *dto.RuleExpressionResponse, error) {
+	return s.setExpression(ctx, userID, id, pgtype.Text{})
+}
+
+// setExpression sets the expression of the rule id, clearing it when expression is NULL
+func (s *RuleExpressionService) setExpression(ctx context.Context, userID, id string, expression pgtype.Text) (int, *dto.RuleExpressionResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	ruleID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	err = s.rules.SetExpression(ctx, *ruleID, *uid, expression)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, &dto.RuleExpressionResponse{ID: ruleID.String(), Expression: expression.String}, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// IRulePriorityService orders the user's rules. The rules applying to a transaction are evaluated
+// by descending priority, and once a rule with stop_processing matches, the rules after it are
+// not evaluated.
+type IRulePriorityService interface {
+	SetPriority(ctx context.Context, userID, id string, req dto.SetRulePriorityRequest) (int, *dto.RulePriorityResponse, error)
+}
+
+type RulePriorityService struct {
+	rules postgres.IAlertRuleInterface
+}
+
+func NewRulePriorityService(rules postgres.IAlertRuleInterface) IRulePriorityService {
+	return &RulePriorityService{
+		rules: rules,
+	}
+}
+
+func (s *RulePriorityService) SetPriority(ctx context.Context, userID, id string, req dto.SetRulePriorityRequest) (int, *dto.RulePriorityResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	ruleID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	err = s.rules.SetPriority(ctx, *ruleID, *uid, *req.Priority, req.StopProcessing)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, &dto.RulePriorityResponse{
+		ID:             ruleID.String(),
+		Priority:       *req.Priority,
+		StopProcessing: req.StopProcessing,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/templates"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/ruleexpr"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IRuleTemplateService lists the rule templates and creates the user's rules from them. A rule
+// created from a template is an advanced rule with the template's expression.
+type IRuleTemplateService interface {
+	ListTemplates(ctx context.Context) (int, []dto.RuleTemplateResponse, error)
+	CreateRule(ctx context.Context, userID, name string, req dto.CreateRuleFromTemplateRequest) (int, *dto.TemplateRuleResponse, error)
+}
+
+type RuleTemplateService struct {
+	addresses postgres.IAddressInterface
+	rules     postgres.IAlertRuleInterface
+}
+
+func NewRuleTemplateService(addresses postgres.IAddressInterface, rules postgres.IAlertRuleInterface) IRuleTemplateService {
+	return &RuleTemplateService{
+		addresses: addresses,
+		rules:     rules,
+	}
+}
+
+func (s *RuleTemplateService) ListTemplates(ctx context.Context) (int, []dto.RuleTemplateResponse, error) {
+	all := templates.All()
+	res := make([]dto.RuleTemplateResponse, len(all))
+	for i, t := range all {
+		res[i] = dto.RuleTemplateResponse{
+			Name:        t.Name,
+			Title:       t.Title,
+			Description: t.Description,
+			Severity:    t.Severity,
+			Expression:  t.Expression,
+		}
+		for _, p := range t.Params {
+			res[i].Params = append(res[i].Params, dto.RuleTemplateParamResponse{
+				Name:        p.Name,
+				Description: p.Description,
+				Default:     p.Default,
+				Min:         p.Min,
+			})
+		}
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *RuleTemplateService) CreateRule(ctx context.Context, userID, name string, req dto.CreateRuleFromTemplateRequest) (int, *dto.TemplateRuleResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	template, ok := templates.Get(name)
+	if !ok {
+		return fiber.StatusNotFound, nil, errors.New("template not found")
+	}
+	expression, err := template.Instantiate(req.Params)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	if _, err := ruleexpr.Compile(expression); err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("template %s does not compile: %w", name, err)
+	}
+
+	params := sqlc.CreateAlertRuleParams{
+		ID:         uuid.New(),
+		UserID:     *uid,
+		Name:       req.Name,
+		Direction:  "any",
+		MinValue:   pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+		Enabled:    true,
+		Severity:   req.Severity,
+		Expression: pgtype.Text{String: expression, Valid: true},
+	}
+	if params.Name == "" {
+		params.Name = template.Title
+	}
+	if params.Severity == "" {
+		params.Severity = template.Severity
+	}
+	if req.AddressID != "" {
+		aid, err := utils.StringToUUID(req.AddressID)
+		if err != nil {
+			return fiber.StatusBadRequest, nil, err
+		}
+		if _, err := s.addresses.GetAddress(ctx, *aid, *uid); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fiber.StatusNotFound, nil, errors.New("address not found")
+			}
+			return errorStatus(err), nil, fmt.Errorf("failed to get address: %w", err)
+		}
+		params.AddressID = pgtype.UUID{Bytes: *aid, Valid: true}
+	}
+
+	if _, err := s.rules.CreateRule(ctx, params); err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return fiber.StatusCreated, &dto.TemplateRuleResponse{
+		ID:         params.ID.String(),
+		Template:   template.Name,
+		AddressID:  req.AddressID,
+		Name:       params.Name,
+		Severity:   params.Severity,
+		Expression: expression,
+		Enabled:    params.Enabled,
+	}, nil
+}
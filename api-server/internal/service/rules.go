@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/severity"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// IRuleService manages the user's alert rules. The engine evaluates the simple rules applying to
+// a watched address before notifying its transfers: once the user has any, only the transfers
+// matching one are notified, with the severity of the first in priority order.
+type IRuleService interface {
+	ListRules(ctx context.Context, userID string) (int, []dto.RuleResponse, error)
+	GetRule(ctx context.Context, userID, id string) (int, *dto.RuleResponse, error)
+	CreateRule(ctx context.Context, userID string, req dto.CreateRuleRequest) (int, *dto.RuleResponse, error)
+	// UpdateRule replaces the rule's conditions unless it was changed since req.Version, in which
+	// case it fails with 409
+	UpdateRule(ctx context.Context, userID, id string, req dto.UpdateRuleRequest) (int, *dto.RuleResponse, error)
+	// DeleteRule deletes the rule, returning it as it was
+	DeleteRule(ctx context.Context, userID, id string) (int, *dto.RuleResponse, error)
+}
+
+type RuleService struct {
+	addresses postgres.IAddressInterface
+	rules     postgres.IAlertRuleInterface
+	tx        postgres.ITxManager
+}
+
+func NewRuleService(addresses postgres.IAddressInterface, rules postgres.IAlertRuleInterface, tx postgres.ITxManager) IRuleService {
+	return &RuleService{
+		addresses: addresses,
+		rules:     rules,
+		tx:        tx,
+	}
+}
+
+func (s *RuleService) ListRules(ctx context.Context, userID string) (int, []dto.RuleResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	rules, err := s.rules.ListRules(ctx, *uid)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	ids := make([]uuid.UUID, len(rules))
+	for i, r := range rules {
+		ids[i] = r.ID
+	}
+	counterparties, err := s.rules.ListCounterparties(ctx, *uid, ids)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to list rule counterparties: %w", err)
+	}
+
+	res := make([]dto.RuleResponse, len(rules))
+	for i := range rules {
+		res[i] = ruleResponse(&rules[i], counterparties[rules[i].ID])
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *RuleService) GetRule(ctx context.Context, userID, id string) (int, *dto.RuleResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	ruleID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	res, err := s.rule(ctx, s.rules, *uid, *ruleID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *RuleService) CreateRule(ctx context.Context, userID string, req dto.CreateRuleRequest) (int, *dto.RuleResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	// Conditions are checked against the chain of the rule's address, if it has one
+	var chain string
+	params := sqlc.CreateAlertRuleParams{
+		ID:              uuid.New(),
+		UserID:          *uid,
+		Name:            req.Name,
+		Direction:       orDefault(req.Direction, "any"),
+		CooldownSeconds: req.CooldownSeconds,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		Severity:        orDefault(req.Severity, severity.Default),
+	}
+	if req.AddressID != "" {
+		aid, err := utils.StringToUUID(req.AddressID)
+		if err != nil {
+			return fiber.StatusBadRequest, nil, err
+		}
+		address, err := s.addresses.GetAddress(ctx, *aid, *uid)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fiber.StatusNotFound, nil, errors.New("address not found")
+		}
+		if err != nil {
+			return errorStatus(err), nil, fmt.Errorf("failed to get address: %w", err)
+		}
+		chain = address.Chain
+		params.AddressID = pgtype.UUID{Bytes: *aid, Valid: true}
+	}
+	if params.MinValue, err = minValue(req.MinValue); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	if params.TokenAddress, err = tokenAddress(chain, req.TokenAddress); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	counterparties, err := ruleCounterparties(chain, req.Counterparties)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	var res *dto.RuleResponse
+	err = s.tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		if _, err := repos.AlertRules.CreateRule(ctx, params); err != nil {
+			return fmt.Errorf("failed to create rule: %w", err)
+		}
+		if len(counterparties) > 0 {
+			if err := repos.AlertRules.SetCounterparties(ctx, params.ID, *uid, counterparties); err != nil {
+				return fmt.Errorf("failed to set rule counterparties: %w", err)
+			}
+		}
+		res, err = s.rule(ctx, repos.AlertRules, *uid, params.ID)
+		return err
+	})
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusCreated, res, nil
+}
+
+func (s *RuleService) UpdateRule(ctx context.Context, userID, id string, req dto.UpdateRuleRequest) (int, *dto.RuleResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	ruleID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	rule, err := s.rules.GetRule(ctx, *ruleID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+	if req.TokenAddress != "" && rule.TokenPreset.Valid {
+		return fiber.StatusBadRequest, nil, errors.New("the rule is filtered by a token preset, clear it before setting token_address")
+	}
+	var chain string
+	if rule.AddressID.Valid {
+		address, err := s.addresses.GetAddress(ctx, rule.AddressID.Bytes, *uid)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return errorStatus(err), nil, fmt.Errorf("failed to get address: %w", err)
+		}
+		if err == nil {
+			chain = address.Chain
+		}
+	}
+
+	params := sqlc.UpdateAlertRuleParams{
+		ID:              *ruleID,
+		UserID:          *uid,
+		Name:            req.Name,
+		Direction:       orDefault(req.Direction, "any"),
+		CooldownSeconds: req.CooldownSeconds,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		Severity:        orDefault(req.Severity, severity.Default),
+		Version:         req.Version,
+	}
+	if params.MinValue, err = minValue(req.MinValue); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	if params.TokenAddress, err = tokenAddress(chain, req.TokenAddress); err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+	counterparties, err := ruleCounterparties(chain, req.Counterparties)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	var res *dto.RuleResponse
+	err = s.tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		if err := repos.AlertRules.UpdateRule(ctx, params); err != nil {
+			return err
+		}
+		if err := repos.AlertRules.SetCounterparties(ctx, *ruleID, *uid, counterparties); err != nil {
+			return fmt.Errorf("failed to set rule counterparties: %w", err)
+		}
+		res, err = s.rule(ctx, repos.AlertRules, *uid, *ruleID)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to update rule: %w", err)
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *RuleService) DeleteRule(ctx context.Context, userID, id string) (int, *dto.RuleResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	ruleID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	res, err := s.rule(ctx, s.rules, *uid, *ruleID)
+	if err == nil {
+		err = s.rules.DeleteRule(ctx, *ruleID, *uid)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to delete rule: %w", err)
+	}
+	return fiber.StatusOK, res, nil
+}
+
+// rule reads the rule id of the user with its counterparties from rules
+func (s *RuleService) rule(ctx context.Context, rules postgres.IAlertRuleInterface, userID, id uuid.UUID) (*dto.RuleResponse, error) {
+	rule, err := rules.GetRule(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	counterparties, err := rules.ListCounterparties(ctx, userID, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	res := ruleResponse(rule, counterparties[id])
+	return &res, nil
+}
+
+// orDefault is s, or def when s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// minValue parses the decimal integer s, 0 when empty
+func minValue(s string) (pgtype.Numeric, error) {
+	if s == "" {
+		return pgtype.Numeric{Int: big.NewInt(0), Valid: true}, nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return pgtype.Numeric{}, fmt.Errorf("min_value %q must be a non-negative integer, in the token's smallest unit", s)
+	}
+	return pgtype.Numeric{Int: v, Valid: true}, nil
+}
+
+// tokenAddress checks the token contract token against chain and normalizes it. Rules without an
+// address, so without a chain, keep it as given.
+func tokenAddress(chain, token string) (pgtype.Text, error) {
+	token = strings.TrimSpace(token)
+	if token == "" || chain == "" {
+		return optionalText(token), nil
+	}
+	if chains.Family(chain) != chains.EVM {
+		return pgtype.Text{}, fmt.Errorf("%s has no token contracts, leave token_address out", chain)
+	}
+	if !chains.ValidAddress(chain, token) {
+		return pgtype.Text{}, fmt.Errorf("token_address %q is not a %s address", token, chain)
+	}
+	return optionalText(chains.NormalizeAddress(chain, token)), nil
+}
+
+// ruleCounterparties checks the counterparties against chain, normalizing and deduplicating them.
+// Rules without an address, so without a chain, keep them as given.
+func ruleCounterparties(chain string, counterparties []string) ([]string, error) {
+	normalized := make([]string, 0, len(counterparties))
+	for _, c := range counterparties {
+		c = strings.TrimSpace(c)
+		if chain != "" {
+			if !chains.ValidAddress(chain, c) {
+				return nil, fmt.Errorf("counterparty %q is not a %s address", c, chain)
+			}
+			c = chains.NormalizeAddress(chain, c)
+		}
+		if !slices.Contains(normalized, c) {
+			normalized = append(normalized, c)
+		}
+	}
+	return normalized, nil
+}
+
+func ruleResponse(r *sqlc.AlertRule, counterparties []string) dto.RuleResponse {
+	res := dto.RuleResponse{
+		ID:              r.ID.String(),
+		Name:            r.Name,
+		Direction:       r.Direction,
+		MinValue:        utils.PgNumericToString(r.MinValue),
+		TokenAddress:    utils.PgTextToString(r.TokenAddress),
+		TokenPreset:     utils.PgTextToString(r.TokenPreset),
+		Counterparties:  counterparties,
+		Expression:      utils.PgTextToString(r.Expression),
+		CooldownSeconds: r.CooldownSeconds,
+		Severity:        r.Severity,
+		Priority:        r.Priority,
+		StopProcessing:  r.StopProcessing,
+		Enabled:         r.Enabled,
+		MutedAt:         optionalTime(r.MutedAt),
+		MutedUntil:      optionalTime(r.MutedUntil),
+		Version:         r.Version,
+		CreatedAt:       r.CreatedAt.Time,
+		UpdatedAt:       r.UpdatedAt.Time,
+	}
+	if r.AddressID.Valid {
+		res.AddressID = uuid.UUID(r.AddressID.Bytes).String()
+	}
+	return res
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultSearchLimit is the number of addresses and of entities returned when no limit is given
+	defaultSearchLimit = 20
+	// maxSearchTerms bounds the size of the generated tsquery
+	maxSearchTerms = 8
+)
+
+// ISearchService searches the user's addresses and the known entities
+type ISearchService interface {
+	Search(ctx context.Context, userID string, req dto.SearchRequest) (int, *dto.SearchResponse, error)
+}
+
+type SearchService struct {
+	addresses postgres.IAddressInterface
+	entities  postgres.IKnownEntityInterface
+}
+
+func NewSearchService(addresses postgres.IAddressInterface, entities postgres.IKnownEntityInterface) ISearchService {
+	return &SearchService{
+		addresses: addresses,
+		entities:  entities,
+	}
+}
+
+func (s *SearchService) Search(ctx context.Context, userID string, req dto.SearchRequest) (int, *dto.SearchResponse, error) {
+	id, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+
+	terms := searchTerms(req.Query)
+	if len(terms) == 0 {
+		return fiber.StatusBadRequest, nil, errors.New("query has no letters or digits")
+	}
+
+	limit := int32(defaultSearchLimit)
+	if req.Limit > 0 {
+		limit = int32(req.Limit)
+	}
+
+	addresses, err := s.addresses.SearchAddresses(ctx, *id, terms, limit)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	entities, err := s.entities.SearchEntities(ctx, terms, limit)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	res := dto.SearchResponse{
+		Addresses: make([]dto.AddressSearchResult, len(addresses)),
+		Entities:  make([]dto.EntitySearchResult, len(entities)),
+	}
+	for i, a := range addresses {
+		res.Addresses[i] = dto.AddressSearchResult{
+			ID:         a.ID.String(),
+			Chain:      a.Chain,
+			Address:    a.Address,
+			Label:      utils.PgTextToString(a.Label),
+			Notes:      utils.PgTextToString(a.Notes),
+			EntityName: utils.PgTextToString(a.EntityName),
+			Rank:       a.Rank,
+			Headline:   escapeHeadline(a.Headline),
+		}
+	}
+	for i, e := range entities {
+		res.Entities[i] = dto.EntitySearchResult{
+			ID:       e.ID.String(),
+			Chain:    e.Chain,
+			Address:  e.Address,
+			Name:     e.Name,
+			Category: utils.PgTextToString(e.Category),
+			Rank:     e.Rank,
+			Headline: escapeHeadline(e.Headline),
+		}
+	}
+
+	return fiber.StatusOK, &res, nil
+}
+
+// searchTerms splits a query into distinct lower case words of letters and digits, the terms the
+// repositories match as word prefixes. Punctuation is dropped, so the query cannot inject tsquery
+// operators.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	seen := make(map[string]bool)
+	for _, w := range words {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// escapeHeadline escapes the user-provided text of a headline so it is safe to render as HTML,
+// keeping the <mark> tags the search added
+func escapeHeadline(headline string) string {
+	return strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>").
+		Replace(html.EscapeString(headline))
+}
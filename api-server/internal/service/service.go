@@ -1,35 +1,89 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
 	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/jobs"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils/jwt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// IUserService implements the user operations. ctx is the request context, passed down to the
+// repository.
 type IUserService interface {
-	RegisterUser(user dto.RegisterUserRequest) (int, string, error)
-	Login(req dto.LoginRequest) (int, *dto.LoginResponse, error)
-	SoftDeleteUser(id string) (int, error)
-	HardDeleteUser(id string) (int, error)
+	RegisterUser(ctx context.Context, user dto.RegisterUserRequest) (int, string, error)
+	Login(ctx context.Context, req dto.LoginRequest) (int, *dto.LoginResponse, error)
+	// Refresh exchanges a refresh token for a new access token and refresh token of its session.
+	// A refresh token presented twice has leaked, so its session is revoked.
+	Refresh(ctx context.Context, req dto.RefreshTokenRequest) (int, *dto.LoginResponse, error)
+	// Logout revokes the session of a refresh token, and with it its access tokens
+	Logout(ctx context.Context, req dto.RefreshTokenRequest) (int, error)
+	GetProfile(ctx context.Context, id string) (int, *dto.UserResponse, error)
+	UpdateProfile(ctx context.Context, id string, req dto.UpdateProfileRequest) (int, *dto.UserResponse, error)
+	// ChangePassword replaces the user's password after checking req.CurrentPassword, signing out
+	// every session but req.SessionID
+	ChangePassword(ctx context.Context, id string, req dto.ChangePasswordRequest) (int, *dto.UserResponse, error)
+	SoftDeleteUser(ctx context.Context, id string) (int, error)
+	// HardDeleteUser enqueues a JobPurgeUser job deleting the user with all their records,
+	// returning its ID
+	HardDeleteUser(ctx context.Context, id string) (int, string, error)
+	// PurgeUser is the handler of JobPurgeUser jobs
+	PurgeUser(ctx context.Context, payload json.RawMessage) (any, error)
 }
 
+// JobPurgeUser is the kind of the jobs deleting a user, see HardDeleteUser
+const JobPurgeUser = "user.purge"
+
+type purgeUserPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// UserService records sign-ins and changes of the profile and password in the account's audit
+// log, see postgres.IAccountEventInterface
 type UserService struct {
-	repo postgres.IUserInterface
+	repo     postgres.IUserInterface
+	events   postgres.IAccountEventInterface
+	sessions postgres.ISessionInterface
+	tx       postgres.ITxManager
+	jobs     *jobs.Queue
 }
 
-func NewService(repo postgres.IUserInterface) IUserService {
+func NewService(repo postgres.IUserInterface, events postgres.IAccountEventInterface, sessions postgres.ISessionInterface, tx postgres.ITxManager, queue *jobs.Queue) IUserService {
 	return &UserService{
-		repo: repo,
+		repo:     repo,
+		events:   events,
+		sessions: sessions,
+		tx:       tx,
+		jobs:     queue,
 	}
 }
 
-func (s *UserService) RegisterUser(user dto.RegisterUserRequest) (int, string, error) {
+// errInvalidCredentials answers sign-ins with an unknown email or a wrong password alike, so they
+// do not tell which emails are registered
+var errInvalidCredentials = errors.New("invalid email or password")
+
+// errInvalidRefreshToken answers refresh tokens that are unknown, expired or of an ended session
+var errInvalidRefreshToken = errors.New("invalid or expired refresh token, log in again")
+
+// refreshTokenPrefix starts every refresh token, telling them apart from API keys
+const refreshTokenPrefix = "bawr_"
+
+func (s *UserService) RegisterUser(ctx context.Context, user dto.RegisterUserRequest) (int, string, error) {
 
 	uuid := uuid.New()
 
@@ -47,60 +101,382 @@ func (s *UserService) RegisterUser(user dto.RegisterUserRequest) (int, string, e
 		Subscribed:    false,
 	}
 
-	id, err := s.repo.CreateNewUser(usr)
+	// The user and its registration event are committed together or not at all
+	id := usr.ID
+	err = s.tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		var err error
+		if id, err = repos.Users.CreateNewUser(ctx, usr); err != nil {
+			return err
+		}
+		if err := repos.AccountEvents.RecordEvent(ctx, postgres.AccountEvent{UserID: id, Kind: postgres.EventAccountCreated}); err != nil {
+			return err
+		}
+
+		return repos.Outbox.CreateEvent(ctx, postgres.OutboxEvent{
+			AggregateType: "user",
+			AggregateID:   id.String(),
+			Type:          "user.registered",
+			Payload: map[string]any{
+				"id":             id,
+				"email":          usr.Email,
+				"wallet_address": user.WalletAddress,
+			},
+		})
+	})
 	if err != nil {
-		return fiber.StatusInternalServerError, "", err
+		return errorStatus(err), "", registrationError(err)
 	}
 
 	return fiber.StatusCreated, id.String(), nil
 }
 
-func (s *UserService) Login(req dto.LoginRequest) (int, *dto.LoginResponse, error) {
+func (s *UserService) Login(ctx context.Context, req dto.LoginRequest) (int, *dto.LoginResponse, error) {
 
-	user, err := s.repo.GetUser(req.Email)
+	user, err := s.repo.GetUser(ctx, req.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusUnauthorized, nil, errInvalidCredentials
+	}
 	if err != nil {
 		return fiber.StatusInternalServerError, nil, err
 	}
 
-	// Compare the hash here from the utils function..
+	event := postgres.AccountEvent{
+		UserID:    user.ID,
+		Kind:      postgres.EventLogin,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	}
+	if !utils.ComparePasswordHash(req.Password, user.PasswordHash) {
+		// The attempt is shown to the user, failing to record it does not change the answer
+		event.Kind = postgres.EventLoginFailed
+		if err := s.events.RecordEvent(ctx, event); err != nil {
+			log.Printf("Failed to record a failed sign-in of user %s: %v", user.ID, err)
+		}
+		return fiber.StatusUnauthorized, nil, errInvalidCredentials
+	}
 
-	status := utils.ComparePasswordHash(req.Password, user.PasswordHash)
-	fmt.Println("Status is: ", status)
+	// Every sign-in handed a token shows in the account's activity
+	if err := s.events.RecordEvent(ctx, event); err != nil {
+		return fiber.StatusInternalServerError, nil, fmt.Errorf("failed to record sign-in: %w", err)
+	}
 
-	// Generate the token if status is true
-	token, err := jwt.GenerateJWT(req.Email)
+	// The token is bound to a session of its own, which the user can revoke from another device.
+	// The session lasts as long as its refresh token, and is extended by every refresh.
+	sessionID := uuid.New()
+	res, err := issueTokens(user.ID, req.Email, sessionID)
 	if err != nil {
 		return fiber.StatusInternalServerError, nil, err
 	}
+	err = s.tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		err := repos.Sessions.CreateSession(ctx, sqlc.CreateSessionParams{
+			ID:        sessionID,
+			UserID:    user.ID,
+			IpAddress: pgtype.Text{String: req.IPAddress, Valid: req.IPAddress != ""},
+			UserAgent: pgtype.Text{String: req.UserAgent, Valid: req.UserAgent != ""},
+			ExpiresAt: utils.ToPgTime(res.RefreshExpiresAt),
+		})
+		if err != nil {
+			return err
+		}
+		return repos.Sessions.CreateRefreshToken(ctx, sqlc.CreateRefreshTokenParams{
+			ID:        uuid.New(),
+			SessionID: sessionID,
+			UserID:    user.ID,
+			TokenHash: tokenHash(res.RefreshToken),
+			ExpiresAt: utils.ToPgTime(res.RefreshExpiresAt),
+		})
+	})
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return fiber.StatusOK, res, nil
+}
+
+func (s *UserService) Refresh(ctx context.Context, req dto.RefreshTokenRequest) (int, *dto.LoginResponse, error) {
+	current, err := s.sessions.GetRefreshToken(ctx, tokenHash(req.RefreshToken))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusUnauthorized, nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if current.UsedAt.Valid {
+		// Only the latest refresh token of a session is ever presented by its client, so an
+		// earlier one was copied: the session is signed out for both
+		err := s.sessions.RevokeSession(ctx, current.SessionID, current.UserID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return errorStatus(err), nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err == nil {
+			revokedSessions(ctx, current.UserID, current.SessionID)
+		}
+		return fiber.StatusUnauthorized, nil, errors.New("refresh token was used already, the session was signed out, log in again")
+	}
+
+	res, err := issueTokens(current.UserID, current.Email, current.SessionID)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	err = s.sessions.RotateRefreshToken(ctx, sqlc.RotateRefreshTokenParams{
+		ID:        current.ID,
+		ExpiresAt: utils.ToPgTime(res.RefreshExpiresAt),
+		NewID:     uuid.New(),
+		TokenHash: tokenHash(res.RefreshToken),
+	})
+	// Another request exchanged the token in the meantime
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusUnauthorized, nil, errors.New("refresh token was used already, log in again")
+	}
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
 
-	res := dto.LoginResponse{ID: user.ID.String(), Token: token}
+	return fiber.StatusOK, res, nil
+}
 
-	return fiber.StatusOK, &res, nil
+func (s *UserService) Logout(ctx context.Context, req dto.RefreshTokenRequest) (int, error) {
+	current, err := s.sessions.GetRefreshToken(ctx, tokenHash(req.RefreshToken))
+	// Like token revocation (RFC 7009), tokens that no longer work are already logged out
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNoContent, nil
+	}
+	if err != nil {
+		return errorStatus(err), fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	err = s.sessions.RevokeSession(ctx, current.SessionID, current.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return errorStatus(err), fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err == nil {
+		revokedSessions(ctx, current.UserID, current.SessionID)
+	}
+	return fiber.StatusNoContent, nil
 }
 
-func (s *UserService) SoftDeleteUser(id string) (int, error) {
+// issueTokens returns a new access token of the user's session sessionID with a new refresh token,
+// the session's until it is exchanged
+func issueTokens(userID uuid.UUID, email string, sessionID uuid.UUID) (*dto.LoginResponse, error) {
+	token, expiresAt, err := jwt.GenerateJWT(userID.String(), email, sessionID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate the refresh token: %w", err)
+	}
+
+	return &dto.LoginResponse{
+		ID:               userID.String(),
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		SessionID:        sessionID.String(),
+		RefreshToken:     refreshTokenPrefix + base64.RawURLEncoding.EncodeToString(secret),
+		RefreshExpiresAt: time.Now().Add(config.GetConfig().JWTRefreshExpiry),
+	}, nil
+}
 
+func (s *UserService) GetProfile(ctx context.Context, id string) (int, *dto.UserResponse, error) {
 	uuid, err := utils.StringToUUID(id)
 	if err != nil {
-		return fiber.StatusBadRequest, err
+		return fiber.StatusBadRequest, nil, err
 	}
 
-	if err := s.repo.SoftDeleteUser(*uuid); err != nil {
-		return fiber.StatusInternalServerError, err
+	user, err := s.repo.GetUserByID(ctx, *uuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
 	}
 
-	return fiber.StatusOK, nil
+	return fiber.StatusOK, userResponse(user), nil
+}
+
+// UpdateProfile replaces the user's profile unless it was changed since req.Version, in which case
+// it fails with 409 rather than overwriting the other change
+func (s *UserService) UpdateProfile(ctx context.Context, id string, req dto.UpdateProfileRequest) (int, *dto.UserResponse, error) {
+	uuid, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	profile := sqlc.UpdateUserProfileParams{
+		ID:            *uuid,
+		PhoneNumber:   utils.ToPgText(&req.PhoneNo),
+		WalletAddress: utils.ToPgText(&req.WalletAddress),
+		Subscribed:    req.Subscribed,
+		Version:       req.Version,
+	}
+
+	// The change and its audit event are committed together. The profile read first is the one
+	// replaced: the update fails when it changed in between.
+	err = s.tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		current, err := repos.Users.GetUserByID(ctx, *uuid)
+		if err != nil {
+			return err
+		}
+		if err := repos.Users.UpdateProfile(ctx, profile); err != nil {
+			return err
+		}
+
+		fields := changedFields(current, profile)
+		if len(fields) == 0 {
+			return nil
+		}
+		return repos.AccountEvents.RecordEvent(ctx, postgres.AccountEvent{
+			UserID: *uuid,
+			Kind:   postgres.EventProfileUpdated,
+			Detail: map[string]any{"fields": fields},
+		})
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, registrationError(err)
+	}
+
+	return s.GetProfile(ctx, id)
+}
+
+// changedFields names the fields of the profile that update changes, as in UpdateProfileRequest
+func changedFields(current *sqlc.User, update sqlc.UpdateUserProfileParams) []string {
+	fields := []string{}
+	if current.PhoneNumber != update.PhoneNumber {
+		fields = append(fields, "phone_no")
+	}
+	if current.WalletAddress != update.WalletAddress {
+		fields = append(fields, "wallet_address")
+	}
+	if current.Subscribed != update.Subscribed {
+		fields = append(fields, "subscribed")
+	}
+	return fields
+}
+
+func (s *UserService) ChangePassword(ctx context.Context, id string, req dto.ChangePasswordRequest) (int, *dto.UserResponse, error) {
+	uid, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	if !utils.ComparePasswordHash(req.CurrentPassword, user.PasswordHash) {
+		return fiber.StatusForbidden, nil, errors.New("current password is incorrect")
+	}
+
+	// Tokens without a session, e.g. issued before sessions, keep none
+	keep := uuid.Nil
+	if req.SessionID != "" {
+		current, err := utils.StringToUUID(req.SessionID)
+		if err != nil {
+			return fiber.StatusUnauthorized, nil, errors.New("token has an invalid session, log in again")
+		}
+		keep = *current
+	}
+
+	passHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	// The other sessions, which may have been opened with the old password, end with it along with
+	// their refresh tokens, in the same transaction
+	var revoked []uuid.UUID
+	err = s.tx.WithinTx(ctx, func(repos postgres.Repositories) error {
+		if err := repos.Users.UpdatePassword(ctx, *uid, passHash); err != nil {
+			return err
+		}
+		ids, err := repos.Sessions.RevokeOtherSessions(ctx, *uid, keep)
+		if err != nil {
+			return err
+		}
+		revoked = ids
+		return repos.AccountEvents.RecordEvent(ctx, postgres.AccountEvent{
+			UserID:    *uid,
+			Kind:      postgres.EventPasswordChanged,
+			IPAddress: req.IPAddress,
+			UserAgent: req.UserAgent,
+		})
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("user not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	revokedSessions(ctx, *uid, revoked...)
+
+	return s.GetProfile(ctx, id)
 }
 
-func (s *UserService) HardDeleteUser(id string) (int, error) {
+func (s *UserService) SoftDeleteUser(ctx context.Context, id string) (int, error) {
+
 	uuid, err := utils.StringToUUID(id)
 	if err != nil {
 		return fiber.StatusBadRequest, err
 	}
 
-	if err := s.repo.HardDeleteUser(*uuid); err != nil {
+	err = s.repo.SoftDeleteUser(ctx, *uuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, errors.New("user not found")
+	}
+	if err != nil {
 		return fiber.StatusInternalServerError, err
 	}
 
 	return fiber.StatusOK, nil
 }
+
+func (s *UserService) HardDeleteUser(ctx context.Context, id string) (int, string, error) {
+	uuid, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, "", err
+	}
+
+	// Deleting cascades to every address, rule and alert of the user, which may take long
+	job, err := s.jobs.Enqueue(ctx, *uuid, JobPurgeUser, purgeUserPayload{UserID: *uuid})
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, postgres.ErrMissingReference) {
+		return fiber.StatusNotFound, "", errors.New("user not found")
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, "", err
+	}
+
+	return fiber.StatusAccepted, job.ID.String(), nil
+}
+
+func (s *UserService) PurgeUser(ctx context.Context, payload json.RawMessage) (any, error) {
+	var p purgeUserPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	// A user already purged by an earlier attempt is not an error
+	if err := s.repo.HardDeleteUser(ctx, p.UserID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func userResponse(user *sqlc.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		PhoneNo:       utils.PgTextToString(user.PhoneNumber),
+		WalletAddress: utils.PgTextToString(user.WalletAddress),
+		Subscribed:    user.Subscribed,
+		CreatedAt:     user.CreatedAt.Time,
+		UpdatedAt:     user.UpdatedAt.Time,
+		Version:       user.Version,
+	}
+}
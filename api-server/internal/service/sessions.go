@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// sessionTouchInterval bounds how often last_seen_at is written for a busy session
+const sessionTouchInterval = time.Minute
+
+// SessionsRevoked is told about the sessions of a user that were signed out, see NotifyRevokedSessions
+type SessionsRevoked func(ctx context.Context, userID uuid.UUID, sessionIDs []uuid.UUID)
+
+var sessionsRevoked SessionsRevoked
+
+// NotifyRevokedSessions makes the services call notify with the sessions they sign out, by
+// revoking them, logging out or on refresh token reuse, e.g. to close the session's live activity
+// connections. It is set once before the routes serve requests.
+func NotifyRevokedSessions(notify SessionsRevoked) {
+	sessionsRevoked = notify
+}
+
+// revokedSessions calls the SessionsRevoked set with NotifyRevokedSessions, if any
+func revokedSessions(ctx context.Context, userID uuid.UUID, sessionIDs ...uuid.UUID) {
+	if sessionsRevoked != nil && len(sessionIDs) > 0 {
+		sessionsRevoked(ctx, userID, sessionIDs)
+	}
+}
+
+// ISessionService lists the user's sessions, one per sign-in, and signs them out remotely, e.g.
+// from a lost device. The token of a revoked session is rejected from its next request on.
+type ISessionService interface {
+	// ListSessions returns the user's active sessions, marking currentID as the current one
+	ListSessions(ctx context.Context, userID, currentID string) (int, []dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, id string) (int, error)
+	// RevokeOtherSessions revokes every active session of the user but currentID, all of them
+	// when it is empty
+	RevokeOtherSessions(ctx context.Context, userID, currentID string) (int, *dto.RevokedSessionsResponse, error)
+	// Check reports whether the user's session is active, see jwt.CheckSessions
+	Check(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+}
+
+type SessionService struct {
+	sessions postgres.ISessionInterface
+}
+
+func NewSessionService(sessions postgres.ISessionInterface) ISessionService {
+	return &SessionService{
+		sessions: sessions,
+	}
+}
+
+func (s *SessionService) ListSessions(ctx context.Context, userID, currentID string) (int, []dto.SessionResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	sessions, err := s.sessions.ListSessions(ctx, *uid)
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	res := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		res[i] = sessionResponse(session, currentID)
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *SessionService) RevokeSession(ctx context.Context, userID, id string) (int, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, errors.New("token has no user ID, log in again")
+	}
+	sessionID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, err
+	}
+
+	err = s.sessions.RevokeSession(ctx, *sessionID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, errors.New("session not found")
+	}
+	if err != nil {
+		return errorStatus(err), fmt.Errorf("failed to revoke session: %w", err)
+	}
+	revokedSessions(ctx, *uid, *sessionID)
+	return fiber.StatusNoContent, nil
+}
+
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID, currentID string) (int, *dto.RevokedSessionsResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	// Tokens without a session, e.g. issued before sessions, keep none
+	keep := uuid.Nil
+	if currentID != "" {
+		current, err := utils.StringToUUID(currentID)
+		if err != nil {
+			return fiber.StatusUnauthorized, nil, errors.New("token has an invalid session, log in again")
+		}
+		keep = *current
+	}
+
+	ids, err := s.sessions.RevokeOtherSessions(ctx, *uid, keep)
+	if err != nil {
+		return errorStatus(err), nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	revokedSessions(ctx, *uid, ids...)
+
+	res := dto.RevokedSessionsResponse{Revoked: len(ids), SessionIDs: make([]string, len(ids))}
+	for i, id := range ids {
+		res.SessionIDs[i] = id.String()
+	}
+	return fiber.StatusOK, &res, nil
+}
+
+func (s *SessionService) Check(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	session, err := s.sessions.GetActiveSession(ctx, sessionID, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if time.Since(session.LastSeenAt.Time) > sessionTouchInterval {
+		if err := s.sessions.TouchSession(ctx, session.ID); err != nil {
+			return false, fmt.Errorf("failed to record session use: %w", err)
+		}
+	}
+	return true, nil
+}
+
+func sessionResponse(session sqlc.Session, currentID string) dto.SessionResponse {
+	return dto.SessionResponse{
+		ID:         session.ID.String(),
+		IPAddress:  session.IpAddress.String,
+		UserAgent:  session.UserAgent.String,
+		Current:    session.ID.String() == currentID,
+		CreatedAt:  session.CreatedAt.Time,
+		LastSeenAt: session.LastSeenAt.Time,
+		ExpiresAt:  session.ExpiresAt.Time,
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tokens"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ITokenPresetService lists the token presets and turns them on and off for the user's rules. A
+// rule with a preset only alerts on transfers of the preset's contracts on its address's chain.
+type ITokenPresetService interface {
+	ListPresets(ctx context.Context) (int, []dto.TokenPresetResponse, error)
+	SetRulePreset(ctx context.Context, userID, id string, req dto.SetTokenPresetRequest) (int, *dto.RuleTokenPresetResponse, error)
+	ClearRulePreset(ctx context.Context, userID, id string) (int, *dto.RuleTokenPresetResponse, error)
+}
+
+type TokenPresetService struct {
+	rules postgres.IAlertRuleInterface
+}
+
+func NewTokenPresetService(rules postgres.IAlertRuleInterface) ITokenPresetService {
+	return &TokenPresetService{
+		rules: rules,
+	}
+}
+
+func (s *TokenPresetService) ListPresets(ctx context.Context) (int, []dto.TokenPresetResponse, error) {
+	presets := tokens.All()
+	res := make([]dto.TokenPresetResponse, len(presets))
+	for i, p := range presets {
+		chains := make(map[string][]dto.TokenResponse, len(p.Chains))
+		for chain, contracts := range p.Chains {
+			for _, t := range contracts {
+				chains[chain] = append(chains[chain], dto.TokenResponse{Symbol: t.Symbol, Address: t.Address})
+			}
+		}
+		res[i] = dto.TokenPresetResponse{Name: p.Name, Description: p.Description, Chains: chains}
+	}
+	return fiber.StatusOK, res, nil
+}
+
+func (s *TokenPresetService) SetRulePreset(ctx context.Context, userID, id string, req dto.SetTokenPresetRequest) (int, *dto.RuleTokenPresetResponse, error) {
+	if !tokens.Valid(req.Preset) {
+		return fiber.StatusBadRequest, nil, fmt.Errorf("unknown token preset %q", req.Preset)
+	}
+	return s.setPreset(ctx, userID, id, pgtype.Text{String: req.Preset, Valid: true})
+}
+
+func (s *TokenPresetService) ClearRulePreset(ctx context.Context, userID, id string) (int, *dto.RuleTokenPresetResponse, error) {
+	return s.setPreset(ctx, userID, id, pgtype.Text{})
+}
+
+// setPreset sets the token preset of the rule id, clearing it when preset is NULL
+func (s *TokenPresetService) setPreset(ctx context.Context, userID, id string, preset pgtype.Text) (int, *dto.RuleTokenPresetResponse, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	ruleID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	// A preset replaces the filter of a single contract, so rules with one have no preset to clear
+	rule, err := s.rules.GetRule(ctx, *ruleID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	if rule.TokenAddress.Valid {
+		if !preset.Valid {
+			return fiber.StatusOK, &dto.RuleTokenPresetResponse{ID: ruleID.String()}, nil
+		}
+		return fiber.StatusUnprocessableEntity, nil,
+			fmt.Errorf("rule already filters on token %s, remove its token_address first", rule.TokenAddress.String)
+	}
+
+	err = s.rules.SetTokenPreset(ctx, *ruleID, *uid, preset)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("rule not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+	return fiber.StatusOK, &dto.RuleTokenPresetResponse{ID: ruleID.String(), TokenPreset: preset.String}, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/rpc"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// transferTopic is the topic of the ERC-20 event Transfer(address,address,uint256)
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// transferSelector is the selector of the ERC-20 call transfer(address,uint256)
+	transferSelector = "0xa9059cbb"
+	// bitcoinNotFound is the code Bitcoin Core answers unknown transactions with
+	bitcoinNotFound = -5
+)
+
+// errTransactionNotFound fails the lookup of a hash the node does not know
+var errTransactionNotFound = errors.New("transaction not found")
+
+var (
+	evmHash     = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+	bitcoinHash = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+)
+
+// ITransactionService looks transactions up on the configured node
+type ITransactionService interface {
+	Status(ctx context.Context, chain, hash string) (int, *dto.TransactionStatusResponse, error)
+}
+
+type TransactionService struct {
+	network       string
+	node          *rpc.Client
+	confirmations int64
+}
+
+// NewTransactionService creates the transaction service of the network node is a node of.
+// Transactions are final after confirmations blocks.
+func NewTransactionService(network string, node *rpc.Client, confirmations int) ITransactionService {
+	return &TransactionService{
+		network:       strings.ToLower(network),
+		node:          node,
+		confirmations: int64(confirmations),
+	}
+}
+
+func (s *TransactionService) Status(ctx context.Context, chain, hash string) (int, *dto.TransactionStatusResponse, error) {
+	if strings.ToLower(chain) != s.network {
+		return fiber.StatusNotFound, nil, fmt.Errorf("no node is configured for chain %q", chain)
+	}
+
+	var res *dto.TransactionStatusResponse
+	var err error
+	if s.network == "bitcoin" {
+		if !bitcoinHash.MatchString(hash) {
+			return fiber.StatusBadRequest, nil, errors.New("hash must be 64 hex digits")
+		}
+		res, err = s.bitcoinStatus(ctx, strings.ToLower(hash))
+	} else {
+		if !evmHash.MatchString(hash) {
+			return fiber.StatusBadRequest, nil, errors.New("hash must be 0x and 64 hex digits")
+		}
+		res, err = s.evmStatus(ctx, strings.ToLower(hash))
+	}
+	if errors.Is(err, errTransactionNotFound) {
+		return fiber.StatusNotFound, nil, err
+	}
+	if errors.Is(err, rpc.ErrNotConfigured) {
+		return fiber.StatusNotFound, nil, fmt.Errorf("no node is configured for chain %q", chain)
+	}
+	if err != nil {
+		return fiber.StatusBadGateway, nil, fmt.Errorf("failed to read the transaction from the node: %w", err)
+	}
+	res.Chain = s.network
+	res.Final = res.Confirmations >= s.confirmations
+	return fiber.StatusOK, res, nil
+}
+
+// evmStatus reads a transaction, and once mined its receipt, whose ERC-20 Transfer events are
+// summarized. A pending ERC-20 transfer call is summarized from its input instead.
+func (s *TransactionService) evmStatus(ctx context.Context, hash string) (*dto.TransactionStatusResponse, error) {
+	var tx *struct {
+		BlockNumber *string `json:"blockNumber"`
+		BlockHash   *string `json:"blockHash"`
+		From        string  `json:"from"`
+		To          *string `json:"to"`
+		Value       string  `json:"value"`
+		Input       string  `json:"input"`
+	}
+	if err := s.node.Call(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, errTransactionNotFound
+	}
+
+	value, err := parseHex(tx.Value)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.TransactionStatusResponse{
+		Hash:      hash,
+		Status:    "pending",
+		From:      tx.From,
+		Value:     value.String(),
+		Transfers: []dto.TransferSummary{},
+	}
+	if tx.To != nil {
+		res.To = *tx.To
+	}
+	if value.Sign() > 0 {
+		res.Transfers = append(res.Transfers, dto.TransferSummary{From: tx.From, To: res.To, Value: res.Value})
+	}
+
+	if tx.BlockNumber == nil {
+		if transfer, ok := decodeTransferCall(tx.From, res.To, tx.Input); ok {
+			res.Transfers = append(res.Transfers, transfer)
+		}
+		return res, nil
+	}
+
+	var receipt struct {
+		Status            string `json:"status"`
+		GasUsed           string `json:"gasUsed"`
+		EffectiveGasPrice string `json:"effectiveGasPrice"`
+		Logs              []struct {
+			Address string   `json:"address"`
+			Topics  []string `json:"topics"`
+			Data    string   `json:"data"`
+		} `json:"logs"`
+	}
+	if err := s.node.Call(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	var head string
+	if err := s.node.Call(ctx, &head, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+
+	block, err := parseHex(*tx.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	headBlock, err := parseHex(head)
+	if err != nil {
+		return nil, err
+	}
+	blockNumber := block.Int64()
+	res.BlockNumber = &blockNumber
+	if tx.BlockHash != nil {
+		res.BlockHash = *tx.BlockHash
+	}
+	res.Confirmations = max(headBlock.Int64()-blockNumber+1, 0)
+	res.Status = "confirmed"
+	if receipt.Status == "0x0" {
+		res.Status = "failed"
+	}
+	if gasUsed, err := parseHex(receipt.GasUsed); err == nil {
+		if price, err := parseHex(receipt.EffectiveGasPrice); err == nil {
+			res.Fee = gasUsed.Mul(gasUsed, price).String()
+		}
+	}
+
+	for _, event := range receipt.Logs {
+		// ERC-721 transfers share the topic but index the token ID as a fourth topic
+		if len(event.Topics) != 3 || event.Topics[0] != transferTopic {
+			continue
+		}
+		amount, err := parseHex(event.Data)
+		if err != nil {
+			continue
+		}
+		res.Transfers = append(res.Transfers, dto.TransferSummary{
+			Token: event.Address,
+			From:  topicAddress(event.Topics[1]),
+			To:    topicAddress(event.Topics[2]),
+			Value: amount.String(),
+		})
+	}
+	return res, nil
+}
+
+// decodeTransferCall decodes the input of an ERC-20 transfer(address,uint256) call from from to
+// the token contract
+func decodeTransferCall(from, contract, input string) (dto.TransferSummary, bool) {
+	// The selector followed by two 32 byte words
+	if len(input) < 2+8+64+64 || !strings.HasPrefix(input, transferSelector) {
+		return dto.TransferSummary{}, false
+	}
+	amount, err := parseHex(input[2+8+64 : 2+8+64+64])
+	if err != nil {
+		return dto.TransferSummary{}, false
+	}
+	return dto.TransferSummary{
+		Token: contract,
+		From:  from,
+		To:    topicAddress(input[2+8 : 2+8+64]),
+		Value: amount.String(),
+	}, true
+}
+
+// topicAddress returns the address in the last 20 bytes of a 32 byte word
+func topicAddress(word string) string {
+	word = strings.TrimPrefix(word, "0x")
+	if len(word) < 40 {
+		return ""
+	}
+	return "0x" + word[len(word)-40:]
+}
+
+// bitcoinStatus reads a transaction with getrawtransaction, which needs the node's txindex for
+// confirmed transactions of other wallets, summarizing its outputs
+func (s *TransactionService) bitcoinStatus(ctx context.Context, hash string) (*dto.TransactionStatusResponse, error) {
+	type output struct {
+		Value        float64 `json:"value"`
+		ScriptPubKey struct {
+			Address string `json:"address"`
+		} `json:"scriptPubKey"`
+	}
+	var tx struct {
+		BlockHash     string   `json:"blockhash"`
+		Confirmations int64    `json:"confirmations"`
+		Fee           *float64 `json:"fee"`
+		Vin           []struct {
+			Prevout *output `json:"prevout"`
+		} `json:"vin"`
+		Vout []output `json:"vout"`
+	}
+	err := s.node.Call(ctx, &tx, "getrawtransaction", hash, 2)
+	var nodeErr *rpc.Error
+	if errors.As(err, &nodeErr) && nodeErr.Code == bitcoinNotFound {
+		return nil, errTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.TransactionStatusResponse{
+		Hash:          hash,
+		Status:        "pending",
+		BlockHash:     tx.BlockHash,
+		Confirmations: tx.Confirmations,
+		Transfers:     []dto.TransferSummary{},
+	}
+	if tx.Confirmations > 0 {
+		var count int64
+		if err := s.node.Call(ctx, &count, "getblockcount"); err != nil {
+			return nil, err
+		}
+		block := count - tx.Confirmations + 1
+		res.BlockNumber = &block
+		res.Status = "confirmed"
+	}
+	if tx.Fee != nil {
+		res.Fee = big.NewInt(satoshis(*tx.Fee)).String()
+	}
+
+	// The sender is known when every spent output, returned by nodes with the undo data, paid the
+	// same address
+	for i, in := range tx.Vin {
+		if in.Prevout == nil || i > 0 && in.Prevout.ScriptPubKey.Address != res.From {
+			res.From = ""
+			break
+		}
+		res.From = in.Prevout.ScriptPubKey.Address
+	}
+
+	var total int64
+	for _, out := range tx.Vout {
+		value := satoshis(out.Value)
+		total += value
+		res.Transfers = append(res.Transfers, dto.TransferSummary{
+			To:    out.ScriptPubKey.Address,
+			Value: big.NewInt(value).String(),
+		})
+	}
+	res.Value = big.NewInt(total).String()
+	return res, nil
+}
+
+// satoshis converts an amount in BTC
+func satoshis(btc float64) int64 {
+	return int64(math.Round(btc * 1e8))
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	sqlc "github.com/ahsansaif47/blockchain-address-watcher/api-server/db/generated"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/dto"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// States of an address's watch, see dto.AddressStatus
+const (
+	WatcherStatePending  = "pending"
+	WatcherStateWatching = "watching"
+	WatcherStateError    = "error"
+)
+
+// IWatcherStatusService reports whether the engine is watching the user's addresses
+type IWatcherStatusService interface {
+	AddressStatus(ctx context.Context, userID, id string) (int, *dto.AddressStatus, error)
+}
+
+type WatcherStatusService struct {
+	addresses postgres.IAddressInterface
+	status    postgres.IWatcherStatusInterface
+}
+
+func NewWatcherStatusService(addresses postgres.IAddressInterface, status postgres.IWatcherStatusInterface) IWatcherStatusService {
+	return &WatcherStatusService{
+		addresses: addresses,
+		status:    status,
+	}
+}
+
+// AddressStatus reads the engine's state of the address's chain and address, shared with the
+// other users watching it
+func (s *WatcherStatusService) AddressStatus(ctx context.Context, userID, id string) (int, *dto.AddressStatus, error) {
+	uid, err := utils.StringToUUID(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, errors.New("token has no user ID, log in again")
+	}
+	addressID, err := utils.StringToUUID(id)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, err
+	}
+
+	address, err := s.addresses.GetAddress(ctx, *addressID, *uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusNotFound, nil, errors.New("address not found")
+	}
+	if err != nil {
+		return errorStatus(err), nil, err
+	}
+
+	res := &dto.AddressStatus{
+		AddressID: address.ID.String(),
+		Chain:     address.Chain,
+		Address:   address.Address,
+		State:     WatcherStatePending,
+	}
+
+	status, err := s.status.GetWatcherStatus(ctx, address.Chain, address.Address)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.StatusOK, res, nil
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	applyWatcherStatus(res, status)
+	return fiber.StatusOK, res, nil
+}
+
+// applyWatcherStatus fills res from the engine's status row. An address the engine stopped
+// watching is pending again: its row was deleted and re-added faster than the engine caught up.
+func applyWatcherStatus(res *dto.AddressStatus, status *sqlc.WatcherStatus) {
+	if !status.Watching {
+		return
+	}
+
+	res.State = WatcherStateWatching
+	res.Mode = utils.PgTextToString(status.Mode)
+	res.WatchingSince = optionalTime(status.WatchingSince)
+	res.LastScannedAt = optionalTime(status.LastScannedAt)
+	if status.LastScannedBlock.Valid {
+		res.LastScannedBlock = &status.LastScannedBlock.Int64
+	}
+	if status.LastActivityBlock.Valid && status.LastActivityAt.Valid {
+		res.LastActivity = &dto.AddressLastActivity{
+			BlockNumber: status.LastActivityBlock.Int64,
+			Hash:        utils.PgTextToString(status.LastActivityHash),
+			At:          status.LastActivityAt.Time,
+		}
+	}
+	if status.LastError.Valid && status.LastErrorAt.Valid {
+		res.LastError = &dto.AddressWatcherError{
+			Message: status.LastError.String,
+			At:      status.LastErrorAt.Time,
+			Count:   status.ErrorCount,
+		}
+		// Failing since the last successful scan
+		if status.ErrorCount > 0 {
+			res.State = WatcherStateError
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package severity grades alerts as info, warning or critical. A rule carries the severity of its
+// alerts, raised by what is known of the counterparty: a transfer with a sanctioned address is
+// always critical, one with a mixer at least a warning. The severity picks the channels an alert
+// is delivered over, NOTIFY_ROUTE_INFO, NOTIFY_ROUTE_WARNING and NOTIFY_ROUTE_CRITICAL.
+package severity
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+)
+
+// Severities from the lowest
+const (
+	Info     = "info"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// Default is the severity of rules created without one and of alerts without a rule
+const Default = Warning
+
+var ranks = map[string]int{Info: 1, Warning: 2, Critical: 3}
+
+// Valid reports whether s is a severity
+func Valid(s string) bool {
+	return ranks[s] > 0
+}
+
+// Max returns the higher of a and b. Invalid severities rank below info.
+func Max(a, b string) string {
+	if ranks[b] > ranks[a] {
+		return b
+	}
+	return a
+}
+
+// Derive returns the severity of an alert raised by a rule of severity rule for a transfer with a
+// counterparty of the known entity category, e.g. exchange or mixer, or "" when it is unknown
+func Derive(rule, category string) string {
+	if !Valid(rule) {
+		rule = Default
+	}
+	switch strings.ToLower(category) {
+	case "sanctioned", "sanctions":
+		return Critical
+	case "mixer":
+		return Max(rule, Warning)
+	}
+	return rule
+}
+
+// Channels returns the channels, of email, sms and webhook, alerts of severity s are delivered over
+func Channels(s string) []string {
+	routing := config.GetConfig().Routing
+	switch s {
+	case Info:
+		return routing.Info
+	case Critical:
+		return routing.Critical
+	}
+	return routing.Warning
+}
+
+// Routes reports whether alerts of severity s are delivered over channel
+func Routes(s, channel string) bool {
+	return slices.Contains(Channels(s), channel)
+}
+
+// Subject returns the subject of an email or the first line of a text message for an alert of
+// severity s, e.g. "[CRITICAL] Large outflow"
+func Subject(s, message string) string {
+	if !Valid(s) {
+		s = Default
+	}
+	return "[" + strings.ToUpper(s) + "] " + message
+}
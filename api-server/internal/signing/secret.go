@@ -0,0 +1,80 @@
+package signing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// secretPrefix starts every signing secret, so leaked secrets are easy to recognize
+const secretPrefix = "baws_"
+
+// EncryptionKeyLength is the length of the decoded SERVER_API_KEY_ENCRYPTION_KEY, an AES-256 key
+const EncryptionKeyLength = 32
+
+// ErrNoEncryptionKey is returned when SERVER_API_KEY_ENCRYPTION_KEY is not set
+var ErrNoEncryptionKey = errors.New("signed API keys need SERVER_API_KEY_ENCRYPTION_KEY to be set")
+
+// NewSecret returns a random signing secret, separate from the API key it belongs to
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate the signing secret: %w", err)
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// ParseEncryptionKey decodes SERVER_API_KEY_ENCRYPTION_KEY, the standard base64 of
+// EncryptionKeyLength random bytes, e.g. from openssl rand -base64 32
+func ParseEncryptionKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, ErrNoEncryptionKey
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != EncryptionKeyLength {
+		return nil, fmt.Errorf("SERVER_API_KEY_ENCRYPTION_KEY must be the base64 of %d bytes", EncryptionKeyLength)
+	}
+	return key, nil
+}
+
+// Encrypt seals secret with AES-256-GCM under key, returning the nonce followed by the ciphertext.
+// The ciphertext is bound to keyID, the ID of the API key, so it cannot be moved to another key.
+func Encrypt(key []byte, keyID, secret string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(secret)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to encrypt the signing secret: %w", err)
+	}
+	return aead.Seal(nonce, nonce, []byte(secret), []byte(keyID)), nil
+}
+
+// Decrypt opens a secret sealed by Encrypt for keyID
+func Decrypt(key []byte, keyID string, sealed []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("failed to decrypt the signing secret: too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the signing secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
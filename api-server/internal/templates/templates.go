@@ -0,0 +1,129 @@
+// Package templates holds the rule templates, ready-made advanced rules a user creates with one
+// call instead of writing their CEL expression. Templates with parameters, e.g. the threshold of
+// a large transfer, fill them into their expression when instantiated.
+package templates
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Templates
+const (
+	LargeOutgoingTransfer = "large-outgoing-transfer"
+	NewCounterparty       = "new-counterparty"
+	ApprovalGranted       = "approval-granted"
+	ExchangeDeposit       = "exchange-deposit"
+	HighRiskInbound       = "high-risk-inbound"
+)
+
+// Param is a number filled into a template's expression where it has {Name}
+type Param struct {
+	Name        string
+	Description string
+	Default     float64
+	Min         float64
+}
+
+// Template is a ready-made advanced rule
+type Template struct {
+	Name        string
+	Title       string
+	Description string
+	Severity    string
+	// Expression is the rule's CEL expression, with a {name} placeholder for each of Params
+	Expression string
+	Params     []Param
+}
+
+var templates = []Template{
+	{
+		Name:        LargeOutgoingTransfer,
+		Title:       "Large outgoing transfer",
+		Description: "Alerts when the address sends at least threshold_usd US dollars in one transfer",
+		Severity:    "warning",
+		Expression:  `tx.kind == "transfer" && tx.direction == "out" && has(tx.value_usd) && tx.value_usd >= {threshold_usd}`,
+		Params: []Param{
+			{Name: "threshold_usd", Description: "Smallest transfer to alert on, in US dollars", Default: 10000, Min: 0},
+		},
+	},
+	{
+		Name:        NewCounterparty,
+		Title:       "New counterparty",
+		Description: "Alerts when the address transacts with an address it never transacted with before",
+		Severity:    "info",
+		Expression:  `tx.kind == "transfer" && tx.new_counterparty`,
+	},
+	{
+		Name:        ApprovalGranted,
+		Title:       "Approval granted",
+		Description: "Alerts when the address grants another address an allowance to spend its tokens",
+		Severity:    "warning",
+		Expression:  `tx.kind == "approval" && tx.direction == "out"`,
+	},
+	{
+		Name:        ExchangeDeposit,
+		Title:       "Exchange deposit",
+		Description: "Alerts when the address sends funds to a known exchange",
+		Severity:    "info",
+		Expression:  `tx.kind == "transfer" && tx.direction == "out" && tx.counterparty in known.exchanges`,
+	},
+	{
+		Name:        HighRiskInbound,
+		Title:       "Funds from a high-risk source",
+		Description: "Alerts when the address receives funds from a counterparty with a risk score of at least min_risk",
+		Severity:    "critical",
+		Expression:  `tx.kind == "transfer" && tx.direction == "in" && has(tx.counterparty_risk) && tx.counterparty_risk >= {min_risk}`,
+		Params: []Param{
+			{Name: "min_risk", Description: "Lowest counterparty risk score to alert on, 0 to 100", Default: 70, Min: 0},
+		},
+	},
+}
+
+// All returns the templates
+func All() []Template {
+	return templates
+}
+
+// Get returns the template named name, false when there is none
+func Get(name string) (Template, bool) {
+	i := slices.IndexFunc(templates, func(t Template) bool { return t.Name == name })
+	if i < 0 {
+		return Template{}, false
+	}
+	return templates[i], true
+}
+
+// Instantiate returns the template's expression with its parameters filled in from params, their
+// default when missing. Unknown parameters and values below a parameter's minimum fail.
+func (t Template) Instantiate(params map[string]float64) (string, error) {
+	for name := range params {
+		if !slices.ContainsFunc(t.Params, func(p Param) bool { return p.Name == name }) {
+			return "", fmt.Errorf("template %s has no parameter %q", t.Name, name)
+		}
+	}
+
+	expression := t.Expression
+	for _, p := range t.Params {
+		v, ok := params[p.Name]
+		if !ok {
+			v = p.Default
+		}
+		if v < p.Min {
+			return "", fmt.Errorf("%s must be at least %g", p.Name, p.Min)
+		}
+		expression = strings.ReplaceAll(expression, "{"+p.Name+"}", number(v))
+	}
+	return expression, nil
+}
+
+// number formats v as a CEL double literal
+func number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
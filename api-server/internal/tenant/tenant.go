@@ -0,0 +1,24 @@
+// Package tenant carries the user a request acts for in its context. The JWT middleware sets it
+// for signed-in requests, and the repositories scope every query to it: a request can only read or
+// change its own user's records, whatever IDs it passes.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type userKey struct{}
+
+// WithUser returns a context acting for userID
+func WithUser(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// User returns the user ctx acts for. Contexts without one, e.g. of background jobs, registration
+// and sign-in, are not scoped.
+func User(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value(userKey{}).(uuid.UUID)
+	return userID, ok
+}
@@ -0,0 +1,566 @@
+// Package webhooks posts alerts to the users' webhook endpoints from their pending deliveries in
+// the notification_deliveries table. A webhook in the single delivery mode gets one POST per alert;
+// one in the batch mode gets the alerts as an array once batch_max_events are due or the oldest has
+// waited batch_interval_seconds, so high-volume users are not flooded with requests.
+//
+// Every POST carries an X-Webhook-Signature header, "v1=" and the hex HMAC-SHA256 of
+// "<X-Webhook-Timestamp>.<body>" keyed with the webhook's secret; a batch is signed once as a
+// whole. The alerts of muted addresses and rules, and of severities not routed to webhooks by
+// NOTIFY_ROUTE_*, are skipped rather than posted, as are those of webhooks not verified yet: a
+// webhook is verified by echoing the challenge of a webhook.verification event, see Challenge. When one block has WEBHOOK_AGGREGATE_MIN or more
+// alerts of an address for transfers of the same token, e.g. of an airdrop, they are posted as one
+// aggregated alert with their count and total value. Failed deliveries
+// are retried with exponential backoff until WEBHOOK_MAX_ATTEMPTS, so an endpoint may receive an
+// alert more than once and should deduplicate by its ID.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/severity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Event types of the posted bodies
+const (
+	EventAlert          = "alert"
+	EventAlertAggregate = "alert.aggregate"
+	EventAlertBatch     = "alert.batch"
+	EventVerification   = "webhook.verification"
+)
+
+const (
+	// claimLimit is the number of single deliveries posted per transaction
+	claimLimit = 20
+	// batchLimit is the number of due batches looked for per poll
+	batchLimit = 10
+	// blockLimit bounds the deliveries of a block claimed to aggregate them
+	blockLimit = 1000
+)
+
+// errInactive fails the deliveries of webhooks disabled or deleted since the alert was raised
+var errInactive = errors.New("webhook disabled or deleted")
+
+// mutedReason is recorded with the skipped deliveries of alerts whose address or rule is muted
+const mutedReason = "address or rule muted"
+
+// unverifiedReason is recorded with the skipped deliveries of webhooks that did not answer the
+// verification challenge
+const unverifiedReason = "webhook not verified"
+
+// ErrChallengeFailed fails verifications whose endpoint did not echo the challenge
+var ErrChallengeFailed = errors.New("endpoint did not echo the challenge")
+
+// unroutedReason is recorded with the skipped deliveries of alerts whose severity is not routed to
+// webhooks
+const unroutedReason = "severity not routed to webhooks"
+
+// Alert is an alert as posted to webhook endpoints. An aggregated alert stands for the alerts of
+// AlertIDs, its Value is their total and its ID, TransactionID and CreatedAt are the first's.
+// Alerts of balance changes have no TransactionID.
+type Alert struct {
+	ID            uuid.UUID   `json:"id"`
+	AddressID     uuid.UUID   `json:"address_id"`
+	Chain         string      `json:"chain"`
+	Address       string      `json:"address"`
+	RuleID        *uuid.UUID  `json:"rule_id,omitempty"`
+	TransactionID *uuid.UUID  `json:"transaction_id,omitempty"`
+	Message       string      `json:"message"`
+	Severity      string      `json:"severity"`
+	BlockNumber   *int64      `json:"block_number,omitempty"`
+	Value         string      `json:"value,omitempty"`
+	TokenAddress  *string     `json:"token_address,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Count         int         `json:"count,omitempty"`
+	AlertIDs      []uuid.UUID `json:"alert_ids,omitempty"`
+}
+
+// Event is the body of a POST: an alert, an aggregated alert, the alerts of a batch, or the
+// challenge verifying a webhook. Its ID is the delivery's, or a new one for each attempt of an
+// aggregated alert, a batch or a verification.
+type Event struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Alert     *Alert  `json:"alert,omitempty"`
+	Alerts    []Alert `json:"alerts,omitempty"`
+	Challenge string  `json:"challenge,omitempty"`
+}
+
+// Dispatcher posts the due webhook deliveries
+type Dispatcher struct {
+	deliveries postgres.INotificationDeliveryInterface
+	txManager  postgres.ITxManager
+	client     *http.Client
+}
+
+func New(deliveries postgres.INotificationDeliveryInterface, txManager postgres.ITxManager) *Dispatcher {
+	return &Dispatcher{
+		deliveries: deliveries,
+		txManager:  txManager,
+		client:     &http.Client{},
+	}
+}
+
+// Run posts due deliveries every WEBHOOK_POLL_INTERVAL until ctx is done. The POSTs in flight then
+// are finished and recorded before Run returns.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := d.Deliver(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Failed to deliver webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(config.GetConfig().Webhooks.PollInterval):
+		}
+	}
+}
+
+// Deliver posts the due deliveries of single mode webhooks and the due batches
+func (d *Dispatcher) Deliver(ctx context.Context) error {
+	for {
+		n, err := d.deliverSingle(ctx)
+		if err != nil {
+			return err
+		}
+		if n < claimLimit {
+			break
+		}
+	}
+
+	batches, err := d.deliveries.ListDueBatches(ctx, batchLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list due batches: %w", err)
+	}
+	for _, batch := range batches {
+		if err := d.deliverBatch(ctx, batch.ID, batch.BatchMaxEvents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverSingle posts up to claimLimit due deliveries at once, with the rest of the blocks they
+// aggregate, returning how many were claimed
+func (d *Dispatcher) deliverSingle(ctx context.Context) (int, error) {
+	var claimed int
+	err := d.txManager.WithinTx(ctx, func(repos postgres.Repositories) error {
+		deliveries, err := repos.NotificationDeliveries.ClaimWebhookDeliveries(ctx, claimLimit)
+		if err != nil {
+			return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+		claimed = len(deliveries)
+		if deliveries, err = claimBlocks(ctx, repos, deliveries); err != nil {
+			return err
+		}
+
+		deliveries, muted := withoutMuted(deliveries)
+		if err := repos.NotificationDeliveries.SkipDeliveries(ctx, muted, mutedReason); err != nil {
+			return fmt.Errorf("failed to skip muted webhook deliveries: %w", err)
+		}
+		deliveries, unrouted := withoutUnrouted(deliveries)
+		if err := repos.NotificationDeliveries.SkipDeliveries(ctx, unrouted, unroutedReason); err != nil {
+			return fmt.Errorf("failed to skip unrouted webhook deliveries: %w", err)
+		}
+		deliveries, unverified := withoutUnverified(deliveries)
+		if err := repos.NotificationDeliveries.SkipDeliveries(ctx, unverified, unverifiedReason); err != nil {
+			return fmt.Errorf("failed to skip the deliveries of unverified webhooks: %w", err)
+		}
+
+		groups := aggregate(deliveries)
+		results := make([]error, len(groups))
+		var wg sync.WaitGroup
+		for i, group := range groups {
+			first := group[0]
+			if !first.Active {
+				results[i] = errInactive
+				continue
+			}
+			wg.Go(func() {
+				alert := toAlert(group)
+				event := Event{ID: first.ID.String(), Type: EventAlert, Alert: &alert}
+				if len(group) > 1 {
+					event.ID, event.Type = uuid.New().String(), EventAlertAggregate
+				}
+				results[i] = d.post(ctx, first.Url, first.Secret, event)
+			})
+		}
+		wg.Wait()
+
+		for i, group := range groups {
+			if err := record(ctx, repos, group, results[i]); err != nil {
+				return fmt.Errorf("failed to record webhook delivery %s: %w", group[0].ID, err)
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// deliverBatch posts up to maxEvents due deliveries of a batch webhook in one request
+func (d *Dispatcher) deliverBatch(ctx context.Context, webhookID uuid.UUID, maxEvents int32) error {
+	return d.txManager.WithinTx(ctx, func(repos postgres.Repositories) error {
+		deliveries, err := repos.NotificationDeliveries.ClaimBatch(ctx, webhookID, maxEvents)
+		if err != nil {
+			return fmt.Errorf("failed to claim the batch of webhook %s: %w", webhookID, err)
+		}
+		deliveries, muted := withoutMuted(deliveries)
+		if err := repos.NotificationDeliveries.SkipDeliveries(ctx, muted, mutedReason); err != nil {
+			return fmt.Errorf("failed to skip the muted deliveries of webhook %s: %w", webhookID, err)
+		}
+		deliveries, unrouted := withoutUnrouted(deliveries)
+		if err := repos.NotificationDeliveries.SkipDeliveries(ctx, unrouted, unroutedReason); err != nil {
+			return fmt.Errorf("failed to skip the unrouted deliveries of webhook %s: %w", webhookID, err)
+		}
+		deliveries, unverified := withoutUnverified(deliveries)
+		if err := repos.NotificationDeliveries.SkipDeliveries(ctx, unverified, unverifiedReason); err != nil {
+			return fmt.Errorf("failed to skip the deliveries of unverified webhook %s: %w", webhookID, err)
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		groups := aggregate(deliveries)
+		alerts := make([]Alert, len(groups))
+		for i, group := range groups {
+			alerts[i] = toAlert(group)
+		}
+
+		first := deliveries[0]
+		cause := errInactive
+		if first.Active {
+			cause = d.post(ctx, first.Url, first.Secret, Event{
+				ID:     uuid.New().String(),
+				Type:   EventAlertBatch,
+				Alerts: alerts,
+			})
+		}
+		if err := record(ctx, repos, deliveries, cause); err != nil {
+			return fmt.Errorf("failed to record the batch of webhook %s: %w", webhookID, err)
+		}
+		return nil
+	})
+}
+
+// Challenge posts a webhook.verification event with a random challenge to url, signed with secret
+// like alerts, failing with ErrChallengeFailed unless the endpoint answers 2xx with the challenge,
+// either as the whole body or as the challenge field of a JSON object
+func (d *Dispatcher) Challenge(ctx context.Context, url, secret string) error {
+	challenge := make([]byte, 16)
+	if _, err := cryptorand.Read(challenge); err != nil {
+		return fmt.Errorf("failed to generate the challenge: %w", err)
+	}
+	event := Event{ID: uuid.New().String(), Type: EventVerification, Challenge: hex.EncodeToString(challenge)}
+
+	body, err := d.send(ctx, url, secret, event)
+	if err != nil {
+		return err
+	}
+	var echo struct {
+		Challenge string `json:"challenge"`
+	}
+	if strings.TrimSpace(string(body)) == event.Challenge ||
+		(json.Unmarshal(body, &echo) == nil && echo.Challenge == event.Challenge) {
+		return nil
+	}
+	return ErrChallengeFailed
+}
+
+// post sends event to url signed with secret, failing unless the endpoint answers 2xx
+func (d *Dispatcher) post(ctx context.Context, url, secret string, event Event) error {
+	_, err := d.send(ctx, url, secret, event)
+	return err
+}
+
+// send posts event like post, returning the start of the response body
+func (d *Dispatcher) send(ctx context.Context, url, secret string, event Event) ([]byte, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetConfig().Webhooks.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blockchain-address-watcher-webhooks")
+	req.Header.Set("X-Webhook-ID", event.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", Sign(secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	answer, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return answer, nil
+}
+
+// Sign returns the X-Webhook-Signature of body posted at timestamp, the X-Webhook-Timestamp header.
+// Endpoints recompute it to check a request came from the watcher and was not replayed later.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryAt returns when to retry a delivery that failed its attempt after attempts earlier ones, or
+// the zero time to give up
+func retryAt(attempts int32, cause error) time.Time {
+	cfg := config.GetConfig().Webhooks
+	if errors.Is(cause, errInactive) || int(attempts)+1 >= cfg.MaxAttempts {
+		return time.Time{}
+	}
+	return time.Now().Add(backoff(cfg.RetryDelay, cfg.MaxRetryDelay, int(attempts)))
+}
+
+// backoff returns the delay before retry attempt (0 based): base * 2^attempt capped at max,
+// jittered to between half and all of it
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	d := max
+	if attempt < 32 && base<<attempt > 0 && base<<attempt < max {
+		d = base << attempt
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// withoutMuted splits the deliveries of alerts whose address or rule is muted off deliveries,
+// returning the others and the IDs of the muted ones
+func withoutMuted(deliveries []postgres.WebhookDelivery) ([]postgres.WebhookDelivery, []uuid.UUID) {
+	var muted []uuid.UUID
+	kept := deliveries[:0]
+	for _, delivery := range deliveries {
+		if delivery.Muted {
+			muted = append(muted, delivery.ID)
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	return kept, muted
+}
+
+// withoutUnverified splits the deliveries to webhooks not verified yet off deliveries, returning
+// the others and the IDs of the unverified ones
+func withoutUnverified(deliveries []postgres.WebhookDelivery) ([]postgres.WebhookDelivery, []uuid.UUID) {
+	var unverified []uuid.UUID
+	kept := deliveries[:0]
+	for _, delivery := range deliveries {
+		if !delivery.Verified {
+			unverified = append(unverified, delivery.ID)
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	return kept, unverified
+}
+
+// withoutUnrouted splits the deliveries of alerts whose severity is not routed to webhooks off
+// deliveries, returning the others and the IDs of the unrouted ones
+func withoutUnrouted(deliveries []postgres.WebhookDelivery) ([]postgres.WebhookDelivery, []uuid.UUID) {
+	var unrouted []uuid.UUID
+	kept := deliveries[:0]
+	for _, delivery := range deliveries {
+		if !severity.Routes(delivery.Severity, "webhook") {
+			unrouted = append(unrouted, delivery.ID)
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	return kept, unrouted
+}
+
+// record marks deliveries, posted together, sent when cause is nil or failed by it
+func record(ctx context.Context, repos postgres.Repositories, deliveries []postgres.WebhookDelivery, cause error) error {
+	if len(deliveries) == 1 {
+		delivery := deliveries[0]
+		if cause == nil {
+			return repos.NotificationDeliveries.MarkSent(ctx, delivery.ID)
+		}
+		return repos.NotificationDeliveries.MarkFailed(ctx, delivery.ID, cause, retryAt(delivery.Attempts, cause))
+	}
+
+	ids := make([]uuid.UUID, len(deliveries))
+	var attempts int32
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+		attempts = max(attempts, delivery.Attempts)
+	}
+	if cause == nil {
+		return repos.NotificationDeliveries.MarkBatchSent(ctx, ids)
+	}
+	return repos.NotificationDeliveries.MarkBatchFailed(ctx, ids, cause, retryAt(attempts, cause))
+}
+
+// block identifies the alerts of an address for transfers of a token in a block, posted to a
+// webhook
+type block struct {
+	webhookID uuid.UUID
+	addressID uuid.UUID
+	number    int64
+	token     pgtype.Text
+}
+
+func blockOf(delivery postgres.WebhookDelivery) (block, bool) {
+	return block{
+		webhookID: delivery.WebhookID,
+		addressID: delivery.AddressID,
+		number:    delivery.BlockNumber.Int64,
+		token:     delivery.TokenAddress,
+	}, delivery.BlockNumber.Valid
+}
+
+// claimBlocks adds the other due deliveries of the blocks with several of deliveries, so that an
+// airdrop claimed in part is aggregated whole
+func claimBlocks(ctx context.Context, repos postgres.Repositories, deliveries []postgres.WebhookDelivery) ([]postgres.WebhookDelivery, error) {
+	if config.GetConfig().Webhooks.AggregateMin == 0 {
+		return deliveries, nil
+	}
+
+	counts := map[block]int{}
+	for _, delivery := range deliveries {
+		if b, ok := blockOf(delivery); ok {
+			b.token = pgtype.Text{}
+			counts[b]++
+		}
+	}
+	ids := make([]uuid.UUID, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+	for b, n := range counts {
+		if n < 2 {
+			continue
+		}
+		more, err := repos.NotificationDeliveries.ClaimBlock(ctx, b.webhookID, b.addressID, b.number, ids, blockLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim the deliveries of block %d: %w", b.number, err)
+		}
+		deliveries = append(deliveries, more...)
+	}
+	return deliveries, nil
+}
+
+// aggregate groups deliveries to post: those of a block with at least WEBHOOK_AGGREGATE_MIN
+// alerts together, the others alone, in the order of their first delivery
+func aggregate(deliveries []postgres.WebhookDelivery) [][]postgres.WebhookDelivery {
+	minimum := config.GetConfig().Webhooks.AggregateMin
+	blocks := map[block][]postgres.WebhookDelivery{}
+	for _, delivery := range deliveries {
+		if b, ok := blockOf(delivery); ok {
+			blocks[b] = append(blocks[b], delivery)
+		}
+	}
+
+	for b, group := range blocks {
+		if minimum == 0 || len(group) < minimum {
+			delete(blocks, b)
+		}
+	}
+
+	var groups [][]postgres.WebhookDelivery
+	for _, delivery := range deliveries {
+		b, _ := blockOf(delivery)
+		group, ok := blocks[b]
+		switch {
+		case !ok:
+			groups = append(groups, []postgres.WebhookDelivery{delivery})
+		case group != nil:
+			// The first of the block's deliveries posts them all
+			groups = append(groups, group)
+			blocks[b] = nil
+		}
+	}
+	return groups
+}
+
+// toAlert returns the alert of a delivery, or the aggregated alert of the deliveries of a block
+func toAlert(deliveries []postgres.WebhookDelivery) Alert {
+	first := deliveries[0]
+	alert := Alert{
+		ID:        first.AlertID,
+		AddressID: first.AddressID,
+		Chain:     first.Chain,
+		Address:   first.Address,
+		Message:   first.Message,
+		Severity:  first.Severity,
+		CreatedAt: first.AlertCreatedAt.Time,
+	}
+	if first.RuleID.Valid {
+		ruleID := uuid.UUID(first.RuleID.Bytes)
+		alert.RuleID = &ruleID
+	}
+	if first.TransactionID.Valid {
+		transactionID := uuid.UUID(first.TransactionID.Bytes)
+		alert.TransactionID = &transactionID
+	}
+	if first.BlockNumber.Valid {
+		alert.BlockNumber = &first.BlockNumber.Int64
+		alert.Value = amount(first.Value).String()
+	}
+	if first.TokenAddress.Valid {
+		alert.TokenAddress = &first.TokenAddress.String
+	}
+	if len(deliveries) == 1 {
+		return alert
+	}
+
+	total := new(big.Int)
+	alert.Count = len(deliveries)
+	alert.AlertIDs = make([]uuid.UUID, len(deliveries))
+	for i, delivery := range deliveries {
+		alert.AlertIDs[i] = delivery.AlertID
+		alert.Severity = severity.Max(alert.Severity, delivery.Severity)
+		total.Add(total, amount(delivery.Value))
+		if delivery.RuleID != first.RuleID {
+			alert.RuleID = nil
+		}
+	}
+	alert.Value = total.String()
+	alert.Message = fmt.Sprintf("%d matching transfers of %s in block %d", alert.Count, first.Address, first.BlockNumber.Int64)
+	return alert
+}
+
+// amount returns the integer value of n, zero when it is NULL
+func amount(n pgtype.Numeric) *big.Int {
+	if !n.Valid || n.Int == nil {
+		return new(big.Int)
+	}
+	v := new(big.Int).Set(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(n.Exp, -n.Exp))), nil)
+	if n.Exp >= 0 {
+		return v.Mul(v, exp)
+	}
+	return v.Quo(v, exp)
+}
@@ -1,49 +1,169 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/analytics"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/api"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/balances"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/jobs"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/live"
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/repository/postgres"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/rpc"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/service"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/webhooks"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/diagnostics"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Administrative commands, e.g. `api-server export`, run instead of the server
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(ctx, os.Args[1], os.Args[2:]))
+	}
+
 	// Load configuration
 	cfg := config.GetConfig()
+	go config.Watch(ctx)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "Blockchain Address Watcher API",
 		// DisableStartupMessage: false,
-		// ErrorHandler:          customErrorHandler,
+		// Errors of middleware and unknown routes are answered in the response envelope too
+		ErrorHandler: api.ErrorHandler,
+		// c.IP(), which API key allowlists and audit records use, takes the client's address from
+		// ProxyHeader only for requests from a trusted proxy, and only when it is a valid address
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// App-Level Middleware
 	app.Use(recover.New())
+	app.Use(api.Tracing())
 	app.Use(logger.New(logger.Config{
-		Format: "[${ip}]:${port} ${status} - ${method} ${path}\n",
+		Format: "[${ip}]:${port} ${status} - ${method} ${path} trace=${locals:trace_id}\n",
 	}))
+	// gzip, deflate or brotli, as the client accepts
+	app.Use(compress.New())
 	app.Use(cors.New(
 		cors.Config{
-			AllowOrigins: "*",
-			AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-			AllowHeaders: "Origin,Content-Type,Accept,Authorization",
+			AllowOrigins:  "*",
+			AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
+			AllowHeaders:  "Origin,Content-Type,Accept,Authorization,X-API-Key,If-None-Match,traceparent,tracestate",
+			ExposeHeaders: "ETag,traceparent",
 		},
 	))
 
 	// Initialize database
-	// TODO: This needs to be fixed - currently creating both connection and pool
-	// The repository should use the pool, but NewUserRepository receives nil
-	db := postgres.GetDatabaseInstance()
+	repos, txManager, health, closeDB := openDatabase(cfg)
 	log.Printf("Database connected successfully")
 
-	// Setup routes
-	api.SetupRoutes(app, db)
+	// Count the API calls of signed-in users, on the routes registered below
+	usage := analytics.New(repos.APIUsage, cfg.Analytics)
+	app.Use(api.RecordUsage(usage))
+	// Requests made with the impersonation tokens of support staff show in the user's activity
+	app.Use(api.AuditImpersonation(repos.AccountEvents))
+	recording := make(chan struct{})
+	go func() {
+		usage.Run(ctx)
+		close(recording)
+	}()
+
+	// The node of CHAIN_NETWORK, whose URL and key follow reloads, with idempotent calls cached
+	cache, err := rpc.NewCache(cfg.Chain.Network, cfg.Chain.Cache)
+	if err != nil {
+		log.Printf("Calling the node without a cache: %v", err)
+	}
+	node := rpc.New(func() (string, string) {
+		chain := config.GetConfig().Chain
+		return chain.RPCURL, chain.RPCAPIKey
+	}, cache)
+
+	// Setup routes, and the queue of the background jobs they enqueue
+	queue := jobs.New(repos.Jobs, cfg.Jobs)
+	api.SetupRoutes(app, repos, txManager, health, queue, node)
+	workers := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(workers)
+	}()
+
+	// Alerts are posted to the users' webhooks, one at a time or in batches
+	dispatcher := webhooks.New(repos.NotificationDeliveries, txManager)
+	delivering := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(delivering)
+	}()
+
+	// ERC-20 balances of the balance watches are polled from the node
+	poller := balances.New(repos.BalanceWatches, txManager, cfg.Chain.Network, node)
+	polling := make(chan struct{})
+	go func() {
+		poller.Run(ctx)
+		close(polling)
+	}()
+
+	// Operations dashboard and support tools, for requests with the admin token or an operator's own
+	api.SetupAdminRoutes(app, repos, cfg.EngineAdminURL, func() api.AdminCredentials {
+		cfg := config.GetConfig()
+		return api.AdminCredentials{Token: cfg.AdminToken, Operators: cfg.AdminOperatorTokens}
+	})
+
+	// Aged alerts and detached transactions partitions move to the archive, which serves them back
+	archiving := make(chan struct{})
+	if cfg.Archive.URL != "" {
+		archiver := openArchiver(ctx, cfg)
+		api.SetupArchiveRoutes(app, archiver)
+		go func() {
+			archiver.Run(ctx)
+			close(archiving)
+		}()
+		log.Printf("Archiving aged records to %s", cfg.Archive.URL)
+	} else {
+		close(archiving)
+	}
+
+	// Transfers published by the engine are pushed to the users' WebSocket connections
+	listening := make(chan struct{})
+	if cfg.LiveActivity {
+		hub := live.NewHub(postgres.GetDatabaseInstance().Pool)
+		api.SetupLiveRoutes(app, repos, hub)
+		// Connections of signed out sessions are told and closed
+		service.NotifyRevokedSessions(hub.RevokeSessions)
+		go func() {
+			hub.Run(ctx)
+			close(listening)
+		}()
+		log.Printf("Live activity enabled at /ws/activity")
+	} else {
+		close(listening)
+	}
+
+	// Profiles and runtime metrics, for requests with the diagnostics token
+	if config.Settings().Diagnostics.Enabled {
+		app.All("/debug/*", adaptor.HTTPHandler(diagnostics.Handler(func() string {
+			return config.Settings().Diagnostics.Token
+		})))
+		log.Printf("Diagnostics endpoints enabled under /debug/")
+	}
 
 	// Start server
 	port := cfg.Port
@@ -51,21 +171,50 @@ func main() {
 		port = "7000"
 	}
 
+	// Stop accepting requests on SIGINT or SIGTERM, letting in-flight ones finish within
+	// SERVER_SHUTDOWN_TIMEOUT. Default signal handling is restored, so a second signal kills the
+	// process.
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		<-ctx.Done()
+		stop()
+		timeout := config.GetConfig().ShutdownTimeout
+		log.Printf("Shutting down, waiting up to %s for requests in flight", timeout)
+		if err := app.ShutdownWithTimeout(timeout); err != nil {
+			log.Printf("Failed to shut down server: %v", err)
+		}
+	}()
+
+	exitCode := 0
 	log.Printf("Server starting on port %s", port)
 	if err := app.Listen(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+		// The background loops are stopped and the database closed all the same
+		log.Printf("Failed to start server: %v", err)
+		exitCode = 1
+		stop()
 	}
-}
-
-// // customErrorHandler handles errors in a standardized way
-// func customErrorHandler(c *fiber.Ctx, err error) error {
-// 	code := fiber.StatusInternalServerError
+	// Listen returns as soon as the listener closes, the requests in flight still need the database
+	<-served
 
-// 	if e, ok := err.(*fiber.Error); ok {
-// 		code = e.Code
-// 	}
+	// Running jobs are stopped and requeued, webhook POSTs, balance checks and archive uploads in
+	// flight recorded, live activity connections closed, and the API usage counted during the
+	// shutdown written, before the database pool is closed
+	<-workers
+	<-delivering
+	<-polling
+	<-archiving
+	<-listening
+	<-recording
+	flushCtx, cancel := context.WithTimeout(context.Background(), usageFlushTimeout)
+	usage.Flush(flushCtx)
+	cancel()
+	closeDB()
+	log.Printf("Database closed")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
 
-// 	return c.Status(code).JSON(fiber.Map{
-// 		"error": err.Error(),
-// 	})
-// }
+// usageFlushTimeout bounds writing the API usage counted during the shutdown
+const usageFlushTimeout = 10 * time.Second
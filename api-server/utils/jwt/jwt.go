@@ -1,57 +1,176 @@
 package jwt
 
 import (
+	"context"
 	"time"
 
 	"github.com/ahsansaif47/blockchain-address-watcher/api-server/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/api-server/internal/tenant"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-var jwtKey = []byte(config.GetConfig().JWTSecret)
+// jwtKey returns the current signing key, which may change when secrets are refreshed
+func jwtKey() []byte {
+	return []byte(config.GetConfig().JWTSecret)
+}
 
 type Claims struct {
-	Email string
+	UserID string
+	Email  string
+	// Impersonator is the support operator acting as the user, set on impersonation tokens only
+	Impersonator string `json:",omitempty"`
+	// SessionID is the session of tokens handed out by login, refresh and impersonation, which
+	// ends when it is revoked or logged out
+	SessionID string `json:",omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(email string) (string, error) {
-	expTime := time.Now().Add(1 * time.Hour)
+// Locals set by JWTMiddleware for impersonation tokens, the operator and the token's ID
+const (
+	ImpersonatorLocal = "impersonator"
+	TokenIDLocal      = "token_id"
+)
+
+// SessionIDLocal holds the session of the request's token, unset for tokens without one
+const SessionIDLocal = "session_id"
+
+// SessionCheck reports whether the user's session sessionID is active, see CheckSessions
+type SessionCheck func(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+
+var sessionCheck SessionCheck
+
+// CheckSessions makes JWTMiddleware reject the tokens of sessions check reports inactive, e.g.
+// revoked by the user. It is set once before the routes serve requests.
+func CheckSessions(check SessionCheck) {
+	sessionCheck = check
+}
+
+// APIKeyIDLocal holds the ID of the API key a request was authenticated with ahead of
+// JWTMiddleware, which lets such requests through without a token
+const APIKeyIDLocal = "api_key_id"
+
+// ImpersonatedByHeader marks the responses to requests made with an impersonation token
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+// GenerateJWT returns a token of the user for the session sessionID, with its expiry
+func GenerateJWT(userID, email, sessionID string) (string, time.Time, error) {
+	expTime := time.Now().Add(config.GetConfig().JWTExpiry)
 	claims := &Claims{
-		Email: email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "home-kitchens",
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtKey())
+	return token, expTime, err
+}
+
+// GenerateImpersonationJWT returns a token acting as the user for the support operator impersonator,
+// valid for ttl unless its session sessionID is revoked first, with its ID and expiry for the
+// audit log
+func GenerateImpersonationJWT(userID, email, impersonator, sessionID string, ttl time.Duration) (string, string, time.Time, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:       userID,
+		Email:        email,
+		Impersonator: impersonator,
+		SessionID:    sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "home-kitchens",
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtKey())
+	return token, claims.ID, claims.ExpiresAt.Time, err
 }
 
 // I wont be needing this in the auth service but this will be used in other services
 func JWTMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if keyID, _ := c.Locals(APIKeyIDLocal).(string); keyID != "" {
+			return c.Next()
+		}
+
 		tokenStr := c.Get("Authorization")
 		if tokenStr == "" {
-			return c.SendStatus(fiber.StatusUnauthorized)
+			return fiber.ErrUnauthorized
 		}
 
 		claims := &Claims{}
 		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (any, error) {
-			return jwtKey, nil
+			return jwtKey(), nil
 		})
 
 		if err != nil {
-			return c.SendStatus(fiber.StatusUnauthorized)
+			return fiber.ErrUnauthorized
 		}
 
 		if !token.Valid {
-			return c.SendStatus(fiber.StatusUnauthorized)
+			return fiber.ErrUnauthorized
 		}
 
+		// The request acts for the token's user: the repositories only reach that user's records
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			return fiber.ErrUnauthorized
+		}
+		c.SetUserContext(tenant.WithUser(c.UserContext(), userID))
+
+		// Tokens of a revoked or logged out session stop working at once, not when they expire
+		if claims.SessionID != "" && sessionCheck != nil {
+			sessionID, err := uuid.Parse(claims.SessionID)
+			if err != nil {
+				return fiber.ErrUnauthorized
+			}
+			active, err := sessionCheck(c.UserContext(), userID, sessionID)
+			if err != nil {
+				return err
+			}
+			if !active {
+				return fiber.NewError(fiber.StatusUnauthorized, "the session was signed out, log in again")
+			}
+			c.Locals(SessionIDLocal, claims.SessionID)
+		}
+
+		c.Locals("user_id", claims.UserID)
 		c.Locals("email", claims.Email)
+		if claims.Impersonator != "" {
+			c.Locals(ImpersonatorLocal, claims.Impersonator)
+			c.Locals(TokenIDLocal, claims.ID)
+			c.Set(ImpersonatedByHeader, claims.Impersonator)
+		}
+
+		return c.Next()
+	}
+}
 
+// DenyImpersonation rejects requests made with an impersonation token, for the routes support
+// staff must not use on the user's behalf, such as changing their password or deleting them.
+// It follows JWTMiddleware.
+func DenyImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if operator, _ := c.Locals(ImpersonatorLocal).(string); operator != "" {
+			return fiber.NewError(fiber.StatusForbidden, "not allowed while impersonating the user")
+		}
+		return c.Next()
+	}
+}
+
+// DenyAPIKey rejects requests authenticated with an API key, for the routes managing the user's
+// credentials, which need a token from logging in. It follows JWTMiddleware.
+func DenyAPIKey() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if keyID, _ := c.Locals(APIKeyIDLocal).(string); keyID != "" {
+			return fiber.NewError(fiber.StatusForbidden, "not allowed with an API key, log in instead")
+		}
 		return c.Next()
 	}
 }
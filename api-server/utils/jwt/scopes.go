@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Scopes of API keys, each granting a key the routes of one kind of record. Tokens from logging in
+// have every scope.
+const (
+	ScopeReadAccount    = "read:account"
+	ScopeWriteAccount   = "write:account"
+	ScopeReadAddresses  = "read:addresses"
+	ScopeWriteAddresses = "write:addresses"
+	ScopeReadRules      = "read:rules"
+	ScopeWriteRules     = "write:rules"
+	ScopeReadAlerts     = "read:alerts"
+)
+
+// Scopes lists the scopes an API key can be given
+var Scopes = []string{
+	ScopeReadAccount,
+	ScopeWriteAccount,
+	ScopeReadAddresses,
+	ScopeWriteAddresses,
+	ScopeReadRules,
+	ScopeWriteRules,
+	ScopeReadAlerts,
+}
+
+// APIKeyScopesLocal holds the scopes of the API key a request was authenticated with
+const APIKeyScopesLocal = "api_key_scopes"
+
+// RequireScope rejects requests authenticated with an API key without scope. It follows
+// JWTMiddleware.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return checkScope(c, scope)
+	}
+}
+
+// ReadWriteScope requires read of GET and HEAD requests and write of the others, for route groups
+// both reading and changing one kind of record. It follows JWTMiddleware.
+func ReadWriteScope(read, write string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+			return checkScope(c, read)
+		}
+		return checkScope(c, write)
+	}
+}
+
+func checkScope(c *fiber.Ctx, scope string) error {
+	if keyID, _ := c.Locals(APIKeyIDLocal).(string); keyID != "" {
+		scopes, _ := c.Locals(APIKeyScopesLocal).([]string)
+		if !slices.Contains(scopes, scope) {
+			return fiber.NewError(fiber.StatusForbidden, "the API key lacks the "+scope+" scope")
+		}
+	}
+	return c.Next()
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"math/big"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,14 @@ func ToPgText(s *string) pgtype.Text {
 	}
 }
 
+// ToPgUUID converts id to a nullable UUID, NULL for the zero UUID
+func ToPgUUID(id uuid.UUID) pgtype.UUID {
+	return pgtype.UUID{
+		Bytes: id,
+		Valid: id != uuid.Nil,
+	}
+}
+
 func ToPgTime(t time.Time) pgtype.Timestamptz {
 	return pgtype.Timestamptz{
 		Time:  t,
@@ -25,6 +34,14 @@ func ToPgTime(t time.Time) pgtype.Timestamptz {
 	}
 }
 
+// ToPgDate is the UTC date of t
+func ToPgDate(t time.Time) pgtype.Date {
+	return pgtype.Date{
+		Time:  t.UTC().Truncate(24 * time.Hour),
+		Valid: true,
+	}
+}
+
 func PgTextToString(pgText pgtype.Text) string {
 	if !pgText.Valid {
 		return ""
@@ -44,3 +61,16 @@ func PgUUIDToUUID(u pgtype.UUID) (string, error) {
 
 	return id.String(), nil
 }
+
+// PgNumericToString formats an integral NUMERIC, such as a token amount, in base 10
+func PgNumericToString(n pgtype.Numeric) string {
+	if !n.Valid || n.Int == nil {
+		return "0"
+	}
+	v := new(big.Int).Set(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(n.Exp, -n.Exp))), nil)
+	if n.Exp >= 0 {
+		return v.Mul(v, exp).String()
+	}
+	return v.Quo(v, exp).String()
+}
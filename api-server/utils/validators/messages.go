@@ -0,0 +1,82 @@
+package validators
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// localeFiles are the message catalogs, one JSON object per language named after its BCP 47 tag
+// (e.g. "pt-BR.json"), mapping message keys to templates with {field} and {param} placeholders.
+// Keys missing from a catalog fall back to English.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalog holds the validation messages of one language
+type catalog map[string]string
+
+// format fills the template of key with field and param, falling back to the English template
+func (c catalog) format(key, field, param string) string {
+	template, ok := c[key]
+	if !ok {
+		template = catalogs[0][key]
+	}
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(template)
+}
+
+// catalogs are the catalogs of the languages of tags, English first as the fallback of matcher
+var catalogs, tags = loadCatalogs()
+
+var matcher = language.NewMatcher(tags)
+
+// catalogFor returns the catalog best matching acceptLanguage, an Accept-Language header
+func catalogFor(acceptLanguage string) catalog {
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return catalogs[0]
+	}
+	_, i, _ := matcher.Match(preferred...)
+	return catalogs[i]
+}
+
+// loadCatalogs parses the embedded catalogs. They are part of the binary, so a malformed one is a
+// programming error and panics.
+func loadCatalogs() ([]catalog, []language.Tag) {
+	english := mustLoadCatalog("locales/en.json")
+	catalogs, tags := []catalog{english}, []language.Tag{language.English}
+
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), ".json")
+		if name == "en" {
+			continue
+		}
+		tag, err := language.Parse(name)
+		if err != nil {
+			panic(fmt.Sprintf("validation message catalog %s is not named after a language: %v", f.Name(), err))
+		}
+		catalogs = append(catalogs, mustLoadCatalog(path.Join("locales", f.Name())))
+		tags = append(tags, tag)
+	}
+	return catalogs, tags
+}
+
+func mustLoadCatalog(name string) catalog {
+	data, err := localeFiles.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	var c catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		panic(fmt.Sprintf("failed to parse validation message catalog %s: %v", name, err))
+	}
+	return c
+}
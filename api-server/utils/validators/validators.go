@@ -1,10 +1,11 @@
 package validators
 
 import (
-	"fmt"
+	"reflect"
 	"regexp"
 	"unicode"
 
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -15,6 +16,7 @@ func NewValidator() *validator.Validate {
 	// Register custom validators
 	v.RegisterValidation("phone", validatePhone)
 	v.RegisterValidation("strong_password", validateStrongPassword)
+	v.RegisterValidation("chain", validateChain)
 
 	return v
 }
@@ -31,6 +33,11 @@ func validatePhone(fl validator.FieldLevel) bool {
 
 }
 
+// validateChain validates a chain addresses can be watched on, see package chains
+func validateChain(fl validator.FieldLevel) bool {
+	return chains.Valid(fl.Field().String())
+}
+
 // validateStrongPassword validates password strength
 // Minimum 8 characters, at least one uppercase, one lowercase, one digit, one special character
 func validateStrongPassword(fl validator.FieldLevel) bool {
@@ -63,33 +70,36 @@ func validateStrongPassword(fl validator.FieldLevel) bool {
 	return hasUpper && hasLower && hasDigit && hasSpecial
 }
 
-// GetValidationErrors extracts validation error messages
-func GetValidationErrors(err error) map[string]string {
+// GetValidationErrors extracts validation error messages, in the language of acceptLanguage (an
+// Accept-Language header) that best matches a message catalog, English by default
+func GetValidationErrors(err error, acceptLanguage string) map[string]string {
 	errors := make(map[string]string)
+	messages := catalogFor(acceptLanguage)
 
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, e := range validationErrors {
-			field := e.Field()
-			tag := e.Tag()
-
-			switch tag {
-			case "required":
-				errors[field] = fmt.Sprintf("%s is required", field)
-			case "email":
-				errors[field] = fmt.Sprintf("%s must be a valid email address", field)
-			case "min":
-				errors[field] = fmt.Sprintf("%s must be at least %s characters", field, e.Param())
-			case "max":
-				errors[field] = fmt.Sprintf("%s must be at most %s characters", field, e.Param())
-			case "phone":
-				errors[field] = fmt.Sprintf("%s must be a valid phone number", field)
-			case "strong_password":
-				errors[field] = fmt.Sprintf("%s must be at least 8 characters with uppercase, lowercase, digit, and special character", field)
-			default:
-				errors[field] = fmt.Sprintf("%s is invalid", field)
-			}
+			errors[e.Field()] = messages.format(messageKey(e), e.Field(), e.Param())
 		}
 	}
 
 	return errors
 }
+
+// messageKey returns the catalog key of the message for e. Bounds of numbers are not lengths,
+// their messages leave out the unit.
+func messageKey(e validator.FieldError) string {
+	switch tag := e.Tag(); tag {
+	case "required", "email", "phone", "strong_password", "chain":
+		return tag
+	case "min", "max":
+		switch e.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return tag + "_number"
+		}
+		return tag
+	default:
+		return "invalid"
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"time"
+
+	client "github.com/ahsansaif47/blockchain-address-watcher/api-client"
+	"github.com/spf13/cobra"
+)
+
+var addressesCmd = &cobra.Command{
+	Use:   "addresses",
+	Short: "Manage your watched addresses",
+}
+
+var addressesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your watched addresses, oldest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		var addresses []client.Address
+		for address, err := range c.Addresses(cmd.Context(), client.ListAddressesOptions{}) {
+			if err != nil {
+				return err
+			}
+			addresses = append(addresses, address)
+		}
+		return render(cmd, addresses, func() *table {
+			return addressesTable(addresses...)
+		})
+	},
+}
+
+var addressesAddCmd = &cobra.Command{
+	Use:   "add CHAIN ADDRESS",
+	Short: "Watch an address",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		label, _ := cmd.Flags().GetString("label")
+		notes, _ := cmd.Flags().GetString("notes")
+		address, err := c.CreateAddress(cmd.Context(), client.CreateAddressRequest{
+			Chain:   args[0],
+			Address: args[1],
+			Label:   label,
+			Notes:   notes,
+		})
+		if err != nil {
+			return err
+		}
+		return render(cmd, address, func() *table {
+			return addressesTable(*address)
+		})
+	},
+}
+
+var addressesLabelCmd = &cobra.Command{
+	Use:   "label ID",
+	Short: "Replace the label and notes of an address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		// The update is based on the address as read now
+		current, err := c.Address(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		req := client.UpdateAddressRequest{Label: current.Label, Notes: current.Notes, Version: current.Version}
+		if cmd.Flags().Changed("label") {
+			req.Label, _ = cmd.Flags().GetString("label")
+		}
+		if cmd.Flags().Changed("notes") {
+			req.Notes, _ = cmd.Flags().GetString("notes")
+		}
+		address, err := c.UpdateAddress(cmd.Context(), args[0], req)
+		if err != nil {
+			return err
+		}
+		return render(cmd, address, func() *table {
+			return addressesTable(*address)
+		})
+	},
+}
+
+var addressesRemoveCmd = &cobra.Command{
+	Use:   "remove ID",
+	Short: "Stop watching an address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		address, err := c.DeleteAddress(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		return render(cmd, address, func() *table {
+			return addressesTable(*address)
+		})
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{addressesAddCmd, addressesLabelCmd} {
+		cmd.Flags().String("label", "", "nickname of the address")
+		cmd.Flags().String("notes", "", "free-form notes")
+	}
+	addressesCmd.AddCommand(addressesListCmd, addressesAddCmd, addressesLabelCmd, addressesRemoveCmd)
+}
+
+func addressesTable(addresses ...client.Address) *table {
+	t := &table{header: []string{"ID", "CHAIN", "ADDRESS", "LABEL", "CREATED"}}
+	for _, a := range addresses {
+		t.add(a.ID, a.Chain, a.Address, orDash(a.Label), a.CreatedAt.Local().Format(time.DateTime))
+	}
+	return t
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Operations commands, for the holder of the ADMIN_TOKEN",
+}
+
+var adminStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the operations dashboard snapshot",
+	Long:  `stats shows the figures of the operations dashboard. The admin token is read from BAW_ADMIN_TOKEN.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if os.Getenv("BAW_ADMIN_TOKEN") == "" {
+			return errors.New("BAW_ADMIN_TOKEN is not set")
+		}
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		stats, err := c.AdminStats(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return render(cmd, stats, func() *table {
+			t := &table{header: []string{"METRIC", "VALUE"}}
+			t.add("users", stats.Users)
+			t.add("addresses", stats.Addresses.Total)
+			t.add("alerts (24h)", stats.Alerts24h)
+			t.add("notification failure rate (24h)", fmt.Sprintf("%.2f%%", stats.Notifications.FailureRate*100))
+			for _, chain := range stats.Chains {
+				if chain.HeadLagSeconds != nil {
+					t.add("head lag "+chain.Chain, fmt.Sprintf("%.0fs", *chain.HeadLagSeconds))
+				}
+			}
+			if stats.Consumer.Lag != nil {
+				t.add("consumer lag", *stats.Consumer.Lag)
+			}
+			return t
+		})
+	},
+}
+
+func init() {
+	adminCmd.AddCommand(adminStatsCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Read your alerts",
+}
+
+var alertsArchivedCmd = &cobra.Command{
+	Use:   "archived",
+	Short: "Read alerts back from the archive, oldest first",
+	Long: `archived reads your alerts created in [--from, --to) back from the archive, at most
+31 days at a time. With -o json the alerts are written one JSON object per line as they
+are read.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		from, err := timeFlag(cmd, "from")
+		if err != nil {
+			return err
+		}
+		to, err := timeFlag(cmd, "to")
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		t := &table{header: []string{"CREATED", "CHAIN", "HASH", "MESSAGE"}}
+		for alert, err := range c.ArchivedAlerts(cmd.Context(), from, to) {
+			if err != nil {
+				return err
+			}
+			if output, _ := cmd.Flags().GetString("output"); output == "json" {
+				if err := enc.Encode(alert); err != nil {
+					return err
+				}
+				continue
+			}
+			chain, hash := "-", "-"
+			if alert.Transfer != nil {
+				chain, hash = alert.Transfer.Chain, alert.Transfer.Hash
+			}
+			t.add(alert.CreatedAt.Local().Format(time.DateTime), chain, hash, alert.Message)
+		}
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return nil
+		}
+		return render(cmd, nil, func() *table { return t })
+	},
+}
+
+func init() {
+	flags := alertsArchivedCmd.Flags()
+	flags.String("from", "", "start of the period, RFC 3339 or YYYY-MM-DD")
+	flags.String("to", "", "end of the period, RFC 3339 or YYYY-MM-DD (default now)")
+	alertsArchivedCmd.MarkFlagRequired("from")
+	alertsCmd.AddCommand(alertsArchivedCmd)
+}
+
+// timeFlag parses the time flag name, given in RFC 3339 or as a local date. An empty flag is now.
+func timeFlag(cmd *cobra.Command, name string) (time.Time, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
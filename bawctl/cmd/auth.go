@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Sign in and store the token in the config file",
+	Long: `login signs in with an email and password and stores the server and token in the
+config file. The password is prompted for, or read from stdin with --password-stdin.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, cfg, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		in := bufio.NewReader(os.Stdin)
+
+		email, _ := cmd.Flags().GetString("email")
+		if email == "" {
+			if email, err = prompt(in, "Email: "); err != nil {
+				return err
+			}
+		}
+		var password string
+		if fromStdin, _ := cmd.Flags().GetBool("password-stdin"); fromStdin {
+			password, err = readLine(in)
+		} else {
+			password, err = promptPassword(in, "Password: ")
+		}
+		if err != nil {
+			return err
+		}
+
+		res, err := c.Login(cmd.Context(), email, password)
+		if err != nil {
+			return err
+		}
+		cfg.Email, cfg.Token = email, res.Token
+		if err := cfg.save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Signed in to %s as %s\n", cfg.Server, email)
+		return nil
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		cfg.Email, cfg.Token = "", ""
+		return cfg.save()
+	},
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the signed-in user",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		me, err := c.Me(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return render(cmd, me, func() *table {
+			t := &table{header: []string{"ID", "EMAIL", "PHONE", "WALLET", "SUBSCRIBED"}}
+			t.add(me.ID, me.Email, orDash(me.PhoneNo), orDash(me.WalletAddress), me.Subscribed)
+			return t
+		})
+	},
+}
+
+func init() {
+	loginCmd.Flags().String("email", "", "email to sign in with, prompted for when empty")
+	loginCmd.Flags().Bool("password-stdin", false, "read the password from stdin")
+}
+
+func prompt(in *bufio.Reader, label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	return readLine(in)
+}
+
+// promptPassword prompts for a password, not echoing it when stdin is a terminal
+func promptPassword(in *bufio.Reader, label string) (string, error) {
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	return prompt(in, label)
+}
+
+// stty changes the settings of the terminal on stdin, failing when there is none
+func stty(arg string) error {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return errors.New("stdin is not a terminal")
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
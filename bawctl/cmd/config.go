@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultServer is the server used when none is configured, the api-server's default port
+const defaultServer = "http://localhost:7000"
+
+// Config is the config file of bawctl. It holds the token of the signed-in user, so it is written
+// readable by its owner only.
+type Config struct {
+	Server string `json:"server"`
+	Email  string `json:"email,omitempty"`
+	Token  string `json:"token,omitempty"`
+
+	path string
+}
+
+// configPath is --config, or config.json in the user's bawctl config directory
+func configPath(cmd *cobra.Command) (string, error) {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory, set --config: %w", err)
+	}
+	return filepath.Join(dir, "bawctl", "config.json"), nil
+}
+
+// loadConfig reads the config file, a missing one being empty, and applies --server and
+// BAW_SERVER
+func loadConfig(cmd *cobra.Command) (*Config, error) {
+	path, err := configPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+
+	server, _ := cmd.Flags().GetString("server")
+	cfg.Server = firstNonEmpty(server, os.Getenv("BAW_SERVER"), cfg.Server, defaultServer)
+	return cfg, nil
+}
+
+// save writes the config file, creating its directory
+func (c *Config) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+func httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"time"
+
+	client "github.com/ahsansaif47/blockchain-address-watcher/api-client"
+	"github.com/spf13/cobra"
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Show your background jobs, e.g. an account purge",
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your background jobs, newest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		jobs, _, err := c.ListJobs(cmd.Context(), client.ListJobsOptions{Limit: limit})
+		if err != nil {
+			return err
+		}
+		return render(cmd, jobs, func() *table {
+			return jobsTable(jobs...)
+		})
+	},
+}
+
+var jobsGetCmd = &cobra.Command{
+	Use:   "get ID",
+	Short: "Show a background job, optionally waiting for it to finish",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		var job *client.Job
+		if wait, _ := cmd.Flags().GetBool("wait"); wait {
+			job, err = c.WaitForJob(cmd.Context(), args[0], time.Second)
+		} else {
+			job, err = c.Job(cmd.Context(), args[0])
+		}
+		if err != nil {
+			return err
+		}
+		return render(cmd, job, func() *table {
+			return jobsTable(*job)
+		})
+	},
+}
+
+func init() {
+	jobsListCmd.Flags().Int("limit", 0, "maximum jobs, 1 to 100 (default 20)")
+	jobsGetCmd.Flags().Bool("wait", false, "wait until the job succeeded or failed")
+	jobsCmd.AddCommand(jobsListCmd, jobsGetCmd)
+}
+
+func jobsTable(jobs ...client.Job) *table {
+	t := &table{header: []string{"ID", "KIND", "STATUS", "ATTEMPTS", "CREATED", "ERROR"}}
+	for _, j := range jobs {
+		t.add(j.ID, j.Kind, j.Status, j.Attempts, j.CreatedAt.Local().Format(time.DateTime), orDash(j.LastError))
+	}
+	return t
+}
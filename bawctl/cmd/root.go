@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	client "github.com/ahsansaif47/blockchain-address-watcher/api-client"
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "bawctl",
+	Short: "Command-line client of the Blockchain Address Watcher API",
+	Long: `bawctl talks to the Blockchain Address Watcher API. Sign in with 'bawctl login',
+which stores the server and token in the config file for the following commands.
+
+The server is taken from --server, then BAW_SERVER, then the config file. The token
+from BAW_TOKEN overrides the stored one, e.g. for scripts.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// Execute runs the command selected by the command line arguments
+func Execute() error {
+	err := rootCmd.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if client.IsUnauthorized(err) {
+			fmt.Fprintln(os.Stderr, "Sign in again with 'bawctl login'")
+		}
+	}
+	return err
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.String("config", "", "config file holding the server and token (default $XDG_CONFIG_HOME/bawctl/config.json)")
+	flags.String("server", "", "API base URL, e.g. http://localhost:7000 (BAW_SERVER)")
+	flags.StringP("output", "o", "table", "output format: table or json")
+	flags.Duration("timeout", 30*time.Second, "timeout of each request")
+
+	rootCmd.AddCommand(loginCmd, logoutCmd, whoamiCmd, addressesCmd, searchCmd, jobsCmd, alertsCmd, usageCmd, adminCmd)
+}
+
+// newClient returns a client of the configured server, signed in with the stored token or
+// BAW_TOKEN
+func newClient(cmd *cobra.Command) (*client.Client, *Config, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	opts := []client.Option{
+		client.WithHTTPClient(httpClient(timeout)),
+		client.WithUserAgent("bawctl"),
+	}
+	if token := firstNonEmpty(os.Getenv("BAW_TOKEN"), cfg.Token); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	if token := os.Getenv("BAW_ADMIN_TOKEN"); token != "" {
+		opts = append(opts, client.WithAdminToken(token))
+	}
+	return client.New(cfg.Server, opts...), cfg, nil
+}
+
+// requireToken fails commands of a signed-in user before they reach the server without a token
+func requireToken(c *client.Client) error {
+	if c.Token() == "" {
+		return errors.New("not signed in, run 'bawctl login' or set BAW_TOKEN")
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search QUERY",
+	Short: "Search your addresses and the known entities",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		res, err := c.Search(cmd.Context(), args[0], limit)
+		if err != nil {
+			return err
+		}
+		return render(cmd, res, func() *table {
+			t := &table{header: []string{"KIND", "CHAIN", "ADDRESS", "NAME", "RANK"}}
+			for _, a := range res.Addresses {
+				t.add("address", a.Chain, a.Address, orDash(firstNonEmpty(a.Label, a.EntityName)), a.Rank)
+			}
+			for _, e := range res.Entities {
+				t.add("entity", e.Chain, e.Address, e.Name, e.Rank)
+			}
+			return t
+		})
+	},
+}
+
+func init() {
+	searchCmd.Flags().Int("limit", 0, "maximum results per kind, 1 to 100 (default 20)")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show your API usage per endpoint",
+	Long: `usage shows your calls of each API endpoint over the last --days UTC days, with their
+4xx and 5xx responses and latencies. -o json adds the breakdown per day.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		if err := requireToken(c); err != nil {
+			return err
+		}
+		days, _ := cmd.Flags().GetInt("days")
+		res, err := c.Analytics(cmd.Context(), days)
+		if err != nil {
+			return err
+		}
+		return render(cmd, res, func() *table {
+			t := &table{header: []string{"METHOD", "ROUTE", "CALLS", "4XX", "5XX", "AVG MS", "MAX MS"}}
+			for _, e := range res.Endpoints {
+				t.add(e.Method, e.Route, e.Calls, e.ClientErrors, e.ServerErrors,
+					fmt.Sprintf("%.1f", e.AvgLatencyMs), fmt.Sprintf("%.1f", e.MaxLatencyMs))
+			}
+			return t
+		})
+	},
+}
+
+func init() {
+	usageCmd.Flags().Int("days", 0, "days reported, today included, 1 to 90 (default 30)")
+}
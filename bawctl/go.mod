@@ -0,0 +1,15 @@
+module github.com/ahsansaif47/blockchain-address-watcher/bawctl
+
+go 1.25.5
+
+require (
+	github.com/ahsansaif47/blockchain-address-watcher/api-client v0.0.0
+	github.com/spf13/cobra v1.10.2
+)
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.10 // indirect
+)
+
+replace github.com/ahsansaif47/blockchain-address-watcher/api-client => ../api-client
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/bawctl/cmd"
+)
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,151 @@
+// Package activity publishes the transfers matched by the chain watchers as live activity, see
+// package liveactivity of the shared module. Every match is published, pending ones included, once
+// per user watching the address, so the api-server can forward it to that user's connections
+// without reading the database.
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watchlist"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/liveactivity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// logger is used by the publisher
+var logger = logging.For("activity")
+
+// queueSize bounds the matches waiting to be published; further matches are dropped
+const queueSize = 1000
+
+// Publisher sends the matches of the chain watchers with pg_notify. Matches are queued without
+// blocking the watchers, and dropped, counted, when the queue is full.
+//
+// Example usage:
+//
+//	publisher := activity.NewPublisher(watched, pool)
+//	watchers.OnMatch(publisher.OnMatch)
+//	go publisher.Run(ctx)
+type Publisher struct {
+	watched *watchlist.Watchlist
+	pool    *pgxpool.Pool
+	queue   chan watcher.Match
+
+	mu        sync.Mutex
+	published int
+	failed    int
+	dropped   int
+	lastError string
+}
+
+// NewPublisher creates a Publisher sending the matches of the addresses of watched on pool's
+// database
+func NewPublisher(watched *watchlist.Watchlist, pool *pgxpool.Pool) *Publisher {
+	return &Publisher{
+		watched: watched,
+		pool:    pool,
+		queue:   make(chan watcher.Match, queueSize),
+	}
+}
+
+// OnMatch queues m, see watcher.Dispatcher.OnMatch
+func (p *Publisher) OnMatch(m watcher.Match) {
+	select {
+	case p.queue <- m:
+	default:
+		p.mu.Lock()
+		p.dropped++
+		n := p.dropped
+		p.mu.Unlock()
+		if n == 1 || n%100 == 0 {
+			logger.Warn("Live activity queue is full, dropping transfers", "dropped", n)
+		}
+	}
+}
+
+// Run publishes the queued matches until ctx is done
+func (p *Publisher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-p.queue:
+			p.publish(ctx, m)
+		}
+	}
+}
+
+// publish sends m to every user watching its address. Users no longer watching it by now are not
+// sent it.
+func (p *Publisher) publish(ctx context.Context, m watcher.Match) {
+	for _, w := range p.watched.Watchers(m.Chain, m.Address) {
+		payload, err := json.Marshal(liveactivity.Message{UserID: w.UserID, Event: event(m, w)})
+		if err == nil && len(payload) >= liveactivity.MaxPayload {
+			// Only an unusually long label gets here
+			e := event(m, w)
+			e.Label = ""
+			payload, err = json.Marshal(liveactivity.Message{UserID: w.UserID, Event: e})
+		}
+		if err == nil {
+			_, err = p.pool.Exec(ctx, "SELECT pg_notify($1, $2)", liveactivity.Channel, string(payload))
+		}
+
+		p.mu.Lock()
+		if err != nil {
+			p.failed++
+			p.lastError = err.Error()
+		} else {
+			p.published++
+		}
+		p.mu.Unlock()
+		if err != nil {
+			logger.Error("Failed to publish live activity", "address_id", w.AddressID, "hash", m.Hash, "error", err)
+		}
+	}
+}
+
+// event returns m as an event of the address row of w
+func event(m watcher.Match, w watchlist.Watcher) liveactivity.Event {
+	value := "0"
+	if m.Value != nil {
+		value = m.Value.String()
+	}
+	return liveactivity.Event{
+		Type:        liveactivity.EventTransfer,
+		AddressID:   w.AddressID,
+		Label:       w.Label,
+		Chain:       m.Chain,
+		Address:     m.Address,
+		Direction:   m.Direction,
+		Hash:        m.Hash,
+		LogIndex:    m.LogIndex,
+		BlockNumber: m.BlockNumber,
+		From:        m.From,
+		To:          m.To,
+		Value:       value,
+		Token:       m.Token,
+		Status:      m.Status,
+		OccurredAt:  m.Time,
+	}
+}
+
+// GetStats returns the notifications published and failed, and the matches dropped
+func (p *Publisher) GetStats() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"queued":    len(p.queue),
+		"published": p.published,
+		"failed":    p.failed,
+		"dropped":   p.dropped,
+	}
+	if p.lastError != "" {
+		stats["last_error"] = p.lastError
+	}
+	return stats
+}
@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/metrics"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/diagnostics"
+)
+
+// logger is used by the admin server
+var logger = logging.For("admin")
+
+// Source is the broker connection change events are consumed from, e.g. *consumer.KafkaManager
+// or *rabbitmq.Transport
+type Source interface {
+	HealthCheck(ctx context.Context) error
+	GetStats() map[string]interface{}
+}
+
+// CheckFunc reports whether a dependency is usable, e.g. by pinging it
+type CheckFunc func(ctx context.Context) error
+
+// StatsFunc returns a JSON serializable snapshot included in /stats under its registered name
+type StatsFunc func() any
+
+// Server is the engine's admin HTTP server exposing health, readiness and stats endpoints, and
+// optionally the diagnostics endpoints
+type Server struct {
+	name   string
+	source Source
+	server *http.Server
+	mux    *http.ServeMux
+
+	mu     sync.RWMutex
+	stats  map[string]StatsFunc
+	checks map[string]CheckFunc
+}
+
+// NewServer creates an admin server listening on addr (e.g. ":8090") for the broker connection
+// source, reported under name (e.g. "kafka")
+//
+// Endpoints:
+//   - /healthz: 200 while the process is running
+//   - /readyz: 200 when source.HealthCheck (for Kafka, the broker is reachable and the CDC heartbeat is fresh) and every registered CheckFunc succeed, 503 otherwise, with the latency of each check
+//   - /stats: source.GetStats under name plus every registered StatsFunc
+//   - /metrics: the counters of package metrics, e.g. parse failures, in the Prometheus text format
+//   - /debug/pprof/ and /debug/runtime: profiles and runtime metrics, once EnableDiagnostics is called
+func NewServer(addr, name string, source Source) *Server {
+	s := &Server{
+		name:   name,
+		source: source,
+		mux:    http.NewServeMux(),
+		stats:  make(map[string]StatsFunc),
+		checks: make(map[string]CheckFunc),
+	}
+
+	s.mux.HandleFunc("GET /healthz", s.healthz)
+	s.mux.HandleFunc("GET /readyz", s.readyz)
+	s.mux.HandleFunc("GET /stats", s.statsHandler)
+	s.mux.Handle("GET /metrics", metrics.Handler())
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// EnableDiagnostics serves the pprof and runtime metrics endpoints under /debug/ to requests
+// presenting the bearer token returned by token (see diagnostics.Handler). It must be called
+// before Start.
+func (s *Server) EnableDiagnostics(token func() string) {
+	s.mux.Handle("/debug/", diagnostics.Handler(token))
+}
+
+// RegisterStats adds fn to /stats under name, e.g. watcher states or the address registry size
+func (s *Server) RegisterStats(name string, fn StatsFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats[name] = fn
+}
+
+// AddReadinessCheck makes /readyz also run fn, reported under name, e.g. a database ping
+func (s *Server) AddReadinessCheck(name string, fn CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = fn
+}
+
+// Start binds the listen address and serves requests in the background
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+
+	logger.Info("Listening", "addr", ln.Addr().String())
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Server stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops the server, waiting for in-flight requests until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	checks := map[string]CheckFunc{s.name: s.source.HealthCheck}
+	s.mu.RLock()
+	for name, fn := range s.checks {
+		checks[name] = fn
+	}
+	s.mu.RUnlock()
+
+	status, code := "ready", http.StatusOK
+	results := make(map[string]any, len(checks))
+	for name, fn := range checks {
+		start := time.Now()
+		err := fn(ctx)
+		result := map[string]any{"latency_ms": float64(time.Since(start).Microseconds()) / 1000}
+		if err != nil {
+			result["error"] = err.Error()
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+		results[name] = result
+	}
+	writeJSON(w, code, map[string]any{"status": status, "checks": results})
+}
+
+func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := map[string]any{s.name: s.source.GetStats()}
+
+	s.mu.RLock()
+	for name, fn := range s.stats {
+		stats[name] = fn()
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("Error writing response", "error", err)
+	}
+}
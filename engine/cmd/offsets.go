@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/spf13/cobra"
+)
+
+var offsetsCmd = &cobra.Command{
+	Use:   "offsets",
+	Short: "Show the consumer group's committed offsets and lag",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		km, _, err := connect(cmd.Context(), cmd)
+		if err != nil {
+			return err
+		}
+		defer km.Close()
+
+		offsets, err := km.GroupOffsets(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return printJSON(offsets)
+	},
+}
+
+var offsetsResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Move the consumer group's offsets, the engine must be stopped first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+
+		var req consumer.OffsetResetRequest
+		var err error
+		req.To, _ = flags.GetString("to")
+		req.Topic, _ = flags.GetString("reset-topic")
+		req.Partitions, _ = flags.GetIntSlice("partitions")
+		req.DryRun, _ = flags.GetBool("dry-run")
+		if req.Timestamp, err = timeFlag(cmd, "timestamp"); err != nil {
+			return err
+		}
+		if !req.Timestamp.IsZero() && !flags.Changed("to") {
+			req.To = consumer.OffsetTimestamp
+		}
+
+		km, _, err := connect(cmd.Context(), cmd)
+		if err != nil {
+			return err
+		}
+		defer km.Close()
+
+		offsets, err := km.ResetOffsets(cmd.Context(), req)
+		if err != nil {
+			return err
+		}
+		if req.DryRun {
+			fmt.Println("Dry run, offsets were not committed")
+		}
+		return printJSON(offsets)
+	},
+}
+
+func init() {
+	flags := offsetsResetCmd.Flags()
+	flags.String("to", consumer.OffsetLatest, "new position: earliest, latest or timestamp")
+	flags.String("timestamp", "", "RFC3339 time to reset to, implies --to timestamp")
+	flags.String("reset-topic", "", "limit the reset to one subscribed topic")
+	flags.IntSlice("partitions", nil, "limit the reset to these partitions")
+	flags.Bool("dry-run", false, "print the new offsets without committing them")
+
+	offsetsCmd.AddCommand(offsetsResetCmd)
+}
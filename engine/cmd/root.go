@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/settings"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// logger is used by the engine commands
+var logger = logging.For("engine")
+
+var rootCmd = &cobra.Command{
+	Use:   "engine",
+	Short: "Watches blockchain addresses registered through Debezium CDC events",
+	Long: `engine consumes Debezium change events from Kafka and keeps the set of watched
+addresses in sync with the database.
+
+Settings are read from --config (a YAML or TOML file), then the environment (and
+../.env), then the flags below, each overriding the previous.`,
+	SilenceUsage: true,
+}
+
+// Execute runs the command selected by the command line arguments
+func Execute() error {
+	return rootCmd.Execute()
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.String("config", "", "YAML or TOML configuration file (CONFIG_FILE)")
+	flags.String("broker", "", "Kafka broker address (KAFKA_BROKER)")
+	flags.String("topic", "", "Debezium topic to consume (KAFKA_TOPIC)")
+	flags.StringSlice("topics", nil, "additional topics to consume (KAFKA_TOPICS)")
+	flags.String("topic-prefix", "", "consume every topic with this prefix (KAFKA_TOPIC_PREFIX)")
+	flags.String("topic-pattern", "", "consume every topic matching this regular expression (KAFKA_TOPIC_PATTERN)")
+	flags.String("serialization", "", "message value format: json, avro or protobuf (KAFKA_SERIALIZATION)")
+	flags.String("key-serialization", "", "message key format, defaults to --serialization (KAFKA_KEY_SERIALIZATION)")
+	flags.String("schema-registry-url", "", "Confluent Schema Registry URL (SCHEMA_REGISTRY_URL)")
+	flags.String("heartbeat-topic", "", "Debezium heartbeat topic (KAFKA_HEARTBEAT_TOPIC)")
+	flags.String("group-id", "", "Kafka consumer group shared by the engine replicas (KAFKA_GROUP_ID)")
+	flags.Int("workers", 0, "number of event handler goroutines (KAFKA_WORKERS)")
+	flags.Int("max-in-flight", 0, "maximum events queued or being handled (KAFKA_MAX_IN_FLIGHT)")
+	flags.Int("max-workers", 0, "scale handler goroutines with consumer lag up to this many (KAFKA_MAX_WORKERS)")
+
+	rootCmd.AddCommand(runCmd, validateConfigCmd, replayCmd, simulateCmd, offsetsCmd, statusCmd)
+}
+
+// flagSettings maps CLI flags to the settings they override
+var flagSettings = map[string]string{
+	"broker":              "kafka.broker",
+	"topic":               "kafka.topic",
+	"topics":              "kafka.topics",
+	"topic-prefix":        "kafka.topic_prefix",
+	"topic-pattern":       "kafka.topic_pattern",
+	"serialization":       "kafka.serialization",
+	"key-serialization":   "kafka.key_serialization",
+	"schema-registry-url": "kafka.schema_registry_url",
+	"heartbeat-topic":     "kafka.heartbeat_topic",
+	"group-id":            "kafka.group_id",
+	"workers":             "kafka.workers",
+	"max-in-flight":       "kafka.max_in_flight",
+	"max-workers":         "kafka.max_workers",
+	"admin-addr":          "engine.admin_addr",
+	"shutdown-timeout":    "engine.shutdown_timeout",
+}
+
+// settingsOptions returns where the settings are read from, letting the flags of cmd override them
+func settingsOptions(cmd *cobra.Command) settings.Options {
+	configFile, _ := cmd.Flags().GetString("config")
+
+	flags := make(map[string]*pflag.Flag)
+	for flag, name := range flagSettings {
+		if f := cmd.Flags().Lookup(flag); f != nil {
+			flags[name] = f
+		}
+	}
+	return config.Options(configFile, flags)
+}
+
+// loadSettings reads and validates the settings, letting the flags of cmd override them
+func loadSettings(cmd *cobra.Command) (*settings.Settings, error) {
+	s, err := settings.Load(settingsOptions(cmd))
+	if err == nil {
+		err = s.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := logging.Setup(config.LoggingConfig(s)); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return s, nil
+}
+
+// loadConfig reads the consumer configuration, letting the flags of cmd override it
+func loadConfig(cmd *cobra.Command) (*consumer.Config, error) {
+	s, err := loadSettings(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return config.KafkaConfig(s), nil
+}
+
+// connect loads and validates the configuration and connects to Kafka, giving up when ctx is done
+func connect(ctx context.Context, cmd *cobra.Command) (*consumer.KafkaManager, *consumer.Config, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	km, err := consumer.NewKafkaManagerContext(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return km, cfg, nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
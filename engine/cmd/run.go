@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/activity"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/admin"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/config"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/notifier"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/outbox"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/quarantine"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/rules"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transactions"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	kafkatransport "github.com/ahsansaif47/blockchain-address-watcher/engine/transport/kafka"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport/pubsub"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport/rabbitmq"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport/sqs"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher/bitcoin"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher/ethereum"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcherstatus"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watchlist"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/dbstats"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/settings"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Consume change events until interrupted",
+	Long: `run consumes change events from Kafka, or from another broker selected with
+ENGINE_TRANSPORT (rabbitmq, sqs, pubsub), until SIGINT or SIGTERM is received. On
+shutdown the consumer stops fetching, events already fetched are handled and committed,
+and then every component is stopped, the chain watchers first so the notifications and
+transactions they matched are still delivered and written, all within --shutdown-timeout.
+A second signal exits immediately.
+
+SIGHUP reloads the log level, alert thresholds, RPC endpoints and notification
+credentials from the configuration file and environment without a restart.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		s, err := loadSettings(cmd)
+		if err != nil {
+			return err
+		}
+		shutdownTimeout := s.Engine.ShutdownTimeout
+
+		reloader := settings.NewReloader(settingsOptions(cmd), s)
+		reloader.OnChange(func(s *settings.Settings) {
+			if err := logging.Setup(config.LoggingConfig(s)); err != nil {
+				logger.Error("Failed to apply reloaded log settings", "error", err)
+			}
+		})
+		go reloader.WatchSignals(ctx)
+		go reloader.Refresh(ctx, s.Secrets.RefreshInterval)
+
+		handlerMetrics := consumer.NewHandlerMetrics()
+		// Events no registered handler is interested in are logged
+		consumer.DefaultRouter.Fallback(logEvent)
+
+		// Addresses to watch, kept in step with the api-server's addresses table
+		watched := watchlist.New()
+		watched.Register(consumer.DefaultRouter)
+		// Each chain's watched addresses are scanned by the watcher of that chain
+		dispatcher := watcher.NewDispatcher()
+		watched.Observe(dispatcher.Observe)
+		// Compiled conditions of the advanced rules, kept in step with the alert_rules table
+		evaluator := rules.New()
+		evaluator.Register(consumer.DefaultRouter)
+		middlewares := []consumer.Middleware{
+			consumer.Recover(),
+			consumer.Metrics(handlerMetrics),
+		}
+
+		var hooks shutdownHooks
+
+		// The outbox, the quarantine, the watcher status and the transaction history are tables of the
+		// api-server's database, which also holds the users' notification channels and bridges the
+		// live activity
+		var pool *pgxpool.Pool
+		if s.Outbox.Enabled || s.Quarantine.Enabled || s.WatcherStatus.Enabled || s.Notifications.Transfers.Enabled ||
+			s.LiveActivity.Enabled || s.TransactionHistory.Enabled {
+			poolConfig, err := config.DatabaseConfig(s)
+			if err != nil {
+				return err
+			}
+			// pgxpool does not count failed connection attempts, see the database stats
+			dbstats.Trace(poolConfig)
+			pool, err = pgxpool.NewWithConfig(ctx, poolConfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			hooks.add("database pool", func(ctx context.Context) error {
+				pool.Close()
+				return nil
+			})
+		}
+
+		// Samples of the events failing to decode or handle are kept for inspection, failures are
+		// counted on the admin server's /metrics either way
+		if s.Quarantine.Enabled {
+			consumer.SetQuarantine(quarantine.NewStore(pool, s.Quarantine.Retention), s.Quarantine.PerMinute)
+		}
+
+		// Users can check their addresses are picked up, see GET /addresses/{id}/status
+		var status *watcherstatus.Store
+		if s.WatcherStatus.Enabled {
+			status = watcherstatus.NewStore(pool)
+			watched.Observe(status.Observe)
+		}
+
+		// The node of an EVM network is followed over its WebSocket endpoint
+		if s.Chain.WSURL != "" && chains.Family(s.Chain.Network) == chains.EVM {
+			w, err := ethereum.New(ethereum.Config{
+				Chain:               s.Chain.Network,
+				URL:                 s.Chain.WSURL,
+				APIKey:              s.Chain.RPCAPIKey,
+				Confirmations:       s.Chain.Confirmations,
+				PendingTransactions: s.Chain.PendingTransactions,
+				Emit:                dispatcher.Emit,
+				Status:              status,
+			})
+			if err != nil {
+				return err
+			}
+			if err := dispatcher.Register(s.Chain.Network, w); err != nil {
+				return err
+			}
+		}
+		// Bitcoin Core is polled over its JSON-RPC endpoint
+		if s.Chain.RPCURL != "" && chains.Family(s.Chain.Network) == chains.UTXO {
+			w := bitcoin.New(bitcoin.Config{
+				URL:                 s.Chain.RPCURL,
+				APIKey:              s.Chain.RPCAPIKey,
+				Confirmations:       s.Chain.Confirmations,
+				PollInterval:        s.Chain.PollInterval,
+				PendingTransactions: s.Chain.PendingTransactions,
+				Emit:                dispatcher.Emit,
+				Status:              status,
+			})
+			if err := dispatcher.Register(s.Chain.Network, w); err != nil {
+				return err
+			}
+		}
+		dispatcher.OnMatch(logMatch)
+
+		// Matches are pushed to the api-server's /ws/activity clients, see package liveactivity
+		var publisher *activity.Publisher
+		if s.LiveActivity.Enabled {
+			publisher = activity.NewPublisher(watched, pool)
+			dispatcher.OnMatch(publisher.OnMatch)
+
+			publisherCtx, stopPublisher := context.WithCancel(context.WithoutCancel(ctx))
+			publisherDone := make(chan struct{})
+			go func() {
+				defer close(publisherDone)
+				publisher.Run(publisherCtx)
+			}()
+			hooks.add("live activity", func(ctx context.Context) error {
+				stopPublisher()
+				select {
+				case <-publisherDone:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}
+
+		// Mined transfers are stored for the api-server's GET /addresses/{id}/transactions. The writer
+		// stops after the chain watchers and writes what they matched until then.
+		var history *transactions.Writer
+		if s.TransactionHistory.Enabled {
+			history = transactions.NewWriter(pool, transactions.WriterConfig{})
+			dispatcher.OnMatch(history.OnMatch)
+
+			historyCtx, stopHistory := context.WithCancel(context.WithoutCancel(ctx))
+			historyDone := make(chan struct{})
+			go func() {
+				defer close(historyDone)
+				history.Run(historyCtx)
+			}()
+			hooks.add("transaction history", func(ctx context.Context) error {
+				stopHistory()
+				select {
+				case <-historyDone:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				return history.Flush(ctx)
+			})
+		}
+
+		// Users are notified of the confirmed transfers of their addresses over their channels. The
+		// notifier stops after the chain watchers, delivering what they matched until then.
+		var notifications *notifier.Dispatcher
+		if s.Notifications.Transfers.Enabled {
+			notifierConfig := func() notifier.Config { return config.NotifierConfig(reloader.Settings()) }
+			notifications = notifier.NewDispatcher(watched, notifier.NewStore(pool), notifierConfig)
+			notifications.Register(notifier.NewEmail(notifierConfig))
+			notifications.Register(notifier.NewSMS(notifierConfig))
+			notifications.Register(notifier.NewWebhooks(notifierConfig))
+			dispatcher.OnMatch(notifications.OnMatch)
+
+			notifierCtx, stopNotifier := context.WithCancel(context.WithoutCancel(ctx))
+			notifierDone := make(chan struct{})
+			go func() {
+				defer close(notifierDone)
+				notifications.Run(notifierCtx)
+			}()
+			hooks.add("notifier", func(ctx context.Context) error {
+				stopNotifier()
+				select {
+				case <-notifierDone:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				return notifications.Flush(ctx)
+			})
+		}
+
+		// Messages emitted by handlers are stored with the event's commit and relayed from the outbox
+		var relay *outbox.Relay
+		if s.Outbox.Enabled {
+			producer := kafkatransport.NewProducer(s.Kafka.Broker)
+			hooks.add("outbox producer", func(ctx context.Context) error { return producer.Close() })
+
+			store := outbox.NewStore(pool, s.Kafka.GroupID)
+			relay = outbox.NewRelay(store, producer, outbox.RelayConfig{
+				PollInterval: s.Outbox.PollInterval,
+				BatchSize:    s.Outbox.BatchSize,
+				Retention:    s.Outbox.Retention,
+			})
+			middlewares = append(middlewares, consumer.Transactional(store))
+
+			// The relay keeps publishing while the consumer drains and stops with the other components
+			relayCtx, stopRelay := context.WithCancel(context.WithoutCancel(ctx))
+			relayDone := make(chan struct{})
+			go func() {
+				defer close(relayDone)
+				relay.Run(relayCtx)
+			}()
+			hooks.add("outbox relay", func(ctx context.Context) error {
+				stopRelay()
+				select {
+				case <-relayDone:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}
+
+		// The chain watchers scan until shutdown, while the consumer drains they keep running
+		watchersCtx, stopWatchers := context.WithCancel(context.WithoutCancel(ctx))
+		watchersDone := make(chan struct{})
+		go func() {
+			defer close(watchersDone)
+			dispatcher.Run(watchersCtx)
+		}()
+		hooks.add("chain watchers", func(ctx context.Context) error {
+			stopWatchers()
+			select {
+			case <-watchersDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+		handler := consumer.Chain(consumer.DefaultRouter.Handle, middlewares...)
+		var source admin.Source
+		var consume func(ctx context.Context) error
+
+		// Serialization settings apply to every transport
+		readTransport := func(name string, t transport.Transport) {
+			cfg := config.KafkaConfig(s)
+			hooks.add(name, func(ctx context.Context) error { return t.Close() })
+			source = t
+			consume = func(ctx context.Context) error { return consumer.ReadTransport(ctx, cfg, t, handler) }
+		}
+
+		switch s.Engine.Transport {
+		case "rabbitmq":
+			t, err := rabbitmq.Dial(config.RabbitMQConfig(s))
+			if err != nil {
+				return err
+			}
+			readTransport("rabbitmq", t)
+
+		case "sqs":
+			t, err := sqs.New(ctx, config.SQSConfig(s))
+			if err != nil {
+				return err
+			}
+			readTransport("sqs", t)
+
+		case "pubsub":
+			t, err := pubsub.New(ctx, config.PubSubConfig(s))
+			if err != nil {
+				return err
+			}
+			readTransport("pubsub", t)
+
+		default:
+			km, cfg, err := connect(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			hooks.add("kafka manager", func(ctx context.Context) error { return km.Close() })
+			source = km
+			consume = func(ctx context.Context) error { return consumer.ReadWithRetry(ctx, km, handler, 5*time.Second) }
+
+			if cfg.HeartbeatTopic != "" {
+				go func() {
+					if err := consumer.ConsumeHeartbeats(ctx, km); err != nil && ctx.Err() == nil {
+						logger.Error("Heartbeat consumer stopped", "error", err)
+					}
+				}()
+			}
+		}
+
+		if adminAddr := s.Engine.AdminAddr; adminAddr != "" && adminAddr != "off" {
+			server := admin.NewServer(adminAddr, s.Engine.Transport, source)
+			server.RegisterStats("handlers", func() any { return handlerMetrics.Snapshot() })
+			server.RegisterStats("watchlist", func() any { return watched.GetStats() })
+			server.RegisterStats("watchers", func() any { return dispatcher.GetStats() })
+			server.RegisterStats("rules", func() any { return evaluator.GetStats() })
+			if relay != nil {
+				server.RegisterStats("outbox", func() any { return relay.GetStats() })
+			}
+			if publisher != nil {
+				server.RegisterStats("live_activity", func() any { return publisher.GetStats() })
+			}
+			if history != nil {
+				server.RegisterStats("transactions", func() any { return history.GetStats() })
+			}
+			if notifications != nil {
+				server.RegisterStats("notifier", func() any { return notifications.GetStats() })
+			}
+			if pool != nil {
+				server.RegisterStats("database", func() any { return dbstats.Snapshot(pool) })
+				server.AddReadinessCheck("database", func(ctx context.Context) error {
+					_, err := dbstats.Ping(ctx, pool)
+					return err
+				})
+			}
+			if s.Diagnostics.Enabled {
+				server.EnableDiagnostics(func() string { return reloader.Settings().Diagnostics.Token })
+			}
+			if err := server.Start(); err != nil {
+				hooks.run(context.Background())
+				return err
+			}
+			hooks.add("admin server", server.Shutdown)
+		}
+
+		logger.Info("Starting consumer", "transport", s.Engine.Transport)
+		done := make(chan error, 1)
+		go func() {
+			done <- consume(ctx)
+		}()
+
+		var runErr error
+		stopped := false
+		select {
+		case runErr = <-done:
+			stopped = true
+		case <-ctx.Done():
+		}
+
+		// Restore default signal handling so a second signal kills the process
+		stop()
+		logger.Info("Shutting down", "timeout", shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if !stopped {
+			// The consumer returns once in-flight events are handled and its reader is closed
+			select {
+			case runErr = <-done:
+			case <-shutdownCtx.Done():
+				runErr = fmt.Errorf("consumer did not stop within %v", shutdownTimeout)
+			}
+		}
+		if errors.Is(runErr, context.Canceled) {
+			runErr = nil
+		}
+
+		err = errors.Join(runErr, hooks.run(shutdownCtx))
+		if err == nil {
+			logger.Info("Shutdown complete")
+		}
+		return err
+	},
+}
+
+func init() {
+	flags := runCmd.Flags()
+	flags.String("admin-addr", ":8090", `admin server listen address, "off" disables it (ENGINE_ADMIN_ADDR)`)
+	flags.Duration("shutdown-timeout", 30*time.Second, "time allowed for a graceful shutdown (ENGINE_SHUTDOWN_TIMEOUT)")
+}
+
+// logEvent logs every change event, it is the engine's default handler
+func logEvent(event *consumer.Event) error {
+	logger.Info("Change event", "event", event)
+	return nil
+}
+
+// logMatch logs the transfers of watched addresses found by the chain watchers
+func logMatch(m watcher.Match) {
+	logger.Info("Watched address transfer", "chain", m.Chain, "address", m.Address, "direction", m.Direction,
+		"hash", m.Hash, "log_index", m.LogIndex, "block", m.BlockNumber, "value", m.Value, "token", m.Token, "status", m.Status)
+}
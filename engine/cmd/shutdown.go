@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// shutdownHook is a cleanup step run when the engine stops, e.g. flushing notifications,
+// persisting checkpoints or closing the KafkaManager
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// shutdownHooks runs cleanup steps in reverse registration order, so components stop before
+// the components they depend on
+type shutdownHooks struct {
+	hooks []shutdownHook
+}
+
+// add registers fn to run on shutdown
+func (s *shutdownHooks) add(name string, fn func(ctx context.Context) error) {
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// run executes every hook before ctx is done, returning the errors of those that failed.
+// Hooks left when the deadline passes are skipped.
+func (s *shutdownHooks) run(ctx context.Context) error {
+	var errs []error
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		hook := s.hooks[i]
+		if ctx.Err() != nil {
+			errs = append(errs, fmt.Errorf("%s: skipped, shutdown deadline exceeded", hook.name))
+			continue
+		}
+
+		start := time.Now()
+		if err := hook.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hook.name, err))
+			logger.Error("Failed to stop component", "hook", hook.name, "error", err)
+			continue
+		}
+		logger.Info("Stopped component", "hook", hook.name, "duration", time.Since(start).Round(time.Millisecond))
+	}
+
+	return errors.Join(errs...)
+}
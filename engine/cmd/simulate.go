@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/simulate"
+	"github.com/spf13/cobra"
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Show which rules recorded events would have fired, without sending alerts",
+	Long: `simulate runs a fixtures file through the pipeline of engine run without
+connecting to Kafka, the database or any notification channel. Change events of the
+addresses and alert_rules tables build the watched addresses and the advanced rules,
+and each recorded transaction is evaluated for the addresses watching its sender or
+recipient. Alerts are captured instead of delivered, and printed one JSON object per
+line, followed by a summary with the number of alerts per rule.
+
+Fixtures are NDJSON (--fixtures, "-" for stdin). Change events are in any format
+engine replay reads; the other lines hold a transaction or the known addresses:
+
+  {"known":{"exchanges":["0xexchange"]}}
+  {"transaction":{"chain":"ethereum","hash":"0xabc","from":"0xwatched","to":"0xexchange","value_usd":3200}}
+
+Use it to tune a rule's expression before enabling it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("fixtures")
+		if file == "" {
+			return fmt.Errorf("--fixtures is required")
+		}
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = os.Stdin
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		capture := &simulate.Capture{}
+		stats, err := simulate.New(capture).Run(cmd.Context(), cfg, r)
+
+		enc := json.NewEncoder(os.Stdout)
+		for _, alert := range capture.Alerts() {
+			if encErr := enc.Encode(alert); encErr != nil {
+				return encErr
+			}
+		}
+		if printErr := printJSON(stats); printErr != nil {
+			return printErr
+		}
+		return err
+	},
+}
+
+func init() {
+	simulateCmd.Flags().String("fixtures", "", `NDJSON fixtures to simulate, "-" reads stdin`)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check Kafka connectivity and print connection and consumer group stats",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		km, _, err := connect(cmd.Context(), cmd)
+		if err != nil {
+			return err
+		}
+		defer km.Close()
+
+		status := map[string]any{"healthy": true}
+		if err := km.HealthCheck(cmd.Context()); err != nil {
+			status["healthy"] = false
+			status["error"] = err.Error()
+		}
+		status["stats"] = km.GetStats()
+		if offsets, err := km.GroupOffsets(cmd.Context()); err == nil {
+			var lag int64
+			for _, o := range offsets {
+				lag += o.Lag
+			}
+			status["offsets"] = offsets
+			status["total_lag"] = lag
+		} else {
+			status["offsets_error"] = err.Error()
+		}
+
+		return printJSON(status)
+	},
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate-config",
+	Short: "Check the configuration without connecting to Kafka",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration:\n%w", err)
+		}
+
+		fmt.Println("Configuration is valid")
+
+		// Print the effective settings, durations in their human readable form
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		v := reflect.ValueOf(cfg).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(w, "  %s\t%v\n", v.Type().Field(i).Name, v.Field(i).Interface())
+		}
+		return w.Flush()
+	},
+}
@@ -1,25 +1,160 @@
 package config
 
 import (
-	"os"
-	"path/filepath"
-	"time"
+	"fmt"
+	"strconv"
 
 	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
-	"github.com/joho/godotenv"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/notifier"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport/pubsub"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport/rabbitmq"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport/sqs"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/settings"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/pflag"
 )
 
+// Load reads the engine settings from configFile (optional, YAML or TOML), the environment and
+// ../.env, with flags (keyed by setting name, e.g. "kafka.broker") taking precedence over all of them
+func Load(configFile string, flags map[string]*pflag.Flag) (*settings.Settings, error) {
+	return settings.Load(Options(configFile, flags))
+}
+
+// Options returns the settings options Load uses, e.g. to reload the same settings later
+func Options(configFile string, flags map[string]*pflag.Flag) settings.Options {
+	return settings.Options{
+		ConfigFile: configFile,
+		Flags:      flags,
+	}
+}
+
+// ConsumerConfig loads the Kafka consumer configuration from the environment and ../.env, applying
+// the documented defaults. Every malformed value is reported in the returned error, not just the first.
+// Required settings are checked separately by consumer.Config.Validate.
 func ConsumerConfig() (*consumer.Config, error) {
-	if err := godotenv.Load(filepath.Join("..", ".env")); err != nil {
+	s, err := Load("", nil)
+	if err != nil {
 		return nil, err
 	}
+	return KafkaConfig(s), nil
+}
 
+// KafkaConfig converts the Kafka settings into a consumer configuration
+func KafkaConfig(s *settings.Settings) *consumer.Config {
+	k := s.Kafka
 	return &consumer.Config{
-		Broker:          os.Getenv("KAFKA_BROKER"),
-		Topic:           os.Getenv("KAFKA_TOPIC"),
-		Partition:       0,
-		MaxRetries:      5,
-		RetryDelay:      time.Duration(2),
-		HealthCheckFreq: time.Duration(1),
-	}, nil
+		Broker:               k.Broker,
+		Topic:                k.Topic,
+		Topics:               k.Topics,
+		TopicPrefix:          k.TopicPrefix,
+		TopicPattern:         k.TopicPattern,
+		TopicRefreshInterval: k.TopicRefreshInterval,
+		MaxRetries:           k.MaxRetries,
+		RetryDelay:           k.RetryDelay,
+		MaxRetryDelay:        k.MaxRetryDelay,
+		HealthCheckFreq:      k.HealthCheckInterval,
+		Serialization:        k.Serialization,
+		KeySerialization:     k.KeySerialization,
+		SchemaRegistryURL:    k.SchemaRegistryURL,
+		Workers:              k.Workers,
+		MaxInFlight:          k.MaxInFlight,
+		HandlerAttempts:      k.HandlerAttempts,
+		MaxWorkers:           k.MaxWorkers,
+		LagPerWorker:         k.LagPerWorker,
+		ScaleInterval:        k.ScaleInterval,
+		HeartbeatTopic:       k.HeartbeatTopic,
+		HeartbeatTimeout:     k.HeartbeatTimeout,
+		GroupID:              k.GroupID,
+		GroupBalancer:        k.GroupBalancer,
+		RebalanceTimeout:     k.RebalanceTimeout,
+	}
+}
+
+// LoggingConfig converts the log settings into a logger configuration
+func LoggingConfig(s *settings.Settings) logging.Config {
+	return logging.Config{
+		Level:            s.Log.Level,
+		Format:           s.Log.Format,
+		SampleInitial:    s.Log.SampleInitial,
+		SampleThereafter: s.Log.SampleThereafter,
+	}
+}
+
+// DatabaseConfig converts the database settings into a connection pool configuration
+func DatabaseConfig(s *settings.Settings) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(s.Database.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	poolConfig.MaxConns = s.Database.MaxConns
+	poolConfig.MinConns = s.Database.MinConns
+	poolConfig.MaxConnLifetime = s.Database.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = s.Database.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = s.Database.HealthCheckPeriod
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(s.Database.QueryTimeout.Milliseconds(), 10)
+	return poolConfig, nil
+}
+
+// RabbitMQConfig converts the RabbitMQ settings into a transport configuration
+func RabbitMQConfig(s *settings.Settings) rabbitmq.Config {
+	r := s.RabbitMQ
+	return rabbitmq.Config{
+		URL:           r.URL,
+		Exchange:      r.Exchange,
+		Queue:         r.Queue,
+		BindingKeys:   r.BindingKeys,
+		Prefetch:      r.Prefetch,
+		DeliveryLimit: r.DeliveryLimit,
+	}
+}
+
+// SQSConfig converts the SQS settings into a transport configuration
+func SQSConfig(s *settings.Settings) sqs.Config {
+	q := s.SQS
+	return sqs.Config{
+		QueueURL:          q.QueueURL,
+		TopicARN:          q.TopicARN,
+		WaitTime:          q.WaitTime,
+		VisibilityTimeout: q.VisibilityTimeout,
+		MaxMessages:       q.MaxMessages,
+	}
+}
+
+// PubSubConfig converts the Pub/Sub settings into a transport configuration
+func PubSubConfig(s *settings.Settings) pubsub.Config {
+	p := s.PubSub
+	return pubsub.Config{
+		ProjectID:      p.ProjectID,
+		Subscription:   p.Subscription,
+		Topic:          p.Topic,
+		MaxOutstanding: p.MaxOutstanding,
+	}
+}
+
+// NotifierConfig converts the notification settings into the transfer notifier's configuration,
+// with the channels routed for each severity
+func NotifierConfig(s *settings.Settings) notifier.Config {
+	n := s.Notifications
+	return notifier.Config{
+		Severity: n.Transfers.Severity,
+		Routes: map[string][]string{
+			"info":     n.Routing.Info,
+			"warning":  n.Routing.Warning,
+			"critical": n.Routing.Critical,
+		},
+		SMTP: notifier.SMTPConfig{
+			Host:     n.SMTP.Host,
+			Port:     n.SMTP.Port,
+			Username: n.SMTP.Username,
+			Password: n.SMTP.Password,
+			From:     n.SMTP.From,
+		},
+		Twilio: notifier.TwilioConfig{
+			AccountSID: n.Twilio.AccountSID,
+			AuthToken:  n.Twilio.AuthToken,
+			FromNumber: n.Twilio.FromNumber,
+		},
+		WebhookTimeout: n.Webhooks.Timeout,
+	}
 }
@@ -0,0 +1,199 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// avroDeserializer decodes messages written by Confluent's AvroConverter
+type avroDeserializer struct {
+	registry *SchemaRegistryClient
+	mu       sync.RWMutex
+	codecs   map[int]*goavro.Codec
+}
+
+func newAvroDeserializer(registry *SchemaRegistryClient) *avroDeserializer {
+	return &avroDeserializer{
+		registry: registry,
+		codecs:   make(map[int]*goavro.Codec),
+	}
+}
+
+// Deserialize decodes the Avro record and re-encodes it as a schemaless Debezium JSON message
+func (d *avroDeserializer) Deserialize(data []byte) ([]byte, error) {
+	schemaID, body, err := parseConfluentHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := d.codec(schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	native, _, err := codec.NativeFromBinary(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode avro record with schema %d: %w", schemaID, err)
+	}
+
+	// Standard JSON codecs drop the {"type": value} union wrappers, so the
+	// textual form has the same shape as the JsonConverter payload
+	payload, err := codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert avro record to JSON: %w", err)
+	}
+
+	return json.Marshal(struct {
+		Payload json.RawMessage `json:"payload"`
+	}{Payload: payload})
+}
+
+// codec returns the cached codec for schemaID, building it from the registry on first use
+func (d *avroDeserializer) codec(schemaID int) (*goavro.Codec, error) {
+	d.mu.RLock()
+	codec, ok := d.codecs[schemaID]
+	d.mu.RUnlock()
+	if ok {
+		return codec, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schema, err := d.registry.GetSchemaByID(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+	if schema.SchemaType != "" && schema.SchemaType != "AVRO" {
+		return nil, fmt.Errorf("schema %d has type %s, expected AVRO", schemaID, schema.SchemaType)
+	}
+
+	spec := schema.Schema
+	if len(schema.References) > 0 {
+		if spec, err = d.inlineReferences(ctx, schema); err != nil {
+			return nil, fmt.Errorf("failed to resolve the references of avro schema %d: %w", schemaID, err)
+		}
+	}
+
+	codec, err = goavro.NewCodecForStandardJSONFull(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build avro codec for schema %d: %w", schemaID, err)
+	}
+
+	d.mu.Lock()
+	d.codecs[schemaID] = codec
+	d.mu.Unlock()
+
+	return codec, nil
+}
+
+// inlineReferences returns schema with the named types it references from other subjects defined
+// at their first use, since goavro cannot be handed them separately. Confluent names Avro
+// references by the full name of the type they define.
+func (d *avroDeserializer) inlineReferences(ctx context.Context, schema *RegisteredSchema) (string, error) {
+	pending := make(map[string]any)
+	if err := d.fetchReferences(ctx, schema.References, pending); err != nil {
+		return "", err
+	}
+
+	var root any
+	if err := json.Unmarshal([]byte(schema.Schema), &root); err != nil {
+		return "", fmt.Errorf("invalid schema: %w", err)
+	}
+	spec, err := json.Marshal(inlineAvroTypes(root, "", pending))
+	return string(spec), err
+}
+
+// fetchReferences fetches the schemas of refs and of their own references into pending, parsed
+// and keyed by the full name of the type they define
+func (d *avroDeserializer) fetchReferences(ctx context.Context, refs []SchemaReference, pending map[string]any) error {
+	for _, ref := range refs {
+		if _, ok := pending[ref.Name]; ok {
+			continue
+		}
+
+		schema, err := d.registry.GetSchemaBySubject(ctx, ref.Subject, ref.Version)
+		if err != nil {
+			return err
+		}
+		var parsed any
+		if err := json.Unmarshal([]byte(schema.Schema), &parsed); err != nil {
+			return fmt.Errorf("invalid schema of subject %s version %d: %w", ref.Subject, ref.Version, err)
+		}
+		pending[ref.Name] = parsed
+
+		if err := d.fetchReferences(ctx, schema.References, pending); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inlineAvroTypes replaces the first use of each type in pending, by name relative to namespace,
+// with its definition, removing it from pending. Types defined inline are removed too.
+func inlineAvroTypes(node any, namespace string, pending map[string]any) any {
+	switch n := node.(type) {
+	case string:
+		name := n
+		if !strings.Contains(name, ".") && namespace != "" {
+			name = namespace + "." + name
+		}
+		def, ok := pending[name]
+		if !ok {
+			return n
+		}
+		delete(pending, name)
+		return inlineAvroTypes(def, avroNamespace(name), pending)
+	case []any:
+		for i, branch := range n {
+			n[i] = inlineAvroTypes(branch, namespace, pending)
+		}
+		return n
+	case map[string]any:
+		switch n["type"] {
+		case "record", "error", "enum", "fixed":
+			name, _ := n["name"].(string)
+			if ns, ok := n["namespace"].(string); ok && !strings.Contains(name, ".") {
+				namespace = ns
+			}
+			if strings.Contains(name, ".") {
+				namespace = avroNamespace(name)
+			} else if namespace != "" {
+				name = namespace + "." + name
+			}
+			delete(pending, name)
+
+			fields, _ := n["fields"].([]any)
+			for _, field := range fields {
+				if f, ok := field.(map[string]any); ok {
+					f["type"] = inlineAvroTypes(f["type"], namespace, pending)
+				}
+			}
+		case "array":
+			n["items"] = inlineAvroTypes(n["items"], namespace, pending)
+		case "map":
+			n["values"] = inlineAvroTypes(n["values"], namespace, pending)
+		default:
+			// {"type": <schema>} wraps another schema, e.g. with a logicalType
+			n["type"] = inlineAvroTypes(n["type"], namespace, pending)
+		}
+		return n
+	default:
+		return node
+	}
+}
+
+// avroNamespace returns the namespace of a full Avro name, "" for names without one
+func avroNamespace(fullName string) string {
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		return fullName[:i]
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package consumer
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// backoff returns the delay before retry attempt (0 based): base * 2^attempt capped at max,
+// jittered over [d/2, d] so instances reconnecting after the same outage don't retry in lockstep
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	d := max
+	if attempt < 32 && base<<attempt > 0 && base<<attempt < max {
+		d = base << attempt
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// sleepContext waits for d, returning early with ctx.Err() if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,128 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// BatchConfig controls how ReadBatch groups events
+type BatchConfig struct {
+	// Size is the maximum number of events per batch
+	Size int
+	// Window is the maximum time to wait for a batch to fill, measured from its first message
+	Window time.Duration
+}
+
+// BatchHandler is a callback function that processes a batch of Debezium events at once,
+// e.g. to write them to Postgres with a single bulk insert
+type BatchHandler func(events []*Event) error
+
+// ReadBatch consumes messages like Read but delivers events in batches of up to config.Size,
+// flushing early once config.Window has passed since the first message of the batch.
+//
+// Offsets are committed only after the handler succeeds. If the handler fails, ReadBatch returns
+// its error without committing, so running it under a retry loop redelivers the whole batch. The
+// pending batch is flushed early when the consumer group rebalances or ctx is cancelled, so its
+// events are not delivered again to the partitions' next owner.
+//
+// Example usage:
+//
+//	err := consumer.ReadBatch(ctx, kafkaManager, func(events []*consumer.Event) error {
+//	    return repo.BulkUpsert(ctx, events)
+//	}, consumer.BatchConfig{Size: 500, Window: 2 * time.Second})
+func ReadBatch(ctx context.Context, km *KafkaManager, handler BatchHandler, config BatchConfig) error {
+	if km == nil {
+		return fmt.Errorf("KafkaManager cannot be nil")
+	}
+	if handler == nil {
+		return fmt.Errorf("batch handler cannot be nil")
+	}
+	if config.Size <= 0 {
+		config.Size = 100
+	}
+	if config.Window <= 0 {
+		config.Window = 1 * time.Second
+	}
+
+	decoder, err := newMessageDecoder(km.config, &km.metrics)
+	if err != nil {
+		return err
+	}
+
+	return km.subscribe(ctx, func(ctx context.Context, r *partitionReader) error {
+		var (
+			messages []kafka.Message
+			events   []*Event
+			deadline time.Time
+		)
+
+		flush := func() error {
+			if len(messages) == 0 {
+				return nil
+			}
+			if len(events) > 0 {
+				if err := handler(events); err != nil {
+					km.metrics.handlerErrors.Add(1)
+					for _, event := range events {
+						handlerFailed(event, err)
+					}
+					return fmt.Errorf("batch handler failed for %d events: %w", len(events), err)
+				}
+			}
+			// Skipped tombstones and unparsable messages are committed with the batch
+			if err := r.CommitMessages(messages...); err != nil {
+				return fmt.Errorf("failed to commit batch: %w", err)
+			}
+			messages, events = messages[:0], nil
+			return nil
+		}
+
+		for {
+			fetchCtx, cancel := ctx, context.CancelFunc(func() {})
+			if len(messages) > 0 {
+				fetchCtx, cancel = context.WithDeadline(ctx, deadline)
+			}
+			m, err := r.FetchMessage(fetchCtx)
+			cancel()
+
+			if err != nil {
+				if ctx.Err() != nil {
+					batchLogger.Info("Context cancelled, stopping reader")
+					if err := flush(); err != nil {
+						return err
+					}
+					return ctx.Err()
+				}
+				if errors.Is(err, context.DeadlineExceeded) {
+					if err := flush(); err != nil {
+						return err
+					}
+					continue
+				}
+				batchLogger.Error("Error fetching message", "error", err)
+				if err := sleepContext(ctx, 1*time.Second); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if len(messages) == 0 {
+				deadline = time.Now().Add(config.Window)
+			}
+			messages = append(messages, m)
+			if event := decoder.decode(m); event != nil {
+				events = append(events, event)
+			}
+
+			if len(events) >= config.Size || !time.Now().Before(deadline) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
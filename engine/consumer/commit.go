@@ -0,0 +1,83 @@
+package consumer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// commitTimeout bounds the requests to the consumer group coordinator, offset commits included,
+// which also run while a generation ends after its context is cancelled
+const commitTimeout = 10 * time.Second
+
+// topicPartition identifies a Kafka partition
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// commitTracker commits a message's offset only once it and every earlier message fetched from
+// its partition have been handled. Workers finish events out of order, so committing on completion
+// alone could skip an unhandled event after a crash.
+type commitTracker struct {
+	reader *partitionReader
+
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionProgress
+}
+
+// partitionProgress holds the fetched offsets of a partition that are not committed yet, in fetch order
+type partitionProgress struct {
+	pending []int64
+	done    map[int64]bool
+}
+
+func newCommitTracker(r *partitionReader) *commitTracker {
+	return &commitTracker{
+		reader:     r,
+		partitions: make(map[topicPartition]*partitionProgress),
+	}
+}
+
+// fetched records that m was fetched and is about to be handled
+func (t *commitTracker) fetched(m kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := topicPartition{m.Topic, m.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionProgress{done: make(map[int64]bool)}
+		t.partitions[key] = p
+	}
+	p.pending = append(p.pending, m.Offset)
+}
+
+// handled marks the message at offset as handled and commits the longest handled prefix of its
+// partition. Commits are serialized so the committed offset never moves backwards.
+func (t *commitTracker) handled(topic string, partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[topicPartition{topic, partition}]
+	if !ok {
+		return
+	}
+	p.done[offset] = true
+
+	committable := int64(-1)
+	for len(p.pending) > 0 && p.done[p.pending[0]] {
+		committable = p.pending[0]
+		delete(p.done, committable)
+		p.pending = p.pending[1:]
+	}
+	if committable < 0 {
+		return
+	}
+
+	m := kafka.Message{Topic: topic, Partition: partition, Offset: committable}
+	if err := t.reader.CommitMessages(m); err != nil {
+		logger.Error("Failed to commit offset", "topic", topic, "partition", partition, "offset", committable, "error", err)
+	}
+}
@@ -2,9 +2,11 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -12,13 +14,56 @@ import (
 
 // Config holds Kafka connection configuration
 type Config struct {
-	Broker    string
-	Topic     string
-	Partition int
+	Broker string
+	Topic  string
+	// Topics lists additional topics to consume (e.g. users, addresses, rules) alongside Topic
+	Topics []string
+	// TopicPrefix subscribes to every existing topic starting with the prefix (e.g. "sub-users-db.public.")
+	TopicPrefix string
+	// TopicPattern subscribes to every topic fully matching the regular expression (e.g. `sub-users-db\.public\..*`)
+	TopicPattern string
+	// TopicRefreshInterval is how often prefix and pattern subscriptions check for new topics
+	TopicRefreshInterval time.Duration
 	// Optional: TLS and SASL configuration can be added here
-	MaxRetries      int
-	RetryDelay      time.Duration
+	MaxRetries int
+	// RetryDelay is the base reconnection delay, doubled on every failed attempt
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the reconnection delay (default 30s)
+	MaxRetryDelay   time.Duration
 	HealthCheckFreq time.Duration
+	// Serialization selects the converter used by the Debezium connector: "json" (default), "avro" or "protobuf"
+	Serialization string
+	// KeySerialization is the converter used for message keys, defaults to Serialization
+	KeySerialization string
+	// SchemaRegistryURL is the Confluent Schema Registry endpoint, required for registry-based serializations
+	SchemaRegistryURL string
+	// Workers is the number of goroutines running the event handler, 1 (default) handles events sequentially
+	Workers int
+	// MaxInFlight bounds events queued or being handled; fetching pauses when it is reached (default 10 per worker)
+	MaxInFlight int
+	// HandlerAttempts bounds how often a failing event is handled before it is given up on:
+	// quarantined regardless of sampling, or logged when quarantine is off, and committed (default 5)
+	HandlerAttempts int
+	// MaxWorkers enables lag-aware scaling when above Workers: the pool grows up to MaxWorkers
+	// while the consumer group lags and shrinks back to Workers once it catches up (default 0, fixed)
+	MaxWorkers int
+	// LagPerWorker is the consumer lag, in messages, each worker is expected to absorb (default 1000)
+	LagPerWorker int64
+	// ScaleInterval is how often the consumer lag is checked, for scaling and GetStats (default 30s)
+	ScaleInterval time.Duration
+	// HeartbeatTopic is the Debezium heartbeat topic (e.g. "__debezium-heartbeat.<topic.prefix>"), optional
+	HeartbeatTopic string
+	// HeartbeatTimeout is how long without a heartbeat before the CDC link is reported dead
+	HeartbeatTimeout time.Duration
+	// GroupID is the consumer group the topics are read in; engines sharing it split the partitions
+	// between them (default DefaultGroupID)
+	GroupID string
+	// GroupBalancer assigns the partitions to the group's members, BalancerRange (default) or
+	// BalancerRoundRobin. Every member of the group must use the same.
+	GroupBalancer string
+	// RebalanceTimeout is how long the group waits for its members to rejoin on a rebalance, which
+	// includes handling and committing the events already fetched from revoked partitions (default 30s)
+	RebalanceTimeout time.Duration
 }
 
 // KafkaManager manages Kafka connections with reconnection logic, health checks, and observability
@@ -27,13 +72,121 @@ type KafkaManager struct {
 	config      *Config
 	mu          sync.RWMutex
 	isClosed    bool
-	retryCount  int
+	retryCount  int // Failed attempts since the last successful connection
+	reconnects  int
 	lastConnect time.Time
-	healthCheck chan struct{}
+	lastError   error
+	// ctx is cancelled by Close, interrupting reconnection backoff and the health check
+	ctx        context.Context
+	cancel     context.CancelFunc
+	metrics    readerMetrics
+	heartbeat  heartbeatState
+	group      groupState
+	topicRegex *regexp.Regexp
+}
+
+// readerMetrics counts message outcomes observed by Read
+type readerMetrics struct {
+	messages           atomic.Int64
+	tombstones         atomic.Int64
+	parseErrors        atomic.Int64
+	handlerErrors      atomic.Int64
+	handlerGaveUp      atomic.Int64
+	inFlight           atomic.Int64
+	backpressurePauses atomic.Int64
+	workers            atomic.Int64
+	desiredWorkers     atomic.Int64
+	lag                atomic.Int64
+	lagCheckedAt       atomic.Int64 // Unix nanoseconds, 0 until the lag was first checked
+	scaleEvents        atomic.Int64
+}
+
+// Validate checks the configuration without connecting, reporting every problem found
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Broker == "" {
+		errs = append(errs, fmt.Errorf("broker is required"))
+	}
+	if c.Topic == "" && len(c.Topics) == 0 && c.TopicPrefix == "" && c.TopicPattern == "" {
+		errs = append(errs, fmt.Errorf("one of topic, topics, topic prefix or topic pattern is required"))
+	}
+	if c.TopicPattern != "" {
+		if _, err := regexp.Compile(c.TopicPattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid topic pattern: %w", err))
+		}
+	}
+	serializations := []string{c.Serialization}
+	if c.KeySerialization != c.Serialization {
+		serializations = append(serializations, c.KeySerialization)
+	}
+	for _, s := range serializations {
+		switch s {
+		case "", SerializationJSON:
+		case SerializationAvro, SerializationProtobuf:
+			if c.SchemaRegistryURL == "" {
+				errs = append(errs, fmt.Errorf("schema registry URL is required for %s serialization", s))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unsupported serialization: %s", s))
+		}
+	}
+	switch c.GroupBalancer {
+	case "", BalancerRange, BalancerRoundRobin:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported group balancer: %s", c.GroupBalancer))
+	}
+	if c.Workers < 0 {
+		errs = append(errs, fmt.Errorf("workers cannot be negative"))
+	}
+	if c.MaxInFlight < 0 {
+		errs = append(errs, fmt.Errorf("max in flight cannot be negative"))
+	}
+	if c.HandlerAttempts < 0 {
+		errs = append(errs, fmt.Errorf("handler attempts cannot be negative"))
+	}
+	if c.MaxWorkers < 0 {
+		errs = append(errs, fmt.Errorf("max workers cannot be negative"))
+	}
+	if c.MaxWorkers > 0 && c.MaxWorkers < c.Workers {
+		errs = append(errs, fmt.Errorf("max workers %d is below workers %d", c.MaxWorkers, c.Workers))
+	}
+	if c.LagPerWorker < 0 {
+		errs = append(errs, fmt.Errorf("lag per worker cannot be negative"))
+	}
+	if c.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("max retries cannot be negative"))
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"retry delay", c.RetryDelay},
+		{"max retry delay", c.MaxRetryDelay},
+		{"health check interval", c.HealthCheckFreq},
+		{"heartbeat timeout", c.HeartbeatTimeout},
+		{"topic refresh interval", c.TopicRefreshInterval},
+		{"scale interval", c.ScaleInterval},
+		{"rebalance timeout", c.RebalanceTimeout},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s cannot be negative", d.name))
+		}
+	}
+	if c.MaxRetryDelay > 0 && c.RetryDelay > c.MaxRetryDelay {
+		errs = append(errs, fmt.Errorf("retry delay %v exceeds max retry delay %v", c.RetryDelay, c.MaxRetryDelay))
+	}
+
+	return errors.Join(errs...)
 }
 
 // NewKafkaManager creates a new Kafka connection manager with the given configuration
 func NewKafkaManager(config *Config) (*KafkaManager, error) {
+	return NewKafkaManagerContext(context.Background(), config)
+}
+
+// NewKafkaManagerContext is like NewKafkaManager, but gives up on the initial connection when ctx is done
+func NewKafkaManagerContext(ctx context.Context, config *Config) (*KafkaManager, error) {
 	if config == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
@@ -45,17 +198,75 @@ func NewKafkaManager(config *Config) (*KafkaManager, error) {
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 1 * time.Second
 	}
+	if config.MaxRetryDelay == 0 {
+		config.MaxRetryDelay = 30 * time.Second
+	}
 	if config.HealthCheckFreq == 0 {
 		config.HealthCheckFreq = 30 * time.Second
 	}
+	if config.Serialization == "" {
+		config.Serialization = SerializationJSON
+	}
+	if config.KeySerialization == "" {
+		config.KeySerialization = config.Serialization
+	}
+	if config.HeartbeatTimeout == 0 {
+		config.HeartbeatTimeout = 1 * time.Minute
+	}
+	if config.Workers == 0 {
+		config.Workers = 1
+	}
+	if config.MaxWorkers < config.Workers {
+		config.MaxWorkers = config.Workers
+	}
+	if config.LagPerWorker == 0 {
+		config.LagPerWorker = 1000
+	}
+	if config.ScaleInterval == 0 {
+		config.ScaleInterval = 30 * time.Second
+	}
+	if config.MaxInFlight == 0 {
+		config.MaxInFlight = 10 * config.MaxWorkers
+	}
+	if config.HandlerAttempts == 0 {
+		config.HandlerAttempts = 5
+	}
+	if config.TopicRefreshInterval == 0 {
+		config.TopicRefreshInterval = 1 * time.Minute
+	}
+	if config.GroupID == "" {
+		config.GroupID = DefaultGroupID
+	}
+	if config.GroupBalancer == "" {
+		config.GroupBalancer = BalancerRange
+	}
+	if config.RebalanceTimeout == 0 {
+		config.RebalanceTimeout = 30 * time.Second
+	}
 
 	km := &KafkaManager{
-		config:      config,
-		isClosed:    false,
-		healthCheck: make(chan struct{}),
+		config:   config,
+		isClosed: false,
+	}
+	km.ctx, km.cancel = context.WithCancel(context.Background())
+
+	if config.TopicPattern != "" {
+		// Kafka pattern subscriptions match the whole topic name
+		re, err := regexp.Compile("^(?:" + config.TopicPattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid topic pattern: %w", err)
+		}
+		km.topicRegex = re
 	}
 
-	if err := km.connectWithRetry(); err != nil {
+	// Close also interrupts the initial connection, although km is not returned until it succeeds
+	connectCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(km.ctx, cancel)
+	err := km.connectWithRetry(connectCtx)
+	stop()
+	cancel()
+	if err != nil {
+		km.cancel()
 		return nil, err
 	}
 
@@ -65,45 +276,69 @@ func NewKafkaManager(config *Config) (*KafkaManager, error) {
 	return km, nil
 }
 
-// connect establishes a new Kafka connection
-func (km *KafkaManager) connect() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// connect establishes a new Kafka connection. It is only used for metadata and health checks, the
+// partitions are read by the consumer group's readers.
+func (km *KafkaManager) connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	conn, err := kafka.DialLeader(ctx, "tcp", km.config.Broker, km.config.Topic, km.config.Partition)
+	conn, err := kafka.DialContext(ctx, "tcp", km.config.Broker)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Kafka: %w", err)
 	}
 
 	km.mu.Lock()
+	if km.isClosed {
+		km.mu.Unlock()
+		conn.Close()
+		return fmt.Errorf("connection manager is closed")
+	}
+	if km.conn != nil {
+		km.conn.Close()
+		km.reconnects++
+	}
 	km.conn = conn
 	km.lastConnect = time.Now()
+	km.retryCount = 0
+	km.lastError = nil
 	km.mu.Unlock()
 
-	log.Printf("[KafkaManager] Connected to %s, topic: %s, partition: %d", 
-		km.config.Broker, km.config.Topic, km.config.Partition)
-	
+	managerLogger.Info("Connected", "broker", km.config.Broker, "group", km.config.GroupID)
+
 	return nil
 }
 
-// connectWithRetry attempts to connect with exponential backoff
-func (km *KafkaManager) connectWithRetry() error {
+// connectWithRetry attempts to connect with jittered exponential backoff, giving up after
+// MaxRetries attempts or when ctx is done
+func (km *KafkaManager) connectWithRetry(ctx context.Context) error {
 	var lastErr error
-	
+
 	for i := 0; i < km.config.MaxRetries; i++ {
-		if err := km.connect(); err != nil {
-			lastErr = err
-			km.retryCount++
-			
-			// Exponential backoff: delay * 2^attempt
-			backoff := km.config.RetryDelay * time.Duration(1<<uint(i))
-			log.Printf("[KafkaManager] Connection attempt %d/%d failed: %v, retrying in %v", 
-				i+1, km.config.MaxRetries, err, backoff)
-			
-			time.Sleep(backoff)
-			continue
+		err := km.connect(ctx)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		km.mu.Lock()
+		km.retryCount++
+		km.lastError = err
+		km.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if i == km.config.MaxRetries-1 {
+			break
+		}
+
+		delay := backoff(km.config.RetryDelay, km.config.MaxRetryDelay, i)
+		managerLogger.Warn("Connection attempt failed, retrying",
+			"attempt", i+1, "max_attempts", km.config.MaxRetries, "retry_in", delay.Round(time.Millisecond), "error", err)
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
 		}
-		return nil
 	}
 
 	return fmt.Errorf("failed to connect after %d attempts: %w", km.config.MaxRetries, lastErr)
@@ -124,11 +359,11 @@ func (km *KafkaManager) GetConnection() (*kafka.Conn, error) {
 		if km.isConnectionAlive() {
 			return conn, nil
 		}
-		log.Printf("[KafkaManager] Connection appears dead, attempting reconnection")
+		managerLogger.Warn("Connection appears dead, attempting reconnection")
 	}
 
 	// Reconnection attempt
-	if err := km.connectWithRetry(); err != nil {
+	if err := km.connectWithRetry(km.ctx); err != nil {
 		return nil, fmt.Errorf("failed to reconnect to Kafka: %w", err)
 	}
 
@@ -164,13 +399,13 @@ func (km *KafkaManager) Close() error {
 	}
 
 	km.isClosed = true
-	close(km.healthCheck)
-	
+	km.cancel()
+
 	if km.conn != nil {
-		log.Printf("[KafkaManager] Closing connection to %s", km.config.Broker)
+		managerLogger.Info("Closing connection", "broker", km.config.Broker)
 		return km.conn.Close()
 	}
-	
+
 	return nil
 }
 
@@ -187,6 +422,10 @@ func (km *KafkaManager) HealthCheck(ctx context.Context) error {
 		return fmt.Errorf("connection is not alive")
 	}
 
+	if err := km.checkHeartbeat(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -196,23 +435,64 @@ func (km *KafkaManager) GetStats() map[string]interface{} {
 	defer km.mu.RUnlock()
 
 	stats := map[string]interface{}{
-		"broker":        km.config.Broker,
-		"topic":         km.config.Topic,
-		"partition":     km.config.Partition,
-		"is_closed":     km.isClosed,
-		"retry_count":   km.retryCount,
-		"last_connect":  km.lastConnect,
-		"is_connected":  km.conn != nil,
+		"broker":              km.config.Broker,
+		"topic":               km.config.Topic,
+		"topics":              km.config.Topics,
+		"topic_prefix":        km.config.TopicPrefix,
+		"topic_pattern":       km.config.TopicPattern,
+		"group_id":            km.config.GroupID,
+		"is_closed":           km.isClosed,
+		"retry_count":         km.retryCount,
+		"reconnects":          km.reconnects,
+		"last_connect":        km.lastConnect,
+		"is_connected":        km.conn != nil,
+		"messages_read":       km.metrics.messages.Load(),
+		"tombstones":          km.metrics.tombstones.Load(),
+		"parse_errors":        km.metrics.parseErrors.Load(),
+		"handler_errors":      km.metrics.handlerErrors.Load(),
+		"handler_gave_up":     km.metrics.handlerGaveUp.Load(),
+		"workers":             km.metrics.workers.Load(),
+		"in_flight":           km.metrics.inFlight.Load(),
+		"max_in_flight":       km.config.MaxInFlight,
+		"backpressure_pauses": km.metrics.backpressurePauses.Load(),
+	}
+
+	if checked := km.metrics.lagCheckedAt.Load(); checked != 0 {
+		stats["consumer_lag"] = km.metrics.lag.Load()
+		stats["lag_checked_at"] = time.Unix(0, checked)
+	}
+
+	km.group.stats(stats)
+
+	if km.scaling() {
+		stats["min_workers"] = km.config.Workers
+		stats["max_workers"] = km.config.MaxWorkers
+		stats["desired_workers"] = km.metrics.desiredWorkers.Load()
+		stats["scale_events"] = km.metrics.scaleEvents.Load()
+	}
+
+	if km.lastError != nil {
+		stats["last_error"] = km.lastError.Error()
 	}
 
 	if !km.lastConnect.IsZero() {
 		stats["uptime_seconds"] = time.Since(km.lastConnect).Seconds()
 	}
 
+	if km.config.HeartbeatTopic != "" {
+		lastHeartbeat := km.heartbeat.last()
+		stats["heartbeat_topic"] = km.config.HeartbeatTopic
+		stats["last_heartbeat"] = lastHeartbeat
+		stats["cdc_alive"] = km.checkHeartbeat() == nil
+		if !lastHeartbeat.IsZero() {
+			stats["heartbeat_age_seconds"] = time.Since(lastHeartbeat).Seconds()
+		}
+	}
+
 	return stats
 }
 
-// runHealthCheck periodically checks the connection health
+// runHealthCheck periodically checks the connection health until the manager is closed
 func (km *KafkaManager) runHealthCheck() {
 	ticker := time.NewTicker(km.config.HealthCheckFreq)
 	defer ticker.Stop()
@@ -223,11 +503,11 @@ func (km *KafkaManager) runHealthCheck() {
 			if km.isConnectionAlive() {
 				continue
 			}
-			log.Printf("[KafkaManager] Health check failed, attempting reconnection")
-			if err := km.connectWithRetry(); err != nil {
-				log.Printf("[KafkaManager] Auto-reconnection failed: %v", err)
+			managerLogger.Warn("Health check failed, attempting reconnection")
+			if err := km.connectWithRetry(km.ctx); err != nil && km.ctx.Err() == nil {
+				managerLogger.Error("Auto-reconnection failed", "error", err)
 			}
-		case <-km.healthCheck:
+		case <-km.ctx.Done():
 			return
 		}
 	}
@@ -237,15 +517,14 @@ func (km *KafkaManager) runHealthCheck() {
 // This is kept for backward compatibility but returns error instead of panicking
 func Connect() (*KafkaManager, error) {
 	config := &Config{
-		Broker:    "localhost:9092",
-		Topic:     "topic",
-		Partition: 0,
+		Broker: "localhost:9092",
+		Topic:  "topic",
 	}
-	
+
 	km, err := NewKafkaManager(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kafka manager: %w", err)
 	}
-	
+
 	return km, nil
 }
@@ -0,0 +1,232 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	objects "github.com/ahsansaif47/blockchain-address-watcher/engine/models"
+)
+
+// Debezium and Kafka Connect semantic type names that need conversion from their wire representation
+const (
+	schemaTimestamp      = "io.debezium.time.Timestamp"
+	schemaMicroTimestamp = "io.debezium.time.MicroTimestamp"
+	schemaNanoTimestamp  = "io.debezium.time.NanoTimestamp"
+	schemaZonedTimestamp = "io.debezium.time.ZonedTimestamp"
+	schemaDate           = "io.debezium.time.Date"
+	schemaConnectTime    = "org.apache.kafka.connect.data.Timestamp"
+	schemaConnectDate    = "org.apache.kafka.connect.data.Date"
+	schemaDecimal        = "org.apache.kafka.connect.data.Decimal"
+)
+
+var (
+	tableTypesMu sync.RWMutex
+	tableTypes   = map[string]func() any{
+		"users":       func() any { return &objects.User{} },
+		"addresses":   func() any { return &objects.Address{} },
+		"alert_rules": func() any { return &objects.AlertRule{} },
+	}
+)
+
+// RegisterTable decodes rows of table into values returned by newRow (a pointer to a struct with
+// json tags) instead of map[string]any. The users, addresses and alert_rules tables are registered
+// as *objects.User, *objects.Address and *objects.AlertRule by default.
+//
+// Example usage:
+//
+//	consumer.RegisterTable("webhooks", func() any { return &Webhook{} })
+func RegisterTable(table string, newRow func() any) {
+	tableTypesMu.Lock()
+	defer tableTypesMu.Unlock()
+	tableTypes[table] = newRow
+}
+
+// decodeRow decodes a before/after row. Values are normalized using the row schema when the
+// message embeds one, then converted to the table's registered type or left as map[string]any.
+func decodeRow(table string, data json.RawMessage, fields []DebeziumSchemaField) (any, error) {
+	if isTombstone(data) {
+		return nil, nil
+	}
+
+	row, err := decodeStruct(data, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode row: %w", err)
+	}
+
+	tableTypesMu.RLock()
+	newRow, ok := tableTypes[table]
+	tableTypesMu.RUnlock()
+	if !ok {
+		return row, nil
+	}
+
+	typed := newRow()
+	normalized, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(normalized, typed); err != nil {
+		return nil, fmt.Errorf("failed to decode %s row: %w", table, err)
+	}
+
+	return typed, nil
+}
+
+// decodeStruct decodes a JSON object into a map, normalizing each value using its schema field
+func decodeStruct(data json.RawMessage, fields []DebeziumSchemaField) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]DebeziumSchemaField, len(fields))
+	for _, f := range fields {
+		schemas[f.Field] = f
+	}
+
+	for name, value := range row {
+		// Metadata added by ExtractNewRecordState is not part of the row
+		if strings.HasPrefix(name, "__") {
+			delete(row, name)
+			continue
+		}
+
+		converted, err := convertValue(value, schemas[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %s: %w", name, err)
+		}
+		row[name] = converted
+	}
+
+	return row, nil
+}
+
+// convertValue converts a single value according to its schema field, which may be empty
+func convertValue(value any, field DebeziumSchemaField) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case json.Number:
+		return convertNumber(v, field)
+	case string:
+		switch field.Name {
+		case schemaZonedTimestamp:
+			return time.Parse(time.RFC3339Nano, v)
+		case schemaDecimal:
+			return decodeDecimal(v, field.Parameters["scale"])
+		}
+		if field.Type == "bytes" {
+			return base64.StdEncoding.DecodeString(v)
+		}
+		return v, nil
+	case map[string]any:
+		nested := make(map[string]DebeziumSchemaField, len(field.Fields))
+		for _, f := range field.Fields {
+			nested[f.Field] = f
+		}
+		for name, inner := range v {
+			converted, err := convertValue(inner, nested[name])
+			if err != nil {
+				return nil, err
+			}
+			v[name] = converted
+		}
+		return v, nil
+	case []any:
+		for i, inner := range v {
+			converted, err := convertValue(inner, DebeziumSchemaField{})
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// convertNumber converts numeric values, expanding Debezium temporal types to time.Time
+func convertNumber(n json.Number, field DebeziumSchemaField) (any, error) {
+	switch field.Name {
+	case schemaTimestamp, schemaConnectTime:
+		ms, err := n.Int64()
+		return time.UnixMilli(ms).UTC(), err
+	case schemaMicroTimestamp:
+		us, err := n.Int64()
+		return time.UnixMicro(us).UTC(), err
+	case schemaNanoTimestamp:
+		ns, err := n.Int64()
+		return time.Unix(0, ns).UTC(), err
+	case schemaDate, schemaConnectDate:
+		days, err := n.Int64()
+		return time.Unix(days*24*60*60, 0).UTC(), err
+	}
+
+	switch field.Type {
+	case "float", "float32", "float64", "double":
+		return n.Float64()
+	}
+
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	return n.Float64()
+}
+
+// decodeDecimal converts a base64 big-endian two's complement unscaled value into a decimal string
+func decodeDecimal(encoded string, scaleParam string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	scale, err := strconv.Atoi(scaleParam)
+	if err != nil {
+		scale = 0
+	}
+
+	unscaled := new(big.Int).SetBytes(raw)
+	if len(raw) > 0 && raw[0]&0x80 != 0 {
+		// Negative values are stored in two's complement
+		unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(raw)*8)))
+	}
+
+	value := new(big.Rat).SetFrac(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	return value.FloatString(scale), nil
+}
+
+// rowFields returns the schema fields of the named struct field (e.g. "after") in an envelope schema
+func rowFields(schema *DebeziumSchema, name string) []DebeziumSchemaField {
+	if schema == nil {
+		return nil
+	}
+	for _, f := range schema.Fields {
+		if f.Field == name {
+			return f.Fields
+		}
+	}
+	return nil
+}
+
+// tableFromSchemaName derives the table from a Debezium schema name such as "server.public.users.Value"
+func tableFromSchemaName(name string) string {
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[len(parts)-1] {
+	case "Value", "Envelope", "Key":
+		return parts[len(parts)-2]
+	}
+	return ""
+}
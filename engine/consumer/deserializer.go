@@ -0,0 +1,62 @@
+package consumer
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Supported values for Config.Serialization
+const (
+	SerializationJSON     = "json"
+	SerializationAvro     = "avro"
+	SerializationProtobuf = "protobuf"
+)
+
+// confluentMagicByte prefixes every message produced by a Schema Registry aware converter
+const confluentMagicByte = 0x0
+
+// Deserializer converts a raw Kafka message value into Debezium JSON understood by parseDebeziumMessage
+type Deserializer interface {
+	Deserialize(data []byte) ([]byte, error)
+}
+
+// NewDeserializer returns the Deserializer matching config.Serialization
+func NewDeserializer(config *Config) (Deserializer, error) {
+	switch config.Serialization {
+	case "", SerializationJSON:
+		return jsonDeserializer{}, nil
+	case SerializationAvro:
+		registry, err := NewSchemaRegistryClient(config.SchemaRegistryURL)
+		if err != nil {
+			return nil, err
+		}
+		return newAvroDeserializer(registry), nil
+	case SerializationProtobuf:
+		registry, err := NewSchemaRegistryClient(config.SchemaRegistryURL)
+		if err != nil {
+			return nil, err
+		}
+		return newProtobufDeserializer(registry), nil
+	default:
+		return nil, fmt.Errorf("unsupported serialization: %s", config.Serialization)
+	}
+}
+
+// jsonDeserializer passes messages produced by the JsonConverter through unchanged
+type jsonDeserializer struct{}
+
+func (jsonDeserializer) Deserialize(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// parseConfluentHeader splits a Confluent wire-format message into its schema ID and body
+func parseConfluentHeader(data []byte) (int, []byte, error) {
+	if len(data) < 5 {
+		return 0, nil, fmt.Errorf("message too short for schema registry framing: %d bytes", len(data))
+	}
+	if data[0] != confluentMagicByte {
+		return 0, nil, fmt.Errorf("unknown magic byte: %d", data[0])
+	}
+
+	return int(binary.BigEndian.Uint32(data[1:5])), data[5:], nil
+}
@@ -0,0 +1,203 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/metrics"
+	"github.com/segmentio/kafka-go"
+)
+
+// Stages a message fails at, see QuarantinedMessage
+const (
+	StageDeserialize      = "deserialize"
+	StageParse            = "parse"
+	StageUnknownOperation = "unknown_operation"
+	StageHandler          = "handler"
+)
+
+// quarantineTimeout bounds storing a sampled message, which holds up the message's reader
+const quarantineTimeout = 5 * time.Second
+
+// Counters of the messages skipped or failed by every reader, served by the admin server's
+// /metrics endpoint
+var (
+	parseFailuresTotal = metrics.NewCounterVec("engine_parse_failures_total",
+		"Messages that failed to deserialize or to parse as a Debezium event, by topic and stage",
+		"topic", "stage")
+	unknownOperationsTotal = metrics.NewCounterVec("engine_unknown_operations_total",
+		"Debezium messages of an operation other than c, u, d and r, by topic and operation",
+		"topic", "operation")
+	tombstonesTotal = metrics.NewCounterVec("engine_tombstones_total",
+		"Tombstone messages skipped, by topic",
+		"topic")
+	handlerErrorsTotal = metrics.NewCounterVec("engine_handler_errors_total",
+		"Events the handler returned an error for, by topic, table and operation",
+		"topic", "table", "operation")
+	handlerGaveUpTotal = metrics.NewCounterVec("engine_handler_gave_up_total",
+		"Events still failing after HandlerAttempts attempts, committed without being handled, by topic, table and operation",
+		"topic", "table", "operation")
+)
+
+// QuarantinedMessage is a sample of a message that failed at Stage, with the error it failed with
+type QuarantinedMessage struct {
+	Stage     string
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Error     string
+}
+
+// Quarantine stores the sampled failing messages for later inspection, e.g. *quarantine.Store
+type Quarantine interface {
+	Quarantine(ctx context.Context, m QuarantinedMessage) error
+}
+
+// quarantiner samples failing messages into a Quarantine: per topic and stage, the first perMinute
+// messages of each minute are stored and the rest only counted
+type quarantiner struct {
+	store     Quarantine
+	perMinute int
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[[2]string]int
+}
+
+var activeQuarantine atomic.Pointer[quarantiner]
+
+// SetQuarantine samples messages failing to decode or to be handled by any reader into q, at most
+// perMinute per topic and stage. A nil q or a perMinute of 0 stops sampling. Failures are counted
+// in the /metrics counters either way.
+func SetQuarantine(q Quarantine, perMinute int) {
+	if q == nil || perMinute <= 0 {
+		activeQuarantine.Store(nil)
+		return
+	}
+	activeQuarantine.Store(&quarantiner{store: q, perMinute: perMinute, counts: make(map[[2]string]int)})
+}
+
+// allow reports whether a message of topic failing at stage at t is sampled
+func (q *quarantiner) allow(topic, stage string, t time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if t.Sub(q.window) >= time.Minute || t.Before(q.window) {
+		q.window = t
+		clear(q.counts)
+	}
+
+	key := [2]string{topic, stage}
+	q.counts[key]++
+	return q.counts[key] <= q.perMinute
+}
+
+// quarantine stores a sample of m, which failed at stage with err, when sampling is on. It reports
+// whether m was stored.
+func quarantine(stage string, m kafka.Message, err error) bool {
+	q := activeQuarantine.Load()
+	if q == nil || !q.allow(m.Topic, stage, time.Now()) {
+		return false
+	}
+	return q.put(stage, m, err)
+}
+
+// put stores m, which failed at stage with err, reporting whether it was stored
+func (q *quarantiner) put(stage string, m kafka.Message, err error) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), quarantineTimeout)
+	defer cancel()
+	sample := QuarantinedMessage{
+		Stage:     stage,
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Key:       m.Key,
+		Value:     m.Value,
+		Error:     err.Error(),
+	}
+	if err := q.store.Quarantine(ctx, sample); err != nil {
+		logger.Warn("Error quarantining message", messageAttrs(m), "stage", stage, "error", err)
+		return false
+	}
+	return true
+}
+
+// handlerFailed counts an event the handler returned err for and samples its message, reporting
+// whether it was quarantined
+func handlerFailed(event *Event, err error) bool {
+	handlerErrorsTotal.Inc(event.Topic, event.Source.Table, event.Operation)
+	return quarantine(StageHandler, event.message(), err)
+}
+
+// giveUp counts an event still failing with err after its last attempt and quarantines it
+// regardless of sampling, so it is not lost once committed. Without a quarantine its message is
+// logged instead.
+func giveUp(metrics *readerMetrics, event *Event, attempts int, err error) {
+	metrics.handlerGaveUp.Add(1)
+	handlerGaveUpTotal.Inc(event.Topic, event.Source.Table, event.Operation)
+
+	m := event.message()
+	if q := activeQuarantine.Load(); q != nil && q.put(StageHandler, m, err) {
+		logger.Error("Giving up on event, event quarantined", "event", event, "attempts", attempts, "error", err)
+		return
+	}
+	logger.Error("Giving up on event, skipping it", "event", event, "attempts", attempts, "error", err,
+		"key", string(m.Key), "value", string(m.Value))
+}
+
+// message returns the Kafka message event was parsed from
+func (e *Event) message() kafka.Message {
+	return kafka.Message{
+		Topic:     e.Topic,
+		Partition: e.Partition,
+		Offset:    e.Offset,
+		Key:       e.rawKey,
+		Value:     e.rawValue,
+	}
+}
+
+// handleUntilDone runs handler on event until it succeeds, the failing event is quarantined, or
+// attempts attempts failed, backing off up to 30s between attempts, and reports whether the event
+// is done with so its offset may be committed. After the last attempt the event is given up on
+// (see giveUp) rather than holding up its partition forever. Once ctx is done it returns false
+// after the current attempt, leaving the event to be redelivered; a failure then is not
+// quarantined, since it may be caused by the shutdown.
+func handleUntilDone(ctx context.Context, metrics *readerMetrics, attempts int, handler EventHandler, event *Event) bool {
+	for attempt := 1; ; attempt++ {
+		err := handler(event)
+		if err == nil {
+			return true
+		}
+		metrics.handlerErrors.Add(1)
+		if ctx.Err() != nil {
+			logger.Warn("Event handler failed while stopping, leaving the event to be redelivered", "event", event, "error", err)
+			return false
+		}
+		if handlerFailed(event, err) {
+			logger.Error("Error in event handler, event quarantined", "event", event, "error", err)
+			return true
+		}
+		if attempt >= attempts {
+			giveUp(metrics, event, attempt, err)
+			return true
+		}
+
+		delay := backoff(time.Second, 30*time.Second, attempt-1)
+		logger.Error("Error in event handler, retrying", "event", event, "attempt", attempt, "retry_in", delay.Round(time.Millisecond), "error", err)
+		if sleepContext(ctx, delay) != nil {
+			return false
+		}
+	}
+}
+
+// operationLabel bounds the values of the operation label, Debezium operations are one letter
+func operationLabel(op string) string {
+	if len(op) > 8 {
+		return "other"
+	}
+	return op
+}
@@ -0,0 +1,302 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultGroupID is the Kafka consumer group of engines without Config.GroupID
+const DefaultGroupID = "blockchain-address-watcher-group"
+
+// Supported values for Config.GroupBalancer
+const (
+	BalancerRange      = "range"
+	BalancerRoundRobin = "round_robin"
+)
+
+// Assignment is the set of partitions the consumer group assigned to this engine for one generation
+type Assignment struct {
+	GroupID    string
+	Generation int32
+	MemberID   string
+	// Partitions holds the assigned partition IDs by topic, topics without any are left out
+	Partitions map[string][]int
+}
+
+// Len returns the number of assigned partitions
+func (a Assignment) Len() int {
+	n := 0
+	for _, partitions := range a.Partitions {
+		n += len(partitions)
+	}
+	return n
+}
+
+func newAssignment(gen *kafka.Generation) Assignment {
+	a := Assignment{
+		GroupID:    gen.GroupID,
+		Generation: gen.ID,
+		MemberID:   gen.MemberID,
+		Partitions: make(map[string][]int),
+	}
+	for topic, partitions := range gen.Assignments {
+		for _, p := range partitions {
+			a.Partitions[topic] = append(a.Partitions[topic], p.ID)
+		}
+		sort.Ints(a.Partitions[topic])
+	}
+	return a
+}
+
+// groupState tracks the consumer group generation being read and the rebalance callbacks,
+// independent of km.mu since callbacks may call GetStats
+type groupState struct {
+	mu         sync.Mutex
+	current    *Assignment
+	rebalances int64
+	assigned   []func(Assignment)
+	revoked    []func(Assignment)
+}
+
+// OnAssigned registers fn to be called with the partitions of every generation of the consumer
+// group before they are read, e.g. to load state kept per partition
+func (km *KafkaManager) OnAssigned(fn func(Assignment)) {
+	km.group.mu.Lock()
+	defer km.group.mu.Unlock()
+	km.group.assigned = append(km.group.assigned, fn)
+}
+
+// OnRevoked registers fn to be called with the partitions of a generation once they are no longer
+// read, because the group is rebalancing or the reader stopped. By then the events fetched from them
+// are handled and their offsets committed, and the engine has not rejoined the group yet.
+func (km *KafkaManager) OnRevoked(fn func(Assignment)) {
+	km.group.mu.Lock()
+	defer km.group.mu.Unlock()
+	km.group.revoked = append(km.group.revoked, fn)
+}
+
+// assign records a as the generation being read and calls the OnAssigned callbacks
+func (g *groupState) assign(a Assignment) {
+	g.mu.Lock()
+	g.current = &a
+	g.rebalances++
+	callbacks := g.assigned
+	g.mu.Unlock()
+
+	logger.Info("Partitions assigned", "group", a.GroupID, "generation", a.Generation, "partitions", a.Partitions)
+	for _, fn := range callbacks {
+		fn(a)
+	}
+}
+
+// revoke clears the generation being read and calls the OnRevoked callbacks with it
+func (g *groupState) revoke() {
+	g.mu.Lock()
+	current := g.current
+	g.current = nil
+	callbacks := g.revoked
+	g.mu.Unlock()
+
+	if current == nil {
+		return
+	}
+	logger.Info("Partitions revoked", "group", current.GroupID, "generation", current.Generation, "partitions", current.Partitions)
+	for _, fn := range callbacks {
+		fn(*current)
+	}
+}
+
+// stats adds the generation being read and the number of generations joined to stats
+func (g *groupState) stats(stats map[string]interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats["rebalances"] = g.rebalances
+	if g.current != nil {
+		stats["generation"] = g.current.Generation
+		stats["member_id"] = g.current.MemberID
+		stats["assigned_partitions"] = g.current.Partitions
+	}
+}
+
+// groupBalancers returns the kafka-go balancer named by Config.GroupBalancer
+func groupBalancers(name string) []kafka.GroupBalancer {
+	if name == BalancerRoundRobin {
+		return []kafka.GroupBalancer{kafka.RoundRobinGroupBalancer{}}
+	}
+	return []kafka.GroupBalancer{kafka.RangeGroupBalancer{}}
+}
+
+// newConsumerGroup joins the consumer group on topics. The group coordinates with the other engines
+// reading them, and a rebalance starts whenever one joins or leaves or a topic gains partitions.
+func (km *KafkaManager) newConsumerGroup(topics []string) (*kafka.ConsumerGroup, error) {
+	return kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:                    km.config.GroupID,
+		Brokers:               []string{km.config.Broker},
+		Topics:                topics,
+		GroupBalancers:        groupBalancers(km.config.GroupBalancer),
+		WatchPartitionChanges: true,
+		RebalanceTimeout:      km.config.RebalanceTimeout,
+		StartOffset:           kafka.FirstOffset,
+		Timeout:               commitTimeout,
+	})
+}
+
+// consumeGenerations runs consume for every generation of group until ctx is done or consume fails
+func (km *KafkaManager) consumeGenerations(ctx context.Context, group *kafka.ConsumerGroup, consume func(ctx context.Context, r *partitionReader) error) error {
+	for {
+		gen, err := group.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The group keeps trying to join, backing off itself
+			logger.Warn("Failed to join consumer group", "group", km.config.GroupID, "error", err)
+			continue
+		}
+
+		if err := km.consumeGeneration(ctx, gen, consume); err != nil {
+			return err
+		}
+	}
+}
+
+// consumeGeneration runs consume with a reader of the partitions gen assigned to this engine, until
+// the generation ends or ctx is done. consume runs as part of the generation, so the group waits for
+// it to return, committing the offsets of the events it handled, before rejoining. It returns nil
+// when the generation ended.
+func (km *KafkaManager) consumeGeneration(ctx context.Context, gen *kafka.Generation, consume func(ctx context.Context, r *partitionReader) error) error {
+	km.group.assign(newAssignment(gen))
+
+	result := make(chan error, 1)
+	gen.Start(func(genCtx context.Context) {
+		readCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(genCtx, cancel)
+		defer stop()
+
+		r := newPartitionReader(readCtx, km, gen)
+		err := consume(readCtx, r)
+		r.close()
+		km.group.revoke()
+		result <- err
+	})
+
+	err := <-result
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.Canceled) {
+		logger.Info("Consumer group rebalancing", "group", km.config.GroupID, "generation", gen.ID)
+		return nil
+	}
+	return err
+}
+
+// partitionReader fetches the messages of the partitions a generation assigned to this engine, with
+// one reader per partition, and commits their offsets in the generation
+type partitionReader struct {
+	gen      *kafka.Generation
+	messages chan kafka.Message
+	readers  []*kafka.Reader
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newPartitionReader(ctx context.Context, km *KafkaManager, gen *kafka.Generation) *partitionReader {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &partitionReader{
+		gen:      gen,
+		messages: make(chan kafka.Message),
+		cancel:   cancel,
+	}
+
+	for topic, partitions := range gen.Assignments {
+		for _, p := range partitions {
+			pr := kafka.NewReader(kafka.ReaderConfig{
+				Brokers:   []string{km.config.Broker},
+				Topic:     topic,
+				Partition: p.ID,
+				MinBytes:  10e3, // 10KB
+				MaxBytes:  10e6, // 10MB
+			})
+			r.readers = append(r.readers, pr)
+			r.wg.Add(1)
+			go r.read(ctx, pr, p.Offset)
+		}
+	}
+
+	return r
+}
+
+// read fetches the messages of one partition from offset, the group's committed offset or the first
+// one, until ctx is done
+func (r *partitionReader) read(ctx context.Context, pr *kafka.Reader, offset int64) {
+	defer r.wg.Done()
+
+	config := pr.Config()
+	if err := pr.SetOffset(offset); err != nil {
+		logger.Error("Failed to seek partition", "topic", config.Topic, "partition", config.Partition, "offset", offset, "error", err)
+		return
+	}
+
+	for {
+		m, err := pr.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error("Error reading message", "topic", config.Topic, "partition", config.Partition, "error", err)
+			if err := sleepContext(ctx, 1*time.Second); err != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case r.messages <- m:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// FetchMessage returns the next message of the assigned partitions, in order within each partition.
+// With no partitions assigned it blocks until ctx is done.
+func (r *partitionReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.messages:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+// CommitMessages commits the offset following the last of msgs on each of their partitions
+func (r *partitionReader) CommitMessages(msgs ...kafka.Message) error {
+	offsets := make(map[string]map[int]int64)
+	for _, m := range msgs {
+		if offsets[m.Topic] == nil {
+			offsets[m.Topic] = make(map[int]int64)
+		}
+		if next := m.Offset + 1; next > offsets[m.Topic][m.Partition] {
+			offsets[m.Topic][m.Partition] = next
+		}
+	}
+	return r.gen.CommitOffsets(offsets)
+}
+
+// close stops fetching and closes the partitions' readers. Messages fetched but not returned by
+// FetchMessage are read again by the partition's next owner.
+func (r *partitionReader) close() {
+	r.cancel()
+	r.wg.Wait()
+	for _, pr := range r.readers {
+		pr.Close()
+	}
+}
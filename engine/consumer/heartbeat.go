@@ -0,0 +1,124 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// heartbeatState records the last Debezium heartbeat, independent of km.mu so stats can read it cheaply
+type heartbeatState struct {
+	lastUnixNano atomic.Int64
+}
+
+func (h *heartbeatState) record(t time.Time) {
+	h.lastUnixNano.Store(t.UnixNano())
+}
+
+func (h *heartbeatState) last() time.Time {
+	ns := h.lastUnixNano.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// checkHeartbeat reports whether the CDC link is alive based on the last heartbeat.
+// It is a no-op when no heartbeat topic is configured.
+func (km *KafkaManager) checkHeartbeat() error {
+	if km.config.HeartbeatTopic == "" {
+		return nil
+	}
+
+	last := km.heartbeat.last()
+	if last.IsZero() {
+		return fmt.Errorf("no Debezium heartbeat received on %s", km.config.HeartbeatTopic)
+	}
+	if age := time.Since(last); age > km.config.HeartbeatTimeout {
+		return fmt.Errorf("CDC link stale: last heartbeat at %s (%s ago)", last.Format(time.RFC3339), age.Truncate(time.Second))
+	}
+
+	return nil
+}
+
+// ConsumeHeartbeats reads the Debezium heartbeat topic until ctx is cancelled, recording
+// each heartbeat so HealthCheck and GetStats can tell "no changes" apart from "connector dead".
+// It only follows new heartbeats and does not join a consumer group.
+func ConsumeHeartbeats(ctx context.Context, km *KafkaManager) error {
+	if km == nil {
+		return fmt.Errorf("KafkaManager cannot be nil")
+	}
+	if km.config.HeartbeatTopic == "" {
+		return fmt.Errorf("heartbeat topic is not configured")
+	}
+
+	deserializer, err := NewDeserializer(km.config)
+	if err != nil {
+		return fmt.Errorf("failed to create deserializer: %w", err)
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  []string{km.config.Broker},
+		Topic:    km.config.HeartbeatTopic,
+		MaxBytes: 1e6, // 1MB
+	})
+	defer r.Close()
+
+	if err := r.SetOffset(kafka.LastOffset); err != nil {
+		return fmt.Errorf("failed to seek heartbeat topic: %w", err)
+	}
+
+	heartbeatLogger.Info("Watching heartbeat topic", "topic", km.config.HeartbeatTopic)
+
+	for {
+		m, err := r.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			heartbeatLogger.Error("Error reading heartbeat", "error", err)
+			if err := sleepContext(ctx, 1*time.Second); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ts, err := parseHeartbeat(deserializer, m.Value)
+		if err != nil {
+			heartbeatLogger.Warn("Error parsing heartbeat", "offset", m.Offset, "error", err)
+			// The message still proves the connector is producing
+			ts = m.Time
+		}
+
+		km.heartbeat.record(ts)
+	}
+}
+
+// parseHeartbeat extracts the heartbeat timestamp from a `{"ts_ms": ...}` payload
+func parseHeartbeat(deserializer Deserializer, data []byte) (time.Time, error) {
+	value, err := deserializer.Deserialize(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(value, &msg); err != nil {
+		return time.Time{}, fmt.Errorf("failed to unmarshal heartbeat: %w", err)
+	}
+
+	var payload struct {
+		TsMs int64 `json:"ts_ms"`
+	}
+	if err := json.Unmarshal(extractPayload(value, msg), &payload); err != nil {
+		return time.Time{}, fmt.Errorf("failed to unmarshal heartbeat payload: %w", err)
+	}
+	if payload.TsMs == 0 {
+		return time.Time{}, fmt.Errorf("heartbeat is missing ts_ms")
+	}
+
+	return time.UnixMilli(payload.TsMs), nil
+}
@@ -0,0 +1,73 @@
+package consumer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseKey parses a deserialized Debezium message key into column/value pairs, as Read does
+// for event.Key. Keyless tables produce a nil key.
+func ParseKey(data []byte) (map[string]any, error) {
+	return parseDebeziumKey(data)
+}
+
+// parseDebeziumKey parses a deserialized Debezium message key (the primary key struct) into
+// column/value pairs. Keyless tables produce a nil key.
+func parseDebeziumKey(data []byte) (map[string]any, error) {
+	if isTombstone(data) {
+		return nil, nil
+	}
+
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Debezium key: %w", err)
+	}
+
+	raw := extractPayload(data, msg)
+	if isTombstone(raw) {
+		return nil, nil
+	}
+
+	schema, err := extractSchema(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []DebeziumSchemaField
+	if schema != nil {
+		fields = schema.Fields
+	}
+
+	key, err := decodeStruct(raw, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Debezium key: %w", err)
+	}
+
+	return key, nil
+}
+
+// KeyString returns a stable string form of the event key ("col=value" pairs sorted by column),
+// suitable for hashing events to workers or deduplicating them. It is empty for keyless tables.
+func (e *Event) KeyString() string {
+	if len(e.Key) == 0 {
+		return ""
+	}
+
+	columns := make([]string, 0, len(e.Key))
+	for column := range e.Key {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var b strings.Builder
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%v", column, e.Key[column])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,202 @@
+package consumer
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Middleware wraps an EventHandler to add behaviour around it, such as logging or retries
+type Middleware func(next EventHandler) EventHandler
+
+// Chain wraps handler with middlewares. The first middleware is the outermost, so it sees the
+// event first and the final error last.
+//
+// Example usage:
+//
+//	handler := consumer.Chain(router.Handle,
+//	    consumer.Recover(),
+//	    consumer.Logging(),
+//	    consumer.Metrics(handlerMetrics),
+//	    consumer.Retry(3, 500*time.Millisecond),
+//	)
+func Chain(handler EventHandler, middlewares ...Middleware) EventHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Logging logs every event with its outcome and handling time, failures as errors and successes
+// at debug level
+func Logging() Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) error {
+			start := time.Now()
+			err := next(event)
+			if err != nil {
+				handlerLogger.Error("Event handler failed", "event", event, "duration", time.Since(start), "error", err)
+				return err
+			}
+			handlerLogger.Debug("Event handled", "event", event, "duration", time.Since(start))
+			return nil
+		}
+	}
+}
+
+// Recover turns a panic in the handler into an error, so one bad event doesn't crash the engine
+func Recover() Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					handlerLogger.Error("Panic in event handler", "event", event, "panic", r, "stack", string(debug.Stack()))
+					err = fmt.Errorf("handler panicked: %v", r)
+				}
+			}()
+			return next(event)
+		}
+	}
+}
+
+// Filter only passes events for which keep returns true; other events are skipped without error
+//
+// Example usage:
+//
+//	consumer.Filter(func(e *consumer.Event) bool { return e.Operation != "r" }) // ignore snapshots
+func Filter(keep func(event *Event) bool) Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) error {
+			if !keep(event) {
+				return nil
+			}
+			return next(event)
+		}
+	}
+}
+
+// Retry calls the handler up to attempts times, backing off exponentially from delay between attempts.
+// Since the reader waits for the handler, keep attempts and delay small; persistent failures are
+// better sent to a dead letter topic.
+func Retry(attempts int, delay time.Duration) Middleware {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) error {
+			var err error
+			for i := 0; i < attempts; i++ {
+				if err = next(event); err == nil {
+					return nil
+				}
+				if i < attempts-1 {
+					time.Sleep(backoff(delay, 30*delay, i))
+				}
+			}
+			return fmt.Errorf("failed after %d attempts: %w", attempts, err)
+		}
+	}
+}
+
+// Trace calls start before each event and the returned finish function with the handler's
+// result, e.g. to open and close a span in a tracing library
+func Trace(start func(event *Event) (finish func(err error))) Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) error {
+			finish := start(event)
+			err := next(event)
+			finish(err)
+			return err
+		}
+	}
+}
+
+// HandlerMetrics collects per table and operation counts and handling time, see Metrics
+type HandlerMetrics struct {
+	mu     sync.Mutex
+	counts map[handlerKey]*handlerCount
+}
+
+// HandlerStats is the snapshot of one table and operation returned by HandlerMetrics.Snapshot
+type HandlerStats struct {
+	Table     string        `json:"table"`
+	Operation string        `json:"operation"`
+	Handled   int64         `json:"handled"`
+	Failed    int64         `json:"failed"`
+	TotalTime time.Duration `json:"total_time"`
+	MaxTime   time.Duration `json:"max_time"`
+}
+
+type handlerKey struct {
+	table, operation string
+}
+
+type handlerCount struct {
+	handled, failed int64
+	total, max      time.Duration
+}
+
+// NewHandlerMetrics creates an empty HandlerMetrics
+func NewHandlerMetrics() *HandlerMetrics {
+	return &HandlerMetrics{counts: make(map[handlerKey]*handlerCount)}
+}
+
+// Metrics records the outcome and handling time of every event in m
+func Metrics(m *HandlerMetrics) Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) error {
+			start := time.Now()
+			err := next(event)
+			m.record(event.Source.Table, event.Operation, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+func (m *HandlerMetrics) record(table, op string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := handlerKey{table: table, operation: op}
+	c, ok := m.counts[key]
+	if !ok {
+		c = &handlerCount{}
+		m.counts[key] = c
+	}
+	if err != nil {
+		c.failed++
+	} else {
+		c.handled++
+	}
+	c.total += d
+	c.max = max(c.max, d)
+}
+
+// Snapshot returns the collected stats sorted by table and operation
+func (m *HandlerMetrics) Snapshot() []HandlerStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]HandlerStats, 0, len(m.counts))
+	for key, c := range m.counts {
+		stats = append(stats, HandlerStats{
+			Table:     key.table,
+			Operation: key.operation,
+			Handled:   c.handled,
+			Failed:    c.failed,
+			TotalTime: c.total,
+			MaxTime:   c.max,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Table != stats[j].Table {
+			return stats[i].Table < stats[j].Table
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+
+	return stats
+}
@@ -0,0 +1,263 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Positions accepted by OffsetResetRequest.To
+const (
+	OffsetEarliest  = "earliest"
+	OffsetLatest    = "latest"
+	OffsetTimestamp = "timestamp"
+)
+
+// PartitionOffset describes the consumer group position on one partition
+type PartitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Committed int64  `json:"committed"` // -1 when the group has not committed on this partition
+	LogStart  int64  `json:"log_start"`
+	LogEnd    int64  `json:"log_end"`
+	Lag       int64  `json:"lag"`
+}
+
+// OffsetResetRequest selects where ResetOffsets moves the consumer group
+type OffsetResetRequest struct {
+	To         string    // OffsetEarliest, OffsetLatest or OffsetTimestamp
+	Timestamp  time.Time // First message at or after this time, used with OffsetTimestamp
+	Topic      string    // Optional, limits the reset to one of the subscribed topics
+	Partitions []int     // Optional, limits the reset to these partitions
+	DryRun     bool      // Compute the new offsets without committing them
+}
+
+// GroupOffsets returns the committed offset, log bounds and lag of the consumer group
+// for every partition of the subscribed topics
+func (km *KafkaManager) GroupOffsets(ctx context.Context) ([]PartitionOffset, error) {
+	partitions, err := km.topicPartitions()
+	if err != nil {
+		return nil, err
+	}
+
+	client := km.client()
+
+	fetched, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: km.config.GroupID,
+		Topics:  partitions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+	if fetched.Error != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", fetched.Error)
+	}
+
+	bounds, err := km.listOffsets(ctx, partitions, func(p int) []kafka.OffsetRequest {
+		return []kafka.OffsetRequest{kafka.FirstOffsetOf(p), kafka.LastOffsetOf(p)}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make(map[string]map[int]int64)
+	for topic, parts := range fetched.Topics {
+		committed[topic] = make(map[int]int64)
+		for _, p := range parts {
+			committed[topic][p.Partition] = p.CommittedOffset
+		}
+	}
+
+	var offsets []PartitionOffset
+	for topic, parts := range bounds {
+		for _, p := range parts {
+			offset := PartitionOffset{
+				Topic:     topic,
+				Partition: p.Partition,
+				Committed: -1,
+				LogStart:  p.FirstOffset,
+				LogEnd:    p.LastOffset,
+			}
+			if c, ok := committed[topic][p.Partition]; ok && c >= 0 {
+				offset.Committed = c
+				offset.Lag = p.LastOffset - c
+			} else {
+				offset.Lag = p.LastOffset - p.FirstOffset
+			}
+			offsets = append(offsets, offset)
+		}
+	}
+	sortOffsets(offsets)
+
+	return offsets, nil
+}
+
+// ResetOffsets commits new consumer group offsets for the subscribed topics and returns them.
+// Kafka only accepts these commits while the group has no active members, so every engine
+// instance must be stopped first.
+func (km *KafkaManager) ResetOffsets(ctx context.Context, req OffsetResetRequest) ([]PartitionOffset, error) {
+	partitions, err := km.topicPartitions()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Topic != "" {
+		parts, ok := partitions[req.Topic]
+		if !ok {
+			return nil, fmt.Errorf("topic %s is not subscribed", req.Topic)
+		}
+		partitions = map[string][]int{req.Topic: parts}
+	}
+	if len(req.Partitions) > 0 {
+		for topic, parts := range partitions {
+			partitions[topic] = slices.DeleteFunc(parts, func(p int) bool {
+				return !slices.Contains(req.Partitions, p)
+			})
+		}
+	}
+
+	var request func(p int) []kafka.OffsetRequest
+	switch req.To {
+	case OffsetEarliest:
+		request = func(p int) []kafka.OffsetRequest { return []kafka.OffsetRequest{kafka.FirstOffsetOf(p)} }
+	case OffsetLatest:
+		request = func(p int) []kafka.OffsetRequest { return []kafka.OffsetRequest{kafka.LastOffsetOf(p)} }
+	case OffsetTimestamp:
+		if req.Timestamp.IsZero() {
+			return nil, fmt.Errorf("timestamp is required to reset offsets by time")
+		}
+		request = func(p int) []kafka.OffsetRequest {
+			// The log end is the fallback when no message is newer than the timestamp
+			return []kafka.OffsetRequest{kafka.TimeOffsetOf(p, req.Timestamp), kafka.LastOffsetOf(p)}
+		}
+	default:
+		return nil, fmt.Errorf("unknown offset position: %q", req.To)
+	}
+
+	listed, err := km.listOffsets(ctx, partitions, request)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make(map[string][]kafka.OffsetCommit)
+	var offsets []PartitionOffset
+	for topic, parts := range listed {
+		for _, p := range parts {
+			target := p.LastOffset
+			switch req.To {
+			case OffsetEarliest:
+				target = p.FirstOffset
+			case OffsetTimestamp:
+				for offset := range p.Offsets {
+					if offset >= 0 {
+						target = offset
+					}
+				}
+			}
+
+			commits[topic] = append(commits[topic], kafka.OffsetCommit{Partition: p.Partition, Offset: target})
+			offsets = append(offsets, PartitionOffset{
+				Topic:     topic,
+				Partition: p.Partition,
+				Committed: target,
+				LogEnd:    p.LastOffset,
+				Lag:       p.LastOffset - target,
+			})
+		}
+	}
+	sortOffsets(offsets)
+
+	if req.DryRun {
+		return offsets, nil
+	}
+
+	res, err := km.client().OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      km.config.GroupID,
+		GenerationID: -1,
+		Topics:       commits,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit offsets: %w", err)
+	}
+	for topic, parts := range res.Topics {
+		for _, p := range parts {
+			if p.Error != nil {
+				return nil, fmt.Errorf("failed to commit offset for %s[%d]: %w (is the engine still running?)", topic, p.Partition, p.Error)
+			}
+		}
+	}
+
+	managerLogger.Info("Reset partition offsets", "partitions", len(offsets), "group", km.config.GroupID, "to", req.To)
+
+	return offsets, nil
+}
+
+// topicPartitions returns the partition IDs of every subscribed topic
+func (km *KafkaManager) topicPartitions() (map[string][]int, error) {
+	topics, err := km.ResolveTopics()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := km.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := conn.ReadPartitions(topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partitions: %w", err)
+	}
+
+	out := make(map[string][]int)
+	for _, p := range partitions {
+		out[p.Topic] = append(out[p.Topic], p.ID)
+	}
+
+	return out, nil
+}
+
+// listOffsets runs a ListOffsets request built per partition by request
+func (km *KafkaManager) listOffsets(ctx context.Context, partitions map[string][]int, request func(p int) []kafka.OffsetRequest) (map[string][]kafka.PartitionOffsets, error) {
+	topics := make(map[string][]kafka.OffsetRequest)
+	for topic, parts := range partitions {
+		for _, p := range parts {
+			topics[topic] = append(topics[topic], request(p)...)
+		}
+	}
+
+	res, err := km.client().ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+	for topic, parts := range res.Topics {
+		for _, p := range parts {
+			if p.Error != nil {
+				return nil, fmt.Errorf("failed to list offsets for %s[%d]: %w", topic, p.Partition, p.Error)
+			}
+		}
+	}
+
+	return res.Topics, nil
+}
+
+// client returns a kafka-go admin client for the configured broker
+func (km *KafkaManager) client() *kafka.Client {
+	return &kafka.Client{
+		Addr:    kafka.TCP(km.config.Broker),
+		Timeout: 10 * time.Second,
+	}
+}
+
+func sortOffsets(offsets []PartitionOffset) {
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+}
@@ -0,0 +1,111 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tracecontext"
+)
+
+// Emit queues m (e.g. an alert) to be published once the event is committed by the Transactional
+// middleware. Without that middleware emitted messages are dropped. Unless m already has a
+// traceparent header, it carries the event's trace context so the alert joins the event's trace.
+func (e *Event) Emit(m transport.Message) {
+	if _, ok := m.Headers[tracecontext.TraceparentHeader]; !ok && e.Trace.IsValid() {
+		headers := make(map[string]string, len(m.Headers)+2)
+		for k, v := range m.Headers {
+			headers[k] = v
+		}
+		tracecontext.Inject(e.Trace, headers)
+		m.Headers = headers
+	}
+	e.emitted = append(e.emitted, m)
+}
+
+// Emitted returns the messages queued with Emit
+func (e *Event) Emitted() []transport.Message {
+	return e.emitted
+}
+
+// Outbox durably records which events were processed together with the messages they emitted.
+// A relay publishes the stored messages afterwards (see package outbox).
+type Outbox interface {
+	// Processed reports whether the event was already committed
+	Processed(ctx context.Context, event *Event) (bool, error)
+	// Commit marks the event as processed and stores msgs in one transaction. It returns false,
+	// storing nothing, when the event was already committed.
+	Commit(ctx context.Context, event *Event, msgs []transport.Message) (bool, error)
+}
+
+// outboxTimeout bounds each outbox operation
+const outboxTimeout = 30 * time.Second
+
+// Transactional makes handling an event and publishing what it emits atomic: emitted messages are
+// stored in outbox in the same transaction that marks the event processed, so they are published
+// if and only if the event counts as handled. Events already committed (redelivered after a crash
+// before their Kafka offset was committed) are skipped, and messages of failed handlers are
+// discarded. While the outbox is unavailable the operation is retried with backoff rather than the
+// event skipped, until the reader stops (see Event.Context), when the event fails to be redelivered.
+//
+// Example usage:
+//
+//	handler := consumer.Chain(router.Handle, consumer.Recover(), consumer.Transactional(store))
+func Transactional(outbox Outbox) Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(event *Event) error {
+			var processed bool
+			err := retryOutbox(event, "check", func(ctx context.Context) (err error) {
+				processed, err = outbox.Processed(ctx, event)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if processed {
+				outboxLogger.Info("Skipping already committed event", "event", event)
+				return nil
+			}
+
+			event.emitted = nil
+			if err := next(event); err != nil {
+				return err
+			}
+
+			var committed bool
+			err = retryOutbox(event, "commit", func(ctx context.Context) (err error) {
+				committed, err = outbox.Commit(ctx, event, event.emitted)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if !committed {
+				outboxLogger.Warn("Event was committed concurrently, discarding its messages", "event", event, "messages", len(event.emitted))
+			}
+			return nil
+		}
+	}
+}
+
+// retryOutbox runs op until it succeeds, backing off up to 30s between attempts. It gives up with
+// op's last error once the event's context is done. An attempt already running is not cancelled, so a
+// commit is not rolled back halfway through.
+func retryOutbox(event *Event, name string, op func(ctx context.Context) error) error {
+	eventCtx := event.Context()
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(eventCtx), outboxTimeout)
+		err := op(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		delay := backoff(time.Second, 30*time.Second, attempt)
+		outboxLogger.Error("Outbox operation failed, retrying", "operation", name, "event", event, "retry_in", delay.Round(time.Millisecond), "error", err)
+		if sleepContext(eventCtx, delay) != nil {
+			return fmt.Errorf("outbox %s failed: %w", name, err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package consumer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufRootFile is the name the registry schema is compiled under
+const protobufRootFile = "schema.proto"
+
+// protobufDeserializer decodes messages written by Confluent's ProtobufConverter
+type protobufDeserializer struct {
+	registry *SchemaRegistryClient
+	mu       sync.RWMutex
+	files    map[int]protoreflect.FileDescriptor
+}
+
+func newProtobufDeserializer(registry *SchemaRegistryClient) *protobufDeserializer {
+	return &protobufDeserializer{
+		registry: registry,
+		files:    make(map[int]protoreflect.FileDescriptor),
+	}
+}
+
+// Deserialize decodes the protobuf message and re-encodes it as a schemaless Debezium JSON message
+func (d *protobufDeserializer) Deserialize(data []byte) ([]byte, error) {
+	schemaID, body, err := parseConfluentHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, body, err := parseMessageIndexes(body)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := d.file(schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := messageByIndexes(file, indexes)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: %w", schemaID, err)
+	}
+
+	msg := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf message with schema %d: %w", schemaID, err)
+	}
+
+	return json.Marshal(map[string]any{"payload": protoMessageToMap(msg)})
+}
+
+// file returns the cached file descriptor for schemaID, compiling it from the registry on first use
+func (d *protobufDeserializer) file(schemaID int) (protoreflect.FileDescriptor, error) {
+	d.mu.RLock()
+	file, ok := d.files[schemaID]
+	d.mu.RUnlock()
+	if ok {
+		return file, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schema, err := d.registry.GetSchemaByID(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+	if schema.SchemaType != "PROTOBUF" {
+		return nil, fmt.Errorf("schema %d has type %q, expected PROTOBUF", schemaID, schema.SchemaType)
+	}
+
+	sources := map[string]string{protobufRootFile: schema.Schema}
+	if err := d.resolveReferences(ctx, schema.References, sources); err != nil {
+		return nil, err
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+	}
+	files, err := compiler.Compile(ctx, protobufRootFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile protobuf schema %d: %w", schemaID, err)
+	}
+	file = files[0]
+
+	d.mu.Lock()
+	d.files[schemaID] = file
+	d.mu.Unlock()
+
+	return file, nil
+}
+
+// resolveReferences fetches imported schemas from the registry into sources, keyed by import path
+func (d *protobufDeserializer) resolveReferences(ctx context.Context, refs []SchemaReference, sources map[string]string) error {
+	for _, ref := range refs {
+		if _, ok := sources[ref.Name]; ok {
+			continue
+		}
+
+		schema, err := d.registry.GetSchemaBySubject(ctx, ref.Subject, ref.Version)
+		if err != nil {
+			return err
+		}
+		sources[ref.Name] = schema.Schema
+
+		if err := d.resolveReferences(ctx, schema.References, sources); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseMessageIndexes reads the zigzag varint array identifying which message in the schema was written.
+// A single 0 byte is shorthand for the first top-level message.
+func parseMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("invalid message index count")
+	}
+	data = data[n:]
+
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		idx, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, fmt.Errorf("invalid message index at position %d", i)
+		}
+		indexes[i] = int(idx)
+		data = data[n:]
+	}
+
+	return indexes, data, nil
+}
+
+// messageByIndexes walks top-level and nested message declarations following indexes
+func messageByIndexes(file protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	messages := file.Messages()
+	var desc protoreflect.MessageDescriptor
+
+	for _, idx := range indexes {
+		if idx < 0 || idx >= messages.Len() {
+			return nil, fmt.Errorf("message index %d out of range", idx)
+		}
+		desc = messages.Get(idx)
+		messages = desc.Messages()
+	}
+
+	return desc, nil
+}
+
+// protoMessageToMap converts a dynamic message into plain Go values using proto field names.
+// Well-known wrapper and timestamp types are flattened so the result matches the JsonConverter payload.
+func protoMessageToMap(msg protoreflect.Message) any {
+	desc := msg.Descriptor()
+	name := string(desc.FullName())
+
+	switch {
+	case name == "google.protobuf.Timestamp":
+		fields := desc.Fields()
+		secs := msg.Get(fields.ByName("seconds")).Int()
+		nanos := msg.Get(fields.ByName("nanos")).Int()
+		return time.Unix(secs, nanos).UTC()
+	case strings.HasPrefix(name, "google.protobuf.") && strings.HasSuffix(name, "Value"):
+		if field := desc.Fields().ByName("value"); field != nil {
+			return protoValueToAny(field, msg.Get(field))
+		}
+	}
+
+	out := make(map[string]any, desc.Fields().Len())
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.HasPresence() && !msg.Has(field) {
+			out[string(field.Name())] = nil
+			continue
+		}
+		out[string(field.Name())] = protoFieldToAny(field, msg.Get(field))
+	}
+
+	return out
+}
+
+// protoFieldToAny converts a field value, expanding repeated and map fields
+func protoFieldToAny(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch {
+	case field.IsList():
+		list := value.List()
+		out := make([]any, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			out[i] = protoValueToAny(field, list.Get(i))
+		}
+		return out
+	case field.IsMap():
+		out := make(map[string]any, value.Map().Len())
+		value.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			out[k.String()] = protoValueToAny(field.MapValue(), v)
+			return true
+		})
+		return out
+	default:
+		return protoValueToAny(field, value)
+	}
+}
+
+// protoValueToAny converts a single (non-repeated) value
+func protoValueToAny(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoMessageToMap(value.Message())
+	case protoreflect.EnumKind:
+		if enum := field.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			return string(enum.Name())
+		}
+		return int32(value.Enum())
+	default:
+		return value.Interface()
+	}
+}
@@ -1,23 +1,104 @@
 package consumer
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
-	objects "github.com/ahsansaif47/blockchain-address-watcher/engine/models"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tracecontext"
 	"github.com/segmentio/kafka-go"
 )
 
+// Loggers of the consumer's components
+var (
+	logger          = logging.For("reader")
+	batchLogger     = logging.For("batch_reader")
+	handlerLogger   = logging.For("handler")
+	heartbeatLogger = logging.For("heartbeat")
+	managerLogger   = logging.For("kafka_manager")
+	outboxLogger    = logging.For("outbox")
+	replayLogger    = logging.For("replay")
+)
+
+// ErrTombstone is returned for null-value messages Debezium emits after deletes for log compaction
+var ErrTombstone = errors.New("tombstone message")
+
+// UnknownOperationError is returned for messages of an operation other than c, u, d and r, e.g.
+// "t" for truncates
+type UnknownOperationError struct {
+	Operation string
+}
+
+func (e *UnknownOperationError) Error() string {
+	return "unknown operation type: " + e.Operation
+}
+
 // Event represents a parsed Debezium CDC event
+// Before and After hold the table's registered row type (see RegisterTable), e.g. *objects.User
+// for the users table, or map[string]any for tables without a registered type.
 type Event struct {
-	Operation string        // "c" (create), "u" (update), "d" (delete), "r" (read/snapshot)
-	Before    *objects.User // State before the change (nil for creates)
-	After     *objects.User // State after the change (nil for deletes)
-	Source    SourceInfo    // Metadata like table name, timestamp, etc.
-	Timestamp time.Time     // When the event was created
+	Operation string                   // "c" (create), "u" (update), "d" (delete), "r" (read/snapshot)
+	Before    any                      // State before the change (nil for creates)
+	After     any                      // State after the change (nil for deletes)
+	Source    SourceInfo               // Metadata like table name, timestamp, etc.
+	Timestamp time.Time                // When the event was created
+	Unwrapped bool                     // True when flattened by ExtractNewRecordState; Before is unavailable for updates
+	Topic     string                   // Kafka topic the event was read from
+	Partition int                      // Kafka partition the event was read from
+	Offset    int64                    // Kafka offset of the message
+	Key       map[string]any           // Primary key columns from the message key (nil for keyless tables)
+	Headers   map[string]string        // Kafka message headers
+	Trace     tracecontext.SpanContext // Span handling the event, a child of the traceparent header if present
+
+	emitted []transport.Message // Messages queued by Emit
+	ctx     context.Context     // Context of the reader, see Context
+
+	// Key and value of the message, quarantined when handling fails
+	rawKey, rawValue []byte
+}
+
+// Context returns the context of the reader the event was read by, done once the reader stops or
+// its partitions are revoked. Events built by hand get context.Background.
+func (e *Event) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// LogValue groups the event's position, table, operation, key and trace ID in structured logs,
+// e.g. logger.Info("Handled", "event", event)
+func (e *Event) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("topic", e.Topic),
+		slog.Int("partition", e.Partition),
+		slog.Int64("offset", e.Offset),
+		slog.String("table", e.Source.Table),
+		slog.String("op", e.Operation),
+	}
+	if key := e.KeyString(); key != "" {
+		attrs = append(attrs, slog.String("key", key))
+	}
+	if e.Trace.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", e.Trace.TraceIDString()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// messageAttrs groups the position of a Kafka message in structured logs
+func messageAttrs(m kafka.Message) slog.Attr {
+	return slog.Group("message",
+		slog.String("topic", m.Topic),
+		slog.Int("partition", m.Partition),
+		slog.Int64("offset", m.Offset),
+	)
 }
 
 // SourceInfo contains metadata from Debezium about the source of the event
@@ -26,15 +107,17 @@ type SourceInfo struct {
 	Connector string `json:"connector"`
 	Name      string `json:"name"`
 	TsMs      int64  `json:"ts_ms"`
-	TsUs      int64  `json:"ts_us"`
-	TsNs      int64  `json:"ts_ns"`
-	Snapshot  bool   `json:"snapshot"` // "true", "false", or "last"
+	TsUs      int64  `json:"ts_us,omitempty"`
+	TsNs      int64  `json:"ts_ns,omitempty"`
+	Snapshot  string `json:"snapshot"` // "true", "false", "last", or another snapshot phase
 	Db        string `json:"db"`
-	Sequence  string `json:"sequences"`
+	Sequence  string `json:"sequence,omitempty"` // e.g. `["24023119","24023128"]`, JSON encoded by Debezium
 	Schema    string `json:"schema"`
 	Table     string `json:"table"`
-	TxId      string `json:"txId,omitempty"`
-	Lsn       int64  `json:"lsn,omitempty"`
+	// TxId is a number for PostgreSQL and a string for connectors such as Oracle, null when unknown
+	TxId json.RawMessage `json:"txId,omitempty"`
+	Lsn  int64           `json:"lsn,omitempty"`
+	Xmin *int64          `json:"xmin"` // PostgreSQL only, null unless xmin.fetch.interval.ms is set
 }
 
 // DebeziumMessage represents the raw Debezium message structure
@@ -54,20 +137,24 @@ type DebeziumSchema struct {
 
 // DebeziumSchemaField represents a field in the schema
 type DebeziumSchemaField struct {
-	Type     string `json:"type"`
-	Field    string `json:"field"`
-	Optional bool   `json:"optional"`
+	Type       string                `json:"type"`
+	Field      string                `json:"field"`
+	Optional   bool                  `json:"optional"`
+	Name       string                `json:"name,omitempty"`       // Semantic type, e.g. "io.debezium.time.MicroTimestamp"
+	Parameters map[string]string     `json:"parameters,omitempty"` // Type parameters, e.g. the scale of a decimal
+	Fields     []DebeziumSchemaField `json:"fields,omitempty"`     // Nested fields of struct types such as before/after
 }
 
 // DebeziumPayload contains the actual data from Debezium
+// Rows are kept raw and decoded per table by decodeRow
 type DebeziumPayload struct {
-	Before    *objects.User `json:"before"`
-	After     *objects.User `json:"after"`
-	Source    SourceInfo    `json:"source"`
-	Operation string        `json:"op"`
-	TsMs      int64         `json:"ts_ms"`
-	TsUs      int64         `json:"ts_us"`
-	TsNs      int64         `json:"ts_ns"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Source    SourceInfo      `json:"source"`
+	Operation string          `json:"op"`
+	TsMs      int64           `json:"ts_ms"`
+	TsUs      int64           `json:"ts_us"`
+	TsNs      int64           `json:"ts_ns"`
 }
 
 // EventHandler is a callback function that processes each Debezium event
@@ -85,9 +172,11 @@ type EventHandler func(event *Event) error
 //	func handleEvent(event *consumer.Event) error {
 //	    switch event.Operation {
 //	    case "c", "u":
-//	        fmt.Printf("User created/updated: %s\n", event.After.Email)
+//	        user := event.After.(*objects.User)
+//	        fmt.Printf("User created/updated: %s\n", user.Email)
 //	    case "d":
-//	        fmt.Printf("User deleted: %s\n", event.Before.Email)
+//	        user := event.Before.(*objects.User)
+//	        fmt.Printf("User deleted: %s\n", user.Email)
 //	    }
 //	    return nil
 //	}
@@ -102,78 +191,327 @@ func Read(ctx context.Context, km *KafkaManager, handler EventHandler) error {
 		return fmt.Errorf("event handler cannot be nil")
 	}
 
-	// Create a reader for the topic
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  []string{km.config.Broker},
-		Topic:    km.config.Topic,
-		GroupID:  "blockchain-address-watcher-group",
-		MinBytes: 10e3, // 10KB
-		MaxBytes: 10e6, // 10MB
-	})
-	defer r.Close()
+	decoder, err := newMessageDecoder(km.config, &km.metrics)
+	if err != nil {
+		return err
+	}
 
-	log.Printf("[Reader] Starting to read from topic: %s", km.config.Topic)
+	return km.subscribe(ctx, func(ctx context.Context, r *partitionReader) error {
+		// Offsets are committed once handled, so events are not lost if the engine stops midway
+		commits := newCommitTracker(r)
+		handled := func(event *Event) {
+			commits.handled(event.Topic, event.Partition, event.Offset)
+		}
 
-	// Start reading loop
-	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("[Reader] Context cancelled, stopping reader")
-			return ctx.Err()
+		// With multiple workers events are handed to a bounded pool, otherwise handled inline. A failed
+		// event is only committed once quarantined or given up on after HandlerAttempts attempts,
+		// otherwise it is retried and, if the reader stops first, read again by the partition's next owner.
+		dispatch := func(ctx context.Context, event *Event) error {
+			if !handleUntilDone(ctx, &km.metrics, km.config.HandlerAttempts, handler, event) {
+				return ctx.Err()
+			}
+			handled(event)
+			return nil
+		}
+		km.metrics.workers.Store(1)
+		var target *atomic.Int64
+		if km.config.MaxWorkers > 1 {
+			pool := newWorkerPool(ctx, km, handler, handled)
+			// Events already queued are handled and committed before the partitions are revoked
+			defer pool.close()
+			dispatch = pool.submit
 
-		default:
-			// Read message from Kafka
-			m, err := r.ReadMessage(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					log.Printf("[Reader] Context cancelled during read: %v", err)
-					return ctx.Err()
-				}
-				log.Printf("[Reader] Error reading message: %v", err)
-				// Continue trying to read
-				time.Sleep(1 * time.Second)
-				continue
+			if km.scaling() {
+				target = &pool.target
 			}
+		}
+		go km.monitorLag(ctx, target)
 
-			log.Printf("[Reader] Received message at offset %d (partition %d)",
-				m.Offset, m.Partition)
+		// Start reading loop
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("Context cancelled, stopping reader")
+				return ctx.Err()
 
-			// Parse the Debezium message
-			event, err := parseDebeziumMessage(m.Value)
-			if err != nil {
-				log.Printf("[Reader] Error parsing message: %v", err)
-				continue
-			}
+			default:
+				// Fetch message from Kafka, it is committed once handled
+				m, err := r.FetchMessage(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						logger.Info("Context cancelled during read", "error", err)
+						return ctx.Err()
+					}
+					logger.Error("Error reading message", "error", err)
+					// Continue trying to read
+					if err := sleepContext(ctx, 1*time.Second); err != nil {
+						return err
+					}
+					continue
+				}
+				commits.fetched(m)
 
-			// Call the event handler
-			if err := handler(event); err != nil {
-				log.Printf("[Reader] Error in event handler: %v", err)
-				// Continue processing other messages even if one fails
+				event := decoder.decode(m)
+				if event == nil {
+					// Tombstones and unparsable messages have nothing to handle
+					commits.handled(m.Topic, m.Partition, m.Offset)
+					continue
+				}
+				event.ctx = ctx
+
+				// Call the event handler, blocking while the workers are at capacity
+				if err := dispatch(ctx, event); err != nil {
+					return err
+				}
 			}
 		}
+	})
+}
+
+// subscribe resolves the configured topics, joins the consumer group on them and runs consume for
+// every generation of the group, with a reader of the partitions assigned to this engine. consume's
+// context is cancelled when the group rebalances, and its partitions are revoked once it returns.
+// Pattern and prefix subscriptions rejoin the group when matching topics appear or disappear.
+func (km *KafkaManager) subscribe(ctx context.Context, consume func(ctx context.Context, r *partitionReader) error) error {
+	for {
+		topics, err := km.ResolveTopics()
+		if err != nil {
+			return fmt.Errorf("failed to resolve topics: %w", err)
+		}
+
+		readCtx, cancel := context.WithCancel(ctx)
+		if km.hasDynamicTopics() {
+			go km.watchTopics(readCtx, topics, cancel)
+		}
+
+		group, err := km.newConsumerGroup(topics)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to create consumer group: %w", err)
+		}
+
+		logger.Info("Starting to read from topics", "topics", topics, "group", km.config.GroupID)
+
+		err = km.consumeGenerations(readCtx, group, consume)
+		// Leaves the group, so its partitions are assigned to the other engines right away
+		group.Close()
+		cancel()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if readCtx.Err() == nil {
+			return err
+		}
+		logger.Info("Topic subscription changed, rejoining consumer group")
 	}
 }
 
+// messageDecoder turns Kafka messages into Events using the configured value and key serializations
+type messageDecoder struct {
+	metrics *readerMetrics
+	value   Deserializer
+	key     Deserializer
+}
+
+func newMessageDecoder(config *Config, metrics *readerMetrics) (*messageDecoder, error) {
+	value, err := NewDeserializer(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deserializer: %w", err)
+	}
+
+	keySerialization := config.KeySerialization
+	if keySerialization == "" {
+		keySerialization = config.Serialization
+	}
+	key, err := NewDeserializer(&Config{
+		Serialization:     keySerialization,
+		SchemaRegistryURL: config.SchemaRegistryURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key deserializer: %w", err)
+	}
+
+	return &messageDecoder{metrics: metrics, value: value, key: key}, nil
+}
+
+// decode parses m into an Event, counting it in metrics.
+// It returns nil for tombstones and messages that fail to parse, which are skipped.
+func (d *messageDecoder) decode(m kafka.Message) *Event {
+	logger.Debug("Received message", messageAttrs(m))
+	d.metrics.messages.Add(1)
+
+	// Tombstones only mark deleted keys for compaction, there is nothing to handle
+	if isTombstone(m.Value) {
+		d.metrics.tombstones.Add(1)
+		tombstonesTotal.Inc(m.Topic)
+		return nil
+	}
+
+	// Decode the message value according to the configured serialization
+	value, err := d.value.Deserialize(m.Value)
+	if err != nil {
+		d.metrics.parseErrors.Add(1)
+		parseFailuresTotal.Inc(m.Topic, StageDeserialize)
+		logger.Warn("Error deserializing message", messageAttrs(m), "error", err)
+		quarantine(StageDeserialize, m, err)
+		return nil
+	}
+
+	// Parse the Debezium message
+	event, err := parseDebeziumMessage(value)
+	if errors.Is(err, ErrTombstone) {
+		d.metrics.tombstones.Add(1)
+		tombstonesTotal.Inc(m.Topic)
+		return nil
+	}
+	var unknown *UnknownOperationError
+	if errors.As(err, &unknown) {
+		d.metrics.parseErrors.Add(1)
+		unknownOperationsTotal.Inc(m.Topic, operationLabel(unknown.Operation))
+		logger.Warn("Skipping message of unknown operation", messageAttrs(m), "op", unknown.Operation)
+		quarantine(StageUnknownOperation, m, err)
+		return nil
+	}
+	if err != nil {
+		d.metrics.parseErrors.Add(1)
+		parseFailuresTotal.Inc(m.Topic, StageParse)
+		logger.Warn("Error parsing message", messageAttrs(m), "error", err)
+		quarantine(StageParse, m, err)
+		return nil
+	}
+
+	event.rawKey, event.rawValue = m.Key, m.Value
+	event.Topic = m.Topic
+	event.Partition = m.Partition
+	event.Offset = m.Offset
+
+	// Continue the trace of whoever produced the change, e.g. an API request, or start one
+	if len(m.Headers) > 0 {
+		event.Headers = make(map[string]string, len(m.Headers))
+		for _, h := range m.Headers {
+			event.Headers[h.Key] = string(h.Value)
+		}
+	}
+	parent, _ := tracecontext.Extract(event.Headers)
+	event.Trace = parent.Child()
+
+	// A key that fails to decode is not fatal, handlers can still use the payload
+	if !isTombstone(m.Key) {
+		if key, err := d.key.Deserialize(m.Key); err != nil {
+			logger.Warn("Error deserializing key", "event", event, "error", err)
+		} else if event.Key, err = parseDebeziumKey(key); err != nil {
+			logger.Warn("Error parsing key", "event", event, "error", err)
+		}
+	}
+
+	return event
+}
+
+// ParseMessage parses a deserialized Debezium JSON message into an Event.
+// It accepts the same shapes as Read: enveloped or unwrapped, with or without an embedded schema.
+func ParseMessage(data []byte) (*Event, error) {
+	return parseDebeziumMessage(data)
+}
+
 // parseDebeziumMessage parses a raw Debezium message into an Event struct
 func parseDebeziumMessage(data []byte) (*Event, error) {
-	var msg DebeziumMessage
+	if isTombstone(data) {
+		return nil, ErrTombstone
+	}
+
+	var msg map[string]json.RawMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal Debezium message: %w", err)
 	}
 
+	raw := extractPayload(data, msg)
+	if isTombstone(raw) {
+		return nil, ErrTombstone
+	}
+
+	schema, err := extractSchema(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Debezium payload: %w", err)
+	}
+
+	// Payloads flattened by the ExtractNewRecordState SMT carry the row itself
+	if isUnwrappedPayload(fields) {
+		return parseUnwrappedPayload(raw, schema)
+	}
+
+	var payload DebeziumPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Debezium payload: %w", err)
+	}
+
+	return eventFromPayload(&payload, schema)
+}
+
+// extractPayload returns the Debezium payload from a decoded message.
+// Connectors configured with `schemas.enable=false` send the payload without the
+// schema/payload wrapper, in which case the whole message is the payload.
+func extractPayload(data []byte, msg map[string]json.RawMessage) json.RawMessage {
+	payload, hasPayload := msg["payload"]
+	_, hasSchema := msg["schema"]
+
+	if hasPayload && (hasSchema || len(msg) == 1) {
+		return payload
+	}
+
+	return data
+}
+
+// extractSchema returns the schema embedded by the JsonConverter, or nil for schemaless messages
+func extractSchema(msg map[string]json.RawMessage) (*DebeziumSchema, error) {
+	raw, hasSchema := msg["schema"]
+	if _, hasPayload := msg["payload"]; !hasSchema || !hasPayload || isTombstone(raw) {
+		return nil, nil
+	}
+
+	var schema DebeziumSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Debezium schema: %w", err)
+	}
+
+	return &schema, nil
+}
+
+// isTombstone reports whether a message value is empty or JSON null
+func isTombstone(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) == 0 || bytes.Equal(data, []byte("null"))
+}
+
+// eventFromPayload builds and validates an Event from a full Debezium envelope payload
+func eventFromPayload(payload *DebeziumPayload, schema *DebeziumSchema) (*Event, error) {
 	// Validate operation type
-	operation := msg.Payload.Operation
+	operation := payload.Operation
 	if operation == "" {
 		return nil, fmt.Errorf("missing operation type in payload")
 	}
 
+	before, err := decodeRow(payload.Source.Table, payload.Before, rowFields(schema, "before"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode 'before': %w", err)
+	}
+	after, err := decodeRow(payload.Source.Table, payload.After, rowFields(schema, "after"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode 'after': %w", err)
+	}
+
 	// Create event
 	event := &Event{
 		Operation: operation,
-		Before:    msg.Payload.Before,
-		After:     msg.Payload.After,
-		Source:    msg.Payload.Source,
-		Timestamp: time.UnixMilli(msg.Payload.TsMs),
+		Before:    before,
+		After:     after,
+		Source:    payload.Source,
+		Timestamp: time.UnixMilli(payload.TsMs),
 	}
 
 	// Validate event data
@@ -191,34 +529,41 @@ func parseDebeziumMessage(data []byte) (*Event, error) {
 			return nil, fmt.Errorf("missing 'before' data for operation 'd'")
 		}
 	default:
-		return nil, fmt.Errorf("unknown operation type: %s", operation)
+		return nil, &UnknownOperationError{Operation: operation}
 	}
 
 	return event, nil
 }
 
 // ReadWithRetry wraps the Read function with automatic retry logic
-// It will retry reading indefinitely if the connection is lost
+// It will retry reading indefinitely if the connection is lost, backing off exponentially from
+// retryDelay up to the configured MaxRetryDelay
 func ReadWithRetry(ctx context.Context, km *KafkaManager, handler EventHandler, retryDelay time.Duration) error {
 	if retryDelay == 0 {
 		retryDelay = 5 * time.Second
 	}
+	if km == nil {
+		return fmt.Errorf("KafkaManager cannot be nil")
+	}
+	maxDelay := max(retryDelay, km.config.MaxRetryDelay)
 
-	for {
-		select {
-		case <-ctx.Done():
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() != nil {
 			return ctx.Err()
-		default:
-			err := Read(ctx, km, handler)
-			if err != nil {
-				if ctx.Err() != nil {
-					return ctx.Err()
-				}
-				log.Printf("[ReaderWithRetry] Read failed: %v, retrying in %v", err, retryDelay)
-				time.Sleep(retryDelay)
-				continue
-			}
+		}
+
+		err := Read(ctx, km, handler)
+		if err == nil {
 			return nil
 		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delay := backoff(retryDelay, maxDelay, attempt)
+		logger.Error("Read failed, retrying", "retry_in", delay.Round(time.Millisecond), "error", err)
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
 	}
 }
@@ -0,0 +1,261 @@
+package consumer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// RecordedMessage is one line of an NDJSON message dump used by Replay.
+// JSON keys and values are stored inline; binary ones (Avro, Protobuf) as base64.
+type RecordedMessage struct {
+	Topic       string          `json:"topic,omitempty"`
+	Partition   int             `json:"partition"`
+	Offset      int64           `json:"offset"`
+	Time        time.Time       `json:"time,omitempty"`
+	Key         json.RawMessage `json:"key,omitempty"`
+	KeyBase64   []byte          `json:"key_base64,omitempty"`
+	Value       json.RawMessage `json:"value,omitempty"`
+	ValueBase64 []byte          `json:"value_base64,omitempty"`
+}
+
+// ReplayStats summarizes a replay run
+type ReplayStats struct {
+	Messages      int64 `json:"messages"`
+	Tombstones    int64 `json:"tombstones"`
+	ParseErrors   int64 `json:"parse_errors"`
+	HandlerErrors int64 `json:"handler_errors"`
+}
+
+// ReplayRange selects the messages ReplayTopic reads from a single partition.
+// The range ends at EndOffset or Until, whichever comes first; when both are unset it ends at
+// the partition's current end.
+type ReplayRange struct {
+	Topic       string
+	Partition   int
+	StartOffset int64     // Defaults to the first offset when Since is also unset
+	Since       time.Time // Start at the first message at or after this time instead of StartOffset
+	EndOffset   int64     // Exclusive, 0 means unset
+	Until       time.Time // Stop at the first message after this time
+}
+
+// RecordMessage appends m to w in the NDJSON format read by Replay
+func RecordMessage(w io.Writer, m kafka.Message) error {
+	rec := RecordedMessage{
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Time:      m.Time,
+	}
+	if json.Valid(m.Key) {
+		rec.Key = m.Key
+	} else {
+		rec.KeyBase64 = m.Key
+	}
+	if json.Valid(m.Value) {
+		rec.Value = m.Value
+	} else {
+		rec.ValueBase64 = m.Value
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// Replay runs every message in an NDJSON dump through the parser and handler, as Read would,
+// without connecting to Kafka or committing offsets. Lines that are not RecordedMessages are
+// treated as bare message values, so output of kafka-console-consumer can be replayed directly.
+// Dumps stored elsewhere can be streamed in, e.g. `aws s3 cp s3://bucket/dump.ndjson - | ...`.
+func Replay(ctx context.Context, config *Config, r io.Reader, handler EventHandler) (ReplayStats, error) {
+	replayer, err := NewReplayer(config, handler)
+	if err != nil {
+		return ReplayStats{}, err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10e6) // Debezium messages can be large
+
+	line := 0
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return replayer.Stats(), err
+		}
+
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		replayer.ReplayLine(data)
+	}
+	if err := scanner.Err(); err != nil {
+		return replayer.Stats(), fmt.Errorf("failed to read dump at line %d: %w", line, err)
+	}
+
+	return replayer.Stats(), nil
+}
+
+// Replayer runs the lines of an NDJSON dump through the parser and handler one at a time, like
+// Replay, for callers interleaving them with input of their own, e.g. engine simulate
+type Replayer struct {
+	decoder *messageDecoder
+	metrics readerMetrics
+	handler EventHandler
+}
+
+// NewReplayer creates a Replayer decoding messages according to config's serialization settings
+func NewReplayer(config *Config, handler EventHandler) (*Replayer, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("event handler cannot be nil")
+	}
+
+	r := &Replayer{handler: handler}
+	decoder, err := newMessageDecoder(config, &r.metrics)
+	if err != nil {
+		return nil, err
+	}
+	r.decoder = decoder
+	return r, nil
+}
+
+// ReplayLine decodes and handles one line of a dump, a RecordedMessage or a bare message value.
+// Handler failures are logged and counted, not returned.
+func (r *Replayer) ReplayLine(data []byte) {
+	replayMessage(r.decoder, &r.metrics, recordedToMessage(data), r.handler)
+}
+
+// Stats returns the counts of the lines replayed so far
+func (r *Replayer) Stats() ReplayStats {
+	return r.metrics.replayStats()
+}
+
+// ReplayTopic runs a range of a partition through the parser and handler. It reads without a
+// consumer group, so the engine's committed offsets are left untouched.
+func ReplayTopic(ctx context.Context, config *Config, rng ReplayRange, handler EventHandler) (ReplayStats, error) {
+	if handler == nil {
+		return ReplayStats{}, fmt.Errorf("event handler cannot be nil")
+	}
+	if rng.Topic == "" {
+		return ReplayStats{}, fmt.Errorf("topic is required")
+	}
+
+	var metrics readerMetrics
+	decoder, err := newMessageDecoder(config, &metrics)
+	if err != nil {
+		return ReplayStats{}, err
+	}
+
+	// Resolve the end of the range up front so the replay terminates on a live topic
+	conn, err := kafka.DialLeader(ctx, "tcp", config.Broker, rng.Topic, rng.Partition)
+	if err != nil {
+		return ReplayStats{}, fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	lastOffset, err := conn.ReadLastOffset()
+	conn.Close()
+	if err != nil {
+		return ReplayStats{}, fmt.Errorf("failed to read last offset: %w", err)
+	}
+	end := lastOffset
+	if rng.EndOffset > 0 && rng.EndOffset < end {
+		end = rng.EndOffset
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   []string{config.Broker},
+		Topic:     rng.Topic,
+		Partition: rng.Partition,
+		MaxBytes:  10e6, // 10MB
+	})
+	defer r.Close()
+
+	switch {
+	case !rng.Since.IsZero():
+		err = r.SetOffsetAt(ctx, rng.Since)
+	case rng.StartOffset > 0:
+		err = r.SetOffset(rng.StartOffset)
+	default:
+		err = r.SetOffset(kafka.FirstOffset)
+	}
+	if err != nil {
+		return ReplayStats{}, fmt.Errorf("failed to seek: %w", err)
+	}
+
+	replayLogger.Info("Replaying partition", "topic", rng.Topic, "partition", rng.Partition, "end_offset", end)
+
+	for {
+		if r.Offset() >= end {
+			return metrics.replayStats(), nil
+		}
+
+		m, err := r.ReadMessage(ctx)
+		if err != nil {
+			return metrics.replayStats(), fmt.Errorf("failed to read message: %w", err)
+		}
+		if !rng.Until.IsZero() && m.Time.After(rng.Until) {
+			return metrics.replayStats(), nil
+		}
+
+		replayMessage(decoder, &metrics, m, handler)
+
+		if m.Offset+1 >= end {
+			return metrics.replayStats(), nil
+		}
+	}
+}
+
+// replayMessage decodes and handles m, logging handler failures instead of stopping the replay
+func replayMessage(decoder *messageDecoder, metrics *readerMetrics, m kafka.Message, handler EventHandler) {
+	event := decoder.decode(m)
+	if event == nil {
+		return
+	}
+
+	if err := handler(event); err != nil {
+		metrics.handlerErrors.Add(1)
+		replayLogger.Error("Error in event handler", "event", event, "error", err)
+	}
+}
+
+// recordedToMessage converts a dump line into a Kafka message
+func recordedToMessage(data []byte) kafka.Message {
+	var rec RecordedMessage
+	if err := json.Unmarshal(data, &rec); err != nil || (rec.Value == nil && rec.ValueBase64 == nil) {
+		return kafka.Message{Value: data}
+	}
+
+	m := kafka.Message{
+		Topic:     rec.Topic,
+		Partition: rec.Partition,
+		Offset:    rec.Offset,
+		Time:      rec.Time,
+		Key:       rec.KeyBase64,
+		Value:     rec.ValueBase64,
+	}
+	if rec.Key != nil {
+		m.Key = rec.Key
+	}
+	if rec.Value != nil {
+		m.Value = rec.Value
+	}
+
+	return m
+}
+
+func (m *readerMetrics) replayStats() ReplayStats {
+	return ReplayStats{
+		Messages:      m.messages.Load(),
+		Tombstones:    m.tombstones.Load(),
+		ParseErrors:   m.parseErrors.Load(),
+		HandlerErrors: m.handlerErrors.Load(),
+	}
+}
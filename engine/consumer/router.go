@@ -0,0 +1,162 @@
+package consumer
+
+import (
+	"errors"
+	"sync"
+)
+
+// Debezium operation codes found in Event.Operation
+const (
+	OpCreate   = "c"
+	OpUpdate   = "u"
+	OpDelete   = "d"
+	OpSnapshot = "r"
+)
+
+// DefaultRouter is the Router used by `engine run`. Packages plug in handlers from init, e.g.
+//
+//	func init() {
+//	    consumer.OnTable("addresses").OnCreate(watchAddress).OnDelete(unwatchAddress)
+//	}
+var DefaultRouter = NewRouter()
+
+// OnTable registers operation handlers for table on DefaultRouter
+func OnTable(table string) *TableRoute {
+	return DefaultRouter.OnTable(table)
+}
+
+// Router dispatches events to handlers registered per table, per table and operation, or per topic.
+// Table handlers take precedence over topic handlers; unmatched events go to the fallback.
+//
+// Example usage:
+//
+//	router := consumer.NewRouter().
+//	    HandleTable("users", handleUser).
+//	    HandleTable("addresses", handleAddress)
+//
+//	router.OnTable("rules").OnCreate(compileRule).OnDelete(dropRule)
+//
+//	consumer.Read(ctx, kafkaManager, router.Handle)
+type Router struct {
+	mu         sync.RWMutex
+	tables     map[string]EventHandler
+	operations map[string]map[string][]EventHandler // table -> operation -> handlers
+	topics     map[string]EventHandler
+	fallback   EventHandler
+}
+
+// TableRoute registers handlers for the operations of one table, see Router.OnTable
+type TableRoute struct {
+	router *Router
+	table  string
+}
+
+// NewRouter creates an empty Router; unmatched events are dropped until a fallback is set
+func NewRouter() *Router {
+	return &Router{
+		tables:     make(map[string]EventHandler),
+		operations: make(map[string]map[string][]EventHandler),
+		topics:     make(map[string]EventHandler),
+	}
+}
+
+// OnTable returns a TableRoute for registering per operation handlers on table. Unlike HandleTable,
+// handlers are added rather than replaced, so several components can handle the same operation;
+// they run in registration order.
+func (r *Router) OnTable(table string) *TableRoute {
+	return &TableRoute{router: r, table: table}
+}
+
+// On registers handler for op (e.g. OpCreate) events of the table
+func (t *TableRoute) On(op string, handler EventHandler) *TableRoute {
+	r := t.router
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ops, ok := r.operations[t.table]
+	if !ok {
+		ops = make(map[string][]EventHandler)
+		r.operations[t.table] = ops
+	}
+	ops[op] = append(ops[op], handler)
+	return t
+}
+
+// OnCreate registers handler for inserted rows
+func (t *TableRoute) OnCreate(handler EventHandler) *TableRoute {
+	return t.On(OpCreate, handler)
+}
+
+// OnUpdate registers handler for updated rows
+func (t *TableRoute) OnUpdate(handler EventHandler) *TableRoute {
+	return t.On(OpUpdate, handler)
+}
+
+// OnDelete registers handler for deleted rows
+func (t *TableRoute) OnDelete(handler EventHandler) *TableRoute {
+	return t.On(OpDelete, handler)
+}
+
+// OnSnapshot registers handler for rows read during the connector's initial snapshot
+func (t *TableRoute) OnSnapshot(handler EventHandler) *TableRoute {
+	return t.On(OpSnapshot, handler)
+}
+
+// OnChange registers handler for every operation of the table
+func (t *TableRoute) OnChange(handler EventHandler) *TableRoute {
+	for _, op := range []string{OpCreate, OpUpdate, OpDelete, OpSnapshot} {
+		t.On(op, handler)
+	}
+	return t
+}
+
+// HandleTable registers handler for events whose Source.Table equals table
+func (r *Router) HandleTable(table string, handler EventHandler) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tables[table] = handler
+	return r
+}
+
+// HandleTopic registers handler for events read from topic
+func (r *Router) HandleTopic(topic string, handler EventHandler) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics[topic] = handler
+	return r
+}
+
+// Fallback registers handler for events no table or topic handler matched
+func (r *Router) Fallback(handler EventHandler) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = handler
+	return r
+}
+
+// Handle dispatches event to the matching handlers, it satisfies EventHandler. The table handler and
+// every operation handler of the table run in turn; their errors are joined.
+func (r *Router) Handle(event *Event) error {
+	r.mu.RLock()
+	var handlers []EventHandler
+	if handler, ok := r.tables[event.Source.Table]; ok {
+		handlers = append(handlers, handler)
+	}
+	handlers = append(handlers, r.operations[event.Source.Table][event.Operation]...)
+	if len(handlers) == 0 {
+		if handler, ok := r.topics[event.Topic]; ok {
+			handlers = append(handlers, handler)
+		} else if r.fallback != nil {
+			handlers = append(handlers, r.fallback)
+		}
+	}
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
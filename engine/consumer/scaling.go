@@ -0,0 +1,65 @@
+package consumer
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// scaling reports whether the worker pool scales with consumer lag
+func (km *KafkaManager) scaling() bool {
+	return km.config.MaxWorkers > km.config.Workers
+}
+
+// monitorLag checks the consumer group lag every ScaleInterval until ctx is done, recording it for
+// GetStats. When scaling, it also stores the number of workers the lag calls for in target, one
+// worker per LagPerWorker messages within Workers and MaxWorkers; target is nil otherwise. The pool
+// grows at once but shrinks by one worker per check, so a briefly idle topic does not undo a scale
+// up. Lag beyond what MaxWorkers can absorb is logged as a hint to run more engine instances.
+func (km *KafkaManager) monitorLag(ctx context.Context, target *atomic.Int64) {
+	ticker := time.NewTicker(km.config.ScaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		offsets, err := km.GroupOffsets(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("Failed to check consumer lag", "error", err)
+			}
+			continue
+		}
+
+		var lag int64
+		for _, o := range offsets {
+			lag += o.Lag
+		}
+		km.metrics.lag.Store(lag)
+		km.metrics.lagCheckedAt.Store(time.Now().UnixNano())
+		if target == nil {
+			continue
+		}
+
+		desired := int((lag + km.config.LagPerWorker - 1) / km.config.LagPerWorker)
+		km.metrics.desiredWorkers.Store(int64(desired))
+
+		if desired > km.config.MaxWorkers {
+			logger.Warn("Consumer lag exceeds what the maximum workers absorb, consider adding engine instances",
+				"lag", lag, "desired_workers", desired, "max_workers", km.config.MaxWorkers)
+		}
+
+		current := int(target.Load())
+		next := min(max(desired, km.config.Workers), km.config.MaxWorkers)
+		if next < current {
+			next = current - 1
+		}
+		if next != current {
+			target.Store(int64(next))
+		}
+	}
+}
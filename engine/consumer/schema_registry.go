@@ -0,0 +1,115 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegisteredSchema is a schema as returned by the Confluent Schema Registry
+type RegisteredSchema struct {
+	ID         int               `json:"-"`
+	Schema     string            `json:"schema"`
+	SchemaType string            `json:"schemaType,omitempty"` // empty means AVRO
+	References []SchemaReference `json:"references,omitempty"`
+}
+
+// SchemaReference points to another subject/version a schema depends on
+type SchemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// SchemaRegistryClient fetches schemas from a Confluent Schema Registry and caches them by ID.
+// Schemas are immutable once registered, so cached entries never need to be invalidated.
+type SchemaRegistryClient struct {
+	baseURL    string
+	httpClient *http.Client
+	mu         sync.RWMutex
+	cache      map[int]*RegisteredSchema
+}
+
+// NewSchemaRegistryClient creates a registry client for the given base URL
+func NewSchemaRegistryClient(baseURL string) (*SchemaRegistryClient, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("schema registry URL cannot be empty")
+	}
+
+	return &SchemaRegistryClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cache:      make(map[int]*RegisteredSchema),
+	}, nil
+}
+
+// GetSchemaByID returns the schema registered under id, fetching it on first use
+func (c *SchemaRegistryClient) GetSchemaByID(ctx context.Context, id int) (*RegisteredSchema, error) {
+	c.mu.RLock()
+	schema, ok := c.cache[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema = &RegisteredSchema{}
+	if err := c.get(ctx, fmt.Sprintf("/schemas/ids/%d", id), schema); err != nil {
+		return nil, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+	schema.ID = id
+
+	c.mu.Lock()
+	c.cache[id] = schema
+	c.mu.Unlock()
+
+	return schema, nil
+}
+
+// GetSchemaBySubject returns the schema registered under a subject version, used to resolve references
+func (c *SchemaRegistryClient) GetSchemaBySubject(ctx context.Context, subject string, version int) (*RegisteredSchema, error) {
+	var res struct {
+		ID int `json:"id"`
+		RegisteredSchema
+	}
+	path := fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version)
+	if err := c.get(ctx, path, &res); err != nil {
+		return nil, fmt.Errorf("failed to fetch schema for subject %s version %d: %w", subject, version, err)
+	}
+
+	schema := res.RegisteredSchema
+	schema.ID = res.ID
+
+	c.mu.Lock()
+	c.cache[schema.ID] = &schema
+	c.mu.Unlock()
+
+	return &schema, nil
+}
+
+// get performs a GET request against the registry and decodes the JSON response into out
+func (c *SchemaRegistryClient) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("schema registry returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,95 @@
+package testing_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	cdctest "github.com/ahsansaif47/blockchain-address-watcher/engine/consumer/testing"
+)
+
+// postgresUpdate is an update envelope as the PostgreSQL connector 2.7 sends it with
+// schemas.enable=false, from the Debezium documentation
+var postgresUpdate = filepath.Join("testdata", "postgres_update.json")
+
+// shape maps each key of a JSON object to the JSON type of its value, descending into objects
+func shape(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("not a JSON object: %v", err)
+	}
+
+	s := make(map[string]any, len(fields))
+	for key, raw := range fields {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			t.Fatalf("invalid value of %s: %v", key, err)
+		}
+		switch value.(type) {
+		case map[string]any:
+			s[key] = shape(t, raw)
+		case nil:
+			s[key] = "null"
+		default:
+			s[key] = reflect.TypeOf(value).Kind().String()
+		}
+	}
+	return s
+}
+
+func TestParseConnectorEnvelope(t *testing.T) {
+	data, err := os.ReadFile(postgresUpdate)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	event, err := consumer.ParseMessage(data)
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if event.Operation != "u" || event.Source.Table != "customers" {
+		t.Errorf("event = %q on %q, want \"u\" on customers", event.Operation, event.Source.Table)
+	}
+	if event.Source.Snapshot != "false" {
+		t.Errorf("Source.Snapshot = %q, want \"false\"", event.Source.Snapshot)
+	}
+	if want := `["24023119","24023128"]`; event.Source.Sequence != want {
+		t.Errorf("Source.Sequence = %q, want %q", event.Source.Sequence, want)
+	}
+	if string(event.Source.TxId) != "556" || event.Source.Lsn != 24023128 {
+		t.Errorf("Source position = txId %s lsn %d, want txId 556 lsn 24023128", event.Source.TxId, event.Source.Lsn)
+	}
+}
+
+func TestBuildersMatchConnectorEnvelope(t *testing.T) {
+	data, err := os.ReadFile(postgresUpdate)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	row := map[string]any{"id": 1, "first_name": "Anne", "last_name": "Kretchmar", "email": "annek@noanswer.org"}
+
+	tests := []struct {
+		name string
+		msg  *cdctest.Message
+	}{
+		{name: "default", msg: cdctest.Update("customers", row, row)},
+		{name: "with position", msg: cdctest.Update("customers", row, row).WithTxID(557).WithLSN(24023200)},
+	}
+
+	want := shape(t, data)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.msg.Value()
+			if err != nil {
+				t.Fatalf("Value() error = %v", err)
+			}
+			if got := shape(t, value); !reflect.DeepEqual(got, want) {
+				t.Fatalf("envelope shape = %v, want the connector's %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Package testing builds Debezium messages for unit testing EventHandlers without a Kafka
+// cluster. Messages are built for a table and operation, optionally flattened as the
+// ExtractNewRecordState SMT would, and parsed into events exactly as consumer.Read parses them.
+//
+// Import it under another name to keep the standard library's testing package available:
+//
+//	import cdctest "github.com/ahsansaif47/blockchain-address-watcher/engine/consumer/testing"
+//
+//	func TestHandleUser(t *testing.T) {
+//		user := &objects.User{Id: "7d0f...", Email: "a@example.com"}
+//		err := cdctest.Handle(t, handleUser, cdctest.Create("users", user).WithKey(map[string]any{"id": user.Id}))
+//		...
+//	}
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/segmentio/kafka-go"
+)
+
+// TopicPrefix is the Debezium topic.prefix of the default topics, "<prefix>.<schema>.<table>"
+const TopicPrefix = "sub-users-db"
+
+// TB is the subset of testing.TB the helpers need
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// Message is a Debezium message under construction. Rows can be structs with json tags (e.g.
+// *objects.User) or maps, and are encoded the way the JsonConverter encodes them.
+type Message struct {
+	table     string
+	topic     string
+	operation string
+	before    any
+	after     any
+	source    consumer.SourceInfo
+	timestamp time.Time
+	key       map[string]any
+	schema    *consumer.DebeziumSchema
+	offset    int64
+	partition int
+	unwrapped bool
+	tombstone bool
+}
+
+// Create returns an insert ("c") of after into table
+func Create(table string, after any) *Message {
+	return newMessage(table, "c", nil, after)
+}
+
+// Update returns an update ("u") of a row in table from before to after
+func Update(table string, before, after any) *Message {
+	return newMessage(table, "u", before, after)
+}
+
+// Delete returns a delete ("d") of before from table
+func Delete(table string, before any) *Message {
+	return newMessage(table, "d", before, nil)
+}
+
+// Snapshot returns a snapshot read ("r") of after from table
+func Snapshot(table string, after any) *Message {
+	m := newMessage(table, "r", nil, after)
+	m.source.Snapshot = "true"
+	return m
+}
+
+// Tombstone returns the null-value message Debezium emits after deleting the row with key
+func Tombstone(table string, key map[string]any) *Message {
+	m := newMessage(table, "", nil, nil)
+	m.key = key
+	m.tombstone = true
+	return m
+}
+
+// defaultLSN and defaultTxID are the source position of messages built without WithLSN and WithTxID
+const (
+	defaultLSN  = 24023128
+	defaultTxID = 556
+)
+
+func newMessage(table, operation string, before, after any) *Message {
+	m := &Message{
+		table:     table,
+		topic:     fmt.Sprintf("%s.public.%s", TopicPrefix, table),
+		operation: operation,
+		before:    before,
+		after:     after,
+		source: consumer.SourceInfo{
+			Version:   "2.7.0.Final",
+			Connector: "postgresql",
+			Name:      TopicPrefix,
+			Snapshot:  "false",
+			Db:        "postgres",
+			Schema:    "public",
+			Table:     table,
+		},
+	}
+	m.At(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	m.WithTxID(defaultTxID)
+	m.WithLSN(defaultLSN)
+	return m
+}
+
+// WithKey sets the primary key columns sent as the message key
+func (m *Message) WithKey(key map[string]any) *Message {
+	m.key = key
+	return m
+}
+
+// WithTopic overrides the default "<TopicPrefix>.public.<table>" topic
+func (m *Message) WithTopic(topic string) *Message {
+	m.topic = topic
+	return m
+}
+
+// WithOffset sets the partition and offset the message is read from
+func (m *Message) WithOffset(partition int, offset int64) *Message {
+	m.partition, m.offset = partition, offset
+	return m
+}
+
+// At sets the source commit and Debezium processing time, which default to 2025-01-01 UTC
+func (m *Message) At(t time.Time) *Message {
+	m.timestamp = t
+	m.source.TsMs = t.UnixMilli()
+	m.source.TsUs = t.UnixMicro()
+	m.source.TsNs = t.UnixNano()
+	return m
+}
+
+// WithTxID sets the source transaction ID, a number as the PostgreSQL connector sends it
+func (m *Message) WithTxID(txID int64) *Message {
+	m.source.TxId = json.RawMessage(strconv.FormatInt(txID, 10))
+	return m
+}
+
+// WithLSN sets the source log sequence number, and the sequence of the last committed and the
+// current LSN the PostgreSQL connector sends with it
+func (m *Message) WithLSN(lsn int64) *Message {
+	m.source.Lsn = lsn
+	m.source.Sequence = fmt.Sprintf(`[null,"%d"]`, lsn)
+	return m
+}
+
+// WithSchema wraps the payload in a schema/payload envelope, as connectors with
+// `schemas.enable=true` do, so values are converted by the schema's semantic types
+func (m *Message) WithSchema(schema consumer.DebeziumSchema) *Message {
+	m.schema = &schema
+	return m
+}
+
+// Unwrapped flattens the message as the ExtractNewRecordState SMT configured with
+// `add.fields=op,table,db,schema,lsn,txId,source.ts_ms,ts_ms` and `delete.handling.mode=rewrite`
+func (m *Message) Unwrapped() *Message {
+	m.unwrapped = true
+	return m
+}
+
+// Value returns the message value, nil for tombstones
+func (m *Message) Value() ([]byte, error) {
+	if m.tombstone {
+		return nil, nil
+	}
+
+	var payload any
+	if m.unwrapped {
+		row, err := m.unwrappedRow()
+		if err != nil {
+			return nil, err
+		}
+		payload = row
+	} else {
+		payload = map[string]any{
+			"before":      m.before,
+			"after":       m.after,
+			"source":      m.source,
+			"op":          m.operation,
+			"ts_ms":       m.timestamp.UnixMilli(),
+			"ts_us":       m.timestamp.UnixMicro(),
+			"ts_ns":       m.timestamp.UnixNano(),
+			"transaction": nil,
+		}
+	}
+
+	if m.schema != nil {
+		return json.Marshal(map[string]any{"schema": m.schema, "payload": payload})
+	}
+	return json.Marshal(payload)
+}
+
+// unwrappedRow merges the row with the metadata fields added by ExtractNewRecordState
+func (m *Message) unwrappedRow() (map[string]any, error) {
+	row, deleted := m.after, "false"
+	if m.operation == "d" {
+		row, deleted = m.before, "true"
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("unwrapped %s row is not an object: %w", m.table, err)
+	}
+	if fields == nil {
+		fields = make(map[string]any)
+	}
+
+	fields["__op"] = m.operation
+	fields["__deleted"] = deleted
+	fields["__table"] = m.source.Table
+	fields["__db"] = m.source.Db
+	fields["__schema"] = m.source.Schema
+	fields["__lsn"] = m.source.Lsn
+	fields["__source_ts_ms"] = m.source.TsMs
+	fields["__ts_ms"] = m.timestamp.UnixMilli()
+	if m.source.TxId != nil {
+		fields["__txId"] = m.source.TxId
+	}
+
+	return fields, nil
+}
+
+// Key returns the message key, nil when no key was set
+func (m *Message) Key() ([]byte, error) {
+	if m.key == nil {
+		return nil, nil
+	}
+	return json.Marshal(m.key)
+}
+
+// Kafka returns the message as read from the topic, e.g. to pass to consumer.RecordMessage
+func (m *Message) Kafka() (kafka.Message, error) {
+	value, err := m.Value()
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to encode value: %w", err)
+	}
+	key, err := m.Key()
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	return kafka.Message{
+		Topic:     m.topic,
+		Partition: m.partition,
+		Offset:    m.offset,
+		Key:       key,
+		Value:     value,
+		Time:      m.timestamp,
+	}, nil
+}
+
+// Event parses the message as consumer.Read does, failing the test if it does not parse.
+// Tombstones, which Read skips, fail the test as well.
+func (m *Message) Event(t TB) *consumer.Event {
+	t.Helper()
+
+	event, err := m.parse()
+	if err != nil {
+		t.Fatalf("failed to parse %s message for %s: %v", operationName(m), m.table, err)
+	}
+	return event
+}
+
+// parse builds the event Read would hand to a handler, returning consumer.ErrTombstone for tombstones
+func (m *Message) parse() (*consumer.Event, error) {
+	msg, err := m.Kafka()
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := consumer.ParseMessage(msg.Value)
+	if err != nil {
+		return nil, err
+	}
+	if event.Key, err = consumer.ParseKey(msg.Key); err != nil {
+		return nil, err
+	}
+	event.Topic = msg.Topic
+	event.Partition = msg.Partition
+	event.Offset = msg.Offset
+
+	return event, nil
+}
+
+// Handle runs handler on each message in order, as Read would, and returns the first handler
+// error. Tombstones are skipped, and messages that do not parse fail the test.
+func Handle(t TB, handler consumer.EventHandler, msgs ...*Message) error {
+	t.Helper()
+
+	for _, m := range msgs {
+		if m.tombstone {
+			continue
+		}
+		if err := handler(m.Event(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func operationName(m *Message) string {
+	if m.tombstone {
+		return "tombstone"
+	}
+	return fmt.Sprintf("%q", m.operation)
+}
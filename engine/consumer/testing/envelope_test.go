@@ -0,0 +1,121 @@
+package testing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	cdctest "github.com/ahsansaif47/blockchain-address-watcher/engine/consumer/testing"
+)
+
+// recorder is an EventHandler keeping the events it was given
+type recorder struct {
+	events []*consumer.Event
+	err    error
+}
+
+func (r *recorder) handle(event *consumer.Event) error {
+	r.events = append(r.events, event)
+	return r.err
+}
+
+// rowName returns the name column of a row decoded as map[string]any, "" for nil rows
+func rowName(t *testing.T, row any) string {
+	t.Helper()
+	if row == nil {
+		return ""
+	}
+	fields, ok := row.(map[string]any)
+	if !ok {
+		t.Fatalf("row is %T, want map[string]any", row)
+	}
+	name, _ := fields["name"].(string)
+	return name
+}
+
+func TestHandleBuilders(t *testing.T) {
+	before := map[string]any{"id": "w1", "name": "before"}
+	after := map[string]any{"id": "w1", "name": "after"}
+	key := map[string]any{"id": "w1"}
+
+	tests := []struct {
+		name      string
+		msg       *cdctest.Message
+		handled   bool
+		operation string
+		before    string
+		after     string
+		unwrapped bool
+	}{
+		{name: "create", msg: cdctest.Create("widgets", after), handled: true, operation: "c", after: "after"},
+		{name: "update", msg: cdctest.Update("widgets", before, after), handled: true, operation: "u", before: "before", after: "after"},
+		{name: "delete", msg: cdctest.Delete("widgets", before), handled: true, operation: "d", before: "before"},
+		{name: "snapshot", msg: cdctest.Snapshot("widgets", after), handled: true, operation: "r", after: "after"},
+		{name: "tombstone", msg: cdctest.Tombstone("widgets", key), handled: false},
+		{name: "unwrapped create", msg: cdctest.Create("widgets", after).Unwrapped(), handled: true, operation: "c", after: "after", unwrapped: true},
+		// ExtractNewRecordState drops the state before an update
+		{name: "unwrapped update", msg: cdctest.Update("widgets", before, after).Unwrapped(), handled: true, operation: "u", after: "after", unwrapped: true},
+		{name: "unwrapped delete", msg: cdctest.Delete("widgets", before).Unwrapped(), handled: true, operation: "d", before: "before", unwrapped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r recorder
+			msg := tt.msg.WithKey(key).WithOffset(2, 42)
+			if err := cdctest.Handle(t, r.handle, msg); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			if !tt.handled {
+				if len(r.events) != 0 {
+					t.Fatalf("handler called %d times, want none", len(r.events))
+				}
+				return
+			}
+			if len(r.events) != 1 {
+				t.Fatalf("handler called %d times, want 1", len(r.events))
+			}
+
+			event := r.events[0]
+			if event.Operation != tt.operation {
+				t.Errorf("Operation = %q, want %q", event.Operation, tt.operation)
+			}
+			if got := rowName(t, event.Before); got != tt.before {
+				t.Errorf("Before name = %q, want %q", got, tt.before)
+			}
+			if got := rowName(t, event.After); got != tt.after {
+				t.Errorf("After name = %q, want %q", got, tt.after)
+			}
+			if event.Unwrapped != tt.unwrapped {
+				t.Errorf("Unwrapped = %v, want %v", event.Unwrapped, tt.unwrapped)
+			}
+			if event.Source.Table != "widgets" {
+				t.Errorf("Source.Table = %q, want widgets", event.Source.Table)
+			}
+			if want := cdctest.TopicPrefix + ".public.widgets"; event.Topic != want {
+				t.Errorf("Topic = %q, want %q", event.Topic, want)
+			}
+			if event.Partition != 2 || event.Offset != 42 {
+				t.Errorf("position = %d/%d, want 2/42", event.Partition, event.Offset)
+			}
+			if got := event.KeyString(); got == "" {
+				t.Errorf("KeyString() is empty, want the key of %v", key)
+			}
+		})
+	}
+}
+
+func TestHandleStopsAtFirstError(t *testing.T) {
+	r := recorder{err: errors.New("handler failed")}
+	err := cdctest.Handle(t, r.handle,
+		cdctest.Create("widgets", map[string]any{"name": "first"}),
+		cdctest.Create("widgets", map[string]any{"name": "second"}),
+	)
+
+	if !errors.Is(err, r.err) {
+		t.Fatalf("Handle() error = %v, want %v", err, r.err)
+	}
+	if len(r.events) != 1 {
+		t.Fatalf("handler called %d times, want 1", len(r.events))
+	}
+}
@@ -0,0 +1,119 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tracecontext"
+)
+
+// UpdateGoldenEnv names the environment variable that makes Golden rewrite golden files
+// instead of comparing against them, e.g. `UPDATE_GOLDEN=1 go test ./...`
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Golden compares got, encoded as indented JSON, with the golden file at path (conventionally
+// under testdata/). A missing file fails the test unless UPDATE_GOLDEN is set.
+func Golden(t TB, path string, got any) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode golden value for %s: %v", path, err)
+	}
+	data = append(data, '\n')
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(want, data) {
+		t.Fatalf("%s does not match (run with %s=1 to update it)\ngot:\n%s\nwant:\n%s", path, UpdateGoldenEnv, data, want)
+	}
+}
+
+// goldenMessage is the stable JSON form of an emitted message
+type goldenMessage struct {
+	Topic   string            `json:"topic"`
+	Key     json.RawMessage   `json:"key,omitempty"`
+	Value   json.RawMessage   `json:"value,omitempty"`
+	Raw     []byte            `json:"value_base64,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Time    *time.Time        `json:"time,omitempty"`
+}
+
+// GoldenEmitted compares the messages event emitted with the golden file at path. JSON keys and
+// values are stored inline, and the traceparent header, which differs between runs, is dropped.
+func GoldenEmitted(t TB, path string, event *consumer.Event) {
+	t.Helper()
+
+	emitted := event.Emitted()
+	msgs := make([]goldenMessage, 0, len(emitted))
+	for _, m := range emitted {
+		msgs = append(msgs, newGoldenMessage(m))
+	}
+	Golden(t, path, msgs)
+}
+
+func newGoldenMessage(m transport.Message) goldenMessage {
+	g := goldenMessage{Topic: m.Topic}
+	if json.Valid(m.Key) {
+		g.Key = m.Key
+	}
+	if json.Valid(m.Value) {
+		g.Value = m.Value
+	} else {
+		g.Raw = m.Value
+	}
+	for k, v := range m.Headers {
+		if k == tracecontext.TraceparentHeader {
+			continue
+		}
+		if g.Headers == nil {
+			g.Headers = make(map[string]string)
+		}
+		g.Headers[k] = v
+	}
+	if !m.Time.IsZero() {
+		g.Time = &m.Time
+	}
+	return g
+}
+
+// WriteFixtures writes msgs to path in the NDJSON format read by consumer.Replay and
+// `engine simulate --fixtures`, so fixtures built in tests can be replayed against a running setup
+func WriteFixtures(t TB, path string, msgs ...*Message) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	for _, m := range msgs {
+		km, err := m.Kafka()
+		if err != nil {
+			t.Fatalf("failed to build %s message for %s: %v", operationName(m), m.table, err)
+		}
+		if err := consumer.RecordMessage(&buf, km); err != nil {
+			t.Fatalf("failed to record message: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create fixtures directory: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write fixtures: %v", err)
+	}
+}
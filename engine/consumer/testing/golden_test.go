@@ -0,0 +1,84 @@
+package testing_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	cdctest "github.com/ahsansaif47/blockchain-address-watcher/engine/consumer/testing"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+)
+
+// emitAlert emits an alert for every renamed widget, as a handler behind Transactional would
+func emitAlert(event *consumer.Event) error {
+	after, _ := event.After.(map[string]any)
+	event.Emit(transport.Message{
+		Topic:   "widget-alerts",
+		Key:     []byte(fmt.Sprintf(`{"id":%q}`, after["id"])),
+		Value:   []byte(fmt.Sprintf(`{"type":"widget.renamed","name":%q}`, after["name"])),
+		Headers: map[string]string{"source": event.Source.Table},
+		Time:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	return nil
+}
+
+func TestGoldenEmitted(t *testing.T) {
+	msg := cdctest.Update("widgets",
+		map[string]any{"id": "w1", "name": "before"},
+		map[string]any{"id": "w1", "name": "after"},
+	)
+	event := msg.Event(t)
+	if err := emitAlert(event); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	cdctest.GoldenEmitted(t, filepath.Join("testdata", "widget_renamed.golden.json"), event)
+}
+
+// fatalRecorder is a TB recording Fatalf instead of stopping the test
+type fatalRecorder struct {
+	failures []string
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestGoldenUpdateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "value.golden.json")
+	value := map[string]any{"name": "after", "count": 2}
+
+	// Without the file the comparison fails and asks for UPDATE_GOLDEN
+	var missing fatalRecorder
+	cdctest.Golden(&missing, path, value)
+	if len(missing.failures) == 0 || !strings.Contains(missing.failures[0], cdctest.UpdateGoldenEnv) {
+		t.Fatalf("Golden() without a file failures = %q, want one naming %s", missing.failures, cdctest.UpdateGoldenEnv)
+	}
+
+	// With UPDATE_GOLDEN the file is written, directories included
+	t.Setenv(cdctest.UpdateGoldenEnv, "1")
+	cdctest.Golden(t, path, value)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	if want := "{\n  \"count\": 2,\n  \"name\": \"after\"\n}\n"; string(data) != want {
+		t.Fatalf("golden file = %q, want %q", data, want)
+	}
+
+	// Without it the same value matches the written file, and a changed one does not
+	t.Setenv(cdctest.UpdateGoldenEnv, "")
+	cdctest.Golden(t, path, value)
+
+	var changed fatalRecorder
+	cdctest.Golden(&changed, path, map[string]any{"name": "changed", "count": 2})
+	if len(changed.failures) != 1 || !strings.Contains(changed.failures[0], "does not match") {
+		t.Fatalf("Golden() with a changed value failures = %q, want one mismatch", changed.failures)
+	}
+}
@@ -0,0 +1,120 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ResolveTopics returns the de-duplicated set of topics to consume: Topic, Topics,
+// and every topic currently on the broker matching TopicPrefix or TopicPattern
+func (km *KafkaManager) ResolveTopics() ([]string, error) {
+	seen := make(map[string]bool)
+	var topics []string
+
+	add := func(topic string) {
+		if topic == "" || seen[topic] {
+			return
+		}
+		seen[topic] = true
+		topics = append(topics, topic)
+	}
+
+	add(km.config.Topic)
+	for _, topic := range km.config.Topics {
+		add(topic)
+	}
+
+	if km.hasDynamicTopics() {
+		existing, err := km.listTopics()
+		if err != nil {
+			return nil, err
+		}
+		for _, topic := range existing {
+			if km.matchesSubscription(topic) {
+				add(topic)
+			}
+		}
+	}
+
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("no topics configured or matched")
+	}
+
+	return topics, nil
+}
+
+// listTopics returns the sorted names of all topics known to the broker
+func (km *KafkaManager) listTopics() ([]string, error) {
+	conn, err := km.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := conn.ReadPartitions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topic metadata: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var topics []string
+	for _, p := range partitions {
+		if !seen[p.Topic] {
+			seen[p.Topic] = true
+			topics = append(topics, p.Topic)
+		}
+	}
+	sort.Strings(topics)
+
+	return topics, nil
+}
+
+// hasDynamicTopics reports whether the subscription depends on the topics present on the broker
+func (km *KafkaManager) hasDynamicTopics() bool {
+	return km.config.TopicPrefix != "" || km.topicRegex != nil
+}
+
+// matchesSubscription reports whether topic matches the configured prefix or pattern
+func (km *KafkaManager) matchesSubscription(topic string) bool {
+	// Skip Kafka internal topics such as __consumer_offsets and Debezium heartbeats
+	if strings.HasPrefix(topic, "__") {
+		return false
+	}
+	if km.config.TopicPrefix != "" && strings.HasPrefix(topic, km.config.TopicPrefix) {
+		return true
+	}
+	return km.topicRegex != nil && km.topicRegex.MatchString(topic)
+}
+
+// watchTopics periodically re-resolves the subscription and calls onChange once it differs from current
+func (km *KafkaManager) watchTopics(ctx context.Context, current []string, onChange func()) {
+	ticker := time.NewTicker(km.config.TopicRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			topics, err := km.ResolveTopics()
+			if err != nil {
+				managerLogger.Warn("Failed to refresh topics", "error", err)
+				continue
+			}
+			if !slices.Equal(sortedCopy(topics), sortedCopy(current)) {
+				managerLogger.Info("Subscribed topics changed", "topics", topics)
+				onChange()
+				return
+			}
+		}
+	}
+}
+
+func sortedCopy(s []string) []string {
+	out := slices.Clone(s)
+	sort.Strings(out)
+	return out
+}
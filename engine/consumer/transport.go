@@ -0,0 +1,91 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	"github.com/segmentio/kafka-go"
+)
+
+// ReadTransport consumes Debezium messages from a non-Kafka broker (see package transport) and
+// processes them with handler, decoding them like Read according to config's serialization
+// settings. Events are handled as the transport delivers them, before the message is acknowledged,
+// so a handler error rejects the message, which the broker redelivers or dead-letters; unparseable
+// messages and tombstones are acknowledged and skipped. Connection failures are retried with backoff from config.RetryDelay up to
+// config.MaxRetryDelay until ctx is done.
+//
+// Example usage:
+//
+//	t, err := rabbitmq.Dial(rabbitmq.Config{URL: url, Exchange: "debezium", Queue: "engine"})
+//	...
+//	consumer.ReadTransport(ctx, cfg, t, handleEvent)
+func ReadTransport(ctx context.Context, config *Config, source transport.Consumer, handler EventHandler) error {
+	if source == nil {
+		return fmt.Errorf("transport cannot be nil")
+	}
+	if handler == nil {
+		return fmt.Errorf("event handler cannot be nil")
+	}
+
+	var metrics readerMetrics
+	decoder, err := newMessageDecoder(config, &metrics)
+	if err != nil {
+		return err
+	}
+
+	handle := func(ctx context.Context, m transport.Message) error {
+		event := decoder.decode(transportToMessage(m))
+		if event == nil {
+			return nil
+		}
+		event.ctx = ctx
+		if err := handler(event); err != nil {
+			metrics.handlerErrors.Add(1)
+			handlerFailed(event, err)
+			return err
+		}
+		return nil
+	}
+
+	retryDelay := config.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = 2 * time.Second
+	}
+	maxDelay := max(retryDelay, config.MaxRetryDelay)
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		err := source.Consume(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// A consumer that ran for a while before failing starts over from the base delay
+		if time.Since(start) > maxDelay {
+			attempt = 0
+		}
+
+		delay := backoff(retryDelay, maxDelay, attempt)
+		logger.Error("Transport consumer stopped, retrying", "retry_in", delay.Round(time.Millisecond), "error", err)
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// transportToMessage converts a transport message for the Kafka message decoder
+func transportToMessage(m transport.Message) kafka.Message {
+	km := kafka.Message{
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Key:       m.Key,
+		Value:     m.Value,
+		Time:      m.Time,
+	}
+	for k, v := range m.Headers {
+		km.Headers = append(km.Headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return km
+}
@@ -0,0 +1,129 @@
+package consumer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// unwrappedMetadata holds the fields the ExtractNewRecordState SMT adds next to the row
+// when configured with `add.fields` and `delete.handling.mode=rewrite`
+type unwrappedMetadata struct {
+	Operation  string          `json:"__op"`
+	Deleted    json.RawMessage `json:"__deleted"`
+	Table      string          `json:"__table"`
+	Db         string          `json:"__db"`
+	Schema     string          `json:"__schema"`
+	Lsn        int64           `json:"__lsn"`
+	TxId       json.RawMessage `json:"__txId"`
+	SourceTsMs int64           `json:"__source_ts_ms"`
+	TsMs       int64           `json:"__ts_ms"`
+}
+
+// isUnwrappedPayload reports whether a payload was flattened by ExtractNewRecordState.
+// Flattened payloads either carry the SMT metadata fields or lack the envelope's op/source keys.
+func isUnwrappedPayload(fields map[string]json.RawMessage) bool {
+	if _, ok := fields["__op"]; ok {
+		return true
+	}
+	if _, ok := fields["__deleted"]; ok {
+		return true
+	}
+
+	_, hasOp := fields["op"]
+	_, hasSource := fields["source"]
+	return !hasOp && !hasSource
+}
+
+// parseUnwrappedPayload builds an Event from a flattened row.
+// Without `__op` the operation is inferred: "d" when `__deleted` is true, "u" (upsert) otherwise.
+func parseUnwrappedPayload(data []byte, schema *DebeziumSchema) (*Event, error) {
+	var meta unwrappedMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal unwrapped metadata: %w", err)
+	}
+
+	// Without add.fields=table the table can still be recovered from the schema name
+	table := meta.Table
+	var fields []DebeziumSchemaField
+	if schema != nil {
+		fields = schema.Fields
+		if table == "" {
+			table = tableFromSchemaName(schema.Name)
+		}
+	}
+
+	row, err := decodeRow(table, data, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode unwrapped row: %w", err)
+	}
+
+	deleted := isTrue(meta.Deleted)
+	operation := meta.Operation
+	switch {
+	case deleted:
+		operation = "d"
+	case operation == "":
+		operation = "u"
+	}
+
+	event := &Event{
+		Operation: operation,
+		Source: SourceInfo{
+			Db:     meta.Db,
+			Schema: meta.Schema,
+			Table:  table,
+			TsMs:   meta.SourceTsMs,
+			TxId:   meta.TxId,
+			Lsn:    meta.Lsn,
+		},
+		Timestamp: unwrappedTimestamp(meta),
+		Unwrapped: true,
+	}
+
+	// Validate event data
+	switch operation {
+	case "c", "r", "u":
+		event.After = row
+	case "d":
+		// With delete.handling.mode=rewrite the row holds the last known state
+		event.Before = row
+	default:
+		return nil, &UnknownOperationError{Operation: operation}
+	}
+
+	return event, nil
+}
+
+// unwrappedTimestamp prefers the Debezium processing time and falls back to the source commit time
+func unwrappedTimestamp(meta unwrappedMetadata) time.Time {
+	if meta.TsMs != 0 {
+		return time.UnixMilli(meta.TsMs)
+	}
+	if meta.SourceTsMs != 0 {
+		return time.UnixMilli(meta.SourceTsMs)
+	}
+	return time.Time{}
+}
+
+// isTrue accepts both the string ("true") and boolean encodings of __deleted
+func isTrue(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	b, err := strconv.ParseBool(unquote(raw))
+	return err == nil && b
+}
+
+// unquote returns the JSON value as a plain string, stripping quotes from string values
+func unquote(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
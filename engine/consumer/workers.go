@@ -0,0 +1,119 @@
+package consumer
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// workerPool runs the handler on a set of workers. Events with the same key always go to the same
+// worker so changes to a row are handled in order. submit blocks while MaxInFlight events are
+// queued or being handled, which pauses fetching until the handlers catch up. done is called after
+// each event is handled successfully, quarantined or given up on (see handleUntilDone); events
+// still failing when ctx is done are left uncommitted.
+//
+// The number of workers follows target, which the lag monitor adjusts (see monitorLag). Since
+// routing depends on the worker count, the pool drains before it is resized.
+type workerPool struct {
+	ctx     context.Context
+	km      *KafkaManager
+	handler EventHandler
+	done    func(event *Event)
+	queues  []chan *Event
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	target  atomic.Int64
+}
+
+func newWorkerPool(ctx context.Context, km *KafkaManager, handler EventHandler, done func(event *Event)) *workerPool {
+	p := &workerPool{
+		ctx:     ctx,
+		km:      km,
+		handler: handler,
+		done:    done,
+		slots:   make(chan struct{}, km.config.MaxInFlight),
+	}
+	p.target.Store(int64(km.config.Workers))
+	p.start(km.config.Workers)
+
+	return p
+}
+
+// start launches n workers
+func (p *workerPool) start(n int) {
+	p.queues = make([]chan *Event, n)
+	for i := range p.queues {
+		p.queues[i] = make(chan *Event, p.km.config.MaxInFlight)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	p.km.metrics.workers.Store(int64(n))
+}
+
+// submit queues event for its worker, blocking while the pool is at capacity. The pool is resized
+// first if the lag monitor changed its target.
+func (p *workerPool) submit(ctx context.Context, event *Event) error {
+	if n := int(p.target.Load()); n != len(p.queues) {
+		p.resize(n)
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.km.metrics.backpressurePauses.Add(1)
+		logger.Debug("Pausing fetch until handlers catch up", "in_flight", cap(p.slots))
+
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	p.km.metrics.inFlight.Add(1)
+	p.queues[p.route(event)] <- event
+	return nil
+}
+
+// route picks a worker by hashing the event key, falling back to the partition for keyless tables
+func (p *workerPool) route(event *Event) int {
+	key := event.KeyString()
+	if key == "" {
+		key = event.Topic + "/" + strconv.Itoa(event.Partition)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+func (p *workerPool) work(queue <-chan *Event) {
+	defer p.wg.Done()
+
+	for event := range queue {
+		if handleUntilDone(p.ctx, &p.km.metrics, p.km.config.HandlerAttempts, p.handler, event) {
+			p.done(event)
+		}
+		p.km.metrics.inFlight.Add(-1)
+		<-p.slots
+	}
+}
+
+// resize waits for queued events to be handled and restarts the pool with n workers. It must be
+// called from the goroutine calling submit.
+func (p *workerPool) resize(n int) {
+	logger.Info("Scaling workers", "from", len(p.queues), "to", n)
+	p.close()
+	p.start(n)
+	p.km.metrics.scaleEvents.Add(1)
+}
+
+// close stops accepting events and waits for queued events to be handled
+func (p *workerPool) close() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
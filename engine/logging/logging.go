@@ -0,0 +1,169 @@
+// Package logging configures the engine's leveled, structured logger on log/slog. Components log
+// through loggers from For, which follow later calls to Setup, so they can be created in package
+// variables before the settings are loaded and keep working after a reload changes the level or
+// format. Setup also routes the standard log package and slog.Default through the same handler.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config holds the logger settings
+type Config struct {
+	// Level is the minimum level logged: debug, info (default), warn or error
+	Level string
+	// Format is text (default, logfmt style key=value pairs) or json
+	Format string
+	// SampleInitial is how many debug records with the same message are logged each second before
+	// sampling starts (default 100)
+	SampleInitial int
+	// SampleThereafter logs every SampleThereafter-th debug record with the same message within
+	// the second once SampleInitial is exceeded (default 100)
+	SampleThereafter int
+	// Output is where records are written (default os.Stderr)
+	Output io.Writer
+}
+
+// root is the handler currently configured by Setup, with a generation so derived handlers know
+// when to rebuild
+type root struct {
+	gen     uint64
+	handler slog.Handler
+}
+
+var current atomic.Pointer[root]
+
+func init() {
+	current.Store(&root{handler: newHandler(Config{})})
+	slog.SetDefault(slog.New(&handler{}))
+}
+
+// Setup replaces the handler of every logger with one built from config
+func Setup(config Config) error {
+	if _, err := ParseLevel(config.Level); err != nil {
+		return err
+	}
+	switch config.Format {
+	case "", FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("unsupported log format: %s", config.Format)
+	}
+
+	prev := current.Load()
+	current.Store(&root{gen: prev.gen + 1, handler: newHandler(config)})
+	return nil
+}
+
+// ParseLevel parses a level name, the empty name is info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level: %s", name)
+}
+
+// For returns the logger of a component (e.g. "reader"), which adds it as the component field
+//
+// Example usage:
+//
+//	var logger = logging.For("reader")
+//
+//	logger.Warn("Failed to commit offset", "topic", topic, "partition", partition, "error", err)
+func For(component string) *slog.Logger {
+	return slog.New(&handler{}).With("component", component)
+}
+
+// newHandler builds the handler for config, sampling debug records
+func newHandler(config Config) slog.Handler {
+	level, _ := ParseLevel(config.Level)
+	if config.Output == nil {
+		config.Output = os.Stderr
+	}
+	if config.SampleInitial <= 0 {
+		config.SampleInitial = 100
+	}
+	if config.SampleThereafter <= 0 {
+		config.SampleThereafter = 100
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if config.Format == FormatJSON {
+		h = slog.NewJSONHandler(config.Output, opts)
+	} else {
+		h = slog.NewTextHandler(config.Output, opts)
+	}
+
+	if level > slog.LevelDebug {
+		return h
+	}
+	return &sampler{next: h, counts: newSampleCounts(config.SampleInitial, config.SampleThereafter)}
+}
+
+// built caches the handler derived from one generation of the root handler
+type built struct {
+	gen     uint64
+	handler slog.Handler
+}
+
+// handler resolves to the current root handler with its attributes and groups applied, rebuilding
+// them only after Setup
+type handler struct {
+	ops   []func(slog.Handler) slog.Handler
+	cache atomic.Pointer[built]
+}
+
+func (h *handler) resolve() slog.Handler {
+	r := current.Load()
+	if b := h.cache.Load(); b != nil && b.gen == r.gen {
+		return b.handler
+	}
+
+	resolved := r.handler
+	for _, op := range h.ops {
+		resolved = op(resolved)
+	}
+	h.cache.Store(&built{gen: r.gen, handler: resolved})
+	return resolved
+}
+
+func (h *handler) derive(op func(slog.Handler) slog.Handler) *handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &handler{ops: append(ops, op)}
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.resolve().Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	return h.resolve().Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.derive(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return h.derive(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// sampler drops high-volume debug records: per message, the first initial records of each second
+// are logged and after that every thereafter-th. Other levels are never sampled.
+type sampler struct {
+	next   slog.Handler
+	counts *sampleCounts
+}
+
+func (s *sampler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.next.Enabled(ctx, level)
+}
+
+func (s *sampler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelInfo && !s.counts.allow(r.Message, r.Time) {
+		return nil
+	}
+	return s.next.Handle(ctx, r)
+}
+
+func (s *sampler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampler{next: s.next.WithAttrs(attrs), counts: s.counts}
+}
+
+func (s *sampler) WithGroup(name string) slog.Handler {
+	return &sampler{next: s.next.WithGroup(name), counts: s.counts}
+}
+
+// sampleCounts counts records per message within the current second, shared by every logger
+// derived from one handler
+type sampleCounts struct {
+	initial    int
+	thereafter int
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]int
+}
+
+func newSampleCounts(initial, thereafter int) *sampleCounts {
+	return &sampleCounts{initial: initial, thereafter: thereafter, counts: make(map[string]int)}
+}
+
+// allow reports whether a record with msg logged at t is kept
+func (c *sampleCounts) allow(msg string, t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.Sub(c.window) >= time.Second || t.Before(c.window) {
+		c.window = t
+		clear(c.counts)
+	}
+
+	n := c.counts[msg] + 1
+	c.counts[msg] = n
+	return n <= c.initial || (n-c.initial)%c.thereafter == 0
+}
@@ -1,21 +1,13 @@
 package main
 
 import (
-	"log"
+	"os"
 
-	"github.com/ahsansaif47/blockchain-address-watcher/engine/config"
-	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/cmd"
 )
 
 func main() {
-	config, err := config.ConsumerConfig()
-	if err != nil {
-		log.Fatalf("Error getting kafka manager config: %v", err)
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
 	}
-
-	km, err := consumer.NewKafkaManager(config)
-	if err != nil {
-		log.Fatalf("Error creating kafka manager: %v", err)
-	}
-	defer km.Close()
 }
@@ -0,0 +1,149 @@
+// Package metrics holds the engine's Prometheus counters and writes them in the Prometheus text
+// exposition format, served by the admin server's /metrics endpoint. Counters are created once,
+// at package initialization, and registered for the lifetime of the process.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// CounterVec is a counter partitioned by the values of its labels, e.g. per topic
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.RWMutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	count  atomic.Uint64
+}
+
+var registry struct {
+	mu       sync.Mutex
+	counters []*CounterVec
+}
+
+// NewCounterVec creates and registers a counter named name with the label names labels. It
+// panics when a counter of that name is already registered.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, series: make(map[string]*series)}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if slices.ContainsFunc(registry.counters, func(r *CounterVec) bool { return r.name == name }) {
+		panic("metrics: counter " + name + " registered twice")
+	}
+	registry.counters = append(registry.counters, c)
+	return c
+}
+
+// Inc adds one to the counter of the label values, given in the order of the label names
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds n to the counter of the label values, given in the order of the label names
+func (c *CounterVec) Add(n uint64, values ...string) {
+	if len(values) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: counter %s takes %d label values, got %d", c.name, len(c.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	c.mu.RLock()
+	s, ok := c.series[key]
+	c.mu.RUnlock()
+	if !ok {
+		c.mu.Lock()
+		if s, ok = c.series[key]; !ok {
+			s = &series{values: slices.Clone(values)}
+			c.series[key] = s
+		}
+		c.mu.Unlock()
+	}
+	s.count.Add(n)
+}
+
+// Value returns the counter of the label values
+func (c *CounterVec) Value(values ...string) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if s, ok := c.series[strings.Join(values, "\xff")]; ok {
+		return s.count.Load()
+	}
+	return 0
+}
+
+// write writes the counter's series sorted by their label values
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.RLock()
+	all := make([]*series, 0, len(c.series))
+	for _, s := range c.series {
+		all = append(all, s)
+	}
+	c.mu.RUnlock()
+	slices.SortFunc(all, func(a, b *series) int { return slices.Compare(a.values, b.values) })
+
+	fmt.Fprintf(w, "# HELP %s %s\n", c.name, escape(c.help, false))
+	fmt.Fprintf(w, "# TYPE %s counter\n", c.name)
+	for _, s := range all {
+		w.WriteString(c.name)
+		if len(c.labels) > 0 {
+			w.WriteByte('{')
+			for i, label := range c.labels {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				fmt.Fprintf(w, `%s="%s"`, label, escape(s.values[i], true))
+			}
+			w.WriteByte('}')
+		}
+		w.WriteByte(' ')
+		w.WriteString(strconv.FormatUint(s.count.Load(), 10))
+		w.WriteByte('\n')
+	}
+}
+
+// Write writes every registered counter to w in the Prometheus text exposition format
+func Write(w io.Writer) error {
+	registry.mu.Lock()
+	counters := slices.Clone(registry.counters)
+	registry.mu.Unlock()
+	slices.SortFunc(counters, func(a, b *CounterVec) int { return strings.Compare(a.name, b.name) })
+
+	bw := bufio.NewWriter(w)
+	for _, c := range counters {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the registered counters to Prometheus scrapes
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := Write(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// escape escapes backslashes and line feeds of help texts, and double quotes of label values
+func escape(s string, quote bool) string {
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	if quote {
+		r = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	}
+	return r.Replace(s)
+}
@@ -0,0 +1,16 @@
+package objects
+
+import "time"
+
+// Address is a row of the api-server's addresses table. A soft-deleted address has DeletedAt set
+// and must no longer be watched.
+type Address struct {
+	Id        string     `json:"id"`
+	UserId    string     `json:"user_id"`
+	Chain     string     `json:"chain"`
+	Address   string     `json:"address"`
+	Label     string     `json:"label"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,21 @@
+package objects
+
+import "time"
+
+// AlertRule is a row of the api-server's alert_rules table, with the columns the engine uses.
+// Advanced rules have their condition in Expression, a CEL expression (see package ruleexpr).
+// Rules are evaluated by descending Priority, and a matching rule with StopProcessing suppresses
+// the rules after it.
+type AlertRule struct {
+	Id             string     `json:"id"`
+	UserId         string     `json:"user_id"`
+	AddressId      *string    `json:"address_id"`
+	Name           string     `json:"name"`
+	Enabled        bool       `json:"enabled"`
+	Expression     *string    `json:"expression"`
+	Priority       int32      `json:"priority"`
+	StopProcessing bool       `json:"stop_processing"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at"`
+}
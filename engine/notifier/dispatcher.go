@@ -0,0 +1,306 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/rules"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watchlist"
+)
+
+// logger is used by the notifier
+var logger = logging.For("notifier")
+
+const (
+	// workers is the number of notifications delivered at once
+	workers = 4
+	// queueSize bounds the matches waiting for a worker; further matches are dropped
+	queueSize = 1000
+	// notifyTimeout bounds the delivery of a match to all its users
+	notifyTimeout = time.Minute
+)
+
+// Dispatcher delivers the confirmed matches of the chain watchers to the users watching their
+// addresses. Matches are queued without blocking the watchers, and dropped, counted, when the
+// queue is full.
+//
+// Example usage:
+//
+//	notifications := notifier.NewDispatcher(watched, notifier.NewStore(pool), config)
+//	notifications.Register(notifier.NewEmail(config))
+//	watchers.OnMatch(notifications.OnMatch)
+//	go notifications.Run(ctx)
+//	...
+//	notifications.Flush(shutdownCtx)
+type Dispatcher struct {
+	watched  *watchlist.Watchlist
+	store    *Store
+	config   func() Config
+	queue    chan watcher.Match
+	mu       sync.Mutex
+	channels map[string]Notifier
+
+	dropped    int
+	sent       map[string]int // channel -> notifications delivered
+	failed     map[string]int // channel -> notifications that failed
+	muted      int
+	skipped    int // notifications to users without any routed channel
+	unmatched  int // transfers matching none of the user's simple rules
+	ruleMuted  int // transfers whose rule is muted
+	cooling    int // transfers whose rule was notified within its cooldown
+	lastErrors map[string]string
+
+	notified map[string]time.Time // rule ID -> last notification of the rule
+}
+
+// NewDispatcher creates a Dispatcher notifying the watchers of watched, with their channels read
+// from store
+func NewDispatcher(watched *watchlist.Watchlist, store *Store, config func() Config) *Dispatcher {
+	return &Dispatcher{
+		watched:    watched,
+		store:      store,
+		config:     config,
+		queue:      make(chan watcher.Match, queueSize),
+		channels:   make(map[string]Notifier),
+		sent:       make(map[string]int),
+		failed:     make(map[string]int),
+		lastErrors: make(map[string]string),
+		notified:   make(map[string]time.Time),
+	}
+}
+
+// Register makes n the notifier of its channel, replacing the one registered before
+func (d *Dispatcher) Register(n Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.channels[n.Channel()] = n
+}
+
+// OnMatch queues m when it is confirmed, see watcher.Dispatcher.OnMatch
+func (d *Dispatcher) OnMatch(m watcher.Match) {
+	if m.Status != watcher.StatusConfirmed {
+		return
+	}
+	select {
+	case d.queue <- m:
+	default:
+		d.mu.Lock()
+		d.dropped++
+		n := d.dropped
+		d.mu.Unlock()
+		if n == 1 || n%100 == 0 {
+			logger.Warn("Notification queue is full, dropping transfers", "dropped", n)
+		}
+	}
+}
+
+// Run delivers the queued matches until ctx is done. Deliveries in flight then are finished, and
+// the matches still queued are left for Flush.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case m := <-d.queue:
+					d.deliver(context.WithoutCancel(ctx), m)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Flush delivers the matches still queued once Run returned, until ctx is done. Those left then
+// are dropped, counted.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				select {
+				case m := <-d.queue:
+					d.deliver(ctx, m)
+				default:
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	left := len(d.queue)
+	if left == 0 {
+		return nil
+	}
+	d.count(func() { d.dropped += left })
+	logger.Warn("Dropping the notifications still queued at shutdown", "dropped", left)
+	return ctx.Err()
+}
+
+// deliver notifies m to every user watching its address whose simple rules, if any, it matches
+func (d *Dispatcher) deliver(ctx context.Context, m watcher.Match) {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	cfg := d.config()
+	for _, w := range d.watched.Watchers(m.Chain, m.Address) {
+		muted, err := d.store.Muted(ctx, w.AddressID)
+		if err != nil {
+			logger.Error("Failed to notify transfer", "address_id", w.AddressID, "hash", m.Hash, "error", err)
+			continue
+		}
+		if muted {
+			d.count(func() { d.muted++ })
+			continue
+		}
+		conditions, err := d.store.Rules(ctx, w.UserID, w.AddressID)
+		if err != nil {
+			logger.Error("Failed to notify transfer", "address_id", w.AddressID, "hash", m.Hash, "error", err)
+			continue
+		}
+		n := newNotification(m, w.UserID, w.AddressID, w.Label, cfg.Severity)
+		if len(conditions) > 0 {
+			rule, ok := rules.First(conditions, transfer(m))
+			if !ok {
+				d.count(func() { d.unmatched++ })
+				continue
+			}
+			if rule.Muted {
+				d.count(func() { d.ruleMuted++ })
+				continue
+			}
+			if !d.cooledDown(rule) {
+				d.count(func() { d.cooling++ })
+				continue
+			}
+			n.RuleID, n.RuleName = rule.ID, rule.Name
+			if rule.Severity != "" {
+				n.Severity = rule.Severity
+			}
+		}
+		r, err := d.store.Recipient(ctx, w.UserID)
+		if err != nil {
+			logger.Error("Failed to notify transfer", "address_id", w.AddressID, "hash", m.Hash, "error", err)
+			continue
+		}
+		d.notify(ctx, cfg, r, n)
+	}
+}
+
+// transfer returns m as evaluated by the simple rules
+func transfer(m watcher.Match) rules.Transfer {
+	counterparty := m.From
+	if m.Direction == "out" {
+		counterparty = m.To
+	}
+	return rules.Transfer{
+		Chain:        m.Chain,
+		Direction:    m.Direction,
+		Value:        m.Value,
+		Token:        m.Token,
+		Counterparty: counterparty,
+	}
+}
+
+// cooledDown reports whether rule may be notified now, its cooldown having passed since it was
+// last notified, and if so records the notification. Cooldowns are kept in memory, so they start
+// over when the engine restarts.
+func (d *Dispatcher) cooledDown(rule rules.Condition) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	if last, ok := d.notified[rule.ID]; ok && now.Sub(last) < rule.Cooldown {
+		return false
+	}
+	if rule.Cooldown > 0 {
+		d.notified[rule.ID] = now
+	} else {
+		delete(d.notified, rule.ID)
+	}
+	return true
+}
+
+// notify delivers n to r over every channel routed for its severity which r has set up
+func (d *Dispatcher) notify(ctx context.Context, cfg Config, r Recipient, n Notification) {
+	d.mu.Lock()
+	var notifiers []Notifier
+	for _, channel := range cfg.Routes[n.Severity] {
+		if notifier, ok := d.channels[channel]; ok && r.Has(channel) {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	d.mu.Unlock()
+
+	if len(notifiers) == 0 {
+		d.count(func() { d.skipped++ })
+		logger.Debug("User has no channel routed for transfers", "user_id", r.UserID, "severity", n.Severity)
+		return
+	}
+	for _, notifier := range notifiers {
+		channel := notifier.Channel()
+		err := notifier.Notify(ctx, r, n)
+		if err != nil {
+			d.count(func() {
+				d.failed[channel]++
+				d.lastErrors[channel] = err.Error()
+			})
+			if errors.Is(err, ErrNotConfigured) {
+				logger.Warn("Channel is routed but not configured", "channel", channel)
+			} else {
+				logger.Error("Failed to notify transfer", "channel", channel, "user_id", r.UserID, "hash", n.Hash, "error", err)
+			}
+			continue
+		}
+		d.count(func() { d.sent[channel]++ })
+		logger.Info("Notified transfer", "channel", channel, "user_id", r.UserID, "chain", n.Chain, "hash", n.Hash)
+	}
+}
+
+// count runs fn, updating the stats, under the lock
+func (d *Dispatcher) count(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn()
+}
+
+// GetStats returns the notifications delivered and failed per channel, and the transfers dropped
+// or not notified, by reason
+func (d *Dispatcher) GetStats() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	channels := make(map[string]interface{}, len(d.channels))
+	for channel := range d.channels {
+		stats := map[string]interface{}{
+			"sent":   d.sent[channel],
+			"failed": d.failed[channel],
+		}
+		if err, ok := d.lastErrors[channel]; ok {
+			stats["last_error"] = err
+		}
+		channels[channel] = stats
+	}
+	return map[string]interface{}{
+		"channels": channels,
+		"queued":   len(d.queue),
+		"dropped":  d.dropped,
+		"muted":    d.muted,
+		"skipped":  d.skipped,
+		"rules": map[string]interface{}{
+			"unmatched": d.unmatched,
+			"muted":     d.ruleMuted,
+			"cooldown":  d.cooling,
+		},
+	}
+}
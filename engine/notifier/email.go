@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Email sends notifications as plain text emails through an SMTP server
+type Email struct {
+	config func() Config
+}
+
+// NewEmail creates an Email notifier sending through the SMTP server of config
+func NewEmail(config func() Config) *Email {
+	return &Email{config: config}
+}
+
+// Channel returns email
+func (e *Email) Channel() string {
+	return ChannelEmail
+}
+
+// Notify emails n to the verified address of r. net/smtp takes no context, so ctx only stops a
+// send that has not started.
+func (e *Email) Notify(ctx context.Context, r Recipient, n Notification) error {
+	cfg := e.config().SMTP
+	if cfg.Host == "" {
+		return fmt.Errorf("email: %w", ErrNotConfigured)
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM %q: %w", cfg.From, err)
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n",
+		from.String(), r.Email, mime.QEncoding.Encode("utf-8", n.Subject()), time.Now().Format(time.RFC1123Z))
+	qp := quotedprintable.NewWriter(&body)
+	if _, err := qp.Write([]byte(n.Text())); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	if err := smtp.SendMail(addr, auth, from.Address, []string{r.Email}, body.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", r.Email, err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Package notifier alerts users of the transfers of their watched addresses. A Dispatcher receives
+// the matches of the chain watchers, looks up the address rows watching each matched address, and
+// delivers a Notification to their users over every Notifier whose channel is routed for the
+// notifications' severity (NOTIFY_ROUTE_*) and which the user has set up: email to a verified
+// address, SMS to their phone number, and a POST to each of their verified webhooks.
+//
+// Transfers are notified once, when confirmed. Those of muted addresses are skipped, like the
+// api-server skips the deliveries of their alerts. Once a user has simple alert rules applying to
+// an address, only its transfers matching one of them are notified, with the severity of the
+// first in evaluation order; see rules.Condition. Transfers matching a muted rule, or one notified
+// within its cooldown, are skipped.
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+)
+
+// Channels of the notifiers
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+)
+
+// ErrNotConfigured fails the notifications of a channel whose credentials are not set
+var ErrNotConfigured = errors.New("channel is not configured")
+
+// Config configures the notifications. It is read on every delivery, so reloaded settings take
+// effect at once.
+type Config struct {
+	// Severity is the severity of the notifications of addresses without simple rules, info,
+	// warning or critical; those matching a rule have the rule's
+	Severity string
+	// Routes are the channels routed for each severity, of email, sms and webhook
+	Routes map[string][]string
+	SMTP   SMTPConfig
+	Twilio TwilioConfig
+	// WebhookTimeout bounds each POST to a webhook
+	WebhookTimeout time.Duration
+}
+
+// SMTPConfig holds the email server settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// TwilioConfig holds the SMS provider settings
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	FromNumber string
+}
+
+// Notification is a transfer of a watched address, notified to a user watching it
+type Notification struct {
+	// ID is the same for every channel and attempt, for receivers to deduplicate
+	ID        string
+	UserID    string
+	AddressID string
+	// Label is the address's nickname, empty when it has none
+	Label    string
+	Severity string
+	// RuleID and RuleName are those of the simple rule the transfer matched, empty when the user
+	// has none for the address
+	RuleID   string
+	RuleName string
+	watcher.Match
+}
+
+// newNotification returns the notification of m to the user of address row addressID
+func newNotification(m watcher.Match, userID, addressID, label, severity string) Notification {
+	key := strings.Join([]string{userID, m.Chain, m.Hash, strconv.FormatInt(m.LogIndex, 10), m.Address, m.Direction}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return Notification{
+		ID:        hex.EncodeToString(sum[:16]),
+		UserID:    userID,
+		AddressID: addressID,
+		Label:     label,
+		Severity:  severity,
+		Match:     m,
+	}
+}
+
+// Name returns the label of the address, or the address when it has none
+func (n Notification) Name() string {
+	if n.Label != "" {
+		return n.Label
+	}
+	return n.Address
+}
+
+// Asset returns the token contract, or the symbol of the chain's native currency
+func (n Notification) Asset() string {
+	if n.Token != "" {
+		return n.Token
+	}
+	if c, ok := chains.Get(n.Chain); ok {
+		return c.Native
+	}
+	return n.Chain
+}
+
+// Subject returns the subject of an email or the first line of a text message, e.g.
+// "[WARNING] Incoming transfer on Treasury"
+func (n Notification) Subject() string {
+	kind := "Incoming"
+	if n.Direction == "out" {
+		kind = "Outgoing"
+	}
+	return fmt.Sprintf("[%s] %s transfer on %s", strings.ToUpper(n.Severity), kind, n.Name())
+}
+
+// Text returns the body of an email
+func (n Notification) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", n.Subject())
+	fmt.Fprintf(&b, "Address: %s (%s)\n", n.Address, n.Chain)
+	fmt.Fprintf(&b, "Direction: %s\n", n.Direction)
+	fmt.Fprintf(&b, "Value: %s %s, in its smallest unit\n", n.Value, n.Asset())
+	fmt.Fprintf(&b, "From: %s\n", orNone(n.From))
+	fmt.Fprintf(&b, "To: %s\n", orNone(n.To))
+	fmt.Fprintf(&b, "Transaction: %s\n", n.Hash)
+	fmt.Fprintf(&b, "Block: %d, %s\n", n.BlockNumber, n.Time.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Status: %s\n", n.Status)
+	if n.RuleName != "" {
+		fmt.Fprintf(&b, "Rule: %s\n", n.RuleName)
+	}
+	return b.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Webhook is a verified, enabled webhook of a user
+type Webhook struct {
+	ID     string
+	URL    string
+	Secret string
+}
+
+// Recipient is a user with the channels they set up
+type Recipient struct {
+	UserID string
+	// Email is empty until the user verified it
+	Email    string
+	Phone    string
+	Webhooks []Webhook
+}
+
+// Has reports whether the recipient set up channel
+func (r Recipient) Has(channel string) bool {
+	switch channel {
+	case ChannelEmail:
+		return r.Email != ""
+	case ChannelSMS:
+		return r.Phone != ""
+	case ChannelWebhook:
+		return len(r.Webhooks) > 0
+	}
+	return false
+}
+
+// Notifier delivers notifications over one channel
+type Notifier interface {
+	// Channel returns the channel, email, sms or webhook
+	Channel() string
+	// Notify delivers n to r, who has set up the channel
+	Notify(ctx context.Context, r Recipient, n Notification) error
+}
@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// twilioURL is Twilio's Messages resource of an account
+const twilioURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// SMS sends notifications as text messages through Twilio
+type SMS struct {
+	config func() Config
+	client *http.Client
+}
+
+// NewSMS creates an SMS notifier sending from the Twilio account of config
+func NewSMS(config func() Config) *SMS {
+	return &SMS{config: config, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Channel returns sms
+func (s *SMS) Channel() string {
+	return ChannelSMS
+}
+
+// Notify texts the subject and transaction of n to the phone number of r
+func (s *SMS) Notify(ctx context.Context, r Recipient, n Notification) error {
+	cfg := s.config().Twilio
+	if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.FromNumber == "" {
+		return fmt.Errorf("sms: %w", ErrNotConfigured)
+	}
+
+	form := url.Values{
+		"To":   {r.Phone},
+		"From": {cfg.FromNumber},
+		"Body": {fmt.Sprintf("%s\n%s %s on %s, tx %s", n.Subject(), n.Value, n.Asset(), n.Chain, n.Hash)},
+	}
+	endpoint := fmt.Sprintf(twilioURL, url.PathEscape(cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(cfg.AccountSID, cfg.AuthToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	var reply struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&reply); err == nil && reply.Message != "" {
+		return fmt.Errorf("twilio answered %s: %s (code %d)", resp.Status, reply.Message, reply.Code)
+	}
+	return fmt.Errorf("twilio answered %s", resp.Status)
+}
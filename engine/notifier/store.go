@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/rules"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store reads the users' channels, the mutes of their addresses and their simple alert rules from
+// the api-server's database. They are read on every notification rather than from change events,
+// so webhook secrets stay out of the change stream and a channel verified a moment ago is used at
+// once.
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// NewStore creates a Store reading from the database of pool
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// Recipient returns user userID with their verified email, phone number and verified, enabled
+// webhooks. A user deleted since has no channels.
+func (s *Store) Recipient(ctx context.Context, userID string) (Recipient, error) {
+	r := Recipient{UserID: userID}
+	err := s.pool.QueryRow(ctx, `
+		SELECT CASE WHEN email_verified_at IS NOT NULL THEN email ELSE '' END, COALESCE(phone_number, '')
+		FROM users WHERE id = $1`,
+		userID).Scan(&r.Email, &r.Phone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r, nil
+	}
+	if err != nil {
+		return r, fmt.Errorf("failed to read user: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, url, secret FROM webhooks
+		WHERE user_id = $1 AND enabled AND verified_at IS NOT NULL AND deleted_at IS NULL
+		ORDER BY created_at`,
+		userID)
+	if err != nil {
+		return r, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var w Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret); err != nil {
+			return r, fmt.Errorf("failed to read webhooks: %w", err)
+		}
+		r.Webhooks = append(r.Webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return r, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+	return r, nil
+}
+
+// Muted reports whether address row addressID is muted now
+func (s *Store) Muted(ctx context.Context, addressID string) (bool, error) {
+	var muted bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT muted_at IS NOT NULL AND (muted_until IS NULL OR muted_until > NOW())
+		FROM addresses WHERE id = $1`,
+		addressID).Scan(&muted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read address mute: %w", err)
+	}
+	return muted, nil
+}
+
+// Rules returns the enabled simple rules of user userID applying to address row addressID, its
+// own and those of all their addresses, in evaluation order: by descending priority, then by
+// creation. Advanced rules are evaluated by package rules from change events instead.
+func (s *Store) Rules(ctx context.Context, userID, addressID string) ([]rules.Condition, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT r.id, r.name, r.severity, r.direction, r.min_value::text, COALESCE(r.token_address, ''),
+			COALESCE(r.token_preset, ''), r.cooldown_seconds,
+			r.muted_at IS NOT NULL AND (r.muted_until IS NULL OR r.muted_until > NOW()),
+			ARRAY(SELECT c.address FROM alert_rule_counterparties c WHERE c.rule_id = r.id ORDER BY c.address)
+		FROM alert_rules r
+		WHERE r.user_id = $1 AND (r.address_id = $2 OR r.address_id IS NULL)
+			AND r.enabled AND r.expression IS NULL AND r.deleted_at IS NULL
+		ORDER BY r.priority DESC, r.created_at, r.id`,
+		userID, addressID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	var conditions []rules.Condition
+	for rows.Next() {
+		var c rules.Condition
+		var minValue string
+		var cooldown int32
+		if err := rows.Scan(&c.ID, &c.Name, &c.Severity, &c.Direction, &minValue, &c.TokenAddress,
+			&c.TokenPreset, &cooldown, &c.Muted, &c.Counterparties); err != nil {
+			return nil, fmt.Errorf("failed to read alert rules: %w", err)
+		}
+		// min_value is a whole NUMERIC, so its text is a decimal integer
+		c.MinValue, _ = new(big.Int).SetString(minValue, 10)
+		c.Cooldown = time.Duration(cooldown) * time.Second
+		conditions = append(conditions, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	return conditions, nil
+}
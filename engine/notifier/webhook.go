@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// EventTransfer is the type of the bodies posted for transfers
+const EventTransfer = "transfer"
+
+// Transfer is a notification as posted to webhook endpoints
+type Transfer struct {
+	AddressID   string    `json:"address_id"`
+	Chain       string    `json:"chain"`
+	Address     string    `json:"address"`
+	Label       string    `json:"label,omitempty"`
+	Direction   string    `json:"direction"`
+	Hash        string    `json:"hash"`
+	LogIndex    int64     `json:"log_index"`
+	BlockNumber int64     `json:"block_number"`
+	From        string    `json:"from"`
+	To          string    `json:"to,omitempty"`
+	Value       string    `json:"value"`
+	Token       string    `json:"token_address,omitempty"`
+	Status      string    `json:"status"`
+	Severity    string    `json:"severity"`
+	RuleID      string    `json:"rule_id,omitempty"`
+	RuleName    string    `json:"rule_name,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// Event is the body of a POST, like the api-server's webhook events
+type Event struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Transfer *Transfer `json:"transfer"`
+}
+
+// Webhooks posts notifications to the verified webhooks of the users. Every POST is signed like the
+// api-server's: an X-Webhook-Signature header, "v1=" and the hex HMAC-SHA256 of
+// "<X-Webhook-Timestamp>.<body>" keyed with the webhook's secret, so endpoints check both the same
+// way.
+type Webhooks struct {
+	config func() Config
+	client *http.Client
+}
+
+// NewWebhooks creates a notifier posting to webhooks, each POST bounded by config's WebhookTimeout
+func NewWebhooks(config func() Config) *Webhooks {
+	return &Webhooks{config: config, client: &http.Client{}}
+}
+
+// Channel returns webhook
+func (w *Webhooks) Channel() string {
+	return ChannelWebhook
+}
+
+// Notify posts n to every webhook of r, failing with the errors of those that did not accept it
+func (w *Webhooks) Notify(ctx context.Context, r Recipient, n Notification) error {
+	body, err := json.Marshal(Event{
+		ID:   n.ID,
+		Type: EventTransfer,
+		Transfer: &Transfer{
+			AddressID:   n.AddressID,
+			Chain:       n.Chain,
+			Address:     n.Address,
+			Label:       n.Label,
+			Direction:   n.Direction,
+			Hash:        n.Hash,
+			LogIndex:    n.LogIndex,
+			BlockNumber: n.BlockNumber,
+			From:        n.From,
+			To:          n.To,
+			Value:       n.Value.String(),
+			Token:       n.Token,
+			Status:      n.Status,
+			Severity:    n.Severity,
+			RuleID:      n.RuleID,
+			RuleName:    n.RuleName,
+			OccurredAt:  n.Time,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var errs []error
+	for _, hook := range r.Webhooks {
+		if err := w.post(ctx, hook, n.ID, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", hook.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Webhooks) post(ctx context.Context, hook Webhook, id string, body []byte) error {
+	if timeout := w.config().WebhookTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blockchain-address-watcher-webhooks")
+	req.Header.Set("X-Webhook-ID", id)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", sign(hook.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return nil
+}
+
+// sign returns the X-Webhook-Signature of body posted at timestamp
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+)
+
+// logger is used by the outbox relay
+var logger = logging.For("outbox_relay")
+
+// IDHeader carries the outbox ID of relayed messages. The relay publishes at least once, so a
+// message may be delivered twice if the engine stops between publishing and deleting it;
+// consumers can drop duplicates by this ID.
+const IDHeader = "outbox-id"
+
+// RelayConfig controls how often and how much the relay publishes
+type RelayConfig struct {
+	// PollInterval is how long the relay waits once the outbox is empty (default 1s)
+	PollInterval time.Duration
+	// BatchSize is the number of messages published per transaction (default 100)
+	BatchSize int
+	// Retention is how long processed event records are kept for deduplication (default 7 days)
+	Retention time.Duration
+}
+
+// Relay publishes stored messages in order through a producer
+type Relay struct {
+	store    *Store
+	producer transport.Producer
+	config   RelayConfig
+
+	relayed atomic.Int64
+	errors  atomic.Int64
+}
+
+// NewRelay creates a Relay publishing the messages in store through producer
+func NewRelay(store *Store, producer transport.Producer, config RelayConfig) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 1 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.Retention <= 0 {
+		config.Retention = 7 * 24 * time.Hour
+	}
+	return &Relay{store: store, producer: producer, config: config}
+}
+
+// Run relays messages until ctx is done, pruning old processed event records once an hour
+func (r *Relay) Run(ctx context.Context) {
+	logger.Info("Relay started")
+	lastPrune := time.Time{}
+
+	for {
+		if time.Since(lastPrune) > time.Hour {
+			if n, err := r.store.Prune(ctx, r.config.Retention); err != nil {
+				logger.Error("Failed to prune processed events", "error", err)
+			} else if n > 0 {
+				logger.Info("Pruned processed events", "count", n)
+			}
+			lastPrune = time.Now()
+		}
+
+		n, err := r.store.Relay(ctx, r.config.BatchSize, r.publish)
+		if ctx.Err() != nil {
+			logger.Info("Relay stopped")
+			return
+		}
+		if err != nil {
+			r.errors.Add(1)
+			logger.Error("Relay failed", "error", err)
+		}
+		r.relayed.Add(int64(n))
+
+		// Keep draining while full batches are available
+		if err == nil && n == r.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			logger.Info("Relay stopped")
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// publish sends records in order, one Publish call per run of consecutive records with the same topic
+func (r *Relay) publish(ctx context.Context, records []Record) error {
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].Topic == records[start].Topic {
+			end++
+		}
+
+		msgs := make([]transport.Message, 0, end-start)
+		for _, rec := range records[start:end] {
+			m := rec.Message
+			if m.Headers == nil {
+				m.Headers = make(map[string]string, 1)
+			}
+			m.Headers[IDHeader] = strconv.FormatInt(rec.ID, 10)
+			msgs = append(msgs, m)
+		}
+		if err := r.producer.Publish(ctx, records[start].Topic, msgs...); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// GetStats returns relay counters and the current outbox backlog
+func (r *Relay) GetStats() map[string]interface{} {
+	stats := map[string]interface{}{
+		"relayed": r.relayed.Load(),
+		"errors":  r.errors.Load(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if pending, err := r.store.Pending(ctx); err == nil {
+		stats["pending"] = pending
+	}
+	return stats
+}
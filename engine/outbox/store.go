@@ -0,0 +1,167 @@
+// Package outbox stores messages emitted by event handlers in Postgres, in the same transaction
+// that records the event as processed, and relays them to a broker afterwards. Together with
+// consumer.Transactional this gives alert publishing transactional semantics on Kafka clients
+// without transaction support: a message is published if and only if its event was committed.
+// Tables are created by the api-server migrations (000002_create_engine_outbox).
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store is the Postgres outbox, implementing consumer.Outbox
+type Store struct {
+	pool  *pgxpool.Pool
+	group string
+}
+
+var _ consumer.Outbox = (*Store)(nil)
+
+// Record is a stored message waiting to be published
+type Record struct {
+	ID int64
+	transport.Message
+}
+
+// NewStore creates a Store recording events processed by consumer group
+func NewStore(pool *pgxpool.Pool, group string) *Store {
+	return &Store{pool: pool, group: group}
+}
+
+// Processed reports whether the event was already committed
+func (s *Store) Processed(ctx context.Context, event *consumer.Event) (bool, error) {
+	var exists bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM engine_processed_events
+			WHERE consumer_group = $1 AND topic = $2 AND partition = $3 AND "offset" = $4
+		)`, s.group, event.Topic, event.Partition, event.Offset).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check processed event: %w", err)
+	}
+	return exists, nil
+}
+
+// Commit marks the event as processed and stores msgs in one transaction, returning false when
+// the event was already committed
+func (s *Store) Commit(ctx context.Context, event *consumer.Event, msgs []transport.Message) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO engine_processed_events (consumer_group, topic, partition, "offset")
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`, s.group, event.Topic, event.Partition, event.Offset)
+	if err != nil {
+		return false, fmt.Errorf("failed to record processed event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, m := range msgs {
+		headers, err := json.Marshal(m.Headers)
+		if err != nil {
+			return false, fmt.Errorf("failed to encode headers: %w", err)
+		}
+		if m.Headers == nil {
+			headers = []byte("{}")
+		}
+		batch.Queue(`INSERT INTO engine_outbox (topic, key, value, headers) VALUES ($1, $2, $3, $4)`,
+			m.Topic, m.Key, m.Value, headers)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return false, fmt.Errorf("failed to store outbox messages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// Relay locks up to limit of the oldest stored messages, passes them to publish and deletes them
+// when it succeeds. Locked rows are skipped by other engine instances relaying concurrently.
+// It returns the number of messages relayed.
+func (s *Store) Relay(ctx context.Context, limit int, publish func(ctx context.Context, records []Record) error) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, topic, key, value, headers, created_at
+		FROM engine_outbox
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	records, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Record, error) {
+		var r Record
+		var headers []byte
+		err := row.Scan(&r.ID, &r.Topic, &r.Key, &r.Value, &headers, &r.Time)
+		if err == nil {
+			err = json.Unmarshal(headers, &r.Headers)
+		}
+		return r, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(ctx, records); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, len(records))
+	for i, r := range records {
+		ids[i] = r.ID
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM engine_outbox WHERE id = ANY($1)`, ids); err != nil {
+		return 0, fmt.Errorf("failed to delete relayed messages: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(records), nil
+}
+
+// Prune deletes processed event records older than retention. They only need to outlive the
+// window in which an event can be redelivered, i.e. until its Kafka offset is committed.
+func (s *Store) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM engine_processed_events
+		WHERE consumer_group = $1 AND processed_at < $2`, s.group, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune processed events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Pending returns the number of messages waiting to be relayed
+func (s *Store) Pending(ctx context.Context) (int64, error) {
+	var n int64
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM engine_outbox`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count outbox messages: %w", err)
+	}
+	return n, nil
+}
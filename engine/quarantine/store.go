@@ -0,0 +1,41 @@
+// Package quarantine stores samples of the change events the engine failed to decode or handle in
+// Postgres, for inspecting them after the fact instead of piecing them together from log lines.
+// The table is created by the api-server migrations (000034_create_engine_quarantine).
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store is the Postgres quarantine, implementing consumer.Quarantine
+type Store struct {
+	pool      *pgxpool.Pool
+	retention time.Duration
+}
+
+var _ consumer.Quarantine = (*Store)(nil)
+
+// NewStore creates a Store keeping samples for retention
+func NewStore(pool *pgxpool.Pool, retention time.Duration) *Store {
+	return &Store{pool: pool, retention: retention}
+}
+
+// Quarantine stores m, deleting the samples older than the retention in the same statement
+func (s *Store) Quarantine(ctx context.Context, m consumer.QuarantinedMessage) error {
+	_, err := s.pool.Exec(ctx, `
+		WITH expired AS (
+			DELETE FROM engine_quarantine WHERE created_at < $8
+		)
+		INSERT INTO engine_quarantine (stage, topic, partition, "offset", key, value, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		m.Stage, m.Topic, m.Partition, m.Offset, m.Key, m.Value, m.Error, time.Now().Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to store quarantined message: %w", err)
+	}
+	return nil
+}
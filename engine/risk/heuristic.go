@@ -0,0 +1,217 @@
+package risk
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/ruleexpr"
+)
+
+// Weights of the Heuristic's signals, added up and capped at 100
+const (
+	weightSanctioned   = 100
+	weightMixer        = 90
+	weightBridge       = 30
+	weightNewAddress   = 20
+	weightHighVelocity = 25
+	// Known exchanges are custodial and screened, their other signals only add up to this
+	exchangeCap = 10
+)
+
+// HeuristicConfig tunes the age and velocity signals of the Heuristic
+type HeuristicConfig struct {
+	// NewAddressAge is the age below which a counterparty is new (default 24h). A counterparty is
+	// as old as the first transaction it was observed in.
+	NewAddressAge time.Duration
+	// VelocityWindow and VelocityLimit flag counterparties observed in more than VelocityLimit
+	// transactions within VelocityWindow (default 50 in 1h), typical of peel chains and drainers
+	VelocityWindow time.Duration
+	VelocityLimit  int
+	// MaxTracked bounds the counterparties whose activity is tracked (default 100000); the least
+	// recently seen are forgotten first
+	MaxTracked int
+}
+
+// activity is what the Heuristic observed of a counterparty
+type activity struct {
+	firstSeen time.Time
+	lastSeen  time.Time
+	recent    []time.Time // within the velocity window of lastSeen
+}
+
+// Heuristic scores counterparties locally, without an external provider: sanctioned addresses
+// and mixers score high, bridges raise the score as they hide where funds came from, as do
+// counterparties first seen recently or moving funds at a high rate. It only knows what it was
+// given with SetKnown and Observe.
+//
+// Example usage:
+//
+//	scorer := risk.NewHeuristic(risk.HeuristicConfig{})
+//	scorer.SetKnown(known)
+//	scorer.Observe(risk.Counterparty{Chain: "ethereum", Address: tx.From, At: blockTime})
+//	score, _ := scorer.Score(ctx, risk.Counterparty{Chain: "ethereum", Address: tx.From, At: blockTime})
+type Heuristic struct {
+	config HeuristicConfig
+
+	mu       sync.RWMutex
+	labels   map[string][]string // address -> categories
+	activity map[cacheKey]*activity
+}
+
+var _ Scorer = (*Heuristic)(nil)
+
+// NewHeuristic creates a Heuristic without known entities or observed activity
+func NewHeuristic(config HeuristicConfig) *Heuristic {
+	if config.NewAddressAge <= 0 {
+		config.NewAddressAge = 24 * time.Hour
+	}
+	if config.VelocityWindow <= 0 {
+		config.VelocityWindow = time.Hour
+	}
+	if config.VelocityLimit <= 0 {
+		config.VelocityLimit = 50
+	}
+	if config.MaxTracked <= 0 {
+		config.MaxTracked = 100000
+	}
+	return &Heuristic{
+		config:   config,
+		labels:   make(map[string][]string),
+		activity: make(map[cacheKey]*activity),
+	}
+}
+
+// SetKnown replaces the known entities the labels come from
+func (h *Heuristic) SetKnown(known ruleexpr.Known) {
+	labels := make(map[string][]string)
+	add := func(category string, addresses []string) {
+		for _, a := range addresses {
+			a = normalize(a)
+			labels[a] = append(labels[a], category)
+		}
+	}
+	add("sanctioned", known.Sanctioned)
+	add("mixer", known.Mixers)
+	add("bridge", known.Bridges)
+	add("exchange", known.Exchanges)
+
+	h.mu.Lock()
+	h.labels = labels
+	h.mu.Unlock()
+}
+
+// Observe records a transaction of c, at c.At, for its age and velocity
+func (h *Heuristic) Observe(c Counterparty) {
+	if c.Address == "" {
+		return
+	}
+	key := cacheKey{chain: c.Chain, address: normalize(c.Address)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	a, ok := h.activity[key]
+	if !ok {
+		if len(h.activity) >= h.config.MaxTracked {
+			h.forgetOldest()
+		}
+		a = &activity{firstSeen: c.At}
+		h.activity[key] = a
+	}
+	if c.At.Before(a.firstSeen) {
+		a.firstSeen = c.At
+	}
+	if c.At.After(a.lastSeen) {
+		a.lastSeen = c.At
+	}
+
+	// Keep the transactions within the window of the latest one
+	cutoff := a.lastSeen.Add(-h.config.VelocityWindow)
+	recent := a.recent[:0]
+	for _, t := range a.recent {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if c.At.After(cutoff) {
+		recent = append(recent, c.At)
+	}
+	a.recent = recent
+}
+
+// forgetOldest drops the least recently seen tenth of the tracked counterparties, h.mu must be held
+func (h *Heuristic) forgetOldest() {
+	n := max(len(h.activity)/10, 1)
+	for ; n > 0; n-- {
+		var oldest cacheKey
+		var oldestSeen time.Time
+		first := true
+		for k, a := range h.activity {
+			if first || a.lastSeen.Before(oldestSeen) {
+				oldest, oldestSeen, first = k, a.lastSeen, false
+			}
+		}
+		delete(h.activity, oldest)
+	}
+}
+
+// Score scores c from its labels and observed activity
+func (h *Heuristic) Score(ctx context.Context, c Counterparty) (Score, error) {
+	key := cacheKey{chain: c.Chain, address: normalize(c.Address)}
+
+	h.mu.RLock()
+	labels := h.labels[key.address]
+	var a activity
+	a2, tracked := h.activity[key]
+	if tracked {
+		a = *a2
+		a.recent = nil
+		for _, t := range a2.recent {
+			if !t.After(c.At) && t.After(c.At.Add(-h.config.VelocityWindow)) {
+				a.recent = append(a.recent, t)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	var value int64
+	var reasons []string
+	exchange := false
+	for _, label := range labels {
+		switch label {
+		case "sanctioned":
+			value += weightSanctioned
+		case "mixer":
+			value += weightMixer
+		case "bridge":
+			value += weightBridge
+		case "exchange":
+			exchange = true
+			continue
+		}
+		reasons = append(reasons, label)
+	}
+
+	// Never observed before this transaction, or first observed recently
+	if !tracked || c.At.Sub(a.firstSeen) < h.config.NewAddressAge {
+		value += weightNewAddress
+		reasons = append(reasons, "new_address")
+	}
+	if len(a.recent) > h.config.VelocityLimit {
+		value += weightHighVelocity
+		reasons = append(reasons, "high_velocity")
+	}
+
+	if exchange && value > exchangeCap {
+		value = exchangeCap
+		reasons = []string{"exchange"}
+	}
+	return newScore(value, reasons, "heuristic"), nil
+}
+
+// normalize returns address in the lowercase it is compared in
+func normalize(address string) string {
+	return strings.ToLower(address)
+}
@@ -0,0 +1,87 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPProvider scores counterparties with an external risk provider, asked with
+//
+//	GET <url>?chain=ethereum&address=0x...
+//	Authorization: Bearer <token>
+//
+// and answering {"score": 87, "reasons": ["mixer"]}, score being 0 to 100. Providers with
+// another API are put behind a small adapter serving this one. Wrap it in a Cache.
+type HTTPProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+var _ Scorer = (*HTTPProvider)(nil)
+
+// NewHTTPProvider creates a provider asking providerURL, sending token when it is not empty.
+// Requests taking longer than timeout (default 5s) fail.
+func NewHTTPProvider(providerURL, token string, timeout time.Duration) *HTTPProvider {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &HTTPProvider{
+		url:    providerURL,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// providerResponse is the provider's answer
+type providerResponse struct {
+	Score   *float64 `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// Score asks the provider for the score of c
+func (p *HTTPProvider) Score(ctx context.Context, c Counterparty) (Score, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return Score{}, fmt.Errorf("invalid risk provider URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("chain", c.Chain)
+	q.Set("address", c.Address)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Score{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Score{}, fmt.Errorf("risk provider request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Score{}, fmt.Errorf("risk provider answered %d: %s", resp.StatusCode, body)
+	}
+
+	var res providerResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&res); err != nil {
+		return Score{}, fmt.Errorf("failed to decode risk provider response: %w", err)
+	}
+	if res.Score == nil {
+		return Score{}, fmt.Errorf("risk provider response has no score")
+	}
+
+	return newScore(int64(*res.Score+0.5), res.Reasons, "http"), nil
+}
@@ -0,0 +1,174 @@
+// Package risk scores the counterparties of watched addresses' transactions, from 0 (no known
+// risk) to 100, so alerts can carry the score and rules can fire on funds from risky sources, e.g.
+//
+//	tx.direction == "in" && tx.counterparty_risk_level == "high"
+//
+// Scores come from a Scorer: the local Heuristic, from the known entity labels and the
+// counterparty's age and velocity, or an external provider through HTTP. A counterparty that
+// cannot be scored is left unscored, has(tx.counterparty_risk) is then false.
+package risk
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+)
+
+// logger is used by the scorers
+var logger = logging.For("risk")
+
+// Risk levels of scores
+const (
+	LevelLow    = "low"
+	LevelMedium = "medium"
+	LevelHigh   = "high"
+)
+
+// Thresholds of the medium and high levels
+const (
+	MediumThreshold = 40
+	HighThreshold   = 70
+)
+
+// Counterparty is the other side of a transaction of a watched address. At is the transaction's
+// block time, which the counterparty's age is measured at.
+type Counterparty struct {
+	Chain   string
+	Address string
+	At      time.Time
+}
+
+// Score is the risk of a counterparty. Reasons name the signals that raised it, e.g. mixer or
+// high_velocity, and Provider the Scorer it came from.
+type Score struct {
+	Value    int64    `json:"score"`
+	Level    string   `json:"level"`
+	Reasons  []string `json:"reasons,omitempty"`
+	Provider string   `json:"provider"`
+}
+
+// Scorer scores counterparties, safe for concurrent use
+type Scorer interface {
+	Score(ctx context.Context, c Counterparty) (Score, error)
+}
+
+// Level returns the level of score value
+func Level(value int64) string {
+	switch {
+	case value >= HighThreshold:
+		return LevelHigh
+	case value >= MediumThreshold:
+		return LevelMedium
+	}
+	return LevelLow
+}
+
+// newScore clamps value to 0-100 and sets its level
+func newScore(value int64, reasons []string, provider string) Score {
+	value = min(max(value, 0), 100)
+	return Score{Value: value, Level: Level(value), Reasons: reasons, Provider: provider}
+}
+
+// cacheKey is a scored counterparty
+type cacheKey struct {
+	chain   string
+	address string
+}
+
+type cacheEntry struct {
+	score   Score
+	expires time.Time
+}
+
+// Cache keeps the scores of a Scorer for a TTL, so an external provider is asked once per
+// counterparty however many transactions it has. Failures are not cached.
+type Cache struct {
+	scorer Scorer
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+var _ Scorer = (*Cache)(nil)
+
+// NewCache caches the scores of scorer for ttl
+func NewCache(scorer Scorer, ttl time.Duration) *Cache {
+	return &Cache{
+		scorer:  scorer,
+		ttl:     ttl,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+// Score returns the cached score of c, asking the scorer once it expired
+func (c *Cache) Score(ctx context.Context, cp Counterparty) (Score, error) {
+	key := cacheKey{chain: cp.Chain, address: normalize(cp.Address)}
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		c.hits.Add(1)
+		return entry.score, nil
+	}
+
+	c.misses.Add(1)
+	score, err := c.scorer.Score(ctx, cp)
+	if err != nil {
+		c.errors.Add(1)
+		return Score{}, err
+	}
+
+	c.mu.Lock()
+	// Expired entries are dropped as they are replaced, and all at once when the cache grows large
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = cacheEntry{score: score, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return score, nil
+}
+
+// maxCacheEntries is the number of cached scores past which expired ones are swept
+const maxCacheEntries = 100000
+
+// GetStats returns the cache's size, hits, misses and scorer errors
+func (c *Cache) GetStats() map[string]interface{} {
+	c.mu.Lock()
+	size := len(c.entries)
+	c.mu.Unlock()
+
+	return map[string]interface{}{
+		"entries": size,
+		"hits":    c.hits.Load(),
+		"misses":  c.misses.Load(),
+		"errors":  c.errors.Load(),
+	}
+}
+
+// ScoreOrNil scores c with scorer, returning nil when it fails, which is logged: an unscored
+// counterparty does not hold up the transaction's rules
+func ScoreOrNil(ctx context.Context, scorer Scorer, c Counterparty) *Score {
+	if scorer == nil || c.Address == "" {
+		return nil
+	}
+	score, err := scorer.Score(ctx, c)
+	if err != nil {
+		logger.Warn("Failed to score counterparty", "chain", c.Chain, "address", c.Address, "error", err)
+		return nil
+	}
+	return &score
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/tokens"
+)
+
+// Condition is a simple alert rule: the thresholds a transfer of its address has to meet, as set
+// with the api-server's /rules endpoints. Unlike advanced rules, simple rules are read when their
+// transfers are notified, so they carry no compiled state.
+type Condition struct {
+	ID       string
+	Name     string
+	Severity string
+	// Direction is in, out or any
+	Direction string
+	// MinValue is the least value, in the token's smallest unit
+	MinValue *big.Int
+	// TokenAddress is the token contract of the transfers; with neither it nor TokenPreset set,
+	// only transfers of the chain's native currency match
+	TokenAddress string
+	// TokenPreset is the name of a preset of shared/tokens, whose contracts match
+	TokenPreset string
+	// Counterparties are the addresses on the other side of the transfers, any when empty
+	Counterparties []string
+	// Cooldown is the least time between two notifications of the rule
+	Cooldown time.Duration
+	// Muted is set while the rule is muted
+	Muted bool
+}
+
+// Transfer is a transfer of a watched address, as seen from that address
+type Transfer struct {
+	Chain string
+	// Direction is in or out
+	Direction string
+	// Value is in the token's smallest unit
+	Value *big.Int
+	// Token is the token contract, empty for the chain's native currency
+	Token string
+	// Counterparty is the sender of incoming transfers and the recipient of outgoing ones
+	Counterparty string
+}
+
+// Match reports whether t meets every condition of c
+func (c Condition) Match(t Transfer) bool {
+	if c.Direction != "" && c.Direction != "any" && c.Direction != t.Direction {
+		return false
+	}
+	if c.MinValue != nil && c.MinValue.Sign() > 0 && (t.Value == nil || t.Value.Cmp(c.MinValue) < 0) {
+		return false
+	}
+	switch {
+	case c.TokenAddress != "":
+		if t.Token == "" || chains.NormalizeAddress(t.Chain, t.Token) != chains.NormalizeAddress(t.Chain, c.TokenAddress) {
+			return false
+		}
+	case c.TokenPreset != "":
+		if !tokens.Match(c.TokenPreset, t.Chain, t.Token) {
+			return false
+		}
+	case t.Token != "":
+		return false
+	}
+	if len(c.Counterparties) == 0 {
+		return true
+	}
+	if t.Counterparty == "" {
+		return false
+	}
+	counterparty := chains.NormalizeAddress(t.Chain, t.Counterparty)
+	for _, address := range c.Counterparties {
+		if chains.NormalizeAddress(t.Chain, address) == counterparty {
+			return true
+		}
+	}
+	return false
+}
+
+// First returns the first of conditions, in evaluation order, which t matches
+func First(conditions []Condition, t Transfer) (Condition, bool) {
+	for _, c := range conditions {
+		if c.Match(t) {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
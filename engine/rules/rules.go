@@ -0,0 +1,270 @@
+// Package rules keeps the compiled conditions of the advanced alert rules in step with the
+// api-server's alert_rules table, from its change events, and evaluates them against transactions.
+// An advanced rule's condition is a CEL expression (see package ruleexpr). Expressions are
+// compiled when a rule is created or its expression changes, not per transaction, and rules with
+// the same expression share one compiled program. Disabled and soft-deleted rules are dropped.
+//
+// The rules of a transaction are evaluated in the api-server's order: by descending priority,
+// then by creation. Once a rule with stop_processing matches, the rules after it are skipped.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	objects "github.com/ahsansaif47/blockchain-address-watcher/engine/models"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/ruleexpr"
+)
+
+// logger is used by the evaluator
+var logger = logging.For("rules")
+
+// rule is a compiled advanced rule. addressID is empty for rules applying to all the user's
+// addresses.
+type rule struct {
+	id        string
+	userID    string
+	addressID string
+	program   *ruleexpr.Program
+	priority  int32
+	stop      bool
+	createdAt time.Time
+}
+
+// before reports whether r is evaluated before o
+func (r rule) before(o rule) bool {
+	if r.priority != o.priority {
+		return r.priority > o.priority
+	}
+	if !r.createdAt.Equal(o.createdAt) {
+		return r.createdAt.Before(o.createdAt)
+	}
+	return r.id < o.id
+}
+
+// program is a compiled expression and the number of rules using it
+type program struct {
+	compiled *ruleexpr.Program
+	rules    int
+}
+
+// Evaluator holds the compiled advanced rules, safe for concurrent use
+//
+// Example usage:
+//
+//	evaluator := rules.New()
+//	evaluator.Register(consumer.DefaultRouter)
+//	...
+//	matched, err := evaluator.Match(userID, addressID, tx, known)
+type Evaluator struct {
+	mu       sync.RWMutex
+	rules    map[string]rule     // rule ID -> compiled rule
+	programs map[string]*program // expression -> compiled program
+
+	invalid     atomic.Int64
+	evaluations atomic.Int64
+	matches     atomic.Int64
+	failures    atomic.Int64
+	stops       atomic.Int64
+}
+
+// New creates an Evaluator without rules
+func New() *Evaluator {
+	return &Evaluator{
+		rules:    make(map[string]rule),
+		programs: make(map[string]*program),
+	}
+}
+
+// Register handles the alert_rules table's events on router
+func (e *Evaluator) Register(router *consumer.Router) {
+	router.OnTable("alert_rules").OnChange(e.handle)
+}
+
+func (e *Evaluator) handle(event *consumer.Event) error {
+	if event.Operation == consumer.OpDelete {
+		// Without REPLICA IDENTITY FULL the old row only holds the key
+		id, _ := event.Key["id"].(string)
+		if before, ok := event.Before.(*objects.AlertRule); ok && before.Id != "" {
+			id = before.Id
+		}
+		e.drop(id)
+		return nil
+	}
+
+	row, ok := event.After.(*objects.AlertRule)
+	if !ok {
+		return fmt.Errorf("unexpected alert_rules row %T", event.After)
+	}
+	if row.DeletedAt != nil || !row.Enabled || row.Expression == nil {
+		e.drop(row.Id)
+		return nil
+	}
+	r := rule{
+		id:        row.Id,
+		userID:    row.UserId,
+		priority:  row.Priority,
+		stop:      row.StopProcessing,
+		createdAt: row.CreatedAt,
+	}
+	if row.AddressId != nil {
+		r.addressID = *row.AddressId
+	}
+	e.set(r, *row.Expression)
+	return nil
+}
+
+// set compiles the rule's expression, unless a rule already uses it. The api-server rejects
+// expressions that do not compile, so one that does not was written around it: the rule is
+// dropped rather than failing the event, which would hold up the partition.
+func (e *Evaluator) set(r rule, expression string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := r.id
+	if old, ok := e.rules[id]; ok && old.program.Source() == expression {
+		r.program = old.program
+		e.rules[id] = r
+		return
+	}
+
+	p, ok := e.programs[expression]
+	if !ok {
+		compiled, err := ruleexpr.Compile(expression)
+		if err != nil {
+			e.invalid.Add(1)
+			logger.Warn("Dropping rule with an invalid expression", "rule", id, "error", err)
+			e.release(id)
+			return
+		}
+		p = &program{compiled: compiled}
+		e.programs[expression] = p
+	}
+	e.release(id)
+	p.rules++
+	r.program = p.compiled
+	e.rules[id] = r
+}
+
+func (e *Evaluator) drop(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.release(id)
+}
+
+// release removes the rule id, and its program once no rule uses it, e.mu must be held
+func (e *Evaluator) release(id string) {
+	r, ok := e.rules[id]
+	if !ok {
+		return
+	}
+	delete(e.rules, id)
+	expression := r.program.Source()
+	if p := e.programs[expression]; p != nil {
+		p.rules--
+		if p.rules == 0 {
+			delete(e.programs, expression)
+		}
+	}
+}
+
+// Rules returns the IDs of the user's advanced rules that apply to the address addressID, its own
+// and those without an address, in evaluation order
+func (e *Evaluator) Rules(userID, addressID string) []string {
+	applying := e.applying(userID, addressID)
+	ids := make([]string, len(applying))
+	for i, r := range applying {
+		ids[i] = r.id
+	}
+	return ids
+}
+
+func (e *Evaluator) applying(userID, addressID string) []rule {
+	e.mu.RLock()
+	var applying []rule
+	for _, r := range e.rules {
+		if r.userID == userID && (r.addressID == "" || r.addressID == addressID) {
+			applying = append(applying, r)
+		}
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(applying, func(i, j int) bool { return applying[i].before(applying[j]) })
+	return applying
+}
+
+// Match returns the IDs of the user's advanced rules applying to the address addressID whose
+// condition holds for tx, in evaluation order. It stops after the first matching rule with
+// stop_processing. Rules failing to evaluate do not match; their errors are joined.
+func (e *Evaluator) Match(userID, addressID string, tx ruleexpr.Transaction, known ruleexpr.Known) ([]string, error) {
+	var matched []string
+	var errs []error
+	for _, r := range e.applying(userID, addressID) {
+		ok, err := e.eval(r, tx, known)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		matched = append(matched, r.id)
+		if r.stop {
+			e.stops.Add(1)
+			break
+		}
+	}
+	return matched, errors.Join(errs...)
+}
+
+// Evaluate reports whether the condition of the advanced rule id holds for tx. ok is false when
+// id is not an enabled advanced rule. An error, such as reading tx.value_usd of a token without a
+// price, means the rule does not match.
+func (e *Evaluator) Evaluate(id string, tx ruleexpr.Transaction, known ruleexpr.Known) (matched, ok bool, err error) {
+	e.mu.RLock()
+	r, ok := e.rules[id]
+	e.mu.RUnlock()
+	if !ok {
+		return false, false, nil
+	}
+
+	matched, err = e.eval(r, tx, known)
+	return matched, true, err
+}
+
+func (e *Evaluator) eval(r rule, tx ruleexpr.Transaction, known ruleexpr.Known) (bool, error) {
+	e.evaluations.Add(1)
+	matched, err := r.program.Eval(tx, known)
+	if err != nil {
+		e.failures.Add(1)
+		return false, fmt.Errorf("failed to evaluate rule %s: %w", r.id, err)
+	}
+	if matched {
+		e.matches.Add(1)
+	}
+	return matched, nil
+}
+
+// GetStats returns the number of advanced rules and distinct compiled expressions, and evaluation
+// counters. stops counts the matches of stop_processing rules that ended an evaluation.
+func (e *Evaluator) GetStats() map[string]interface{} {
+	e.mu.RLock()
+	rules, programs := len(e.rules), len(e.programs)
+	e.mu.RUnlock()
+
+	return map[string]interface{}{
+		"rules":       rules,
+		"programs":    programs,
+		"invalid":     e.invalid.Load(),
+		"evaluations": e.evaluations.Load(),
+		"matches":     e.matches.Load(),
+		"failures":    e.failures.Load(),
+		"stops":       e.stops.Load(),
+	}
+}
@@ -0,0 +1,294 @@
+// Package simulate runs recorded change events and chain transactions through the engine's
+// pipeline without side effects, to see which advanced rules would have fired before enabling
+// them. Change events build the watched addresses and the rules, as they do in engine run, and
+// each transaction is evaluated for every address watching its sender or recipient. Alerts are
+// captured instead of being delivered.
+//
+// Counterparties are scored by the local risk.Heuristic only, from the fixtures' known addresses
+// and transactions, so simulations never reach an external risk provider.
+package simulate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/risk"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/rules"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watchlist"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/ruleexpr"
+)
+
+// logger is used by the simulator
+var logger = logging.For("simulate")
+
+// Fixture is one line of a fixtures file holding a chain transaction or the known addresses rules
+// see as known.*. Other lines are change events, in any format consumer.Replay reads.
+//
+// Example fixtures:
+//
+//	{"topic":"sub-users-db.public.alert_rules","value":{"payload":{"op":"c","after":{...}}}}
+//	{"known":{"exchanges":["0xexchange"]}}
+//	{"transaction":{"chain":"ethereum","hash":"0xabc","from":"0xwatched","to":"0xexchange","value":1e18,"value_usd":3200}}
+type Fixture struct {
+	Transaction *Transaction    `json:"transaction"`
+	Known       *ruleexpr.Known `json:"known"`
+}
+
+// Transaction is a chain transaction of the fixtures. Direction and Counterparty are set per
+// watching address: out for the sender's, in for the recipient's. Kind defaults to transfer and
+// Status to confirmed. Time is the block time the counterparties' age and velocity are measured
+// at; CounterpartyRisk pins the score of both counterparties, e.g. to replay a provider's scores.
+type Transaction struct {
+	Chain           string   `json:"chain"`
+	Hash            string   `json:"hash"`
+	BlockNumber     int64    `json:"block_number"`
+	LogIndex        int64    `json:"log_index"`
+	From            string   `json:"from"`
+	To              string   `json:"to"`
+	Value           float64  `json:"value"`
+	ValueUSD        *float64 `json:"value_usd"`
+	Token           string   `json:"token"`
+	Kind            string   `json:"kind"`
+	NewCounterparty bool     `json:"new_counterparty"`
+	Status          string   `json:"status"`
+
+	Time             time.Time `json:"time"`
+	CounterpartyRisk *int64    `json:"counterparty_risk"`
+}
+
+// Alert is an advanced rule that fired for a transaction of a watched address
+type Alert struct {
+	RuleID    string `json:"rule_id"`
+	UserID    string `json:"user_id"`
+	AddressID string `json:"address_id"`
+	Chain     string `json:"chain"`
+	Hash      string `json:"hash"`
+	LogIndex  int64  `json:"log_index"`
+	Direction string `json:"direction"`
+	// CounterpartyRisk is the score of the transaction's counterparty, nil when unscored
+	CounterpartyRisk *risk.Score `json:"counterparty_risk,omitempty"`
+	// Line is the line of the fixtures file holding the transaction
+	Line int `json:"line"`
+}
+
+// Notifier delivers the alerts of the pipeline
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Capture is the Notifier of simulations: it keeps the alerts instead of delivering them
+type Capture struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+var _ Notifier = (*Capture)(nil)
+
+// Notify records alert
+func (c *Capture) Notify(ctx context.Context, alert Alert) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alerts = append(c.alerts, alert)
+	return nil
+}
+
+// Alerts returns the captured alerts in the order they fired
+func (c *Capture) Alerts() []Alert {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Alert(nil), c.alerts...)
+}
+
+// Stats summarizes a simulation. Evaluation errors, such as reading tx.value_usd of a token without
+// a price, mean the rule did not fire.
+type Stats struct {
+	Events           consumer.ReplayStats `json:"events"`
+	Transactions     int64                `json:"transactions"`
+	Unwatched        int64                `json:"unwatched"`
+	Alerts           int64                `json:"alerts"`
+	EvaluationErrors int64                `json:"evaluation_errors"`
+	// Rules counts the alerts per rule ID
+	Rules map[string]int64 `json:"rules"`
+}
+
+// Simulator holds the state built from the fixtures' change events
+type Simulator struct {
+	watched   *watchlist.Watchlist
+	evaluator *rules.Evaluator
+	router    *consumer.Router
+	notifier  Notifier
+	known     ruleexpr.Known
+	scorer    *risk.Heuristic
+}
+
+// New creates a Simulator with no watched addresses or rules, delivering alerts to notifier
+func New(notifier Notifier) *Simulator {
+	s := &Simulator{
+		watched:   watchlist.New(),
+		evaluator: rules.New(),
+		router:    consumer.NewRouter(),
+		notifier:  notifier,
+		scorer:    risk.NewHeuristic(risk.HeuristicConfig{}),
+	}
+	s.watched.Register(s.router)
+	s.evaluator.Register(s.router)
+	// Change events of other tables do not affect the simulation
+	s.router.Fallback(func(*consumer.Event) error { return nil })
+	return s
+}
+
+// Run runs every line of the fixtures read from r in order, decoding change events according to
+// config's serialization settings
+func (s *Simulator) Run(ctx context.Context, config *consumer.Config, r io.Reader) (Stats, error) {
+	stats := Stats{Rules: make(map[string]int64)}
+	replayer, err := consumer.NewReplayer(config, s.router.Handle)
+	if err != nil {
+		return stats, err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10e6) // Debezium messages can be large
+
+	line := 0
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var fixture Fixture
+		if json.Unmarshal(data, &fixture) != nil || (fixture.Transaction == nil && fixture.Known == nil) {
+			replayer.ReplayLine(data)
+			continue
+		}
+		if fixture.Known != nil {
+			s.known = *fixture.Known
+			s.scorer.SetKnown(s.known)
+		}
+		if fixture.Transaction != nil {
+			stats.Transactions++
+			if err := s.evaluate(ctx, *fixture.Transaction, line, &stats); err != nil {
+				return stats, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+	}
+	stats.Events = replayer.Stats()
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("failed to read fixtures at line %d: %w", line, err)
+	}
+
+	return stats, nil
+}
+
+// evaluate matches tx against the rules of every address watching its sender or recipient, then
+// records its sender and recipient for the scores of later transactions
+func (s *Simulator) evaluate(ctx context.Context, tx Transaction, line int, stats *Stats) error {
+	defer func() {
+		s.scorer.Observe(risk.Counterparty{Chain: tx.Chain, Address: tx.From, At: tx.Time})
+		s.scorer.Observe(risk.Counterparty{Chain: tx.Chain, Address: tx.To, At: tx.Time})
+	}()
+
+	sides := []struct {
+		address, counterparty, direction string
+	}{
+		{tx.From, tx.To, "out"},
+		{tx.To, tx.From, "in"},
+	}
+
+	watched := false
+	for _, side := range sides {
+		if side.address == "" {
+			continue
+		}
+		watchers := s.watched.Watchers(tx.Chain, side.address)
+		if len(watchers) == 0 {
+			continue
+		}
+		score := s.score(ctx, tx, side.counterparty)
+		for _, w := range watchers {
+			watched = true
+			matched, err := s.evaluator.Match(w.UserID, w.AddressID, expressionTx(tx, side.counterparty, side.direction, score), s.known)
+			if err != nil {
+				stats.EvaluationErrors++
+				logger.Warn("Rules failed to evaluate", "line", line, "hash", tx.Hash, "address_id", w.AddressID, "error", err)
+			}
+			for _, id := range matched {
+				alert := Alert{
+					RuleID:    id,
+					UserID:    w.UserID,
+					AddressID: w.AddressID,
+					Chain:     tx.Chain,
+					Hash:      tx.Hash,
+					LogIndex:  tx.LogIndex,
+					Direction: side.direction,
+					Line:      line,
+
+					CounterpartyRisk: score,
+				}
+				if err := s.notifier.Notify(ctx, alert); err != nil {
+					return fmt.Errorf("failed to notify alert of rule %s: %w", id, err)
+				}
+				stats.Alerts++
+				stats.Rules[id]++
+			}
+		}
+	}
+	if !watched {
+		stats.Unwatched++
+	}
+	return nil
+}
+
+// score scores counterparty of tx, unless the fixture pinned its score
+func (s *Simulator) score(ctx context.Context, tx Transaction, counterparty string) *risk.Score {
+	if tx.CounterpartyRisk != nil {
+		value := min(max(*tx.CounterpartyRisk, 0), 100)
+		return &risk.Score{Value: value, Level: risk.Level(value), Provider: "fixture"}
+	}
+	return risk.ScoreOrNil(ctx, s.scorer, risk.Counterparty{Chain: tx.Chain, Address: counterparty, At: tx.Time})
+}
+
+// expressionTx is tx as seen from its side direction, with the score of its counterparty
+func expressionTx(tx Transaction, counterparty, direction string, score *risk.Score) ruleexpr.Transaction {
+	kind, status := tx.Kind, tx.Status
+	if kind == "" {
+		kind = "transfer"
+	}
+	if status == "" {
+		status = "confirmed"
+	}
+	t := ruleexpr.Transaction{
+		Chain:           tx.Chain,
+		Hash:            tx.Hash,
+		BlockNumber:     tx.BlockNumber,
+		LogIndex:        tx.LogIndex,
+		From:            tx.From,
+		To:              tx.To,
+		Value:           tx.Value,
+		ValueUSD:        tx.ValueUSD,
+		Token:           tx.Token,
+		Kind:            kind,
+		Direction:       direction,
+		Counterparty:    counterparty,
+		NewCounterparty: tx.NewCounterparty,
+		Status:          status,
+	}
+	if score != nil {
+		t.CounterpartyRisk = &score.Value
+		t.CounterpartyRiskLevel = score.Level
+	}
+	return t
+}
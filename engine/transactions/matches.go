@@ -0,0 +1,39 @@
+package transactions
+
+import (
+	"context"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher"
+)
+
+// OnMatch buffers the transfer of m, see watcher.Dispatcher.OnMatch. Transactions still in the
+// mempool are not stored, the history holds mined transfers only; the status changes of a stored
+// transfer, e.g. to dropped after a reorg, update it. A full buffer drops the transfer, which is
+// counted.
+//
+// A batch filled by m is written before OnMatch returns, holding up the watcher meanwhile; Run
+// normally writes the buffer well before.
+func (w *Writer) OnMatch(m watcher.Match) {
+	if m.BlockHash == "" {
+		return
+	}
+	err := w.Add(context.Background(), Transaction{
+		Chain:        m.Chain,
+		Hash:         m.Hash,
+		LogIndex:     int32(m.LogIndex),
+		BlockNumber:  m.BlockNumber,
+		BlockHash:    m.BlockHash,
+		FromAddress:  m.From,
+		ToAddress:    m.To,
+		Value:        m.Value,
+		TokenAddress: m.Token,
+		Status:       m.Status,
+		OccurredAt:   m.Time,
+	})
+	if err != nil {
+		n := w.dropped.Add(1)
+		if n == 1 || n%100 == 0 {
+			logger.Warn("Dropping matched transactions", "dropped", n, "error", err)
+		}
+	}
+}
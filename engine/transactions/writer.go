@@ -0,0 +1,274 @@
+// Package transactions persists the transfers detected on watched addresses in bulk. Rows are
+// buffered and written with COPY into a staging table, then merged into the api-server's
+// partitioned transactions table, which is an order of magnitude faster than one INSERT per
+// transfer when blocks are backfilled. Tables are created by the api-server migrations
+// (000006_create_transactions_table and 000013_partition_transactions).
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// logger is used by the transaction writer
+var logger = logging.For("transaction_writer")
+
+// ErrBufferFull is returned by Add while MaxBuffered transfers are waiting to be written, which
+// happens when the database is unavailable
+var ErrBufferFull = errors.New("transaction writer buffer is full")
+
+// Transaction is a transfer detected on a watched address. A transaction moving several tokens has
+// one Transaction per transfer, told apart by LogIndex (-1 for the native transfer).
+type Transaction struct {
+	Chain       string
+	Hash        string
+	LogIndex    int32
+	BlockNumber int64
+	BlockHash   string
+	FromAddress string
+	// ToAddress is empty for contract creations
+	ToAddress string
+	// Value is in the token's smallest unit
+	Value *big.Int
+	// TokenAddress is empty for the chain's native currency
+	TokenAddress string
+	// Status is pending, confirmed, failed or dropped
+	Status string
+	// OccurredAt is the block time
+	OccurredAt time.Time
+}
+
+// WriterConfig controls when buffered transfers are written
+type WriterConfig struct {
+	// BatchSize is the number of buffered transfers that triggers a write (default 1000)
+	BatchSize int
+	// FlushInterval is the longest a transfer stays buffered while Run is running (default 1s)
+	FlushInterval time.Duration
+	// MaxBuffered is the number of transfers buffered before Add fails (default 10 batches)
+	MaxBuffered int
+}
+
+// Writer buffers transfers and writes them in batches. A transfer seen before, identified by
+// chain, hash and log index, keeps its ID and has its block, status and block time updated, like
+// the api-server's UpsertTransaction. Within a batch the last write of a transfer wins.
+type Writer struct {
+	pool   *pgxpool.Pool
+	config WriterConfig
+
+	mu  sync.Mutex
+	buf []Transaction
+	// flushMu serializes writes, so batches are applied in order
+	flushMu sync.Mutex
+
+	written atomic.Int64
+	flushes atomic.Int64
+	errors  atomic.Int64
+	// dropped counts the matches OnMatch could not buffer
+	dropped atomic.Int64
+}
+
+// NewWriter creates a Writer storing transfers in the database of pool
+func NewWriter(pool *pgxpool.Pool, config WriterConfig) *Writer {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 1 * time.Second
+	}
+	if config.MaxBuffered <= 0 {
+		config.MaxBuffered = 10 * config.BatchSize
+	}
+	return &Writer{pool: pool, config: config}
+}
+
+// Add buffers txs, writing the buffer once it holds BatchSize transfers. The transfers are
+// durable only once a Flush including them succeeds, so call Flush before acknowledging the
+// blocks they came from. A failed write is logged and retried by the next one.
+func (w *Writer) Add(ctx context.Context, txs ...Transaction) error {
+	for _, tx := range txs {
+		if tx.Chain == "" || tx.Hash == "" || tx.Value == nil {
+			return fmt.Errorf("transaction %q on %q has no chain, hash or value", tx.Hash, tx.Chain)
+		}
+	}
+
+	w.mu.Lock()
+	if len(w.buf)+len(txs) > w.config.MaxBuffered {
+		w.mu.Unlock()
+		return ErrBufferFull
+	}
+	w.buf = append(w.buf, txs...)
+	full := len(w.buf) >= w.config.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		if err := w.Flush(ctx); err != nil {
+			logger.Error("Failed to write transactions", "error", err)
+		}
+	}
+	return nil
+}
+
+// Run writes the buffer every FlushInterval until ctx is done. Call Flush afterwards to write the
+// transfers still buffered.
+func (w *Writer) Run(ctx context.Context) {
+	logger.Info("Writer started")
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Writer stopped")
+			return
+		case <-ticker.C:
+			if err := w.Flush(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("Failed to write transactions", "error", err)
+			}
+		}
+	}
+}
+
+// Flush writes the buffered transfers in batches of BatchSize. Transfers of a failed batch are
+// put back in the buffer.
+func (w *Writer) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	for {
+		w.mu.Lock()
+		n := min(len(w.buf), w.config.BatchSize)
+		batch := w.buf[:n:n]
+		w.buf = w.buf[n:]
+		w.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		if err := w.write(ctx, batch); err != nil {
+			w.errors.Add(1)
+			w.mu.Lock()
+			w.buf = append(batch, w.buf...)
+			w.mu.Unlock()
+			return err
+		}
+		w.written.Add(int64(n))
+		w.flushes.Add(1)
+	}
+}
+
+// stagingColumns are the columns of the staging table filled by COPY, seq orders the writes of
+// a batch
+var stagingColumns = []string{
+	"seq", "chain", "hash", "log_index", "block_number", "block_hash",
+	"from_address", "to_address", "value", "token_address", "status", "occurred_at",
+}
+
+const createStagingTable = `
+CREATE TEMPORARY TABLE transactions_staging (
+    seq INTEGER NOT NULL,
+    chain VARCHAR(32) NOT NULL,
+    hash VARCHAR(128) NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(128) NOT NULL,
+    from_address VARCHAR(255) NOT NULL,
+    to_address VARCHAR(255),
+    value NUMERIC(78, 0) NOT NULL,
+    token_address VARCHAR(255),
+    status VARCHAR(16) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL
+) ON COMMIT DROP`
+
+// insertKeys assigns IDs and partitions to the transfers seen for the first time, see
+// transaction_keys
+const insertKeys = `
+INSERT INTO transaction_keys (chain, hash, log_index, transaction_id, created_at)
+SELECT chain, hash, log_index, gen_random_uuid(), NOW()
+FROM (SELECT DISTINCT chain, hash, log_index FROM transactions_staging) s
+ON CONFLICT (chain, hash, log_index) DO NOTHING`
+
+// mergeTransactions stores the last write of each transfer in the batch
+const mergeTransactions = `
+INSERT INTO transactions (
+    id, chain, hash, log_index, block_number, block_hash, from_address, to_address, value,
+    token_address, status, occurred_at, created_at, updated_at
+)
+SELECT
+    k.transaction_id, s.chain, s.hash, s.log_index, s.block_number, s.block_hash, s.from_address,
+    s.to_address, s.value, s.token_address, s.status, s.occurred_at, k.created_at, NOW()
+FROM (
+    SELECT DISTINCT ON (chain, hash, log_index) *
+    FROM transactions_staging
+    ORDER BY chain, hash, log_index, seq DESC
+) s
+JOIN transaction_keys k USING (chain, hash, log_index)
+ON CONFLICT (id, created_at) DO UPDATE
+SET
+    block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    status = EXCLUDED.status,
+    occurred_at = EXCLUDED.occurred_at,
+    updated_at = NOW()`
+
+// write stores batch in one transaction
+func (w *Writer) write(ctx context.Context, batch []Transaction) error {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, createStagingTable); err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+	rows := pgx.CopyFromSlice(len(batch), func(i int) ([]any, error) {
+		t := batch[i]
+		return []any{
+			int32(i), t.Chain, t.Hash, t.LogIndex, t.BlockNumber, t.BlockHash,
+			t.FromAddress, optional(t.ToAddress), pgtype.Numeric{Int: t.Value, Valid: true},
+			optional(t.TokenAddress), t.Status, t.OccurredAt,
+		}, nil
+	})
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"transactions_staging"}, stagingColumns, rows); err != nil {
+		return fmt.Errorf("failed to copy transactions: %w", err)
+	}
+	if _, err := tx.Exec(ctx, insertKeys); err != nil {
+		return fmt.Errorf("failed to insert transaction keys: %w", err)
+	}
+	if _, err := tx.Exec(ctx, mergeTransactions); err != nil {
+		return fmt.Errorf("failed to merge transactions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// optional stores empty strings as NULL
+func optional(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// GetStats returns writer counters, the number of buffered transfers and the matches dropped
+func (w *Writer) GetStats() map[string]interface{} {
+	w.mu.Lock()
+	buffered := len(w.buf)
+	w.mu.Unlock()
+	return map[string]interface{}{
+		"written":  w.written.Load(),
+		"flushes":  w.flushes.Load(),
+		"errors":   w.errors.Load(),
+		"buffered": buffered,
+		"dropped":  w.dropped.Load(),
+	}
+}
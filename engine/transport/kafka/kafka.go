@@ -0,0 +1,64 @@
+// Package kafka implements transport.Producer on Kafka. Consuming from Kafka goes through
+// consumer.KafkaManager, which adds consumer groups, topic subscriptions and offset management.
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Producer publishes messages to Kafka, waiting for all in-sync replicas to acknowledge them.
+// Messages with the same key go to the same partition, so they stay in order.
+type Producer struct {
+	writer    *kafkago.Writer
+	published atomic.Int64
+}
+
+var _ transport.Producer = (*Producer)(nil)
+
+// NewProducer creates a Producer for broker (e.g. "localhost:9092")
+func NewProducer(broker string) *Producer {
+	return &Producer{
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(broker),
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: kafkago.RequireAll,
+		},
+	}
+}
+
+// Publish writes msgs to topic, returning once the brokers have acknowledged all of them
+func (p *Producer) Publish(ctx context.Context, topic string, msgs ...transport.Message) error {
+	out := make([]kafkago.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = kafkago.Message{
+			Topic: topic,
+			Key:   m.Key,
+			Value: m.Value,
+			Time:  m.Time,
+		}
+		for k, v := range m.Headers {
+			out[i].Headers = append(out[i].Headers, kafkago.Header{Key: k, Value: []byte(v)})
+		}
+	}
+
+	if err := p.writer.WriteMessages(ctx, out...); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	p.published.Add(int64(len(msgs)))
+	return nil
+}
+
+// GetStats returns the number of published messages
+func (p *Producer) GetStats() map[string]interface{} {
+	return map[string]interface{}{"published": p.published.Load()}
+}
+
+// Close flushes pending writes and closes the connections
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
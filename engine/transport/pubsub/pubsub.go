@@ -0,0 +1,246 @@
+// Package pubsub implements transport.Transport on Google Cloud Pub/Sub. Messages are received
+// with streaming pull; subscriptions with message ordering deliver the changes of a key in order,
+// and on subscriptions with exactly-once delivery acknowledgements are confirmed before a message
+// counts as handled. Published messages use the message key as ordering key.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+)
+
+// Message attributes carrying the fields Pub/Sub messages have no place for
+const (
+	TopicAttribute = "topic"
+	KeyAttribute   = "key"
+)
+
+// logger is used by the Pub/Sub transport
+var logger = logging.For("pubsub")
+
+// Config holds the Pub/Sub settings
+type Config struct {
+	// ProjectID is the Google Cloud project of the subscription and topic
+	ProjectID string
+	// Subscription is the subscription consumed by the engine, an ID or full resource name
+	Subscription string
+	// Topic is the topic Publish sends to, optional
+	Topic string
+	// MaxOutstanding bounds messages being handled at once (default 1, one at a time). Messages
+	// with the same ordering key are always handled in order.
+	MaxOutstanding int
+}
+
+// Transport consumes from a Pub/Sub subscription and publishes to a topic using Application
+// Default Credentials
+type Transport struct {
+	config     Config
+	client     *pubsub.Client
+	subscriber *pubsub.Subscriber
+	publisher  *pubsub.Publisher
+
+	received  atomic.Int64
+	acked     atomic.Int64
+	nacked    atomic.Int64
+	ackFailed atomic.Int64
+	published atomic.Int64
+}
+
+var _ transport.Transport = (*Transport)(nil)
+
+// New creates a Transport for config
+//
+// Example usage:
+//
+//	t, err := pubsub.New(ctx, pubsub.Config{
+//	    ProjectID:    "my-project",
+//	    Subscription: "engine",
+//	    Topic:        "alerts",
+//	})
+func New(ctx context.Context, config Config) (*Transport, error) {
+	if config.ProjectID == "" || config.Subscription == "" {
+		return nil, errors.New("Pub/Sub project ID and subscription are required")
+	}
+	if config.MaxOutstanding <= 0 {
+		config.MaxOutstanding = 1
+	}
+
+	client, err := pubsub.NewClient(ctx, config.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+
+	t := &Transport{
+		config:     config,
+		client:     client,
+		subscriber: client.Subscriber(config.Subscription),
+	}
+	t.subscriber.ReceiveSettings.MaxOutstandingMessages = config.MaxOutstanding
+
+	if config.Topic != "" {
+		t.publisher = client.Publisher(config.Topic)
+		t.publisher.EnableMessageOrdering = true
+	}
+
+	return t, nil
+}
+
+// Consume streams messages from the subscription to handler until ctx is done or the stream
+// fails. Messages are acknowledged when handler succeeds and nacked for redelivery when it fails;
+// with exactly-once delivery a message whose acknowledgement is not confirmed is redelivered.
+func (t *Transport) Consume(ctx context.Context, handler transport.MessageHandler) error {
+	sub, err := t.client.SubscriptionAdminClient.GetSubscription(ctx, &pubsubpb.GetSubscriptionRequest{
+		Subscription: t.subscriber.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get subscription %s: %w", t.config.Subscription, err)
+	}
+	exactlyOnce := sub.GetEnableExactlyOnceDelivery()
+	logger.Info("Receiving", "subscription", t.subscriber.String(),
+		"ordering", sub.GetEnableMessageOrdering(), "exactly_once", exactlyOnce)
+
+	err = t.subscriber.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+		t.received.Add(1)
+
+		if err := handler(ctx, toMessage(m)); err != nil {
+			t.nacked.Add(1)
+			logger.Warn("Handler failed, nacking message", "message_id", m.ID, "delivery_attempt", deliveryAttempt(m), "error", err)
+			m.Nack()
+			return
+		}
+
+		if !exactlyOnce {
+			t.acked.Add(1)
+			m.Ack()
+			return
+		}
+
+		status, err := m.AckWithResult().Get(ctx)
+		if err != nil || status != pubsub.AcknowledgeStatusSuccess {
+			t.ackFailed.Add(1)
+			logger.Warn("Acknowledgement not confirmed, message will be redelivered", "message_id", m.ID, "status", status, "error", err)
+			return
+		}
+		t.acked.Add(1)
+	})
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to receive from %s: %w", t.config.Subscription, err)
+	}
+	return errors.New("Pub/Sub receive stopped")
+}
+
+// Publish sends msgs to the configured topic, using each message key as ordering key, and waits
+// until the server has accepted all of them
+func (t *Transport) Publish(ctx context.Context, topic string, msgs ...transport.Message) error {
+	if t.publisher == nil {
+		return errors.New("Pub/Sub topic is not configured")
+	}
+
+	results := make([]*pubsub.PublishResult, len(msgs))
+	for i, m := range msgs {
+		results[i] = t.publisher.Publish(ctx, toPubsubMessage(topic, m))
+	}
+
+	var errs []error
+	for i, r := range results {
+		if _, err := r.Get(ctx); err != nil {
+			// A failed ordered publish pauses its ordering key until resumed
+			if key := string(msgs[i].Key); key != "" {
+				t.publisher.ResumePublish(key)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		t.published.Add(1)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to publish %d of %d messages to %s: %w", len(errs), len(msgs), t.config.Topic, errors.Join(errs...))
+	}
+	return nil
+}
+
+// HealthCheck reports whether the subscription is reachable
+func (t *Transport) HealthCheck(ctx context.Context) error {
+	_, err := t.client.SubscriptionAdminClient.GetSubscription(ctx, &pubsubpb.GetSubscriptionRequest{
+		Subscription: t.subscriber.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("Pub/Sub subscription unreachable: %w", err)
+	}
+	return nil
+}
+
+// GetStats returns receive, acknowledgement and publish counters
+func (t *Transport) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"subscription": t.config.Subscription,
+		"topic":        t.config.Topic,
+		"received":     t.received.Load(),
+		"acked":        t.acked.Load(),
+		"nacked":       t.nacked.Load(),
+		"ack_failed":   t.ackFailed.Load(),
+		"published":    t.published.Load(),
+	}
+}
+
+// Close flushes pending publishes and closes the client
+func (t *Transport) Close() error {
+	if t.publisher != nil {
+		t.publisher.Stop()
+	}
+	logger.Info("Closing client")
+	return t.client.Close()
+}
+
+// toMessage converts a Pub/Sub message, taking the topic and key from its attributes
+func toMessage(m *pubsub.Message) transport.Message {
+	msg := transport.Message{
+		Topic:   m.Attributes[TopicAttribute],
+		Value:   m.Data,
+		Headers: m.Attributes,
+		Time:    m.PublishTime,
+	}
+	if key, ok := m.Attributes[KeyAttribute]; ok {
+		msg.Key = []byte(key)
+	} else if m.OrderingKey != "" {
+		msg.Key = []byte(m.OrderingKey)
+	}
+	return msg
+}
+
+// toPubsubMessage converts a message for publishing to topic
+func toPubsubMessage(topic string, m transport.Message) *pubsub.Message {
+	attrs := make(map[string]string, len(m.Headers)+2)
+	for k, v := range m.Headers {
+		attrs[k] = v
+	}
+	attrs[TopicAttribute] = topic
+	if m.Key != nil {
+		attrs[KeyAttribute] = string(m.Key)
+	}
+
+	return &pubsub.Message{
+		Data:        m.Value,
+		Attributes:  attrs,
+		OrderingKey: string(m.Key),
+	}
+}
+
+// deliveryAttempt returns the delivery attempt for logging, known only with a dead letter policy
+func deliveryAttempt(m *pubsub.Message) string {
+	if m.DeliveryAttempt == nil {
+		return "unknown"
+	}
+	return fmt.Sprint(*m.DeliveryAttempt)
+}
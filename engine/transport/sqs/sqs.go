@@ -0,0 +1,407 @@
+// Package sqs implements transport.Transport on AWS, consuming from an SQS queue and publishing to
+// an SNS topic (which typically fans out to SQS queues). Receiving uses long polling; the
+// visibility timeout of received messages is extended while they are handled, failed messages
+// become visible again once it expires so the queue's redrive policy can dead-letter them. FIFO
+// queues and topics (names ending in .fifo) are supported with per key message groups and
+// content based deduplication ids.
+package sqs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/transport"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Message attributes carrying the fields SQS and SNS messages have no place for
+const (
+	TopicAttribute    = "topic"
+	KeyAttribute      = "key"
+	EncodingAttribute = "encoding" // "base64" when the body is not UTF-8, e.g. Avro
+)
+
+// maxBatch is the SQS and SNS limit of messages per batch request
+const maxBatch = 10
+
+// logger is used by the SQS transport
+var logger = logging.For("sqs")
+
+// Config holds the SQS and SNS settings
+type Config struct {
+	// QueueURL is the SQS queue consumed by the engine
+	QueueURL string
+	// TopicARN is the SNS topic Publish sends to, optional
+	TopicARN string
+	// WaitTime is the long polling duration of each receive, at most 20s (default 20s)
+	WaitTime time.Duration
+	// VisibilityTimeout hides a received message from other consumers while it is handled, and is
+	// extended for as long as handling takes (default 30s)
+	VisibilityTimeout time.Duration
+	// MaxMessages is the number of messages received per request, 1 to 10 (default 10)
+	MaxMessages int
+}
+
+// Transport consumes from SQS and publishes to SNS using the default AWS credential chain
+type Transport struct {
+	config Config
+	sqs    *awssqs.Client
+	sns    *awssns.Client
+
+	received  atomic.Int64
+	deleted   atomic.Int64
+	failed    atomic.Int64
+	published atomic.Int64
+}
+
+var _ transport.Transport = (*Transport)(nil)
+
+// New creates a Transport, loading AWS credentials and region from the environment
+//
+// Example usage:
+//
+//	t, err := sqs.New(ctx, sqs.Config{
+//	    QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/engine.fifo",
+//	    TopicARN: "arn:aws:sns:us-east-1:123456789012:alerts.fifo",
+//	})
+func New(ctx context.Context, config Config) (*Transport, error) {
+	if config.QueueURL == "" {
+		return nil, errors.New("SQS queue URL is required")
+	}
+	if config.WaitTime <= 0 || config.WaitTime > 20*time.Second {
+		config.WaitTime = 20 * time.Second
+	}
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = 30 * time.Second
+	}
+	if config.MaxMessages <= 0 || config.MaxMessages > maxBatch {
+		config.MaxMessages = maxBatch
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	return &Transport{
+		config: config,
+		sqs:    awssqs.NewFromConfig(cfg),
+		sns:    awssns.NewFromConfig(cfg),
+	}, nil
+}
+
+// Consume long polls the queue and hands messages to handler in the order received, deleting
+// each once handler succeeds. It returns when ctx is done or a receive fails.
+func (t *Transport) Consume(ctx context.Context, handler transport.MessageHandler) error {
+	logger.Info("Receiving", "queue_url", t.config.QueueURL)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		out, err := t.sqs.ReceiveMessage(ctx, &awssqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(t.config.QueueURL),
+			MaxNumberOfMessages:   int32(t.config.MaxMessages),
+			WaitTimeSeconds:       int32(t.config.WaitTime / time.Second),
+			VisibilityTimeout:     int32(t.config.VisibilityTimeout / time.Second),
+			MessageAttributeNames: []string{"All"},
+			MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{
+				sqstypes.MessageSystemAttributeNameSentTimestamp,
+				sqstypes.MessageSystemAttributeNameApproximateReceiveCount,
+				sqstypes.MessageSystemAttributeNameMessageGroupId,
+			},
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to receive from SQS: %w", err)
+		}
+		if len(out.Messages) == 0 {
+			continue
+		}
+
+		t.received.Add(int64(len(out.Messages)))
+		if err := t.handleBatch(ctx, out.Messages, handler); err != nil {
+			return err
+		}
+	}
+}
+
+// handleBatch handles msgs one at a time while keeping the ones not yet handled invisible. After a
+// failure the rest of its FIFO message group is left for redelivery, so it is not handled out of order.
+func (t *Transport) handleBatch(ctx context.Context, msgs []sqstypes.Message, handler transport.MessageHandler) error {
+	var (
+		mu      sync.Mutex
+		pending = msgs
+	)
+	failedGroups := make(map[string]bool)
+
+	extendCtx, stopExtending := context.WithCancel(ctx)
+	defer stopExtending()
+	go func() {
+		ticker := time.NewTicker(t.config.VisibilityTimeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-extendCtx.Done():
+				return
+			case <-ticker.C:
+				mu.Lock()
+				remaining := pending
+				mu.Unlock()
+				t.extendVisibility(extendCtx, remaining)
+			}
+		}
+	}()
+
+	for i, m := range msgs {
+		mu.Lock()
+		pending = msgs[i:]
+		mu.Unlock()
+
+		group := m.Attributes[string(sqstypes.MessageSystemAttributeNameMessageGroupId)]
+		if group != "" && failedGroups[group] {
+			continue
+		}
+
+		if err := handler(ctx, toMessage(m)); err != nil {
+			t.failed.Add(1)
+			if group != "" {
+				failedGroups[group] = true
+			}
+			logger.Warn("Handler failed, message will be redelivered after the visibility timeout",
+				"message_id", aws.ToString(m.MessageId),
+				"receive_count", m.Attributes[string(sqstypes.MessageSystemAttributeNameApproximateReceiveCount)],
+				"error", err)
+			continue
+		}
+
+		_, err := t.sqs.DeleteMessage(ctx, &awssqs.DeleteMessageInput{
+			QueueUrl:      aws.String(t.config.QueueURL),
+			ReceiptHandle: m.ReceiptHandle,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.Warn("Failed to delete message, it may be handled again", "message_id", aws.ToString(m.MessageId), "error", err)
+			continue
+		}
+		t.deleted.Add(1)
+	}
+
+	return nil
+}
+
+// extendVisibility resets the visibility timeout of msgs so they are not redelivered while waiting
+func (t *Transport) extendVisibility(ctx context.Context, msgs []sqstypes.Message) {
+	for start := 0; start < len(msgs); start += maxBatch {
+		batch := msgs[start:min(start+maxBatch, len(msgs))]
+
+		entries := make([]sqstypes.ChangeMessageVisibilityBatchRequestEntry, len(batch))
+		for i, m := range batch {
+			entries[i] = sqstypes.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				ReceiptHandle:     m.ReceiptHandle,
+				VisibilityTimeout: int32(t.config.VisibilityTimeout / time.Second),
+			}
+		}
+
+		out, err := t.sqs.ChangeMessageVisibilityBatch(ctx, &awssqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(t.config.QueueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("Failed to extend visibility timeout", "error", err)
+			}
+			continue
+		}
+		for _, f := range out.Failed {
+			logger.Warn("Failed to extend visibility timeout", "entry", aws.ToString(f.Id), "error", aws.ToString(f.Message))
+		}
+	}
+}
+
+// Publish sends msgs to the SNS topic with topic as message attribute. On FIFO topics messages
+// are grouped by key, so changes to a row stay in order, and deduplicated by content.
+func (t *Transport) Publish(ctx context.Context, topic string, msgs ...transport.Message) error {
+	if t.config.TopicARN == "" {
+		return errors.New("SNS topic ARN is not configured")
+	}
+	fifo := strings.HasSuffix(t.config.TopicARN, ".fifo")
+
+	for start := 0; start < len(msgs); start += maxBatch {
+		batch := msgs[start:min(start+maxBatch, len(msgs))]
+
+		entries := make([]snstypes.PublishBatchRequestEntry, len(batch))
+		for i, m := range batch {
+			entries[i] = toPublishEntry(strconv.Itoa(i), topic, m, fifo)
+		}
+
+		out, err := t.sns.PublishBatch(ctx, &awssns.PublishBatchInput{
+			TopicArn:                   aws.String(t.config.TopicARN),
+			PublishBatchRequestEntries: entries,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", t.config.TopicARN, err)
+		}
+		t.published.Add(int64(len(out.Successful)))
+		if len(out.Failed) > 0 {
+			f := out.Failed[0]
+			return fmt.Errorf("failed to publish %d of %d messages to %s: %s: %s",
+				len(out.Failed), len(batch), t.config.TopicARN, aws.ToString(f.Code), aws.ToString(f.Message))
+		}
+	}
+
+	return nil
+}
+
+// HealthCheck reports whether the queue is reachable
+func (t *Transport) HealthCheck(ctx context.Context) error {
+	_, err := t.sqs.GetQueueAttributes(ctx, &awssqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(t.config.QueueURL),
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameApproximateNumberOfMessages},
+	})
+	if err != nil {
+		return fmt.Errorf("SQS queue unreachable: %w", err)
+	}
+	return nil
+}
+
+// GetStats returns receive, delete and publish counters
+func (t *Transport) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"queue_url": t.config.QueueURL,
+		"topic_arn": t.config.TopicARN,
+		"received":  t.received.Load(),
+		"deleted":   t.deleted.Load(),
+		"failed":    t.failed.Load(),
+		"published": t.published.Load(),
+	}
+}
+
+// Close is a no-op, the AWS clients hold no connections that need closing
+func (t *Transport) Close() error {
+	return nil
+}
+
+// snsEnvelope is the JSON body of messages delivered from SNS without raw message delivery
+type snsEnvelope struct {
+	Type              string `json:"Type"`
+	Message           string `json:"Message"`
+	Timestamp         string `json:"Timestamp"`
+	MessageAttributes map[string]struct {
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+// toMessage converts an SQS message, unwrapping SNS envelopes and base64 encoded bodies
+func toMessage(m sqstypes.Message) transport.Message {
+	body := aws.ToString(m.Body)
+	attrs := make(map[string]string, len(m.MessageAttributes))
+	for k, v := range m.MessageAttributes {
+		if v.StringValue != nil {
+			attrs[k] = *v.StringValue
+		} else if v.BinaryValue != nil {
+			attrs[k] = string(v.BinaryValue)
+		}
+	}
+
+	var sentAt time.Time
+	if ms, err := strconv.ParseInt(m.Attributes[string(sqstypes.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+		sentAt = time.UnixMilli(ms)
+	}
+
+	var envelope snsEnvelope
+	if err := json.Unmarshal([]byte(body), &envelope); err == nil && envelope.Type == "Notification" {
+		body = envelope.Message
+		for k, v := range envelope.MessageAttributes {
+			attrs[k] = v.Value
+		}
+		if ts, err := time.Parse(time.RFC3339, envelope.Timestamp); err == nil {
+			sentAt = ts
+		}
+	}
+
+	value := []byte(body)
+	if attrs[EncodingAttribute] == "base64" {
+		if decoded, err := base64.StdEncoding.DecodeString(body); err == nil {
+			value = decoded
+		}
+	}
+
+	msg := transport.Message{
+		Topic:   attrs[TopicAttribute],
+		Value:   value,
+		Headers: attrs,
+		Time:    sentAt,
+	}
+	if key, ok := attrs[KeyAttribute]; ok {
+		msg.Key = []byte(key)
+	}
+	return msg
+}
+
+// toPublishEntry converts a message into an SNS batch entry, base64 encoding binary values
+func toPublishEntry(id, topic string, m transport.Message, fifo bool) snstypes.PublishBatchRequestEntry {
+	attrs := make(map[string]snstypes.MessageAttributeValue, len(m.Headers)+3)
+	for k, v := range m.Headers {
+		attrs[k] = stringAttribute(v)
+	}
+	attrs[TopicAttribute] = stringAttribute(topic)
+	if m.Key != nil {
+		attrs[KeyAttribute] = stringAttribute(string(m.Key))
+	}
+
+	body := string(m.Value)
+	if !utf8.Valid(m.Value) {
+		body = base64.StdEncoding.EncodeToString(m.Value)
+		attrs[EncodingAttribute] = stringAttribute("base64")
+	}
+
+	entry := snstypes.PublishBatchRequestEntry{
+		Id:                aws.String(id),
+		Message:           aws.String(body),
+		MessageAttributes: attrs,
+	}
+	if fifo {
+		group := string(m.Key)
+		if group == "" {
+			group = topic
+		}
+		// Group ids are limited to 128 characters, long composite keys are hashed
+		if len(group) > 128 {
+			groupSum := sha256.Sum256([]byte(group))
+			group = hex.EncodeToString(groupSum[:])
+		}
+		sum := sha256.Sum256([]byte(topic + "\x00" + string(m.Key) + "\x00" + string(m.Value)))
+		entry.MessageGroupId = aws.String(group)
+		entry.MessageDeduplicationId = aws.String(hex.EncodeToString(sum[:]))
+	}
+	return entry
+}
+
+func stringAttribute(value string) snstypes.MessageAttributeValue {
+	return snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+}
@@ -0,0 +1,49 @@
+// Package transport defines the message broker abstraction used to run the engine pipeline on
+// brokers other than Kafka. Implementations live in subpackages, e.g. transport/rabbitmq.
+package transport
+
+import (
+	"context"
+	"time"
+)
+
+// Message is a broker neutral message. Topic is the Kafka topic or its equivalent on other
+// brokers (e.g. the AMQP routing key), so Debezium destinations keep their names.
+type Message struct {
+	Topic     string
+	Partition int   // Kafka partition, 0 on brokers without partitions
+	Offset    int64 // Kafka offset or broker delivery sequence, for logging
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Time      time.Time
+}
+
+// MessageHandler processes one message. Returning an error rejects the message, which the
+// transport redelivers or dead-letters according to its configuration.
+type MessageHandler func(ctx context.Context, m Message) error
+
+// Consumer receives messages from a broker
+type Consumer interface {
+	// Consume delivers messages to handler until ctx is done or the connection fails.
+	// A message is acknowledged once handler returns nil.
+	Consume(ctx context.Context, handler MessageHandler) error
+	Close() error
+}
+
+// Producer publishes messages to a broker
+type Producer interface {
+	// Publish sends msgs to topic, returning once the broker has accepted all of them
+	Publish(ctx context.Context, topic string, msgs ...Message) error
+	Close() error
+}
+
+// Transport is a broker connection that can both consume and publish
+type Transport interface {
+	Consumer
+	Producer
+	// HealthCheck reports whether the broker is reachable
+	HealthCheck(ctx context.Context) error
+	// GetStats returns counters for the admin server's /stats endpoint
+	GetStats() map[string]interface{}
+}
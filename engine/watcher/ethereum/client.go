@@ -0,0 +1,226 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// callTimeout bounds each call
+const callTimeout = 30 * time.Second
+
+// rpcError is an error answered by the node
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("node error %d: %s", e.Code, e.Message)
+}
+
+// message is a reply or a subscription notification from the node
+type message struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// pendingCall waits for the reply to a call. sub receives the notifications of the subscription
+// the call creates, if it is an eth_subscribe.
+type pendingCall struct {
+	reply chan *message
+	sub   chan json.RawMessage
+}
+
+// client calls a node's JSON-RPC API over a WebSocket connection, on which the node also sends the
+// notifications of subscriptions
+type client struct {
+	ws     *wsConn
+	nextID atomic.Int64
+
+	mu      sync.Mutex
+	pending map[int64]*pendingCall
+	subs    map[string]chan json.RawMessage
+
+	// done is closed with err set once the connection failed
+	done chan struct{}
+	err  error
+
+	// dropped counts the notifications discarded because their subscriber was behind
+	dropped *atomic.Int64
+}
+
+// dial connects to the node at url, sending apiKey as a bearer token when set. Notifications
+// dropped are counted in dropped.
+func dial(ctx context.Context, url, apiKey string, idleTimeout time.Duration, dropped *atomic.Int64) (*client, error) {
+	header := make(http.Header)
+	if apiKey != "" {
+		header.Set("Authorization", "Bearer "+apiKey)
+	}
+	ws, err := dialWS(ctx, url, header, idleTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		ws:      ws,
+		pending: make(map[int64]*pendingCall),
+		subs:    make(map[string]chan json.RawMessage),
+		done:    make(chan struct{}),
+		dropped: dropped,
+	}
+	go c.read()
+	return c, nil
+}
+
+// Call calls method with params and decodes its result into result
+func (c *client) Call(ctx context.Context, result any, method string, params ...any) error {
+	raw, err := c.send(ctx, nil, method, params)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Subscribe calls eth_subscribe with params, sending the results of its notifications to ch. A
+// notification is dropped when ch is full, so the connection is never held up by a slow reader.
+func (c *client) Subscribe(ctx context.Context, ch chan json.RawMessage, params ...any) (string, error) {
+	raw, err := c.send(ctx, ch, "eth_subscribe", params)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return "", fmt.Errorf("failed to decode eth_subscribe result: %w", err)
+	}
+	return id, nil
+}
+
+func (c *client) send(ctx context.Context, sub chan json.RawMessage, method string, params []any) (json.RawMessage, error) {
+	if params == nil {
+		params = []any{}
+	}
+	id := c.nextID.Add(1)
+	body, err := json.Marshal(struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      int64  `json:"id"`
+		Method  string `json:"method"`
+		Params  []any  `json:"params"`
+	}{"2.0", id, method, params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s call: %w", method, err)
+	}
+
+	call := &pendingCall{reply: make(chan *message, 1), sub: sub}
+	c.mu.Lock()
+	c.pending[id] = call
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.ws.WriteMessage(body); err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+	select {
+	case reply := <-call.reply:
+		if reply.Error != nil {
+			return nil, fmt.Errorf("%s failed: %w", method, reply.Error)
+		}
+		if len(reply.Result) == 0 {
+			return json.RawMessage("null"), nil
+		}
+		return reply.Result, nil
+	case <-c.done:
+		return nil, fmt.Errorf("failed to call %s: %w", method, c.err)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to call %s: %w", method, ctx.Err())
+	}
+}
+
+// read passes the node's replies to their calls and its notifications to their subscriptions,
+// until the connection fails
+func (c *client) read() {
+	for {
+		data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.err = err
+			close(c.done)
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.Warn("Ignoring undecodable message from node", "error", err)
+			continue
+		}
+
+		if msg.Method == "eth_subscription" {
+			var n struct {
+				Subscription string          `json:"subscription"`
+				Result       json.RawMessage `json:"result"`
+			}
+			if err := json.Unmarshal(msg.Params, &n); err != nil {
+				logger.Warn("Ignoring undecodable notification from node", "error", err)
+				continue
+			}
+			c.mu.Lock()
+			ch := c.subs[n.Subscription]
+			c.mu.Unlock()
+			if ch == nil {
+				continue
+			}
+			select {
+			case ch <- n.Result:
+			default:
+				c.dropped.Add(1)
+			}
+			continue
+		}
+
+		if msg.ID == nil {
+			continue
+		}
+		c.mu.Lock()
+		call, ok := c.pending[*msg.ID]
+		// The subscription is known before its first notification is read, which follows the reply
+		if ok && call.sub != nil && msg.Error == nil {
+			var id string
+			if json.Unmarshal(msg.Result, &id) == nil {
+				c.subs[id] = call.sub
+			}
+		}
+		c.mu.Unlock()
+		if ok {
+			call.reply <- &msg
+		}
+	}
+}
+
+// Done is closed once the connection failed, err is then why
+func (c *client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close closes the connection
+func (c *client) Close() error {
+	return c.ws.Close()
+}
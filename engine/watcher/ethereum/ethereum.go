@@ -0,0 +1,516 @@
+// Package ethereum watches an Ethereum node, or the node of another EVM chain, for the transfers of
+// the watched addresses. It subscribes to the node's new heads and pending transactions over
+// WebSocket (eth_subscribe), checks the transactions and ERC-20 transfers of every new block and
+// every pending transaction against the watched addresses, and emits a watcher.Match for each
+// transfer involving one of them:
+//
+//   - a pending transaction is emitted as pending when the node announces it, if
+//     PendingTransactions is set;
+//   - a mined transfer is emitted as pending when its block is scanned, with the block set, then as
+//     confirmed or failed once Confirmations blocks are mined, or as dropped when a reorganization
+//     removed its transaction from the chain by then.
+//
+// Blocks missed while the connection was down are scanned when it is back, up to MaxBackfill.
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcher"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/watcherstatus"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+)
+
+// logger is used by the Ethereum watcher
+var logger = logging.For("ethereum_watcher")
+
+// transferTopic is the topic of ERC-20 Transfer(address,address,uint256) events
+const transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// dialTimeout bounds connecting to the node
+const dialTimeout = 15 * time.Second
+
+// Config configures a Watcher
+type Config struct {
+	// Chain is the chain of the node, one of shared/chains in the EVM family (default ethereum)
+	Chain string
+	// URL is the node's WebSocket endpoint, ws:// or wss://
+	URL string
+	// APIKey is sent as a bearer token when set
+	APIKey string
+	// Confirmations is the number of blocks, counting its own, after which a mined transfer is
+	// final (default 12)
+	Confirmations int
+	// PendingTransactions also matches the transactions of the node's mempool. The node must send
+	// them in full (geth 1.11 and later), hashes alone are ignored.
+	PendingTransactions bool
+	// IdleTimeout fails the run when the node sent nothing for that long (default 2m)
+	IdleTimeout time.Duration
+	// MaxBackfill is the most blocks scanned to catch up with the node (default 128)
+	MaxBackfill int
+	// Emit receives the matches, on the watcher's goroutine, e.g. watcher.Dispatcher.Emit
+	Emit func(watcher.Match)
+	// Status, when set, records the scanned blocks, the activity of the addresses and the failed runs
+	Status *watcherstatus.Store
+}
+
+// Watcher watches an EVM node for the transfers of the addresses it watches, see the package doc
+//
+// Example usage:
+//
+//	w, err := ethereum.New(ethereum.Config{URL: "wss://node.example.com", Emit: dispatcher.Emit})
+//	dispatcher.Register(chains.Ethereum, w)
+type Watcher struct {
+	config Config
+
+	mu        sync.RWMutex
+	addresses map[string]struct{}
+
+	// lastBlock, held and warnedHashes are used by Run only
+	lastBlock int64
+	// held are the mined transactions with matches waiting for their confirmations, by hash
+	held         map[string]*heldTx
+	warnedHashes bool
+
+	head      atomic.Int64
+	heads     atomic.Int64
+	blocks    atomic.Int64
+	skipped   atomic.Int64
+	reorgs    atomic.Int64
+	pending   atomic.Int64
+	matches   atomic.Int64
+	dropped   atomic.Int64
+	heldCount atomic.Int64
+}
+
+// heldTx is a mined transaction with matches, waiting for its confirmations
+type heldTx struct {
+	blockNumber int64
+	blockHash   string
+	matches     []watcher.Match
+}
+
+// New creates a Watcher of the node config.URL
+func New(config Config) (*Watcher, error) {
+	if config.Chain == "" {
+		config.Chain = chains.Ethereum
+	}
+	c, ok := chains.Get(config.Chain)
+	if !ok || c.Family != chains.EVM {
+		return nil, fmt.Errorf("%q is not an EVM chain", config.Chain)
+	}
+	config.Chain = c.Name
+	if !strings.HasPrefix(config.URL, "ws://") && !strings.HasPrefix(config.URL, "wss://") {
+		return nil, fmt.Errorf("node URL must be a ws:// or wss:// URL")
+	}
+	if config.Confirmations <= 0 {
+		config.Confirmations = 12
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 2 * time.Minute
+	}
+	if config.MaxBackfill <= 0 {
+		config.MaxBackfill = 128
+	}
+	return &Watcher{
+		config:    config,
+		addresses: make(map[string]struct{}),
+		held:      make(map[string]*heldTx),
+	}, nil
+}
+
+// Watch starts matching the transfers of address
+func (w *Watcher) Watch(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.addresses[strings.ToLower(address)] = struct{}{}
+}
+
+// Unwatch stops matching the transfers of address
+func (w *Watcher) Unwatch(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.addresses, strings.ToLower(address))
+}
+
+// watchingAny returns whether any address is watched
+func (w *Watcher) watchingAny() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.addresses) > 0
+}
+
+// Run follows the node until ctx is done or the connection fails
+func (w *Watcher) Run(ctx context.Context) error {
+	err := w.run(ctx)
+	if err != nil && ctx.Err() == nil && w.config.Status != nil {
+		statusCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := w.config.Status.Failed(statusCtx, w.config.Chain, err); err != nil {
+			logger.Warn("Failed to record watcher status", "chain", w.config.Chain, "error", err)
+		}
+	}
+	return err
+}
+
+func (w *Watcher) run(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	c, err := dial(dialCtx, w.config.URL, w.config.APIKey, w.config.IdleTimeout, &w.dropped)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	// Closing the connection unblocks the calls in flight
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	heads := make(chan json.RawMessage, 64)
+	if _, err := c.Subscribe(ctx, heads, "newHeads"); err != nil {
+		return err
+	}
+	var pending chan json.RawMessage
+	if w.config.PendingTransactions {
+		pending = make(chan json.RawMessage, 4096)
+		if _, err := c.Subscribe(ctx, pending, "newPendingTransactions", true); err != nil {
+			return err
+		}
+	}
+	logger.Info("Subscribed to node", "chain", w.config.Chain, "pending_transactions", w.config.PendingTransactions)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.Done():
+			return fmt.Errorf("connection to node failed: %w", c.err)
+		case raw := <-heads:
+			var h struct {
+				Number string `json:"number"`
+			}
+			if err := json.Unmarshal(raw, &h); err != nil {
+				return fmt.Errorf("failed to decode head: %w", err)
+			}
+			number, err := strconv.ParseInt(h.Number, 0, 64)
+			if err != nil {
+				return fmt.Errorf("invalid head number %q: %w", h.Number, err)
+			}
+			if err := w.newHead(ctx, c, number); err != nil {
+				return err
+			}
+		case raw := <-pending:
+			w.pendingTx(ctx, raw)
+		}
+	}
+}
+
+// newHead scans the blocks up to the new head and confirms the held transfers deep enough
+func (w *Watcher) newHead(ctx context.Context, c *client, number int64) error {
+	w.heads.Add(1)
+	w.head.Store(number)
+
+	from := number
+	switch {
+	case w.lastBlock == 0:
+	case number <= w.lastBlock:
+		// The head replaces blocks scanned already, which are scanned again
+		w.reorgs.Add(1)
+		logger.Info("Chain reorganized, scanning replaced blocks again", "chain", w.config.Chain, "block", number, "last_block", w.lastBlock)
+	case number-w.lastBlock > int64(w.config.MaxBackfill):
+		from = number - int64(w.config.MaxBackfill) + 1
+		w.skipped.Add(from - w.lastBlock - 1)
+		logger.Warn("Too far behind the node, skipping blocks", "chain", w.config.Chain, "from", w.lastBlock+1, "to", from-1)
+	default:
+		from = w.lastBlock + 1
+	}
+
+	for n := from; n <= number; n++ {
+		if err := w.scanBlock(ctx, c, n); err != nil {
+			return err
+		}
+		w.lastBlock = n
+	}
+	if err := w.confirm(ctx, c, number); err != nil {
+		return err
+	}
+
+	if w.config.Status != nil {
+		if err := w.config.Status.Scanned(ctx, w.config.Chain, watcherstatus.ModeSubscription, number); err != nil {
+			logger.Warn("Failed to record watcher status", "chain", w.config.Chain, "error", err)
+		}
+	}
+	return nil
+}
+
+// block is a block with its transactions, as returned by eth_getBlockByNumber
+type block struct {
+	Hash         string        `json:"hash"`
+	Timestamp    string        `json:"timestamp"`
+	Transactions []transaction `json:"transactions"`
+}
+
+type transaction struct {
+	Hash  string `json:"hash"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+}
+
+type logEntry struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	LogIndex        string   `json:"logIndex"`
+	TransactionHash string   `json:"transactionHash"`
+	Removed         bool     `json:"removed"`
+}
+
+type receipt struct {
+	Status      string `json:"status"`
+	BlockNumber string `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+}
+
+// scanBlock matches the transactions and ERC-20 transfers of block number, holding the matches
+// until they are confirmed
+func (w *Watcher) scanBlock(ctx context.Context, c *client, number int64) error {
+	defer w.blocks.Add(1)
+	if !w.watchingAny() {
+		return nil
+	}
+
+	var b *block
+	if err := c.Call(ctx, &b, "eth_getBlockByNumber", "0x"+strconv.FormatInt(number, 16), true); err != nil {
+		return err
+	}
+	if b == nil {
+		return fmt.Errorf("block %d not found", number)
+	}
+	seconds, err := strconv.ParseInt(b.Timestamp, 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp of block %d: %w", number, err)
+	}
+	at := time.Unix(seconds, 0).UTC()
+
+	var found []watcher.Match
+	for _, tx := range b.Transactions {
+		value, err := parseQuantity(tx.Value)
+		if err != nil {
+			return fmt.Errorf("invalid value of transaction %s: %w", tx.Hash, err)
+		}
+		found = append(found, w.match(watcher.Match{
+			Hash: tx.Hash, LogIndex: -1, BlockNumber: number, BlockHash: b.Hash,
+			From: tx.From, To: tx.To, Value: value, Time: at,
+		})...)
+	}
+
+	var logs []logEntry
+	filter := map[string]any{"blockHash": b.Hash, "topics": []string{transferTopic}}
+	if err := c.Call(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return err
+	}
+	for _, l := range logs {
+		// ERC-721 transfers index the token ID too, only ERC-20 ones have three topics
+		if l.Removed || len(l.Topics) != 3 {
+			continue
+		}
+		index, err := strconv.ParseInt(l.LogIndex, 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid log index of transaction %s: %w", l.TransactionHash, err)
+		}
+		value, err := parseQuantity(l.Data)
+		if err != nil {
+			return fmt.Errorf("invalid transfer value of transaction %s: %w", l.TransactionHash, err)
+		}
+		found = append(found, w.match(watcher.Match{
+			Hash: l.TransactionHash, LogIndex: index, BlockNumber: number, BlockHash: b.Hash,
+			From: topicAddress(l.Topics[1]), To: topicAddress(l.Topics[2]), Value: value,
+			Token: strings.ToLower(l.Address), Time: at,
+		})...)
+	}
+
+	// A block scanned again replaces the matches held for its transactions
+	scanned := make(map[string]bool)
+	for _, m := range found {
+		h := w.held[m.Hash]
+		if !scanned[m.Hash] {
+			scanned[m.Hash] = true
+			h = &heldTx{blockNumber: number, blockHash: m.BlockHash}
+			w.held[m.Hash] = h
+		}
+		h.matches = append(h.matches, m)
+		if w.config.Confirmations > 1 {
+			m.Status = watcher.StatusPending
+			w.emit(ctx, m)
+		}
+	}
+	w.heldCount.Store(int64(len(w.held)))
+	return nil
+}
+
+// confirm emits the held transfers of the blocks with enough confirmations at head, with the
+// status of their transaction's receipt
+func (w *Watcher) confirm(ctx context.Context, c *client, head int64) error {
+	for hash, h := range w.held {
+		if h.blockNumber+int64(w.config.Confirmations)-1 > head {
+			continue
+		}
+
+		var r *receipt
+		if err := c.Call(ctx, &r, "eth_getTransactionReceipt", hash); err != nil {
+			return err
+		}
+		status := watcher.StatusConfirmed
+		switch {
+		case r == nil:
+			status = watcher.StatusDropped
+		case r.BlockHash != h.blockHash:
+			// A reorganization moved the transaction to another block, it is confirmed with that one
+			number, err := strconv.ParseInt(r.BlockNumber, 0, 64)
+			if err != nil {
+				return fmt.Errorf("invalid block number of receipt %s: %w", hash, err)
+			}
+			h.blockNumber, h.blockHash = number, r.BlockHash
+			for i := range h.matches {
+				h.matches[i].BlockNumber, h.matches[i].BlockHash = number, r.BlockHash
+			}
+			continue
+		case r.Status == "0x0":
+			status = watcher.StatusFailed
+		}
+
+		for _, m := range h.matches {
+			m.Status = status
+			w.emit(ctx, m)
+		}
+		delete(w.held, hash)
+	}
+	w.heldCount.Store(int64(len(w.held)))
+	return nil
+}
+
+// pendingTx matches a transaction announced by the node
+func (w *Watcher) pendingTx(ctx context.Context, raw json.RawMessage) {
+	w.pending.Add(1)
+	if len(raw) > 0 && raw[0] == '"' {
+		if !w.warnedHashes {
+			w.warnedHashes = true
+			logger.Warn("Node sends only the hashes of pending transactions, they are matched once mined", "chain", w.config.Chain)
+		}
+		return
+	}
+
+	var tx transaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		logger.Warn("Ignoring undecodable pending transaction", "chain", w.config.Chain, "error", err)
+		return
+	}
+	value, err := parseQuantity(tx.Value)
+	if err != nil {
+		logger.Warn("Ignoring pending transaction with an invalid value", "chain", w.config.Chain, "hash", tx.Hash, "error", err)
+		return
+	}
+	for _, m := range w.match(watcher.Match{
+		Hash: tx.Hash, LogIndex: -1, From: tx.From, To: tx.To, Value: value,
+		Status: watcher.StatusPending, Time: time.Now().UTC(),
+	}) {
+		w.emit(ctx, m)
+	}
+}
+
+// match returns m for each watched address it involves, with the address and direction set
+func (w *Watcher) match(m watcher.Match) []watcher.Match {
+	m.Chain = w.config.Chain
+	m.From = strings.ToLower(m.From)
+	m.To = strings.ToLower(m.To)
+
+	w.mu.RLock()
+	_, from := w.addresses[m.From]
+	_, to := w.addresses[m.To]
+	w.mu.RUnlock()
+
+	var matches []watcher.Match
+	if from {
+		out := m
+		out.Address, out.Direction = m.From, "out"
+		matches = append(matches, out)
+	}
+	if to && m.To != "" {
+		in := m
+		in.Address, in.Direction = m.To, "in"
+		matches = append(matches, in)
+	}
+	return matches
+}
+
+// emit passes m on and records the activity of its address once its transaction is final
+func (w *Watcher) emit(ctx context.Context, m watcher.Match) {
+	w.matches.Add(1)
+	if w.config.Emit != nil {
+		w.config.Emit(m)
+	}
+
+	final := m.Status == watcher.StatusConfirmed || m.Status == watcher.StatusFailed
+	if final && w.config.Status != nil {
+		if err := w.config.Status.Activity(ctx, m.Chain, m.Address, m.BlockNumber, m.Hash, m.Time); err != nil {
+			logger.Warn("Failed to record watcher status", "chain", m.Chain, "address", m.Address, "error", err)
+		}
+	}
+}
+
+// GetStats returns the node's head, the heads, blocks and pending transactions seen, the blocks
+// skipped catching up, the reorganizations, the matches emitted, the transactions waiting for
+// their confirmations and the notifications dropped while the watcher was behind
+func (w *Watcher) GetStats() map[string]interface{} {
+	w.mu.RLock()
+	watched := len(w.addresses)
+	w.mu.RUnlock()
+
+	return map[string]interface{}{
+		"chain":                 w.config.Chain,
+		"watched":               watched,
+		"head":                  w.head.Load(),
+		"heads":                 w.heads.Load(),
+		"blocks_scanned":        w.blocks.Load(),
+		"blocks_skipped":        w.skipped.Load(),
+		"reorgs":                w.reorgs.Load(),
+		"pending_transactions":  w.pending.Load(),
+		"matches":               w.matches.Load(),
+		"awaiting_confirmation": w.heldCount.Load(),
+		"dropped_notifications": w.dropped.Load(),
+	}
+}
+
+// parseQuantity parses a hex quantity or 32-byte word, "0x" being zero
+func parseQuantity(s string) (*big.Int, error) {
+	digits := strings.TrimPrefix(s, "0x")
+	if digits == "" {
+		return new(big.Int), nil
+	}
+	// Non-standard tokens may log more than the value, which is the first word
+	if len(digits) > 64 {
+		digits = digits[:64]
+	}
+	v, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	return v, nil
+}
+
+// topicAddress returns the address in an indexed address topic
+func topicAddress(topic string) string {
+	if len(topic) < 40 {
+		return ""
+	}
+	return "0x" + strings.ToLower(topic[len(topic)-40:])
+}
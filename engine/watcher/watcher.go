@@ -0,0 +1,274 @@
+// Package watcher scans chains for the transfers of the watched addresses. Each chain has its own
+// Watcher, e.g. one following an Ethereum node and one polling a Bitcoin node, and the Dispatcher
+// passes each chain's addresses to its watcher as they start and stop being watched, so the
+// engine assumes no single chain. Chains are those of shared/chains.
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+)
+
+// logger is used by the dispatcher
+var logger = logging.For("watchers")
+
+// restartDelay is how long a watcher that failed waits before it is run again
+const restartDelay = 5 * time.Second
+
+// Watcher watches the addresses of one chain
+type Watcher interface {
+	// Watch starts watching address. It is called on the consumer's goroutine and must not block;
+	// the address is picked up by the watcher's next scan.
+	Watch(address string)
+	// Unwatch stops watching address, like Watch
+	Unwatch(address string)
+	// Run scans the chain until ctx is done. It is run again after a delay when it fails.
+	Run(ctx context.Context) error
+}
+
+// Statuses of a Match
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+	StatusDropped   = "dropped"
+)
+
+// Match is a transfer to or from a watched address found by a chain's watcher. A transaction
+// moving several tokens has one Match per transfer, and a transfer between two watched addresses
+// one per address. The same transfer is matched again as its status changes.
+type Match struct {
+	Chain string
+	// Address is the watched address involved, as normalized by shared/chains
+	Address string
+	// Direction is in or out, as seen from Address
+	Direction string
+	Hash      string
+	// LogIndex tells apart the transfers of a transaction, -1 for the native transfer
+	LogIndex int64
+	// BlockNumber and BlockHash are unset while the transaction is pending in the mempool
+	BlockNumber int64
+	BlockHash   string
+	From        string
+	// To is empty for contract creations
+	To string
+	// Value is in the token's smallest unit
+	Value *big.Int
+	// Token is the token contract, empty for the chain's native currency
+	Token string
+	// Status is pending, confirmed, failed or dropped
+	Status string
+	// Time is the block time, or when a transaction still in the mempool was seen
+	Time time.Time
+}
+
+// Dispatcher passes the changes of a watchlist to the watchers of their chains. Addresses of a
+// chain without a watcher are counted, and logged once per chain, but not scanned.
+//
+// Example usage:
+//
+//	dispatcher := watcher.NewDispatcher()
+//	dispatcher.Register(chains.Ethereum, ethereumWatcher)
+//	watched.Observe(dispatcher.Observe)
+//	dispatcher.OnMatch(func(m watcher.Match) { ... })
+//	go dispatcher.Run(ctx)
+type Dispatcher struct {
+	mu          sync.Mutex
+	watchers    map[string]Watcher
+	onMatch     []func(Match)
+	matches     map[string]int // chain -> matches emitted
+	watched     map[string]int // chain -> watched addresses
+	unsupported map[string]int // chain -> watched addresses without a watcher
+	failures    map[string]int // chain -> failed runs of its watcher
+	lastErrors  map[string]string
+}
+
+// NewDispatcher creates a Dispatcher without watchers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		watchers:    make(map[string]Watcher),
+		matches:     make(map[string]int),
+		watched:     make(map[string]int),
+		unsupported: make(map[string]int),
+		failures:    make(map[string]int),
+		lastErrors:  make(map[string]string),
+	}
+}
+
+// Register makes w the watcher of chain. Watchers are registered before the dispatcher observes
+// any address; registering a chain twice or one shared/chains does not know fails.
+func (d *Dispatcher) Register(chain string, w Watcher) error {
+	c, ok := chains.Get(chain)
+	if !ok {
+		return fmt.Errorf("unknown chain %q", chain)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.watchers[c.Name]; ok {
+		return fmt.Errorf("chain %s already has a watcher", c.Name)
+	}
+	d.watchers[c.Name] = w
+	return nil
+}
+
+// Chains returns the chains with a watcher, sorted
+func (d *Dispatcher) Chains() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	names := make([]string, 0, len(d.watchers))
+	for name := range d.watchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Observe passes a change of a watchlist to the watcher of chain, see watchlist.Watchlist.Observe
+func (d *Dispatcher) Observe(chain, address string, watching bool) {
+	name := strings.ToLower(chain)
+	if c, ok := chains.Get(chain); ok {
+		name = c.Name
+	}
+
+	d.mu.Lock()
+	w, ok := d.watchers[name]
+	delta := 1
+	if !watching {
+		delta = -1
+	}
+	d.watched[name] += delta
+	if !ok {
+		if watching && d.unsupported[name] == 0 {
+			logger.Warn("No watcher for chain, its addresses are not scanned", "chain", name)
+		}
+		d.unsupported[name] += delta
+	}
+	d.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if watching {
+		w.Watch(address)
+	} else {
+		w.Unwatch(address)
+	}
+}
+
+// OnMatch adds fn to the functions receiving the matches of every watcher. Functions are added
+// before the dispatcher runs; they are called on the watcher's goroutine and must not block for
+// long, since the watcher does not scan meanwhile.
+func (d *Dispatcher) OnMatch(fn func(Match)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onMatch = append(d.onMatch, fn)
+}
+
+// Emit passes m to the functions added with OnMatch. Watchers emit their matches with it.
+func (d *Dispatcher) Emit(m Match) {
+	d.mu.Lock()
+	d.matches[m.Chain]++
+	fns := d.onMatch
+	d.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(m)
+	}
+}
+
+// Run runs every registered watcher until ctx is done, running a watcher that failed again after
+// a delay. It returns once every watcher has returned.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.mu.Lock()
+	registered := make(map[string]Watcher, len(d.watchers))
+	for name, w := range d.watchers {
+		registered[name] = w
+	}
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, w := range registered {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.run(ctx, name, w)
+		}()
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) run(ctx context.Context, chain string, w Watcher) {
+	logger.Info("Starting chain watcher", "chain", chain)
+	for {
+		err := w.Run(ctx)
+		if ctx.Err() != nil {
+			logger.Info("Chain watcher stopped", "chain", chain)
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("watcher returned")
+		}
+
+		d.mu.Lock()
+		d.failures[chain]++
+		d.lastErrors[chain] = err.Error()
+		d.mu.Unlock()
+		logger.Error("Chain watcher failed, restarting", "chain", chain, "error", err, "delay", restartDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+	}
+}
+
+// GetStats returns the chains with a watcher, the watched addresses per chain, those of chains
+// without a watcher, the matches emitted per chain, the failed runs of each watcher with its last
+// error, and the stats of the watchers having a GetStats method by chain
+func (d *Dispatcher) GetStats() map[string]interface{} {
+	chainNames := d.Chains()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	watcherStats := make(map[string]interface{})
+	for name, w := range d.watchers {
+		if s, ok := w.(interface{ GetStats() map[string]interface{} }); ok {
+			watcherStats[name] = s.GetStats()
+		}
+	}
+
+	copyCounts := func(m map[string]int) map[string]int {
+		c := make(map[string]int, len(m))
+		for k, v := range m {
+			if v != 0 {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	lastErrors := make(map[string]string, len(d.lastErrors))
+	for k, v := range d.lastErrors {
+		lastErrors[k] = v
+	}
+	return map[string]interface{}{
+		"chains":      chainNames,
+		"watched":     copyCounts(d.watched),
+		"unsupported": copyCounts(d.unsupported),
+		"matches":     copyCounts(d.matches),
+		"failures":    copyCounts(d.failures),
+		"last_errors": lastErrors,
+		"watchers":    watcherStats,
+	}
+}
@@ -0,0 +1,128 @@
+// Package watcherstatus records the state of the engine's watch of each address in Postgres, so
+// users can check their address is being monitored (GET /addresses/{id}/status on the
+// api-server). The table is created by the api-server migrations (000035_create_watcher_status).
+package watcherstatus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// logger is used by the status store
+var logger = logging.For("watcher_status")
+
+// Scan modes of a chain
+const (
+	ModeSubscription = "subscription"
+	ModePolling      = "polling"
+)
+
+// Store is the watcher_status table
+//
+// Example usage:
+//
+//	status := watcherstatus.NewStore(pool)
+//	watched.Observe(status.Observe)
+//	...
+//	status.Scanned(ctx, "ethereum", watcherstatus.ModeSubscription, block)
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// NewStore creates a Store writing to the database of pool
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// Watching marks address on chain as watched. The watch keeps its start time and scan progress
+// while it is watched already.
+func (s *Store) Watching(ctx context.Context, chain, address string) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO watcher_status (chain, address, watching, watching_since)
+		VALUES ($1, $2, true, NOW())
+		ON CONFLICT (chain, address) DO UPDATE
+		SET watching = true,
+			watching_since = CASE WHEN watcher_status.watching THEN watcher_status.watching_since ELSE NOW() END,
+			updated_at = NOW()`,
+		chain, address)
+	if err != nil {
+		return fmt.Errorf("failed to record watch of %s address: %w", chain, err)
+	}
+	return nil
+}
+
+// Unwatched marks address on chain as no longer watched by anyone. The row is kept, so the last
+// activity seen is still known when the address is watched again.
+func (s *Store) Unwatched(ctx context.Context, chain, address string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE watcher_status SET watching = false, updated_at = NOW()
+		WHERE chain = $1 AND address = $2`,
+		chain, address)
+	if err != nil {
+		return fmt.Errorf("failed to record unwatch of %s address: %w", chain, err)
+	}
+	return nil
+}
+
+// Scanned records that every watched address of chain was checked up to block, clearing the
+// errors of earlier scans
+func (s *Store) Scanned(ctx context.Context, chain, mode string, block int64) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE watcher_status
+		SET mode = $2, last_scanned_block = $3, last_scanned_at = NOW(), error_count = 0, updated_at = NOW()
+		WHERE chain = $1 AND watching`,
+		chain, mode, block)
+	if err != nil {
+		return fmt.Errorf("failed to record scan of %s: %w", chain, err)
+	}
+	return nil
+}
+
+// Activity records a transfer to or from address on chain, at is its block time. Transfers older
+// than the last one recorded, e.g. from a backfill, are ignored.
+func (s *Store) Activity(ctx context.Context, chain, address string, block int64, hash string, at time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE watcher_status
+		SET last_activity_block = $3, last_activity_hash = $4, last_activity_at = $5, updated_at = NOW()
+		WHERE chain = $1 AND address = $2
+			AND (last_activity_block IS NULL OR last_activity_block <= $3)`,
+		chain, address, block, hash, at)
+	if err != nil {
+		return fmt.Errorf("failed to record activity of %s address: %w", chain, err)
+	}
+	return nil
+}
+
+// Failed records a failed scan of chain on each of its watched addresses
+func (s *Store) Failed(ctx context.Context, chain string, scanErr error) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE watcher_status
+		SET last_error = $2, last_error_at = NOW(), error_count = error_count + 1, updated_at = NOW()
+		WHERE chain = $1 AND watching`,
+		chain, scanErr.Error())
+	if err != nil {
+		return fmt.Errorf("failed to record scan error of %s: %w", chain, err)
+	}
+	return nil
+}
+
+// Observe records the changes of a watchlist, see watchlist.Watchlist.Observe. Failures are
+// logged, the status is only informational and must not hold up the change events.
+func (s *Store) Observe(chain, address string, watching bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	if watching {
+		err = s.Watching(ctx, chain, address)
+	} else {
+		err = s.Unwatched(ctx, chain, address)
+	}
+	if err != nil {
+		logger.Warn("Failed to record watcher status", "chain", chain, "address", address, "error", err)
+	}
+}
@@ -0,0 +1,244 @@
+// Package watchlist keeps the addresses the engine watches in step with the api-server's addresses
+// table, from its change events: snapshot reads and inserts watch an address, updates move it and
+// deletes unwatch it. Soft-deleted addresses, those with deleted_at set, are not watched: an update
+// setting deleted_at unwatches the address like a delete does.
+//
+// Addresses are indexed by chain and address, normalized as shared/chains stores them, so looking
+// up the rows watching an address takes constant time whatever the case it is given in.
+package watchlist
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/consumer"
+	"github.com/ahsansaif47/blockchain-address-watcher/engine/logging"
+	objects "github.com/ahsansaif47/blockchain-address-watcher/engine/models"
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/chains"
+)
+
+// logger is used by the watchlist
+var logger = logging.For("watchlist")
+
+// target is an address on a chain. Several users may watch the same target.
+type target struct {
+	chain   string
+	address string
+}
+
+// key returns the target of address on chain, normalized like the api-server stores them
+func key(chain, address string) target {
+	if c, ok := chains.Get(chain); ok {
+		chain = c.Name
+	}
+	return target{chain: chain, address: chains.NormalizeAddress(chain, address)}
+}
+
+// row is an address row, the target watched by its user
+type row struct {
+	target
+	userID string
+	label  string
+}
+
+// change is a target that started or stopped being watched
+type change struct {
+	target
+	watching bool
+}
+
+// Watcher is an address row watching a target
+type Watcher struct {
+	AddressID string
+	UserID    string
+	// Label is the address's nickname given by the user, empty when it has none
+	Label string
+}
+
+// Watchlist is the set of watched addresses, safe for concurrent use
+//
+// Example usage:
+//
+//	watched := watchlist.New()
+//	watched.Register(consumer.DefaultRouter)
+//	...
+//	if watched.Watched("ethereum", tx.To) { ... }
+type Watchlist struct {
+	mu       sync.RWMutex
+	rows     map[string]row                 // address row ID -> row
+	watchers map[target]map[string]struct{} // target -> IDs of the rows watching it
+
+	observers []func(chain, address string, watching bool)
+}
+
+// New creates an empty Watchlist
+func New() *Watchlist {
+	return &Watchlist{
+		rows:     make(map[string]row),
+		watchers: make(map[target]map[string]struct{}),
+	}
+}
+
+// Register handles the addresses table's events on router
+func (w *Watchlist) Register(router *consumer.Router) {
+	router.OnTable("addresses").OnChange(w.handle)
+}
+
+// Observe calls fn whenever an address starts being watched by its first row or stops being
+// watched by its last one, after the watchlist is updated. fn is called on the consumer's
+// goroutine, so it holds up the change events while it runs.
+func (w *Watchlist) Observe(fn func(chain, address string, watching bool)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.observers = append(w.observers, fn)
+}
+
+func (w *Watchlist) handle(event *consumer.Event) error {
+	var changes []change
+	if event.Operation == consumer.OpDelete {
+		// Without REPLICA IDENTITY FULL the old row only holds the key
+		id, _ := event.Key["id"].(string)
+		if before, ok := event.Before.(*objects.Address); ok && before.Id != "" {
+			id = before.Id
+		}
+		changes = w.unwatch(id)
+	} else {
+		row, ok := event.After.(*objects.Address)
+		if !ok {
+			return fmt.Errorf("unexpected addresses row %T", event.After)
+		}
+		if row.DeletedAt != nil {
+			changes = w.unwatch(row.Id)
+		} else {
+			changes = w.watch(row.Id, row.UserId, row.Label, key(row.Chain, row.Address))
+		}
+	}
+
+	w.notify(changes)
+	return nil
+}
+
+// notify passes changes to the observers, w.mu must not be held
+func (w *Watchlist) notify(changes []change) {
+	if len(changes) == 0 {
+		return
+	}
+	w.mu.RLock()
+	observers := w.observers
+	w.mu.RUnlock()
+
+	for _, c := range changes {
+		for _, fn := range observers {
+			fn(c.chain, c.address, c.watching)
+		}
+	}
+}
+
+func (w *Watchlist) watch(id, userID, label string, t target) []change {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changes []change
+	r := row{target: t, userID: userID, label: label}
+	if old, ok := w.rows[id]; ok {
+		// Relabeling a row keeps its target watched
+		if old.target == t {
+			w.rows[id] = r
+			return nil
+		}
+		changes = w.release(id, old.target)
+	}
+	w.rows[id] = r
+	ids := w.watchers[t]
+	if ids == nil {
+		ids = make(map[string]struct{})
+		w.watchers[t] = ids
+	}
+	ids[id] = struct{}{}
+	if len(ids) == 1 {
+		logger.Info("Watching address", "chain", t.chain, "address", t.address)
+		changes = append(changes, change{target: t, watching: true})
+	}
+	return changes
+}
+
+func (w *Watchlist) unwatch(id string) []change {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r, ok := w.rows[id]
+	if !ok {
+		return nil
+	}
+	delete(w.rows, id)
+	return w.release(id, r.target)
+}
+
+// release drops row id from the watchers of t, returning the change when it was the last one.
+// w.mu must be held.
+func (w *Watchlist) release(id string, t target) []change {
+	ids := w.watchers[t]
+	delete(ids, id)
+	if len(ids) > 0 {
+		return nil
+	}
+	delete(w.watchers, t)
+	logger.Info("Stopped watching address", "chain", t.chain, "address", t.address)
+	return []change{{target: t, watching: false}}
+}
+
+// Watched reports whether any user watches address on chain
+func (w *Watchlist) Watched(chain, address string) bool {
+	t := key(chain, address)
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.watchers[t]) > 0
+}
+
+// Watchers returns the address rows watching address on chain, sorted by address ID
+func (w *Watchlist) Watchers(chain, address string) []Watcher {
+	t := key(chain, address)
+	w.mu.RLock()
+	watchers := make([]Watcher, 0, len(w.watchers[t]))
+	for id := range w.watchers[t] {
+		r := w.rows[id]
+		watchers = append(watchers, Watcher{AddressID: id, UserID: r.userID, Label: r.label})
+	}
+	w.mu.RUnlock()
+
+	sort.Slice(watchers, func(i, j int) bool { return watchers[i].AddressID < watchers[j].AddressID })
+	return watchers
+}
+
+// Addresses returns the addresses watched on chain, sorted
+func (w *Watchlist) Addresses(chain string) []string {
+	chain = key(chain, "").chain
+	w.mu.RLock()
+	var addresses []string
+	for t := range w.watchers {
+		if t.chain == chain {
+			addresses = append(addresses, t.address)
+		}
+	}
+	w.mu.RUnlock()
+
+	sort.Strings(addresses)
+	return addresses
+}
+
+// GetStats returns the number of watched addresses per chain
+func (w *Watchlist) GetStats() map[string]interface{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	chains := make(map[string]int)
+	for t := range w.watchers {
+		chains[t.chain]++
+	}
+	return map[string]interface{}{
+		"addresses": len(w.watchers),
+		"rows":      len(w.rows),
+		"chains":    chains,
+	}
+}
@@ -0,0 +1,134 @@
+// Package chains lists the chains addresses can be watched on, e.g. ethereum or bitcoin, with the
+// format of their addresses. The api-server rejects addresses on other chains, and the engine
+// dispatches each chain's addresses to the watcher of its family.
+//
+// Chain names are lowercase, as stored in the addresses table. Adding a chain here makes it
+// accepted by the api-server; it is scanned once the engine has a watcher for it.
+package chains
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Chains
+const (
+	Ethereum  = "ethereum"
+	Polygon   = "polygon"
+	Arbitrum  = "arbitrum"
+	Optimism  = "optimism"
+	Base      = "base"
+	BSC       = "bsc"
+	Avalanche = "avalanche"
+	Bitcoin   = "bitcoin"
+	Solana    = "solana"
+)
+
+// Families of chains sharing an address format and node API
+const (
+	EVM      = "evm"
+	UTXO     = "utxo"
+	SolanaVM = "svm"
+)
+
+// Chain is a chain addresses can be watched on
+type Chain struct {
+	Name   string
+	Family string
+	// Native is the symbol of the chain's native currency
+	Native string
+}
+
+var chains = []Chain{
+	{Ethereum, EVM, "ETH"},
+	{Polygon, EVM, "POL"},
+	{Arbitrum, EVM, "ETH"},
+	{Optimism, EVM, "ETH"},
+	{Base, EVM, "ETH"},
+	{BSC, EVM, "BNB"},
+	{Avalanche, EVM, "AVAX"},
+	{Bitcoin, UTXO, "BTC"},
+	{Solana, SolanaVM, "SOL"},
+}
+
+// Address formats per family. Bitcoin addresses are legacy base58 (P2PKH, P2SH) or bech32 and
+// bech32m (SegWit, Taproot); Solana addresses are base58 public keys.
+var (
+	evmAddress     = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	base58Bitcoin  = regexp.MustCompile(`^[13][1-9A-HJ-NP-Za-km-z]{25,34}$`)
+	bech32Bitcoin  = regexp.MustCompile(`^bc1[02-9ac-hj-np-z]{11,87}$`)
+	base58Solana   = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+	familyPatterns = map[string][]*regexp.Regexp{
+		EVM:      {evmAddress},
+		UTXO:     {base58Bitcoin, bech32Bitcoin},
+		SolanaVM: {base58Solana},
+	}
+)
+
+// All returns the chains
+func All() []Chain {
+	return chains
+}
+
+// Names returns the names of the chains
+func Names() []string {
+	names := make([]string, len(chains))
+	for i, c := range chains {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Get returns the chain named name, in any case, false when there is none
+func Get(name string) (Chain, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	i := slices.IndexFunc(chains, func(c Chain) bool { return c.Name == name })
+	if i < 0 {
+		return Chain{}, false
+	}
+	return chains[i], true
+}
+
+// Valid reports whether name is a chain, in any case
+func Valid(name string) bool {
+	_, ok := Get(name)
+	return ok
+}
+
+// Family returns the family of chain name, empty when it is not a chain
+func Family(name string) string {
+	c, ok := Get(name)
+	if !ok {
+		return ""
+	}
+	return c.Family
+}
+
+// ValidAddress reports whether address has the address format of chain. Addresses are checked for
+// their form only, not their checksum.
+func ValidAddress(chain, address string) bool {
+	address = NormalizeAddress(chain, address)
+	for _, re := range familyPatterns[Family(chain)] {
+		if re.MatchString(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeAddress returns address as it is stored and matched: trimmed, and in lowercase on
+// chains whose addresses are case insensitive, EVM hex and bitcoin bech32 addresses. Base58
+// addresses are case sensitive and kept as given.
+func NormalizeAddress(chain, address string) string {
+	address = strings.TrimSpace(address)
+	switch Family(chain) {
+	case EVM:
+		return strings.ToLower(address)
+	case UTXO:
+		if lower := strings.ToLower(address); strings.HasPrefix(lower, "bc1") {
+			return lower
+		}
+	}
+	return address
+}
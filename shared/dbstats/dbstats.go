@@ -0,0 +1,135 @@
+// Package dbstats reports the health of pgx connection pools: pool counters for the metrics
+// endpoints and the latency of a ping for readiness checks. Watch the wait counters: a growing
+// EmptyAcquires or AcquireWaitSeconds means requests queue for connections and the pool is close to
+// exhaustion.
+package dbstats
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pool is a snapshot of a connection pool, see pgxpool.Stat
+type Pool struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+
+	// Acquires counts successful connection requests, EmptyAcquires those that had to wait for a
+	// connection to be created or released, AcquireWaitSeconds their total wait
+	Acquires           int64   `json:"acquires"`
+	EmptyAcquires      int64   `json:"empty_acquires"`
+	CanceledAcquires   int64   `json:"canceled_acquires"`
+	AcquireSeconds     float64 `json:"acquire_seconds"`
+	AcquireWaitSeconds float64 `json:"acquire_wait_seconds"`
+
+	NewConns            int64 `json:"new_conns"`
+	MaxLifetimeDestroys int64 `json:"max_lifetime_destroys"`
+	MaxIdleDestroys     int64 `json:"max_idle_destroys"`
+
+	// ConnectErrors counts failed connection attempts, see Tracer
+	ConnectErrors    int64  `json:"connect_errors"`
+	LastConnectError string `json:"last_connect_error,omitempty"`
+}
+
+// Snapshot returns the counters of pool, with the connection errors recorded by its Tracer when one
+// was installed
+func Snapshot(pool *pgxpool.Pool) Pool {
+	s := pool.Stat()
+	p := Pool{
+		MaxConns:            s.MaxConns(),
+		TotalConns:          s.TotalConns(),
+		AcquiredConns:       s.AcquiredConns(),
+		IdleConns:           s.IdleConns(),
+		ConstructingConns:   s.ConstructingConns(),
+		Acquires:            s.AcquireCount(),
+		EmptyAcquires:       s.EmptyAcquireCount(),
+		CanceledAcquires:    s.CanceledAcquireCount(),
+		AcquireSeconds:      s.AcquireDuration().Seconds(),
+		AcquireWaitSeconds:  s.EmptyAcquireWaitTime().Seconds(),
+		NewConns:            s.NewConnsCount(),
+		MaxLifetimeDestroys: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroys:     s.MaxIdleDestroyCount(),
+	}
+	if tracer, ok := pool.Config().ConnConfig.Tracer.(*Tracer); ok {
+		p.ConnectErrors, p.LastConnectError = tracer.Errors()
+	}
+	return p
+}
+
+// FromDB returns the counters of a database/sql pool. It does not count acquires or failed
+// connection attempts, so those stay 0.
+func FromDB(s sql.DBStats) Pool {
+	return Pool{
+		MaxConns:            int32(s.MaxOpenConnections),
+		TotalConns:          int32(s.OpenConnections),
+		AcquiredConns:       int32(s.InUse),
+		IdleConns:           int32(s.Idle),
+		EmptyAcquires:       s.WaitCount,
+		AcquireWaitSeconds:  s.WaitDuration.Seconds(),
+		MaxLifetimeDestroys: s.MaxLifetimeClosed,
+		MaxIdleDestroys:     s.MaxIdleClosed + s.MaxIdleTimeClosed,
+	}
+}
+
+// Ping acquires a connection from pool and round-trips to the server, returning how long both
+// took. The time includes waiting for a free connection, so it grows as the pool is exhausted.
+func Ping(ctx context.Context, pool *pgxpool.Pool) (time.Duration, error) {
+	start := time.Now()
+	err := pool.Ping(ctx)
+	return time.Since(start), err
+}
+
+// Tracer counts the connections a pool failed to open, which pgxpool does not report. Install it
+// with Trace before creating the pool, Snapshot finds it in the pool's config.
+type Tracer struct {
+	errors atomic.Int64
+
+	mu   sync.Mutex
+	last string
+}
+
+var _ pgx.ConnectTracer = (*Tracer)(nil)
+
+// Trace installs a new Tracer on config, returning it
+func Trace(config *pgxpool.Config) *Tracer {
+	t := &Tracer{}
+	config.ConnConfig.Tracer = t
+	return t
+}
+
+// Errors returns the number of failed connection attempts and the last error
+func (t *Tracer) Errors() (int64, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.errors.Load(), t.last
+}
+
+func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	return ctx
+}
+
+func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	if data.Err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errors.Add(1)
+	t.last = data.Err.Error()
+}
+
+// TraceQueryStart and TraceQueryEnd do nothing, pgx only calls connect hooks of a query tracer
+func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+func (t *Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {}
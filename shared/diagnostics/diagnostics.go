@@ -0,0 +1,159 @@
+// Package diagnostics serves net/http/pprof profiles and runtime/metrics samples for capturing CPU,
+// heap and goroutine profiles from a running service. Every request must present the configured
+// token, since profiles expose memory contents and command lines.
+package diagnostics
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+)
+
+// Paths served by Handler
+const (
+	PprofPath   = "/debug/pprof/"
+	RuntimePath = "/debug/runtime"
+)
+
+// started is when the process started serving diagnostics, reported as uptime
+var started = time.Now()
+
+// Handler serves the diagnostics endpoints, rejecting requests without the bearer token returned
+// by token. token is called on every request so a rotated token takes effect at once; while it
+// returns "" every request is rejected.
+//
+// Endpoints:
+//   - /debug/pprof/: profile index, e.g. /debug/pprof/heap, /debug/pprof/goroutine?debug=2
+//   - /debug/pprof/profile?seconds=30: CPU profile
+//   - /debug/pprof/trace?seconds=5: execution trace
+//   - /debug/runtime: runtime/metrics samples, goroutine count and build information as JSON
+//
+// Example usage:
+//
+//	mux.Handle("/debug/", diagnostics.Handler(func() string { return cfg.Diagnostics.Token }))
+func Handler(token func() string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PprofPath, pprof.Index)
+	mux.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(PprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(PprofPath+"trace", pprof.Trace)
+	mux.HandleFunc("GET "+RuntimePath, runtimeHandler)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token()) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="diagnostics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// authorized reports whether r carries want as bearer token
+func authorized(r *http.Request, want string) bool {
+	if want == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// Histogram summarizes a runtime/metrics histogram
+type Histogram struct {
+	Count uint64  `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// Runtime is the /debug/runtime response
+type Runtime struct {
+	GoVersion     string         `json:"go_version"`
+	GOMAXPROCS    int            `json:"gomaxprocs"`
+	NumCPU        int            `json:"num_cpu"`
+	Goroutines    int            `json:"goroutines"`
+	UptimeSeconds float64        `json:"uptime_seconds"`
+	Metrics       map[string]any `json:"metrics"`
+}
+
+func runtimeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Snapshot())
+}
+
+// Snapshot reads every supported runtime metric, e.g. /gc/heap/live:bytes or
+// /sched/latencies:seconds, summarizing histograms
+func Snapshot() Runtime {
+	descs := metrics.All()
+	samples := make([]metrics.Sample, len(descs))
+	for i, d := range descs {
+		samples[i].Name = d.Name
+	}
+	metrics.Read(samples)
+
+	values := make(map[string]any, len(samples))
+	for _, s := range samples {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			values[s.Name] = s.Value.Uint64()
+		case metrics.KindFloat64:
+			values[s.Name] = s.Value.Float64()
+		case metrics.KindFloat64Histogram:
+			values[s.Name] = summarize(s.Value.Float64Histogram())
+		}
+	}
+
+	return Runtime{
+		GoVersion:     runtime.Version(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumCPU:        runtime.NumCPU(),
+		Goroutines:    runtime.NumGoroutine(),
+		UptimeSeconds: time.Since(started).Seconds(),
+		Metrics:       values,
+	}
+}
+
+// summarize estimates quantiles of h from the upper bounds of its buckets
+func summarize(h *metrics.Float64Histogram) Histogram {
+	var sum Histogram
+	for _, c := range h.Counts {
+		sum.Count += c
+	}
+	if sum.Count == 0 {
+		return sum
+	}
+
+	quantile := func(q float64) float64 {
+		rank := uint64(math.Ceil(q * float64(sum.Count)))
+		var seen uint64
+		for i, c := range h.Counts {
+			seen += c
+			if seen >= rank {
+				return finite(h.Buckets[i+1], h.Buckets[i])
+			}
+		}
+		return 0
+	}
+	sum.P50 = quantile(0.5)
+	sum.P90 = quantile(0.9)
+	sum.P99 = quantile(0.99)
+	sum.Max = quantile(1)
+	return sum
+}
+
+// finite returns upper, or lower when upper is the unbounded last bucket edge, keeping the JSON
+// encodable
+func finite(upper, lower float64) float64 {
+	if math.IsInf(upper, 0) {
+		return lower
+	}
+	return upper
+}
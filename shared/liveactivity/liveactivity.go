@@ -0,0 +1,67 @@
+// Package liveactivity is the format of the live activity the engine publishes and the api-server
+// pushes to its /ws/activity clients. The engine sends each transfer matched by its chain watchers
+// with pg_notify on Channel, once per user watching the address. The api-server LISTENs on Channel
+// and forwards each Event to that user's connections. Both services reach the same database, so
+// Postgres is the bridge and neither needs a broker of the other's.
+//
+// The api-server also sends a SessionRevoked event on Channel when a session is signed out, so
+// every api-server replica pushes it to the session's connections and closes them.
+//
+// Notifications are not stored: clients connected when a transfer is matched receive it, others
+// read it from the address's activity later.
+package liveactivity
+
+import "time"
+
+// Channel is the Postgres notification channel of the live activity
+const Channel = "address_activity"
+
+// MaxPayload is the largest notification payload Postgres accepts, in bytes
+const MaxPayload = 8000
+
+// EventTransfer is the type of the events of a transfer. A transfer is sent again as its status
+// changes from pending to confirmed, failed or dropped.
+const EventTransfer = "transfer"
+
+// EventSessionRevoked is the type of the event pushed to the connections of a session signed out by
+// logging out or revoking it, which are closed right after
+const EventSessionRevoked = "session.revoked"
+
+// Message is the payload of a notification on Channel, an event for one user
+type Message struct {
+	UserID string `json:"user_id"`
+	Event  Event  `json:"event"`
+	// Revoked is set instead of Event when a session of the user was signed out
+	Revoked *SessionRevoked `json:"revoked,omitempty"`
+}
+
+// SessionRevoked tells the connections of a session that it was signed out
+type SessionRevoked struct {
+	Type      string    `json:"type"`
+	SessionID string    `json:"session_id"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// Event is an activity event of a watched address, as pushed to the user's clients
+type Event struct {
+	Type      string `json:"type"`
+	AddressID string `json:"address_id"`
+	// Label is the address's nickname, omitted when it has none
+	Label     string `json:"label,omitempty"`
+	Chain     string `json:"chain"`
+	Address   string `json:"address"`
+	Direction string `json:"direction"`
+	Hash      string `json:"hash"`
+	// LogIndex tells apart the transfers of a transaction, -1 for the native transfer
+	LogIndex int64 `json:"log_index"`
+	// BlockNumber is omitted while the transaction is pending in the mempool
+	BlockNumber int64  `json:"block_number,omitempty"`
+	From        string `json:"from"`
+	To          string `json:"to,omitempty"`
+	// Value is in the token's smallest unit, a decimal string
+	Value string `json:"value"`
+	// Token is the token contract, omitted for the chain's native currency
+	Token      string    `json:"token_address,omitempty"`
+	Status     string    `json:"status"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
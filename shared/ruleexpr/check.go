@@ -0,0 +1,501 @@
+package ruleexpr
+
+import (
+	"regexp"
+	"strings"
+)
+
+type kind int
+
+const (
+	kindDyn kind = iota
+	kindNull
+	kindBool
+	kindInt
+	kindDouble
+	kindString
+	kindList
+	kindMap
+	kindObject
+)
+
+// typ is the type of an expression. dyn stands for values only known when evaluated.
+type typ struct {
+	kind kind
+	// elem is the type of the elements of a list, or of the values of a map
+	elem *typ
+	// name and fields describe an object, e.g. tx
+	name   string
+	fields map[string]*typ
+}
+
+var (
+	dynType    = &typ{kind: kindDyn}
+	nullType   = &typ{kind: kindNull}
+	boolType   = &typ{kind: kindBool}
+	intType    = &typ{kind: kindInt}
+	doubleType = &typ{kind: kindDouble}
+	stringType = &typ{kind: kindString}
+)
+
+func listOf(elem *typ) *typ {
+	return &typ{kind: kindList, elem: elem}
+}
+
+func mapOf(elem *typ) *typ {
+	return &typ{kind: kindMap, elem: elem}
+}
+
+func (t *typ) String() string {
+	switch t.kind {
+	case kindNull:
+		return "null"
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "int"
+	case kindDouble:
+		return "double"
+	case kindString:
+		return "string"
+	case kindList:
+		return "list(" + t.elem.String() + ")"
+	case kindMap:
+		return "map(string, " + t.elem.String() + ")"
+	case kindObject:
+		return t.name
+	}
+	return "dyn"
+}
+
+func (t *typ) numeric() bool {
+	return t.kind == kindInt || t.kind == kindDouble
+}
+
+// is reports whether t is one of kinds or dyn
+func (t *typ) is(kinds ...kind) bool {
+	if t.kind == kindDyn {
+		return true
+	}
+	for _, k := range kinds {
+		if t.kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// comparable reports whether values of a and b may be equal: numbers compare across int and
+// double, and null with anything
+func comparable(a, b *typ) bool {
+	switch {
+	case a.kind == kindDyn || b.kind == kindDyn || a.kind == kindNull || b.kind == kindNull:
+		return true
+	case a.numeric() && b.numeric():
+		return true
+	case a.kind != b.kind:
+		return false
+	case a.kind == kindList || a.kind == kindMap:
+		return comparable(a.elem, b.elem)
+	}
+	return a.kind != kindObject || a.name == b.name
+}
+
+// join is the type of a value that is either a or b
+func join(a, b *typ) *typ {
+	if a.kind == b.kind && a.kind != kindList && a.kind != kindMap && a.kind != kindObject {
+		return a
+	}
+	return dynType
+}
+
+// scope holds the variables bound by comprehensions, innermost last
+type scope struct {
+	name   string
+	t      *typ
+	parent *scope
+}
+
+func (s *scope) lookup(name string) (*typ, bool) {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s.t, true
+		}
+	}
+	return nil, false
+}
+
+// checker checks the references, field names and operand types of an expression against the
+// declared variables, and compiles its constant regular expressions
+type checker struct {
+	vars    map[string]*typ
+	regexps map[string]*regexp.Regexp
+}
+
+func (c *checker) check(n node, s *scope) (*typ, error) {
+	switch n := n.(type) {
+	case *literal:
+		switch n.val.(type) {
+		case bool:
+			return boolType, nil
+		case int64:
+			return intType, nil
+		case float64:
+			return doubleType, nil
+		case string:
+			return stringType, nil
+		}
+		return nullType, nil
+
+	case *ident:
+		if t, ok := s.lookup(n.name); ok {
+			return t, nil
+		}
+		if t, ok := c.vars[n.name]; ok {
+			return t, nil
+		}
+		return nil, errorf(n.at, "undeclared reference to '%s'", n.name)
+
+	case *selection:
+		operand, err := c.check(n.operand, s)
+		if err != nil {
+			return nil, err
+		}
+		t, err := selectField(n, operand)
+		if err != nil || !n.test {
+			return t, err
+		}
+		return boolType, nil
+
+	case *index:
+		return c.checkIndex(n, s)
+
+	case *unary:
+		operand, err := c.check(n.operand, s)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "!" {
+			if !operand.is(kindBool) {
+				return nil, noOverload(n.at, "!_", operand)
+			}
+			return boolType, nil
+		}
+		if !operand.is(kindInt, kindDouble) {
+			return nil, noOverload(n.at, "-_", operand)
+		}
+		return operand, nil
+
+	case *binary:
+		return c.checkBinary(n, s)
+
+	case *conditional:
+		cond, err := c.check(n.cond, s)
+		if err != nil {
+			return nil, err
+		}
+		if !cond.is(kindBool) {
+			return nil, errorf(n.cond.pos(), "condition must be a bool, not %s", cond)
+		}
+		then, err := c.check(n.then, s)
+		if err != nil {
+			return nil, err
+		}
+		els, err := c.check(n.els, s)
+		if err != nil {
+			return nil, err
+		}
+		return join(then, els), nil
+
+	case *list:
+		var elem *typ
+		for _, e := range n.elems {
+			t, err := c.check(e, s)
+			if err != nil {
+				return nil, err
+			}
+			if elem == nil {
+				elem = t
+			} else {
+				elem = join(elem, t)
+			}
+		}
+		if elem == nil {
+			elem = dynType
+		}
+		return listOf(elem), nil
+
+	case *mapping:
+		var elem *typ
+		for i := range n.keys {
+			k, err := c.check(n.keys[i], s)
+			if err != nil {
+				return nil, err
+			}
+			if !k.is(kindString) {
+				return nil, errorf(n.keys[i].pos(), "map keys must be strings, not %s", k)
+			}
+			v, err := c.check(n.vals[i], s)
+			if err != nil {
+				return nil, err
+			}
+			if elem == nil {
+				elem = v
+			} else {
+				elem = join(elem, v)
+			}
+		}
+		if elem == nil {
+			elem = dynType
+		}
+		return mapOf(elem), nil
+
+	case *comprehension:
+		return c.checkComprehension(n, s)
+
+	case *call:
+		return c.checkCall(n, s)
+	}
+	return nil, errorf(n.pos(), "unsupported expression")
+}
+
+func selectField(n *selection, operand *typ) (*typ, error) {
+	switch operand.kind {
+	case kindDyn:
+		return dynType, nil
+	case kindMap:
+		return operand.elem, nil
+	case kindObject:
+		if t, ok := operand.fields[n.field]; ok {
+			return t, nil
+		}
+		return nil, errorf(n.at, "undefined field '%s' of %s", n.field, operand.name)
+	}
+	return nil, errorf(n.at, "type '%s' has no fields", operand)
+}
+
+func (c *checker) checkIndex(n *index, s *scope) (*typ, error) {
+	operand, err := c.check(n.operand, s)
+	if err != nil {
+		return nil, err
+	}
+	i, err := c.check(n.index, s)
+	if err != nil {
+		return nil, err
+	}
+	switch operand.kind {
+	case kindDyn:
+		return dynType, nil
+	case kindList:
+		if !i.is(kindInt) {
+			return nil, errorf(n.index.pos(), "list index must be an int, not %s", i)
+		}
+		return operand.elem, nil
+	case kindMap:
+		if !i.is(kindString) {
+			return nil, errorf(n.index.pos(), "map key must be a string, not %s", i)
+		}
+		return operand.elem, nil
+	}
+	return nil, errorf(n.at, "type '%s' cannot be indexed", operand)
+}
+
+func (c *checker) checkBinary(n *binary, s *scope) (*typ, error) {
+	left, err := c.check(n.left, s)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.check(n.right, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if !left.is(kindBool) || !right.is(kindBool) {
+			return nil, noOverload(n.at, "_"+n.op+"_", left, right)
+		}
+		return boolType, nil
+
+	case "==", "!=":
+		if !comparable(left, right) {
+			return nil, noOverload(n.at, "_"+n.op+"_", left, right)
+		}
+		return boolType, nil
+
+	case "<", "<=", ">", ">=":
+		ok := left.is(kindInt, kindDouble) && right.is(kindInt, kindDouble) ||
+			left.is(kindString) && right.is(kindString)
+		if !ok {
+			return nil, noOverload(n.at, "_"+n.op+"_", left, right)
+		}
+		return boolType, nil
+
+	case "in":
+		switch right.kind {
+		case kindDyn:
+		case kindList:
+			if !comparable(left, right.elem) {
+				return nil, noOverload(n.at, "@in", left, right)
+			}
+		case kindMap:
+			if !left.is(kindString) {
+				return nil, noOverload(n.at, "@in", left, right)
+			}
+		default:
+			return nil, noOverload(n.at, "@in", left, right)
+		}
+		return boolType, nil
+
+	case "+":
+		if left.kind == kindDyn || right.kind == kindDyn {
+			return dynType, nil
+		}
+		if left.kind == right.kind && (left.is(kindInt, kindDouble, kindString) || left.kind == kindList) {
+			if left.kind == kindList {
+				return listOf(join(left.elem, right.elem)), nil
+			}
+			return left, nil
+		}
+		return nil, noOverload(n.at, "_+_", left, right)
+
+	case "-", "*", "/", "%":
+		if left.kind == kindDyn || right.kind == kindDyn {
+			return dynType, nil
+		}
+		if left.kind == right.kind && (left.kind == kindInt || left.kind == kindDouble && n.op != "%") {
+			return left, nil
+		}
+		return nil, noOverload(n.at, "_"+n.op+"_", left, right)
+	}
+	return nil, errorf(n.at, "unsupported operator %s", n.op)
+}
+
+func (c *checker) checkComprehension(n *comprehension, s *scope) (*typ, error) {
+	rng, err := c.check(n.rng, s)
+	if err != nil {
+		return nil, err
+	}
+	var elem *typ
+	switch rng.kind {
+	case kindDyn:
+		elem = dynType
+	case kindList:
+		elem = rng.elem
+	case kindMap:
+		elem = stringType
+	default:
+		return nil, errorf(n.at, "%s needs a list or map, not %s", n.macro, rng)
+	}
+
+	step, err := c.check(n.step, &scope{name: n.v, t: elem, parent: s})
+	if err != nil {
+		return nil, err
+	}
+	if n.macro == "map" {
+		return listOf(step), nil
+	}
+	if !step.is(kindBool) {
+		return nil, errorf(n.step.pos(), "the predicate of %s must be a bool, not %s", n.macro, step)
+	}
+	if n.macro == "filter" {
+		return listOf(elem), nil
+	}
+	return boolType, nil
+}
+
+// functions are the functions and methods, by name, with the number of arguments of their
+// method form; the global form takes the target as its first argument
+var functions = map[string]int{
+	"size":       0,
+	"contains":   1,
+	"startsWith": 1,
+	"endsWith":   1,
+	"matches":    1,
+	"lowerAscii": 0,
+	"upperAscii": 0,
+	"int":        0,
+	"double":     0,
+	"string":     0,
+}
+
+// globalOnly are the functions only called globally, e.g. int(x) but not x.int()
+var globalOnly = map[string]bool{"int": true, "double": true, "string": true}
+
+func (c *checker) checkCall(n *call, s *scope) (*typ, error) {
+	arity, ok := functions[n.fn]
+	if !ok || n.target != nil && globalOnly[n.fn] {
+		return nil, errorf(n.at, "undeclared reference to '%s'", n.fn)
+	}
+
+	// The target of a method is its first argument
+	args := n.args
+	if n.target != nil {
+		args = append([]node{n.target}, args...)
+	}
+	if len(args) != arity+1 {
+		return nil, errorf(n.at, "%s takes %d arguments, got %d", n.fn, arity+1, len(args))
+	}
+	types := make([]*typ, len(args))
+	for i, a := range args {
+		t, err := c.check(a, s)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = t
+	}
+
+	switch n.fn {
+	case "size":
+		if !types[0].is(kindString, kindList, kindMap) {
+			return nil, noOverload(n.at, n.fn, types...)
+		}
+		return intType, nil
+
+	case "contains", "startsWith", "endsWith", "matches":
+		if !types[0].is(kindString) || !types[1].is(kindString) {
+			return nil, noOverload(n.at, n.fn, types...)
+		}
+		if lit, ok := args[1].(*literal); ok && n.fn == "matches" {
+			pattern := lit.val.(string)
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errorf(lit.at, "invalid regular expression: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+			}
+			c.regexps[pattern] = re
+		}
+		return boolType, nil
+
+	case "lowerAscii", "upperAscii":
+		if !types[0].is(kindString) {
+			return nil, noOverload(n.at, n.fn, types...)
+		}
+		return stringType, nil
+
+	case "int":
+		if !types[0].is(kindInt, kindDouble, kindString) {
+			return nil, noOverload(n.at, n.fn, types...)
+		}
+		return intType, nil
+
+	case "double":
+		if !types[0].is(kindInt, kindDouble, kindString) {
+			return nil, noOverload(n.at, n.fn, types...)
+		}
+		return doubleType, nil
+	}
+
+	// string
+	if !types[0].is(kindInt, kindDouble, kindString, kindBool) {
+		return nil, noOverload(n.at, n.fn, types...)
+	}
+	return stringType, nil
+}
+
+func noOverload(pos int, fn string, types ...*typ) error {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return errorf(pos, "no matching overload for '%s' applied to (%s)", fn, strings.Join(names, ", "))
+}
@@ -0,0 +1,634 @@
+package ruleexpr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Values are nil, bool, int64, float64, string, []any and map[string]any
+
+// activation binds the variables of an evaluation, innermost comprehension variable first
+type activation struct {
+	name   string
+	val    any
+	parent *activation
+	vars   map[string]any
+}
+
+func (a *activation) lookup(name string) (any, bool) {
+	for ; a != nil; a = a.parent {
+		if a.vars != nil {
+			v, ok := a.vars[name]
+			return v, ok
+		}
+		if a.name == name {
+			return a.val, true
+		}
+	}
+	return nil, false
+}
+
+type evaluator struct {
+	regexps map[string]*regexp.Regexp
+}
+
+func (e *evaluator) eval(n node, a *activation) (any, error) {
+	switch n := n.(type) {
+	case *literal:
+		return n.val, nil
+
+	case *ident:
+		v, ok := a.lookup(n.name)
+		if !ok {
+			return nil, errorf(n.at, "no such attribute '%s'", n.name)
+		}
+		return v, nil
+
+	case *selection:
+		operand, err := e.eval(n.operand, a)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := operand.(map[string]any)
+		if !ok {
+			return nil, errorf(n.at, "type '%s' has no fields", typeName(operand))
+		}
+		v, ok := m[n.field]
+		if n.test {
+			return ok, nil
+		}
+		if !ok {
+			return nil, errorf(n.at, "no such key: %s", n.field)
+		}
+		return v, nil
+
+	case *index:
+		return e.evalIndex(n, a)
+
+	case *unary:
+		operand, err := e.eval(n.operand, a)
+		if err != nil {
+			return nil, err
+		}
+		switch v := operand.(type) {
+		case bool:
+			if n.op == "!" {
+				return !v, nil
+			}
+		case int64:
+			if n.op == "-" {
+				if v == math.MinInt64 {
+					return nil, errorf(n.at, "int overflow")
+				}
+				return -v, nil
+			}
+		case float64:
+			if n.op == "-" {
+				return -v, nil
+			}
+		}
+		return nil, errorf(n.at, "no matching overload for '%s_' applied to (%s)", n.op, typeName(operand))
+
+	case *binary:
+		switch n.op {
+		case "&&", "||":
+			return e.evalLogical(n, a)
+		}
+		left, err := e.eval(n.left, a)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.eval(n.right, a)
+		if err != nil {
+			return nil, err
+		}
+		return binaryOp(n, left, right)
+
+	case *conditional:
+		cond, err := e.eval(n.cond, a)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := cond.(bool)
+		if !ok {
+			return nil, errorf(n.cond.pos(), "condition must be a bool, not %s", typeName(cond))
+		}
+		if b {
+			return e.eval(n.then, a)
+		}
+		return e.eval(n.els, a)
+
+	case *list:
+		elems := make([]any, len(n.elems))
+		for i, el := range n.elems {
+			v, err := e.eval(el, a)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = v
+		}
+		return elems, nil
+
+	case *mapping:
+		m := make(map[string]any, len(n.keys))
+		for i := range n.keys {
+			k, err := e.eval(n.keys[i], a)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, errorf(n.keys[i].pos(), "map keys must be strings, not %s", typeName(k))
+			}
+			if _, dup := m[key]; dup {
+				return nil, errorf(n.keys[i].pos(), "duplicate map key %q", key)
+			}
+			v, err := e.eval(n.vals[i], a)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+
+	case *comprehension:
+		return e.evalComprehension(n, a)
+
+	case *call:
+		return e.evalCall(n, a)
+	}
+	return nil, errorf(n.pos(), "unsupported expression")
+}
+
+// evalLogical evaluates && and ||. Like CEL, an error on one side is ignored when the other side
+// decides the result on its own, e.g. false && error is false.
+func (e *evaluator) evalLogical(n *binary, a *activation) (any, error) {
+	decisive := n.op == "||"
+	var firstErr error
+	for _, side := range []node{n.left, n.right} {
+		v, err := e.eval(side, a)
+		if err == nil {
+			b, ok := v.(bool)
+			if !ok {
+				err = errorf(side.pos(), "no matching overload for '_%s_' applied to (%s)", n.op, typeName(v))
+			} else if b == decisive {
+				return decisive, nil
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return !decisive, nil
+}
+
+func (e *evaluator) evalIndex(n *index, a *activation) (any, error) {
+	operand, err := e.eval(n.operand, a)
+	if err != nil {
+		return nil, err
+	}
+	i, err := e.eval(n.index, a)
+	if err != nil {
+		return nil, err
+	}
+	switch o := operand.(type) {
+	case []any:
+		idx, ok := i.(int64)
+		if !ok {
+			return nil, errorf(n.index.pos(), "list index must be an int, not %s", typeName(i))
+		}
+		if idx < 0 || idx >= int64(len(o)) {
+			return nil, errorf(n.at, "index out of range: %d", idx)
+		}
+		return o[idx], nil
+	case map[string]any:
+		key, ok := i.(string)
+		if !ok {
+			return nil, errorf(n.index.pos(), "map key must be a string, not %s", typeName(i))
+		}
+		v, ok := o[key]
+		if !ok {
+			return nil, errorf(n.at, "no such key: %s", key)
+		}
+		return v, nil
+	}
+	return nil, errorf(n.at, "type '%s' cannot be indexed", typeName(operand))
+}
+
+func binaryOp(n *binary, left, right any) (any, error) {
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+
+	case "<", "<=", ">", ">=":
+		c, ok := compare(left, right)
+		if !ok {
+			return nil, noOverloadValues(n, left, right)
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+
+	case "in":
+		switch r := right.(type) {
+		case []any:
+			for _, el := range r {
+				if equal(left, el) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]any:
+			key, ok := left.(string)
+			if !ok {
+				return nil, noOverloadValues(n, left, right)
+			}
+			_, found := r[key]
+			return found, nil
+		}
+		return nil, noOverloadValues(n, left, right)
+	}
+
+	switch l := left.(type) {
+	case int64:
+		if r, ok := right.(int64); ok {
+			return intOp(n, l, r)
+		}
+	case float64:
+		if r, ok := right.(float64); ok {
+			return doubleOp(n, l, r)
+		}
+	case string:
+		if r, ok := right.(string); ok && n.op == "+" {
+			return l + r, nil
+		}
+	case []any:
+		if r, ok := right.([]any); ok && n.op == "+" {
+			return append(append(make([]any, 0, len(l)+len(r)), l...), r...), nil
+		}
+	}
+	return nil, noOverloadValues(n, left, right)
+}
+
+func intOp(n *binary, l, r int64) (any, error) {
+	switch n.op {
+	case "+":
+		if s := l + r; (s > l) == (r > 0) {
+			return s, nil
+		}
+	case "-":
+		if d := l - r; (d < l) == (r > 0) {
+			return d, nil
+		}
+	case "*":
+		if l == 0 || r == 0 {
+			return int64(0), nil
+		}
+		if p := l * r; p/r == l && !(l == -1 && r == math.MinInt64) && !(r == -1 && l == math.MinInt64) {
+			return p, nil
+		}
+	case "/", "%":
+		if r == 0 {
+			return nil, errorf(n.at, "division by zero")
+		}
+		if l == math.MinInt64 && r == -1 {
+			break
+		}
+		if n.op == "/" {
+			return l / r, nil
+		}
+		return l % r, nil
+	}
+	return nil, errorf(n.at, "int overflow")
+}
+
+func doubleOp(n *binary, l, r float64) (any, error) {
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		return l / r, nil
+	}
+	return nil, noOverloadValues(n, l, r)
+}
+
+func noOverloadValues(n *binary, left, right any) error {
+	fn := "_" + n.op + "_"
+	if n.op == "in" {
+		fn = "@in"
+	}
+	return errorf(n.at, "no matching overload for '%s' applied to (%s, %s)", fn, typeName(left), typeName(right))
+}
+
+// equal compares values like CEL: numbers by value across int and double, other values of
+// different types are never equal
+func equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && a == b
+}
+
+// compare orders numbers and strings, false for other values
+func compare(a, b any) (int, bool) {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return cmp(x, y), true
+		}
+	}
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return cmp(x, y), true
+		}
+		return 0, false
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+func cmp[T int64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func (e *evaluator) evalComprehension(n *comprehension, a *activation) (any, error) {
+	rng, err := e.eval(n.rng, a)
+	if err != nil {
+		return nil, err
+	}
+	var elems []any
+	switch r := rng.(type) {
+	case []any:
+		elems = r
+	case map[string]any:
+		for k := range r {
+			elems = append(elems, k)
+		}
+	default:
+		return nil, errorf(n.at, "%s needs a list or map, not %s", n.macro, typeName(rng))
+	}
+
+	var out []any
+	matches := 0
+	var firstErr error
+	for _, el := range elems {
+		v, err := e.eval(n.step, &activation{name: n.v, val: el, parent: a})
+		if n.macro == "map" {
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			continue
+		}
+		b, ok := v.(bool)
+		if err == nil && !ok {
+			err = errorf(n.step.pos(), "the predicate of %s must be a bool, not %s", n.macro, typeName(v))
+		}
+		if err != nil {
+			// Like && and ||, errors of all and exists only count when no element decides the result
+			if n.macro == "filter" || n.macro == "exists_one" {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		switch {
+		case n.macro == "all" && !b:
+			return false, nil
+		case n.macro == "exists" && b:
+			return true, nil
+		case b:
+			matches++
+			out = append(out, el)
+		}
+	}
+
+	switch n.macro {
+	case "all", "exists":
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return n.macro == "all", nil
+	case "exists_one":
+		return matches == 1, nil
+	}
+	if out == nil {
+		out = []any{}
+	}
+	return out, nil
+}
+
+func (e *evaluator) evalCall(n *call, a *activation) (any, error) {
+	args := n.args
+	if n.target != nil {
+		args = append([]node{n.target}, args...)
+	}
+	vals := make([]any, len(args))
+	for i, arg := range args {
+		v, err := e.eval(arg, a)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+
+	switch n.fn {
+	case "size":
+		switch v := vals[0].(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case []any:
+			return int64(len(v)), nil
+		case map[string]any:
+			return int64(len(v)), nil
+		}
+
+	case "contains", "startsWith", "endsWith", "matches":
+		s, ok1 := vals[0].(string)
+		arg, ok2 := vals[1].(string)
+		if !ok1 || !ok2 {
+			break
+		}
+		switch n.fn {
+		case "contains":
+			return strings.Contains(s, arg), nil
+		case "startsWith":
+			return strings.HasPrefix(s, arg), nil
+		case "endsWith":
+			return strings.HasSuffix(s, arg), nil
+		}
+		re, ok := e.regexps[arg]
+		if !ok {
+			var err error
+			if re, err = regexp.Compile(arg); err != nil {
+				return nil, errorf(n.at, "invalid regular expression: %v", err)
+			}
+		}
+		return re.MatchString(s), nil
+
+	case "lowerAscii", "upperAscii":
+		s, ok := vals[0].(string)
+		if !ok {
+			break
+		}
+		if n.fn == "lowerAscii" {
+			return asciiMap(s, 'A', 'Z', 'a'-'A'), nil
+		}
+		return asciiMap(s, 'a', 'z', 'A'-'a'), nil
+
+	case "int":
+		return toInt(n, vals[0])
+
+	case "double":
+		switch v := vals[0].(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, errorf(n.at, "string %q is not a double", v)
+			}
+			return f, nil
+		}
+
+	case "string":
+		switch v := vals[0].(type) {
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		case string:
+			return v, nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	}
+
+	names := make([]string, len(vals))
+	for i, v := range vals {
+		names[i] = typeName(v)
+	}
+	return nil, errorf(n.at, "no matching overload for '%s' applied to (%s)", n.fn, strings.Join(names, ", "))
+}
+
+func toInt(n *call, v any) (any, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if math.IsNaN(v) || v <= math.MinInt64 || v >= math.MaxInt64 {
+			return nil, errorf(n.at, "double %g is out of the int range", v)
+		}
+		return int64(v), nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errorf(n.at, "string %q is not an int", v)
+		}
+		return i, nil
+	}
+	return nil, errorf(n.at, "no matching overload for 'int' applied to (%s)", typeName(v))
+}
+
+// asciiMap shifts the ASCII letters from lo to hi by delta, leaving other characters as they are
+func asciiMap(s string, lo, hi byte, delta int) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= lo && c <= hi {
+			b[i] = byte(int(c) + delta)
+		}
+	}
+	return string(b)
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// errNotBool is returned when an expression evaluates to something else than a bool
+var errNotBool = errors.New("expression did not evaluate to a bool")
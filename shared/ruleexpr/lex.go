@@ -0,0 +1,194 @@
+package ruleexpr
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokDouble
+	tokString
+	tokOp
+)
+
+// token is a lexeme of an expression, at byte offset pos
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	val  any
+}
+
+// operators, longest first so "<=" is not read as "<"
+var operators = []string{
+	"&&", "||", "==", "!=", "<=", ">=",
+	"<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", "{", "}", ".", ",", "?", ":",
+}
+
+// lex splits src into tokens, ending with a tokEOF
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case r == '/' && strings.HasPrefix(src[i:], "//"):
+			// Comments run to the end of the line
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case (r == 'r' || r == 'R') && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\''):
+			s, n, err := lexString(src, i+1, true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[i : i+1+n], pos: i, val: s})
+			i += 1 + n
+
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+
+		case r >= '0' && r <= '9' || r == '.' && i+1 < len(src) && isDigit(src[i+1]):
+			tok, err := lexNumber(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i += len(tok.text)
+
+		case r == '"' || r == '\'':
+			s, n, err := lexString(src, i, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[i : i+n], pos: i, val: s})
+			i += n
+
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, errorf(i, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// lexNumber reads the int or double literal at src[start:]: decimal or 0x hexadecimal ints, and
+// doubles with a fraction or an exponent
+func lexNumber(src string, start int) (token, error) {
+	i := start
+	if strings.HasPrefix(src[i:], "0x") || strings.HasPrefix(src[i:], "0X") {
+		i += 2
+		for i < len(src) && strings.IndexByte("0123456789abcdefABCDEF", src[i]) >= 0 {
+			i++
+		}
+		text := src[start:i]
+		n, err := strconv.ParseInt(text[2:], 16, 64)
+		if err != nil {
+			return token{}, errorf(start, "invalid int literal %s", text)
+		}
+		return token{kind: tokInt, text: text, pos: start, val: n}, nil
+	}
+
+	double := false
+	for i < len(src) && isDigit(src[i]) {
+		i++
+	}
+	if i+1 < len(src) && src[i] == '.' && isDigit(src[i+1]) {
+		double = true
+		i++
+		for i < len(src) && isDigit(src[i]) {
+			i++
+		}
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		j := i + 1
+		if j < len(src) && (src[j] == '+' || src[j] == '-') {
+			j++
+		}
+		if j < len(src) && isDigit(src[j]) {
+			double = true
+			i = j
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+		}
+	}
+	text := src[start:i]
+	if i < len(src) && (src[i] == 'u' || src[i] == 'U') {
+		return token{}, errorf(start, "uint literals are not supported")
+	}
+
+	if double {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return token{}, errorf(start, "invalid double literal %s", text)
+		}
+		return token{kind: tokDouble, text: text, pos: start, val: f}, nil
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return token{}, errorf(start, "int literal %s overflows", text)
+	}
+	return token{kind: tokInt, text: text, pos: start, val: n}, nil
+}
+
+// lexString reads the quoted string at src[start:], returning its value and length. Raw strings
+// keep their backslashes.
+func lexString(src string, start int, raw bool) (string, int, error) {
+	quote := src[start]
+	var b strings.Builder
+	i := start + 1
+	for {
+		if i >= len(src) || src[i] == '\n' {
+			return "", 0, errorf(start, "unterminated string")
+		}
+		if src[i] == quote {
+			return b.String(), i + 1 - start, nil
+		}
+		if raw || src[i] != '\\' {
+			r, size := utf8.DecodeRuneInString(src[i:])
+			b.WriteRune(r)
+			i += size
+			continue
+		}
+		value, _, tail, err := strconv.UnquoteChar(src[i:], quote)
+		if err != nil {
+			return "", 0, errorf(i, "invalid escape sequence")
+		}
+		b.WriteRune(value)
+		i = len(src) - len(tail)
+	}
+}
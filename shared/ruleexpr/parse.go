@@ -0,0 +1,438 @@
+package ruleexpr
+
+// node is a node of the syntax tree, at byte offset pos of the expression
+type node interface {
+	pos() int
+}
+
+type (
+	literal struct {
+		at  int
+		val any // nil, bool, int64, float64 or string
+	}
+	ident struct {
+		at   int
+		name string
+	}
+	// selection is operand.field, or has(operand.field) when test is set
+	selection struct {
+		at      int
+		operand node
+		field   string
+		test    bool
+	}
+	index struct {
+		at             int
+		operand, index node
+	}
+	// call is fn(args), or target.fn(args) when target is set
+	call struct {
+		at     int
+		fn     string
+		target node
+		args   []node
+	}
+	unary struct {
+		at      int
+		op      string
+		operand node
+	}
+	binary struct {
+		at          int
+		op          string
+		left, right node
+	}
+	conditional struct {
+		at              int
+		cond, then, els node
+	}
+	list struct {
+		at    int
+		elems []node
+	}
+	mapping struct {
+		at         int
+		keys, vals []node
+	}
+	// comprehension is one of the macros all, exists, exists_one, filter and map over the elements
+	// of a list, or the keys of a map, bound to v in step
+	comprehension struct {
+		at    int
+		macro string
+		rng   node
+		v     string
+		step  node
+	}
+)
+
+func (n *literal) pos() int       { return n.at }
+func (n *ident) pos() int         { return n.at }
+func (n *selection) pos() int     { return n.at }
+func (n *index) pos() int         { return n.at }
+func (n *call) pos() int          { return n.at }
+func (n *unary) pos() int         { return n.at }
+func (n *binary) pos() int        { return n.at }
+func (n *conditional) pos() int   { return n.at }
+func (n *list) pos() int          { return n.at }
+func (n *mapping) pos() int       { return n.at }
+func (n *comprehension) pos() int { return n.at }
+
+// macros are the comprehensions, called as a method of their range
+var macros = map[string]bool{"all": true, "exists": true, "exists_one": true, "filter": true, "map": true}
+
+// maxDepth bounds the nesting of expressions, so deeply nested input cannot exhaust the stack
+const maxDepth = 100
+
+type parser struct {
+	tokens []token
+	i      int
+	depth  int
+}
+
+// parse parses src following the CEL grammar:
+//
+//	Expr    = Or ["?" Or ":" Expr]
+//	Or      = And {"||" And}
+//	And     = Rel {"&&" Rel}
+//	Rel     = Add {("<" | "<=" | ">=" | ">" | "==" | "!=" | "in") Add}
+//	Add     = Mul {("+" | "-") Mul}
+//	Mul     = Unary {("*" | "/" | "%") Unary}
+//	Unary   = Member | "!" {"!"} Member | "-" {"-"} Member
+//	Member  = Primary {"." IDENT ["(" [Args] ")"] | "[" Expr "]"}
+//	Primary = IDENT ["(" [Args] ")"] | "(" Expr ")" | "[" [Args] "]" | "{" [Entries] "}" | literal
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %s", describe(t))
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the operator op when it is next
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return errorf(t.pos, "expected %q, found %s", op, describe(t))
+	}
+	return nil
+}
+
+func describe(t token) string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return "'" + t.text + "'"
+}
+
+func (p *parser) expr() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, errorf(p.peek().pos, "expression is nested more than %d levels deep", maxDepth)
+	}
+
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if !p.accept("?") {
+		return cond, nil
+	}
+	then, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	return &conditional{at: t.pos, cond: cond, then: then, els: els}, nil
+}
+
+// binaryLevel parses operands of next separated by the operators ops, left-associative
+func (p *parser) binaryLevel(next func() (node, error), ops ...string) (node, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		matched := false
+		for _, op := range ops {
+			if (t.kind == tokOp || t.kind == tokIdent && op == "in") && t.text == op {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return left, nil
+		}
+		p.next()
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{at: t.pos, op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) or() (node, error) {
+	return p.binaryLevel(p.and, "||")
+}
+
+func (p *parser) and() (node, error) {
+	return p.binaryLevel(p.relation, "&&")
+}
+
+func (p *parser) relation() (node, error) {
+	return p.binaryLevel(p.addition, "<", "<=", ">=", ">", "==", "!=", "in")
+}
+
+func (p *parser) addition() (node, error) {
+	return p.binaryLevel(p.multiplication, "+", "-")
+}
+
+func (p *parser) multiplication() (node, error) {
+	return p.binaryLevel(p.unary, "*", "/", "%")
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.peek()
+	if t.kind != tokOp || t.text != "!" && t.text != "-" {
+		return p.member()
+	}
+	p.next()
+	// A minus sign right before a number makes a negative literal
+	if next := p.peek(); t.text == "-" && next.pos == t.pos+1 && (next.kind == tokInt || next.kind == tokDouble) {
+		if lit, ok, err := p.negativeLiteral(t); ok || err != nil {
+			return lit, err
+		}
+	}
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &unary{at: t.pos, op: t.text, operand: operand}, nil
+}
+
+// negativeLiteral parses the number after minus as a negative literal, unless it is the operand
+// of a member expression, e.g. -1.size()
+func (p *parser) negativeLiteral(minus token) (node, bool, error) {
+	num := p.tokens[p.i]
+	if after := p.tokens[p.i+1]; after.kind == tokOp && (after.text == "." || after.text == "[") {
+		return nil, false, nil
+	}
+	p.next()
+	switch v := num.val.(type) {
+	case int64:
+		return &literal{at: minus.pos, val: -v}, true, nil
+	case float64:
+		return &literal{at: minus.pos, val: -v}, true, nil
+	}
+	return nil, false, errorf(num.pos, "invalid number")
+}
+
+func (p *parser) member() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case p.accept("."):
+			name := p.next()
+			if name.kind != tokIdent {
+				return nil, errorf(name.pos, "expected a field or method name, found %s", describe(name))
+			}
+			if !p.accept("(") {
+				n = &selection{at: t.pos, operand: n, field: name.text}
+				continue
+			}
+			args, err := p.args(")")
+			if err != nil {
+				return nil, err
+			}
+			if n, err = p.method(t.pos, n, name.text, args); err != nil {
+				return nil, err
+			}
+
+		case p.accept("["):
+			i, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &index{at: t.pos, operand: n, index: i}
+
+		default:
+			return n, nil
+		}
+	}
+}
+
+// method builds target.fn(args), expanding the comprehension macros
+func (p *parser) method(at int, target node, fn string, args []node) (node, error) {
+	if !macros[fn] {
+		return &call{at: at, fn: fn, target: target, args: args}, nil
+	}
+	if len(args) != 2 {
+		return nil, errorf(at, "%s takes a variable and an expression, e.g. %s(x, x > 0)", fn, fn)
+	}
+	v, ok := args[0].(*ident)
+	if !ok {
+		return nil, errorf(args[0].pos(), "the first argument of %s must be a variable name", fn)
+	}
+	return &comprehension{at: at, macro: fn, rng: target, v: v.name, step: args[1]}, nil
+}
+
+// args parses a comma separated list of expressions up to the closing operator end
+func (p *parser) args(end string) ([]node, error) {
+	var args []node
+	if p.accept(end) {
+		return args, nil
+	}
+	for {
+		a, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if p.accept(end) {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		// A trailing comma is allowed
+		if p.accept(end) {
+			return args, nil
+		}
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokInt, tokDouble, tokString:
+		return &literal{at: t.pos, val: t.val}, nil
+
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &literal{at: t.pos, val: t.text == "true"}, nil
+		case "null":
+			return &literal{at: t.pos}, nil
+		case "in":
+			return nil, errorf(t.pos, "unexpected 'in'")
+		}
+		if !p.accept("(") {
+			return &ident{at: t.pos, name: t.text}, nil
+		}
+		args, err := p.args(")")
+		if err != nil {
+			return nil, err
+		}
+		if t.text != "has" {
+			return &call{at: t.pos, fn: t.text, args: args}, nil
+		}
+		// has(x.f) tests whether x has the field f
+		sel, ok := singleSelection(args)
+		if !ok {
+			return nil, errorf(t.pos, "has takes a field selection, e.g. has(tx.value_usd)")
+		}
+		return &selection{at: t.pos, operand: sel.operand, field: sel.field, test: true}, nil
+
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			elems, err := p.args("]")
+			if err != nil {
+				return nil, err
+			}
+			return &list{at: t.pos, elems: elems}, nil
+		case "{":
+			return p.mapping(t)
+		}
+	}
+	return nil, errorf(t.pos, "unexpected %s", describe(t))
+}
+
+func singleSelection(args []node) (*selection, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	sel, ok := args[0].(*selection)
+	return sel, ok && !sel.test
+}
+
+// mapping parses the entries of a map literal after its opening brace
+func (p *parser) mapping(open token) (node, error) {
+	m := &mapping{at: open.pos}
+	if p.accept("}") {
+		return m, nil
+	}
+	for {
+		k, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		m.keys, m.vals = append(m.keys, k), append(m.vals, v)
+		if p.accept("}") {
+			return m, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if p.accept("}") {
+			return m, nil
+		}
+	}
+}
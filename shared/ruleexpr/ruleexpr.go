@@ -0,0 +1,209 @@
+// Package ruleexpr compiles and evaluates the conditions of advanced alert rules, written in the
+// Common Expression Language (CEL) over the normalized transaction, e.g.
+//
+//	tx.value_usd > 10000 && tx.direction == "out" && !(tx.counterparty in known.exchanges)
+//
+// It implements the part of CEL rules need, with no dependencies: null, bool, int, double and
+// string literals, lists and maps, the logical, relational, arithmetic and in operators, the
+// ternary operator, field selection and indexing, has(), the all, exists, exists_one, filter and
+// map macros, size, contains, startsWith, endsWith, matches, lowerAscii, upperAscii, and the int,
+// double and string conversions. Like CEL, ints and doubles compare with each other but are not
+// mixed in arithmetic, and an error on one side of && or || is ignored when the other side
+// decides the result.
+//
+// Expressions are checked when compiled: references to undeclared variables, unknown fields of tx
+// and known, and operands of the wrong type are rejected with the position of the mistake. A
+// Program is safe for concurrent use.
+package ruleexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxLength bounds the length of an expression in bytes
+const MaxLength = 4096
+
+// Error is a mistake in an expression, at byte offset Pos
+type Error struct {
+	Pos     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos+1)
+}
+
+func errorf(pos int, format string, args ...any) error {
+	return &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// Transaction is the normalized transaction, tx in expressions. Addresses are compared in
+// lowercase.
+type Transaction struct {
+	Chain       string
+	Hash        string
+	BlockNumber int64
+	// LogIndex tells apart the transfers of a transaction, -1 for the native transfer
+	LogIndex int64
+	From     string
+	To       string
+	// Value is the amount in the token's smallest unit, e.g. wei
+	Value float64
+	// ValueUSD is the value in US dollars, nil when the token has no price: has(tx.value_usd) is
+	// then false, and reading it fails the evaluation
+	ValueUSD *float64
+	// Token is the token contract, empty for the chain's native currency
+	Token string
+	// Kind is transfer, or approval for an ERC-20 allowance granted to Counterparty
+	Kind string
+	// Direction is in or out, as seen from the watched address; approvals the address granted are
+	// out
+	Direction string
+	// Counterparty is the other side of the transfer
+	Counterparty string
+	// NewCounterparty is whether the watched address never transacted with Counterparty before
+	NewCounterparty bool
+	// CounterpartyRisk is the risk score of Counterparty, 0 to 100, nil when it was not scored:
+	// has(tx.counterparty_risk) is then false, and so is has(tx.counterparty_risk_level)
+	CounterpartyRisk *int64
+	// CounterpartyRiskLevel is low, medium or high, the level of CounterpartyRisk
+	CounterpartyRiskLevel string
+	// Status is pending, confirmed, failed or dropped
+	Status string
+}
+
+// Known lists the addresses of known entities by category, known in expressions
+type Known struct {
+	Exchanges  []string
+	Mixers     []string
+	Sanctioned []string
+	Bridges    []string
+}
+
+// variables are the declared variables of expressions
+var variables = map[string]*typ{
+	"tx": {kind: kindObject, name: "tx", fields: map[string]*typ{
+		"chain":            stringType,
+		"hash":             stringType,
+		"block_number":     intType,
+		"log_index":        intType,
+		"from":             stringType,
+		"to":               stringType,
+		"value":            doubleType,
+		"value_usd":        doubleType,
+		"token":            stringType,
+		"kind":             stringType,
+		"direction":        stringType,
+		"counterparty":     stringType,
+		"new_counterparty": boolType,
+		"status":           stringType,
+
+		"counterparty_risk":       intType,
+		"counterparty_risk_level": stringType,
+	}},
+	"known": {kind: kindObject, name: "known", fields: map[string]*typ{
+		"exchanges":  listOf(stringType),
+		"mixers":     listOf(stringType),
+		"sanctioned": listOf(stringType),
+		"bridges":    listOf(stringType),
+	}},
+}
+
+// Program is a compiled expression
+type Program struct {
+	source  string
+	root    node
+	regexps map[string]*regexp.Regexp
+}
+
+// Compile parses and checks expr, which must evaluate to a bool. Mistakes are returned as *Error.
+func Compile(expr string) (*Program, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errorf(0, "expression is empty")
+	}
+	if len(expr) > MaxLength {
+		return nil, errorf(MaxLength, "expression is longer than %d bytes", MaxLength)
+	}
+	root, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	c := &checker{vars: variables, regexps: make(map[string]*regexp.Regexp)}
+	t, err := c.check(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !t.is(kindBool) {
+		return nil, errorf(0, "expression must evaluate to a bool, not %s", t)
+	}
+	return &Program{source: expr, root: root, regexps: c.regexps}, nil
+}
+
+// Source returns the expression p was compiled from
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates p for tx, reporting whether the rule's condition holds. It fails when the
+// expression reads a missing value, e.g. tx.value_usd without a price, or on overflows and
+// division by zero.
+func (p *Program) Eval(tx Transaction, known Known) (bool, error) {
+	e := &evaluator{regexps: p.regexps}
+	v, err := e.eval(p.root, &activation{vars: map[string]any{
+		"tx":    tx.value(),
+		"known": known.value(),
+	}})
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errNotBool
+	}
+	return b, nil
+}
+
+func (tx Transaction) value() map[string]any {
+	v := map[string]any{
+		"chain":            tx.Chain,
+		"hash":             tx.Hash,
+		"block_number":     tx.BlockNumber,
+		"log_index":        tx.LogIndex,
+		"from":             strings.ToLower(tx.From),
+		"to":               strings.ToLower(tx.To),
+		"value":            tx.Value,
+		"token":            strings.ToLower(tx.Token),
+		"kind":             tx.Kind,
+		"direction":        tx.Direction,
+		"counterparty":     strings.ToLower(tx.Counterparty),
+		"new_counterparty": tx.NewCounterparty,
+		"status":           tx.Status,
+	}
+	if tx.ValueUSD != nil {
+		v["value_usd"] = *tx.ValueUSD
+	}
+	if tx.CounterpartyRisk != nil {
+		v["counterparty_risk"] = *tx.CounterpartyRisk
+		v["counterparty_risk_level"] = tx.CounterpartyRiskLevel
+	}
+	return v
+}
+
+func (k Known) value() map[string]any {
+	return map[string]any{
+		"exchanges":  addresses(k.Exchanges),
+		"mixers":     addresses(k.Mixers),
+		"sanctioned": addresses(k.Sanctioned),
+		"bridges":    addresses(k.Bridges),
+	}
+}
+
+func addresses(list []string) []any {
+	out := make([]any, len(list))
+	for i, a := range list {
+		out[i] = strings.ToLower(a)
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// awsProvider reads secrets from AWS Secrets Manager
+type awsProvider struct {
+	client *secretsmanager.Client
+}
+
+// newAWSProvider creates a Secrets Manager client from the default AWS credential chain
+func newAWSProvider(ctx context.Context) (Provider, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &awsProvider{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+func (p *awsProvider) Fetch(ctx context.Context, path string) (map[string]string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &path})
+	if err != nil {
+		return nil, err
+	}
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("binary secrets are not supported")
+	}
+
+	// Key/value secrets are stored as a JSON object, anything else is a plain secret
+	var object map[string]any
+	if err := json.Unmarshal([]byte(*out.SecretString), &object); err != nil {
+		return map[string]string{"": *out.SecretString}, nil
+	}
+
+	fields := make(map[string]string, len(object))
+	for k, v := range object {
+		if s, ok := v.(string); ok {
+			fields[k] = s
+		} else {
+			fields[k] = fmt.Sprint(v)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,116 @@
+// Package secrets resolves references to values stored in a secrets backend, so credentials can
+// be kept out of .env files. A reference has the form <scheme>://<path>[#<key>]:
+//
+//	vault://secret/data/watcher#jwt_secret  HashiCorp Vault, path as used by the HTTP API (KV v2 includes data/)
+//	awssm://prod/watcher#db_url             AWS Secrets Manager, secret name or ARN; without #key the whole secret string
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Provider fetches the key/value pairs of a secret from one backend
+type Provider interface {
+	// Fetch returns the fields of the secret at path. Plain (non JSON) secrets are returned under the empty key.
+	Fetch(ctx context.Context, path string) (map[string]string, error)
+}
+
+// Reference schemes understood by the default resolver
+const (
+	SchemeVault  = "vault"
+	SchemeAWSSM  = "awssm"
+	schemeSuffix = "://"
+)
+
+// Resolver resolves references using the provider registered for their scheme. Providers are
+// created on first use, so backends that are not referenced need no configuration.
+type Resolver struct {
+	mu        sync.Mutex
+	factories map[string]func(ctx context.Context) (Provider, error)
+	providers map[string]Provider
+}
+
+// NewResolver creates a Resolver for vault:// (configured through VAULT_ADDR, VAULT_TOKEN, etc.)
+// and awssm:// (configured through the default AWS credential chain and AWS_REGION) references
+func NewResolver() *Resolver {
+	return &Resolver{
+		factories: map[string]func(ctx context.Context) (Provider, error){
+			SchemeVault: newVaultProvider,
+			SchemeAWSSM: newAWSProvider,
+		},
+		providers: make(map[string]Provider),
+	}
+}
+
+// Register adds or replaces the provider for scheme
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = provider
+}
+
+// IsReference reports whether value is a secret reference rather than a literal value
+func IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, schemeSuffix)
+	return ok && (scheme == SchemeVault || scheme == SchemeAWSSM)
+}
+
+// Resolve returns the value value refers to, or value itself when it is not a reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	scheme, rest, ok := strings.Cut(value, schemeSuffix)
+	if !ok {
+		return value, nil
+	}
+
+	provider, err := r.provider(ctx, scheme)
+	if err != nil {
+		return "", err
+	}
+	if provider == nil {
+		return value, nil
+	}
+
+	path, key, _ := strings.Cut(rest, "#")
+	fields, err := provider.Fetch(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret %s://%s: %w", scheme, path, err)
+	}
+
+	secret, ok := fields[key]
+	if !ok && key == "" && len(fields) == 1 {
+		for _, only := range fields {
+			return only, nil
+		}
+	}
+	if !ok {
+		if key == "" {
+			return "", fmt.Errorf("secret %s://%s has several fields, select one with #key", scheme, path)
+		}
+		return "", fmt.Errorf("secret %s://%s has no field %q", scheme, path, key)
+	}
+	return secret, nil
+}
+
+// provider returns the provider for scheme, creating it on first use; nil for unknown schemes
+func (r *Resolver) provider(ctx context.Context, scheme string) (Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.providers[scheme]; ok {
+		return p, nil
+	}
+	factory, ok := r.factories[scheme]
+	if !ok {
+		return nil, nil
+	}
+
+	p, err := factory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure %s secrets backend: %w", scheme, err)
+	}
+	r.providers[scheme] = p
+	return p, nil
+}
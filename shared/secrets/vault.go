@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// vaultProvider reads secrets from HashiCorp Vault
+type vaultProvider struct {
+	client *vault.Client
+}
+
+// newVaultProvider creates a Vault client from the standard VAULT_* environment variables
+func newVaultProvider(ctx context.Context) (Provider, error) {
+	client, err := vault.NewClient(vault.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	if client.Token() == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN is not set")
+	}
+	return &vaultProvider{client: client}, nil
+}
+
+func (p *vaultProvider) Fetch(ctx context.Context, path string) (map[string]string, error) {
+	secret, err := p.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("secret not found")
+	}
+
+	data := secret.Data
+	// KV v2 nests the fields under "data" next to the version metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+
+	fields := make(map[string]string, len(data))
+	for k, v := range data {
+		fields[k] = fmt.Sprint(v)
+	}
+	return fields, nil
+}
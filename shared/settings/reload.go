@@ -0,0 +1,179 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// reloadable lists the settings, by name or section prefix, that may change without a restart.
+// Changes to any other setting are logged and ignored until the process restarts.
+var reloadable = []string{
+	"log.",
+	"alerts.",
+	"chain.rpc_url",
+	"chain.ws_url",
+	"chain.rpc_api_key",
+	"notifications.",
+	// Rotated credentials: the JWT secret, diagnostics, admin and operator tokens are read on every
+	// request and new database connections pick up the current password
+	"jwt.secret",
+	"database.url",
+	"diagnostics.token",
+	"admin.token",
+	"admin.operator_tokens",
+	// Request and query deadlines are applied per request, per query and to new database connections
+	"server.request_timeout",
+	"server.auth_timeout",
+	"server.reporting_timeout",
+	"database.query_timeout",
+	"database.reporting_query_timeout",
+	// Read when verifying every signed request
+	"server.signature_max_skew",
+	// Read by every partition maintenance run
+	"database.partitions_ahead",
+	"database.transaction_retention_months",
+}
+
+// Reloader holds the current settings and replaces them when they are reloaded, either on
+// SIGHUP or periodically to pick up rotated secrets
+type Reloader struct {
+	opts     Options
+	current  atomic.Pointer[Settings]
+	mu       sync.Mutex
+	onChange []func(*Settings)
+}
+
+// NewReloader creates a Reloader starting from s, which was loaded with opts
+func NewReloader(opts Options, s *Settings) *Reloader {
+	r := &Reloader{opts: opts}
+	r.current.Store(s)
+	return r
+}
+
+// Settings returns the current settings, they must not be modified
+func (r *Reloader) Settings() *Settings {
+	return r.current.Load()
+}
+
+// OnChange registers fn to be called with the new settings after every successful reload that
+// changed something
+func (r *Reloader) OnChange(fn func(*Settings)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = append(r.onChange, fn)
+}
+
+// Reload loads the settings again and, when they are valid, swaps in the reloadable ones.
+// reason is recorded in the audit log. On error the current settings stay in effect.
+func (r *Reloader) Reload(reason string) error {
+	next, err := Load(r.opts)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		log.Printf("[Settings] audit: reload (%s) rejected, keeping current settings: %v", reason, err)
+		return fmt.Errorf("reload rejected: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.current.Load()
+	var applied, ignored []string
+	mergeReloadable(reflect.ValueOf(next).Elem(), reflect.ValueOf(current).Elem(), "", &applied, &ignored)
+
+	if len(ignored) > 0 {
+		log.Printf("[Settings] Changes to %s require a restart and were not applied", strings.Join(ignored, ", "))
+	}
+	if len(applied) == 0 {
+		log.Printf("[Settings] audit: reload (%s) found no changes", reason)
+		return nil
+	}
+
+	// Only setting names are logged, values may be secrets
+	log.Printf("[Settings] audit: reload (%s) applied changes to %s", reason, strings.Join(applied, ", "))
+	r.current.Store(next)
+	for _, fn := range r.onChange {
+		fn(next)
+	}
+	return nil
+}
+
+// WatchSignals reloads the settings on every SIGHUP until ctx is done
+func (r *Reloader) WatchSignals(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.Reload("SIGHUP")
+		}
+	}
+}
+
+// Refresh reloads the settings every interval until ctx is done, e.g. to pick up rotated
+// secrets. It returns immediately when interval is 0.
+func (r *Reloader) Refresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reload("refresh")
+		}
+	}
+}
+
+// mergeReloadable compares next with current field by field. Changed reloadable fields are
+// recorded in applied; changed fields that need a restart are reset to their current value in
+// next and recorded in ignored.
+func mergeReloadable(next, current reflect.Value, prefix string, applied, ignored *[]string) {
+	for i := 0; i < next.NumField(); i++ {
+		name := prefix + next.Type().Field(i).Tag.Get("mapstructure")
+		nf, cf := next.Field(i), current.Field(i)
+
+		if nf.Kind() == reflect.Struct {
+			mergeReloadable(nf, cf, name+".", applied, ignored)
+			continue
+		}
+		if reflect.DeepEqual(nf.Interface(), cf.Interface()) {
+			continue
+		}
+		if isReloadable(name) {
+			*applied = append(*applied, name)
+			continue
+		}
+		*ignored = append(*ignored, name)
+		nf.Set(cf)
+	}
+}
+
+// isReloadable reports whether the setting name may change without a restart
+func isReloadable(name string) bool {
+	for _, r := range reloadable {
+		if name == r || (strings.HasSuffix(r, ".") && strings.HasPrefix(name, r)) {
+			return true
+		}
+	}
+	return false
+}
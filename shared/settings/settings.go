@@ -0,0 +1,1054 @@
+// Package settings loads the configuration shared by the api-server and the engine from, in order
+// of precedence: CLI flags, environment variables (including ../.env), a YAML or TOML file, and
+// built-in defaults. Any string setting may instead reference a secret, e.g.
+// DB_URL=awssm://prod/watcher#db_url, which is fetched when the settings are loaded (see package secrets).
+package settings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahsansaif47/blockchain-address-watcher/shared/secrets"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Settings is the complete configuration of both services. File keys are the lower case
+// mapstructure names, e.g. kafka.retry_delay.
+type Settings struct {
+	Kafka              Kafka              `mapstructure:"kafka"`
+	RabbitMQ           RabbitMQ           `mapstructure:"rabbitmq"`
+	SQS                SQS                `mapstructure:"sqs"`
+	PubSub             PubSub             `mapstructure:"pubsub"`
+	Outbox             Outbox             `mapstructure:"outbox"`
+	Quarantine         Quarantine         `mapstructure:"quarantine"`
+	WatcherStatus      WatcherStatus      `mapstructure:"watcher_status"`
+	LiveActivity       LiveActivity       `mapstructure:"live_activity"`
+	TransactionHistory TransactionHistory `mapstructure:"transaction_history"`
+	Database           Database           `mapstructure:"database"`
+	JWT                JWT                `mapstructure:"jwt"`
+	Server             Server             `mapstructure:"server"`
+	Engine             Engine             `mapstructure:"engine"`
+	Chain              Chain              `mapstructure:"chain"`
+	Notifications      Notifications      `mapstructure:"notifications"`
+	Secrets            Secrets            `mapstructure:"secrets"`
+	Log                Log                `mapstructure:"log"`
+	Alerts             Alerts             `mapstructure:"alerts"`
+	Diagnostics        Diagnostics        `mapstructure:"diagnostics"`
+	Archive            Archive            `mapstructure:"archive"`
+	Jobs               Jobs               `mapstructure:"jobs"`
+	Analytics          Analytics          `mapstructure:"analytics"`
+	Admin              Admin              `mapstructure:"admin"`
+}
+
+// Kafka holds the engine's consumer settings
+type Kafka struct {
+	Broker               string        `mapstructure:"broker"`
+	Topic                string        `mapstructure:"topic"`
+	Topics               []string      `mapstructure:"topics"`
+	TopicPrefix          string        `mapstructure:"topic_prefix"`
+	TopicPattern         string        `mapstructure:"topic_pattern"`
+	TopicRefreshInterval time.Duration `mapstructure:"topic_refresh_interval"`
+	MaxRetries           int           `mapstructure:"max_retries"`
+	RetryDelay           time.Duration `mapstructure:"retry_delay"`
+	MaxRetryDelay        time.Duration `mapstructure:"max_retry_delay"`
+	HealthCheckInterval  time.Duration `mapstructure:"health_check_interval"`
+	Serialization        string        `mapstructure:"serialization"`
+	KeySerialization     string        `mapstructure:"key_serialization"`
+	SchemaRegistryURL    string        `mapstructure:"schema_registry_url"`
+	Workers              int           `mapstructure:"workers"`
+	MaxInFlight          int           `mapstructure:"max_in_flight"`
+	HandlerAttempts      int           `mapstructure:"handler_attempts"`
+	MaxWorkers           int           `mapstructure:"max_workers"`
+	LagPerWorker         int64         `mapstructure:"lag_per_worker"`
+	ScaleInterval        time.Duration `mapstructure:"scale_interval"`
+	HeartbeatTopic       string        `mapstructure:"heartbeat_topic"`
+	HeartbeatTimeout     time.Duration `mapstructure:"heartbeat_timeout"`
+	GroupID              string        `mapstructure:"group_id"`
+	GroupBalancer        string        `mapstructure:"group_balancer"`
+	RebalanceTimeout     time.Duration `mapstructure:"rebalance_timeout"`
+}
+
+// RabbitMQ holds the engine's settings for the rabbitmq transport
+type RabbitMQ struct {
+	URL           string   `mapstructure:"url"`
+	Exchange      string   `mapstructure:"exchange"`
+	Queue         string   `mapstructure:"queue"`
+	BindingKeys   []string `mapstructure:"binding_keys"`
+	Prefetch      int      `mapstructure:"prefetch"`
+	DeliveryLimit int      `mapstructure:"delivery_limit"`
+}
+
+// SQS holds the engine's settings for the sqs transport, which publishes through SNS
+type SQS struct {
+	QueueURL          string        `mapstructure:"queue_url"`
+	TopicARN          string        `mapstructure:"topic_arn"`
+	WaitTime          time.Duration `mapstructure:"wait_time"`
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"`
+	MaxMessages       int           `mapstructure:"max_messages"`
+}
+
+// PubSub holds the engine's settings for the Google Cloud Pub/Sub transport
+type PubSub struct {
+	ProjectID      string `mapstructure:"project_id"`
+	Subscription   string `mapstructure:"subscription"`
+	Topic          string `mapstructure:"topic"`
+	MaxOutstanding int    `mapstructure:"max_outstanding"`
+}
+
+// Outbox holds the engine's settings for publishing emitted messages (alerts) through the
+// Postgres outbox
+type Outbox struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Retention    time.Duration `mapstructure:"retention"`
+}
+
+// Quarantine holds the engine's settings for sampling the change events it fails to decode or
+// handle into the engine_quarantine table: at most PerMinute per topic and failure stage, kept for
+// Retention
+type Quarantine struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	PerMinute int           `mapstructure:"per_minute"`
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// WatcherStatus holds the engine's setting for recording the state of its watch of each address
+// in the watcher_status table, read by the api-server's address status endpoint
+type WatcherStatus struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// LiveActivity holds the setting for pushing the transfers of watched addresses to the api-server's
+// /ws/activity clients as they are matched. The engine publishes them with pg_notify and the
+// api-server listens, so both need it. AllowedOrigins are the origins browsers may connect from,
+// e.g. https://app.example.com, or "*" for any.
+type LiveActivity struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+}
+
+// TransactionHistory holds the engine's setting for storing the mined transfers of watched
+// addresses in the transactions table, read by the api-server's address transactions endpoint
+type TransactionHistory struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// Database holds the database connection and pool settings
+type Database struct {
+	// Driver selects the api-server's backend: postgres, or sqlite for local development, with URL
+	// a SQLite file or ":memory:"
+	Driver            string        `mapstructure:"driver"`
+	URL               string        `mapstructure:"url"`
+	MaxConns          int32         `mapstructure:"max_conns"`
+	MinConns          int32         `mapstructure:"min_conns"`
+	MaxConnLifetime   time.Duration `mapstructure:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `mapstructure:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
+	// QueryTimeout bounds interactive (OLTP) queries, ReportingQueryTimeout long-running reads
+	QueryTimeout          time.Duration `mapstructure:"query_timeout"`
+	ReportingQueryTimeout time.Duration `mapstructure:"reporting_query_timeout"`
+	// ReplicaURLs are read-only standbys of URL, used while their replication lag is at most MaxReplicaLag
+	ReplicaURLs          []string      `mapstructure:"replica_urls"`
+	MaxReplicaLag        time.Duration `mapstructure:"max_replica_lag"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
+	// PartitionsAhead is the number of monthly transactions partitions kept ready, counting the
+	// current month. Partitions older than TransactionRetentionMonths are detached, 0 keeps them.
+	PartitionsAhead            int           `mapstructure:"partitions_ahead"`
+	TransactionRetentionMonths int           `mapstructure:"transaction_retention_months"`
+	PartitionCheckInterval     time.Duration `mapstructure:"partition_check_interval"`
+}
+
+// JWT holds the API token settings. Expiry is the lifetime of the access tokens, RefreshExpiry
+// that of the refresh tokens exchanged for new ones, and so of an unused session.
+type JWT struct {
+	Secret        string        `mapstructure:"secret"`
+	Expiry        time.Duration `mapstructure:"expiry"`
+	RefreshExpiry time.Duration `mapstructure:"refresh_expiry"`
+}
+
+// Server holds the api-server HTTP settings
+type Server struct {
+	Port string `mapstructure:"port"`
+	// RequestTimeout, AuthTimeout and ReportingTimeout bound how long the api-server handles a
+	// request of an ordinary, an authentication (register, login) and a reporting route (admin
+	// stats, archived alerts) before answering 504
+	RequestTimeout   time.Duration `mapstructure:"request_timeout"`
+	AuthTimeout      time.Duration `mapstructure:"auth_timeout"`
+	ReportingTimeout time.Duration `mapstructure:"reporting_timeout"`
+	// SignatureMaxSkew is how far the timestamp of a signed request may be from the server's
+	// clock, and how long its nonce is remembered against replays
+	SignatureMaxSkew time.Duration `mapstructure:"signature_max_skew"`
+	// ShutdownTimeout bounds how long in-flight requests may take after SIGINT or SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// APIKeyEncryptionKey is the base64 of the 32-byte AES key the signing secrets of API keys are
+	// encrypted with. Signed keys cannot be created without it, and changing it invalidates them.
+	APIKeyEncryptionKey string `mapstructure:"api_key_encryption_key"`
+	// ProxyHeader is the header a reverse proxy sets to the client's address, e.g. X-Real-IP. It is
+	// only believed for requests from TrustedProxies, the proxies' addresses or networks; the
+	// address of other requests is the connection's. Empty ignores the header, without a proxy.
+	ProxyHeader    string   `mapstructure:"proxy_header"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// Engine holds engine process settings
+type Engine struct {
+	// Transport is the broker change events are consumed from: kafka, rabbitmq, sqs or pubsub
+	Transport       string        `mapstructure:"transport"`
+	AdminAddr       string        `mapstructure:"admin_addr"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// Chain holds the blockchain node settings used to watch addresses
+type Chain struct {
+	Network       string        `mapstructure:"network"`
+	RPCURL        string        `mapstructure:"rpc_url"`
+	WSURL         string        `mapstructure:"ws_url"`
+	RPCAPIKey     string        `mapstructure:"rpc_api_key"`
+	Confirmations int           `mapstructure:"confirmations"`
+	PollInterval  time.Duration `mapstructure:"poll_interval"`
+	// PendingTransactions also matches the transactions of the node's mempool, not only mined ones
+	PendingTransactions bool `mapstructure:"pending_transactions"`
+	// FeeCacheTTL is how long the fee estimates read from the node are served
+	FeeCacheTTL time.Duration `mapstructure:"fee_cache_ttl"`
+	Cache       ChainCache    `mapstructure:"cache"`
+}
+
+// ChainCache holds the settings of the cache of idempotent node calls: an in-memory cache of up to
+// MaxEntries results, 0 disabling it, in front of Redis when RedisURL is set
+type ChainCache struct {
+	MaxEntries int    `mapstructure:"max_entries"`
+	RedisURL   string `mapstructure:"redis_url"`
+	// BlockTTL applies to blocks by number or hash and to mined transactions and receipts
+	BlockTTL time.Duration `mapstructure:"block_ttl"`
+	// MetadataTTL applies to the chain ID and to token names, symbols and decimals
+	MetadataTTL time.Duration `mapstructure:"metadata_ttl"`
+	// BalanceTTL applies to balances at the latest block
+	BalanceTTL time.Duration `mapstructure:"balance_ttl"`
+}
+
+// Notifications holds the credentials of the alert delivery channels
+type Notifications struct {
+	SMTP     SMTP     `mapstructure:"smtp"`
+	Twilio   Twilio   `mapstructure:"twilio"`
+	Webhooks Webhooks `mapstructure:"webhooks"`
+	Routing  Routing  `mapstructure:"routing"`
+	Email    Email    `mapstructure:"email"`
+	// Transfers are the engine's notifications of the transfers its chain watchers match
+	Transfers Transfers `mapstructure:"transfers"`
+}
+
+// SMTP holds the email server settings
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// Twilio holds the SMS provider settings
+type Twilio struct {
+	AccountSID string `mapstructure:"account_sid"`
+	AuthToken  string `mapstructure:"auth_token"`
+	FromNumber string `mapstructure:"from_number"`
+}
+
+// Webhooks holds the settings of the api-server's webhook delivery
+type Webhooks struct {
+	// PollInterval is how often due deliveries and batches are looked for
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Timeout bounds each POST to a webhook endpoint
+	Timeout     time.Duration `mapstructure:"timeout"`
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	// RetryDelay is the delay before the second attempt of a failed delivery, doubling up to
+	// MaxRetryDelay for later ones
+	RetryDelay    time.Duration `mapstructure:"retry_delay"`
+	MaxRetryDelay time.Duration `mapstructure:"max_retry_delay"`
+	// AggregateMin is the number of an address's alerts from one block, e.g. of an airdrop, from
+	// which they are posted as one aggregated alert; 0 disables aggregation
+	AggregateMin int `mapstructure:"aggregate_min"`
+}
+
+// Routing lists the channels alerts are delivered over by severity, of email, sms and webhook
+type Routing struct {
+	Info     []string `mapstructure:"info"`
+	Warning  []string `mapstructure:"warning"`
+	Critical []string `mapstructure:"critical"`
+}
+
+// Transfers holds the engine's settings for notifying users of the transfers of their watched
+// addresses over the channels routed for Severity. Transfers matching a user's simple alert rule
+// have the rule's severity instead.
+type Transfers struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Severity string `mapstructure:"severity"`
+}
+
+// Email holds the branding of alert emails, so white-label deployments can use their own
+type Email struct {
+	BrandName string `mapstructure:"brand_name"`
+	// LogoURL is an image shown above the content, in place of the brand name
+	LogoURL string `mapstructure:"logo_url"`
+	// Colors are hex, e.g. #2563eb
+	PrimaryColor    string `mapstructure:"primary_color"`
+	BackgroundColor string `mapstructure:"background_color"`
+	TextColor       string `mapstructure:"text_color"`
+	FooterText      string `mapstructure:"footer_text"`
+	SupportAddress  string `mapstructure:"support_address"`
+	// AppURL is linked from the alerts, empty leaves the link out
+	AppURL string `mapstructure:"app_url"`
+}
+
+// Diagnostics holds the settings of the pprof and runtime metrics endpoints of both services
+type Diagnostics struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Token is the bearer token every diagnostics request must present
+	Token string `mapstructure:"token"`
+}
+
+// Archive holds the api-server's settings for moving aged alerts and detached transactions
+// partitions to object storage
+type Archive struct {
+	// URL is where archives are written: s3://bucket/prefix, gs://bucket/prefix (through the GCS
+	// S3-compatible API with HMAC keys) or file:///dir for development. Empty disables archiving.
+	URL string `mapstructure:"url"`
+	// Endpoint overrides the S3 endpoint, e.g. for MinIO
+	Endpoint string `mapstructure:"endpoint"`
+	Region   string `mapstructure:"region"`
+	// AlertRetention is the age after which alerts are archived and deleted
+	AlertRetention time.Duration `mapstructure:"alert_retention"`
+	Interval       time.Duration `mapstructure:"interval"`
+	// BatchSize is the number of alerts per archive object
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// Jobs holds the settings of the api-server's background job workers
+type Jobs struct {
+	// Workers is the number of jobs run at once by each api-server instance, 0 runs none
+	Workers      int           `mapstructure:"workers"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Lease is how long a job stays claimed by its worker without a heartbeat, after which another
+	// worker takes it over
+	Lease       time.Duration `mapstructure:"lease"`
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	// RetryDelay is the delay before the second attempt of a failed job, doubling up to
+	// MaxRetryDelay for later ones
+	RetryDelay    time.Duration `mapstructure:"retry_delay"`
+	MaxRetryDelay time.Duration `mapstructure:"max_retry_delay"`
+}
+
+// Analytics holds how the api-server records each user's API usage
+type Analytics struct {
+	// FlushInterval is how often the calls counted in memory are added to the database
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// Retention is how long the daily usage is kept
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// Admin holds the settings of the api-server's operations endpoints under /api/v1/admin
+type Admin struct {
+	// Token is the bearer token every admin request must present, empty rejects them all
+	Token string `mapstructure:"token"`
+	// OperatorTokens name the support operators, each with a bearer token of their own that is
+	// accepted like Token and identifies them, as "name=sha256", the hex SHA-256 of the token.
+	// Impersonating users requires one.
+	OperatorTokens []string `mapstructure:"operator_tokens"`
+	// EngineURL is the base URL of an engine admin server (e.g. http://engine:8090), whose /stats
+	// supply the consumer lag. Empty leaves it out.
+	EngineURL string `mapstructure:"engine_url"`
+}
+
+// Secrets controls how secret references are refreshed
+type Secrets struct {
+	// RefreshInterval is how often Watch reloads the settings to pick up rotated secrets, 0 disables it
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// Log holds the logging settings
+type Log struct {
+	Level            string `mapstructure:"level"`
+	Format           string `mapstructure:"format"`
+	SampleInitial    int    `mapstructure:"sample_initial"`
+	SampleThereafter int    `mapstructure:"sample_thereafter"`
+}
+
+// Alerts holds the thresholds deciding when a watched address triggers a notification
+type Alerts struct {
+	// MinValue is the smallest transfer, in the chain's native unit, that is reported
+	MinValue float64 `mapstructure:"min_value"`
+	// Cooldown is the minimum time between two alerts for the same address
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// key describes one setting: its default and the environment variables it is read from,
+// the first taking precedence
+type key struct {
+	name string
+	def  any
+	env  []string
+}
+
+var keys = []key{
+	{"kafka.broker", "", []string{"KAFKA_BROKER"}},
+	{"kafka.topic", "", []string{"KAFKA_TOPIC"}},
+	{"kafka.topics", []string{}, []string{"KAFKA_TOPICS"}},
+	{"kafka.topic_prefix", "", []string{"KAFKA_TOPIC_PREFIX"}},
+	{"kafka.topic_pattern", "", []string{"KAFKA_TOPIC_PATTERN"}},
+	{"kafka.topic_refresh_interval", 1 * time.Minute, []string{"KAFKA_TOPIC_REFRESH_INTERVAL"}},
+	{"kafka.max_retries", 5, []string{"KAFKA_MAX_RETRIES"}},
+	{"kafka.retry_delay", 2 * time.Second, []string{"KAFKA_RETRY_DELAY"}},
+	{"kafka.max_retry_delay", 30 * time.Second, []string{"KAFKA_MAX_RETRY_DELAY"}},
+	{"kafka.health_check_interval", 30 * time.Second, []string{"KAFKA_HEALTH_CHECK_INTERVAL"}},
+	{"kafka.serialization", "json", []string{"KAFKA_SERIALIZATION"}},
+	{"kafka.key_serialization", "", []string{"KAFKA_KEY_SERIALIZATION"}},
+	{"kafka.schema_registry_url", "", []string{"SCHEMA_REGISTRY_URL", "KAFKA_SCHEMA_REGISTRY_URL"}},
+	{"kafka.workers", 1, []string{"KAFKA_WORKERS"}},
+	{"kafka.max_in_flight", 0, []string{"KAFKA_MAX_IN_FLIGHT"}},
+	{"kafka.handler_attempts", 5, []string{"KAFKA_HANDLER_ATTEMPTS"}},
+	{"kafka.max_workers", 0, []string{"KAFKA_MAX_WORKERS"}},
+	{"kafka.lag_per_worker", 1000, []string{"KAFKA_LAG_PER_WORKER"}},
+	{"kafka.scale_interval", 30 * time.Second, []string{"KAFKA_SCALE_INTERVAL"}},
+	{"kafka.heartbeat_topic", "", []string{"KAFKA_HEARTBEAT_TOPIC"}},
+	{"kafka.heartbeat_timeout", 1 * time.Minute, []string{"KAFKA_HEARTBEAT_TIMEOUT"}},
+	{"kafka.group_id", "blockchain-address-watcher-group", []string{"KAFKA_GROUP_ID"}},
+	{"kafka.group_balancer", "range", []string{"KAFKA_GROUP_BALANCER"}},
+	{"kafka.rebalance_timeout", 30 * time.Second, []string{"KAFKA_REBALANCE_TIMEOUT"}},
+
+	{"rabbitmq.url", "", []string{"RABBITMQ_URL"}},
+	{"rabbitmq.exchange", "debezium", []string{"RABBITMQ_EXCHANGE"}},
+	{"rabbitmq.queue", "blockchain-address-watcher", []string{"RABBITMQ_QUEUE"}},
+	{"rabbitmq.binding_keys", []string{"#"}, []string{"RABBITMQ_BINDING_KEYS"}},
+	{"rabbitmq.prefetch", 10, []string{"RABBITMQ_PREFETCH"}},
+	{"rabbitmq.delivery_limit", 5, []string{"RABBITMQ_DELIVERY_LIMIT"}},
+
+	{"sqs.queue_url", "", []string{"SQS_QUEUE_URL"}},
+	{"sqs.topic_arn", "", []string{"SNS_TOPIC_ARN"}},
+	{"sqs.wait_time", 20 * time.Second, []string{"SQS_WAIT_TIME"}},
+	{"sqs.visibility_timeout", 30 * time.Second, []string{"SQS_VISIBILITY_TIMEOUT"}},
+	{"sqs.max_messages", 10, []string{"SQS_MAX_MESSAGES"}},
+
+	{"pubsub.project_id", "", []string{"PUBSUB_PROJECT_ID", "GOOGLE_CLOUD_PROJECT"}},
+	{"pubsub.subscription", "", []string{"PUBSUB_SUBSCRIPTION"}},
+	{"pubsub.topic", "", []string{"PUBSUB_TOPIC"}},
+	{"pubsub.max_outstanding", 1, []string{"PUBSUB_MAX_OUTSTANDING"}},
+
+	{"outbox.enabled", false, []string{"OUTBOX_ENABLED"}},
+	{"outbox.poll_interval", 1 * time.Second, []string{"OUTBOX_POLL_INTERVAL"}},
+	{"outbox.batch_size", 100, []string{"OUTBOX_BATCH_SIZE"}},
+	{"outbox.retention", 7 * 24 * time.Hour, []string{"OUTBOX_RETENTION"}},
+
+	{"quarantine.enabled", false, []string{"QUARANTINE_ENABLED"}},
+	{"quarantine.per_minute", 10, []string{"QUARANTINE_PER_MINUTE"}},
+	{"quarantine.retention", 7 * 24 * time.Hour, []string{"QUARANTINE_RETENTION"}},
+
+	{"watcher_status.enabled", false, []string{"WATCHER_STATUS_ENABLED"}},
+	{"live_activity.enabled", false, []string{"LIVE_ACTIVITY_ENABLED"}},
+	{"live_activity.allowed_origins", []string{}, []string{"LIVE_ACTIVITY_ALLOWED_ORIGINS"}},
+	{"transaction_history.enabled", false, []string{"TRANSACTION_HISTORY_ENABLED"}},
+
+	{"database.driver", "postgres", []string{"DB_DRIVER"}},
+	{"database.url", "", []string{"DB_URL", "DATABASE_URL"}},
+	{"database.max_conns", 10, []string{"DB_MAX_CONNS"}},
+	{"database.min_conns", 0, []string{"DB_MIN_CONNS"}},
+	{"database.max_conn_lifetime", 1 * time.Hour, []string{"DB_MAX_CONN_LIFETIME"}},
+	{"database.max_conn_idle_time", 30 * time.Minute, []string{"DB_MAX_CONN_IDLE_TIME"}},
+	{"database.health_check_period", 1 * time.Minute, []string{"DB_HEALTH_CHECK_PERIOD"}},
+	{"database.query_timeout", 5 * time.Second, []string{"DB_QUERY_TIMEOUT"}},
+	{"database.reporting_query_timeout", 1 * time.Minute, []string{"DB_REPORTING_QUERY_TIMEOUT"}},
+	{"database.replica_urls", []string{}, []string{"DB_REPLICA_URLS"}},
+	{"database.max_replica_lag", 10 * time.Second, []string{"DB_MAX_REPLICA_LAG"}},
+	{"database.replica_check_interval", 5 * time.Second, []string{"DB_REPLICA_CHECK_INTERVAL"}},
+	{"database.partitions_ahead", 3, []string{"DB_PARTITIONS_AHEAD"}},
+	{"database.transaction_retention_months", 0, []string{"DB_TRANSACTION_RETENTION_MONTHS"}},
+	{"database.partition_check_interval", 1 * time.Hour, []string{"DB_PARTITION_CHECK_INTERVAL"}},
+
+	{"jwt.secret", "", []string{"JWT_SECRET"}},
+	{"jwt.expiry", 1 * time.Hour, []string{"JWT_EXPIRY"}},
+	{"jwt.refresh_expiry", 30 * 24 * time.Hour, []string{"JWT_REFRESH_EXPIRY"}},
+
+	{"server.port", "7000", []string{"PORT", "SERVER_PORT"}},
+	{"server.request_timeout", 15 * time.Second, []string{"SERVER_REQUEST_TIMEOUT"}},
+	{"server.auth_timeout", 5 * time.Second, []string{"SERVER_AUTH_TIMEOUT"}},
+	{"server.reporting_timeout", 90 * time.Second, []string{"SERVER_REPORTING_TIMEOUT"}},
+	{"server.signature_max_skew", 5 * time.Minute, []string{"SERVER_SIGNATURE_MAX_SKEW"}},
+	{"server.shutdown_timeout", 30 * time.Second, []string{"SERVER_SHUTDOWN_TIMEOUT"}},
+	{"server.api_key_encryption_key", "", []string{"SERVER_API_KEY_ENCRYPTION_KEY"}},
+	{"server.proxy_header", "", []string{"SERVER_PROXY_HEADER"}},
+	{"server.trusted_proxies", []string{}, []string{"SERVER_TRUSTED_PROXIES"}},
+
+	{"engine.transport", "kafka", []string{"ENGINE_TRANSPORT"}},
+	{"engine.admin_addr", ":8090", []string{"ENGINE_ADMIN_ADDR"}},
+	{"engine.shutdown_timeout", 30 * time.Second, []string{"ENGINE_SHUTDOWN_TIMEOUT"}},
+
+	{"chain.network", "ethereum", []string{"CHAIN_NETWORK"}},
+	{"chain.rpc_url", "", []string{"CHAIN_RPC_URL"}},
+	{"chain.ws_url", "", []string{"CHAIN_WS_URL"}},
+	{"chain.confirmations", 12, []string{"CHAIN_CONFIRMATIONS"}},
+	{"chain.poll_interval", 15 * time.Second, []string{"CHAIN_POLL_INTERVAL"}},
+	{"chain.pending_transactions", false, []string{"CHAIN_PENDING_TRANSACTIONS"}},
+	{"chain.rpc_api_key", "", []string{"CHAIN_RPC_API_KEY"}},
+	{"chain.fee_cache_ttl", 15 * time.Second, []string{"CHAIN_FEE_CACHE_TTL"}},
+	{"chain.cache.max_entries", 10000, []string{"CHAIN_CACHE_MAX_ENTRIES"}},
+	{"chain.cache.redis_url", "", []string{"CHAIN_CACHE_REDIS_URL"}},
+	{"chain.cache.block_ttl", 1 * time.Hour, []string{"CHAIN_CACHE_BLOCK_TTL"}},
+	{"chain.cache.metadata_ttl", 24 * time.Hour, []string{"CHAIN_CACHE_METADATA_TTL"}},
+	{"chain.cache.balance_ttl", 15 * time.Second, []string{"CHAIN_CACHE_BALANCE_TTL"}},
+
+	{"notifications.smtp.host", "", []string{"SMTP_HOST"}},
+	{"notifications.smtp.port", 587, []string{"SMTP_PORT"}},
+	{"notifications.smtp.username", "", []string{"SMTP_USERNAME"}},
+	{"notifications.smtp.password", "", []string{"SMTP_PASSWORD"}},
+	{"notifications.smtp.from", "", []string{"SMTP_FROM"}},
+	{"notifications.twilio.account_sid", "", []string{"TWILIO_ACCOUNT_SID"}},
+	{"notifications.twilio.auth_token", "", []string{"TWILIO_AUTH_TOKEN"}},
+	{"notifications.twilio.from_number", "", []string{"TWILIO_FROM_NUMBER"}},
+	{"notifications.webhooks.poll_interval", 1 * time.Second, []string{"WEBHOOK_POLL_INTERVAL"}},
+	{"notifications.webhooks.timeout", 10 * time.Second, []string{"WEBHOOK_TIMEOUT"}},
+	{"notifications.webhooks.max_attempts", 8, []string{"WEBHOOK_MAX_ATTEMPTS"}},
+	{"notifications.webhooks.retry_delay", 30 * time.Second, []string{"WEBHOOK_RETRY_DELAY"}},
+	{"notifications.webhooks.max_retry_delay", 1 * time.Hour, []string{"WEBHOOK_MAX_RETRY_DELAY"}},
+	{"notifications.webhooks.aggregate_min", 3, []string{"WEBHOOK_AGGREGATE_MIN"}},
+	{"notifications.routing.info", []string{"email", "webhook"}, []string{"NOTIFY_ROUTE_INFO"}},
+	{"notifications.routing.warning", []string{"email", "webhook"}, []string{"NOTIFY_ROUTE_WARNING"}},
+	{"notifications.routing.critical", []string{"sms", "email", "webhook"}, []string{"NOTIFY_ROUTE_CRITICAL"}},
+	{"notifications.email.brand_name", "Blockchain Address Watcher", []string{"EMAIL_BRAND_NAME"}},
+	{"notifications.email.logo_url", "", []string{"EMAIL_LOGO_URL"}},
+	{"notifications.email.primary_color", "#2563eb", []string{"EMAIL_PRIMARY_COLOR"}},
+	{"notifications.email.background_color", "#f4f5f7", []string{"EMAIL_BACKGROUND_COLOR"}},
+	{"notifications.email.text_color", "#1f2933", []string{"EMAIL_TEXT_COLOR"}},
+	{"notifications.email.footer_text", "", []string{"EMAIL_FOOTER_TEXT"}},
+	{"notifications.email.support_address", "", []string{"EMAIL_SUPPORT_ADDRESS"}},
+	{"notifications.email.app_url", "", []string{"EMAIL_APP_URL"}},
+	{"notifications.transfers.enabled", false, []string{"NOTIFY_TRANSFERS_ENABLED"}},
+	{"notifications.transfers.severity", "warning", []string{"NOTIFY_TRANSFERS_SEVERITY"}},
+
+	{"secrets.refresh_interval", 0, []string{"SECRETS_REFRESH_INTERVAL"}},
+
+	{"log.level", "info", []string{"LOG_LEVEL"}},
+	{"log.format", "text", []string{"LOG_FORMAT"}},
+	{"log.sample_initial", 100, []string{"LOG_SAMPLE_INITIAL"}},
+	{"log.sample_thereafter", 100, []string{"LOG_SAMPLE_THEREAFTER"}},
+
+	{"alerts.min_value", 0.0, []string{"ALERT_MIN_VALUE"}},
+	{"alerts.cooldown", 0, []string{"ALERT_COOLDOWN"}},
+
+	{"diagnostics.enabled", false, []string{"DIAGNOSTICS_ENABLED"}},
+	{"diagnostics.token", "", []string{"DIAGNOSTICS_TOKEN"}},
+
+	{"archive.url", "", []string{"ARCHIVE_URL"}},
+	{"archive.endpoint", "", []string{"ARCHIVE_ENDPOINT"}},
+	{"archive.region", "", []string{"ARCHIVE_REGION", "AWS_REGION"}},
+	{"archive.alert_retention", 90 * 24 * time.Hour, []string{"ARCHIVE_ALERT_RETENTION"}},
+	{"archive.interval", 1 * time.Hour, []string{"ARCHIVE_INTERVAL"}},
+	{"archive.batch_size", 5000, []string{"ARCHIVE_BATCH_SIZE"}},
+
+	{"jobs.workers", 2, []string{"JOBS_WORKERS"}},
+	{"jobs.poll_interval", 1 * time.Second, []string{"JOBS_POLL_INTERVAL"}},
+	{"jobs.lease", 5 * time.Minute, []string{"JOBS_LEASE"}},
+	{"jobs.max_attempts", 5, []string{"JOBS_MAX_ATTEMPTS"}},
+	{"jobs.retry_delay", 30 * time.Second, []string{"JOBS_RETRY_DELAY"}},
+	{"jobs.max_retry_delay", 1 * time.Hour, []string{"JOBS_MAX_RETRY_DELAY"}},
+
+	{"analytics.flush_interval", 30 * time.Second, []string{"ANALYTICS_FLUSH_INTERVAL"}},
+	{"analytics.retention", 90 * 24 * time.Hour, []string{"ANALYTICS_RETENTION"}},
+
+	{"admin.token", "", []string{"ADMIN_TOKEN"}},
+	{"admin.operator_tokens", []string{}, []string{"ADMIN_OPERATOR_TOKENS"}},
+	{"admin.engine_url", "", []string{"ENGINE_ADMIN_URL"}},
+}
+
+// Options controls where Load reads settings from
+type Options struct {
+	// ConfigFile is a YAML or TOML file, optional. Defaults to the CONFIG_FILE environment variable.
+	ConfigFile string
+	// EnvFile is loaded into the environment without overriding existing variables, defaults to ../.env
+	EnvFile string
+	// Flags maps setting names (e.g. "kafka.broker") to CLI flags that override them when set
+	Flags map[string]*pflag.Flag
+	// Secrets resolves secret references, defaults to secrets.NewResolver()
+	Secrets *secrets.Resolver
+}
+
+// Load reads the settings. Every malformed value is reported in the returned error, not just the first.
+//
+// Example usage:
+//
+//	s, err := settings.Load(settings.Options{
+//	    Flags: map[string]*pflag.Flag{"kafka.broker": cmd.Flags().Lookup("broker")},
+//	})
+func Load(opts Options) (*Settings, error) {
+	if opts.EnvFile == "" {
+		opts.EnvFile = filepath.Join("..", ".env")
+	}
+	if err := godotenv.Load(opts.EnvFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load %s: %w", opts.EnvFile, err)
+	}
+
+	v := viper.New()
+	for _, k := range keys {
+		v.SetDefault(k.name, k.def)
+		if err := v.BindEnv(append([]string{k.name}, k.env...)...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := v.BindEnv("config_file", "CONFIG_FILE"); err != nil {
+		return nil, err
+	}
+	if opts.ConfigFile == "" {
+		opts.ConfigFile = v.GetString("config_file")
+	}
+	if opts.ConfigFile != "" {
+		v.SetConfigFile(opts.ConfigFile)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", opts.ConfigFile, err)
+		}
+	}
+
+	for name, flag := range opts.Flags {
+		if flag == nil {
+			continue
+		}
+		if err := v.BindPFlag(name, flag); err != nil {
+			return nil, err
+		}
+	}
+
+	var s Settings
+	err := v.Unmarshal(&s, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		durationHook,
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Kafka.Topics = trimList(s.Kafka.Topics)
+	s.RabbitMQ.BindingKeys = trimList(s.RabbitMQ.BindingKeys)
+	s.Database.ReplicaURLs = trimList(s.Database.ReplicaURLs)
+	s.LiveActivity.AllowedOrigins = trimList(s.LiveActivity.AllowedOrigins)
+	s.Admin.OperatorTokens = trimList(s.Admin.OperatorTokens)
+	s.Server.TrustedProxies = trimList(s.Server.TrustedProxies)
+	s.Notifications.Routing.Info = trimList(s.Notifications.Routing.Info)
+	s.Notifications.Routing.Warning = trimList(s.Notifications.Routing.Warning)
+	s.Notifications.Routing.Critical = trimList(s.Notifications.Routing.Critical)
+
+	if opts.Secrets == nil {
+		opts.Secrets = secrets.NewResolver()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := resolveSecrets(ctx, reflect.ValueOf(&s).Elem(), "", opts.Secrets); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// resolveSecrets replaces every string field of v holding a secret reference with the secret,
+// reporting all failures
+func resolveSecrets(ctx context.Context, v reflect.Value, prefix string, r *secrets.Resolver) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		name := prefix + v.Type().Field(i).Tag.Get("mapstructure")
+
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := resolveSecrets(ctx, field, name+".", r); err != nil {
+				errs = append(errs, err)
+			}
+		case reflect.String:
+			if !secrets.IsReference(field.String()) {
+				continue
+			}
+			value, err := r.Resolve(ctx, field.String())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("'%s' %w", name, err))
+				continue
+			}
+			field.SetString(value)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings that can be reloaded at runtime and those shared by both services,
+// reporting every problem. Kafka settings are checked by the engine's consumer.Config.Validate.
+func (s *Settings) Validate() error {
+	var errs []error
+
+	switch s.Engine.Transport {
+	case "kafka":
+	case "rabbitmq":
+		if s.RabbitMQ.URL == "" {
+			errs = append(errs, errors.New("'rabbitmq.url' is required for the rabbitmq transport"))
+		}
+	case "sqs":
+		if s.SQS.QueueURL == "" {
+			errs = append(errs, errors.New("'sqs.queue_url' is required for the sqs transport"))
+		}
+		if s.SQS.WaitTime > 20*time.Second {
+			errs = append(errs, errors.New("'sqs.wait_time' must be at most 20s"))
+		}
+		if s.SQS.MaxMessages < 1 || s.SQS.MaxMessages > 10 {
+			errs = append(errs, fmt.Errorf("'sqs.max_messages' must be between 1 and 10, got %d", s.SQS.MaxMessages))
+		}
+	case "pubsub":
+		if s.PubSub.ProjectID == "" {
+			errs = append(errs, errors.New("'pubsub.project_id' is required for the pubsub transport"))
+		}
+		if s.PubSub.Subscription == "" {
+			errs = append(errs, errors.New("'pubsub.subscription' is required for the pubsub transport"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("'engine.transport' must be kafka, rabbitmq, sqs or pubsub, got %q", s.Engine.Transport))
+	}
+
+	switch s.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("'log.level' must be debug, info, warn or error, got %q", s.Log.Level))
+	}
+	switch s.Log.Format {
+	case "text", "json":
+	default:
+		errs = append(errs, fmt.Errorf("'log.format' must be text or json, got %q", s.Log.Format))
+	}
+	if s.Log.SampleInitial <= 0 || s.Log.SampleThereafter <= 0 {
+		errs = append(errs, errors.New("'log.sample_initial' and 'log.sample_thereafter' must be positive"))
+	}
+
+	if s.Diagnostics.Enabled && s.Diagnostics.Token == "" {
+		errs = append(errs, errors.New("'diagnostics.token' is required when diagnostics are enabled"))
+	}
+
+	switch s.Database.Driver {
+	case "postgres", "sqlite":
+	default:
+		errs = append(errs, fmt.Errorf("'database.driver' must be postgres or sqlite, got %q", s.Database.Driver))
+	}
+	if s.Database.MaxConns <= 0 {
+		errs = append(errs, errors.New("'database.max_conns' must be positive"))
+	}
+	if s.Database.MinConns < 0 || s.Database.MinConns > s.Database.MaxConns {
+		errs = append(errs, errors.New("'database.min_conns' must be between 0 and 'database.max_conns'"))
+	}
+	if s.Database.MaxConnLifetime <= 0 || s.Database.MaxConnIdleTime <= 0 || s.Database.HealthCheckPeriod <= 0 {
+		errs = append(errs, errors.New("'database.max_conn_lifetime', 'database.max_conn_idle_time' and 'database.health_check_period' must be positive"))
+	}
+	if s.Server.RequestTimeout <= 0 || s.Server.AuthTimeout <= 0 || s.Server.ReportingTimeout <= 0 {
+		errs = append(errs, errors.New("'server.request_timeout', 'server.auth_timeout' and 'server.reporting_timeout' must be positive"))
+	}
+	if s.Server.SignatureMaxSkew <= 0 {
+		errs = append(errs, errors.New("'server.signature_max_skew' must be positive"))
+	}
+	if s.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("'server.shutdown_timeout' must be positive"))
+	}
+	if k := s.Server.APIKeyEncryptionKey; k != "" {
+		if key, err := base64.StdEncoding.DecodeString(k); err != nil || len(key) != 32 {
+			errs = append(errs, errors.New("'server.api_key_encryption_key' must be the base64 of 32 bytes"))
+		}
+	}
+	if s.Server.ProxyHeader != "" && len(s.Server.TrustedProxies) == 0 {
+		errs = append(errs, errors.New("'server.proxy_header' requires 'server.trusted_proxies', otherwise any client could set its address"))
+	}
+	for _, proxy := range s.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("'server.trusted_proxies' must hold addresses or CIDR networks, got %q", proxy))
+			}
+		}
+	}
+	if s.JWT.Expiry <= 0 || s.JWT.RefreshExpiry < s.JWT.Expiry {
+		errs = append(errs, errors.New("'jwt.expiry' must be positive and at most 'jwt.refresh_expiry'"))
+	}
+	if s.Database.QueryTimeout <= 0 || s.Database.ReportingQueryTimeout <= 0 {
+		errs = append(errs, errors.New("'database.query_timeout' and 'database.reporting_query_timeout' must be positive"))
+	}
+	if len(s.Database.ReplicaURLs) > 0 && (s.Database.MaxReplicaLag <= 0 || s.Database.ReplicaCheckInterval <= 0) {
+		errs = append(errs, errors.New("'database.max_replica_lag' and 'database.replica_check_interval' must be positive when replicas are configured"))
+	}
+	if s.Database.PartitionsAhead < 1 {
+		errs = append(errs, fmt.Errorf("'database.partitions_ahead' must be at least 1, got %d", s.Database.PartitionsAhead))
+	}
+	if s.Database.TransactionRetentionMonths < 0 {
+		errs = append(errs, errors.New("'database.transaction_retention_months' must not be negative"))
+	}
+	if s.Database.PartitionCheckInterval <= 0 {
+		errs = append(errs, errors.New("'database.partition_check_interval' must be positive"))
+	}
+
+	if s.Outbox.Enabled {
+		if s.Database.URL == "" {
+			errs = append(errs, errors.New("'database.url' is required when the outbox is enabled"))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'outbox.enabled' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+		// Deduplication relies on Kafka partition offsets identifying events
+		if s.Engine.Transport != "kafka" {
+			errs = append(errs, fmt.Errorf("'outbox.enabled' requires the kafka transport, got %q", s.Engine.Transport))
+		}
+		if s.Outbox.BatchSize <= 0 {
+			errs = append(errs, errors.New("'outbox.batch_size' must be positive"))
+		}
+	}
+
+	if s.Quarantine.Enabled {
+		if s.Database.URL == "" {
+			errs = append(errs, errors.New("'database.url' is required when the quarantine is enabled"))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'quarantine.enabled' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+		if s.Quarantine.PerMinute <= 0 {
+			errs = append(errs, errors.New("'quarantine.per_minute' must be positive"))
+		}
+		if s.Quarantine.Retention <= 0 {
+			errs = append(errs, errors.New("'quarantine.retention' must be positive"))
+		}
+	}
+
+	if s.WatcherStatus.Enabled {
+		if s.Database.URL == "" {
+			errs = append(errs, errors.New("'database.url' is required when the watcher status is enabled"))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'watcher_status.enabled' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+	}
+	if s.LiveActivity.Enabled {
+		if s.Database.URL == "" {
+			errs = append(errs, errors.New("'database.url' is required when live activity is enabled"))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'live_activity.enabled' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+		for _, origin := range s.LiveActivity.AllowedOrigins {
+			if origin == "*" {
+				continue
+			}
+			if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+				errs = append(errs, fmt.Errorf("'live_activity.allowed_origins' must hold origins such as https://app.example.com or *, got %q", origin))
+			}
+		}
+	}
+	operators := make(map[string]bool, len(s.Admin.OperatorTokens))
+	for _, entry := range s.Admin.OperatorTokens {
+		name, sum, _ := strings.Cut(entry, "=")
+		if hash, err := hex.DecodeString(sum); name == "" || err != nil || len(hash) != sha256.Size {
+			errs = append(errs, fmt.Errorf("'admin.operator_tokens' must hold name=sha256 entries, the hex SHA-256 of each operator's token, got %q", entry))
+			continue
+		}
+		if operators[name] {
+			errs = append(errs, fmt.Errorf("'admin.operator_tokens' names the operator %q twice", name))
+		}
+		operators[name] = true
+	}
+	if s.TransactionHistory.Enabled {
+		if s.Database.URL == "" {
+			errs = append(errs, errors.New("'database.url' is required when the transaction history is enabled"))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'transaction_history.enabled' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+	}
+
+	if s.Archive.URL != "" {
+		if u, err := url.Parse(s.Archive.URL); err != nil || (u.Scheme != "s3" && u.Scheme != "gs" && u.Scheme != "file") {
+			errs = append(errs, fmt.Errorf("'archive.url' must be an s3://, gs:// or file:// URL, got %q", s.Archive.URL))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'archive.url' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+		if s.Archive.AlertRetention <= 0 || s.Archive.Interval <= 0 || s.Archive.BatchSize <= 0 {
+			errs = append(errs, errors.New("'archive.alert_retention', 'archive.interval' and 'archive.batch_size' must be positive"))
+		}
+		// Detaching a transactions partition deletes the alerts of its transfers, they must be
+		// archived by then
+		months := s.Database.TransactionRetentionMonths
+		if months > 0 && s.Archive.AlertRetention >= time.Duration(months)*28*24*time.Hour {
+			errs = append(errs, fmt.Errorf("'archive.alert_retention' must be shorter than 'database.transaction_retention_months' (%d months)", months))
+		}
+	}
+
+	if s.Jobs.Workers < 0 {
+		errs = append(errs, errors.New("'jobs.workers' must not be negative"))
+	}
+	if s.Jobs.PollInterval <= 0 || s.Jobs.Lease <= 0 || s.Jobs.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("'jobs.poll_interval', 'jobs.lease' and 'jobs.max_attempts' must be positive"))
+	}
+	if s.Jobs.RetryDelay <= 0 || s.Jobs.MaxRetryDelay < s.Jobs.RetryDelay {
+		errs = append(errs, errors.New("'jobs.retry_delay' must be positive and at most 'jobs.max_retry_delay'"))
+	}
+	if s.Analytics.FlushInterval <= 0 {
+		errs = append(errs, errors.New("'analytics.flush_interval' must be positive"))
+	}
+	if s.Analytics.Retention < 24*time.Hour {
+		errs = append(errs, errors.New("'analytics.retention' must be at least 24h"))
+	}
+
+	if s.Chain.FeeCacheTTL <= 0 {
+		errs = append(errs, errors.New("'chain.fee_cache_ttl' must be positive"))
+	}
+	if s.Chain.Cache.MaxEntries < 0 {
+		errs = append(errs, fmt.Errorf("'chain.cache.max_entries' must not be negative, got %d", s.Chain.Cache.MaxEntries))
+	}
+	if c := s.Chain.Cache; c.BlockTTL <= 0 || c.MetadataTTL <= 0 || c.BalanceTTL <= 0 {
+		errs = append(errs, errors.New("'chain.cache.block_ttl', 'chain.cache.metadata_ttl' and 'chain.cache.balance_ttl' must be positive"))
+	}
+	if s.Alerts.MinValue < 0 {
+		errs = append(errs, errors.New("'alerts.min_value' must not be negative"))
+	}
+	if s.Alerts.Cooldown < 0 {
+		errs = append(errs, errors.New("'alerts.cooldown' must not be negative"))
+	}
+
+	endpoints := []struct{ name, value string }{
+		{"chain.rpc_url", s.Chain.RPCURL},
+		{"chain.ws_url", s.Chain.WSURL},
+		{"chain.cache.redis_url", s.Chain.Cache.RedisURL},
+		{"admin.engine_url", s.Admin.EngineURL},
+		{"notifications.email.logo_url", s.Notifications.Email.LogoURL},
+		{"notifications.email.app_url", s.Notifications.Email.AppURL},
+	}
+	for _, e := range endpoints {
+		if e.value == "" {
+			continue
+		}
+		if u, err := url.Parse(e.value); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("'%s' is not a valid URL: %q", e.name, e.value))
+		}
+	}
+
+	if port := s.Notifications.SMTP.Port; port < 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("'notifications.smtp.port' must be between 0 and 65535, got %d", port))
+	}
+	if w := s.Notifications.Webhooks; w.PollInterval <= 0 || w.Timeout <= 0 || w.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("'notifications.webhooks.poll_interval', 'notifications.webhooks.timeout' and 'notifications.webhooks.max_attempts' must be positive"))
+	}
+	if w := s.Notifications.Webhooks; w.RetryDelay <= 0 || w.MaxRetryDelay < w.RetryDelay {
+		errs = append(errs, errors.New("'notifications.webhooks.retry_delay' must be positive and at most 'notifications.webhooks.max_retry_delay'"))
+	}
+	if n := s.Notifications.Webhooks.AggregateMin; n < 0 || n == 1 {
+		errs = append(errs, fmt.Errorf("'notifications.webhooks.aggregate_min' must be 0 or at least 2, got %d", n))
+	}
+	colors := []struct{ name, value string }{
+		{"notifications.email.primary_color", s.Notifications.Email.PrimaryColor},
+		{"notifications.email.background_color", s.Notifications.Email.BackgroundColor},
+		{"notifications.email.text_color", s.Notifications.Email.TextColor},
+	}
+	for _, c := range colors {
+		if !hexColor.MatchString(c.value) {
+			errs = append(errs, fmt.Errorf("'%s' must be a hex color like #2563eb, got %q", c.name, c.value))
+		}
+	}
+	if smtp := s.Notifications.SMTP; smtp.Host != "" {
+		if _, err := mail.ParseAddress(smtp.From); err != nil {
+			errs = append(errs, fmt.Errorf("'notifications.smtp.from' must be a valid email address when 'notifications.smtp.host' is set, got %q", smtp.From))
+		}
+	}
+	if a := s.Notifications.Email.SupportAddress; a != "" {
+		if _, err := mail.ParseAddress(a); err != nil {
+			errs = append(errs, fmt.Errorf("'notifications.email.support_address' is not a valid email address: %q", a))
+		}
+	}
+	routes := []struct {
+		name     string
+		channels []string
+	}{
+		{"notifications.routing.info", s.Notifications.Routing.Info},
+		{"notifications.routing.warning", s.Notifications.Routing.Warning},
+		{"notifications.routing.critical", s.Notifications.Routing.Critical},
+	}
+	for _, r := range routes {
+		for _, channel := range r.channels {
+			if channel != "email" && channel != "sms" && channel != "webhook" {
+				errs = append(errs, fmt.Errorf("'%s' must list email, sms or webhook, got %q", r.name, channel))
+			}
+		}
+	}
+	if t := s.Notifications.Transfers; t.Severity != "info" && t.Severity != "warning" && t.Severity != "critical" {
+		errs = append(errs, fmt.Errorf("'notifications.transfers.severity' must be info, warning or critical, got %q", t.Severity))
+	}
+	if s.Notifications.Transfers.Enabled {
+		if s.Database.URL == "" {
+			errs = append(errs, errors.New("'database.url' is required when transfer notifications are enabled"))
+		}
+		if s.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("'notifications.transfers.enabled' requires the postgres database driver, got %q", s.Database.Driver))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hexColor matches the #rgb and #rrggbb colors of the email theme
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// EnvVar returns the primary environment variable of a setting, for error messages and docs
+func EnvVar(name string) string {
+	for _, k := range keys {
+		if k.name == name && len(k.env) > 0 {
+			return k.env[0]
+		}
+	}
+	return ""
+}
+
+// durationHook parses durations like "500ms", "5s" or "2m". Bare numbers are taken as seconds,
+// so KAFKA_RETRY_DELAY=2 means two seconds rather than two nanoseconds.
+func durationHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+
+	switch value := data.(type) {
+	case string:
+		value = strings.TrimSpace(value)
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Duration(n) * time.Second, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a duration (e.g. 5s, 2m)", value)
+		}
+		return d, nil
+	case int:
+		return time.Duration(value) * time.Second, nil
+	case int64:
+		return time.Duration(value) * time.Second, nil
+	}
+	return data, nil
+}
+
+// trimList drops blank entries and surrounding spaces from a comma separated list
+func trimList(items []string) []string {
+	var out []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+// Package tokens holds the token presets, built-in lists of token contracts per chain that a rule
+// filters its transfers to instead of the single contract of its token_address. The stablecoins
+// preset lets users watch stablecoin movements only with one toggle rather than listing the USDC,
+// USDT and DAI contracts of each chain themselves. The api-server checks the presets of rules
+// against it, and the engine filters the rules' transfers with Match.
+package tokens
+
+import (
+	"slices"
+	"strings"
+)
+
+// Presets
+const (
+	Stablecoins = "stablecoins"
+)
+
+// Token is a token contract of a preset, its address in lowercase
+type Token struct {
+	Symbol  string
+	Address string
+}
+
+// Preset is a named list of token contracts per chain
+type Preset struct {
+	Name        string
+	Description string
+	// Chains maps the chain of an address, e.g. ethereum, to the preset's contracts on it
+	Chains map[string][]Token
+}
+
+var presets = []Preset{
+	{
+		Name:        Stablecoins,
+		Description: "Major USD stablecoins: USDC, USDT and DAI",
+		Chains: map[string][]Token{
+			"ethereum": {
+				{"USDC", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+				{"USDT", "0xdac17f958d2ee523a2206206994597c13d831ec7"},
+				{"DAI", "0x6b175474e89094c44da98b954eedeac495271d0f"},
+			},
+			"polygon": {
+				{"USDC", "0x3c499c542cef5e3811e1192ce70d8cc03d5c3359"},
+				{"USDC.e", "0x2791bca1f2de4661ed88a30c99a7a9449aa84174"},
+				{"USDT", "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"},
+				{"DAI", "0x8f3cf7ad23cd3cadbd9735aff958023239c6a063"},
+			},
+			"arbitrum": {
+				{"USDC", "0xaf88d065e77c8cc2239327c5edb3a432268e5831"},
+				{"USDC.e", "0xff970a61a04b1ca14834a43f5de4533ebddb5cc8"},
+				{"USDT", "0xfd086bc7cd5c481dcc9c85ebe478a1c0b69fcbb9"},
+				{"DAI", "0xda10009cbd5d07dd0cecc66161fc93d7c9000da1"},
+			},
+			"optimism": {
+				{"USDC", "0x0b2c639c533813f4aa9d7837caf62653d097ff85"},
+				{"USDT", "0x94b008aa00579c1307b0ef2c499ad98a8ce58e58"},
+				{"DAI", "0xda10009cbd5d07dd0cecc66161fc93d7c9000da1"},
+			},
+			"base": {
+				{"USDC", "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913"},
+				{"DAI", "0x50c5725949a6f0c72e6c4a641f24049a917db0cb"},
+			},
+			"bsc": {
+				{"USDC", "0x8ac76a51cc950d9822d68b83fe1ad97b32cd580d"},
+				{"USDT", "0x55d398326f99059ff775485246999027b3197955"},
+				{"DAI", "0x1af3f329e8be154074d8769d1ffa4ee058b1dbc3"},
+			},
+		},
+	},
+}
+
+// All returns the presets
+func All() []Preset {
+	return presets
+}
+
+// Get returns the preset named name, false when there is none
+func Get(name string) (Preset, bool) {
+	i := slices.IndexFunc(presets, func(p Preset) bool { return p.Name == name })
+	if i < 0 {
+		return Preset{}, false
+	}
+	return presets[i], true
+}
+
+// Valid reports whether name is a preset
+func Valid(name string) bool {
+	_, ok := Get(name)
+	return ok
+}
+
+// Match reports whether a transfer of the token contract token on chain passes the filter of the
+// preset named name. Transfers of the chain's native currency, with no contract, never match.
+func Match(name, chain, token string) bool {
+	p, ok := Get(name)
+	if !ok || token == "" {
+		return false
+	}
+	return slices.ContainsFunc(p.Chains[strings.ToLower(chain)], func(t Token) bool {
+		return strings.EqualFold(t.Address, token)
+	})
+}
@@ -0,0 +1,145 @@
+// Package tracecontext propagates W3C Trace Context (https://www.w3.org/TR/trace-context/) through
+// HTTP requests and message headers, so a change made through the API can be followed through the
+// engine to the alerts it publishes. It only carries trace and span IDs; exporting spans is left to
+// a tracing library, e.g. started from consumer.Trace.
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Header names defined by W3C Trace Context, also used as Kafka message header keys
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// flagSampled is the trace-flags bit recording that the caller may have sampled the trace
+const flagSampled = 0x01
+
+// SpanContext identifies a span within a trace. The zero value is invalid and propagates nothing.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	// State is the vendor specific tracestate header, passed on unchanged
+	State string
+}
+
+// New starts a sampled trace with a random trace and span ID
+func New() SpanContext {
+	var sc SpanContext
+	rand.Read(sc.TraceID[:])
+	rand.Read(sc.SpanID[:])
+	sc.Flags = flagSampled
+	return sc
+}
+
+// Child returns a span context for a new span in the same trace, with sc as its parent. A new
+// trace is started when sc is invalid.
+func (sc SpanContext) Child() SpanContext {
+	if !sc.IsValid() {
+		return New()
+	}
+	rand.Read(sc.SpanID[:])
+	return sc
+}
+
+// IsValid reports whether sc has non-zero trace and span IDs
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Sampled reports whether the sampled flag is set
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&flagSampled != 0
+}
+
+// TraceIDString returns the hex encoded trace ID, e.g. for log lines
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// String formats sc as a version 00 traceparent header value
+func (sc SpanContext) String() string {
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// Parse parses traceparent and tracestate header values. Versions above 00 are accepted as long
+// as their first four fields have the version 00 format, as the specification requires.
+func Parse(traceparent, tracestate string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 {
+		return sc, fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("unsupported traceparent version %q", version)
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 {
+		return sc, fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(traceID)); err != nil {
+		return sc, fmt.Errorf("invalid trace ID: %w", err)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(spanID)); err != nil {
+		return sc, fmt.Errorf("invalid span ID: %w", err)
+	}
+	var f [1]byte
+	if _, err := hex.Decode(f[:], []byte(flags)); err != nil {
+		return sc, fmt.Errorf("invalid trace flags: %w", err)
+	}
+	sc.Flags = f[0]
+	if !sc.IsValid() {
+		return SpanContext{}, errors.New("traceparent has an all zero trace or span ID")
+	}
+
+	sc.State = strings.TrimSpace(tracestate)
+	return sc, nil
+}
+
+// Extract reads the span context from message headers, reporting false when there is none or
+// it is malformed
+func Extract(headers map[string]string) (SpanContext, bool) {
+	traceparent, ok := headers[TraceparentHeader]
+	if !ok {
+		return SpanContext{}, false
+	}
+	sc, err := Parse(traceparent, headers[TracestateHeader])
+	return sc, err == nil
+}
+
+// Inject writes sc into message headers, replacing any span context already there. Nothing is
+// written when sc is invalid.
+func Inject(sc SpanContext, headers map[string]string) {
+	if !sc.IsValid() {
+		return
+	}
+	headers[TraceparentHeader] = sc.String()
+	if sc.State != "" {
+		headers[TracestateHeader] = sc.State
+	} else {
+		delete(headers, TracestateHeader)
+	}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying sc
+func NewContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the span context carried by ctx, if any
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}